@@ -3,19 +3,61 @@ package main
 import (
 	"bufio"
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"math/big"
 	"os"
 	"strconv"
 	"strings"
-	"time"
 
-	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/output"
 )
 
+// blockRecord --format json 输出的区块，金额和 Gas 价格为 wei 的十进制字符串
+type blockRecord struct {
+	Number       uint64         `json:"number"`
+	Hash         common.Hash    `json:"hash"`
+	ParentHash   common.Hash    `json:"parentHash"`
+	Timestamp    int64          `json:"timestamp"`
+	Miner        common.Address `json:"miner"`
+	GasUsed      uint64         `json:"gasUsed"`
+	GasLimit     uint64         `json:"gasLimit"`
+	BaseFee      *big.Int       `json:"baseFeePerGas"`
+	TxCount      int            `json:"txCount"`
+	TxGas        uint64         `json:"txGas"`
+	AvgGasPrice  *big.Int       `json:"avgGasPrice"`
+	Transactions []txRecord     `json:"transactions"`
+}
+
+// txRecord 区块中的一笔交易，也是 --format jsonl / csv 的一行
+type txRecord struct {
+	Block    uint64          `json:"block"`
+	Index    int             `json:"index"`
+	Hash     common.Hash     `json:"hash"`
+	Type     string          `json:"type"`
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to"`
+	Value    *big.Int        `json:"value"`
+	Gas      uint64          `json:"gas"`
+	GasPrice *big.Int        `json:"gasPrice"`
+}
+
+// 用法：
+//
+//	go run solutions/03-block-explorer.go                      # 交互输入区块号
+//	go run solutions/03-block-explorer.go 5671744
+//	go run solutions/03-block-explorer.go --format json latest | jq .txCount
+//	go run solutions/03-block-explorer.go --format csv 5671744 > txs.csv
 func main() {
+	format := output.FormatTable
+	flag.Var(&format, "format", output.FlagUsage)
+	flag.Parse()
+
 	// 从环境变量读取 API Key
 	apiKey := os.Getenv("INFURA_API_KEY")
 	if apiKey == "" {
@@ -27,77 +69,105 @@ func main() {
 	}
 	defer client.Close()
 
-	reader := bufio.NewReader(os.Stdin)
-
-	fmt.Print("请输入区块号 (输入 'latest' 获取最新): ")
-	input, _ := reader.ReadString('\n')
+	// 区块号可以作为参数传入；没有参数时交互输入，提示写到 stderr，不影响 stdout 上的结果
+	input := flag.Arg(0)
+	if input == "" {
+		fmt.Fprint(os.Stderr, "请输入区块号 (输入 'latest' 获取最新): ")
+		input, _ = bufio.NewReader(os.Stdin).ReadString('\n')
+	}
 	input = strings.TrimSpace(input)
 
+	// nil 表示最新区块
 	var blockNumber *big.Int
-	if input == "latest" {
-		header, err := client.HeaderByNumber(context.Background(), nil)
-		if err != nil {
-			log.Fatal(err)
-		}
-		blockNumber = header.Number
-	} else {
+	if input != "latest" {
 		num, err := strconv.ParseUint(input, 10, 64)
 		if err != nil {
 			log.Fatal("无效的区块号")
 		}
-		blockNumber = big.NewInt(int64(num))
+		blockNumber = new(big.Int).SetUint64(num)
 	}
 
 	// 获取区块
-	block, err := client.BlockByNumber(context.Background(), blockNumber)
+	block, err := chain.GetBlock(context.Background(), client, blockNumber)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	printBlockInfo(block)
+	if err := writeBlock(format, block); err != nil {
+		log.Fatal(err)
+	}
 }
 
-func printBlockInfo(block *types.Block) {
-	blockTime := time.Unix(int64(block.Time()), 0)
-	timeFormatted := blockTime.UTC().Format("2006-01-02 15:04:05 UTC")
-
-	fmt.Printf("\n=== 区块 %d ===\n", block.Number().Uint64())
-
-	fmt.Println("\n基本信息:")
-	fmt.Printf("  - 时间戳: %s\n", timeFormatted)
-	fmt.Printf("  - 矿工: %s\n", block.Coinbase().Hex())
-	fmt.Printf("  - Gas 使用: %d / %d (%.1f%%)\n",
-		block.GasUsed(),
-		block.GasLimit(),
-		float64(block.GasUsed())/float64(block.GasLimit())*100)
+// writeBlock 按格式输出：table 为文字报告，json 为整个区块，jsonl / csv 每笔交易一行
+func writeBlock(format output.Format, block *chain.Block) error {
+	switch format {
+	case output.FormatTable:
+		printBlockInfo(block)
+		return nil
+	case output.FormatJSON:
+		return output.WriteJSON(os.Stdout, newBlockRecord(block))
+	}
+	w := output.NewWriter(os.Stdout, format)
+	for _, tx := range newBlockRecord(block).Transactions {
+		if err := w.Write(tx); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
 
-	transactions := block.Transactions()
-	fmt.Printf("\n交易列表 (共 %d 笔):\n", len(transactions))
+func newBlockRecord(block *chain.Block) *blockRecord {
+	r := &blockRecord{
+		Number:       block.Number,
+		Hash:         block.Hash,
+		ParentHash:   block.ParentHash,
+		Timestamp:    block.Time.Unix(),
+		Miner:        block.Miner,
+		GasUsed:      block.GasUsed,
+		GasLimit:     block.GasLimit,
+		BaseFee:      block.BaseFee,
+		TxCount:      len(block.Transactions),
+		TxGas:        block.TxGas(),
+		AvgGasPrice:  block.AvgGasPrice(),
+		Transactions: make([]txRecord, len(block.Transactions)),
+	}
+	for i, tx := range block.Transactions {
+		r.Transactions[i] = txRecord{
+			Block:    block.Number,
+			Index:    i,
+			Hash:     tx.Hash,
+			Type:     tx.TypeName(),
+			From:     tx.From,
+			To:       tx.To,
+			Value:    tx.Value,
+			Gas:      tx.Gas,
+			GasPrice: tx.GasPrice,
+		}
+	}
+	return r
+}
 
-	totalGas := uint64(0)
-	totalGasPrice := big.NewInt(0)
+func printBlockInfo(block *chain.Block) {
+	fmt.Printf("\n=== 区块 %d ===\n", block.Number)
 
-	for i, tx := range transactions {
-		gasPrice := tx.GasPrice()
-		totalGas += tx.Gas()
-		totalGasPrice.Add(totalGasPrice, gasPrice)
+	fmt.Println("\n基本信息:")
+	fmt.Printf("  - 时间戳: %s\n", block.Time.Format("2006-01-02 15:04:05 UTC"))
+	fmt.Printf("  - 矿工: %s\n", block.Miner.Hex())
+	fmt.Printf("  - Gas 使用: %d / %d (%.1f%%)\n", block.GasUsed, block.GasLimit, block.GasUsage())
 
+	fmt.Printf("\n交易列表 (共 %d 笔):\n", len(block.Transactions))
+	for i, tx := range block.Transactions {
 		fmt.Printf("  [%2d] %s - Gas: %d - Price: %s Gwei\n",
 			i+1,
-			shortenHash(tx.Hash().Hex()),
-			tx.Gas(),
-			formatGasPrice(gasPrice))
-	}
-
-	avgGasPrice := big.NewInt(0)
-	if len(transactions) > 0 {
-		avgGasPrice.Div(totalGasPrice, big.NewInt(int64(len(transactions))))
+			shortenHash(tx.Hash.Hex()),
+			tx.Gas,
+			formatGasPrice(tx.GasPrice))
 	}
 
 	fmt.Println("\n统计:")
-	fmt.Printf("  - 总交易: %d\n", len(transactions))
-	fmt.Printf("  - 总 Gas 使用: %s\n", formatNumber(totalGas))
-	fmt.Printf("  - 平均 Gas 价格: %s Gwei\n", formatGasPrice(avgGasPrice))
+	fmt.Printf("  - 总交易: %d\n", len(block.Transactions))
+	fmt.Printf("  - 总 Gas 使用: %s\n", formatNumber(block.TxGas()))
+	fmt.Printf("  - 平均 Gas 价格: %s Gwei\n", formatGasPrice(block.AvgGasPrice()))
 }
 
 func shortenHash(hash string) string {
@@ -74,6 +74,10 @@ func main() {
 	//         contractCreations++
 	//     }
 	//
+	//     // TODO: 统计 blob 交易（tx.Type() == types.BlobTxType）
+	//     // blob 数量: len(tx.BlobHashes())
+	//     // blob 费用: receipt.BlobGasUsed × receipt.BlobGasPrice（非 blob 交易的 BlobGasPrice 为 nil）
+	//
 	//     // TODO: 记录交易费用用于排序
 	//     txFees = append(txFees, &TxFee{
 	//         Hash: tx.Hash().Hex(),
@@ -32,13 +32,6 @@ func main() {
 		log.Fatal(err)
 	}
 
-	// 获取链 ID
-	chainID, err := client.ChainID(context.Background())
-	if err != nil {
-		log.Fatal(err)
-	}
-	signer := types.NewEIP155Signer(chainID)
-
 	transactions := block.Transactions()
 
 	// 定义统计变量
@@ -50,6 +43,13 @@ func main() {
 	failCount := 0
 	contractCreations := 0
 
+	// blob 交易（EIP-4844）统计：blob 费用按独立的 blob gas 市场计价，与执行 Gas 分开
+	blobTxs := 0
+	totalBlobs := 0
+	totalBlobGasUsed := uint64(0)
+	totalBlobFee := big.NewInt(0)
+	var blobGasPrice *big.Int // 同一区块内所有 blob 交易的价格相同，取自收据
+
 	txFees := make([]*TxFee, 0)
 
 	// 遍历所有交易进行统计
@@ -70,8 +70,15 @@ func main() {
 				failCount++
 			}
 
-			// 计算实际交易费用
+			// 计算实际交易费用，blob 交易还要加上 blob 费用
 			actualFee := new(big.Int).Mul(big.NewInt(int64(receipt.GasUsed)), tx.GasPrice())
+			if receipt.BlobGasPrice != nil {
+				blobFee := new(big.Int).Mul(new(big.Int).SetUint64(receipt.BlobGasUsed), receipt.BlobGasPrice)
+				actualFee.Add(actualFee, blobFee)
+				totalBlobGasUsed += receipt.BlobGasUsed
+				totalBlobFee.Add(totalBlobFee, blobFee)
+				blobGasPrice = receipt.BlobGasPrice
+			}
 			txFees = append(txFees, &TxFee{
 				Hash: tx.Hash().Hex(),
 				Fee:  actualFee,
@@ -82,6 +89,12 @@ func main() {
 		if tx.To() == nil {
 			contractCreations++
 		}
+
+		// blob 交易的每个版本化哈希对应一个 blob
+		if tx.Type() == types.BlobTxType {
+			blobTxs++
+			totalBlobs += len(tx.BlobHashes())
+		}
 	}
 
 	// 排序找出最贵的交易
@@ -94,7 +107,7 @@ func main() {
 	if totalTxs > 0 {
 		avgGasPrice = new(big.Float).Quo(
 			new(big.Float).SetInt(totalGasPrice),
-			big.NewFloat(int64(totalTxs)),
+			big.NewFloat(float64(totalTxs)),
 		)
 	}
 
@@ -121,6 +134,20 @@ func main() {
 	fmt.Printf("  失败: %d\n", failCount)
 	fmt.Printf("  合约创建: %d\n", contractCreations)
 
+	if blobTxs > 0 {
+		totalBlobFeeEther := new(big.Float).Quo(
+			new(big.Float).SetInt(totalBlobFee),
+			big.NewFloat(1e18),
+		)
+		fmt.Println("\nBlob 统计:")
+		fmt.Printf("  Blob 交易: %d（共 %d 个 blob）\n", blobTxs, totalBlobs)
+		fmt.Printf("  Blob Gas 使用: %d\n", totalBlobGasUsed)
+		if blobGasPrice != nil {
+			fmt.Printf("  Blob Gas 价格: %s wei\n", blobGasPrice)
+		}
+		fmt.Printf("  Blob 费用: %.9f Ether\n", totalBlobFeeEther)
+	}
+
 	fmt.Println("\n最贵的 3 笔交易:")
 	for i, tx := range txFees {
 		if i >= 3 {
@@ -0,0 +1,410 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/dapp-learning/ethclient/util/sigdb"
+)
+
+// 交易分类
+const (
+	KindTransfer      = "ETH 转账"
+	KindCreation      = "合约创建"
+	KindERC20Transfer = "ERC20 transfer"
+	KindERC20Approve  = "ERC20 approve"
+	KindERC20From     = "ERC20 transferFrom"
+	KindKnownCall     = "合约调用"
+	KindUnknownCall   = "未知调用"
+	KindSetCode       = "EIP-7702 授权"
+)
+
+// DecodedArg 解码后的参数
+type DecodedArg struct {
+	Name  string
+	Type  string
+	Value string
+}
+
+// TxClass 交易分类结果
+type TxClass struct {
+	Hash     common.Hash
+	From     common.Address
+	To       *common.Address
+	Kind     string
+	Method   string // 函数签名，如 transfer(address,uint256)
+	Selector string
+	Args     []DecodedArg
+	Status   uint64
+	GasUsed  uint64
+	Fee      *big.Int // GasUsed × EffectiveGasPrice
+	Burned   *big.Int // GasUsed × BaseFee（仅 EIP-1559 及之后的交易）
+	Tip      *big.Int // Fee - Burned，支付给验证者
+
+	Delegations []string // 类型 4 交易携带的授权，格式为 "授权账户 → 委托合约"
+}
+
+// Classifier 根据 calldata 对交易分类，并使用签名数据库解码参数
+type Classifier struct {
+	sigs *sigdb.Registry
+}
+
+func main() {
+	blockFlag := flag.Int64("block", 5671744, "要分析的区块号")
+	txFlag := flag.String("tx", "", "只分析指定的交易哈希（多个用逗号分隔）")
+	sigsFlag := flag.String("sigs", "", "额外加载的 ABI / 编译产物 / 文本签名文件（多个用逗号分隔）")
+	dbFlag := flag.String("db", "", "持久化的签名数据库文件，加载 --sigs 后会写回")
+	flag.Parse()
+
+	// 从环境变量读取 API Key
+	apiKey := os.Getenv("INFURA_API_KEY")
+	if apiKey == "" {
+		log.Fatal("错误: 请设置环境变量 INFURA_API_KEY\n例如: export INFURA_API_KEY=your-key-here")
+	}
+	client, err := ethclient.Dial("https://sepolia.infura.io/v3/" + apiKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	registry, err := loadRegistry(*dbFlag, *sigsFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("签名数据库: %d 条签名\n", registry.Len())
+	classifier := &Classifier{sigs: registry}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	signer := types.LatestSignerForChainID(chainID)
+
+	var results []*TxClass
+	if *txFlag != "" {
+		for _, h := range strings.Split(*txFlag, ",") {
+			result, err := classifyByHash(ctx, client, classifier, signer, common.HexToHash(strings.TrimSpace(h)))
+			if err != nil {
+				log.Fatal(err)
+			}
+			results = append(results, result)
+		}
+	} else {
+		results, err = classifyBlock(ctx, client, classifier, signer, big.NewInt(*blockFlag))
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	printResults(results)
+}
+
+// loadRegistry 打开签名数据库（默认包含常用签名），合并额外的签名文件，
+// 如果指定了 dbPath 则写回磁盘，下次运行无需再次加载
+func loadRegistry(dbPath, files string) (*sigdb.Registry, error) {
+	registry := sigdb.NewDefault()
+	if dbPath != "" {
+		var err error
+		if registry, err = sigdb.Open(dbPath); err != nil {
+			return nil, err
+		}
+	}
+
+	added := 0
+	for _, path := range strings.Split(files, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		n, err := registry.LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		added += n
+	}
+
+	if dbPath != "" && added > 0 {
+		if err := registry.Save(dbPath); err != nil {
+			return nil, err
+		}
+		fmt.Printf("新增 %d 条签名，已保存到 %s\n", added, dbPath)
+	}
+	return registry, nil
+}
+
+// Classify 根据 To 和 calldata 判断交易类型，并尽可能解码参数
+func (c *Classifier) Classify(tx *types.Transaction) *TxClass {
+	result := &TxClass{Hash: tx.Hash(), To: tx.To()}
+	data := tx.Data()
+
+	// 类型 4 交易除了普通调用之外还携带授权列表，不带 calldata 时只是设置委托
+	if tx.Type() == types.SetCodeTxType {
+		result.Delegations = formatDelegations(tx.SetCodeAuthorizations())
+		if len(data) == 0 {
+			result.Kind = KindSetCode
+			return result
+		}
+	}
+
+	switch {
+	case tx.To() == nil:
+		result.Kind = KindCreation
+		return result
+	case len(data) == 0:
+		result.Kind = KindTransfer
+		return result
+	case len(data) < 4:
+		result.Kind = KindUnknownCall
+		return result
+	}
+
+	result.Selector = hexutil.Encode(data[:4])
+
+	entry, args, err := c.sigs.ResolveFunction(data)
+	if err != nil {
+		result.Kind = KindUnknownCall
+		return result
+	}
+
+	result.Method = entry.Signature
+	result.Args = formatArgs(entry.Method().Inputs, args)
+	switch entry.Signature {
+	case "transfer(address,uint256)":
+		result.Kind = KindERC20Transfer
+	case "approve(address,uint256)":
+		result.Kind = KindERC20Approve
+	case "transferFrom(address,address,uint256)":
+		result.Kind = KindERC20From
+	default:
+		result.Kind = KindKnownCall
+	}
+	return result
+}
+
+// formatDelegations 恢复每条授权的签名者。签名无效的授权会被节点跳过，这里标记出来；
+// 委托给零地址表示撤销
+func formatDelegations(auths []types.SetCodeAuthorization) []string {
+	list := make([]string, len(auths))
+	for i, auth := range auths {
+		authority := "<签名无效>"
+		if addr, err := auth.Authority(); err == nil {
+			authority = addr.Hex()
+		}
+		delegate := auth.Address.Hex()
+		if auth.Address == (common.Address{}) {
+			delegate = "<撤销委托>"
+		}
+		list[i] = fmt.Sprintf("%s → %s（nonce %d）", authority, delegate, auth.Nonce)
+	}
+	return list
+}
+
+// classifyBlock 分析整个区块，使用 BlockReceipts 一次取回所有收据
+func classifyBlock(ctx context.Context, client *ethclient.Client, c *Classifier, signer types.Signer, number *big.Int) ([]*TxClass, error) {
+	block, err := client.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := client.BlockReceipts(ctx, rpc.BlockNumberOrHashWithHash(block.Hash(), false))
+	if err != nil {
+		return nil, err
+	}
+
+	receiptByHash := make(map[common.Hash]*types.Receipt, len(receipts))
+	for _, r := range receipts {
+		receiptByHash[r.TxHash] = r
+	}
+
+	results := make([]*TxClass, 0, len(block.Transactions()))
+	for _, tx := range block.Transactions() {
+		result := c.Classify(tx)
+		if from, err := types.Sender(signer, tx); err == nil {
+			result.From = from
+		}
+		if receipt, ok := receiptByHash[tx.Hash()]; ok {
+			applyReceipt(result, tx, receipt, block.BaseFee())
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// classifyByHash 分析单笔交易
+func classifyByHash(ctx context.Context, client *ethclient.Client, c *Classifier, signer types.Signer, hash common.Hash) (*TxClass, error) {
+	tx, isPending, err := client.TransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("查询交易 %s 失败: %w", hash.Hex(), err)
+	}
+
+	result := c.Classify(tx)
+	if from, err := types.Sender(signer, tx); err == nil {
+		result.From = from
+	}
+	if isPending {
+		return result, nil
+	}
+
+	receipt, err := client.TransactionReceipt(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("获取交易收据失败: %w", err)
+	}
+	header, err := client.HeaderByHash(ctx, receipt.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	applyReceipt(result, tx, receipt, header.BaseFee)
+	return result, nil
+}
+
+// applyReceipt 根据收据中的 EffectiveGasPrice 计算实际费用，
+// 对 EIP-1559 交易拆分为销毁部分和小费部分
+func applyReceipt(result *TxClass, tx *types.Transaction, receipt *types.Receipt, baseFee *big.Int) {
+	gasUsed := new(big.Int).SetUint64(receipt.GasUsed)
+
+	result.Status = receipt.Status
+	result.GasUsed = receipt.GasUsed
+
+	gasPrice := receipt.EffectiveGasPrice
+	if gasPrice == nil {
+		// 旧节点可能不返回 effectiveGasPrice，按区块的 BaseFee 计算：
+		// min(GasFeeCap, BaseFee + GasTipCap)。legacy 交易两者都等于 GasPrice，结果不变
+		gasPrice = effectiveGasPrice(tx, baseFee)
+	}
+	result.Fee = new(big.Int).Mul(gasUsed, gasPrice)
+
+	if tx.Type() >= types.DynamicFeeTxType && baseFee != nil {
+		result.Burned = new(big.Int).Mul(gasUsed, baseFee)
+		result.Tip = new(big.Int).Sub(result.Fee, result.Burned)
+	}
+}
+
+// effectiveGasPrice 交易实际支付的单价。tx.GasPrice() 对 EIP-1559 交易返回 GasFeeCap，
+// 是上限而不是实际价格；London 之前的区块没有 BaseFee，只能使用 GasPrice
+func effectiveGasPrice(tx *types.Transaction, baseFee *big.Int) *big.Int {
+	if baseFee == nil {
+		return tx.GasPrice()
+	}
+	price := new(big.Int).Add(baseFee, tx.GasTipCap())
+	if price.Cmp(tx.GasFeeCap()) > 0 {
+		price.Set(tx.GasFeeCap())
+	}
+	return price
+}
+
+// formatArgs 将解码后的参数与 ABI 定义对应起来
+func formatArgs(inputs abi.Arguments, values []interface{}) []DecodedArg {
+	args := make([]DecodedArg, len(values))
+	for i, v := range values {
+		name := inputs[i].Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		args[i] = DecodedArg{Name: name, Type: inputs[i].Type.String(), Value: formatValue(v)}
+	}
+	return args
+}
+
+// formatValue 将解码结果格式化为可读字符串
+func formatValue(v interface{}) string {
+	switch val := v.(type) {
+	case common.Address:
+		return val.Hex()
+	case *big.Int:
+		return val.String()
+	case []byte:
+		return hexutil.Encode(val)
+	case [32]byte:
+		return hexutil.Encode(val[:])
+	case []common.Address:
+		parts := make([]string, len(val))
+		for i, a := range val {
+			parts[i] = a.Hex()
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	case [][]byte:
+		parts := make([]string, len(val))
+		for i, b := range val {
+			parts[i] = hexutil.Encode(b)
+		}
+		return "[" + strings.Join(parts, ", ") + "]"
+	default:
+		return fmt.Sprintf("%v", val)
+	}
+}
+
+// weiToEther 将 Wei 转换为 Ether
+func weiToEther(wei *big.Int) *big.Float {
+	return new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e18))
+}
+
+// printResults 打印每笔交易的分类结果和汇总
+func printResults(results []*TxClass) {
+	fmt.Println("=== 交易分类 ===")
+
+	kindCount := make(map[string]int)
+	totalFee, totalBurned, totalTip := new(big.Int), new(big.Int), new(big.Int)
+
+	for i, r := range results {
+		kindCount[r.Kind]++
+
+		to := "<合约创建>"
+		if r.To != nil {
+			to = r.To.Hex()
+		}
+		fmt.Printf("\n[%d] %s\n", i+1, r.Hash.Hex())
+		fmt.Printf("  类型: %s\n", r.Kind)
+		fmt.Printf("  %s → %s\n", r.From.Hex(), to)
+		if r.Method != "" {
+			fmt.Printf("  方法: %s\n", r.Method)
+		} else if r.Selector != "" {
+			fmt.Printf("  选择器: %s（未在签名库中找到）\n", r.Selector)
+		}
+		for _, arg := range r.Args {
+			fmt.Printf("    %s (%s): %s\n", arg.Name, arg.Type, arg.Value)
+		}
+		for _, d := range r.Delegations {
+			fmt.Printf("  授权: %s\n", d)
+		}
+
+		if r.Fee == nil {
+			fmt.Println("  状态: 待处理")
+			continue
+		}
+		totalFee.Add(totalFee, r.Fee)
+		fmt.Printf("  状态: %d，Gas 使用: %d\n", r.Status, r.GasUsed)
+		fmt.Printf("  手续费: %.8f ETH\n", weiToEther(r.Fee))
+		if r.Burned != nil {
+			totalBurned.Add(totalBurned, r.Burned)
+			totalTip.Add(totalTip, r.Tip)
+			fmt.Printf("    销毁: %.8f ETH，小费: %.8f ETH\n", weiToEther(r.Burned), weiToEther(r.Tip))
+		}
+	}
+
+	fmt.Println("\n=== 汇总 ===")
+	fmt.Printf("交易总数: %d\n", len(results))
+	kinds := make([]string, 0, len(kindCount))
+	for k := range kindCount {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	for _, k := range kinds {
+		fmt.Printf("  %s: %d\n", k, kindCount[k])
+	}
+	fmt.Printf("总手续费: %.8f ETH（销毁 %.8f，小费 %.8f）\n",
+		weiToEther(totalFee), weiToEther(totalBurned), weiToEther(totalTip))
+}
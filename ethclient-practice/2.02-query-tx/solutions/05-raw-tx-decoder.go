@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/sigdb"
+)
+
+// 用法：
+//
+//	go run solutions/05-raw-tx-decoder.go 0x02f873...
+//	echo 0x02f873... | go run solutions/05-raw-tx-decoder.go
+//	go run solutions/05-raw-tx-decoder.go --chain-id 11155111 --nonce 5 0x02f873...
+//	go run solutions/05-raw-tx-decoder.go --online 0x02f873...   # 从节点读取链 ID、nonce 和余额
+func main() {
+	chainIDFlag := flag.String("chain-id", "", "检查交易是否属于这条链")
+	nonceFlag := flag.Int64("nonce", -1, "检查交易 nonce 是否等于账户当前 nonce，-1 表示不检查")
+	online := flag.Bool("online", false, "从节点读取链 ID、发送方的 pending nonce 和余额（需要 INFURA_API_KEY）")
+	sigsFlag := flag.String("sigs", "", "额外加载的 ABI / 编译产物 / 文本签名文件（多个用逗号分隔）")
+	flag.Parse()
+
+	input, err := readInput(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// 解码交易。legacy 交易是 RLP 列表，其他类型以类型字节开头
+	rawTx, err := chain.DecodeRawTxHex(input)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	registry := sigdb.NewDefault()
+	for _, path := range strings.Split(*sigsFlag, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			if _, err := registry.LoadFile(path); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+
+	fmt.Println("=== 交易内容 ===")
+	rawTx.WriteReport(os.Stdout, registry)
+
+	// 检查条件：命令行参数优先，--online 时从节点补全
+	var chainID *big.Int
+	if *chainIDFlag != "" {
+		var ok bool
+		if chainID, ok = new(big.Int).SetString(*chainIDFlag, 10); !ok {
+			log.Fatalf("无效的链 ID: %s", *chainIDFlag)
+		}
+	}
+	var nonce *uint64
+	if *nonceFlag >= 0 {
+		n := uint64(*nonceFlag)
+		nonce = &n
+	}
+	var balance *big.Int
+	if *online {
+		chainID, nonce, balance, err = queryNode(chainID, nonce, rawTx)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	fmt.Println("\n=== 有效性检查 ===")
+	checks := rawTx.Check(chainID, nonce)
+	if balance != nil {
+		cost := rawTx.MaxCost()
+		checks = append(checks, chain.TxCheck{
+			Name:   "余额",
+			OK:     balance.Cmp(cost) >= 0,
+			Detail: fmt.Sprintf("%s ETH，最多花费 %s ETH", chain.FormatEther(balance), chain.FormatEther(cost)),
+		})
+	}
+	if !chain.WriteChecks(os.Stdout, checks) {
+		os.Exit(1)
+	}
+}
+
+// readInput 读取命令行参数中的交易；没有参数或参数为 - 时从标准输入读取
+func readInput(arg string) (string, error) {
+	if arg != "" && arg != "-" {
+		return arg, nil
+	}
+	raw, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", err
+	}
+	if s := strings.TrimSpace(string(raw)); s != "" {
+		return s, nil
+	}
+	return "", fmt.Errorf("请传入十六进制的签名交易，或从标准输入读取")
+}
+
+// queryNode 从节点读取没有在命令行指定的链 ID 和 nonce，以及发送方余额
+func queryNode(chainID *big.Int, nonce *uint64, rawTx *chain.RawTx) (*big.Int, *uint64, *big.Int, error) {
+	apiKey := os.Getenv("INFURA_API_KEY")
+	if apiKey == "" {
+		return nil, nil, nil, fmt.Errorf("--online 需要设置环境变量 INFURA_API_KEY")
+	}
+	client, err := ethclient.Dial("https://sepolia.infura.io/v3/" + apiKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if chainID == nil {
+		if chainID, err = client.ChainID(ctx); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if rawTx.SenderErr != nil {
+		return chainID, nonce, nil, nil
+	}
+	if nonce == nil {
+		n, err := client.PendingNonceAt(ctx, rawTx.From)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		nonce = &n
+	}
+	balance, err := client.BalanceAt(ctx, rawTx.From, nil)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return chainID, nonce, balance, nil
+}
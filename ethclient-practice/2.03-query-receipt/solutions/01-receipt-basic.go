@@ -8,6 +8,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/chain"
 )
 
 func main() {
@@ -25,11 +27,15 @@ func main() {
 	// 交易哈希示例
 	txHash := common.HexToHash("0x20294a03e8766e9aeab58327fc4112756017c6c28f6f99c7722f4a29075601c5")
 
-	// 查询交易收据
-	receipt, err := client.TransactionReceipt(context.Background(), txHash)
+	// 查询交易收据。收据本身不含 From / To，一并查询交易
+	status, err := chain.GetTxStatus(context.Background(), client, txHash)
 	if err != nil {
 		log.Fatal(err)
 	}
+	receipt := status.Receipt
+	if receipt == nil {
+		log.Fatal("交易尚未打包，还没有收据")
+	}
 
 	fmt.Println("=== 交易收据信息 ===")
 	fmt.Printf("交易哈希: %s\n", receipt.TxHash.Hex())
@@ -37,24 +43,24 @@ func main() {
 	fmt.Printf("区块哈希: %s\n", receipt.BlockHash.Hex())
 
 	// 显示收据信息
-	status := "失败"
-	if receipt.Status == 1 {
-		status = "成功"
+	result := "失败"
+	if receipt.Success() {
+		result = "成功"
 	}
-	fmt.Printf("状态: %s\n", status)
+	fmt.Printf("状态: %s\n", result)
 	fmt.Printf("Gas 使用: %d\n", receipt.GasUsed)
 	fmt.Printf("累计 Gas: %d\n", receipt.CumulativeGasUsed)
 	fmt.Printf("交易索引: %d\n", receipt.TransactionIndex)
 
 	// 判断是否为合约创建交易
-	if receipt.ContractAddress != (common.Address{}) {
+	if receipt.ContractAddress != nil {
 		fmt.Printf("创建的合约地址: %s\n", receipt.ContractAddress.Hex())
 	} else {
 		fmt.Println("非合约创建交易")
 	}
 
-	fmt.Printf("From: %s\n", receipt.From.Hex())
-	if receipt.To != nil {
-		fmt.Printf("To: %s\n", receipt.To.Hex())
+	fmt.Printf("From: %s\n", status.Tx.From.Hex())
+	if status.Tx.To != nil {
+		fmt.Printf("To: %s\n", status.Tx.To.Hex())
 	}
 }
@@ -9,6 +9,8 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/sigdb"
 )
 
 func main() {
@@ -23,6 +25,9 @@ func main() {
 	}
 	defer client.Close()
 
+	// 本地签名数据库，用于把 topic0 还原为事件签名
+	sigs := sigdb.NewDefault()
+
 	// 一个包含事件日志的交易哈希（USDT 转账）
 	txHash := common.HexToHash("0x6c0a9e58ec065b56c1ddf4cfeae63c5719bed2ae4b46c3c64fe1ca9e8e8987a6")
 
@@ -60,6 +65,9 @@ func main() {
 		// 显示第一个主题（通常是事件签名）
 		if len(log.Topics) > 0 {
 			fmt.Printf("  事件签名: %s\n", log.Topics[0].Hex())
+			if event, err := sigs.ResolveEvent(log); err == nil {
+				fmt.Printf("  事件: %s\n", event.Signature)
+			}
 		}
 		fmt.Println()
 	}
@@ -2,18 +2,59 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"math/big"
 	"os"
-	"sort"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/output"
 )
 
+// receiptRecord 一笔交易的收据，也是 --format jsonl / csv 的一行。
+// effectiveGasPrice 和 fee 为 wei 的十进制字符串
+type receiptRecord struct {
+	Block             uint64          `json:"block"`
+	Index             uint            `json:"index"`
+	TxHash            common.Hash     `json:"txHash"`
+	Status            uint64          `json:"status"`
+	GasUsed           uint64          `json:"gasUsed"`
+	CumulativeGasUsed uint64          `json:"cumulativeGasUsed"`
+	EffectiveGasPrice *big.Int        `json:"effectiveGasPrice"`
+	Fee               *big.Int        `json:"fee"`
+	ContractAddress   *common.Address `json:"contractAddress"`
+	Logs              int             `json:"logs"`
+}
+
+// statsRecord --format json 输出的区块统计
+type statsRecord struct {
+	Block             uint64          `json:"block"`
+	Total             int             `json:"total"`
+	Success           int             `json:"success"`
+	Failed            int             `json:"failed"`
+	ContractCreations int             `json:"contractCreations"`
+	GasUsed           uint64          `json:"gasUsed"`
+	AvgGasUsed        uint64          `json:"avgGasUsed"`
+	TopGas            []receiptRecord `json:"topGas"`
+	Receipts          []receiptRecord `json:"receipts"`
+}
+
+// 用法：
+//
+//	go run solutions/03-receipt-batch.go
+//	go run solutions/03-receipt-batch.go --block 5671744 --format json | jq .failed
+//	go run solutions/03-receipt-batch.go --format csv > receipts.csv
 func main() {
+	block := flag.Uint64("block", 5671744, "区块号")
+	top := flag.Int("top", 3, "列出 Gas 使用最多的交易数")
+	format := output.FormatTable
+	flag.Var(&format, "format", output.FlagUsage)
+	flag.Parse()
+
 	// 从环境变量读取 API Key
 	apiKey := os.Getenv("INFURA_API_KEY")
 	if apiKey == "" {
@@ -25,83 +66,95 @@ func main() {
 	}
 	defer client.Close()
 
-	blockNumber := big.NewInt(5671744)
+	blockNumber := new(big.Int).SetUint64(*block)
 
-	// 查询指定区块的所有收据
-	receipts, err := client.BlockReceipts(
-		context.Background(),
-		rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(blockNumber.Int64())),
-	)
+	// 查询指定区块的所有收据（节点支持时使用 eth_getBlockReceipts 一次取回）
+	receipts, err := chain.GetBlockReceipts(context.Background(), client, blockNumber)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	fmt.Println("=== 区块收据统计 ===")
-
-	// 定义统计变量
-	totalCount := len(receipts)
-	successCount := 0
-	failCount := 0
-	totalGasUsed := uint64(0)
-	contractCreations := 0
-
-	// 定义 Gas 使用记录（用于找出最大值）
-	type GasRecord struct {
-		TxHash  string
-		GasUsed uint64
-	}
-	gasRecords := make([]*GasRecord, 0)
-
-	// 遍历所有收据进行统计
-	for _, receipt := range receipts {
-		totalGasUsed += receipt.GasUsed
-
-		// 统计成功/失败
-		if receipt.Status == 1 {
-			successCount++
-		} else {
-			failCount++
+	// 统计成功/失败、Gas 使用和合约创建，保留 Gas 使用最多的几笔
+	stats := chain.SummarizeReceipts(receipts, *top)
+
+	switch format {
+	case output.FormatTable:
+		printStats(blockNumber, stats)
+	case output.FormatJSON:
+		err = output.WriteJSON(os.Stdout, newStatsRecord(blockNumber.Uint64(), stats, receipts))
+	default:
+		// jsonl / csv 每笔收据一行，统计可以由下游自行汇总
+		w := output.NewWriter(os.Stdout, format)
+		for _, r := range receipts {
+			if err = w.Write(newReceiptRecord(r)); err != nil {
+				break
+			}
 		}
-
-		// 统计合约创建
-		if receipt.ContractAddress != (common.Address{}) {
-			contractCreations++
+		if err == nil {
+			err = w.Flush()
 		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
 
-		// 记录 Gas 使用
-		gasRecords = append(gasRecords, &GasRecord{
-			TxHash:  receipt.TxHash.Hex(),
-			GasUsed: receipt.GasUsed,
-		})
+func newReceiptRecord(r *chain.Receipt) receiptRecord {
+	return receiptRecord{
+		Block:             r.BlockNumber,
+		Index:             r.TransactionIndex,
+		TxHash:            r.TxHash,
+		Status:            r.Status,
+		GasUsed:           r.GasUsed,
+		CumulativeGasUsed: r.CumulativeGasUsed,
+		EffectiveGasPrice: r.EffectiveGasPrice,
+		Fee:               r.Fee(),
+		ContractAddress:   r.ContractAddress,
+		Logs:              len(r.Logs),
 	}
+}
 
-	// 排序找出 Gas 使用最多的交易
-	sort.Slice(gasRecords, func(i, j int) bool {
-		return gasRecords[i].GasUsed > gasRecords[j].GasUsed
-	})
+func newStatsRecord(block uint64, stats *chain.ReceiptStats, receipts []*chain.Receipt) *statsRecord {
+	s := &statsRecord{
+		Block:             block,
+		Total:             stats.Total,
+		Success:           stats.Success,
+		Failed:            stats.Failed,
+		ContractCreations: stats.ContractCreations,
+		GasUsed:           stats.GasUsed,
+		AvgGasUsed:        stats.AvgGasUsed(),
+		TopGas:            make([]receiptRecord, len(stats.TopGas)),
+		Receipts:          make([]receiptRecord, len(receipts)),
+	}
+	for i, r := range stats.TopGas {
+		s.TopGas[i] = newReceiptRecord(r)
+	}
+	for i, r := range receipts {
+		s.Receipts[i] = newReceiptRecord(r)
+	}
+	return s
+}
 
+func printStats(blockNumber *big.Int, stats *chain.ReceiptStats) {
 	// 输出统计结果
+	fmt.Println("=== 区块收据统计 ===")
 	fmt.Printf("区块号: %d\n", blockNumber.Uint64())
-	fmt.Printf("总收据数: %d\n\n", totalCount)
+	fmt.Printf("总收据数: %d\n\n", stats.Total)
 
 	fmt.Println("交易状态:")
-	fmt.Printf("  成功: %d\n", successCount)
-	fmt.Printf("  失败: %d\n\n", failCount)
+	fmt.Printf("  成功: %d\n", stats.Success)
+	fmt.Printf("  失败: %d\n\n", stats.Failed)
 
 	fmt.Println("Gas 统计:")
-	fmt.Printf("  总 Gas 使用: %d\n", totalGasUsed)
-	if totalCount > 0 {
-		avgGas := totalGasUsed / uint64(totalCount)
-		fmt.Printf("  平均 Gas 使用: %d\n", avgGas)
+	fmt.Printf("  总 Gas 使用: %d\n", stats.GasUsed)
+	if stats.Total > 0 {
+		fmt.Printf("  平均 Gas 使用: %d\n", stats.AvgGasUsed())
 	}
-	fmt.Printf("  合约创建: %d\n\n", contractCreations)
+	fmt.Printf("  合约创建: %d\n\n", stats.ContractCreations)
 
-	fmt.Println("Gas 使用最多的 3 笔交易:")
-	for i, record := range gasRecords {
-		if i >= 3 {
-			break
-		}
-		fmt.Printf("  %d. %s - %d Gas\n", i+1, shortenHash(record.TxHash), record.GasUsed)
+	fmt.Printf("Gas 使用最多的 %d 笔交易:\n", len(stats.TopGas))
+	for i, receipt := range stats.TopGas {
+		fmt.Printf("  %d. %s - %d Gas\n", i+1, shortenHash(receipt.TxHash.Hex()), receipt.GasUsed)
 	}
 }
 
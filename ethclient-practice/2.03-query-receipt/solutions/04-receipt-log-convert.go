@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/logdecode"
+	"github.com/dapp-learning/ethclient/util/output"
+)
+
+func main() {
+	txFlag := flag.String("tx", "0xb7cedb112cb9b246faed99ffdfd8bfcdca9215e32dc2b4d006d7afc529a0c625", "交易哈希")
+	abiFlag := flag.String("abi", "", "额外加载的 ABI / 编译产物 / 签名文件，多个用逗号分隔")
+	jsonFlag := flag.Bool("json", false, "以 JSON 输出解码结果")
+	flag.Parse()
+
+	// 从环境变量读取 API Key
+	apiKey := os.Getenv("INFURA_API_KEY")
+	if apiKey == "" {
+		log.Fatal("错误: 请设置环境变量 INFURA_API_KEY\n例如: export INFURA_API_KEY=your-key-here")
+	}
+	client, err := ethclient.Dial("https://sepolia.infura.io/v3/" + apiKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	// 默认签名库已包含 BidPlaced、ERC20/ERC721 等常用事件，再加载用户指定的 ABI
+	var paths []string
+	for _, path := range strings.Split(*abiFlag, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	decoder, err := logdecode.NewWithABIs(paths...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	receipt, err := client.TransactionReceipt(context.Background(), common.HexToHash(*txFlag))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	records, failed := decoder.DecodeAll(receipt.Logs)
+
+	if *jsonFlag {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(records); err != nil {
+			log.Fatal(err)
+		}
+		printFailed(os.Stderr, failed)
+		return
+	}
+
+	fmt.Println("=== 事件日志解码 ===")
+	fmt.Printf("交易哈希: %s\n", receipt.TxHash.Hex())
+	fmt.Printf("日志数量: %d，解码成功: %d\n\n", len(receipt.Logs), len(records))
+
+	for _, record := range records {
+		fmt.Printf("[日志 %d] %s\n", record.Position.LogIndex, record.Signature)
+		fmt.Printf("  合约地址: %s\n", record.Address.Hex())
+		fmt.Printf("  区块: %d  交易索引: %d\n", record.Position.BlockNumber, record.Position.TxIndex)
+		if record.Anonymous {
+			fmt.Println("  匿名事件")
+		}
+		for _, arg := range record.Args {
+			tag := ""
+			if arg.Indexed {
+				tag = " (indexed)"
+			}
+			if arg.Hashed {
+				tag = " (indexed，仅哈希)"
+			}
+			value, _ := json.Marshal(output.Normalize(arg.Value))
+			fmt.Printf("    %-12s %-10s %s%s\n", arg.Name, arg.Type, value, tag)
+		}
+		fmt.Println()
+	}
+	printFailed(os.Stdout, failed)
+}
+
+// printFailed 输出无法解码的日志
+func printFailed(w *os.File, failed map[int]error) {
+	if len(failed) == 0 {
+		return
+	}
+	idx := make([]int, 0, len(failed))
+	for i := range failed {
+		idx = append(idx, i)
+	}
+	sort.Ints(idx)
+	fmt.Fprintf(w, "未能解码的日志 %d 条（可用 --abi 加载对应合约的 ABI）:\n", len(failed))
+	for _, i := range idx {
+		fmt.Fprintf(w, "  logs[%d]: %v\n", i, failed[i])
+	}
+}
@@ -0,0 +1,99 @@
+// 04-delegation.go - EIP-7702 授权：让钱包地址委托合约代码 - 标准答案
+//
+// 运行：go run solutions/04-delegation.go
+//
+// 可选环境变量：
+//   PRIVATE_KEY  使用已有私钥，为空时生成新钱包
+//   RPC_URL      查询账户当前的代码，判断是否已经委托
+
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+)
+
+func main() {
+	chainID := flag.Uint64("chain-id", 11155111, "授权绑定的链 ID，0 表示所有链（有重放风险）")
+	implHex := flag.String("impl", "0x0000000000000000000000000000000000001234", "实现合约地址（默认值仅作演示），零地址表示撤销委托")
+	nonce := flag.Uint64("nonce", 0, "授权执行时账户的 nonce")
+	flag.Parse()
+
+	fmt.Println("=== EIP-7702 授权 ===")
+
+	// 步骤 1: 加载或生成钱包
+	privateKey, err := loadKey()
+	if err != nil {
+		log.Fatal(err)
+	}
+	address := crypto.PubkeyToAddress(privateKey.PublicKey)
+	fmt.Printf("钱包地址: %s\n", address.Hex())
+
+	// 步骤 2: 签名授权
+	// 签名的内容是 keccak256(0x05 || rlp([chainId, address, nonce]))，0x05 是 EIP-7702 的域前缀，
+	// 保证授权签名不会和交易签名（0x02、0x04 开头）混淆
+	impl := common.HexToAddress(*implHex)
+	signed, err := chain.SignAuthorization(privateKey, new(big.Int).SetUint64(*chainID), impl, *nonce)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\n签名哈希: %s\n", signed.SigHash().Hex())
+	fmt.Printf("签名: yParity=%d r=%s s=%s\n", signed.V, signed.R.Hex(), signed.S.Hex())
+
+	// 步骤 3: 从签名恢复授权者，节点执行授权时也是这样确定要修改哪个账户
+	authority, err := signed.Authority()
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("恢复的授权者: %s（与钱包地址一致: %t）\n", authority.Hex(), authority == address)
+
+	// 步骤 4: 授权生效后账户的代码
+	if impl == (common.Address{}) {
+		fmt.Println("\n实现地址为零地址：授权生效后账户代码被清空，恢复为普通 EOA")
+	} else {
+		code := types.AddressToDelegation(impl)
+		fmt.Printf("\n授权生效后账户代码: %s（%d 字节）\n", hexutil.Encode(code), len(code))
+		target, ok := types.ParseDelegation(code)
+		fmt.Printf("解析委托指示符: %s %t\n", target.Hex(), ok)
+	}
+
+	// 步骤 5: 可选，查询链上账户当前的状态
+	rpcURL := os.Getenv("RPC_URL")
+	if rpcURL == "" {
+		return
+	}
+	client, err := ethclient.Dial(rpcURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+	account, err := chain.InspectAccount(context.Background(), client, address, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\n链上账户状态: %s，nonce %d\n", account.Kind, account.Nonce)
+	if account.Kind == chain.AccountDelegated {
+		fmt.Printf("已委托给 %s（实现合约代码 %d 字节）\n", account.Delegate.Hex(), account.DelegateCodeSize)
+	}
+}
+
+// loadKey 从 PRIVATE_KEY 加载私钥，没有设置时生成新钱包
+func loadKey() (*ecdsa.PrivateKey, error) {
+	if hex := os.Getenv("PRIVATE_KEY"); hex != "" {
+		return crypto.HexToECDSA(hex)
+	}
+	return crypto.GenerateKey()
+}
@@ -4,6 +4,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"math/big"
@@ -12,81 +13,112 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/output"
 )
 
+// balanceRecord 一个地址的余额，也是 --format jsonl / csv 的一行。
+// balance 为 wei 的十进制字符串，查询失败时为空并填写 error
+type balanceRecord struct {
+	Address common.Address `json:"address"`
+	Balance *big.Int       `json:"balance"`
+	Error   string         `json:"error,omitempty"`
+}
+
+// 用法：
+//
+//	go run solutions/02-batch-query.go
+//	go run solutions/02-batch-query.go 0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045 0x71C7...
+//	go run solutions/02-batch-query.go --format json | jq -r .total
+//	go run solutions/02-batch-query.go --format csv > balances.csv
 func main() {
-	fmt.Println("=== 批量查询账户余额 ===")
+	format := output.FormatTable
+	flag.Var(&format, "format", output.FlagUsage)
+	flag.Parse()
 
 	apiKey := os.Getenv("INFURA_API_KEY")
 	if apiKey == "" {
 		log.Fatal("错误: 请设置环境变量 INFURA_API_KEY")
 	}
 
-	client, err := ethclient.Dial("https://sepolia.infura.io/v3/"+apiKey)
+	client, err := ethclient.Dial("https://sepolia.infura.io/v3/" + apiKey)
 	if err != nil {
 		log.Fatal(err)
 	}
 	defer client.Close()
 
-	// 定义多个地址
+	// 定义多个地址，命令行传入的地址优先
 	addresses := []common.Address{
 		common.HexToAddress("0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045"), // Vitalik
 		common.HexToAddress("0x71C7656EC7ab88b098defB751B7401B5f6d8976F"), // 示例地址1
 		common.HexToAddress("0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359"), // 示例地址2
 	}
-
-	// 账户信息结构
-	type AccountInfo struct {
-		Address    common.Address
-		BalanceWei *big.Int
-		BalanceEth *big.Float
+	if flag.NArg() > 0 {
+		addresses = addresses[:0]
+		for _, arg := range flag.Args() {
+			if !common.IsHexAddress(arg) {
+				log.Fatalf("无效的地址: %s", arg)
+			}
+			addresses = append(addresses, common.HexToAddress(arg))
+		}
 	}
 
-	var accounts []AccountInfo
-	totalBalanceWei := big.NewInt(0)
-
-	// 遍历地址，查询余额
-	for _, addr := range addresses {
-		balance, err := client.BalanceAt(context.Background(), addr, nil)
-		if err != nil {
-			log.Printf("查询 %s 失败: %v\n", addr.Hex(), err)
-			continue
+	// 遍历地址，查询余额；单个地址失败不影响其他地址
+	balances := chain.GetBalances(context.Background(), client, addresses, nil)
+	records := make([]balanceRecord, len(balances))
+	for i, b := range balances {
+		records[i] = balanceRecord{Address: b.Address, Balance: b.Wei}
+		if b.Err != nil {
+			records[i].Error = b.Err.Error()
 		}
-
-		balanceEth := new(big.Float).Quo(
-			new(big.Float).SetInt(balance),
-			big.NewFloat(1e18),
-		)
-
-		accounts = append(accounts, AccountInfo{
-			Address:    addr,
-			BalanceWei: balance,
-			BalanceEth: balanceEth,
-		})
-
-		totalBalanceWei.Add(totalBalanceWei, balance)
 	}
+	total := chain.TotalBalance(balances)
+
+	switch format {
+	case output.FormatTable:
+		printTable(records, total)
+	case output.FormatJSON:
+		err = output.WriteJSON(os.Stdout, struct {
+			Balances []balanceRecord `json:"balances"`
+			Total    *big.Int        `json:"total"`
+		}{records, total})
+	default:
+		w := output.NewWriter(os.Stdout, format)
+		for _, r := range records {
+			if err = w.Write(r); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			err = w.Flush()
+		}
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
 
-	// 输出表格
+// printTable 以文本表格输出余额和总计
+func printTable(records []balanceRecord, total *big.Int) {
+	fmt.Println("=== 批量查询账户余额 ===")
 	fmt.Println("\n地址                           余额 (ETH)")
 	fmt.Println(strings.Repeat("─", 50))
 
-	for _, acc := range accounts {
+	for _, r := range records {
 		// 缩短地址显示
-		shortAddr := acc.Address.Hex()
-		if len(shortAddr) > 20 {
-			shortAddr = shortAddr[:6] + "..." + shortAddr[len(shortAddr)-4:]
+		shortAddr := r.Address.Hex()
+		shortAddr = shortAddr[:6] + "..." + shortAddr[len(shortAddr)-4:]
+		if r.Error != "" {
+			fmt.Printf("%-30s  查询失败: %s\n", shortAddr, r.Error)
+			continue
 		}
-		fmt.Printf("%-30s  %12.6f\n", shortAddr, acc.BalanceEth)
+		fmt.Printf("%-30s  %12s\n", shortAddr, chain.FormatEther(r.Balance))
 	}
 
 	// 输出总余额
 	fmt.Println(strings.Repeat("─", 50))
-	totalBalanceEth := new(big.Float).Quo(
-		new(big.Float).SetInt(totalBalanceWei),
-		big.NewFloat(1e18),
-	)
-	fmt.Printf("总计: %.6f ETH\n", totalBalanceEth)
+	fmt.Printf("总计: %s ETH\n", chain.FormatEther(total))
 
 	fmt.Println("=== 完成 ===")
 }
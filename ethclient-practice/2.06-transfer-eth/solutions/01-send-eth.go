@@ -4,20 +4,28 @@ package main
 
 import (
 	"context"
-	"crypto/ecdsa"
+	"flag"
 	"fmt"
 	"log"
-	"math/big"
 	"os"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/chain"
 )
 
+// 用法：
+//
+//	go run solutions/01-send-eth.go
+//	go run solutions/01-send-eth.go --dry-run   # 签名并模拟执行，不发送
 func main() {
+	amount := flag.String("amount", "0.001", "转账数量（ETH）")
+	dryRun := flag.Bool("dry-run", false, "只签名并在 pending 状态上模拟执行，打印签名交易，不发送")
+	flag.Parse()
+
 	fmt.Println("=== ETH 转账 ===")
 
 	// 从环境变量读取配置
@@ -48,91 +56,54 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	fmt.Printf("发送方: %s\n", crypto.PubkeyToAddress(privateKey.PublicKey).Hex())
 
-	// 获取发送方地址
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		log.Fatal("error casting public key to ECDSA")
-	}
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
-
-	fmt.Printf("发送方: %s\n", fromAddress.Hex())
-
-	// 获取 Nonce
-	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
+	value, err := chain.ParseEther(*amount)
 	if err != nil {
 		log.Fatal(err)
 	}
-	fmt.Printf("Nonce: %d\n", nonce)
-
-	// 设置转账金额（0.001 ETH）
-	value := new(big.Int).Mul(big.NewInt(1), big.NewInt(1e15)) // 0.001 ETH = 10^15 Wei
-
-	// 设置 Gas 参数
-	gasLimit := uint64(21000)
-	gasPrice, err := client.SuggestGasPrice(context.Background())
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// 设置接收地址
 	toAddress := common.HexToAddress(toAddressHex)
 
-	// 构建未签名交易
-	tx := types.NewTransaction(nonce, toAddress, value, gasLimit, gasPrice, nil)
-
-	// 获取 Chain ID
-	chainID, err := client.NetworkID(context.Background())
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	// 签名交易
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
-	if err != nil {
-		log.Fatal(err)
+	// dry-run：构造并签名交易，用 eth_call / eth_estimateGas 检查能否执行，但不广播
+	if *dryRun {
+		sim, err := chain.Simulate(context.Background(), client, privateKey, &chain.TxRequest{To: &toAddress, Value: value})
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("\n=== Dry run（未发送） ===")
+		sim.WriteReport(os.Stdout, nil)
+		return
 	}
 
-	// 发送交易
-	err = client.SendTransaction(context.Background(), signedTx)
+	// TransferETH 会查询 nonce、估算 Gas、按 EIP-1559 设置费用并签名发送
+	signedTx, err := chain.TransferETH(context.Background(), client, privateKey, toAddress, value)
 	if err != nil {
 		log.Fatal(err)
 	}
+	fmt.Printf("Nonce: %d\n", signedTx.Nonce())
 
 	fmt.Printf("\n交易已发送: %s\n", signedTx.Hash().Hex())
 	fmt.Printf("查看: https://sepolia.etherscan.io/tx/%s\n", signedTx.Hash().Hex())
 
-	// 等待交易确认
+	// 等待交易确认，每 10 秒查询一次收据
 	fmt.Println("\n等待交易确认...")
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
-	// 简单轮询方式
-	for {
-		receipt, err := client.TransactionReceipt(ctx, signedTx.Hash())
-		if err != nil {
-			// 交易还未确认
-			time.Sleep(10 * time.Second)
-			continue
-		}
+	receipt, err := chain.WaitReceipt(ctx, client, signedTx.Hash(), 10*time.Second)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-		if receipt.Status == 1 {
-			fmt.Printf("\n✅ 交易成功！\n")
-			fmt.Printf("区块号: %d\n", receipt.BlockNumber.Uint64())
-			fmt.Printf("Gas Used: %d\n", receipt.GasUsed)
-
-			// 计算实际费用
-			actualFee := new(big.Int).Mul(receipt.GasUsed, gasPrice)
-			actualFeeEth := new(big.Float).Quo(
-				new(big.Float).SetInt(actualFee),
-				big.NewFloat(1e18),
-			)
-			fmt.Printf("实际费用: %.6f ETH\n", actualFeeEth)
-		} else {
-			fmt.Printf("\n❌ 交易失败！\n")
+	if receipt.Success() {
+		fmt.Printf("\n✅ 交易成功！\n")
+		fmt.Printf("区块号: %d\n", receipt.BlockNumber)
+		fmt.Printf("Gas Used: %d\n", receipt.GasUsed)
+		if fee := receipt.Fee(); fee != nil {
+			fmt.Printf("实际费用: %s ETH\n", chain.FormatEther(fee))
 		}
-		break
+	} else {
+		fmt.Printf("\n❌ 交易失败！\n")
 	}
 
 	fmt.Println("=== 完成 ===")
@@ -0,0 +1,97 @@
+// 04-offline-prepare.go - 离线签名第一步：在线端生成待签名交易包 - 答案
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+)
+
+// 用法（联网的机器，不需要私钥）：
+//
+//	FROM_ADDRESS=0x... go run solutions/04-offline-prepare.go --amount 0.01 0xRecipient1 0xRecipient2
+//	go run solutions/04-offline-prepare.go --out unsigned.json   # 接收方默认读取 TO_ADDRESS
+func main() {
+	amountStr := flag.String("amount", "0.001", "每个接收方的转账数量（ETH）")
+	out := flag.String("out", "unsigned.json", "待签名交易包的输出路径")
+	flag.Parse()
+
+	apiKey := os.Getenv("INFURA_API_KEY")
+	if apiKey == "" {
+		log.Fatal("错误: 请设置环境变量 INFURA_API_KEY")
+	}
+
+	// 只需要发送方地址：私钥留在离线机器上
+	fromHex := os.Getenv("FROM_ADDRESS")
+	if !common.IsHexAddress(fromHex) {
+		log.Fatal("错误: 请设置环境变量 FROM_ADDRESS 为发送方地址")
+	}
+	from := common.HexToAddress(fromHex)
+
+	recipients := flag.Args()
+	if len(recipients) == 0 && os.Getenv("TO_ADDRESS") != "" {
+		recipients = []string{os.Getenv("TO_ADDRESS")}
+	}
+	if len(recipients) == 0 {
+		log.Fatal("错误: 请在命令行传入接收方地址，或设置环境变量 TO_ADDRESS")
+	}
+
+	value, err := chain.ParseEther(*amountStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client, err := ethclient.Dial("https://sepolia.infura.io/v3/" + apiKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	// 每个接收方一笔交易，nonce 从 pending nonce 开始连续编号
+	reqs := make([]*chain.TxRequest, len(recipients))
+	for i, r := range recipients {
+		if !common.IsHexAddress(r) {
+			log.Fatalf("无效的地址: %s", r)
+		}
+		to := common.HexToAddress(r)
+		reqs[i] = &chain.TxRequest{To: &to, Value: value}
+	}
+
+	// 查询链 ID、nonce、估算 Gas 并按当前 baseFee 设置费用
+	bundle, err := chain.PrepareBundle(context.Background(), client, from, reqs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for i := range bundle.Txs {
+		bundle.Txs[i].Note = fmt.Sprintf("转账 %s ETH 给 %s", *amountStr, reqs[i].To.Hex())
+	}
+
+	// 余额不足时提前提示，签名后才发现就要重新走一遍流程
+	balance, err := client.BalanceAt(context.Background(), from, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	total, err := bundle.MaxCost()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if balance.Cmp(total) < 0 {
+		fmt.Printf("⚠️ 余额 %s ETH 少于最多花费 %s ETH，部分交易可能失败\n\n", chain.FormatEther(balance), chain.FormatEther(total))
+	}
+
+	if err := bundle.WriteSummary(os.Stdout, nil); err != nil {
+		log.Fatal(err)
+	}
+	if err := bundle.Save(*out); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\n已写入 %s，复制到离线机器后运行 05-offline-sign.go 签名\n", *out)
+}
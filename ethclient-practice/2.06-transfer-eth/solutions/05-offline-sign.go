@@ -0,0 +1,90 @@
+// 05-offline-sign.go - 离线签名第二步：在离线机器上用 keystore 签名 - 答案
+//
+// 这个程序不导入 ethclient，也不发起任何网络请求：链 ID、nonce 和费用都来自交易包
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+)
+
+// 用法（离线机器）：
+//
+//	KEYSTORE_PASSWORD=... go run solutions/05-offline-sign.go --keystore ./keystore/UTC--...
+//	go run solutions/05-offline-sign.go --keystore key.json --password-file pass.txt --in unsigned.json --out signed.json
+func main() {
+	in := flag.String("in", "unsigned.json", "待签名交易包")
+	out := flag.String("out", "signed.json", "签名交易包的输出路径")
+	keystorePath := flag.String("keystore", "", "keystore 文件")
+	passwordFile := flag.String("password-file", "", "keystore 密码文件，默认读取环境变量 KEYSTORE_PASSWORD")
+	yes := flag.Bool("yes", false, "不询问，直接签名")
+	flag.Parse()
+
+	if *keystorePath == "" {
+		log.Fatal("错误: 请用 --keystore 指定 keystore 文件")
+	}
+
+	bundle, err := chain.ReadUnsignedBundle(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// 签名之前逐笔核对：离线端看到的就是将要签名的全部内容
+	fmt.Println("=== 待签名交易 ===")
+	if err := bundle.WriteSummary(os.Stdout, nil); err != nil {
+		log.Fatal(err)
+	}
+	if !*yes && !confirm("\n确认签名以上交易？[y/N] ") {
+		fmt.Println("已取消")
+		return
+	}
+
+	password := os.Getenv("KEYSTORE_PASSWORD")
+	if *passwordFile != "" {
+		raw, err := os.ReadFile(*passwordFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		password = strings.TrimRight(string(raw), "\r\n")
+	}
+	keyJSON, err := os.ReadFile(*keystorePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	key, err := keystore.DecryptKey(keyJSON, password)
+	if err != nil {
+		log.Fatalf("解密 keystore 失败: %v", err)
+	}
+
+	// 私钥地址必须与交易包的发送方一致
+	signed, err := bundle.Sign(key.PrivateKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := signed.Save(*out); err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println()
+	for _, tx := range signed.Txs {
+		fmt.Printf("✓ nonce %d  %s\n", tx.Nonce, tx.Hash.Hex())
+	}
+	fmt.Printf("\n已写入 %s，复制回联网机器后运行 06-broadcast.go 广播\n", *out)
+}
+
+// confirm 从标准输入读取 y / yes
+func confirm(prompt string) bool {
+	fmt.Print(prompt)
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes"
+}
@@ -0,0 +1,101 @@
+// 06-broadcast.go - 离线签名第三步：广播签名交易并跟踪确认 - 答案
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+)
+
+// 用法（联网的机器）：
+//
+//	go run solutions/06-broadcast.go
+//	go run solutions/06-broadcast.go --in signed.json --no-wait
+//
+// 中断后可以直接重新运行：已在交易池或已打包的交易不会重复发送
+func main() {
+	in := flag.String("in", "signed.json", "签名交易包")
+	noWait := flag.Bool("no-wait", false, "广播后不等待确认")
+	timeout := flag.Duration("timeout", 10*time.Minute, "等待全部交易确认的超时时间")
+	flag.Parse()
+
+	apiKey := os.Getenv("INFURA_API_KEY")
+	if apiKey == "" {
+		log.Fatal("错误: 请设置环境变量 INFURA_API_KEY")
+	}
+
+	bundle, err := chain.ReadSignedBundle(*in)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// 解码并校验签名：哈希、链 ID 和发送方都必须与交易包一致
+	txs, err := bundle.Transactions()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client, err := ethclient.Dial("https://sepolia.infura.io/v3/" + apiKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	if err := bundle.CheckChain(ctx, client); err != nil {
+		log.Fatal(err)
+	}
+
+	// 按 nonce 顺序广播。某一笔失败时停止，后面的交易 nonce 不连续，发出去也只会卡在交易池
+	fmt.Printf("=== 广播 %d 笔交易（发送方 %s） ===\n", len(txs), bundle.From.Hex())
+	for i, tx := range txs {
+		sent, err := chain.Broadcast(ctx, client, bundle.From, tx)
+		if errors.Is(err, chain.ErrNonceUsed) {
+			log.Fatalf("%v\n交易包已失效，请重新生成并签名", err)
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		status := "已发送"
+		if !sent {
+			status = "已在链上或交易池中，跳过"
+		}
+		fmt.Printf("#%d nonce %d  %s  %s\n", i+1, tx.Nonce(), tx.Hash().Hex(), status)
+	}
+	if *noWait {
+		return
+	}
+
+	// 跟踪确认
+	fmt.Println("\n等待确认...")
+	failed := 0
+	for i, tx := range txs {
+		receipt, err := chain.WaitReceipt(ctx, client, tx.Hash(), 0)
+		if err != nil {
+			log.Fatal(err)
+		}
+		status := "✓ 成功"
+		if !receipt.Success() {
+			status = "✗ 失败"
+			failed++
+		}
+		fmt.Printf("#%d %s  区块 %d  Gas %d  费用 %s ETH", i+1, status, receipt.BlockNumber, receipt.GasUsed, chain.FormatEther(receipt.Fee()))
+		if note := bundle.Txs[i].Note; note != "" {
+			fmt.Printf("  %s", note)
+		}
+		fmt.Println()
+	}
+	if failed > 0 {
+		log.Fatalf("%d 笔交易执行失败", failed)
+	}
+}
@@ -0,0 +1,131 @@
+// 07-send-blob.go - 发送 EIP-4844 blob 交易 - 答案
+//
+// blob 交易在普通 EIP-1559 交易之外携带最多 6 个 128 KB 的 blob。
+// blob 不进入 EVM，只在共识层保存约 18 天；链上只记录它们的版本化哈希，
+// 按独立的 blob gas 市场计费
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+)
+
+// 用法：
+//
+//	go run solutions/07-send-blob.go --dry-run                 # 只签名并检查，不发送
+//	go run solutions/07-send-blob.go --message "hello blob"
+//	go run solutions/07-send-blob.go --file ./data.bin         # 数据超过 126976 字节时拆成多个 blob
+//
+// 需要 INFURA_API_KEY 和 PRIVATE_KEY；TO_ADDRESS 为空时发给自己（blob 交易通常不需要接收方执行任何逻辑）
+func main() {
+	message := flag.String("message", "hello from ethclient-practice", "写入 blob 的文本")
+	file := flag.String("file", "", "写入 blob 的文件，优先于 --message")
+	dryRun := flag.Bool("dry-run", false, "只签名并检查 KZG 证明、打印交易，不发送")
+	flag.Parse()
+
+	fmt.Println("=== Blob 交易 ===")
+
+	apiKey := os.Getenv("INFURA_API_KEY")
+	if apiKey == "" {
+		log.Fatal("错误: 请设置环境变量 INFURA_API_KEY")
+	}
+	privateKey, err := crypto.HexToECDSA(os.Getenv("PRIVATE_KEY"))
+	if err != nil {
+		log.Fatal("错误: 请设置环境变量 PRIVATE_KEY")
+	}
+	from := crypto.PubkeyToAddress(privateKey.PublicKey)
+	to := from
+	if hex := os.Getenv("TO_ADDRESS"); hex != "" {
+		to = common.HexToAddress(hex)
+	}
+
+	data := []byte(*message)
+	if *file != "" {
+		if data, err = os.ReadFile(*file); err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	client, err := ethclient.Dial("https://sepolia.infura.io/v3/" + apiKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+	ctx := context.Background()
+
+	// 1. 编码数据并在本地计算 KZG 承诺和证明
+	blobs := chain.EncodeBlobs(data)
+	fmt.Printf("数据: %d 字节 → %d 个 blob\n", len(data), len(blobs))
+	start := time.Now()
+	sidecar, err := chain.NewBlobSidecar(blobs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("KZG 承诺和证明计算耗时 %s\n", time.Since(start).Round(time.Millisecond))
+	for i, h := range sidecar.BlobHashes() {
+		fmt.Printf("  blob #%d 版本化哈希 %s\n", i+1, h.Hex())
+	}
+
+	// 2. 由最新区块的 excessBlobGas 推算下一个区块的 blob gas 价格
+	fee, err := chain.EstimateBlobFee(ctx, client)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\n区块 %d 之后: excessBlobGas %d，blob gas 价格 %s Gwei，最高价格 %s Gwei\n",
+		fee.Head, fee.ExcessBlobGas, chain.FormatUnits(fee.BaseFee, 9), chain.FormatUnits(fee.FeeCap, 9))
+	fmt.Printf("预计 blob 费用: %s ETH\n", chain.FormatEther(fee.Cost(len(blobs))))
+
+	// 3. 构造并签名 blob 交易：执行部分与普通转账相同，另外带上 blob 最高价格和版本化哈希
+	req := &chain.BlobTxRequest{To: to, Sidecar: sidecar, BlobFeeCap: fee.FeeCap}
+	signedTx, err := chain.SignBlobTx(ctx, client, privateKey, req)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *dryRun {
+		raw, err := signedTx.MarshalBinary()
+		if err != nil {
+			log.Fatal(err)
+		}
+		rawTx, err := chain.DecodeRawTx(raw)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("\n=== Dry run（未发送） ===")
+		rawTx.WriteReport(os.Stdout, nil)
+		fmt.Println()
+		chain.WriteChecks(os.Stdout, rawTx.Check(nil, nil))
+		return
+	}
+
+	if err := client.SendTransaction(ctx, signedTx); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\n交易已发送: %s\n", signedTx.Hash().Hex())
+	fmt.Printf("查看: https://sepolia.etherscan.io/tx/%s\n", signedTx.Hash().Hex())
+
+	fmt.Println("\n等待交易确认...")
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	receipt, err := chain.WaitReceipt(waitCtx, client, signedTx.Hash(), 10*time.Second)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("区块号: %d，状态 %d\n", receipt.BlockNumber, receipt.Status)
+	fmt.Printf("执行费用: %s ETH（Gas %d）\n", chain.FormatEther(receipt.Fee()), receipt.GasUsed)
+	if blobFee := receipt.BlobFee(); blobFee != nil {
+		fmt.Printf("Blob 费用: %s ETH（blob gas %d × %s Gwei）\n",
+			chain.FormatEther(blobFee), receipt.BlobGasUsed, chain.FormatUnits(receipt.BlobGasPrice, 9))
+	}
+}
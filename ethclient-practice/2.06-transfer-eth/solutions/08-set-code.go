@@ -0,0 +1,185 @@
+// 08-set-code.go - EIP-7702：把 EOA 委托给批量调用合约 - 答案
+//
+// SetCode 交易（类型 0x04）带有授权列表，每条授权由某个 EOA 的私钥签名，
+// 执行后这个 EOA 的代码变为 0xef0100 || 实现合约地址，之后调用它就会执行实现合约的代码。
+// 这里的实现合约是 simnet/contracts 中的 Batch：账户自己给自己发交易时，可以在一笔交易里完成多次调用
+
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/simnet/contracts"
+)
+
+// 用法（需要启用 Prague 的本地开发链，如 geth 1.15+ 的 --dev 或 anvil --hardfork prague）：
+//
+//	go run solutions/08-set-code.go                      # 部署 Batch，委托并批量转账
+//	go run solutions/08-set-code.go --impl 0x...         # 委托给已部署的实现合约
+//	go run solutions/08-set-code.go --revoke             # 撤销委托，恢复为普通 EOA
+//
+// 需要 PRIVATE_KEY（账户要有少量 ETH）；RPC_URL 默认为 http://127.0.0.1:8545
+func main() {
+	implHex := flag.String("impl", "", "已部署的 Batch 合约地址，为空时先部署一个")
+	revoke := flag.Bool("revoke", false, "撤销委托")
+	flag.Parse()
+
+	rpcURL := os.Getenv("RPC_URL")
+	if rpcURL == "" {
+		rpcURL = "http://127.0.0.1:8545"
+	}
+	privateKey, err := crypto.HexToECDSA(os.Getenv("PRIVATE_KEY"))
+	if err != nil {
+		log.Fatal("错误: 请设置环境变量 PRIVATE_KEY")
+	}
+	account := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	client, err := ethclient.Dial(rpcURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	fmt.Println("=== 账户状态 ===")
+	printAccount(ctx, client, account)
+
+	if *revoke {
+		fmt.Println("\n=== 撤销委托 ===")
+		req, err := chain.NewRevocation(ctx, client, privateKey)
+		if err != nil {
+			log.Fatal(err)
+		}
+		sendSetCode(ctx, client, privateKey, req)
+		printAccount(ctx, client, account)
+		return
+	}
+
+	// 1. 准备实现合约
+	var impl common.Address
+	if *implHex != "" {
+		impl = common.HexToAddress(*implHex)
+	} else {
+		fmt.Println("\n=== 1. 部署 Batch 实现合约 ===")
+		tx, err := chain.Send(ctx, client, privateKey, &chain.TxRequest{Data: contracts.BatchDeployData()})
+		if err != nil {
+			log.Fatal(err)
+		}
+		receipt, err := chain.WaitReceipt(ctx, client, tx.Hash(), time.Second)
+		if err != nil {
+			log.Fatal(err)
+		}
+		impl = *receipt.ContractAddress
+		fmt.Printf("Batch: %s\n", impl.Hex())
+	}
+
+	// 2. 一笔 SetCode 交易同时完成委托和第一次批量调用：授权先生效，然后执行发给自己的 multiSend
+	fmt.Println("\n=== 2. 委托并批量转账 ===")
+	recipients := []common.Address{newAddress(), newAddress()}
+	batch := batchTransfer(recipients, big.NewInt(1e15)) // 每人 0.001 ETH
+	req, err := chain.NewDelegation(ctx, client, privateKey, impl, batch)
+	if err != nil {
+		log.Fatal(err)
+	}
+	sendSetCode(ctx, client, privateKey, req)
+	printAccount(ctx, client, account)
+	printBalances(ctx, client, recipients)
+
+	// 3. 委托一直有效，之后用普通的 EIP-1559 交易发给自己也能批量调用
+	fmt.Println("\n=== 3. 用普通交易再批量转账一次 ===")
+	recipients = []common.Address{newAddress(), newAddress(), newAddress()}
+	tx, err := chain.Send(ctx, client, privateKey, &chain.TxRequest{To: &account, Data: batchTransfer(recipients, big.NewInt(2e15))})
+	if err != nil {
+		log.Fatal(err)
+	}
+	receipt, err := chain.WaitReceipt(ctx, client, tx.Hash(), time.Second)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("交易 %s：状态 %d，Gas %d\n", tx.Hash().Hex(), receipt.Status, receipt.GasUsed)
+	printBalances(ctx, client, recipients)
+
+	fmt.Println("\n撤销委托: go run solutions/08-set-code.go --revoke")
+}
+
+// sendSetCode 签名 SetCode 交易，打印解码结果后发送并等待确认
+func sendSetCode(ctx context.Context, client *ethclient.Client, key *ecdsa.PrivateKey, req *chain.SetCodeTxRequest) {
+	tx, err := chain.SignSetCodeTx(ctx, client, key, req)
+	if err != nil {
+		log.Fatal(err)
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		log.Fatal(err)
+	}
+	rawTx, err := chain.DecodeRawTx(raw)
+	if err != nil {
+		log.Fatal(err)
+	}
+	rawTx.WriteReport(os.Stdout, nil)
+
+	if err := client.SendTransaction(ctx, tx); err != nil {
+		log.Fatal(err)
+	}
+	receipt, err := chain.WaitReceipt(ctx, client, tx.Hash(), time.Second)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\n交易 %s：状态 %d，Gas %d\n", tx.Hash().Hex(), receipt.Status, receipt.GasUsed)
+}
+
+// batchTransfer 给每个接收方转 value 的 multiSend 调用数据
+func batchTransfer(recipients []common.Address, value *big.Int) []byte {
+	var calls []contracts.Call
+	for _, to := range recipients {
+		calls = append(calls, contracts.Call{To: to, Value: value})
+	}
+	data, err := contracts.BatchData(calls)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return data
+}
+
+func printAccount(ctx context.Context, client *ethclient.Client, addr common.Address) {
+	info, err := chain.InspectAccount(ctx, client, addr, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%s  %s，nonce %d\n", addr.Hex(), info.Kind, info.Nonce)
+	if info.Kind == chain.AccountDelegated {
+		fmt.Printf("  委托给 %s（实现合约代码 %d 字节）\n", info.Delegate.Hex(), info.DelegateCodeSize)
+	}
+}
+
+func printBalances(ctx context.Context, client *ethclient.Client, addrs []common.Address) {
+	for _, addr := range addrs {
+		balance, err := client.BalanceAt(ctx, addr, nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("  %s  %s ETH\n", addr.Hex(), chain.FormatEther(balance))
+	}
+}
+
+// newAddress 随机生成一个从未使用过的地址作为接收方
+func newAddress() common.Address {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		log.Fatal(err)
+	}
+	return crypto.PubkeyToAddress(key.PublicKey)
+}
@@ -0,0 +1,236 @@
+// 09-safe-multisig.go - Safe 多签钱包：提案、收集签名、执行 - 答案
+//
+// 资金在 Safe 合约中，转出需要至少 threshold 个 owner 对同一个 safeTxHash 签名。
+// 签名在链下收集：提案保存为 JSON 文件，各 owner 用自己的 keystore 依次签名，
+// 最后由任意账户提交 execTransaction
+
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/safe"
+	"github.com/dapp-learning/ethclient/util/simnet"
+	"github.com/dapp-learning/ethclient/util/simnet/contracts"
+)
+
+// 用法：
+//
+//	go run solutions/09-safe-multisig.go
+//
+// 在模拟链上部署 3 个 owner、阈值为 2 的 Safe，演示：
+//   - 从合约读取 owner、阈值和 nonce，本地计算的 safeTxHash 与 getTransactionHash 一致
+//   - 提案写入文件，两个 owner 分别从 keystore 解密私钥签名
+//   - 签名不足时拒绝执行；签名没有按 owner 地址升序排列时合约回滚 GS026
+//   - 由不是 owner 的账户提交 execTransaction，转出 ETH 和代币
+func main() {
+	ctx := context.Background()
+
+	sim, err := simnet.New(&simnet.Options{Accounts: 5})
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer sim.Close()
+	executor := sim.Accounts[0]
+	owners := sim.Accounts[1:4]
+	bob := simnet.NewAccount(99).Address
+
+	// 1. 部署 Safe 并转入资金
+	fmt.Println("=== 1. 部署 Safe ===")
+	var ownerAddrs []common.Address
+	for _, owner := range owners {
+		ownerAddrs = append(ownerAddrs, owner.Address)
+	}
+	// 与主网相同：先部署 Safe v1.3.0 单例和代理工厂，再由工厂创建指向单例的代理并调用 setup
+	treasury, err := sim.DeploySafe(ctx, executor, ownerAddrs, 2)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("单例 %s，工厂 %s\n", sim.SafeSingleton.Hex(), sim.SafeFactory.Hex())
+	if _, err := sim.Transfer(ctx, executor, treasury, big.NewInt(5e18)); err != nil {
+		log.Fatal(err)
+	}
+	tokens := new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+	if _, err := sim.Send(ctx, executor, &sim.Token, nil, chain.TransferData(treasury, tokens)); err != nil {
+		log.Fatal(err)
+	}
+	info := printInfo(ctx, sim, treasury)
+
+	// 2. 每个 owner 的私钥保存在各自的 keystore 中（演示使用较弱的 scrypt 参数，加快解密）
+	dir, err := os.MkdirTemp("", "safe-multisig")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	ks := keystore.NewKeyStore(filepath.Join(dir, "keys"), keystore.LightScryptN, keystore.LightScryptP)
+	keyFiles := make(map[common.Address]string)
+	for i, owner := range owners {
+		account, err := ks.ImportECDSA(owner.Key, password(i))
+		if err != nil {
+			log.Fatal(err)
+		}
+		keyFiles[owner.Address] = account.URL.Path
+	}
+
+	// 3. 创建提案：从 Safe 向 bob 转 1 ETH，nonce 取 Safe 当前的 nonce
+	fmt.Println("\n=== 2. 创建提案 ===")
+	p := safe.NewProposal(treasury, sim.ChainID, safe.SafeTx{To: bob, Value: big.NewInt(1e18), Nonce: info.Nonce})
+	fmt.Printf("safeTxHash: %s\n", p.SafeTxHash.Hex())
+	checkHash(ctx, sim, p)
+	proposalFile := filepath.Join(dir, "proposal.json")
+	if err := p.Save(proposalFile); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("提案已保存: %s\n", proposalFile)
+
+	// 4. owner 依次加载提案、签名并保存。这里故意让地址较大的 owner 先签
+	fmt.Println("\n=== 3. 收集签名 ===")
+	signers := []int{1, 2}
+	for n, i := range signers {
+		p, err := safe.ReadProposal(proposalFile)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := p.SignKeystore(keyFiles[owners[i].Address], password(i)); err != nil {
+			log.Fatal(err)
+		}
+		if err := p.Save(proposalFile); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("owner %s 已签名，共 %d 个签名\n", owners[i].Address.Hex(), len(p.Signatures))
+
+		// 只有一个签名时不满足阈值，Exec 在发送前就会拒绝
+		if n == 0 {
+			if err := p.Check(info); errors.Is(err, safe.ErrNotEnoughSignatures) {
+				fmt.Printf("  不能执行: %v\n", err)
+			}
+		}
+	}
+
+	// 5. 执行
+	fmt.Println("\n=== 4. 执行 ===")
+	if p, err = safe.ReadProposal(proposalFile); err != nil {
+		log.Fatal(err)
+	}
+	checkUnsorted(ctx, sim, executor.Address, p)
+	execute(ctx, sim, executor, p, info)
+	balance, err := sim.Client.BalanceAt(ctx, bob, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("bob 的余额: %s ETH\n", chain.FormatEther(balance))
+	info = printInfo(ctx, sim, treasury)
+
+	// 6. 第二笔提案调用代币合约：To 是代币，Data 是 transfer 调用。
+	//    nonce 已经加 1，同一个提案不能再执行第二次
+	fmt.Println("\n=== 5. 转出代币 ===")
+	amount := new(big.Int).Mul(big.NewInt(250), big.NewInt(1e18))
+	p = safe.NewProposal(treasury, sim.ChainID, safe.SafeTx{To: sim.Token, Data: chain.TransferData(bob, amount), Nonce: info.Nonce})
+	for _, i := range []int{0, 2} {
+		if err := p.Sign(owners[i].Key); err != nil {
+			log.Fatal(err)
+		}
+	}
+	execute(ctx, sim, executor, p, info)
+	if balance, err = chain.TokenBalance(ctx, sim.Client, sim.Token, bob); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("bob 的代币余额: %s %s\n", chain.FormatEther(balance), contracts.TokenSymbol)
+	printInfo(ctx, sim, treasury)
+}
+
+func password(i int) string {
+	return fmt.Sprintf("owner-%d", i+1)
+}
+
+// printInfo 打印 Safe 的 owner、阈值、nonce 和余额
+func printInfo(ctx context.Context, sim *simnet.Net, treasury common.Address) *safe.Info {
+	info, err := safe.GetInfo(ctx, sim.Client, treasury)
+	if err != nil {
+		log.Fatal(err)
+	}
+	balance, err := sim.Client.BalanceAt(ctx, treasury, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tokens, err := chain.TokenBalance(ctx, sim.Client, sim.Token, treasury)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Safe %s: %d/%d 多签，nonce %d，余额 %s ETH、%s %s\n", treasury.Hex(),
+		info.Threshold, len(info.Owners), info.Nonce, chain.FormatEther(balance), chain.FormatEther(tokens), contracts.TokenSymbol)
+	for _, owner := range info.Owners {
+		fmt.Printf("  owner %s\n", owner.Hex())
+	}
+	return info
+}
+
+// checkHash 比较本地计算的 safeTxHash 和合约 getTransactionHash 的结果
+func checkHash(ctx context.Context, sim *simnet.Net, p *safe.Proposal) {
+	parsed, err := abi.JSON(strings.NewReader(contracts.SafeABI))
+	if err != nil {
+		log.Fatal(err)
+	}
+	tx := p.Tx
+	data, err := parsed.Pack("getTransactionHash", tx.To, tx.Value, []byte(tx.Data), uint8(tx.Operation),
+		new(big.Int), new(big.Int), new(big.Int), common.Address{}, common.Address{}, new(big.Int).SetUint64(tx.Nonce))
+	if err != nil {
+		log.Fatal(err)
+	}
+	out, err := sim.Client.CallContract(ctx, ethereum.CallMsg{To: &p.Safe, Data: data}, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("与 getTransactionHash 一致: %t\n", common.BytesToHash(out) == p.SafeTxHash)
+}
+
+// checkUnsorted 按收集顺序（未排序）拼接签名，用 eth_call 模拟执行，合约会回滚
+func checkUnsorted(ctx context.Context, sim *simnet.Net, from common.Address, p *safe.Proposal) {
+	var sigs []byte
+	for _, sig := range p.Signatures {
+		sigs = append(sigs, sig.Data...)
+	}
+	parsed, err := abi.JSON(strings.NewReader(contracts.SafeABI))
+	if err != nil {
+		log.Fatal(err)
+	}
+	tx := p.Tx
+	data, err := parsed.Pack("execTransaction", tx.To, tx.Value, []byte(tx.Data), uint8(tx.Operation),
+		new(big.Int), new(big.Int), new(big.Int), common.Address{}, common.Address{}, sigs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	_, err = sim.Client.CallContract(ctx, ethereum.CallMsg{From: from, To: &p.Safe, Data: data}, nil)
+	fmt.Printf("未按地址排序的签名: %v\n", err)
+}
+
+// execute 发送 execTransaction，出块后从事件中读取执行结果
+func execute(ctx context.Context, sim *simnet.Net, executor *simnet.Account, p *safe.Proposal, info *safe.Info) {
+	tx, err := safe.Exec(ctx, sim.Client, executor.Key, p, info)
+	if err != nil {
+		log.Fatal(err)
+	}
+	sim.Commit()
+	receipt, err := sim.Client.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		log.Fatal(err)
+	}
+	result, err := safe.ParseExecution(receipt, p.Safe)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("交易 %s：Gas %d，safeTxHash %s，执行成功 %t\n", tx.Hash().Hex(), receipt.GasUsed, result.SafeTxHash.Hex(), result.Success)
+}
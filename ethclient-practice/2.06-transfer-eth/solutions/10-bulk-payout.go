@@ -0,0 +1,188 @@
+// 10-bulk-payout.go - 按 CSV 批量出款，中断后可以继续 - 答案
+//
+// 作业 2 的批量转账把收款人写死在代码里，同时启动全部 goroutine，
+// 程序崩溃后也不知道哪些已经付过。这里从 CSV 读取付款清单，先检查地址和余额，
+// 再按 nonce 顺序发送；每行的状态写入日志文件，重新运行时只处理没有完成的行
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/output"
+	"github.com/dapp-learning/ethclient/util/payout"
+)
+
+// 用法：
+//
+//	go run solutions/10-bulk-payout.go --check payouts.example.csv   # 只检查清单和余额，不发送
+//	go run solutions/10-bulk-payout.go payouts.csv                   # 出款，日志写入 payouts.csv.journal
+//	go run solutions/10-bulk-payout.go --concurrency 8 payouts.csv
+//	go run solutions/10-bulk-payout.go --verify-rpc https://rpc.sepolia.org payouts.csv
+//	go run solutions/10-bulk-payout.go --confirm-dropped 5,7 payouts.csv  # 核实第 5、7 行没有付款后重新发送
+//
+// 中断（Ctrl-C、断网、崩溃）后用同样的命令重新运行即可：已签名的交易只会原样重新广播，
+// 不会用新的 nonce 再付一次。日志中交易的 nonce 已被使用、但两个节点都查不到收据时停在这一行，
+// 在区块浏览器上核实后用 --confirm-dropped 指定行号。结束时对账报告写入 payouts.csv.report.csv
+func main() {
+	journalPath := flag.String("journal", "", "状态日志，默认为 <CSV>.journal")
+	reportPath := flag.String("report", "", "对账报告，默认为 <CSV>.report.<格式>")
+	format := output.FormatCSV
+	flag.Var(&format, "format", "对账报告格式: table | json | jsonl | csv")
+	concurrency := flag.Int("concurrency", payout.DefaultConcurrency, "最多同时等待确认的交易数")
+	checkOnly := flag.Bool("check", false, "只检查清单和余额，不发送交易")
+	verifyRPC := flag.String("verify-rpc", "", "另一个节点的 RPC 地址，恢复时在两个节点上查收据")
+	confirmDropped := flag.String("confirm-dropped", "", "核实过没有付款、可以重新发送的行号，逗号分隔")
+	timeout := flag.Duration("timeout", time.Hour, "整个出款的超时时间")
+	flag.Parse()
+	if flag.NArg() != 1 {
+		log.Fatal("用法: go run solutions/10-bulk-payout.go [flags] payouts.csv")
+	}
+	csvPath := flag.Arg(0)
+	var dropped []int
+	for _, s := range strings.Split(*confirmDropped, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		line, err := strconv.Atoi(s)
+		if err != nil {
+			log.Fatalf("--confirm-dropped: 无效的行号 %q", s)
+		}
+		dropped = append(dropped, line)
+	}
+	if *journalPath == "" {
+		*journalPath = csvPath + ".journal"
+	}
+	if *reportPath == "" {
+		*reportPath = csvPath + ".report." + string(format)
+	}
+
+	apiKey := os.Getenv("INFURA_API_KEY")
+	if apiKey == "" {
+		log.Fatal("错误: 请设置环境变量 INFURA_API_KEY")
+	}
+	privateKey, err := crypto.HexToECDSA(strings.TrimPrefix(os.Getenv("PRIVATE_KEY"), "0x"))
+	if err != nil {
+		log.Fatal("错误: 请设置环境变量 PRIVATE_KEY")
+	}
+	from := crypto.PubkeyToAddress(privateKey.PublicKey)
+
+	// 1. 读取并检查清单：所有格式错误一次列出，一笔都不发送
+	f, err := os.Open(csvPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	rows, err := payout.ReadCSV(f)
+	f.Close()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client, err := ethclient.Dial("https://sepolia.infura.io/v3/" + apiKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+	opts := &payout.Options{Concurrency: *concurrency, ConfirmDropped: dropped}
+	if *verifyRPC != "" {
+		verifier, err := ethclient.Dial(*verifyRPC)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer verifier.Close()
+		opts.Verifier = verifier
+	}
+
+	// Ctrl-C 时取消 ctx：已广播的交易在日志中保持 sent，下次运行继续等待确认
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	plan, err := payout.NewPlan(ctx, client, from, rows)
+	if err != nil {
+		log.Fatal(err)
+	}
+	journal, err := payout.OpenJournal(*journalPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer journal.Close()
+
+	// 2. 余额检查：只统计还没有付款的行，重新运行时已完成的行不再计入
+	fmt.Printf("=== 出款账户 %s，共 %d 行 ===\n", from.Hex(), len(plan.Payments))
+	reqs, err := payout.Check(ctx, client, plan, journal, 0)
+	for _, req := range reqs {
+		if req.Rows == 0 && req.Fees == nil {
+			continue
+		}
+		line := fmt.Sprintf("%-6s %3d 行  %s", req.Asset.Symbol, req.Rows, req.Asset.Format(req.Amount))
+		if req.Fees != nil {
+			line += fmt.Sprintf(" + Gas 费用上限 %s ETH", chain.FormatEther(req.Fees))
+		}
+		mark := "✅"
+		if !req.Enough() {
+			mark = "❌"
+		}
+		fmt.Printf("%s %s，余额 %s\n", mark, line, req.Asset.Format(req.Balance))
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *checkOnly {
+		return
+	}
+
+	// 3. 发送
+	fmt.Printf("\n=== 发送（日志 %s） ===\n", journal.Path())
+	opts.OnUpdate = func(p *payout.Payment, e payout.Entry) {
+		fmt.Printf("[第 %d 行] %-9s %s → %s", p.Line, e.Status, p.Asset.Format(p.Value), p.To.Hex())
+		if e.TxHash != nil {
+			fmt.Printf("  nonce %d  %s", e.Nonce, e.TxHash.Hex())
+		}
+		if e.Error != "" {
+			fmt.Printf("  %s", e.Error)
+		}
+		fmt.Println()
+	}
+	runErr := payout.Run(ctx, client, privateKey, plan, journal, opts)
+
+	// 4. 对账报告：无论是否出错都写出，方便核对已经付了哪些
+	report := payout.Report(plan, journal)
+	out, err := os.Create(*reportPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := payout.WriteReport(out, format, report); err != nil {
+		log.Fatal(err)
+	}
+	if err := out.Close(); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\n=== 对账报告 %s ===\n", *reportPath)
+	summary := payout.Summarize(report)
+	summary.Write(os.Stdout, plan)
+
+	if errors.Is(runErr, payout.ErrNeedConfirmation) {
+		log.Fatalf("出款暂停: %v", runErr)
+	}
+	if runErr != nil {
+		log.Fatalf("出款未完成: %v\n重新运行同样的命令即可继续", runErr)
+	}
+	if !summary.Done() {
+		log.Fatal("部分行没有付款，请检查报告中 failed / error 的行")
+	}
+}
@@ -4,35 +4,28 @@ package main
 
 import (
 	"context"
-	"crypto/ecdsa"
+	"flag"
 	"fmt"
 	"log"
-	"math/big"
 	"os"
 	"time"
 
-	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"golang.org/x/crypto/sha3"
-)
 
-// tokenAmountToWei 将人类可读的代币数量转换为最小单位（Wei）
-// amount: 代币数量，如 1000 表示 1000 个代币
-// decimals: 代币小数位数，如 18 表示 18 位小数（大多数 ERC20 代币）
-// 返回: 转换后的最小单位数量
-func tokenAmountToWei(amount float64, decimals uint64) *big.Int {
-	decimalsBig := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
-	amountFloat := big.NewFloat(amount)
-	wei := new(big.Float).Mul(amountFloat, new(big.Float).SetInt(decimalsBig))
-	result, _ := wei.Int(nil)
-	return result
-}
+	"github.com/dapp-learning/ethclient/util/chain"
+)
 
+// 用法：
+//
+//	go run solutions/01-send-token.go
+//	go run solutions/01-send-token.go --dry-run   # 签名并模拟执行，不发送
 func main() {
+	dryRun := flag.Bool("dry-run", false, "只签名并在 pending 状态上模拟执行，打印签名交易，不发送")
+	flag.Parse()
+
 	fmt.Println("=== ERC20 代币转账 ===")
 
 	// 从环境变量读取配置
@@ -47,7 +40,7 @@ func main() {
 	}
 
 	// 连接到以太坊节点
-	client, err := ethclient.Dial("https://sepolia.infura.io/v3/"+apiKey)
+	client, err := ethclient.Dial("https://sepolia.infura.io/v3/" + apiKey)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -58,146 +51,75 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	fmt.Printf("发送方: %s\n", crypto.PubkeyToAddress(privateKey.PublicKey).Hex())
 
-	// 获取发送方地址
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		log.Fatal("error casting public key to ECDSA")
-	}
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
-
-	// 获取 Nonce 和 Gas Price
-	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	gasPrice, err := client.SuggestGasPrice(context.Background())
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	fmt.Printf("发送方: %s\n", fromAddress.Hex())
-	fmt.Printf("Nonce: %d\n", nonce)
-
-	// 设置地址
+	ctx := context.Background()
 	toAddress := common.HexToAddress(toAddressHex)
 	tokenAddress := common.HexToAddress(tokenAddressHex)
 
-	// 查询代币小数位数（可选，默认使用 18）
-	// 大多数 ERC20 代币使用 18 位小数
-	var decimals uint64 = 18
-	{
-		// 尝试查询代币的 decimals()
-		decimalsFnSignature := []byte("decimals()")
-		hash := sha3.NewLegacyKeccak256()
-		hash.Write(decimalsFnSignature)
-		decimalsMethodID := hash.Sum(nil)[:4]
-
-		callData := append(decimalsMethodID)
-		result, err := client.CallContract(context.Background(), ethereum.CallMsg{
-			To:   &tokenAddress,
-			Data: callData,
-		}, nil)
-
-		if err == nil && len(result) >= 32 {
-			decimals = new(big.Int).SetBytes(result).Uint64()
-		}
-		// 如果查询失败，使用默认值 18
+	// 查询代币信息，decimals() 查询失败时使用默认值 18
+	token, err := chain.GetToken(ctx, client, tokenAddress)
+	if err != nil {
+		log.Fatal(err)
 	}
 
-	// 将人类可读的数量转换为最小单位
-	// amountStr 是字符串，如 "1000" 表示 1000 个代币
-	var amountFloat float64
-	_, err := fmt.Sscanf(amountStr, "%f", &amountFloat)
+	// 将人类可读的数量转换为最小单位，如 "1000" 表示 1000 个代币
+	amount, err := chain.ParseUnits(amountStr, token.Decimals)
 	if err != nil {
 		log.Fatalf("错误: 无法解析代币数量 %s: %v", amountStr, err)
 	}
-
-	amount := tokenAmountToWei(amountFloat, decimals)
-	fmt.Printf("转账数量: %s 代币 (decimals: %d)\n", amountStr, decimals)
+	fmt.Printf("转账数量: %s %s (decimals: %d)\n", amountStr, token.Symbol, token.Decimals)
 	fmt.Printf("转换为最小单位: %s\n", amount.String())
 
-	// 构建 transfer 函数调用数据
-	// 函数签名: transfer(address,uint256)
-
-	// 生成 Method ID
-	transferFnSignature := []byte("transfer(address,uint256)")
-	hash := sha3.NewLegacyKeccak256()
-	hash.Write(transferFnSignature)
-	methodID := hash.Sum(nil)[:4]
-	fmt.Printf("Method ID: %s\n", hexutil.Encode(methodID))
-
-	// 填充地址到 32 字节
-	paddedAddress := common.LeftPadBytes(toAddress.Bytes(), 32)
-	fmt.Printf("Padded Address: %s\n", hexutil.Encode(paddedAddress))
-
-	// 填充金额到 32 字节
-	paddedAmount := common.LeftPadBytes(amount.Bytes(), 32)
-	fmt.Printf("Padded Amount: %s\n", hexutil.Encode(paddedAmount))
-
-	// 组合数据
-	var data []byte
-	data = append(data, methodID...)
-	data = append(data, paddedAddress...)
-	data = append(data, paddedAmount...)
-
-	// 估算 Gas
-	gasLimit, err := client.EstimateGas(context.Background(), ethereum.CallMsg{
-		To:   &tokenAddress,
-		Data: data,
-	})
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Printf("Estimated Gas: %d\n", gasLimit)
-
-	// 构建交易
-	value := big.NewInt(0)
-	tx := types.NewTransaction(nonce, tokenAddress, value, gasLimit, gasPrice, data)
-
-	// 获取 Chain ID
-	chainID, err := client.NetworkID(context.Background())
-	if err != nil {
-		log.Fatal(err)
-	}
+	// transfer(address,uint256) 调用数据：4 字节 Method ID + 两个 32 字节参数
+	data := chain.TransferData(toAddress, amount)
+	fmt.Printf("Method ID: %s\n", hexutil.Encode(data[:4]))
+	fmt.Printf("Padded Address: %s\n", hexutil.Encode(data[4:36]))
+	fmt.Printf("Padded Amount: %s\n", hexutil.Encode(data[36:68]))
 
-	// 签名交易
-	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
-	if err != nil {
-		log.Fatal(err)
+	// dry-run：余额不足等情况会在模拟执行时 revert，报告中给出 revert 原因
+	if *dryRun {
+		sim, err := chain.Simulate(ctx, client, privateKey, &chain.TxRequest{To: &tokenAddress, Data: data})
+		if err != nil {
+			log.Fatal(err)
+		}
+		balance, err := chain.TokenBalance(ctx, client, tokenAddress, sim.From)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("\n=== Dry run（未发送） ===")
+		fmt.Printf("代币余额: %s %s\n", chain.FormatUnits(balance, token.Decimals), token.Symbol)
+		sim.WriteReport(os.Stdout, nil)
+		return
 	}
 
-	// 发送交易
-	err = client.SendTransaction(context.Background(), signedTx)
+	// 估算 Gas、签名并发送
+	signedTx, err := chain.TransferToken(ctx, client, privateKey, tokenAddress, toAddress, amount)
 	if err != nil {
 		log.Fatal(err)
 	}
+	fmt.Printf("Nonce: %d\n", signedTx.Nonce())
+	fmt.Printf("Gas Limit: %d\n", signedTx.Gas())
 
 	fmt.Printf("\n交易已发送: %s\n", signedTx.Hash().Hex())
 	fmt.Printf("查看: https://sepolia.etherscan.io/tx/%s\n\n", signedTx.Hash().Hex())
 
 	// 等待交易确认
 	fmt.Println("等待交易确认...")
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel()
 
-	for {
-		receipt, err := client.TransactionReceipt(ctx, signedTx.Hash())
-		if err != nil {
-			time.Sleep(10 * time.Second)
-			continue
-		}
+	receipt, err := chain.WaitReceipt(waitCtx, client, signedTx.Hash(), 10*time.Second)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-		if receipt.Status == 1 {
-			fmt.Printf("\n✅ 交易成功！\n")
-			fmt.Printf("区块号: %d\n", receipt.BlockNumber.Uint64())
-			fmt.Printf("Gas Used: %d\n", receipt.GasUsed)
-		} else {
-			fmt.Printf("\n❌ 交易失败\n")
-		}
-		break
+	if receipt.Success() {
+		fmt.Printf("\n✅ 交易成功！\n")
+		fmt.Printf("区块号: %d\n", receipt.BlockNumber)
+		fmt.Printf("Gas Used: %d\n", receipt.GasUsed)
+	} else {
+		fmt.Printf("\n❌ 交易失败\n")
 	}
 
 	fmt.Println("=== 完成 ===")
@@ -4,30 +4,17 @@ package main
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"fmt"
 	"log"
-	"math/big"
 	"os"
 	"time"
 
-	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"golang.org/x/crypto/sha3"
-)
 
-// tokenAmountToWei 将人类可读的代币数量转换为最小单位（Wei）
-func tokenAmountToWei(amount float64, decimals uint64) *big.Int {
-	decimalsBig := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
-	amountFloat := big.NewFloat(amount)
-	wei := new(big.Float).Mul(amountFloat, new(big.Float).SetInt(decimalsBig))
-	result, _ := wei.Int(nil)
-	return result
-}
+	"github.com/dapp-learning/ethclient/util/chain"
+)
 
 func main() {
 	fmt.Println("=== ERC20 授权并代理转账 ===")
@@ -43,7 +30,7 @@ func main() {
 		log.Fatal("错误: 请设置环境变量 INFURA_API_KEY, PRIVATE_KEY, TOKEN_ADDRESS, SPENDER_ADDRESS, TO_ADDRESS, TOKEN_AMOUNT")
 	}
 
-	client, err := ethclient.Dial("https://sepolia.infura.io/v3/"+apiKey)
+	client, err := ethclient.Dial("https://sepolia.infura.io/v3/" + apiKey)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -53,162 +40,74 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	fromAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
 
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		log.Fatal("error casting public key to ECDSA")
-	}
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
-
+	ctx := context.Background()
 	tokenAddress := common.HexToAddress(tokenAddressHex)
 	spenderAddress := common.HexToAddress(spenderAddressHex)
 	toAddress := common.HexToAddress(toAddressHex)
 
-	// 查询代币小数位数（默认 18）
-	var decimals uint64 = 18
-	{
-		decimalsFnSignature := []byte("decimals()")
-		hash := sha3.NewLegacyKeccak256()
-		hash.Write(decimalsFnSignature)
-		decimalsMethodID := hash.Sum(nil)[:4]
-
-		result, err := client.CallContract(context.Background(), ethereum.CallMsg{
-			To:   &tokenAddress,
-			Data: decimalsMethodID,
-		}, nil)
-
-		if err == nil && len(result) >= 32 {
-			decimals = new(big.Int).SetBytes(result).Uint64()
-		}
+	// 查询代币小数位数（默认 18）并转换数量
+	token, err := chain.GetToken(ctx, client, tokenAddress)
+	if err != nil {
+		log.Fatal(err)
 	}
-
-	// 将人类可读的数量转换为最小单位
-	var amountFloat float64
-	_, err = fmt.Sscanf(amountStr, "%f", &amountFloat)
+	amount, err := chain.ParseUnits(amountStr, token.Decimals)
 	if err != nil {
 		log.Fatalf("错误: 无法解析代币数量 %s: %v", amountStr, err)
 	}
-	amount := tokenAmountToWei(amountFloat, decimals)
-	fmt.Printf("代币数量: %s (decimals: %d) = %s\n", amountStr, decimals, amount.String())
-
-	// 步骤 1: 构建 approve 函数调用数据
-	approveSignature := []byte("approve(address,uint256)")
-	hash := sha3.NewLegacyKeccak256()
-	hash.Write(approveSignature)
-	methodID := hash.Sum(nil)[:4]
-
-	paddedSpender := common.LeftPadBytes(spenderAddress.Bytes(), 32)
-	paddedAmount := common.LeftPadBytes(amount.Bytes(), 32)
-
-	var approveData []byte
-	approveData = append(approveData, methodID...)
-	approveData = append(approveData, paddedSpender...)
-	approveData = append(approveData, paddedAmount...)
+	fmt.Printf("代币数量: %s (decimals: %d) = %s\n", amountStr, token.Decimals, amount.String())
 
+	// 步骤 1: 发送 approve 交易
 	fmt.Printf("授权地址: %s\n", spenderAddress.Hex())
 	fmt.Printf("授权金额: %s\n", amount.String())
-
-	// 步骤 2: 发送 approve 交易
-	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
+	approveTx, err := chain.ApproveToken(ctx, client, privateKey, tokenAddress, spenderAddress, amount)
 	if err != nil {
 		log.Fatal(err)
 	}
+	fmt.Printf("\n授权交易已发送: %s\n", approveTx.Hash().Hex())
 
-	gasPrice, _ := client.SuggestGasPrice(context.Background())
-
-	value := big.NewInt(0)
-	gasLimit, _ := client.EstimateGas(context.Background(), ethereum.CallMsg{
-		To:   &tokenAddress,
-		Data: approveData,
-	})
-
-	tx := types.NewTransaction(nonce, tokenAddress, value, gasLimit, gasPrice, approveData)
-
-	chainID, _ := client.NetworkID(context.Background())
-	signedTx, _ := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
+	// 步骤 2: 等待授权交易确认
+	fmt.Println("等待授权交易确认...")
+	if receipt := waitReceipt(ctx, client, approveTx.Hash()); !receipt.Success() {
+		log.Fatal("\n❌ 授权交易失败")
+	}
+	fmt.Println("\n✅ 授权已确认！")
 
-	err = client.SendTransaction(context.Background(), signedTx)
+	allowance, err := chain.TokenAllowance(ctx, client, tokenAddress, fromAddress, spenderAddress)
 	if err != nil {
 		log.Fatal(err)
 	}
+	fmt.Printf("当前授权额度: %s\n", allowance.String())
 
-	fmt.Printf("\n授权交易已发送: %s\n", signedTx.Hash().Hex())
-
-	// 步骤 3: 等待授权交易确认
-	fmt.Println("等待授权交易确认...")
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
-
-	for {
-		receipt, err := client.TransactionReceipt(ctx, signedTx.Hash())
-		if err != nil {
-			time.Sleep(5 * time.Second)
-			continue
-		}
-
-		if receipt.Status == 1 {
-			fmt.Println("\n✅ 授权已确认！")
-		} else {
-			log.Fatal("\n❌ 授权交易失败")
-		}
-		break
-	}
-
-	// 步骤 4: 构建 transferFrom 函数调用数据
-	transferFromSignature := []byte("transferFrom(address,address,uint256)")
-	hash = sha3.NewLegacyKeccak256()
-	hash.Write(transferFromSignature)
-	methodID = hash.Sum(nil)[:4]
-
-	paddedFrom := common.LeftPadBytes(fromAddress.Bytes(), 32)
-	paddedTo := common.LeftPadBytes(toAddress.Bytes(), 32)
-
-	var transferFromData []byte
-	transferFromData = append(transferFromData, methodID...)
-	transferFromData = append(transferFromData, paddedFrom...)
-	transferFromData = append(transferFromData, paddedTo...)
-	transferFromData = append(transferFromData, paddedAmount...)
-
-	// 步骤 5: 发送 transferFrom 交易
-	nonce, _ = client.PendingNonceAt(context.Background(), fromAddress)
-
-	gasLimit, _ = client.EstimateGas(context.Background(), ethereum.CallMsg{
-		To:   &tokenAddress,
-		Data: transferFromData,
-	})
-
-	tx = types.NewTransaction(nonce, tokenAddress, value, gasLimit, gasPrice, transferFromData)
-	signedTx, _ = types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
-
-	err = client.SendTransaction(context.Background(), signedTx)
+	// 步骤 3: 发送 transferFrom 交易（需由 spender 发送，这里 spender 即当前账户）
+	transferTx, err := chain.TransferTokenFrom(ctx, client, privateKey, tokenAddress, fromAddress, toAddress, amount)
 	if err != nil {
 		log.Fatal(err)
 	}
+	fmt.Printf("\n代理转账交易已发送: %s\n", transferTx.Hash().Hex())
 
-	fmt.Printf("\n代理转账交易已发送: %s\n", signedTx.Hash().Hex())
-
-	// 步骤 6: 等待转账交易确认
+	// 步骤 4: 等待转账交易确认
 	fmt.Println("等待转账交易确认...")
-	ctx2, cancel2 := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel2()
-
-	for {
-		receipt, err := client.TransactionReceipt(ctx2, signedTx.Hash())
-		if err != nil {
-			time.Sleep(5 * time.Second)
-			continue
-		}
-
-		if receipt.Status == 1 {
-			fmt.Println("\n✅ 代理转账完成！")
-			fmt.Printf("区块号: %d\n", receipt.BlockNumber.Uint64())
-			fmt.Printf("Gas Used: %d\n", receipt.GasUsed)
-		} else {
-			fmt.Println("\n❌ 转账交易失败")
-		}
-		break
+	receipt := waitReceipt(ctx, client, transferTx.Hash())
+	if receipt.Success() {
+		fmt.Println("\n✅ 代理转账完成！")
+		fmt.Printf("区块号: %d\n", receipt.BlockNumber)
+		fmt.Printf("Gas Used: %d\n", receipt.GasUsed)
+	} else {
+		fmt.Println("\n❌ 转账交易失败")
 	}
 
 	fmt.Println("=== 完成 ===")
 }
+
+// waitReceipt 最多等待 5 分钟，每 5 秒查询一次收据
+func waitReceipt(ctx context.Context, client *ethclient.Client, hash common.Hash) *chain.Receipt {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+	receipt, err := chain.WaitReceipt(ctx, client, hash, 5*time.Second)
+	if err != nil {
+		log.Fatal(err)
+	}
+	return receipt
+}
@@ -0,0 +1,99 @@
+// 04-offline-prepare-token.go - 离线签名：在线端生成代币转账的待签名交易包 - 答案
+//
+// 签名和广播与 ETH 转账完全相同，使用 2.06 的 05-offline-sign.go 和 06-broadcast.go
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+)
+
+// 用法（联网的机器，不需要私钥）：
+//
+//	FROM_ADDRESS=0x... TOKEN_ADDRESS=0x... go run solutions/04-offline-prepare-token.go --amount 100 0xRecipient1 0xRecipient2
+//	go run solutions/04-offline-prepare-token.go --out unsigned.json   # 接收方默认读取 TO_ADDRESS
+func main() {
+	amountStr := flag.String("amount", "1", "每个接收方的代币数量")
+	out := flag.String("out", "unsigned.json", "待签名交易包的输出路径")
+	flag.Parse()
+
+	apiKey := os.Getenv("INFURA_API_KEY")
+	fromHex := os.Getenv("FROM_ADDRESS")
+	tokenHex := os.Getenv("TOKEN_ADDRESS")
+	if apiKey == "" || !common.IsHexAddress(fromHex) || !common.IsHexAddress(tokenHex) {
+		log.Fatal("错误: 请设置环境变量 INFURA_API_KEY, FROM_ADDRESS, TOKEN_ADDRESS")
+	}
+	from := common.HexToAddress(fromHex)
+	tokenAddress := common.HexToAddress(tokenHex)
+
+	recipients := flag.Args()
+	if len(recipients) == 0 && os.Getenv("TO_ADDRESS") != "" {
+		recipients = []string{os.Getenv("TO_ADDRESS")}
+	}
+	if len(recipients) == 0 {
+		log.Fatal("错误: 请在命令行传入接收方地址，或设置环境变量 TO_ADDRESS")
+	}
+
+	client, err := ethclient.Dial("https://sepolia.infura.io/v3/" + apiKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	// 按代币的 decimals 换算数量
+	token, err := chain.GetToken(ctx, client, tokenAddress)
+	if err != nil {
+		log.Fatal(err)
+	}
+	amount, err := chain.ParseUnits(*amountStr, token.Decimals)
+	if err != nil {
+		log.Fatalf("错误: 无法解析代币数量 %s: %v", *amountStr, err)
+	}
+
+	// 代币余额不足时 transfer 会 revert，Gas 估算就会失败，提前给出明确的提示
+	balance, err := chain.TokenBalance(ctx, client, tokenAddress, from)
+	if err != nil {
+		log.Fatal(err)
+	}
+	total := new(big.Int).Mul(amount, big.NewInt(int64(len(recipients))))
+	if balance.Cmp(total) < 0 {
+		log.Fatalf("代币余额不足: %s %s，需要 %s %s", chain.FormatUnits(balance, token.Decimals), token.Symbol, chain.FormatUnits(total, token.Decimals), token.Symbol)
+	}
+
+	// 交易的 to 是代币合约，接收方和数量编码在 transfer 的调用数据里
+	reqs := make([]*chain.TxRequest, len(recipients))
+	for i, r := range recipients {
+		if !common.IsHexAddress(r) {
+			log.Fatalf("无效的地址: %s", r)
+		}
+		reqs[i] = &chain.TxRequest{To: &tokenAddress, Data: chain.TransferData(common.HexToAddress(r), amount)}
+	}
+
+	bundle, err := chain.PrepareBundle(ctx, client, from, reqs)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for i := range bundle.Txs {
+		bundle.Txs[i].Note = fmt.Sprintf("转账 %s %s 给 %s", *amountStr, token.Symbol, recipients[i])
+	}
+
+	if err := bundle.WriteSummary(os.Stdout, nil); err != nil {
+		log.Fatal(err)
+	}
+	if err := bundle.Save(*out); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\n已写入 %s，复制到离线机器后用 2.06 的 05-offline-sign.go 签名\n", *out)
+}
@@ -0,0 +1,163 @@
+// 05-trace-transfer.go - 用 debug_trace* 调试代币转账 - 答案
+//
+// exercises/debug-transfer.go 只能从余额和 revert 原因推测失败的位置；
+// callTracer 会返回完整的调用树，prestateTracer 会返回执行前后的状态差异
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/sigdb"
+)
+
+// 用法（节点需要开放 debug 命名空间，如 geth --dev --http.api eth,debug 或 anvil）：
+//
+//	RPC_URL=http://127.0.0.1:8545 go run solutions/05-trace-transfer.go --amount 1000   # 跟踪一次转账调用，不发送
+//	RPC_URL=http://127.0.0.1:8545 go run solutions/05-trace-transfer.go --tx 0x...      # 跟踪已打包的交易
+//	go run solutions/05-trace-transfer.go --tx 0x... --prestate                         # 同时打印状态变化
+//
+// 没有 RPC_URL 时使用 Infura Sepolia（需要 INFURA_API_KEY，且套餐支持 debug_trace*）
+func main() {
+	txFlag := flag.String("tx", "", "要跟踪的交易哈希；为空时用 debug_traceCall 跟踪一次转账调用")
+	amountStr := flag.String("amount", "1", "跟踪转账调用时的代币数量")
+	prestate := flag.Bool("prestate", false, "同时用 prestateTracer 打印执行前后的状态变化")
+	sigsFlag := flag.String("sigs", "", "额外加载的 ABI / 编译产物 / 文本签名文件（多个用逗号分隔）")
+	flag.Parse()
+
+	url := os.Getenv("RPC_URL")
+	if url == "" {
+		apiKey := os.Getenv("INFURA_API_KEY")
+		if apiKey == "" {
+			log.Fatal("错误: 请设置环境变量 RPC_URL 或 INFURA_API_KEY")
+		}
+		url = "https://sepolia.infura.io/v3/" + apiKey
+	}
+	rpcClient, err := rpc.Dial(url)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer rpcClient.Close()
+	client := ethclient.NewClient(rpcClient)
+
+	registry := sigdb.NewDefault()
+	for _, path := range strings.Split(*sigsFlag, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			if _, err := registry.LoadFile(path); err != nil {
+				log.Fatal(err)
+			}
+		}
+	}
+
+	ctx := context.Background()
+	if *txFlag != "" {
+		traceTx(ctx, rpcClient, registry, common.HexToHash(*txFlag), *prestate)
+		return
+	}
+	msg, err := transferCall(ctx, client, *amountStr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	traceTransfer(ctx, rpcClient, registry, msg, *prestate)
+}
+
+// traceTx 跟踪已打包的交易
+func traceTx(ctx context.Context, c *rpc.Client, registry *sigdb.Registry, hash common.Hash, prestate bool) {
+	frame, err := chain.TraceTransaction(ctx, c, hash)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("=== 调用树 %s ===\n", hash.Hex())
+	frame.WriteTree(os.Stdout, registry)
+
+	if prestate {
+		diff, err := chain.TraceTransactionDiff(ctx, c, hash)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("\n=== 状态变化 ===")
+		diff.WriteDiff(os.Stdout)
+	}
+}
+
+// traceTransfer 在最新区块上跟踪转账调用，不发送交易
+func traceTransfer(ctx context.Context, c *rpc.Client, registry *sigdb.Registry, msg ethereum.CallMsg, prestate bool) {
+	frame, err := chain.TraceCall(ctx, c, msg, nil, nil, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("=== 调用树（debug_traceCall，未发送） ===")
+	frame.WriteTree(os.Stdout, registry)
+
+	if p := frame.RevertPoint(); p != nil {
+		where := "<合约创建>"
+		if p.To != nil {
+			where = p.To.Hex()
+		}
+		fmt.Printf("\n转账会失败，revert 发生在第 %d 层调用 %s\n", depth(frame, p), where)
+		return
+	}
+	if prestate {
+		diff, err := chain.TraceCallDiff(ctx, c, msg, nil, nil, nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("\n=== 状态变化（模拟） ===")
+		diff.WriteDiff(os.Stdout)
+	}
+}
+
+// transferCall 按环境变量构造 transfer 调用：发送方取 FROM_ADDRESS，没有时由 PRIVATE_KEY 推导
+func transferCall(ctx context.Context, client *ethclient.Client, amountStr string) (ethereum.CallMsg, error) {
+	tokenHex, toHex := os.Getenv("TOKEN_ADDRESS"), os.Getenv("TO_ADDRESS")
+	if !common.IsHexAddress(tokenHex) || !common.IsHexAddress(toHex) {
+		return ethereum.CallMsg{}, fmt.Errorf("错误: 请设置环境变量 TOKEN_ADDRESS, TO_ADDRESS")
+	}
+	var from common.Address
+	if fromHex := os.Getenv("FROM_ADDRESS"); common.IsHexAddress(fromHex) {
+		from = common.HexToAddress(fromHex)
+	} else {
+		key, err := crypto.HexToECDSA(strings.TrimPrefix(os.Getenv("PRIVATE_KEY"), "0x"))
+		if err != nil {
+			return ethereum.CallMsg{}, fmt.Errorf("错误: 请设置环境变量 FROM_ADDRESS 或 PRIVATE_KEY")
+		}
+		from = crypto.PubkeyToAddress(key.PublicKey)
+	}
+	token := common.HexToAddress(tokenHex)
+
+	info, err := chain.GetToken(ctx, client, token)
+	if err != nil {
+		return ethereum.CallMsg{}, err
+	}
+	amount, err := chain.ParseUnits(amountStr, info.Decimals)
+	if err != nil {
+		return ethereum.CallMsg{}, fmt.Errorf("错误: 无法解析代币数量 %s: %v", amountStr, err)
+	}
+	fmt.Printf("从 %s 转账 %s %s 给 %s\n\n", from.Hex(), amountStr, info.Symbol, toHex)
+	return ethereum.CallMsg{From: from, To: &token, Data: chain.TransferData(common.HexToAddress(toHex), amount)}, nil
+}
+
+// depth target 在调用树中的层数，根为 1
+func depth(root, target *chain.CallFrame) int {
+	if root == target {
+		return 1
+	}
+	for _, c := range root.Calls {
+		if d := depth(c, target); d > 0 {
+			return d + 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+// 06-debug-transfer.go - 调试 transfer 调用并解码 revert 原因 - 答案
+//
+// exercises/debug-transfer.go 调用失败时只打印节点返回的原始错误；
+// 这里用 util/sigdb 把 revert 数据解码为 Error(string)、Panic(uint256) 或已知的自定义错误
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/sigdb"
+)
+
+// 用法：
+//
+//	export INFURA_API_KEY=... PRIVATE_KEY=... TOKEN_ADDRESS=0x... TO_ADDRESS=0x...
+//	go run solutions/06-debug-transfer.go
+func main() {
+	fmt.Println("=== 调试 Transfer 调用 ===")
+
+	apiKey := os.Getenv("INFURA_API_KEY")
+	privateKeyHex := os.Getenv("PRIVATE_KEY")
+	tokenAddressHex := os.Getenv("TOKEN_ADDRESS")
+	toAddressHex := os.Getenv("TO_ADDRESS")
+
+	if apiKey == "" || privateKeyHex == "" || tokenAddressHex == "" || toAddressHex == "" {
+		log.Fatal("错误: 请设置环境变量 INFURA_API_KEY, PRIVATE_KEY, TOKEN_ADDRESS, TO_ADDRESS")
+	}
+
+	client, err := ethclient.Dial("https://sepolia.infura.io/v3/" + apiKey)
+	if err != nil {
+		log.Fatal("错误: 连接失败", err)
+	}
+	defer client.Close()
+
+	privateKey, err := crypto.HexToECDSA(privateKeyHex)
+	if err != nil {
+		log.Fatal("错误: 解析私钥失败", err)
+	}
+	fromAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
+	tokenAddress := common.HexToAddress(tokenAddressHex)
+	toAddress := common.HexToAddress(toAddressHex)
+	ctx := context.Background()
+
+	// 签名数据库，内置 Error(string) 和 Panic(uint256)；自定义错误可用 sigs.LoadFile 加载合约 ABI 或编译产物
+	sigs := sigdb.NewDefault()
+
+	// 1. 查询发送方余额
+	fmt.Println("\n1. 查询余额...")
+	balance, err := queryBalance(ctx, client, tokenAddress, fromAddress)
+	if err != nil {
+		fmt.Printf("❌ 查询余额失败: %s\n", sigs.DescribeError(err))
+		return
+	}
+	fmt.Printf("发送方余额 (wei): %s\n", balance)
+
+	// 2. 构建 transfer 调用数据
+	fmt.Println("\n2. 构建 transfer 调用...")
+	amount := new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18)) // 1000 个代币（18 位小数）
+	fmt.Printf("转账数量: %s wei\n", amount)
+	if balance.Cmp(amount) < 0 {
+		fmt.Println("⚠️  余额不足，下面的调用预期会 revert")
+	}
+
+	methodID := crypto.Keccak256([]byte("transfer(address,uint256)"))[:4]
+	data := append([]byte{}, methodID...)
+	data = append(data, common.LeftPadBytes(toAddress.Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(amount.Bytes(), 32)...)
+	fmt.Printf("Data: %x\n", data)
+
+	msg := ethereum.CallMsg{From: fromAddress, To: &tokenAddress, Data: data}
+
+	// 3. 模拟调用（不发送交易），失败时把 revert 数据解码为可读的错误原因
+	fmt.Println("\n3. 模拟调用...")
+	result, err := client.CallContract(ctx, msg, nil)
+	if err != nil {
+		fmt.Printf("❌ 调用失败: %s\n", sigs.DescribeError(err))
+		return
+	}
+	fmt.Printf("✅ 调用成功，返回: %x\n", result)
+
+	// 4. 估算 Gas，revert 时节点同样会返回 revert 数据
+	fmt.Println("\n4. 估算 Gas...")
+	gasLimit, err := client.EstimateGas(ctx, msg)
+	if err != nil {
+		fmt.Printf("❌ 估算 Gas 失败: %s\n", sigs.DescribeError(err))
+		return
+	}
+	fmt.Printf("✅ Gas Limit: %d\n", gasLimit)
+}
+
+// queryBalance 通过 balanceOf(address) 查询代币余额（最小单位）
+func queryBalance(ctx context.Context, client *ethclient.Client, token, account common.Address) (*big.Int, error) {
+	data := append(crypto.Keccak256([]byte("balanceOf(address)"))[:4], common.LeftPadBytes(account.Bytes(), 32)...)
+	result, err := client.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, nil)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(result), nil
+}
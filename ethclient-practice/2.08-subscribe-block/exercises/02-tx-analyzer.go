@@ -64,6 +64,7 @@ func main() {
 	//         // 1. 交易数量: len(block.Transactions())
 	//         // 2. 总 Gas 使用: block.GasUsed()
 	//         // 3. 平均 Gas 价格: 遍历所有交易，计算平均值
+	//         // 4. blob 数量: 累加 len(tx.BlobHashes())；blob gas 价格随分叉参数变化: chain.BlobGasPrice(chain.ChainConfig(chainID), block.Header())
 	//
 	//         // TODO: 更新 stats 列表（保持最多10个）
 	//         // 使用 mu.Lock() 和 mu.Unlock() 保护并发访问
@@ -10,6 +10,9 @@ import (
 
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/dapp-learning/ethclient/util/chain"
 )
 
 // BlockStats 区块统计信息
@@ -19,6 +22,9 @@ type BlockStats struct {
 	TxCount      int
 	TotalGasUsed uint64
 	AvgGasPrice  *big.Int
+	BlobCount    int // 区块内 blob 交易携带的 blob 总数
+	BlobGasUsed  uint64
+	BlobGasPrice *big.Int // Cancun 之前的区块或未知网络为 nil
 }
 
 func main() {
@@ -34,6 +40,13 @@ func main() {
 	}
 	defer client.Close()
 
+	// blob gas 价格的参数随分叉变化，按链 ID 选择分叉配置
+	chainID, err := client.ChainID(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+	config := chain.ChainConfig(chainID)
+
 	// 创建通道并订阅
 	headers := make(chan *types.Header)
 	sub, err := client.SubscribeNewHead(context.Background(), headers)
@@ -66,6 +79,7 @@ func main() {
 			txCount := len(block.Transactions())
 			totalGasUsed := block.GasUsed()
 			avgGasPrice := calculateAverageGasPrice(block)
+			blobCount, blobGasUsed, blobGasPrice := blobStats(block, config)
 
 			// 创建统计信息
 			stat := BlockStats{
@@ -74,6 +88,9 @@ func main() {
 				TxCount:      txCount,
 				TotalGasUsed: totalGasUsed,
 				AvgGasPrice:  avgGasPrice,
+				BlobCount:    blobCount,
+				BlobGasUsed:  blobGasUsed,
+				BlobGasPrice: blobGasPrice,
 			}
 
 			// 更新 stats 列表（保持最多10个）
@@ -91,6 +108,11 @@ func main() {
 			if avgGasPrice != nil {
 				fmt.Printf("  平均 Gas 价格: %s Gwei\n", avgGasPrice.String())
 			}
+			if blobGasPrice != nil {
+				blobFee := new(big.Int).Mul(new(big.Int).SetUint64(blobGasUsed), blobGasPrice)
+				fmt.Printf("  Blob: %d 个，Blob Gas %d，价格 %s wei，费用 %s wei\n",
+					blobCount, blobGasUsed, blobGasPrice.String(), blobFee.String())
+			}
 			fmt.Printf("  区块哈希: %s\n", block.Hash().Hex())
 
 			// 打印统计列表
@@ -116,11 +138,27 @@ func calculateAverageGasPrice(block *types.Block) *big.Int {
 	return avg
 }
 
+// blobStats 统计区块内的 blob 数量、blob gas 使用量和 blob gas 价格。
+// blob gas 价格由区块头的 excessBlobGas 和当时生效的分叉参数决定，
+// Cancun 之前的区块或未知网络（config 为 nil）价格返回 nil
+func blobStats(block *types.Block, config *params.ChainConfig) (int, uint64, *big.Int) {
+	count := 0
+	for _, tx := range block.Transactions() {
+		count += len(tx.BlobHashes())
+	}
+
+	var used uint64
+	if block.BlobGasUsed() != nil {
+		used = *block.BlobGasUsed()
+	}
+	return count, used, chain.BlobGasPrice(config, block.Header())
+}
+
 // printStats 打印统计列表
 func printStats(stats []BlockStats) {
 	fmt.Println("\n=== 最近区块统计 ===")
 	for i, s := range stats {
-		fmt.Printf("%d. 区块#%d: %d tx, Gas:%d, Avg:%s Gwei\n",
-			i+1, s.Number, s.TxCount, s.TotalGasUsed, s.AvgGasPrice.String())
+		fmt.Printf("%d. 区块#%d: %d tx, Gas:%d, Avg:%s Gwei, Blob:%d\n",
+			i+1, s.Number, s.TxCount, s.TotalGasUsed, s.AvgGasPrice.String(), s.BlobCount)
 	}
 }
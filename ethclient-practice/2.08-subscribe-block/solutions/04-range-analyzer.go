@@ -0,0 +1,494 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/output"
+)
+
+// BlockStats 单个区块的统计信息
+type BlockStats struct {
+	Number         uint64         `json:"number"`
+	Hash           string         `json:"hash"`
+	Time           uint64         `json:"time"`
+	TxCount        int            `json:"txCount"`
+	GasUsed        uint64         `json:"gasUsed"`
+	GasLimit       uint64         `json:"gasLimit"`
+	GasUtilization float64        `json:"gasUtilization"` // GasUsed / GasLimit（百分比）
+	BaseFee        *big.Int       `json:"baseFee"`
+	Burned         *big.Int       `json:"burned"`    // BaseFee × GasUsed
+	MedianTip      *big.Int       `json:"medianTip"` // 区块内优先费中位数
+	TxTypes        map[string]int `json:"txTypes"`   // 交易类型分布
+	Blobs          int            `json:"blobs"`     // blob 交易携带的 blob 总数
+	BlobGasUsed    uint64         `json:"blobGasUsed"`
+	BlobGasPrice   *big.Int       `json:"blobGasPrice"` // 由 excessBlobGas 和当时的分叉参数决定，Cancun 之前或未知网络为 nil（JSON 中为 null）
+	BlobFees       *big.Int       `json:"blobFees"`     // BlobGasPrice × BlobGasUsed，同样全部销毁，价格未知时为 nil
+
+	tips []*big.Int
+}
+
+// AddressStat 地址维度的统计（发送者 / 接收者）
+type AddressStat struct {
+	Address string   `json:"address"`
+	TxCount int      `json:"txCount"`
+	Value   *big.Int `json:"value"`
+}
+
+// RangeSummary 整个区块范围的汇总
+type RangeSummary struct {
+	From              uint64              `json:"from"`
+	To                uint64              `json:"to"`
+	Blocks            int                 `json:"blocks"`
+	TxCount           int                 `json:"txCount"`
+	GasUsed           uint64              `json:"gasUsed"`
+	AvgUtilization    float64             `json:"avgUtilization"`
+	TotalBurned       *big.Int            `json:"totalBurned"`
+	BaseFeeFirst      *big.Int            `json:"baseFeeFirst"`
+	BaseFeeLast       *big.Int            `json:"baseFeeLast"`
+	BaseFeeMin        *big.Int            `json:"baseFeeMin"`
+	BaseFeeMax        *big.Int            `json:"baseFeeMax"`
+	BaseFeeChange     float64             `json:"baseFeeChange"` // 首尾区块 BaseFee 变化（百分比）
+	TipPercentiles    map[string]*big.Int `json:"tipPercentiles"`
+	TxTypes           map[string]int      `json:"txTypes"`
+	TopSenders        []AddressStat       `json:"topSenders"`
+	TopReceivers      []AddressStat       `json:"topReceivers"`
+	ContractCreations int                 `json:"contractCreations"`
+	Blobs             int                 `json:"blobs"`
+	BlobGasUsed       uint64              `json:"blobGasUsed"`
+	TotalBlobFees     *big.Int            `json:"totalBlobFees"` // 只累加 blob 价格已知的区块，全部未知时为 nil
+}
+
+// Report 完整的分析报告（JSON 输出使用）
+type Report struct {
+	Summary RangeSummary  `json:"summary"`
+	Blocks  []*BlockStats `json:"blocks"`
+}
+
+// tipPercentiles 需要计算的优先费百分位
+var tipPercentiles = []int{10, 25, 50, 75, 90}
+
+func main() {
+	rpcURL := flag.String("rpc", os.Getenv("SEPOLIA_RPC_URL"), "节点 RPC 地址（默认读取 SEPOLIA_RPC_URL）")
+	from := flag.Int64("from", -1, "起始区块号（包含）")
+	to := flag.Int64("to", -1, "结束区块号（包含），默认最新区块")
+	last := flag.Uint64("last", 10, "未指定 --from 时，分析最近 N 个区块")
+	workers := flag.Int("workers", 8, "并发获取区块的 goroutine 数量")
+	top := flag.Int("top", 5, "输出前 N 个发送者 / 接收者")
+	format := output.FormatTable
+	flag.Var(&format, "format", output.FlagUsage)
+	outPath := flag.String("out", "", "输出文件路径，默认输出到终端")
+	flag.Parse()
+
+	if *rpcURL == "" {
+		log.Fatal("错误: 请通过 --rpc 或环境变量 SEPOLIA_RPC_URL 指定节点地址")
+	}
+	if *workers < 1 {
+		log.Fatal("错误: --workers 必须大于 0")
+	}
+
+	client, err := ethclient.Dial(*rpcURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	// 确定区块范围
+	toBlock := uint64(*to)
+	if *to < 0 {
+		toBlock, err = client.BlockNumber(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	fromBlock := uint64(*from)
+	if *from < 0 {
+		if *last == 0 {
+			log.Fatal("错误: --last 必须大于 0")
+		}
+		fromBlock = 0
+		if toBlock+1 > *last {
+			fromBlock = toBlock + 1 - *last
+		}
+	}
+	if fromBlock > toBlock {
+		log.Fatalf("错误: 起始区块 %d 大于结束区块 %d", fromBlock, toBlock)
+	}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	signer := types.LatestSignerForChainID(chainID)
+
+	log.Printf("分析区块 #%d - #%d（%d 个区块，%d 个并发）", fromBlock, toBlock, toBlock-fromBlock+1, *workers)
+
+	blocks, err := fetchBlocks(ctx, client, fromBlock, toBlock, *workers)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// blob gas 价格的参数随分叉变化（Cancun、Prague、BPO...），按链 ID 选择分叉配置
+	report := analyze(blocks, signer, chain.ChainConfig(chainID), *top)
+
+	// 选择输出目标
+	var w io.Writer = os.Stdout
+	if *outPath != "" {
+		f, err := os.Create(*outPath)
+		if err != nil {
+			log.Fatal(err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	if err := writeReport(w, format, report); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// fetchBlocks 使用固定数量的 worker 并发获取区块，结果按区块号排序。
+// 任一区块获取失败时立即取消 ctx：正在进行的请求被中断，其余 worker 不再领取新任务
+func fetchBlocks(ctx context.Context, client *ethclient.Client, from, to uint64, workers int) ([]*types.Block, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	count := int(to - from + 1)
+	blocks := make([]*types.Block, count)
+	jobs := make(chan int)
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				number := new(big.Int).SetUint64(from + uint64(idx))
+				block, err := client.BlockByNumber(ctx, number)
+				if err != nil {
+					fail(fmt.Errorf("获取区块 #%s 失败: %w", number, err))
+					return
+				}
+				blocks[idx] = block
+			}
+		}()
+	}
+
+dispatch:
+	for i := 0; i < count; i++ {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	// 外部 ctx 被取消时，分发循环提前退出，部分区块未获取
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return blocks, nil
+}
+
+// analyze 计算每个区块以及整个范围的统计数据
+func analyze(blocks []*types.Block, signer types.Signer, config *params.ChainConfig, top int) *Report {
+	report := &Report{Blocks: make([]*BlockStats, 0, len(blocks))}
+	summary := &report.Summary
+	summary.TotalBurned = new(big.Int)
+	summary.TxTypes = make(map[string]int)
+
+	senders := make(map[common.Address]*AddressStat)
+	receivers := make(map[common.Address]*AddressStat)
+	var allTips []*big.Int
+	var utilizationSum float64
+
+	for _, block := range blocks {
+		stat := blockStats(block, config)
+		report.Blocks = append(report.Blocks, stat)
+
+		summary.TxCount += stat.TxCount
+		summary.GasUsed += stat.GasUsed
+		summary.TotalBurned.Add(summary.TotalBurned, stat.Burned)
+		summary.Blobs += stat.Blobs
+		summary.BlobGasUsed += stat.BlobGasUsed
+		if stat.BlobFees != nil {
+			if summary.TotalBlobFees == nil {
+				summary.TotalBlobFees = new(big.Int)
+			}
+			summary.TotalBlobFees.Add(summary.TotalBlobFees, stat.BlobFees)
+		}
+		utilizationSum += stat.GasUtilization
+		allTips = append(allTips, stat.tips...)
+		for name, n := range stat.TxTypes {
+			summary.TxTypes[name] += n
+		}
+
+		// BaseFee 走势
+		if summary.BaseFeeFirst == nil {
+			summary.BaseFeeFirst = stat.BaseFee
+			summary.BaseFeeMin = stat.BaseFee
+			summary.BaseFeeMax = stat.BaseFee
+		}
+		summary.BaseFeeLast = stat.BaseFee
+		if stat.BaseFee.Cmp(summary.BaseFeeMin) < 0 {
+			summary.BaseFeeMin = stat.BaseFee
+		}
+		if stat.BaseFee.Cmp(summary.BaseFeeMax) > 0 {
+			summary.BaseFeeMax = stat.BaseFee
+		}
+
+		// 发送者 / 接收者统计
+		for _, tx := range block.Transactions() {
+			if from, err := types.Sender(signer, tx); err == nil {
+				addAddressStat(senders, from, tx.Value())
+			}
+			if tx.To() == nil {
+				summary.ContractCreations++
+				continue
+			}
+			addAddressStat(receivers, *tx.To(), tx.Value())
+		}
+	}
+
+	if len(blocks) > 0 {
+		summary.From = blocks[0].NumberU64()
+		summary.To = blocks[len(blocks)-1].NumberU64()
+		summary.Blocks = len(blocks)
+		summary.AvgUtilization = utilizationSum / float64(len(blocks))
+	}
+	if summary.BaseFeeFirst != nil && summary.BaseFeeFirst.Sign() > 0 {
+		change := new(big.Float).Quo(
+			new(big.Float).SetInt(new(big.Int).Sub(summary.BaseFeeLast, summary.BaseFeeFirst)),
+			new(big.Float).SetInt(summary.BaseFeeFirst),
+		)
+		summary.BaseFeeChange, _ = new(big.Float).Mul(change, big.NewFloat(100)).Float64()
+	}
+
+	sortBigInts(allTips)
+	summary.TipPercentiles = make(map[string]*big.Int, len(tipPercentiles))
+	for _, p := range tipPercentiles {
+		summary.TipPercentiles["p"+strconv.Itoa(p)] = percentile(allTips, p)
+	}
+
+	summary.TopSenders = topAddresses(senders, top)
+	summary.TopReceivers = topAddresses(receivers, top)
+	return report
+}
+
+// blockStats 计算单个区块的 Gas、BaseFee、销毁量、优先费、交易类型和 blob 费用。
+// config 为 nil（未知网络）时无法确定 blob 参数，blob gas 价格和费用为 nil
+func blockStats(block *types.Block, config *params.ChainConfig) *BlockStats {
+	baseFee := block.BaseFee()
+	if baseFee == nil {
+		// London 升级之前的区块没有 BaseFee
+		baseFee = new(big.Int)
+	}
+
+	stat := &BlockStats{
+		Number:   block.NumberU64(),
+		Hash:     block.Hash().Hex(),
+		Time:     block.Time(),
+		TxCount:  len(block.Transactions()),
+		GasUsed:  block.GasUsed(),
+		GasLimit: block.GasLimit(),
+		BaseFee:  baseFee,
+		Burned:   new(big.Int).Mul(baseFee, new(big.Int).SetUint64(block.GasUsed())),
+		TxTypes:  make(map[string]int),
+	}
+	if block.GasLimit() > 0 {
+		stat.GasUtilization = float64(block.GasUsed()) / float64(block.GasLimit()) * 100
+	}
+
+	// Cancun 升级之前的区块没有 excessBlobGas 和 blobGasUsed；未知网络无法确定价格，
+	// 价格和费用保持 nil，不能记为 0，否则会和“blob gas 价格确实为 0”混淆
+	if used := block.BlobGasUsed(); used != nil {
+		stat.BlobGasUsed = *used
+	}
+	if price := chain.BlobGasPrice(config, block.Header()); price != nil {
+		stat.BlobGasPrice = price
+		stat.BlobFees = new(big.Int).Mul(price, new(big.Int).SetUint64(stat.BlobGasUsed))
+	}
+
+	for _, tx := range block.Transactions() {
+		stat.TxTypes[chain.TxTypeName(tx.Type())]++
+		stat.Blobs += len(tx.BlobHashes())
+
+		// 实际支付给验证者的优先费 = min(GasTipCap, GasFeeCap - BaseFee)
+		tip, err := tx.EffectiveGasTip(baseFee)
+		if err != nil {
+			continue
+		}
+		stat.tips = append(stat.tips, tip)
+	}
+
+	tips := append([]*big.Int(nil), stat.tips...)
+	sortBigInts(tips)
+	stat.MedianTip = percentile(tips, 50)
+	return stat
+}
+
+// addAddressStat 累加地址的交易次数和金额
+func addAddressStat(stats map[common.Address]*AddressStat, addr common.Address, value *big.Int) {
+	stat, ok := stats[addr]
+	if !ok {
+		stat = &AddressStat{Address: addr.Hex(), Value: new(big.Int)}
+		stats[addr] = stat
+	}
+	stat.TxCount++
+	stat.Value.Add(stat.Value, value)
+}
+
+// topAddresses 按交易次数排序（次数相同按金额），返回前 n 个地址
+func topAddresses(stats map[common.Address]*AddressStat, n int) []AddressStat {
+	list := make([]AddressStat, 0, len(stats))
+	for _, s := range stats {
+		list = append(list, *s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].TxCount != list[j].TxCount {
+			return list[i].TxCount > list[j].TxCount
+		}
+		if c := list[i].Value.Cmp(list[j].Value); c != 0 {
+			return c > 0
+		}
+		return list[i].Address < list[j].Address
+	})
+	if len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
+
+// sortBigInts 升序排序
+func sortBigInts(values []*big.Int) {
+	sort.Slice(values, func(i, j int) bool {
+		return values[i].Cmp(values[j]) < 0
+	})
+}
+
+// percentile 使用最近排名法计算百分位，values 需已升序排序
+func percentile(values []*big.Int, p int) *big.Int {
+	if len(values) == 0 {
+		return new(big.Int)
+	}
+	rank := (p*len(values) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return values[rank-1]
+}
+
+// writeReport 按格式输出：table 为文字报告，json 为完整报告，jsonl / csv 每个区块一行（金额单位为 Wei）
+func writeReport(w io.Writer, format output.Format, report *Report) error {
+	switch format {
+	case output.FormatTable:
+		printTable(w, report)
+		return nil
+	case output.FormatJSON:
+		return output.WriteJSON(w, report)
+	}
+	ow := output.NewWriter(w, format)
+	for _, b := range report.Blocks {
+		if err := ow.Write(b); err != nil {
+			return err
+		}
+	}
+	return ow.Flush()
+}
+
+// gwei 将 Wei 格式化为 Gwei
+func gwei(wei *big.Int) string {
+	return chain.FormatUnits(wei, 9)
+}
+
+// printTable 以表格形式打印报告
+func printTable(w io.Writer, report *Report) {
+	s := report.Summary
+
+	fmt.Fprintln(w, "=== 区块统计 ===")
+	t := output.NewTable("区块", "交易", "Gas 使用", "利用率", "BaseFee(Gwei)", "销毁(ETH)", "Tip中位(Gwei)")
+	for _, b := range report.Blocks {
+		t.Add(strconv.FormatUint(b.Number, 10), strconv.Itoa(b.TxCount), strconv.FormatUint(b.GasUsed, 10),
+			fmt.Sprintf("%.2f%%", b.GasUtilization), gwei(b.BaseFee), chain.FormatEther(b.Burned), gwei(b.MedianTip))
+	}
+	t.Write(w)
+
+	fmt.Fprintln(w, "\n=== 范围汇总 ===")
+	fmt.Fprintf(w, "区块范围: #%d - #%d（%d 个区块）\n", s.From, s.To, s.Blocks)
+	fmt.Fprintf(w, "交易总数: %d（合约创建 %d）\n", s.TxCount, s.ContractCreations)
+	fmt.Fprintf(w, "Gas 使用: %d，平均利用率 %.2f%%\n", s.GasUsed, s.AvgUtilization)
+	fmt.Fprintf(w, "总销毁: %s ETH\n", chain.FormatEther(s.TotalBurned))
+	if s.Blobs > 0 {
+		fees := "未知（未知网络的 blob 参数）"
+		if s.TotalBlobFees != nil {
+			fees = chain.FormatEther(s.TotalBlobFees) + " ETH"
+		}
+		fmt.Fprintf(w, "Blob: %d 个，Blob Gas %d，Blob 费用 %s\n", s.Blobs, s.BlobGasUsed, fees)
+	}
+	if s.BaseFeeFirst != nil {
+		fmt.Fprintf(w, "BaseFee 走势: %s → %s Gwei（%+.2f%%），最低 %s，最高 %s\n",
+			gwei(s.BaseFeeFirst), gwei(s.BaseFeeLast), s.BaseFeeChange, gwei(s.BaseFeeMin), gwei(s.BaseFeeMax))
+	}
+
+	fmt.Fprintln(w, "\n优先费百分位 (Gwei):")
+	for _, p := range tipPercentiles {
+		key := "p" + strconv.Itoa(p)
+		fmt.Fprintf(w, "  %s: %s\n", key, gwei(s.TipPercentiles[key]))
+	}
+
+	fmt.Fprintln(w, "\n交易类型分布:")
+	for _, name := range sortedKeys(s.TxTypes) {
+		fmt.Fprintf(w, "  %-10s %d\n", name, s.TxTypes[name])
+	}
+
+	fmt.Fprintln(w, "\n最活跃的发送者:")
+	for i, a := range s.TopSenders {
+		fmt.Fprintf(w, "  %d. %s - %d 笔，%s ETH\n", i+1, a.Address, a.TxCount, chain.FormatEther(a.Value))
+	}
+	fmt.Fprintln(w, "\n最活跃的接收者:")
+	for i, a := range s.TopReceivers {
+		fmt.Fprintf(w, "  %d. %s - %d 笔，%s ETH\n", i+1, a.Address, a.TxCount, chain.FormatEther(a.Value))
+	}
+}
+
+// sortedKeys 返回按字母排序的 map 键
+func sortedKeys(m map[string]int) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
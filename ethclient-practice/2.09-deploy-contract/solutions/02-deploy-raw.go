@@ -3,22 +3,35 @@ package main
 import (
 	"context"
 	"encoding/hex"
+	"flag"
 	"fmt"
 	"log"
 	"math/big"
 	"os"
 	"time"
 
-	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/sigdb"
 )
 
 // Store 合约的字节码（编译后生成）
 const contractBytecode = "608060405234801561000f575f80fd5b5060405161087538038061087583398181016040528101906100319190610193565b805f908161003f91906103e7565b50506104b6565b5f604051905090565b5f80fd5b5f80fd5b5f80fd5b5f80fd5b5f601f19601f8301169050919050565b7f4e487b71000000000000000000000000000000000000000000000000000000005f52604160045260245ffd5b6100a58261005f565b810181811067ffffffffffffffff821117156100c4576100c361006f565b5b80604052505050565b5f6100d6610046565b90506100e2828261009c565b919050565b5f67ffffffffffffffff8211156101015761010061006f565b5b61010a8261005f565b9050602081019050919050565b8281835e5f83830152505050565b5f610137610132846100e7565b6100cd565b9050828152602081018484840111156101535761015261005b565b5b61015e848285610117565b509392505050565b5f82601f83011261017a57610179610057565b5b815161018a848260208601610125565b91505092915050565b5f602082840312156101a8576101a761004f565b5b5f82015167ffffffffffffffff8111156101c5576101c4610053565b5b6101d184828501610166565b91505092915050565b5f81519050919050565b7f4e487b71000000000000000000000000000000000000000000000000000000005f52602260045260245ffd5b5f600282049050600182168061022857607f821691505b60208210810361023b5761023a6101e4565b5b50919050565b5f819050815f5260205f209050919050565b5f6020601f8301049050919050565b5f82821b905092915050565b5f6008830261029d7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff82610262565b6102a78683610262565b95508019841693508086168417925050509392505050565b5f819050919050565b5f819050919050565b5f6102eb6102e66102e1846102bf565b6102c8565b6102bf565b9050919050565b5f819050919050565b610304836102d1565b610318610310826102f2565b84845461026e565b825550505050565b5f90565b61032c610320565b6103378184846102fb565b505050565b5b8181101561035a5761034f5f82610324565b60018101905061033d565b5050565b601f82111561039f5761037081610241565b61037984610253565b81016020851015610388578190505b61039c61039485610253565b83018261033c565b50505b505050565b5f82821c905092915050565b5f6103bf5f19846008026103a4565b1980831691505092915050565b5f6103d783836103b0565b9150826002028217905092915050565b6103f0826101da565b67ffffffffffffffff8111156104095761040861006f565b5b6104138254610211565b61041e82828561035e565b5f60209050601f83116001811461044f575f841561043d578287015190505b61044785826103cc565b8655506104ae565b601f19841661045d86610241565b5f5b828110156104845784890151825560018201915060208501945060208101905061045f565b868310156104a1578489015161049d601f8916826103b0565b8355505b6001600288020188555050505b505050505050565b6103b2806104c35f395ff3fe608060405234801561000f575f80fd5b506004361061003f575f3560e01c806348f343f31461004357806354fd4d5014610073578063f56256c714610091575b5f80fd5b61005d600480360381019061005891906101d7565b6100ad565b60405161006a9190610211565b60405180910390f35b61007b6100c2565b604051610088919061029a565b60405180910390f35b6100ab60048036038101906100a691906102ba565b61014d565b005b6001602052805f5260405f205f915090505481565b5f80546100ce90610325565b80601f01602080910402602001604051908101604052809291908181526020018280546100fa90610325565b80156101455780601f1061011c57610100808354040283529160200191610145565b820191905f5260205f20905b81548152906001019060200180831161012857829003601f168201915b505050505081565b8060015f8481526020019081526020015f20819055507fe79e73da417710ae99aa2088575580a60415d359acfad9cdd3382d59c80281d48282604051610194929190610355565b60405180910390a15050565b5f80fd5b5f819050919050565b6101b6816101a4565b81146101c0575f80fd5b50565b5f813590506101d1816101ad565b92915050565b5f602082840312156101ec576101eb6101a0565b5b5f6101f9848285016101c3565b91505092915050565b61020b816101a4565b82525050565b5f6020820190506102245f830184610202565b92915050565b5f81519050919050565b5f82825260208201905092915050565b8281835e5f83830152505050565b5f601f19601f8301169050919050565b5f61026c8261022a565b6102768185610234565b9350610286818560208601610244565b61028f81610252565b840191505092915050565b5f6020820190508181035f8301526102b28184610262565b905092915050565b5f80604083850312156102d0576102cf6101a0565b5b5f6102dd858286016101c3565b92505060206102ee858286016101c3565b9150509250929050565b7f4e487b71000000000000000000000000000000000000000000000000000000005f52602260045260245ffd5b5f600282049050600182168061033c57607f821691505b60208210810361034f5761034e6102f8565b5b50919050565b5f6040820190506103685f830185610202565b6103756020830184610202565b939250505056fea26469706673582212205aae308f77654b000c9d222eff2d9f2bd2ac18d990b10774842e4309d4e3e15664736f6c634300081a0033"
 
+// 用法：
+//
+//	go run solutions/02-deploy-raw.go
+//	go run solutions/02-deploy-raw.go --version 2.0 --dry-run   # 签名并模拟部署，不发送
 func main() {
+	version := flag.String("version", "1.0", "构造参数 _version")
+	dryRun := flag.Bool("dry-run", false, "只签名并在 pending 状态上模拟执行，打印签名交易，不发送")
+	flag.Parse()
+
 	privateKeyHex := os.Getenv("PRIVATE_KEY")
 	if privateKeyHex == "" {
 		log.Fatal("错误: 请设置环境变量 PRIVATE_KEY")
@@ -41,14 +54,7 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
-
-	publicKey := privateKey.Public()
-	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
-	if !ok {
-		log.Fatal("error casting public key to ECDSA")
-	}
-
-	fromAddress := crypto.PubkeyToAddress(*publicKeyECDSA)
+	fromAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
 
 	// 获取 nonce
 	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
@@ -62,27 +68,42 @@ func main() {
 		log.Fatal(err)
 	}
 
-	// 解码合约字节码
-	data, err := hex.DecodeString(contractBytecode)
+	// 部署数据 = 合约字节码 + ABI 编码的构造参数。
+	// Store 的构造函数需要一个 string，缺少参数时构造函数解码失败，部署会 revert
+	code, err := hex.DecodeString(contractBytecode)
 	if err != nil {
 		log.Fatal(err)
 	}
+	stringType, _ := abi.NewType("string", "", nil)
+	ctorArgs, err := abi.Arguments{{Type: stringType}}.Pack(*version)
+	if err != nil {
+		log.Fatal(err)
+	}
+	data := append(code, ctorArgs...)
 
 	fmt.Printf("发送者地址: %s\n", fromAddress.Hex())
 	fmt.Printf("当前 Nonce: %d\n", nonce)
 	fmt.Printf("Gas 价格: %s Wei\n", gasPrice.String())
 
+	// 估算 Gas，而不是写死一个上限。估算失败说明部署会 revert，解码 revert 原因
+	estimated, err := client.EstimateGas(context.Background(), ethereum.CallMsg{From: fromAddress, Data: data})
+	if err != nil {
+		log.Fatalf("估算 Gas 失败: %s", sigdb.NewDefault().DescribeError(err))
+	}
+	gasLimit := chain.WithMargin(estimated, 0)
+	fmt.Printf("Gas 上限: %d（估算 %d）\n", gasLimit, estimated)
+
 	// 创建合约部署交易（to 地址为 nil）
 	tx := types.NewContractCreation(
 		nonce,
-		big.NewInt(0),  // value
-		3000000,        // gasLimit
-		gasPrice,       // gasPrice
-		data,           // 合约字节码
+		big.NewInt(0), // value
+		gasLimit,      // gasLimit
+		gasPrice,      // gasPrice
+		data,          // 合约字节码 + 构造参数
 	)
 
 	// 获取链 ID 并签名交易
-	chainID, err := client.NetworkID(context.Background())
+	chainID, err := client.ChainID(context.Background())
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -92,6 +113,17 @@ func main() {
 		log.Fatal(err)
 	}
 
+	// dry-run：在 pending 状态上模拟部署，打印部署地址和签名交易，不发送
+	if *dryRun {
+		sim, err := chain.SimulateTx(context.Background(), client, signedTx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("\n=== Dry run（未发送） ===")
+		sim.WriteReport(os.Stdout, nil)
+		return
+	}
+
 	// 发送交易
 	err = client.SendTransaction(context.Background(), signedTx)
 	if err != nil {
@@ -102,33 +134,21 @@ func main() {
 	fmt.Println("等待交易确认...")
 
 	// 等待交易确认
-	receipt, err := waitForReceipt(client, signedTx.Hash())
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+	receipt, err := chain.WaitReceipt(ctx, client, signedTx.Hash(), 2*time.Second)
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	if receipt.Status == 0 {
+	if !receipt.Success() {
 		log.Fatal("合约部署失败！")
 	}
 
 	fmt.Println("\n✓ 合约部署成功！")
 	fmt.Printf("✓ 合约地址: %s\n", receipt.ContractAddress.Hex())
 	fmt.Printf("✓ 交易哈希: %s\n", receipt.TxHash.Hex())
-	fmt.Printf("✓ 区块号: %d\n", receipt.BlockNumber.Uint64())
+	fmt.Printf("✓ 区块号: %d\n", receipt.BlockNumber)
 	fmt.Printf("✓ Gas 使用: %d\n", receipt.GasUsed)
 	fmt.Printf("✓ 实际 Gas 价格: %s Wei\n", receipt.EffectiveGasPrice.String())
 }
-
-func waitForReceipt(client *ethclient.Client, txHash common.Hash) (*types.Receipt, error) {
-	for {
-		receipt, err := client.TransactionReceipt(context.Background(), txHash)
-		if err == nil {
-			return receipt, nil
-		}
-		if err.Error() != "not found" {
-			return nil, err
-		}
-		fmt.Print(".")
-		time.Sleep(2 * time.Second)
-	}
-}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/abiarg"
+	"github.com/dapp-learning/ethclient/util/deploy"
+)
+
+// 用法：
+//
+//	go run solutions/04-deploy-artifact.go [flags] [构造参数...]
+//	go run solutions/04-deploy-artifact.go --artifact contract/Store_sol_Store.abi 1.0
+//	go run solutions/04-deploy-artifact.go --artifact out/Store.sol/Store.json "v2.0"
+func main() {
+	artifactPath := flag.String("artifact", "contract/Store_sol_Store.abi", "编译产物：Hardhat / Foundry JSON、solc 输出，或 solcjs 的 .abi/.bin")
+	contractName := flag.String("contract", "", "产物中包含多个合约时指定合约名")
+	registryPath := flag.String("registry", deploy.DefaultRegistryFile, "部署记录文件")
+	valueFlag := flag.String("value", "0", "随部署发送的 ETH，如 0.01ether")
+	gasLimit := flag.Uint64("gas-limit", 0, "Gas 上限，0 表示自动估算")
+	timeout := flag.Duration("timeout", 5*time.Minute, "等待确认的超时时间")
+	flag.Parse()
+
+	privateKeyHex := os.Getenv("PRIVATE_KEY")
+	if privateKeyHex == "" {
+		log.Fatal("错误: 请设置环境变量 PRIVATE_KEY")
+	}
+
+	rpcURL := os.Getenv("SEPOLIA_RPC_URL")
+	if rpcURL == "" {
+		log.Fatal("错误: 请设置环境变量 SEPOLIA_RPC_URL")
+	}
+
+	// 1. 读取编译产物
+	art, err := deploy.LoadArtifact(*artifactPath, *contractName)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// 2. 按构造函数的 ABI 把命令行参数转换为 Go 值
+	args, err := abiarg.ParseArgs(art.ABI.Constructor.Inputs, flag.Args())
+	if err != nil {
+		log.Fatalf("构造参数错误: %v", err)
+	}
+	value, err := abiarg.ParseInt(*valueFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	client, err := ethclient.Dial(rpcURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	privateKey, err := crypto.HexToECDSA(privateKeyHex)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	deployer, err := deploy.NewDeployer(ctx, client, privateKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("合约: %s（%s，%s）\n", art.Name, art.Format, art.Path)
+	fmt.Printf("网络: %s（链 ID %s）\n", deployer.Network(), deployer.ChainID())
+	fmt.Printf("部署账户: %s\n", deployer.From().Hex())
+	if art.ABI.Constructor.Inputs != nil {
+		fmt.Printf("构造函数: %s\n", art.ABI.Constructor.Sig)
+	}
+
+	// 3. 估算 Gas、签名并发送
+	opts := &deploy.Options{Value: value, GasLimit: *gasLimit}
+	tx, predicted, err := deployer.Send(ctx, art, opts, args...)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\n交易已发送: %s\n", tx.Hash().Hex())
+	fmt.Printf("Gas 上限: %d\n", tx.Gas())
+	fmt.Printf("预期地址: %s\n", predicted.Hex())
+	fmt.Println("等待交易确认...")
+
+	// 4. 等待确认
+	d, err := deployer.Wait(ctx, art.Name, tx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	d.Args = flag.Args()
+	d.Artifact = *artifactPath
+
+	fmt.Println("\n✓ 合约部署成功！")
+	fmt.Printf("✓ 合约地址: %s\n", d.Address.Hex())
+	fmt.Printf("✓ 区块号: %d\n", d.Block)
+	fmt.Printf("✓ Gas 使用: %d\n", d.GasUsed)
+	fmt.Printf("✓ 运行时代码哈希: %s\n", d.BytecodeHash.Hex())
+
+	// 5. 写入部署记录
+	registry, err := deploy.OpenRegistry(*registryPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	registry.Add(*d)
+	if err := registry.Save(); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("✓ 已记录到 %s（%s/%s）\n", registry.Path(), d.Network, d.Name)
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"runtime"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/abiarg"
+	"github.com/dapp-learning/ethclient/util/deploy"
+)
+
+// 用法：
+//
+//	# 只预测地址（不需要私钥和 RPC）
+//	go run solutions/05-create2.go --predict --salt my-salt 1.0
+//	# 搜索以 0x0000 开头的地址并部署
+//	go run solutions/05-create2.go --mine 0000 1.0
+//	# 通过自己的工厂合约部署：function deploy(bytes32 salt, bytes code)
+//	go run solutions/05-create2.go --factory 0x... --factory-abi Factory.json --factory-method deploy --salt 0x01 1.0
+func main() {
+	artifactPath := flag.String("artifact", "contract/Store_sol_Store.abi", "编译产物")
+	contractName := flag.String("contract", "", "产物中包含多个合约时指定合约名")
+	saltFlag := flag.String("salt", "0x00", "salt：0x 十六进制，或任意文本（取 keccak256）")
+	mine := flag.String("mine", "", "搜索地址前缀（十六进制），找到后使用该 salt")
+	workers := flag.Int("workers", runtime.NumCPU(), "搜索 salt 的 goroutine 数")
+	factoryFlag := flag.String("factory", deploy.DeterministicDeployer.Hex(), "CREATE2 工厂地址，默认为确定性部署代理")
+	factoryABI := flag.String("factory-abi", "", "自定义工厂的 ABI / 编译产物；为空时按代理格式 salt ++ initcode 调用")
+	factoryMethod := flag.String("factory-method", "deploy", "自定义工厂的部署方法，参数须为 (bytes32, bytes)")
+	predictOnly := flag.Bool("predict", false, "只计算地址，不发送交易")
+	registryPath := flag.String("registry", deploy.DefaultRegistryFile, "部署记录文件")
+	flag.Parse()
+
+	art, err := deploy.LoadArtifact(*artifactPath, *contractName)
+	if err != nil {
+		log.Fatal(err)
+	}
+	args, err := abiarg.ParseArgs(art.ABI.Constructor.Inputs, flag.Args())
+	if err != nil {
+		log.Fatalf("构造参数错误: %v", err)
+	}
+	// CREATE2 地址取决于完整的 initcode，构造参数不同地址也不同
+	initCode, err := art.DeployData(args...)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if !common.IsHexAddress(*factoryFlag) {
+		log.Fatalf("无效的工厂地址 %q", *factoryFlag)
+	}
+	factory := deploy.Factory{Address: common.HexToAddress(*factoryFlag)}
+	if *factoryABI != "" {
+		parsed, err := loadABI(*factoryABI)
+		if err != nil {
+			log.Fatal(err)
+		}
+		factory = deploy.ABIFactory(factory.Address, parsed, *factoryMethod)
+	}
+
+	salt, err := deploy.ParseSalt(*saltFlag)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *mine != "" {
+		salt = mineSalt(factory.Address, initCode, *mine, *workers)
+	}
+
+	predicted := deploy.PredictCreate2(factory.Address, salt, initCode)
+	fmt.Printf("合约: %s\n", art.Name)
+	fmt.Printf("工厂: %s\n", factory.Address.Hex())
+	fmt.Printf("Salt: %s\n", salt.Hex())
+	fmt.Printf("initcode 哈希: %s\n", crypto.Keccak256Hash(initCode).Hex())
+	fmt.Printf("预测地址: %s\n", predicted.Hex())
+	if *predictOnly {
+		return
+	}
+
+	privateKeyHex := os.Getenv("PRIVATE_KEY")
+	if privateKeyHex == "" {
+		log.Fatal("错误: 请设置环境变量 PRIVATE_KEY")
+	}
+	rpcURL := os.Getenv("SEPOLIA_RPC_URL")
+	if rpcURL == "" {
+		log.Fatal("错误: 请设置环境变量 SEPOLIA_RPC_URL")
+	}
+
+	client, err := ethclient.Dial(rpcURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	privateKey, err := crypto.HexToECDSA(privateKeyHex)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	deployer, err := deploy.NewDeployer(ctx, client, privateKey)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tx, _, err := deployer.SendCreate2(ctx, factory, salt, initCode, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\n交易已发送: %s\n", tx.Hash().Hex())
+	fmt.Println("等待交易确认...")
+
+	d, err := deployer.WaitCreate2(ctx, art.Name, tx, predicted)
+	if err != nil {
+		log.Fatal(err)
+	}
+	d.Args = flag.Args()
+	d.Artifact = *artifactPath
+
+	fmt.Println("\n✓ 合约部署成功！")
+	fmt.Printf("✓ 合约地址: %s（与预测一致）\n", d.Address.Hex())
+	fmt.Printf("✓ 区块号: %d\n", d.Block)
+	fmt.Printf("✓ Gas 使用: %d\n", d.GasUsed)
+
+	registry, err := deploy.OpenRegistry(*registryPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	registry.Add(*d)
+	if err := registry.Save(); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("✓ 已记录到 %s\n", registry.Path())
+}
+
+// mineSalt 多 goroutine 搜索靓号 salt，每秒输出一次进度
+func mineSalt(factory common.Address, initCode []byte, prefix string, workers int) common.Hash {
+	miner := &deploy.SaltMiner{
+		Deployer:     factory,
+		InitCodeHash: crypto.Keccak256Hash(initCode),
+		Prefix:       prefix,
+		Workers:      workers,
+	}
+	fmt.Printf("搜索前缀 %s（%d 个 goroutine，期望约 %.0f 次）...\n", prefix, workers, miner.Expected())
+
+	done := make(chan struct{})
+	start := time.Now()
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				attempts := miner.Attempts()
+				fmt.Printf("  已尝试 %d 次（%.0f 次/秒）\n", attempts, float64(attempts)/time.Since(start).Seconds())
+			}
+		}
+	}()
+
+	result, err := miner.Mine(context.Background())
+	close(done)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("找到: %s（共 %d 次，用时 %s）\n\n", result.Address.Hex(), miner.Attempts(), time.Since(start).Round(time.Millisecond))
+	return result.Salt
+}
+
+// loadABI 读取工厂合约的 ABI，支持纯 ABI 文件和各类编译产物
+func loadABI(path string) (abi.ABI, error) {
+	if art, err := deploy.LoadArtifact(path, ""); err == nil {
+		return art.ABI, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return abi.ABI{}, err
+	}
+	defer f.Close()
+	return abi.JSON(f)
+}
@@ -7,7 +7,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/dapp-learning/ethclient/load-contract/store"
+	"github.com/dapp-learning/ethclient/call-contract/store"
 )
 
 func main() {
@@ -2,16 +2,25 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
-	"github.com/dapp-learning/ethclient/load-contract/store"
+	"github.com/dapp-learning/ethclient/call-contract/store"
+
+	"github.com/dapp-learning/ethclient/util/deploy"
+	"github.com/dapp-learning/ethclient/util/verify"
 )
 
 func main() {
+	// 本地编译产物，默认使用 2.09 中 solcjs 编译 Store.sol 的输出
+	artifactPath := flag.String("artifact", "../2.09-deploy-contract/contract/Store_sol_Store.abi", "编译产物（Hardhat / Foundry / solc / solcjs）")
+	contractName := flag.String("contract", "", "产物中包含多个合约时指定合约名")
+	flag.Parse()
+
 	// 从环境变量获取合约地址
 	contractAddressStr := os.Getenv("CONTRACT_ADDRESS")
 	if contractAddressStr == "" {
@@ -62,5 +71,34 @@ func main() {
 	}
 
 	fmt.Printf("✅ 合约版本: %s\n", version)
-	fmt.Println("✅ 合约验证通过！")
+
+	// 能调用 Version() 只说明 ABI 兼容，不能说明代码来自 Store.sol。
+	// 真正的验证：把链上运行时代码与本地编译产物逐字节比较
+	art, err := deploy.LoadArtifact(*artifactPath, *contractName)
+	if err != nil {
+		log.Fatalf("❌ 读取编译产物失败: %v", err)
+	}
+	result, err := verify.Verify(context.Background(), client, contractAddress, art)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("\n字节码比较（%s，来源: %s）\n", art.Name, result.Source)
+	fmt.Printf("  链上 %d 字节，本地 %d 字节\n", result.OnchainSize, result.LocalSize)
+	if v := verify.SolcVersion(result.OnchainMetadata); v != "" {
+		fmt.Printf("  链上编译器版本: %s\n", v)
+	}
+	if len(result.Masked) > 0 {
+		fmt.Printf("  忽略 %d 处 immutable / 库地址\n", len(result.Masked))
+	}
+
+	switch result.Status {
+	case verify.StatusMatch:
+		fmt.Println("✅ 合约验证通过：字节码与元数据完全一致")
+	case verify.StatusPartial:
+		fmt.Println("⚠️  部分匹配：代码逻辑一致，但元数据不同（源码注释、文件路径或编译设置有差异）")
+	default:
+		fmt.Printf("❌ 字节码不一致（第一个差异在偏移 %d），该地址的合约不是由此产物部署的\n", result.FirstDiff)
+		os.Exit(1)
+	}
 }
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/call-contract/store"
+	"github.com/dapp-learning/ethclient/util/deploy"
+)
+
+func main() {
+	registryPath := flag.String("registry", "../2.09-deploy-contract/"+deploy.DefaultRegistryFile, "2.09 部署时生成的部署记录文件")
+	name := flag.String("name", "Store", "合约名")
+	flag.Parse()
+
+	// 从环境变量获取 RPC URL
+	rpcURL := os.Getenv("SEPOLIA_RPC_URL")
+	if rpcURL == "" {
+		rpcURL = "https://eth-sepolia.g.alchemy.com/v2/YOUR_API_KEY"
+	}
+
+	// 连接到以太坊节点
+	client, err := ethclient.Dial(rpcURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	// 按当前网络和合约名查找地址，不再需要手动设置 CONTRACT_ADDRESS
+	d, err := deploy.Resolve(context.Background(), client, *registryPath, *name)
+	switch {
+	case errors.Is(err, deploy.ErrCodeChanged):
+		// 地址上的代码与部署时不同（例如本地链重启过），提示后继续
+		fmt.Printf("⚠️  %v\n", err)
+	case err != nil:
+		log.Fatal(err)
+	}
+
+	fmt.Printf("✅ 部署记录: %s/%s\n", d.Network, d.Name)
+	fmt.Printf("   地址: %s\n", d.Address.Hex())
+	fmt.Printf("   部署交易: %s（区块 %d）\n", d.TxHash.Hex(), d.Block)
+
+	// 使用记录中的地址加载合约实例
+	storeContract, err := store.NewStore(d.Address, client)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	version, err := storeContract.Version(nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("✅ 合约版本: %s\n", version)
+}
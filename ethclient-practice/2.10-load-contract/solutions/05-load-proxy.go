@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/proxy"
+)
+
+// 用法：
+//
+//	go run solutions/05-load-proxy.go --address 0xProxy
+//	PRIVATE_KEY=... go run solutions/05-load-proxy.go --address 0xProxy --upgrade-to 0xNewImpl
+func main() {
+	address := flag.String("address", os.Getenv("CONTRACT_ADDRESS"), "合约（代理）地址")
+	upgradeTo := flag.String("upgrade-to", "", "升级到的新实现地址，需要 PRIVATE_KEY")
+	initData := flag.String("init-data", "0x", "升级后在新实现上执行的初始化 calldata")
+	flag.Parse()
+
+	if !common.IsHexAddress(*address) {
+		log.Fatal("错误: 请通过 --address 或 CONTRACT_ADDRESS 指定合约地址")
+	}
+	proxyAddr := common.HexToAddress(*address)
+
+	// 从环境变量获取 RPC URL
+	rpcURL := os.Getenv("SEPOLIA_RPC_URL")
+	if rpcURL == "" {
+		rpcURL = "https://eth-sepolia.g.alchemy.com/v2/YOUR_API_KEY"
+	}
+
+	client, err := ethclient.Dial(rpcURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	// 沿代理链解析到最终实现
+	impl, chain, err := proxy.Resolve(ctx, client, proxyAddr)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(chain) == 0 {
+		fmt.Printf("%s 不是代理合约，直接使用该地址加载即可\n", proxyAddr.Hex())
+	} else {
+		fmt.Println("代理链:")
+		for i, info := range chain {
+			fmt.Printf("  %d. %s\n", i+1, info)
+		}
+		fmt.Printf("\n最终实现: %s\n", impl.Hex())
+		fmt.Printf("代理类型: %s\n", chain[0].Kind)
+		fmt.Println("\n提示: 调用时使用代理地址 + 实现合约的 ABI，例如:")
+		fmt.Printf("  store.NewStore(common.HexToAddress(%q), client)\n", proxyAddr.Hex())
+	}
+
+	if *upgradeTo == "" {
+		return
+	}
+	if !common.IsHexAddress(*upgradeTo) {
+		log.Fatalf("无效的新实现地址 %q", *upgradeTo)
+	}
+	newImpl := common.HexToAddress(*upgradeTo)
+	data, err := hexutil.Decode(*initData)
+	if err != nil {
+		log.Fatalf("无效的 --init-data: %v", err)
+	}
+
+	privateKeyHex := os.Getenv("PRIVATE_KEY")
+	if privateKeyHex == "" {
+		log.Fatal("错误: 升级需要设置环境变量 PRIVATE_KEY")
+	}
+	privateKey, err := crypto.HexToECDSA(privateKeyHex)
+	if err != nil {
+		log.Fatal(err)
+	}
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
+	if err != nil {
+		log.Fatal(err)
+	}
+	opts.Context = ctx
+
+	// 先给出升级方案，再发送交易
+	plan, err := proxy.PlanUpgrade(ctx, client, opts.From, proxyAddr, newImpl, data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\n升级 %s 代理 %s\n", plan.Kind, plan.Proxy.Hex())
+	fmt.Printf("  %s → %s\n", plan.OldImplementation.Hex(), plan.NewImplementation.Hex())
+	fmt.Printf("  调用 %s.%s\n", plan.Target.Hex(), plan.Method)
+
+	info, tx, err := proxy.Upgrade(ctx, client, opts, proxyAddr, newImpl, data)
+	if tx != nil {
+		fmt.Printf("  交易: %s\n", tx.Hash().Hex())
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("✓ 升级完成，当前实现: %s\n", info.Implementation.Hex())
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"crypto/ecdsa"
+	"flag"
 	"fmt"
 	"log"
 	"math/big"
@@ -16,6 +17,9 @@ import (
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/sigdb"
 )
 
 const (
@@ -23,7 +27,14 @@ const (
 	storeABI = `[{"inputs":[{"internalType":"bytes32","name":"key","type":"bytes32"},{"internalType":"bytes32","name":"value","type":"bytes32"}],"name":"setItem","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"name":"getItem","outputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"stateMutability":"view","type":"function"}]`
 )
 
+// 用法：
+//
+//	go run solutions/03-manual-call.go
+//	go run solutions/03-manual-call.go --dry-run   # 签名并模拟调用，不发送
 func main() {
+	dryRun := flag.Bool("dry-run", false, "只签名并在 pending 状态上模拟执行，打印签名交易，不发送")
+	flag.Parse()
+
 	// 从环境变量获取配置
 	privateKeyStr := os.Getenv("PRIVATE_KEY")
 	if privateKeyStr == "" {
@@ -103,17 +114,34 @@ func main() {
 
 	fmt.Println("✅ 调用数据打包成功")
 
-	// 创建交易
+	// 估算 Gas，而不是写死一个上限。估算失败说明调用会 revert，解码 revert 原因
 	contractAddress := common.HexToAddress(contractAddressStr)
-	chainID := big.NewInt(11155111) // Sepolia
+	estimated, err := client.EstimateGas(context.Background(), ethereum.CallMsg{
+		From: fromAddress,
+		To:   &contractAddress,
+		Data: input,
+	})
+	if err != nil {
+		log.Fatalf("估算 Gas 失败: %s", sigdb.NewDefault().DescribeError(err))
+	}
+	gasLimit := chain.WithMargin(estimated, 0)
 
+	fmt.Printf("✅ Gas 上限: %d（估算 %d）\n", gasLimit, estimated)
+
+	// 获取链 ID
+	chainID, err := client.ChainID(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// 创建交易
 	tx := types.NewTransaction(
 		nonce,
 		contractAddress,
-		big.NewInt(0),  // 金额（0 ETH）
-		300000,         // Gas 限制
-		gasPrice,       // Gas 价格
-		input,          // 调用数据
+		big.NewInt(0), // 金额（0 ETH）
+		gasLimit,      // Gas 限制
+		gasPrice,      // Gas 价格
+		input,         // 调用数据
 	)
 
 	// 签名交易
@@ -124,6 +152,17 @@ func main() {
 
 	fmt.Println("✅ 交易签名成功")
 
+	// dry-run：在 pending 状态上模拟调用，打印解码后的调用和签名交易，不发送
+	if *dryRun {
+		sim, err := chain.SimulateTx(context.Background(), client, signedTx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println("\n=== Dry run（未发送） ===")
+		sim.WriteReport(os.Stdout, nil)
+		return
+	}
+
 	// 发送交易
 	err = client.SendTransaction(context.Background(), signedTx)
 	if err != nil {
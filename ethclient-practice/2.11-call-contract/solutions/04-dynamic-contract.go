@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/abiarg"
+	"github.com/dapp-learning/ethclient/util/contract"
+	"github.com/dapp-learning/ethclient/util/logdecode"
+	"github.com/dapp-learning/ethclient/util/output"
+)
+
+// 用法：
+//
+//	go run solutions/04-dynamic-contract.go [flags] methods
+//	go run solutions/04-dynamic-contract.go [flags] call  <方法> [参数...]
+//	go run solutions/04-dynamic-contract.go [flags] send  <方法> [参数...]   需要 PRIVATE_KEY
+//	go run solutions/04-dynamic-contract.go [flags] events <事件> [indexed参数=值...]
+//
+// 例如：
+//
+//	go run solutions/04-dynamic-contract.go call items my_key
+//	go run solutions/04-dynamic-contract.go send setItem my_key my_value
+//	go run solutions/04-dynamic-contract.go --from-block 7000000 events ItemSet key=my_key
+func main() {
+	abiPath := flag.String("abi", "../2.09-deploy-contract/contract/Store_sol_Store.abi", "ABI 文件：纯 ABI 数组或 Hardhat / Foundry / solc 编译产物")
+	address := flag.String("address", os.Getenv("CONTRACT_ADDRESS"), "合约地址")
+	valueFlag := flag.String("value", "0", "send 时附带的 ETH，如 0.01ether")
+	fromBlock := flag.Uint64("from-block", 0, "events 查询的起始区块")
+	timeout := flag.Duration("timeout", 5*time.Minute, "超时时间")
+	flag.Parse()
+
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *address == "" {
+		*address = "0x8D4141ec2b522dE5Cf42705C3010541B4B3EC24e"
+	}
+	if !common.IsHexAddress(*address) {
+		log.Fatalf("无效的合约地址 %q", *address)
+	}
+
+	rpcURL := os.Getenv("SEPOLIA_RPC_URL")
+	if rpcURL == "" {
+		rpcURL = "https://eth-sepolia.g.alchemy.com/v2/YOUR_API_KEY"
+	}
+
+	client, err := ethclient.Dial(rpcURL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	// 1. 从 ABI 文件创建合约客户端，不需要 abigen 生成的绑定
+	c, err := contract.Load(common.HexToAddress(*address), *abiPath, client)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	command, rest := flag.Arg(0), flag.Args()[1:]
+	switch command {
+	case "methods":
+		for _, sig := range c.Methods() {
+			fmt.Println(sig)
+		}
+
+	case "call":
+		if len(rest) < 1 {
+			log.Fatal("用法: call <方法> [参数...]")
+		}
+		// 2. 命令行参数都是字符串，由 Contract 按 ABI 类型转换
+		out, err := c.Call(&bind.CallOpts{Context: ctx}, rest[0], toArgs(rest[1:])...)
+		if err != nil {
+			log.Fatal(err)
+		}
+		m, _ := c.Method(rest[0])
+		for i, output := range m.Outputs {
+			name := output.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i)
+			}
+			fmt.Printf("%s (%s) = %s\n", name, output.Type.String(), format(out[i]))
+		}
+
+	case "send":
+		if len(rest) < 1 {
+			log.Fatal("用法: send <方法> [参数...]")
+		}
+		receipt, err := send(ctx, client, c, *valueFlag, rest[0], toArgs(rest[1:]))
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("✅ 已确认，区块 %d，Gas 使用 %d，状态 %d\n", receipt.BlockNumber.Uint64(), receipt.GasUsed, receipt.Status)
+		for _, l := range receipt.Logs {
+			if len(l.Topics) == 0 {
+				continue
+			}
+			event, err := c.ABI.EventByID(l.Topics[0])
+			if err != nil {
+				continue // 其他合约的事件
+			}
+			if record, err := logdecode.DecodeEvent(event, l); err == nil {
+				fmt.Printf("  事件 %s(%s)\n", record.Event, formatArgs(record.Args))
+			}
+		}
+
+	case "events":
+		if len(rest) < 1 {
+			log.Fatal("用法: events <事件> [indexed参数=值...]")
+		}
+		filter := &contract.EventFilter{FromBlock: *fromBlock, Args: make(map[string]interface{})}
+		for _, kv := range rest[1:] {
+			name, value, ok := strings.Cut(kv, "=")
+			if !ok {
+				log.Fatalf("过滤条件应为 参数名=值，实际为 %q", kv)
+			}
+			// 逗号分隔表示任一匹配
+			values, err := abiarg.SplitList(value)
+			if err != nil {
+				log.Fatal(err)
+			}
+			filter.Args[name] = values
+		}
+		records, err := c.Events(ctx, rest[0], filter)
+		if err != nil {
+			log.Fatal(err)
+		}
+		for _, r := range records {
+			fmt.Printf("区块 %d 交易 %s: %s\n", r.Position.BlockNumber, r.Position.TxHash.Hex(), formatArgs(r.Args))
+		}
+		fmt.Printf("共 %d 条\n", len(records))
+
+	default:
+		log.Fatalf("未知命令 %q，可用: methods, call, send, events", command)
+	}
+}
+
+// send 签名并发送交易，等待确认
+func send(ctx context.Context, client *ethclient.Client, c *contract.Contract, valueFlag, method string, args []interface{}) (*types.Receipt, error) {
+	privateKeyHex := os.Getenv("PRIVATE_KEY")
+	if privateKeyHex == "" {
+		return nil, errors.New("发送交易需要设置环境变量 PRIVATE_KEY")
+	}
+	privateKey, err := crypto.HexToECDSA(privateKeyHex)
+	if err != nil {
+		return nil, err
+	}
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
+	if err != nil {
+		return nil, err
+	}
+	opts.Context = ctx
+	if opts.Value, err = abiarg.ParseInt(valueFlag); err != nil {
+		return nil, err
+	}
+
+	tx, err := c.Transact(opts, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	fmt.Printf("✅ 交易已发送: %s\n", tx.Hash().Hex())
+	return bind.WaitMined(ctx, client, tx)
+}
+
+func toArgs(values []string) []interface{} {
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	return args
+}
+
+// format 以 JSON 显示值：*big.Int 为十进制字符串，字节为十六进制
+func format(v interface{}) string {
+	b, err := json.Marshal(output.Normalize(v))
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+// formatArgs 以 名称=值 的形式显示事件参数
+func formatArgs(args []logdecode.Arg) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = arg.Name + "=" + format(arg.Value)
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+
+	"github.com/dapp-learning/ethclient/call-contract/store"
+	"github.com/dapp-learning/ethclient/util/contract"
+	"github.com/dapp-learning/ethclient/util/simnet"
+	"github.com/dapp-learning/ethclient/util/simnet/contracts"
+)
+
+// 在模拟链上离线运行课程中的主要流程：查询区块和余额、转账 ETH、
+// 部署合约、通过 abigen 绑定和动态客户端调用合约、查询事件。
+// 不需要 RPC 和私钥，每次运行的账户和合约地址都相同
+func main() {
+	ctx := context.Background()
+
+	// 1. 启动模拟链：4 个各有 1000 ETH 的账户，已部署 Store 和 ERC20
+	net, err := simnet.New(nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer net.Close()
+	alice, bob := net.Accounts[0], net.Accounts[1]
+
+	fmt.Printf("链 ID: %s\n", net.ChainID)
+	for i, account := range net.Accounts {
+		fmt.Printf("账户 %d: %s\n", i, account.Address.Hex())
+	}
+	fmt.Printf("Store: %s\n", net.Store.Hex())
+	fmt.Printf("Token: %s\n", net.Token.Hex())
+
+	// 2. 查询区块与余额：net.Client 的方法与 *ethclient.Client 相同
+	head, err := net.Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\n当前区块: %d（%s）\n", head.Number, head.Hash().Hex())
+
+	// 3. 转账 ETH，Transfer 会立即出块并返回收据
+	receipt, err := net.Transfer(ctx, alice, bob.Address, big.NewInt(1e18))
+	if err != nil {
+		log.Fatal(err)
+	}
+	balance, err := net.Client.BalanceAt(ctx, bob.Address, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("转账 1 ETH: 区块 %d，Gas %d，bob 余额 %s wei\n", receipt.BlockNumber, receipt.GasUsed, balance)
+
+	// 4. 使用 abigen 绑定调用 Store
+	s, err := store.NewStore(net.Store, net.Client)
+	if err != nil {
+		log.Fatal(err)
+	}
+	version, err := s.Version(nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\nStore 版本: %s\n", version)
+
+	var key, value [32]byte
+	copy(key[:], "sim_key")
+	copy(value[:], "sim_value")
+	tx, err := s.SetItem(net.Transactor(alice), key, value)
+	if err != nil {
+		log.Fatal(err)
+	}
+	net.Commit() // 通过绑定发送的交易需要手动出块
+	if receipt, err = bind.WaitMined(ctx, net.Client, tx); err != nil {
+		log.Fatal(err)
+	}
+	stored, err := s.GetItem(nil, key)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("SetItem 区块 %d，GetItem: %s\n", receipt.BlockNumber, strings.TrimRight(string(stored[:]), "\x00"))
+
+	it, err := s.FilterItemSet(&bind.FilterOpts{Context: ctx}, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for it.Next() {
+		fmt.Printf("ItemSet 事件: key=%s\n", strings.TrimRight(string(it.Event.Key[:]), "\x00"))
+	}
+	it.Close()
+
+	// 5. 使用动态客户端调用 ERC20，参数可以直接传字符串
+	tokenABI, err := abi.JSON(strings.NewReader(contracts.TokenABI))
+	if err != nil {
+		log.Fatal(err)
+	}
+	token := contract.New(net.Token, tokenABI, net.Client)
+	tx, err = token.Transact(net.Transactor(alice), "transfer", bob.Address.Hex(), "250ether")
+	if err != nil {
+		log.Fatal(err)
+	}
+	net.Commit()
+	if _, err := bind.WaitMined(ctx, net.Client, tx); err != nil {
+		log.Fatal(err)
+	}
+	out, err := token.Call(nil, "balanceOf", bob.Address)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\n转账 250 %s，bob 代币余额: %s\n", contracts.TokenSymbol, out[0])
+
+	// 余额不足时，调用会在估算 gas 阶段返回回滚原因
+	if _, err := token.Transact(net.Transactor(bob), "transfer", alice.Address.Hex(), "1000ether"); err != nil {
+		fmt.Printf("超额转账被拒绝: %v\n", err)
+	}
+
+	// 6. 部署一个新的 Store
+	data, err := contracts.StoreDeployData("2.0")
+	if err != nil {
+		log.Fatal(err)
+	}
+	addr, receipt, err := net.Deploy(ctx, bob, data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	s2, err := store.NewStore(addr, net.Client)
+	if err != nil {
+		log.Fatal(err)
+	}
+	version, err = s2.Version(nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\n新 Store: %s，区块 %d，版本 %s\n", addr.Hex(), receipt.BlockNumber, version)
+}
@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/dapp-learning/ethclient/call-contract/store"
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/simnet"
+	"github.com/dapp-learning/ethclient/util/simnet/contracts"
+)
+
+// blockProbeCode 返回 (block.timestamp, block.number) 的运行时代码：
+//
+//	TIMESTAMP PUSH1 0 MSTORE NUMBER PUSH1 32 MSTORE PUSH1 64 PUSH1 0 RETURN
+var blockProbeCode = common.FromHex("0x426000524360205260406000f3")
+
+// 用法：
+//
+//	go run solutions/06-state-override.go
+//
+// 在模拟链上演示 eth_call 的状态覆盖和区块覆盖：覆盖只在本次调用中生效，
+// 不需要给账户转账、也不需要部署合约，链上状态不会改变
+func main() {
+	ctx := context.Background()
+
+	net, err := simnet.New(nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer net.Close()
+	bob := net.Accounts[1]
+	ghost := simnet.NewAccount(99).Address // 没有 ETH 也没有代币的账户
+	fmt.Printf("无资金账户: %s\n", ghost.Hex())
+
+	// 1. 从无资金账户转账代币：直接调用会 revert，覆盖余额映射的存储槽后成功。
+	//    示例 ERC20 的余额映射在 slot 0（与 OpenZeppelin ERC20 相同）
+	amount := new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))
+	transfer := ethereum.CallMsg{From: ghost, To: &net.Token, Data: chain.TransferData(bob.Address, amount)}
+
+	fmt.Println("\n=== 1. 代币余额覆盖 ===")
+	if _, err := net.Client.CallContract(ctx, transfer, nil); err != nil {
+		fmt.Printf("不覆盖: %v\n", err)
+	}
+	state := chain.StateOverride{}.SetTokenBalance(net.Token, ghost, new(big.Int).Mul(amount, big.NewInt(5)), 0)
+	state.WriteSummary(os.Stdout)
+	out, err := chain.CallWithOverrides(ctx, net.RPC, transfer, nil, state, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("覆盖后 transfer 返回: %t\n", new(big.Int).SetBytes(out).Sign() != 0)
+
+	// 同一组覆盖可以用于多次调用，例如查询覆盖后的余额
+	caller := &chain.OverrideCaller{RPC: net.RPC, State: state}
+	balance, err := chain.TokenBalance(ctx, caller, net.Token, ghost)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("覆盖后余额: %s %s\n", chain.FormatEther(balance), contracts.TokenSymbol)
+	if balance, err = chain.TokenBalance(ctx, net.Client, net.Token, ghost); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("链上余额:   %s %s\n", chain.FormatEther(balance), contracts.TokenSymbol)
+
+	// 2. 从无资金账户转账 ETH：覆盖 ETH 余额
+	fmt.Println("\n=== 2. ETH 余额覆盖 ===")
+	send := ethereum.CallMsg{From: ghost, To: &bob.Address, Value: big.NewInt(5e18)}
+	if _, err := net.Client.CallContract(ctx, send, nil); err != nil {
+		fmt.Printf("不覆盖: %v\n", err)
+	}
+	state = chain.StateOverride{}.SetBalance(ghost, new(big.Int).Mul(big.NewInt(10), big.NewInt(1e18)))
+	if _, err := chain.CallWithOverrides(ctx, net.RPC, send, nil, state, nil); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println("覆盖余额为 10 ETH 后转账 5 ETH 成功")
+
+	// 3. 不部署就测试合约代码：把 Store 的运行时代码放到一个空地址，
+	//    同时替换存储，version 在 slot 0（31 字节以内的字符串：内容左对齐，末字节为长度 * 2）
+	fmt.Println("\n=== 3. 代码覆盖 ===")
+	runtime, err := net.Client.CodeAt(ctx, net.Store, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	preview := common.HexToAddress("0x000000000000000000000000000000000000cafe")
+	state = chain.StateOverride{}.
+		SetCode(preview, runtime).
+		ReplaceStorage(preview, map[common.Hash]common.Hash{{}: shortString("2.0-preview")})
+	state.WriteSummary(os.Stdout)
+
+	// OverrideCaller 实现了 bind.ContractCaller，abigen 绑定可以直接使用
+	s, err := store.NewStoreCaller(preview, &chain.OverrideCaller{RPC: net.RPC, State: state})
+	if err != nil {
+		log.Fatal(err)
+	}
+	version, err := s.Version(nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("覆盖地址上的 version(): %s\n", version)
+	if code, err := net.Client.CodeAt(ctx, preview, nil); err == nil {
+		fmt.Printf("链上代码长度: %d 字节\n", len(code))
+	}
+
+	// 4. 区块覆盖：修改调用看到的 block.number 和 block.timestamp，
+	//    用来测试依赖时间的逻辑（锁仓到期、拍卖结束等）
+	fmt.Println("\n=== 4. 区块覆盖 ===")
+	probe := common.HexToAddress("0x000000000000000000000000000000000000b10c")
+	state = chain.StateOverride{}.SetCode(probe, blockProbeCode)
+	call := ethereum.CallMsg{To: &probe}
+	if out, err = chain.CallWithOverrides(ctx, net.RPC, call, nil, state, nil); err != nil {
+		log.Fatal(err)
+	}
+	printBlock("当前区块", out)
+
+	future := time.Now().AddDate(1, 0, 0)
+	number, timestamp := big.NewInt(1_000_000), hexutil.Uint64(future.Unix())
+	block := &chain.BlockOverrides{Number: (*hexutil.Big)(number), Time: &timestamp}
+	if out, err = chain.CallWithOverrides(ctx, net.RPC, call, nil, state, block); err != nil {
+		log.Fatal(err)
+	}
+	printBlock("覆盖后  ", out)
+}
+
+// shortString Solidity 短字符串（不超过 31 字节）在存储槽中的编码
+func shortString(s string) common.Hash {
+	var h common.Hash
+	copy(h[:], s)
+	h[31] = byte(len(s) * 2)
+	return h
+}
+
+// printBlock 打印 blockProbeCode 返回的时间戳和区块号
+func printBlock(label string, out []byte) {
+	if len(out) != 64 {
+		log.Fatalf("返回数据长度 %d，应为 64", len(out))
+	}
+	timestamp := new(big.Int).SetBytes(out[:32]).Int64()
+	number := new(big.Int).SetBytes(out[32:])
+	fmt.Printf("%s: 区块 %s，时间 %s\n", label, number, time.Unix(timestamp, 0).Format(time.DateTime))
+}
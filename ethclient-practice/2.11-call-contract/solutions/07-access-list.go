@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/dapp-learning/ethclient/call-contract/store"
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/simnet"
+	"github.com/dapp-learning/ethclient/util/simnet/contracts"
+)
+
+// 用法：
+//
+//	go run solutions/07-access-list.go
+//
+// 在模拟链上演示 EIP-2930 访问列表：用 eth_createAccessList 生成列表，
+// 比较带与不带列表时估算的 Gas，只在能节省 Gas 时附加到交易。
+//   - Store.setItem 只访问接收方的存储，列表中的接收方地址要多付 2400，反而更贵
+//   - 通过转发合约调用代币，代币合约和它的余额槽位都是首次访问（cold），列表能节省 Gas
+func main() {
+	ctx := context.Background()
+
+	net, err := simnet.New(nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer net.Close()
+	alice := net.Accounts[0]
+
+	// 1. 单合约调用
+	fmt.Println("=== 1. Store.setItem ===")
+	storeABI, err := store.StoreMetaData.GetAbi()
+	if err != nil {
+		log.Fatal(err)
+	}
+	data, err := storeABI.Pack("setItem", bytes32("key"), bytes32("value"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	req := &chain.TxRequest{To: &net.Store, Data: data}
+	cmp, err := chain.AttachAccessList(ctx, net.RPC, alice.Address, req)
+	if err != nil {
+		log.Fatal(err)
+	}
+	cmp.WriteReport(os.Stdout)
+	fmt.Printf("附加到交易: %t\n", req.AccessList != nil)
+
+	// 2. 多合约调用：alice → 转发合约 → 代币。先部署转发合约并给它转入代币
+	fmt.Println("\n=== 2. 通过转发合约转账代币 ===")
+	forwarder, _, err := net.Deploy(ctx, alice, contracts.ForwarderDeployData(net.Token))
+	if err != nil {
+		log.Fatal(err)
+	}
+	amount := new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))
+	if _, err := net.Send(ctx, alice, &net.Token, nil, chain.TransferData(forwarder, amount)); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("转发合约: %s（持有 1000 %s）\n", forwarder.Hex(), contracts.TokenSymbol)
+
+	// 转给两个从未持有代币的地址，两笔交易的存储写入开销相同，只有访问列表不同
+	ten := new(big.Int).Mul(big.NewInt(10), big.NewInt(1e18))
+	plain := &chain.TxRequest{To: &forwarder, Data: chain.TransferData(net.Accounts[2].Address, ten)}
+	withList := &chain.TxRequest{To: &forwarder, Data: chain.TransferData(net.Accounts[3].Address, ten)}
+	if cmp, err = chain.AttachAccessList(ctx, net.RPC, alice.Address, withList); err != nil {
+		log.Fatal(err)
+	}
+	cmp.WriteReport(os.Stdout)
+	fmt.Printf("附加到交易: %t\n", withList.AccessList != nil)
+
+	// 3. 发送两笔交易，比较实际使用的 Gas
+	fmt.Println("\n=== 3. 实际使用的 Gas ===")
+	plainReceipt, err := send(ctx, net, alice, plain)
+	if err != nil {
+		log.Fatal(err)
+	}
+	listReceipt, err := send(ctx, net, alice, withList)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("不带访问列表: %d\n", plainReceipt.GasUsed)
+	fmt.Printf("带访问列表:   %d（节省 %d）\n", listReceipt.GasUsed, int64(plainReceipt.GasUsed)-int64(listReceipt.GasUsed))
+}
+
+// send 用 chain.Send 构造、签名并发送交易，出块后返回收据
+func send(ctx context.Context, net *simnet.Net, from *simnet.Account, req *chain.TxRequest) (*types.Receipt, error) {
+	tx, err := chain.Send(ctx, net.Client, from.Key, req)
+	if err != nil {
+		return nil, err
+	}
+	net.Commit()
+	receipt, err := net.Client.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("交易 %s 执行失败", tx.Hash().Hex())
+	}
+	fmt.Printf("%s  类型 %s，访问列表 %d 个地址\n", tx.Hash().Hex(), chain.TxTypeName(tx.Type()), len(tx.AccessList()))
+	return receipt, nil
+}
+
+func bytes32(s string) [32]byte {
+	var b [32]byte
+	copy(b[:], s)
+	return b
+}
@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/simnet"
+	"github.com/dapp-learning/ethclient/util/simnet/contracts"
+	"github.com/dapp-learning/ethclient/util/userop"
+)
+
+// 用法：
+//
+//	go run solutions/08-user-operation.go
+//
+// 在模拟链上演示 ERC-4337 UserOperation 的完整流程：部署 EntryPoint v0.7 和账户工厂，
+// 在本地启动一个 bundler HTTP 服务，然后像连接真实 bundler 一样通过 JSON-RPC
+// 估算 Gas、发送 UserOperation 并等待收据。
+//   - 第一个 UserOperation 带 factory，EntryPoint 先部署账户再执行转账
+//   - 第二个 UserOperation 由已部署的账户转账代币
+//   - 用错误的私钥签名，bundler 模拟验证失败后直接拒绝
+func main() {
+	ctx := context.Background()
+
+	sim, err := simnet.New(nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer sim.Close()
+	deployer, owner, relayer := sim.Accounts[0], sim.Accounts[1], sim.Accounts[2]
+	bob := simnet.NewAccount(99).Address
+
+	// 1. 部署 EntryPoint 和账户工厂
+	fmt.Println("=== 1. 部署合约 ===")
+	epAddress, _, err := sim.Deploy(ctx, deployer, contracts.EntryPointDeployData())
+	if err != nil {
+		log.Fatal(err)
+	}
+	factory, _, err := sim.Deploy(ctx, deployer, contracts.AccountFactoryDeployData(epAddress))
+	if err != nil {
+		log.Fatal(err)
+	}
+	ep := userop.EntryPoint{Address: epAddress, Version: userop.V07}
+	fmt.Printf("EntryPoint: %s\n账户工厂:   %s\n", epAddress.Hex(), factory.Hex())
+
+	// 2. 启动本地 bundler：relayer 支付 handleOps 交易的 Gas，从 EntryPoint 收回费用。
+	//    模拟链只在 Commit 时出块，所以每发送一笔 handleOps 就出块
+	dev := userop.NewDevBundler(sim.Client, relayer.Key, epAddress)
+	dev.AfterSend = func() { sim.Commit() }
+	srv, err := dev.Server()
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer srv.Stop()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer listener.Close()
+	go http.Serve(listener, srv)
+
+	bundler, err := userop.Dial("http://" + listener.Addr().String())
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer bundler.Close()
+	chainID, err := bundler.ChainID(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	eps, err := bundler.SupportedEntryPoints(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\nbundler: %s 链 ID %s，支持的 EntryPoint %v\n", listener.Addr(), chainID, eps)
+
+	// 3. 账户地址由工厂、owner 和 salt 决定，部署之前就可以接收 ETH 和代币
+	salt := big.NewInt(0)
+	account := contracts.AccountAddress(factory, epAddress, owner.Address, salt)
+	if _, err := sim.Transfer(ctx, deployer, account, big.NewInt(1e18)); err != nil {
+		log.Fatal(err)
+	}
+	tokens := new(big.Int).Mul(big.NewInt(500), big.NewInt(1e18))
+	if _, err := sim.Send(ctx, deployer, &sim.Token, nil, chain.TransferData(account, tokens)); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("\n智能账户: %s（尚未部署），已转入 1 ETH 和 500 %s\n", account.Hex(), contracts.TokenSymbol)
+
+	// 4. 第一个 UserOperation：部署账户并向 bob 转账 0.1 ETH
+	fmt.Println("\n=== 2. 部署账户并转账 ETH ===")
+	factoryData, err := contracts.CreateAccountData(owner.Address, salt)
+	if err != nil {
+		log.Fatal(err)
+	}
+	callData, err := contracts.ExecuteData(bob, big.NewInt(1e17), nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	op := &userop.UserOperation{Sender: account, Factory: &factory, FactoryData: factoryData, CallData: callData}
+	if err := prepare(ctx, sim, bundler, ep, chainID, op, owner.Key); err != nil {
+		log.Fatal(err)
+	}
+	checkHash(ctx, sim, ep, chainID, op)
+	receipt := send(ctx, bundler, ep, op)
+	code, err := sim.Client.CodeAt(ctx, account, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("账户代码: %d 字节\n", len(code))
+	balance, err := sim.Client.BalanceAt(ctx, bob, receipt.BlockNumber)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("bob 的余额: %s ETH\n", chain.FormatEther(balance))
+
+	// 5. 第二个 UserOperation：账户已部署，不再需要 factory，nonce 递增
+	fmt.Println("\n=== 3. 转账代币 ===")
+	amount := new(big.Int).Mul(big.NewInt(120), big.NewInt(1e18))
+	if callData, err = contracts.ExecuteData(sim.Token, nil, chain.TransferData(bob, amount)); err != nil {
+		log.Fatal(err)
+	}
+	op = &userop.UserOperation{Sender: account, CallData: callData}
+	if err := prepare(ctx, sim, bundler, ep, chainID, op, owner.Key); err != nil {
+		log.Fatal(err)
+	}
+	send(ctx, bundler, ep, op)
+	if balance, err = chain.TokenBalance(ctx, sim.Client, sim.Token, bob); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("bob 的代币余额: %s %s\n", chain.FormatEther(balance), contracts.TokenSymbol)
+
+	// 6. 签名与账户 owner 不符：bundler 模拟 handleOps 时 EntryPoint 回滚，不会发送交易
+	fmt.Println("\n=== 4. 错误的签名 ===")
+	op = &userop.UserOperation{Sender: account, CallData: callData}
+	if err := prepare(ctx, sim, bundler, ep, chainID, op, relayer.Key); err != nil {
+		log.Fatal(err)
+	}
+	if _, err := bundler.Send(ctx, op, ep); err != nil {
+		fmt.Printf("bundler 拒绝: %v\n", err)
+	}
+}
+
+// prepare 查询 nonce、设置费用、估算 Gas，最后签名
+func prepare(ctx context.Context, sim *simnet.Net, bundler *userop.Client, ep userop.EntryPoint, chainID *big.Int, op *userop.UserOperation, key *ecdsa.PrivateKey) error {
+	nonce, err := userop.GetNonce(ctx, sim.Client, ep, op.Sender, nil)
+	if err != nil {
+		return err
+	}
+	op.Nonce = nonce
+	if err := op.SuggestFees(ctx, sim.Client); err != nil {
+		return err
+	}
+
+	// 估算时使用占位签名，账户的验证逻辑照常执行但不会通过
+	op.Signature = userop.DummySignature
+	gas, err := bundler.EstimateGas(ctx, op, ep)
+	if err != nil {
+		return err
+	}
+	gas.Apply(op)
+	fmt.Printf("nonce %s，估算 Gas: preVerification=%d verification=%d call=%d，最高费用 %s ETH\n",
+		op.Nonce, op.PreVerificationGas, op.VerificationGasLimit, op.CallGasLimit, chain.FormatEther(op.RequiredPrefund()))
+
+	if err := op.Sign(key, ep, chainID); err != nil {
+		return err
+	}
+	signer, err := op.Signer(ep, chainID)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("userOpHash: %s，签名者 %s\n", op.Hash(ep, chainID).Hex(), signer.Hex())
+	return nil
+}
+
+// checkHash 比较本地计算的 userOpHash 和 EntryPoint.getUserOpHash 的结果
+func checkHash(ctx context.Context, sim *simnet.Net, ep userop.EntryPoint, chainID *big.Int, op *userop.UserOperation) {
+	parsed, err := abi.JSON(strings.NewReader(contracts.EntryPointABI))
+	if err != nil {
+		log.Fatal(err)
+	}
+	data, err := parsed.Pack("getUserOpHash", op.Pack())
+	if err != nil {
+		log.Fatal(err)
+	}
+	out, err := sim.Client.CallContract(ctx, ethereum.CallMsg{To: &ep.Address, Data: data}, nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("与 getUserOpHash 一致: %t\n", common.BytesToHash(out) == op.Hash(ep, chainID))
+}
+
+// send 发送 UserOperation 并等待收据
+func send(ctx context.Context, bundler *userop.Client, ep userop.EntryPoint, op *userop.UserOperation) *userop.Receipt {
+	hash, err := bundler.Send(ctx, op, ep)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	receipt, err := bundler.WaitReceipt(ctx, hash, 100*time.Millisecond)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("已上链: 交易 %s 区块 %s，执行成功 %t\n", receipt.TxHash.Hex(), receipt.BlockNumber, receipt.Success)
+	fmt.Printf("实际 Gas %d，费用 %s ETH（从账户在 EntryPoint 的存款中扣除）\n", receipt.ActualGasUsed, chain.FormatEther(receipt.ActualGasCost))
+	if !receipt.Success {
+		fmt.Printf("回滚原因: %s\n", receipt.Reason)
+	}
+	return receipt
+}
@@ -0,0 +1,14 @@
+// Package store 是 Store.sol 的 Go 绑定，store.go 由 bindgen 生成，请勿手动修改。
+//
+// 绑定从 2.09 提交的 solcjs 0.8.30 编译产物（Store_sol_Store.abi / .bin）生成，不需要安装编译器：
+//
+//	go generate ./store
+//
+// 检查已提交的绑定是否与编译产物一致（使用与生成时完全相同的参数）：
+//
+//	BINDGEN_CHECK=1 go generate ./store
+//
+// store_test.go 用同样的参数调用 bindgen.Check，go test ./... 时也会检查
+package store
+
+//go:generate go run github.com/dapp-learning/ethclient/util/cmd/bindgen --artifact ../../2.09-deploy-contract/contract/Store_sol_Store.abi --solc 0.8.30 --pkg store --out store.go
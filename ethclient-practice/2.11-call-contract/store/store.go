@@ -1,9 +1,11 @@
 // Code generated - DO NOT EDIT.
-// This file is a generated binding and any modifications will be lost.
+// This file is a generated binding and any manual changes will be lost.
 
 package store
 
 import (
+	"errors"
+	"math/big"
 	"strings"
 
 	ethereum "github.com/ethereum/go-ethereum"
@@ -14,8 +16,49 @@ import (
 	"github.com/ethereum/go-ethereum/event"
 )
 
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+	_ = abi.ConvertType
+)
+
+// StoreMetaData contains all meta data concerning the Store contract.
+var StoreMetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[{\"internalType\":\"string\",\"name\":\"_version\",\"type\":\"string\"}],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":true,\"internalType\":\"bytes32\",\"name\":\"key\",\"type\":\"bytes32\"},{\"indexed\":false,\"internalType\":\"bytes32\",\"name\":\"value\",\"type\":\"bytes32\"}],\"name\":\"ItemSet\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"key\",\"type\":\"bytes32\"}],\"name\":\"getItem\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"name\":\"items\",\"outputs\":[{\"internalType\":\"bytes32\",\"name\":\"\",\"type\":\"bytes32\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"bytes32\",\"name\":\"key\",\"type\":\"bytes32\"},{\"internalType\":\"bytes32\",\"name\":\"value\",\"type\":\"bytes32\"}],\"name\":\"setItem\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[],\"name\":\"version\",\"outputs\":[{\"internalType\":\"string\",\"name\":\"\",\"type\":\"string\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+	Bin: "0x608060405234801561000f575f5ffd5b506040516108a53803806108a583398181016040528101906100319190610193565b805f908161003f91906103ea565b50506104b9565b5f604051905090565b5f5ffd5b5f5ffd5b5f5ffd5b5f5ffd5b5f601f19601f8301169050919050565b7f4e487b71000000000000000000000000000000000000000000000000000000005f52604160045260245ffd5b6100a58261005f565b810181811067ffffffffffffffff821117156100c4576100c361006f565b5b80604052505050565b5f6100d6610046565b90506100e2828261009c565b919050565b5f67ffffffffffffffff8211156101015761010061006f565b5b61010a8261005f565b9050602081019050919050565b8281835e5f83830152505050565b5f610137610132846100e7565b6100cd565b9050828152602081018484840111156101535761015261005b565b5b61015e848285610117565b509392505050565b5f82601f83011261017a57610179610057565b5b815161018a848260208601610125565b91505092915050565b5f602082840312156101a8576101a761004f565b5b5f82015167ffffffffffffffff8111156101c5576101c4610053565b5b6101d184828501610166565b91505092915050565b5f81519050919050565b7f4e487b71000000000000000000000000000000000000000000000000000000005f52602260045260245ffd5b5f600282049050600182168061022857607f821691505b60208210810361023b5761023a6101e4565b5b50919050565b5f819050815f5260205f209050919050565b5f6020601f8301049050919050565b5f82821b905092915050565b5f6008830261029d7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff82610262565b6102a78683610262565b95508019841693508086168417925050509392505050565b5f819050919050565b5f819050919050565b5f6102eb6102e66102e1846102bf565b6102c8565b6102bf565b9050919050565b5f819050919050565b610304836102d1565b610318610310826102f2565b84845461026e565b825550505050565b5f5f905090565b61032f610320565b61033a8184846102fb565b505050565b5b8181101561035d576103525f82610327565b600181019050610340565b5050565b601f8211156103a25761037381610241565b61037c84610253565b8101602085101561038b578190505b61039f61039785610253565b83018261033f565b50505b505050565b5f82821c905092915050565b5f6103c25f19846008026103a7565b1980831691505092915050565b5f6103da83836103b3565b9150826002028217905092915050565b6103f3826101da565b67ffffffffffffffff81111561040c5761040b61006f565b5b6104168254610211565b610421828285610361565b5f60209050601f831160018114610452575f8415610440578287015190505b61044a85826103cf565b8655506104b1565b601f19841661046086610241565b5f5b8281101561048757848901518255600182019150602085019450602081019050610462565b868310156104a457848901516104a0601f8916826103b3565b8355505b6001600288020188555050505b505050505050565b6103df806104c65f395ff3fe608060405234801561000f575f5ffd5b506004361061004a575f3560e01c806348f343f31461004e57806354fd4d501461007e578063aa0372e71461009c578063f56256c7146100cc575b5f5ffd5b6100686004803603810190610063919061022b565b6100e8565b6040516100759190610265565b60405180910390f35b6100866100fd565b60405161009391906102ee565b60405180910390f35b6100b660048036038101906100b1919061022b565b610188565b6040516100c39190610265565b60405180910390f35b6100e660048036038101906100e1919061030e565b6101a2565b005b6001602052805f5260405f205f915090505481565b5f805461010990610379565b80601f016020809104026020016040519081016040528092919081815260200182805461013590610379565b80156101805780601f1061015757610100808354040283529160200191610180565b820191905f5260205f20905b81548152906001019060200180831161016357829003601f168201915b505050505081565b5f60015f8381526020019081526020015f20549050919050565b8060015f8481526020019081526020015f2081905550817fe79e73da417710ae99aa2088575580a60415d359acfad9cdd3382d59c80281d4826040516101e89190610265565b60405180910390a25050565b5f5ffd5b5f819050919050565b61020a816101f8565b8114610214575f5ffd5b50565b5f8135905061022581610201565b92915050565b5f602082840312156102405761023f6101f4565b5b5f61024d84828501610217565b91505092915050565b61025f816101f8565b82525050565b5f6020820190506102785f830184610256565b92915050565b5f81519050919050565b5f82825260208201905092915050565b8281835e5f83830152505050565b5f601f19601f8301169050919050565b5f6102c08261027e565b6102ca8185610288565b93506102da818560208601610298565b6102e3816102a6565b840191505092915050565b5f6020820190508181035f83015261030681846102b6565b905092915050565b5f5f60408385031215610324576103236101f4565b5b5f61033185828601610217565b925050602061034285828601610217565b9150509250929050565b7f4e487b71000000000000000000000000000000000000000000000000000000005f52602260045260245ffd5b5f600282049050600182168061039057607f821691505b6020821081036103a3576103a261034c565b5b5091905056fea264697066735822122039cfe9bdf7ae032ad0d157c5cdacf62eb6f142fbf4bcf898169a2be4dc355f1264736f6c634300081e0033",
+}
+
 // StoreABI is the input ABI used to generate the binding from.
-const StoreABI = `[{"inputs":[{"internalType":"string","name":"_version","type":"string"}],"stateMutability":"nonpayable","type":"constructor"},{"anonymous":false,"inputs":[{"indexed":true,"internalType":"bytes32","name":"key","type":"bytes32"},{"indexed":false,"internalType":"bytes32","name":"value","type":"bytes32"}],"name":"ItemSet","type":"event"},{"inputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"name":"items","outputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"bytes32","name":"key","type":"bytes32"},{"internalType":"bytes32","name":"value","type":"bytes32"}],"name":"setItem","outputs":[],"stateMutability":"nonpayable","type":"function"},{"inputs":[],"name":"version","outputs":[{"internalType":"string","name":"","type":"string"}],"stateMutability":"view","type":"function"}]`
+// Deprecated: Use StoreMetaData.ABI instead.
+var StoreABI = StoreMetaData.ABI
+
+// StoreBin is the compiled bytecode used for deploying new contracts.
+// Deprecated: Use StoreMetaData.Bin instead.
+var StoreBin = StoreMetaData.Bin
+
+// DeployStore deploys a new Ethereum contract, binding an instance of Store to it.
+func DeployStore(auth *bind.TransactOpts, backend bind.ContractBackend, _version string) (common.Address, *types.Transaction, *Store, error) {
+	parsed, err := StoreMetaData.GetAbi()
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	if parsed == nil {
+		return common.Address{}, nil, nil, errors.New("GetABI returned nil")
+	}
+
+	address, tx, contract, err := bind.DeployContract(auth, *parsed, common.FromHex(StoreBin), backend, _version)
+	if err != nil {
+		return common.Address{}, nil, nil, err
+	}
+	return address, tx, &Store{StoreCaller: StoreCaller{contract: contract}, StoreTransactor: StoreTransactor{contract: contract}, StoreFilterer: StoreFilterer{contract: contract}}, nil
+}
 
 // Store is an auto generated Go binding around an Ethereum contract.
 type Store struct {
@@ -26,67 +69,54 @@ type Store struct {
 
 // StoreCaller is an auto generated read-only Go binding around an Ethereum contract.
 type StoreCaller struct {
-	contract *bind.BoundContract
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
 }
 
 // StoreTransactor is an auto generated write-only Go binding around an Ethereum contract.
 type StoreTransactor struct {
-	contract *bind.BoundContract
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
 }
 
 // StoreFilterer is an auto generated log filtering Go binding around an Ethereum contract events.
 type StoreFilterer struct {
-	contract *bind.BoundContract
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
 }
 
 // StoreSession is an auto generated Go binding around an Ethereum contract,
 // with pre-set call and transact options.
 type StoreSession struct {
-	Contract     *Store
-	CallOpts     bind.CallOpts
-	TransactOpts bind.TransactOpts
+	Contract     *Store            // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
 }
 
 // StoreCallerSession is an auto generated read-only Go binding around an Ethereum contract,
 // with pre-set call options.
 type StoreCallerSession struct {
-	Contract *StoreCaller
-	CallOpts bind.CallOpts
+	Contract *StoreCaller  // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts // Call options to use throughout this session
 }
 
 // StoreTransactorSession is an auto generated write-only Go binding around an Ethereum contract,
 // with pre-set transact options.
 type StoreTransactorSession struct {
-	Contract     *StoreTransactor
-	TransactOpts bind.TransactOpts
-}
-
-// StoreItemSet represents a ItemSet event raised by the Store contract.
-type StoreItemSet struct {
-	Key   [32]byte
-	Value [32]byte
-	Raw   types.Log
+	Contract     *StoreTransactor  // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
 }
 
-// StoreItemSetIterator is returned from FilterItemSet and is used to iterate over the raw logs.
-type StoreItemSetIterator struct {
-	event *event.Event
+// StoreRaw is an auto generated low-level Go binding around an Ethereum contract.
+type StoreRaw struct {
+	Contract *Store // Generic contract binding to access the raw methods on
 }
 
-// Next advances the iterator to the subsequent event.
-func (it *StoreItemSetIterator) Next() bool {
-	return it.event.Next()
+// StoreCallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type StoreCallerRaw struct {
+	Contract *StoreCaller // Generic read-only contract binding to access the raw methods on
 }
 
-// Error returns any retrieval or parsing error occurred during filtering.
-func (it *StoreItemSetIterator) Error() error {
-	return it.event.Err()
-}
-
-// Close terminates the iteration process.
-func (it *StoreItemSetIterator) Close() error {
-	it.event.Close()
-	return nil
+// StoreTransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type StoreTransactorRaw struct {
+	Contract *StoreTransactor // Generic write-only contract binding to access the raw methods on
 }
 
 // NewStore creates a new instance of Store, bound to a specific deployed contract.
@@ -98,86 +128,293 @@ func NewStore(address common.Address, backend bind.ContractBackend) (*Store, err
 	return &Store{StoreCaller: StoreCaller{contract: contract}, StoreTransactor: StoreTransactor{contract: contract}, StoreFilterer: StoreFilterer{contract: contract}}, nil
 }
 
+// NewStoreCaller creates a new read-only instance of Store, bound to a specific deployed contract.
+func NewStoreCaller(address common.Address, caller bind.ContractCaller) (*StoreCaller, error) {
+	contract, err := bindStore(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &StoreCaller{contract: contract}, nil
+}
+
+// NewStoreTransactor creates a new write-only instance of Store, bound to a specific deployed contract.
+func NewStoreTransactor(address common.Address, transactor bind.ContractTransactor) (*StoreTransactor, error) {
+	contract, err := bindStore(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &StoreTransactor{contract: contract}, nil
+}
+
+// NewStoreFilterer creates a new log filterer instance of Store, bound to a specific deployed contract.
+func NewStoreFilterer(address common.Address, filterer bind.ContractFilterer) (*StoreFilterer, error) {
+	contract, err := bindStore(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &StoreFilterer{contract: contract}, nil
+}
+
 // bindStore binds a generic wrapper to an already deployed contract.
 func bindStore(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
-	parsed, err := abi.JSON(strings.NewReader(StoreABI))
+	parsed, err := StoreMetaData.GetAbi()
 	if err != nil {
 		return nil, err
 	}
-	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+	return bind.NewBoundContract(address, *parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Store *StoreRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Store.Contract.StoreCaller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Store *StoreRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Store.Contract.StoreTransactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Store *StoreRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Store.Contract.StoreTransactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_Store *StoreCallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _Store.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_Store *StoreTransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _Store.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_Store *StoreTransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _Store.Contract.contract.Transact(opts, method, params...)
+}
+
+// GetItem is a free data retrieval call binding the contract method 0xaa0372e7.
+//
+// Solidity: function getItem(bytes32 key) view returns(bytes32)
+func (_Store *StoreCaller) GetItem(opts *bind.CallOpts, key [32]byte) ([32]byte, error) {
+	var out []interface{}
+	err := _Store.contract.Call(opts, &out, "getItem", key)
+
+	if err != nil {
+		return *new([32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+
+	return out0, err
+
+}
+
+// GetItem is a free data retrieval call binding the contract method 0xaa0372e7.
+//
+// Solidity: function getItem(bytes32 key) view returns(bytes32)
+func (_Store *StoreSession) GetItem(key [32]byte) ([32]byte, error) {
+	return _Store.Contract.GetItem(&_Store.CallOpts, key)
+}
+
+// GetItem is a free data retrieval call binding the contract method 0xaa0372e7.
+//
+// Solidity: function getItem(bytes32 key) view returns(bytes32)
+func (_Store *StoreCallerSession) GetItem(key [32]byte) ([32]byte, error) {
+	return _Store.Contract.GetItem(&_Store.CallOpts, key)
+}
+
+// Items is a free data retrieval call binding the contract method 0x48f343f3.
+//
+// Solidity: function items(bytes32 ) view returns(bytes32)
+func (_Store *StoreCaller) Items(opts *bind.CallOpts, arg0 [32]byte) ([32]byte, error) {
+	var out []interface{}
+	err := _Store.contract.Call(opts, &out, "items", arg0)
+
+	if err != nil {
+		return *new([32]byte), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new([32]byte)).(*[32]byte)
+
+	return out0, err
+
 }
 
-// Version is a free data retrieval call binding the contract method.
+// Items is a free data retrieval call binding the contract method 0x48f343f3.
+//
+// Solidity: function items(bytes32 ) view returns(bytes32)
+func (_Store *StoreSession) Items(arg0 [32]byte) ([32]byte, error) {
+	return _Store.Contract.Items(&_Store.CallOpts, arg0)
+}
+
+// Items is a free data retrieval call binding the contract method 0x48f343f3.
+//
+// Solidity: function items(bytes32 ) view returns(bytes32)
+func (_Store *StoreCallerSession) Items(arg0 [32]byte) ([32]byte, error) {
+	return _Store.Contract.Items(&_Store.CallOpts, arg0)
+}
+
+// Version is a free data retrieval call binding the contract method 0x54fd4d50.
+//
 // Solidity: function version() view returns(string)
 func (_Store *StoreCaller) Version(opts *bind.CallOpts) (string, error) {
 	var out []interface{}
 	err := _Store.contract.Call(opts, &out, "version")
-	return out[0].(string), err
+
+	if err != nil {
+		return *new(string), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(string)).(*string)
+
+	return out0, err
+
 }
 
-// Version is a free data retrieval call binding the contract method.
+// Version is a free data retrieval call binding the contract method 0x54fd4d50.
+//
 // Solidity: function version() view returns(string)
 func (_Store *StoreSession) Version() (string, error) {
 	return _Store.Contract.Version(&_Store.CallOpts)
 }
 
-// GetItem is a free data retrieval call binding the contract method.
-// Solidity: function items(bytes32 ) view returns(bytes32)
-func (_Store *StoreCaller) GetItem(opts *bind.CallOpts, key [32]byte) ([32]byte, error) {
-	var out []interface{}
-	err := _Store.contract.Call(opts, &out, "items", key)
-	return out[0].([32]byte), err
-}
-
-// GetItem is a free data retrieval call binding the contract method.
-// Solidity: function items(bytes32 ) view returns(bytes32)
-func (_Store *StoreSession) GetItem(key [32]byte) ([32]byte, error) {
-	return _Store.Contract.GetItem(&_Store.CallOpts, key)
+// Version is a free data retrieval call binding the contract method 0x54fd4d50.
+//
+// Solidity: function version() view returns(string)
+func (_Store *StoreCallerSession) Version() (string, error) {
+	return _Store.Contract.Version(&_Store.CallOpts)
 }
 
-// SetItem is a paid mutator transaction binding the contract method.
+// SetItem is a paid mutator transaction binding the contract method 0xf56256c7.
+//
 // Solidity: function setItem(bytes32 key, bytes32 value) returns()
 func (_Store *StoreTransactor) SetItem(opts *bind.TransactOpts, key [32]byte, value [32]byte) (*types.Transaction, error) {
 	return _Store.contract.Transact(opts, "setItem", key, value)
 }
 
-// SetItem is a paid mutator transaction binding the contract method.
+// SetItem is a paid mutator transaction binding the contract method 0xf56256c7.
+//
 // Solidity: function setItem(bytes32 key, bytes32 value) returns()
 func (_Store *StoreSession) SetItem(key [32]byte, value [32]byte) (*types.Transaction, error) {
 	return _Store.Contract.SetItem(&_Store.TransactOpts, key, value)
 }
 
-// FilterItemSet is a free log retrieval operation binding the contract event.
+// SetItem is a paid mutator transaction binding the contract method 0xf56256c7.
+//
+// Solidity: function setItem(bytes32 key, bytes32 value) returns()
+func (_Store *StoreTransactorSession) SetItem(key [32]byte, value [32]byte) (*types.Transaction, error) {
+	return _Store.Contract.SetItem(&_Store.TransactOpts, key, value)
+}
+
+// StoreItemSetIterator is returned from FilterItemSet and is used to iterate over the raw logs and unpacked data for ItemSet events raised by the Store contract.
+type StoreItemSetIterator struct {
+	Event *StoreItemSet // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *StoreItemSetIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(StoreItemSet)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(StoreItemSet)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *StoreItemSetIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *StoreItemSetIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// StoreItemSet represents a ItemSet event raised by the Store contract.
+type StoreItemSet struct {
+	Key   [32]byte
+	Value [32]byte
+	Raw   types.Log // Blockchain specific contextual infos
+}
+
+// FilterItemSet is a free log retrieval operation binding the contract event 0xe79e73da417710ae99aa2088575580a60415d359acfad9cdd3382d59c80281d4.
+//
 // Solidity: event ItemSet(bytes32 indexed key, bytes32 value)
 func (_Store *StoreFilterer) FilterItemSet(opts *bind.FilterOpts, key [][32]byte) (*StoreItemSetIterator, error) {
+
 	var keyRule []interface{}
-	if len(key) == 0 {
-		keyRule = []interface{}{new([32]byte)}
-	} else {
-		keyRule = make([]interface{}, len(key))
-		for i, k := range key {
-			keyRule[i] = k
-		}
+	for _, keyItem := range key {
+		keyRule = append(keyRule, keyItem)
 	}
 
-	_, sub, err := _Store.contract.FilterLogs(opts, "ItemSet", keyRule)
+	logs, sub, err := _Store.contract.FilterLogs(opts, "ItemSet", keyRule)
 	if err != nil {
 		return nil, err
 	}
-	return &StoreItemSetIterator{event: sub}, nil
+	return &StoreItemSetIterator{contract: _Store.contract, event: "ItemSet", logs: logs, sub: sub}, nil
 }
 
-// WatchItemSet is a free log subscription operation binding the contract event.
+// WatchItemSet is a free log subscription operation binding the contract event 0xe79e73da417710ae99aa2088575580a60415d359acfad9cdd3382d59c80281d4.
+//
 // Solidity: event ItemSet(bytes32 indexed key, bytes32 value)
 func (_Store *StoreFilterer) WatchItemSet(opts *bind.WatchOpts, sink chan<- *StoreItemSet, key [][32]byte) (event.Subscription, error) {
+
 	var keyRule []interface{}
-	if len(key) == 0 {
-		keyRule = []interface{}{new([32]byte)}
-	} else {
-		keyRule = make([]interface{}, len(key))
-		for i, k := range key {
-			keyRule[i] = k
-		}
+	for _, keyItem := range key {
+		keyRule = append(keyRule, keyItem)
 	}
 
 	logs, sub, err := _Store.contract.WatchLogs(opts, "ItemSet", keyRule)
@@ -189,11 +426,13 @@ func (_Store *StoreFilterer) WatchItemSet(opts *bind.WatchOpts, sink chan<- *Sto
 		for {
 			select {
 			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
 				event := new(StoreItemSet)
 				if err := _Store.contract.UnpackLog(event, "ItemSet", log); err != nil {
 					return err
 				}
 				event.Raw = log
+
 				select {
 				case sink <- event:
 				case err := <-sub.Err():
@@ -210,7 +449,8 @@ func (_Store *StoreFilterer) WatchItemSet(opts *bind.WatchOpts, sink chan<- *Sto
 	}), nil
 }
 
-// ParseItemSet is a log parse operation binding the contract event.
+// ParseItemSet is a log parse operation binding the contract event 0xe79e73da417710ae99aa2088575580a60415d359acfad9cdd3382d59c80281d4.
+//
 // Solidity: event ItemSet(bytes32 indexed key, bytes32 value)
 func (_Store *StoreFilterer) ParseItemSet(log types.Log) (*StoreItemSet, error) {
 	event := new(StoreItemSet)
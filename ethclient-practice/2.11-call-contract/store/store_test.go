@@ -0,0 +1,26 @@
+package store
+
+import (
+	"testing"
+
+	"github.com/dapp-learning/ethclient/util/bindgen"
+)
+
+// 已提交的 store.go 必须与 2.09 的编译产物一致，参数与 gen.go 中的 go:generate 指令相同。
+// 修改合约后运行 go generate ./store 重新生成
+func TestBindingUpToDate(t *testing.T) {
+	src, err := bindgen.FromArtifact("../../2.09-deploy-contract/contract/Store_sol_Store.abi", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bindgen.CheckCompiler(src, "0.8.30"); err != nil {
+		t.Fatal(err)
+	}
+	code, err := bindgen.Generate(src, "store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bindgen.Check("store.go", code); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,283 @@
+// Package abiarg 把命令行传入的字符串参数按 ABI 类型转换为 go-ethereum
+// abi.Pack 所需的 Go 值。
+//
+// 支持的写法：
+//
+//	address   0x 开头的 20 字节十六进制
+//	bool      true / false / 1 / 0
+//	uintN     十进制或 0x 十六进制，可带 ether / gwei 单位，如 1.5ether
+//	intN      同上，可为负数
+//	bytes     0x 开头的十六进制
+//	bytesN    0x 开头的十六进制（不足 N 字节右侧补零），或不带 0x 的短文本（按 UTF-8 右侧补零）
+//	string    原样使用，可用双引号包裹
+//	T[] / T[k] / 元组  用方括号包裹、逗号分隔，如 [1,2,3]、[[0xabc...,1],[0xdef...,2]]
+package abiarg
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// ParseArgs 按参数列表依次转换字符串参数，数量必须一致
+func ParseArgs(args abi.Arguments, values []string) ([]interface{}, error) {
+	if len(args) != len(values) {
+		return nil, fmt.Errorf("需要 %d 个参数 (%s)，实际传入 %d 个", len(args), describe(args), len(values))
+	}
+	out := make([]interface{}, len(args))
+	for i, arg := range args {
+		v, err := Parse(arg.Type, values[i])
+		if err != nil {
+			name := arg.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i)
+			}
+			return nil, fmt.Errorf("参数 %s (%s): %w", name, arg.Type.String(), err)
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// Parse 把单个字符串转换为 typ 对应的 Go 值
+func Parse(typ abi.Type, s string) (interface{}, error) {
+	v, err := parseValue(typ, strings.TrimSpace(s))
+	if err != nil {
+		return nil, err
+	}
+	return v.Interface(), nil
+}
+
+func parseValue(typ abi.Type, s string) (reflect.Value, error) {
+	switch typ.T {
+	case abi.AddressTy:
+		if !common.IsHexAddress(s) {
+			return reflect.Value{}, fmt.Errorf("无效的地址 %q", s)
+		}
+		return reflect.ValueOf(common.HexToAddress(s)), nil
+
+	case abi.BoolTy:
+		b, err := strconv.ParseBool(unquote(s))
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("无效的布尔值 %q", s)
+		}
+		return reflect.ValueOf(b), nil
+
+	case abi.StringTy:
+		return reflect.ValueOf(unquote(s)), nil
+
+	case abi.BytesTy:
+		b, err := hexutil.Decode(unquote(s))
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("无效的 bytes %q: %w", s, err)
+		}
+		return reflect.ValueOf(b), nil
+
+	case abi.FixedBytesTy:
+		b, err := fixedBytes(unquote(s), typ.Size)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		arr := reflect.New(typ.GetType()).Elem()
+		reflect.Copy(arr, reflect.ValueOf(b))
+		return arr, nil
+
+	case abi.IntTy, abi.UintTy:
+		n, err := ParseInt(unquote(s))
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return intValue(typ, n)
+
+	case abi.SliceTy, abi.ArrayTy:
+		items, err := SplitList(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		var list reflect.Value
+		if typ.T == abi.ArrayTy {
+			if len(items) != typ.Size {
+				return reflect.Value{}, fmt.Errorf("%s 需要 %d 个元素，实际 %d 个", typ.String(), typ.Size, len(items))
+			}
+			list = reflect.New(typ.GetType()).Elem()
+		} else {
+			list = reflect.MakeSlice(typ.GetType(), len(items), len(items))
+		}
+		for i, item := range items {
+			v, err := parseValue(*typ.Elem, item)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("第 %d 个元素: %w", i, err)
+			}
+			list.Index(i).Set(v)
+		}
+		return list, nil
+
+	case abi.TupleTy:
+		items, err := SplitList(s)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if len(items) != len(typ.TupleElems) {
+			return reflect.Value{}, fmt.Errorf("元组 %s 需要 %d 个字段，实际 %d 个", typ.String(), len(typ.TupleElems), len(items))
+		}
+		tuple := reflect.New(typ.GetType()).Elem()
+		for i, elem := range typ.TupleElems {
+			v, err := parseValue(*elem, items[i])
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("字段 %s: %w", typ.TupleRawNames[i], err)
+			}
+			tuple.Field(i).Set(v)
+		}
+		return tuple, nil
+	}
+	return reflect.Value{}, fmt.Errorf("不支持的类型 %s", typ.String())
+}
+
+// ParseInt 解析十进制 / 0x 十六进制整数，支持 ether、gwei、wei 单位后缀（如 0.5ether）
+func ParseInt(s string) (*big.Int, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), "_", "")
+	lower := strings.ToLower(s)
+	for _, unit := range []struct {
+		suffix   string
+		decimals int
+	}{{"ether", 18}, {"eth", 18}, {"gwei", 9}, {"wei", 0}} {
+		if strings.HasSuffix(lower, unit.suffix) {
+			return parseDecimal(strings.TrimSpace(s[:len(s)-len(unit.suffix)]), unit.decimals)
+		}
+	}
+	if strings.HasPrefix(lower, "0x") || strings.HasPrefix(lower, "-0x") {
+		neg := strings.HasPrefix(lower, "-")
+		n, ok := new(big.Int).SetString(strings.TrimPrefix(lower, "-")[2:], 16)
+		if !ok {
+			return nil, fmt.Errorf("无效的整数 %q", s)
+		}
+		if neg {
+			n.Neg(n)
+		}
+		return n, nil
+	}
+	return parseDecimal(s, 0)
+}
+
+// parseDecimal 解析可能带小数点的十进制数，并乘以 10^decimals，结果必须是整数
+func parseDecimal(s string, decimals int) (*big.Int, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || strings.ContainsAny(s, "eE/") {
+		return nil, fmt.Errorf("无效的整数 %q", s)
+	}
+	r.Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)))
+	if !r.IsInt() {
+		return nil, fmt.Errorf("%q 不是整数（精度超出 %d 位小数）", s, decimals)
+	}
+	return r.Num(), nil
+}
+
+// intValue 检查范围并转换为 abi 包对该位宽要求的 Go 类型（uint8 … uint64 / *big.Int）
+func intValue(typ abi.Type, n *big.Int) (reflect.Value, error) {
+	if typ.T == abi.UintTy {
+		if n.Sign() < 0 || n.BitLen() > typ.Size {
+			return reflect.Value{}, fmt.Errorf("%s 超出 %s 的范围", n, typ.String())
+		}
+	} else {
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(typ.Size-1))
+		if n.Cmp(limit) >= 0 || n.Cmp(new(big.Int).Neg(limit)) < 0 {
+			return reflect.Value{}, fmt.Errorf("%s 超出 %s 的范围", n, typ.String())
+		}
+	}
+	goType := typ.GetType()
+	switch goType.Kind() {
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return reflect.ValueOf(n.Uint64()).Convert(goType), nil
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflect.ValueOf(n.Int64()).Convert(goType), nil
+	}
+	return reflect.ValueOf(n), nil
+}
+
+// fixedBytes 解析 bytesN：十六进制右侧补零；不带 0x 时按文本处理（常用于 bytes32 键）
+func fixedBytes(s string, size int) ([]byte, error) {
+	var b []byte
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		var err error
+		if b, err = hexutil.Decode(s); err != nil {
+			return nil, fmt.Errorf("无效的 bytes%d %q: %w", size, s, err)
+		}
+	} else {
+		b = []byte(s)
+	}
+	if len(b) > size {
+		return nil, fmt.Errorf("%q 超过 %d 字节", s, size)
+	}
+	return common.RightPadBytes(b, size), nil
+}
+
+// SplitList 拆分 [a, b, [c, d], "e,f"] 形式的列表，返回顶层元素
+func SplitList(s string) ([]string, error) {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != '[' || s[len(s)-1] != ']' {
+		return nil, fmt.Errorf("列表需要用方括号包裹: %q", s)
+	}
+	body := strings.TrimSpace(s[1 : len(s)-1])
+	if body == "" {
+		return nil, nil
+	}
+
+	var (
+		items   []string
+		depth   int
+		quoted  bool
+		start   int
+		escaped bool
+	)
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case escaped:
+			escaped = false
+		case quoted && c == '\\':
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case quoted:
+		case c == '[':
+			depth++
+		case c == ']':
+			depth--
+			if depth < 0 {
+				return nil, fmt.Errorf("方括号不匹配: %q", s)
+			}
+		case c == ',' && depth == 0:
+			items = append(items, strings.TrimSpace(body[start:i]))
+			start = i + 1
+		}
+	}
+	if depth != 0 || quoted {
+		return nil, fmt.Errorf("方括号或引号不匹配: %q", s)
+	}
+	return append(items, strings.TrimSpace(body[start:])), nil
+}
+
+// unquote 去掉首尾的双引号
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		if u, err := strconv.Unquote(s); err == nil {
+			return u
+		}
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
+func describe(args abi.Arguments) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		parts[i] = strings.TrimSpace(arg.Type.String() + " " + arg.Name)
+	}
+	return strings.Join(parts, ", ")
+}
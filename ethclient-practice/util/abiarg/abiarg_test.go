@@ -0,0 +1,106 @@
+package abiarg_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+
+	"github.com/dapp-learning/ethclient/util/abiarg"
+)
+
+const owner = "0x71C7656EC7ab88b098defB751B7401B5f6d8976F"
+
+func mustType(t *testing.T, typ string, components ...abi.ArgumentMarshaling) abi.Type {
+	t.Helper()
+	parsed, err := abi.NewType(typ, "", components)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestParse(t *testing.T) {
+	tuple := mustType(t, "tuple", abi.ArgumentMarshaling{Name: "owner", Type: "address"}, abi.ArgumentMarshaling{Name: "amount", Type: "uint256"})
+	nested := mustType(t, "uint256[2][]")
+	bytes4 := mustType(t, "bytes4")
+	uint8T, uint256T, int8T := mustType(t, "uint8"), mustType(t, "uint256"), mustType(t, "int8")
+	maxUint256 := "0x" + strings.Repeat("ff", 32)
+
+	tests := []struct {
+		name  string
+		typ   abi.Type
+		input string
+		want  string // 成功时 fmt.Sprint 的结果
+		err   string // 失败时错误信息中应包含的内容
+	}{
+		// 元组
+		{"tuple", tuple, "[" + owner + ", 1ether]", "{" + owner + " 1000000000000000000}", ""},
+		{"tuple missing field", tuple, "[" + owner + "]", "", "需要 2 个字段"},
+		{"tuple without brackets", tuple, owner + ",1", "", "方括号"},
+		{"tuple bad field", tuple, "[0x1234,1]", "", "字段 owner"},
+
+		// 嵌套数组：外层动态，内层定长 2
+		{"nested", nested, "[[1,2],[3,4]]", "[[1 2] [3 4]]", ""},
+		{"nested empty", nested, "[]", "[]", ""},
+		{"nested short", nested, "[[1,2],[3]]", "", "第 1 个元素: uint256[2] 需要 2 个元素"},
+		{"nested unbalanced", nested, "[[1,2],[3,4]", "", "不匹配"},
+		{"nested bad element", nested, "[[1,x]]", "", "无效的整数"},
+
+		// bytesN
+		{"bytes4 hex", bytes4, "0x1234", "[18 52 0 0]", ""},
+		{"bytes4 text", bytes4, "abcd", "[97 98 99 100]", ""},
+		{"bytes4 too long", bytes4, "0x1234567890", "", "超过 4 字节"},
+		{"bytes4 text too long", bytes4, "abcde", "", "超过 4 字节"},
+		{"bytes4 odd hex", bytes4, "0x123", "", "无效的 bytes4"},
+
+		// 负数与范围
+		{"uint negative", uint256T, "-1", "", "超出 uint256 的范围"},
+		{"uint negative hex", uint256T, "-0x1", "", "超出 uint256 的范围"},
+		{"uint8 max", uint8T, "255", "255", ""},
+		{"uint8 overflow", uint8T, "256", "", "超出 uint8 的范围"},
+		{"int8 min", int8T, "-128", "-128", ""},
+		{"int8 underflow", int8T, "-129", "", "超出 int8 的范围"},
+		{"int8 max", int8T, "0x7f", "127", ""},
+
+		// 最大值：不支持 max 关键字，需要写出数值
+		{"uint256 max", uint256T, maxUint256, "115792089237316195423570985008687907853269984665640564039457584007913129639935", ""},
+		{"uint256 max + 1", uint256T, "0x1" + strings.Repeat("00", 32), "", "超出 uint256 的范围"},
+		{"max keyword", uint256T, "max", "", "无效的整数"},
+		{"scientific", uint256T, "1e18", "", "无效的整数"},
+		{"fraction of wei", uint256T, "1.5wei", "", "不是整数"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := abiarg.Parse(tt.typ, tt.input)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("Parse(%s, %q) 返回 %v，期望错误包含 %q", tt.typ, tt.input, err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := fmt.Sprint(got); s != tt.want {
+				t.Fatalf("Parse(%s, %q) = %s，期望 %s", tt.typ, tt.input, s, tt.want)
+			}
+			// 结果可以直接交给 abi.Pack
+			if _, err := (abi.Arguments{{Type: tt.typ}}).Pack(got); err != nil {
+				t.Fatalf("无法编码 %v: %v", got, err)
+			}
+		})
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	args := abi.Arguments{{Name: "to", Type: mustType(t, "address")}, {Type: mustType(t, "uint256")}}
+	if _, err := abiarg.ParseArgs(args, []string{owner}); err == nil || !strings.Contains(err.Error(), "需要 2 个参数 (address to, uint256)") {
+		t.Fatalf("参数数量不符时返回 %v", err)
+	}
+	// 没有名称的参数按位置描述
+	if _, err := abiarg.ParseArgs(args, []string{owner, "-1"}); err == nil || !strings.Contains(err.Error(), "参数 #1 (uint256)") {
+		t.Fatalf("错误信息没有指出参数位置: %v", err)
+	}
+}
@@ -0,0 +1,261 @@
+// Package bindgen 从 Solidity 源码或编译产物生成 abigen 风格的 Go 绑定，
+// 并检查已提交的绑定是否与合约一致。
+//
+// 生成的代码与 abigen 相同：带字节码时包含 DeployXxx 部署函数，
+// 每个事件都有 FilterXxx / WatchXxx / ParseXxx 以及类型化的迭代器 XxxIterator。
+package bindgen
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/abigen"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/dapp-learning/ethclient/util/deploy"
+	"github.com/dapp-learning/ethclient/util/sigdb"
+	"github.com/dapp-learning/ethclient/util/verify"
+)
+
+// ErrStale 已提交的绑定与重新生成的结果不一致
+var ErrStale = errors.New("绑定代码已过期")
+
+// ErrCompilerVersion 字节码不是由要求的 solc 版本编译的
+var ErrCompilerVersion = errors.New("solc 版本不一致")
+
+// ErrNoCompiler PATH 中既没有 solc 也没有 solcjs
+var ErrNoCompiler = errors.New("未找到 solc 或 solcjs，请先安装: npm install -g solc")
+
+// Source 生成绑定需要的合约信息
+type Source struct {
+	Name     string          // 合约名，也是生成的 Go 类型名
+	ABI      json.RawMessage // ABI JSON
+	Bytecode []byte          // 创建字节码，为空时不生成部署函数
+	Origin   string          // 来源描述，用于提示信息
+}
+
+// FromArtifact 从编译产物读取，支持 deploy.LoadArtifact 识别的所有格式
+func FromArtifact(path, name string) (*Source, error) {
+	art, err := deploy.LoadArtifact(path, name)
+	if err != nil {
+		return nil, err
+	}
+	return &Source{Name: art.Name, ABI: art.RawABI, Bytecode: art.Bytecode, Origin: path}, nil
+}
+
+// FromABI 只从 ABI 文件读取（纯 ABI 数组或带 abi 字段的产物），生成的绑定没有部署函数
+func FromABI(path, name string) (*Source, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	extracted, err := sigdb.ExtractABI(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if name == "" {
+		name = contractName(path)
+	}
+	return &Source{Name: name, ABI: extracted, Origin: path}, nil
+}
+
+// Compile 用 solc（优先）或 solcjs 编译 Solidity 源文件，取出名为 name 的合约。
+// name 为空时使用文件名（Store.sol → Store）
+func Compile(solPath, name string) (*Source, error) {
+	if name == "" {
+		name = contractName(solPath)
+	}
+	dir, err := os.MkdirTemp("", "bindgen")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	var artifact string
+	switch {
+	case lookPath("solc") != "":
+		// solc --combined-json 输出到标准输出
+		out, err := run(exec.Command("solc", "--combined-json", "abi,bin,bin-runtime", solPath))
+		if err != nil {
+			return nil, err
+		}
+		artifact = filepath.Join(dir, "combined.json")
+		if err := os.WriteFile(artifact, out, 0o644); err != nil {
+			return nil, err
+		}
+	case lookPath("solcjs") != "":
+		// solcjs 在输出目录生成 <路径>_sol_<合约>.abi / .bin
+		if _, err := run(exec.Command("solcjs", "--abi", "--bin", "-o", dir, solPath)); err != nil {
+			return nil, err
+		}
+		matches, err := filepath.Glob(filepath.Join(dir, "*_sol_"+name+".abi"))
+		if err != nil {
+			return nil, err
+		}
+		if len(matches) != 1 {
+			return nil, fmt.Errorf("solcjs 输出中没有合约 %s", name)
+		}
+		artifact = matches[0]
+	default:
+		return nil, ErrNoCompiler
+	}
+
+	src, err := FromArtifact(artifact, name)
+	if err != nil {
+		return nil, err
+	}
+	src.Origin = solPath
+	return src, nil
+}
+
+// CheckCompiler 确认字节码由 want 版本（如 "0.8.30"）的 solc 编译。
+// 版本不同时字节码末尾的元数据不同，生成的绑定也会不同
+func CheckCompiler(src *Source, want string) error {
+	if len(src.Bytecode) == 0 {
+		return fmt.Errorf("%s 没有字节码，无法确认 solc 版本", src.Origin)
+	}
+	_, metadata := verify.SplitMetadata(src.Bytecode)
+	got := verify.SolcVersion(metadata)
+	if got == "" {
+		return fmt.Errorf("%w: %s 的字节码中没有 solc 版本元数据", ErrCompilerVersion, src.Origin)
+	}
+	if got != want {
+		return fmt.Errorf("%w: %s 由 solc %s 编译，要求 %s", ErrCompilerVersion, src.Origin, got, want)
+	}
+	return nil
+}
+
+// Generate 生成 Go 绑定代码
+func Generate(src *Source, pkg string) ([]byte, error) {
+	if _, err := abi.JSON(bytes.NewReader(src.ABI)); err != nil {
+		return nil, fmt.Errorf("%s: 解析 ABI 失败: %w", src.Origin, err)
+	}
+	var bin string
+	if len(src.Bytecode) > 0 {
+		bin = hexutil.Encode(src.Bytecode)
+	}
+	code, err := abigen.Bind([]string{src.Name}, []string{string(src.ABI)}, []string{bin}, []map[string]string{nil}, pkg, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("生成 %s 绑定失败: %w", src.Name, err)
+	}
+	return []byte(code), nil
+}
+
+// Check 比较已有文件与重新生成的代码，不一致时返回 ErrStale 并说明差异
+func Check(path string, generated []byte) error {
+	current, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("%w: %s 不存在", ErrStale, path)
+	}
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(current, generated) {
+		return nil
+	}
+
+	msg := fmt.Sprintf("%s 与合约不一致", path)
+	if added, removed := diffAPI(current, generated); len(added)+len(removed) > 0 {
+		if len(added) > 0 {
+			msg += fmt.Sprintf("，缺少: %s", strings.Join(added, ", "))
+		}
+		if len(removed) > 0 {
+			msg += fmt.Sprintf("，多出: %s", strings.Join(removed, ", "))
+		}
+	} else {
+		msg += fmt.Sprintf("（第 %d 行起不同）", firstDiffLine(current, generated))
+	}
+	return fmt.Errorf("%w: %s", ErrStale, msg)
+}
+
+// diffAPI 按导出的函数和类型比较两份绑定，便于看出是哪个方法或事件发生了变化
+func diffAPI(current, generated []byte) (added, removed []string) {
+	have, want := exported(current), exported(generated)
+	for name := range want {
+		if !have[name] {
+			added = append(added, name)
+		}
+	}
+	for name := range have {
+		if !want[name] {
+			removed = append(removed, name)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
+
+// exported 收集 "func (_X *Recv) Name(" 与 "type Name " 声明
+func exported(code []byte) map[string]bool {
+	names := make(map[string]bool)
+	for _, line := range strings.Split(string(code), "\n") {
+		switch {
+		case strings.HasPrefix(line, "func ("):
+			recv := line[len("func ("):strings.Index(line, ")")]
+			rest := strings.TrimSpace(line[strings.Index(line, ")")+1:])
+			if idx := strings.Index(rest, "("); idx > 0 {
+				fields := strings.Fields(recv)
+				names[strings.TrimPrefix(fields[len(fields)-1], "*")+"."+rest[:idx]] = true
+			}
+		case strings.HasPrefix(line, "func "):
+			if idx := strings.Index(line, "("); idx > 0 {
+				names[line[len("func "):idx]] = true
+			}
+		case strings.HasPrefix(line, "type "):
+			if fields := strings.Fields(line); len(fields) >= 2 {
+				names[fields[1]] = true
+			}
+		}
+	}
+	return names
+}
+
+func firstDiffLine(a, b []byte) int {
+	la, lb := strings.Split(string(a), "\n"), strings.Split(string(b), "\n")
+	for i := 0; i < len(la) && i < len(lb); i++ {
+		if la[i] != lb[i] {
+			return i + 1
+		}
+	}
+	if len(la) < len(lb) {
+		return len(la) + 1
+	}
+	return len(lb) + 1
+}
+
+// contractName 从文件名推断合约名：Store.sol、Store.json、Store_sol_Store.abi → Store
+func contractName(path string) string {
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if idx := strings.LastIndex(name, "_sol_"); idx >= 0 {
+		name = name[idx+len("_sol_"):]
+	}
+	return name
+}
+
+func lookPath(name string) string {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return ""
+	}
+	return path
+}
+
+// run 执行编译器，失败时返回其标准错误输出
+func run(cmd *exec.Cmd) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s 失败: %w\n%s", strings.Join(cmd.Args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
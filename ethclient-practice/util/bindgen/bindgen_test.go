@@ -0,0 +1,100 @@
+package bindgen_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dapp-learning/ethclient/util/bindgen"
+)
+
+// storeArtifact 2.09 提交的 Store 编译产物（solcjs 0.8.30）
+const storeArtifact = "../../2.09-deploy-contract/contract/Store_sol_Store.abi"
+
+func TestCheckCompiler(t *testing.T) {
+	src, err := bindgen.FromArtifact(storeArtifact, "Store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bindgen.CheckCompiler(src, "0.8.30"); err != nil {
+		t.Fatal(err)
+	}
+	if err := bindgen.CheckCompiler(src, "0.8.26"); !errors.Is(err, bindgen.ErrCompilerVersion) {
+		t.Fatalf("版本不同时返回 %v，期望 %v", err, bindgen.ErrCompilerVersion)
+	}
+
+	// 没有元数据的字节码
+	handwritten := &bindgen.Source{Bytecode: []byte{0x60, 0x00, 0x60, 0x00, 0xf3}, Origin: "handwritten"}
+	if err := bindgen.CheckCompiler(handwritten, "0.8.30"); !errors.Is(err, bindgen.ErrCompilerVersion) {
+		t.Fatalf("手写字节码返回 %v，期望 %v", err, bindgen.ErrCompilerVersion)
+	}
+}
+
+// removeMethod 删除绑定中所有接收者的 name 方法，模拟合约新增方法后没有重新生成的绑定
+func removeMethod(code []byte, name string) []byte {
+	var out []string
+	skip := false
+	for _, line := range strings.Split(string(code), "\n") {
+		if strings.HasPrefix(line, "func (") && strings.Contains(line, ") "+name+"(") {
+			skip = true
+		}
+		if !skip {
+			out = append(out, line)
+		}
+		if skip && line == "}" {
+			skip = false
+		}
+	}
+	return []byte(strings.Join(out, "\n"))
+}
+
+func TestCheck(t *testing.T) {
+	src, err := bindgen.FromArtifact(storeArtifact, "Store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	generated, err := bindgen.Generate(src, "store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+
+	tests := []struct {
+		name    string
+		current []byte // nil 表示文件不存在
+		want    []string
+	}{
+		{"up to date", generated, nil},
+		{"missing file", nil, []string{"不存在"}},
+		{"method removed", removeMethod(generated, "GetItem"), []string{"缺少: StoreCaller.GetItem, StoreCallerSession.GetItem, StoreSession.GetItem"}},
+		{"method added", append(append([]byte(nil), generated...), "\nfunc (_Store *StoreCaller) Legacy() {}\n"...), []string{"多出: StoreCaller.Legacy"}},
+		{"same API", []byte(strings.Replace(string(generated), "package store", "package store2", 1)), []string{"行起不同"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(dir, strings.ReplaceAll(tt.name, " ", "-")+".go")
+			if tt.current != nil {
+				if err := os.WriteFile(path, tt.current, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := bindgen.Check(path, generated)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if !errors.Is(err, bindgen.ErrStale) {
+				t.Fatalf("Check 返回 %v，期望 %v", err, bindgen.ErrStale)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("错误信息 %q 中没有 %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// AccessListResult eth_createAccessList 的结果
+type AccessListResult struct {
+	AccessList types.AccessList `json:"accessList"`
+	GasUsed    hexutil.Uint64   `json:"gasUsed"` // 带上访问列表执行时使用的 Gas
+	Error      string           `json:"error,omitempty"`
+}
+
+// CreateAccessList 用 eth_createAccessList 在 block（nil 为 latest）上执行调用，
+// 返回调用访问的账户和存储槽位。发送方、接收方和预编译合约在交易开始时就已经是 warm 的，
+// 节点不会单独列出它们，但接收方的存储槽位仍会带着接收方地址出现在列表中。
+// 调用失败（如 revert）时不返回错误，原因记录在 Error 中
+func CreateAccessList(ctx context.Context, c RPCCaller, msg ethereum.CallMsg, block *big.Int) (*AccessListResult, error) {
+	result := new(AccessListResult)
+	if err := c.CallContext(ctx, result, "eth_createAccessList", callArg(msg), blockArg(block)); err != nil {
+		return nil, fmt.Errorf("eth_createAccessList 失败: %w", err)
+	}
+	return result, nil
+}
+
+// AccessListComparison 带与不带访问列表时 eth_estimateGas 的结果
+type AccessListComparison struct {
+	AccessList types.AccessList
+	GasWithout uint64
+	GasWith    uint64
+}
+
+// Saving 使用访问列表节省的 Gas，为负数时表示更贵
+func (c *AccessListComparison) Saving() int64 {
+	return int64(c.GasWithout) - int64(c.GasWith)
+}
+
+// Saves 访问列表是否非空且能节省 Gas
+func (c *AccessListComparison) Saves() bool {
+	return len(c.AccessList) > 0 && c.GasWith < c.GasWithout
+}
+
+// CompareAccessList 为 msg 生成访问列表，并分别估算带与不带访问列表时需要的 Gas。
+// 列表中每个地址预付 2400、每个槽位预付 1900，之后的访问按 warm 计费（100），
+// 比首次访问的 cold 费用（2600 / 2100）各省 100；但接收方地址本来就是 warm 的，
+// 只访问接收方存储的调用带上列表反而更贵，所以要以估算结果为准
+func CompareAccessList(ctx context.Context, c RPCCaller, msg ethereum.CallMsg) (*AccessListComparison, error) {
+	msg.AccessList = nil
+	result, err := CreateAccessList(ctx, c, msg, nil)
+	if err != nil {
+		return nil, err
+	}
+	if result.Error != "" {
+		return nil, fmt.Errorf("生成访问列表时调用失败: %s", result.Error)
+	}
+	cmp := &AccessListComparison{AccessList: result.AccessList}
+	if cmp.GasWithout, err = estimateGas(ctx, c, msg); err != nil {
+		return nil, err
+	}
+	if len(cmp.AccessList) == 0 {
+		cmp.GasWith = cmp.GasWithout
+		return cmp, nil
+	}
+	msg.AccessList = cmp.AccessList
+	if cmp.GasWith, err = estimateGas(ctx, c, msg); err != nil {
+		return nil, err
+	}
+	return cmp, nil
+}
+
+// AttachAccessList 以 from 为发送方比较 req 带与不带访问列表的 Gas，
+// 能节省 Gas 时把访问列表写入 req.AccessList，之后 BuildTx 会据此构造交易
+func AttachAccessList(ctx context.Context, c RPCCaller, from common.Address, req *TxRequest) (*AccessListComparison, error) {
+	cmp, err := CompareAccessList(ctx, c, ethereum.CallMsg{From: from, To: req.To, Value: req.Value, Data: req.Data})
+	if err != nil {
+		return nil, err
+	}
+	if cmp.Saves() {
+		req.AccessList = cmp.AccessList
+	} else {
+		req.AccessList = nil
+	}
+	return cmp, nil
+}
+
+// WriteReport 打印访问列表以及带与不带列表时估算的 Gas
+func (c *AccessListComparison) WriteReport(w io.Writer) {
+	WriteAccessList(w, c.AccessList)
+	fmt.Fprintf(w, "不带访问列表: %d Gas\n", c.GasWithout)
+	if len(c.AccessList) == 0 {
+		return
+	}
+	if saving := c.Saving(); saving > 0 {
+		fmt.Fprintf(w, "带访问列表:   %d Gas（节省 %d）\n", c.GasWith, saving)
+	} else {
+		fmt.Fprintf(w, "带访问列表:   %d Gas（多花 %d）\n", c.GasWith, -saving)
+	}
+}
+
+// WriteAccessList 按地址打印访问列表中的账户和存储槽位
+func WriteAccessList(w io.Writer, list types.AccessList) {
+	if len(list) == 0 {
+		fmt.Fprintln(w, "访问列表为空：调用没有读写存储，也没有访问其他合约")
+		return
+	}
+	fmt.Fprintf(w, "访问列表: %d 个地址，%d 个存储槽位\n", len(list), list.StorageKeys())
+	for _, tuple := range list {
+		fmt.Fprintf(w, "  %s\n", tuple.Address.Hex())
+		for _, slot := range tuple.StorageKeys {
+			fmt.Fprintf(w, "    %s\n", slot.Hex())
+		}
+	}
+}
+
+func estimateGas(ctx context.Context, c RPCCaller, msg ethereum.CallMsg) (uint64, error) {
+	var gas hexutil.Uint64
+	if err := c.CallContext(ctx, &gas, "eth_estimateGas", callArg(msg)); err != nil {
+		return 0, fmt.Errorf("估算 Gas 失败（交易可能会 revert）: %w", err)
+	}
+	return uint64(gas), nil
+}
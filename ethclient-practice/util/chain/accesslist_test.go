@@ -0,0 +1,96 @@
+package chain_test
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/simnet/contracts"
+)
+
+func TestAccessList(t *testing.T) {
+	net := newNet(t)
+	ctx := context.Background()
+	alice := net.Accounts[0]
+
+	// 只访问接收方的存储：接收方本来就是 warm 的，列表反而更贵，不附加
+	storeABI, err := abi.JSON(strings.NewReader(contracts.StoreABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	input, _ := storeABI.Pack("setItem", [32]byte{1}, [32]byte{2})
+	single := &chain.TxRequest{To: &net.Store, Data: input}
+	cmp, err := chain.AttachAccessList(ctx, net.RPC, alice.Address, single)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cmp.AccessList) != 1 || cmp.AccessList[0].Address != net.Store || cmp.Saves() || cmp.Saving() >= 0 {
+		t.Fatalf("Store.setItem: 列表 %v，不带 %d，带 %d", cmp.AccessList, cmp.GasWithout, cmp.GasWith)
+	}
+	if single.AccessList != nil {
+		t.Fatal("不能节省 Gas 时不应附加访问列表")
+	}
+
+	// 通过转发合约调用代币：代币合约和它的余额槽位都是 cold，列表能节省 Gas
+	forwarder, _, err := net.Deploy(ctx, alice, contracts.ForwarderDeployData(net.Token))
+	if err != nil {
+		t.Fatal(err)
+	}
+	amount := new(big.Int).Mul(big.NewInt(100), ether)
+	if _, err := net.Send(ctx, alice, &net.Token, nil, chain.TransferData(forwarder, amount)); err != nil {
+		t.Fatal(err)
+	}
+	// 两个接收方都从未持有代币，存储写入的开销相同
+	plain := &chain.TxRequest{To: &forwarder, Data: chain.TransferData(net.Accounts[2].Address, ether)}
+	withList := &chain.TxRequest{To: &forwarder, Data: chain.TransferData(net.Accounts[3].Address, ether)}
+	if cmp, err = chain.AttachAccessList(ctx, net.RPC, alice.Address, withList); err != nil {
+		t.Fatal(err)
+	}
+	if !cmp.Saves() || withList.AccessList == nil {
+		t.Fatalf("多合约调用: 列表 %v，不带 %d，带 %d", cmp.AccessList, cmp.GasWithout, cmp.GasWith)
+	}
+	var token bool
+	for _, tuple := range withList.AccessList {
+		token = token || (tuple.Address == net.Token && len(tuple.StorageKeys) > 0)
+	}
+	if !token {
+		t.Fatalf("访问列表中没有代币合约的槽位: %v", withList.AccessList)
+	}
+
+	// BuildTx 根据 AccessList 构造交易；模拟链支持 EIP-1559，列表放在动态费用交易中
+	tests := []struct {
+		name string
+		req  *chain.TxRequest
+		list bool
+	}{
+		{"store", single, false},
+		{"plain", plain, false},
+		{"with list", withList, true},
+	}
+	gasUsed := make(map[string]uint64)
+	for _, tt := range tests {
+		tx, err := chain.BuildTx(ctx, net.Client, alice.Address, net.ChainID, tt.req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tx.Type() != types.DynamicFeeTxType || (len(tx.AccessList()) > 0) != tt.list {
+			t.Fatalf("%s: 交易类型 %s，访问列表 %d 个地址", tt.name, chain.TxTypeName(tx.Type()), len(tx.AccessList()))
+		}
+		signed, err := types.SignTx(tx, types.LatestSignerForChainID(net.ChainID), alice.Key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := net.Client.SendTransaction(ctx, signed); err != nil {
+			t.Fatal(err)
+		}
+		gasUsed[tt.name] = mine(t, net, signed).GasUsed
+	}
+	if gasUsed["with list"] >= gasUsed["plain"] {
+		t.Fatalf("带访问列表使用 %d Gas，不带 %d", gasUsed["with list"], gasUsed["plain"])
+	}
+}
@@ -0,0 +1,46 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Balance 一个地址的余额查询结果
+type Balance struct {
+	Address common.Address
+	Wei     *big.Int
+	Err     error // 查询失败时不为 nil，Wei 为 nil
+}
+
+// GetBalance 查询地址在指定区块的余额，number 为 nil 时查询最新区块
+func GetBalance(ctx context.Context, r ChainReader, address common.Address, number *big.Int) (*big.Int, error) {
+	balance, err := r.BalanceAt(ctx, address, number)
+	if err != nil {
+		return nil, fmt.Errorf("查询 %s 余额失败: %w", address.Hex(), err)
+	}
+	return balance, nil
+}
+
+// GetBalances 批量查询余额。单个地址失败不影响其他地址，错误记录在对应结果的 Err 中
+func GetBalances(ctx context.Context, r ChainReader, addresses []common.Address, number *big.Int) []*Balance {
+	out := make([]*Balance, len(addresses))
+	for i, addr := range addresses {
+		balance, err := GetBalance(ctx, r, addr, number)
+		out[i] = &Balance{Address: addr, Wei: balance, Err: err}
+	}
+	return out
+}
+
+// TotalBalance 成功查询的余额之和
+func TotalBalance(balances []*Balance) *big.Int {
+	total := new(big.Int)
+	for _, b := range balances {
+		if b.Err == nil {
+			total.Add(total, b.Wei)
+		}
+	}
+	return total
+}
@@ -0,0 +1,273 @@
+package chain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+const (
+	// BlobDataSize 每个 blob 能携带的数据字节数。blob 由 4096 个 32 字节的字段元素组成，
+	// 每个元素必须小于 BLS12-381 的模数，EncodeBlobs 只使用每个元素的低 31 字节
+	BlobDataSize = 31 * 4096
+	// MaxBlobsPerTx 一笔交易最多携带的 blob 数量（EIP-7594）。一个区块的上限随分叉提高，见 MaxBlobsPerBlock
+	MaxBlobsPerTx = params.BlobTxMaxBlobs
+)
+
+// ErrNoBlobSupport 节点还没有启用 Cancun（EIP-4844）
+var ErrNoBlobSupport = errors.New("节点未启用 EIP-4844（区块头没有 excessBlobGas）")
+
+// ErrUnknownBlobParams 不在 ChainConfig 中的网络，无法确定各分叉的 blob 参数
+var ErrUnknownBlobParams = errors.New("未知网络的 blob 参数")
+
+// EncodeBlobs 把任意数据切分并编码为 blob：每个字段元素的首字节为 0，后 31 字节为数据，
+// 最后一个 blob 不足的部分补 0。空数据返回一个全 0 的 blob
+func EncodeBlobs(data []byte) []kzg4844.Blob {
+	n := (len(data) + BlobDataSize - 1) / BlobDataSize
+	if n == 0 {
+		n = 1
+	}
+	blobs := make([]kzg4844.Blob, n)
+	for i := range blobs {
+		chunk := data[i*BlobDataSize:]
+		if len(chunk) > BlobDataSize {
+			chunk = chunk[:BlobDataSize]
+		}
+		for j := 0; len(chunk) > 0; j++ {
+			m := copy(blobs[i][j*32+1:(j+1)*32], chunk)
+			chunk = chunk[m:]
+		}
+	}
+	return blobs
+}
+
+// NewBlobSidecar 在本地计算每个 blob 的 KZG 承诺和证明，生成 blob 交易的 sidecar（版本 0，每个 blob 一个证明）。
+// 每个 blob 的计算需要几十毫秒。Osaka 之后节点只接受带单元证明（cell proofs）的版本 1，
+// BuildBlobTx 会按目标网络的分叉自动转换
+func NewBlobSidecar(blobs []kzg4844.Blob) (*types.BlobTxSidecar, error) {
+	if len(blobs) == 0 || len(blobs) > MaxBlobsPerTx {
+		return nil, fmt.Errorf("blob 数量为 %d，应在 1 到 %d 之间", len(blobs), MaxBlobsPerTx)
+	}
+	sidecar := &types.BlobTxSidecar{Version: types.BlobSidecarVersion0, Blobs: blobs}
+	for i := range blobs {
+		commitment, err := kzg4844.BlobToCommitment(&blobs[i])
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 个 blob 计算承诺失败: %w", i+1, err)
+		}
+		proof, err := kzg4844.ComputeBlobProof(&blobs[i], commitment)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 个 blob 计算证明失败: %w", i+1, err)
+		}
+		sidecar.Commitments = append(sidecar.Commitments, commitment)
+		sidecar.Proofs = append(sidecar.Proofs, proof)
+	}
+	return sidecar, nil
+}
+
+// VerifyBlobSidecar 检查 sidecar 与版本化哈希一致，并验证 KZG 证明：
+// 版本 0 每个 blob 一个证明，版本 1 每个 blob 有 kzg4844.CellProofsPerBlob 个单元证明
+func VerifyBlobSidecar(sidecar *types.BlobTxSidecar, hashes []common.Hash) error {
+	proofs := len(sidecar.Commitments)
+	if sidecar.Version == types.BlobSidecarVersion1 {
+		proofs *= kzg4844.CellProofsPerBlob
+	}
+	if n := len(sidecar.Commitments); n != len(hashes) || len(sidecar.Blobs) != n || len(sidecar.Proofs) != proofs {
+		return fmt.Errorf("sidecar 数量不一致: %d 个哈希，%d 个 blob，%d 个承诺，%d 个证明",
+			len(hashes), len(sidecar.Blobs), n, len(sidecar.Proofs))
+	}
+	hasher := sha256.New()
+	for i := range sidecar.Commitments {
+		if common.Hash(kzg4844.CalcBlobHashV1(hasher, &sidecar.Commitments[i])) != hashes[i] {
+			return fmt.Errorf("第 %d 个承诺与版本化哈希不匹配", i+1)
+		}
+	}
+	switch sidecar.Version {
+	case types.BlobSidecarVersion0:
+		for i := range sidecar.Blobs {
+			if err := kzg4844.VerifyBlobProof(&sidecar.Blobs[i], sidecar.Commitments[i], sidecar.Proofs[i]); err != nil {
+				return fmt.Errorf("第 %d 个 blob 的 KZG 证明无效: %w", i+1, err)
+			}
+		}
+	case types.BlobSidecarVersion1:
+		if err := kzg4844.VerifyCellProofs(sidecar.Blobs, sidecar.Commitments, sidecar.Proofs); err != nil {
+			return fmt.Errorf("KZG 单元证明无效: %w", err)
+		}
+	default:
+		return fmt.Errorf("不支持的 sidecar 版本 %d", sidecar.Version)
+	}
+	return nil
+}
+
+// BlobGasPrice 区块内每单位 blob gas 的价格（wei），由区块头的 excessBlobGas 和当时生效的分叉参数决定。
+// Cancun 之前的区块或 config 为 nil（未知网络）时返回 nil
+func BlobGasPrice(config *params.ChainConfig, header *types.Header) *big.Int {
+	if config == nil || header.ExcessBlobGas == nil || !config.IsCancun(header.Number, header.Time) {
+		return nil
+	}
+	return eip4844.CalcBlobFee(config, header)
+}
+
+// MaxBlobsPerBlock time 时刻一个区块最多包含的 blob 数量，config 为 nil 或 Cancun 之前为 0。
+// Cancun 为 6 个，Prague 提高到 9 个，之后的 BPO 分叉继续提高
+func MaxBlobsPerBlock(config *params.ChainConfig, time uint64) int {
+	if config == nil {
+		return 0
+	}
+	return eip4844.MaxBlobsPerBlock(config, time)
+}
+
+// BlobFeeReader 推算 blob gas 价格需要的节点接口
+type BlobFeeReader interface {
+	HeaderReader
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// BlobFee 按最新区块推算的下一个区块的 blob gas 价格
+type BlobFee struct {
+	Head          uint64   // 推算所依据的区块号
+	ExcessBlobGas uint64   // 下一个区块的 excessBlobGas
+	BaseFee       *big.Int // 下一个区块每单位 blob gas 的价格
+	FeeCap        *big.Int // 建议的 maxFeePerBlobGas：2 × BaseFee，可以承受连续几个区块的上涨
+}
+
+// EstimateBlobFee 由最新区块的 excessBlobGas 和 blobGasUsed 推算下一个区块的 blob gas 价格。
+// blob 的价格与执行 Gas 的 baseFee 相互独立：每个区块的 blob 超过目标数量时上涨，低于目标时下降。
+// 目标数量和涨跌速度随分叉变化，只支持 ChainConfig 中的网络，其他网络返回 ErrUnknownBlobParams
+func EstimateBlobFee(ctx context.Context, r BlobFeeReader) (*BlobFee, error) {
+	chainID, err := r.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链 ID 失败: %w", err)
+	}
+	config := ChainConfig(chainID)
+	if config == nil {
+		return nil, fmt.Errorf("%w: 链 ID %s", ErrUnknownBlobParams, chainID)
+	}
+	head, err := r.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取最新区块头失败: %w", err)
+	}
+	if head.ExcessBlobGas == nil || head.BlobGasUsed == nil {
+		return nil, ErrNoBlobSupport
+	}
+	// 下一个区块的时间戳未知，按 12 秒的出块间隔推算，只影响分叉切换前后的一个区块
+	next := &types.Header{Number: new(big.Int).Add(head.Number, common.Big1), Time: head.Time + 12}
+	excess := eip4844.CalcExcessBlobGas(config, head, next.Time)
+	next.ExcessBlobGas = &excess
+	fee := &BlobFee{Head: head.Number.Uint64(), ExcessBlobGas: excess, BaseFee: BlobGasPrice(config, next)}
+	fee.FeeCap = new(big.Int).Mul(fee.BaseFee, big.NewInt(2))
+	return fee, nil
+}
+
+// Cost blobs 个 blob 按 BaseFee 计算的 blob 费用（wei）
+func (f *BlobFee) Cost(blobs int) *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(uint64(blobs)*params.BlobTxBlobGasPerBlob), f.BaseFee)
+}
+
+// BlobTxRequest 待发送的 blob 交易，零值字段由 SignBlobTx 从节点补全
+type BlobTxRequest struct {
+	To         common.Address // blob 交易不能创建合约
+	Value      *big.Int
+	Data       []byte
+	Sidecar    *types.BlobTxSidecar // 由 NewBlobSidecar 生成
+	GasLimit   uint64               // 为 0 时使用 EstimateGas 的结果加余量
+	Nonce      *uint64              // 为 nil 时使用 pending nonce
+	BlobFeeCap *big.Int             // maxFeePerBlobGas，为 nil 时使用 EstimateBlobFee 的 FeeCap
+}
+
+// BuildBlobTx 补全 nonce、Gas 上限、执行费用和 blob 费用，返回未签名的 blob 交易。
+// 执行部分的费用与 BuildTx 的 EIP-1559 交易相同。目标网络已启用 Osaka 时，
+// 把版本 0 的 sidecar 转换为带单元证明的版本 1（每个 blob 需要约 100 毫秒）
+func BuildBlobTx(ctx context.Context, s TransactionSender, from common.Address, chainID *big.Int, req *BlobTxRequest) (*types.Transaction, error) {
+	if req.Sidecar == nil || len(req.Sidecar.Blobs) == 0 {
+		return nil, fmt.Errorf("blob 交易至少需要一个 blob")
+	}
+	base, err := BuildTx(ctx, s, from, chainID, &TxRequest{To: &req.To, Value: req.Value, Data: req.Data, GasLimit: req.GasLimit, Nonce: req.Nonce})
+	if err != nil {
+		return nil, err
+	}
+	if base.Type() != types.DynamicFeeTxType {
+		return nil, ErrNoBlobSupport
+	}
+	blobFeeCap := req.BlobFeeCap
+	if blobFeeCap == nil {
+		fee, err := EstimateBlobFee(ctx, s)
+		if err != nil {
+			return nil, err
+		}
+		blobFeeCap = fee.FeeCap
+	}
+	sidecar, err := sidecarFor(ctx, s, chainID, req.Sidecar)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewTx(&types.BlobTx{
+		ChainID:    uint256.MustFromBig(chainID),
+		Nonce:      base.Nonce(),
+		GasTipCap:  uint256.MustFromBig(base.GasTipCap()),
+		GasFeeCap:  uint256.MustFromBig(base.GasFeeCap()),
+		Gas:        base.Gas(),
+		To:         req.To,
+		Value:      uint256.MustFromBig(base.Value()),
+		Data:       req.Data,
+		BlobFeeCap: uint256.MustFromBig(blobFeeCap),
+		BlobHashes: sidecar.BlobHashes(),
+		Sidecar:    sidecar,
+	}), nil
+}
+
+// sidecarFor 按最新区块是否已进入 Osaka 选择 sidecar 版本，不修改传入的 sidecar。
+// 未知网络保持原样，由节点决定是否接受
+func sidecarFor(ctx context.Context, r HeaderReader, chainID *big.Int, sidecar *types.BlobTxSidecar) (*types.BlobTxSidecar, error) {
+	config := ChainConfig(chainID)
+	if config == nil || sidecar.Version != types.BlobSidecarVersion0 {
+		return sidecar, nil
+	}
+	head, err := r.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取最新区块头失败: %w", err)
+	}
+	if !config.IsOsaka(head.Number, head.Time) {
+		return sidecar, nil
+	}
+	converted := sidecar.Copy()
+	if err := converted.ToV1(); err != nil {
+		return nil, fmt.Errorf("计算 KZG 单元证明失败: %w", err)
+	}
+	return converted, nil
+}
+
+// SignBlobTx 补全并签名 blob 交易，但不发送。签名交易带有 sidecar，可以直接广播
+func SignBlobTx(ctx context.Context, s TransactionSender, key *ecdsa.PrivateKey, req *BlobTxRequest) (*types.Transaction, error) {
+	chainID, err := s.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链 ID 失败: %w", err)
+	}
+	tx, err := BuildBlobTx(ctx, s, crypto.PubkeyToAddress(key.PublicKey), chainID, req)
+	if err != nil {
+		return nil, err
+	}
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), key)
+}
+
+// SendBlobTx 补全、签名并发送 blob 交易，不等待确认
+func SendBlobTx(ctx context.Context, s TransactionSender, key *ecdsa.PrivateKey, req *BlobTxRequest) (*types.Transaction, error) {
+	signed, err := SignBlobTx(ctx, s, key, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.SendTransaction(ctx, signed); err != nil {
+		return nil, fmt.Errorf("发送交易失败: %w", err)
+	}
+	return signed, nil
+}
@@ -0,0 +1,184 @@
+package chain_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+)
+
+// blobData 把 blob 中每个字段元素的低 31 字节拼接起来，是 EncodeBlobs 的逆过程
+func blobData(blobs []kzg4844.Blob) (data []byte, ok bool) {
+	for _, blob := range blobs {
+		for j := 0; j < len(blob); j += 32 {
+			if blob[j] != 0 {
+				return nil, false // 首字节必须为 0，否则可能超过模数
+			}
+			data = append(data, blob[j+1:j+32]...)
+		}
+	}
+	return data, true
+}
+
+func TestEncodeBlobs(t *testing.T) {
+	tests := []struct {
+		name  string
+		size  int
+		blobs int
+	}{
+		{"empty", 0, 1},
+		{"one byte", 1, 1},
+		{"full blob", chain.BlobDataSize, 1},
+		{"one byte over", chain.BlobDataSize + 1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := make([]byte, tt.size)
+			for i := range data {
+				data[i] = byte(i%251 + 1) // 不含 0，补齐的部分一眼可以分辨
+			}
+			blobs := chain.EncodeBlobs(data)
+			if len(blobs) != tt.blobs {
+				t.Fatalf("%d 字节编码为 %d 个 blob，期望 %d", tt.size, len(blobs), tt.blobs)
+			}
+			decoded, ok := blobData(blobs)
+			if !ok {
+				t.Fatal("字段元素的首字节不为 0")
+			}
+			if len(decoded) != tt.blobs*chain.BlobDataSize {
+				t.Fatalf("解出 %d 字节", len(decoded))
+			}
+			for i, b := range decoded {
+				want := byte(0)
+				if i < tt.size {
+					want = data[i]
+				}
+				if b != want {
+					t.Fatalf("第 %d 字节为 %#x，期望 %#x", i, b, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBlobSidecar(t *testing.T) {
+	blobs := chain.EncodeBlobs(make([]byte, chain.BlobDataSize+1))
+	blobs[1][1] = 0x2a
+	v0, err := chain.NewBlobSidecar(blobs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v0.Version != types.BlobSidecarVersion0 || len(v0.Proofs) != len(blobs) {
+		t.Fatalf("sidecar 版本 %d，%d 个证明", v0.Version, len(v0.Proofs))
+	}
+	hashes := v0.BlobHashes()
+	if err := chain.VerifyBlobSidecar(v0, hashes); err != nil {
+		t.Fatalf("版本 0: %v", err)
+	}
+
+	// Osaka 之后的单元证明
+	v1 := v0.Copy()
+	if err := v1.ToV1(); err != nil {
+		t.Fatal(err)
+	}
+	if len(v1.Proofs) != len(blobs)*kzg4844.CellProofsPerBlob {
+		t.Fatalf("版本 1 有 %d 个证明", len(v1.Proofs))
+	}
+	if err := chain.VerifyBlobSidecar(v1, hashes); err != nil {
+		t.Fatalf("版本 1: %v", err)
+	}
+
+	// 把第一个承诺换成第二个 blob 的承诺：与版本化哈希不匹配
+	tampered := v0.Copy()
+	tampered.Commitments[0] = tampered.Commitments[1]
+	if err := chain.VerifyBlobSidecar(tampered, hashes); err == nil {
+		t.Fatal("篡改承诺后应验证失败")
+	}
+	// 哈希也随之更新时，证明与承诺不匹配
+	if err := chain.VerifyBlobSidecar(tampered, tampered.BlobHashes()); err == nil {
+		t.Fatal("承诺与证明不匹配时应验证失败")
+	}
+	// 数量不一致
+	if err := chain.VerifyBlobSidecar(v0, hashes[:1]); err == nil {
+		t.Fatal("哈希数量不一致时应验证失败")
+	}
+
+	if _, err := chain.NewBlobSidecar(nil); err == nil {
+		t.Fatal("没有 blob 时应返回错误")
+	}
+	if _, err := chain.NewBlobSidecar(make([]kzg4844.Blob, chain.MaxBlobsPerTx+1)); err == nil {
+		t.Fatal("超过每笔交易的 blob 上限时应返回错误")
+	}
+}
+
+// blobNode 返回指定区块头的节点
+type blobNode struct {
+	*fakeNode
+	head *types.Header
+}
+
+func (n *blobNode) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return n.head, nil
+}
+
+func TestEstimateBlobFee(t *testing.T) {
+	config := params.SepoliaChainConfig
+	cancun := *config.CancunTime + 120 // Prague 之前：每个区块目标 3 个 blob
+	header := func(excess, used uint64) *types.Header {
+		return &types.Header{Number: big.NewInt(6_000_000), Time: cancun, BaseFee: big.NewInt(1e9), ExcessBlobGas: &excess, BlobGasUsed: &used}
+	}
+	node := &blobNode{fakeNode: newFakeNode()}
+	ctx := context.Background()
+
+	// 没有积压、没有使用：价格为最低的 1 wei
+	node.head = header(0, 0)
+	fee, err := chain.EstimateBlobFee(ctx, node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fee.Head != 6_000_000 || fee.ExcessBlobGas != 0 || fee.BaseFee.Int64() != 1 || fee.FeeCap.Int64() != 2 {
+		t.Fatalf("EstimateBlobFee = %+v", fee)
+	}
+	if want := int64(2 * params.BlobTxBlobGasPerBlob); fee.Cost(2).Int64() != want {
+		t.Fatalf("Cost(2) = %s，期望 %d", fee.Cost(2), want)
+	}
+
+	// 最新区块用满 6 个 blob，超出目标的部分累积到下一个区块，价格上涨
+	excess := 3 * params.DefaultCancunBlobConfig.UpdateFraction
+	node.head = header(excess, 6*params.BlobTxBlobGasPerBlob)
+	current := chain.BlobGasPrice(config, node.head)
+	if fee, err = chain.EstimateBlobFee(ctx, node); err != nil {
+		t.Fatal(err)
+	}
+	if want := excess + 3*params.BlobTxBlobGasPerBlob; fee.ExcessBlobGas != want {
+		t.Fatalf("ExcessBlobGas = %d，期望 %d", fee.ExcessBlobGas, want)
+	}
+	if fee.BaseFee.Cmp(current) <= 0 || fee.FeeCap.Cmp(new(big.Int).Mul(fee.BaseFee, big.NewInt(2))) != 0 {
+		t.Fatalf("下一个区块价格 %s（当前 %s），FeeCap %s", fee.BaseFee, current, fee.FeeCap)
+	}
+
+	// 区块头没有 excessBlobGas（节点未启用 Cancun）
+	node.head = &types.Header{Number: big.NewInt(6_000_000), Time: cancun}
+	if _, err := chain.EstimateBlobFee(ctx, node); !errors.Is(err, chain.ErrNoBlobSupport) {
+		t.Fatalf("没有 excessBlobGas 时返回 %v，期望 %v", err, chain.ErrNoBlobSupport)
+	}
+	if chain.BlobGasPrice(config, node.head) != nil {
+		t.Fatal("没有 excessBlobGas 的区块没有 blob gas 价格")
+	}
+
+	// 未知网络
+	node.chainID = big.NewInt(31337)
+	node.head = header(0, 0)
+	if _, err := chain.EstimateBlobFee(ctx, node); !errors.Is(err, chain.ErrUnknownBlobParams) {
+		t.Fatalf("未知网络返回 %v，期望 %v", err, chain.ErrUnknownBlobParams)
+	}
+	if chain.BlobGasPrice(nil, node.head) != nil {
+		t.Fatal("未知网络的 blob gas 价格应为 nil")
+	}
+}
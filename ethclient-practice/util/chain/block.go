@@ -0,0 +1,118 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Block 区块摘要
+type Block struct {
+	Number       uint64
+	Hash         common.Hash
+	ParentHash   common.Hash
+	Time         time.Time
+	Miner        common.Address
+	GasUsed      uint64
+	GasLimit     uint64
+	BaseFee      *big.Int // London 之前的区块为 nil
+	Transactions []*Tx
+
+	BlobGasUsed   *uint64  // Cancun 之前的区块为 nil
+	ExcessBlobGas *uint64  // Cancun 之前的区块为 nil
+	BlobGasPrice  *big.Int // 每单位 blob gas 的价格，Cancun 之前的区块或未知网络为 nil
+}
+
+// NewBlock 从区块中提取摘要。chainID 用于恢复每笔交易的发送者，
+// 并选择计算 blob gas 价格的分叉参数（见 ChainConfig）
+func NewBlock(block *types.Block, chainID *big.Int) (*Block, error) {
+	signer := types.LatestSignerForChainID(chainID)
+	b := &Block{
+		Number:     block.NumberU64(),
+		Hash:       block.Hash(),
+		ParentHash: block.ParentHash(),
+		Time:       time.Unix(int64(block.Time()), 0).UTC(),
+		Miner:      block.Coinbase(),
+		GasUsed:    block.GasUsed(),
+		GasLimit:   block.GasLimit(),
+		BaseFee:    block.BaseFee(),
+
+		BlobGasUsed:   block.BlobGasUsed(),
+		ExcessBlobGas: block.ExcessBlobGas(),
+		BlobGasPrice:  BlobGasPrice(ChainConfig(chainID), block.Header()),
+	}
+	for _, tx := range block.Transactions() {
+		info, err := NewTx(tx, signer)
+		if err != nil {
+			return nil, err
+		}
+		b.Transactions = append(b.Transactions, info)
+	}
+	return b, nil
+}
+
+// GetBlock 查询区块及其交易，number 为 nil 时查询最新区块
+func GetBlock(ctx context.Context, r ChainReader, number *big.Int) (*Block, error) {
+	block, err := r.BlockByNumber(ctx, number)
+	if err != nil {
+		if number == nil {
+			return nil, fmt.Errorf("查询最新区块失败: %w", err)
+		}
+		return nil, fmt.Errorf("查询区块 %s 失败: %w", number, err)
+	}
+	chainID, err := r.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链 ID 失败: %w", err)
+	}
+	return NewBlock(block, chainID)
+}
+
+// GasUsage Gas 使用率（百分比）
+func (b *Block) GasUsage() float64 {
+	if b.GasLimit == 0 {
+		return 0
+	}
+	return float64(b.GasUsed) / float64(b.GasLimit) * 100
+}
+
+// TxGas 区块内交易的 Gas 上限之和
+func (b *Block) TxGas() uint64 {
+	var total uint64
+	for _, tx := range b.Transactions {
+		total += tx.Gas
+	}
+	return total
+}
+
+// AvgGasPrice 区块内交易的平均 Gas 价格（EIP-1559 交易按 GasFeeCap 计），没有交易时为 0
+func (b *Block) AvgGasPrice() *big.Int {
+	total := new(big.Int)
+	if len(b.Transactions) == 0 {
+		return total
+	}
+	for _, tx := range b.Transactions {
+		total.Add(total, tx.GasPrice)
+	}
+	return total.Div(total, big.NewInt(int64(len(b.Transactions))))
+}
+
+// BlobCount 区块内所有交易携带的 blob 数量
+func (b *Block) BlobCount() int {
+	var n int
+	for _, tx := range b.Transactions {
+		n += tx.BlobCount()
+	}
+	return n
+}
+
+// BlobFees 区块内 blob 费用之和（wei），Cancun 之前的区块或未知网络为 nil
+func (b *Block) BlobFees() *big.Int {
+	if b.BlobGasPrice == nil || b.BlobGasUsed == nil {
+		return nil
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(*b.BlobGasUsed), b.BlobGasPrice)
+}
@@ -0,0 +1,93 @@
+// Package chain 把课程中查询区块、交易、收据、余额以及发送 ETH / ERC20 转账的
+// 核心逻辑提取为可复用的函数。
+//
+// 函数只依赖下面几个很小的接口，返回结构化的结果而不是直接打印，
+// *ethclient.Client、simnet 的模拟客户端或自己实现的假客户端都可以传入：
+//
+//	ChainReader        查询区块、交易、收据、余额
+//	TransactionSender  构造、签名并发送交易
+//	ContractCaller     调用合约的只读方法
+//
+// 各课程的 main 只负责读取参数、调用这里的函数并打印结果。
+package chain
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// ReceiptReader 查询交易收据
+type ReceiptReader interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// HeaderReader 查询区块头
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// ChainReader 只读查询需要的节点接口
+type ChainReader interface {
+	ReceiptReader
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error)
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// TransactionSender 构造并发送交易需要的节点接口
+type TransactionSender interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// ContractCaller 调用合约只读方法需要的节点接口
+type ContractCaller interface {
+	CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// CodeReader 查询账户代码和 nonce 需要的节点接口
+type CodeReader interface {
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+}
+
+// signerFor 查询链 ID 并返回支持所有交易类型的签名器，用于恢复发送者地址
+func signerFor(ctx context.Context, r ChainReader) (types.Signer, error) {
+	chainID, err := r.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return types.LatestSignerForChainID(chainID), nil
+}
+
+// ChainConfig 已知网络的分叉配置：主网、Sepolia、Holesky、Hoodi 和开发链（1337，geth --dev 与 simnet），
+// 其他网络返回 nil。blob gas 价格、单个区块的 blob 上限等参数随分叉变化，需要按区块时间戳从配置中选择
+func ChainConfig(chainID *big.Int) *params.ChainConfig {
+	if chainID == nil {
+		return nil
+	}
+	for _, config := range []*params.ChainConfig{
+		params.MainnetChainConfig,
+		params.SepoliaChainConfig,
+		params.HoleskyChainConfig,
+		params.HoodiChainConfig,
+		params.AllDevChainProtocolChanges,
+	} {
+		if config.ChainID.Cmp(chainID) == 0 {
+			return config
+		}
+	}
+	return nil
+}
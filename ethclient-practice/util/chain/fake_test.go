@@ -0,0 +1,120 @@
+package chain_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var errFake = errors.New("fake: 节点错误")
+
+// fakeNode 内存中的假节点，实现 ChainReader、TransactionSender 和 ContractCaller，
+// 字段决定各方法的返回值，并记录收到的调用
+type fakeNode struct {
+	chainID  *big.Int
+	baseFee  *big.Int // 为 nil 时模拟不支持 EIP-1559 的节点
+	gasPrice *big.Int
+	tip      *big.Int
+	nonce    uint64
+	estimate uint64
+	estErr   error
+
+	balances map[common.Address]*big.Int
+	receipts map[common.Hash]*types.Receipt
+	blocks   map[uint64]*types.Block
+	txs      map[common.Hash]*types.Transaction
+
+	// receiptAfter 前若干次 TransactionReceipt 返回 NotFound，模拟尚未打包
+	receiptAfter int
+	// calls 按方法选择器返回 eth_call 的结果，没有登记的选择器返回 errFake
+	calls map[[4]byte][]byte
+
+	sent      []*types.Transaction
+	estimated []ethereum.CallMsg
+}
+
+func newFakeNode() *fakeNode {
+	return &fakeNode{
+		chainID:  big.NewInt(11155111),
+		baseFee:  big.NewInt(10e9),
+		gasPrice: big.NewInt(25e9),
+		tip:      big.NewInt(2e9),
+		nonce:    7,
+		estimate: 50000,
+		balances: make(map[common.Address]*big.Int),
+		receipts: make(map[common.Hash]*types.Receipt),
+		blocks:   make(map[uint64]*types.Block),
+		txs:      make(map[common.Hash]*types.Transaction),
+		calls:    make(map[[4]byte][]byte),
+	}
+}
+
+func (f *fakeNode) ChainID(ctx context.Context) (*big.Int, error) { return f.chainID, nil }
+
+func (f *fakeNode) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{Number: big.NewInt(100), BaseFee: f.baseFee}, nil
+}
+
+func (f *fakeNode) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return f.nonce, nil
+}
+
+func (f *fakeNode) SuggestGasPrice(ctx context.Context) (*big.Int, error) { return f.gasPrice, nil }
+
+func (f *fakeNode) SuggestGasTipCap(ctx context.Context) (*big.Int, error) { return f.tip, nil }
+
+func (f *fakeNode) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	f.estimated = append(f.estimated, call)
+	return f.estimate, f.estErr
+}
+
+func (f *fakeNode) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	f.sent = append(f.sent, tx)
+	return nil
+}
+
+func (f *fakeNode) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	if f.receiptAfter > 0 {
+		f.receiptAfter--
+		return nil, ethereum.NotFound
+	}
+	if r, ok := f.receipts[hash]; ok {
+		return r, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (f *fakeNode) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	if b, ok := f.blocks[number.Uint64()]; ok {
+		return b, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (f *fakeNode) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	if tx, ok := f.txs[hash]; ok {
+		_, mined := f.receipts[hash]
+		return tx, !mined, nil
+	}
+	return nil, false, ethereum.NotFound
+}
+
+func (f *fakeNode) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	if b, ok := f.balances[account]; ok {
+		return b, nil
+	}
+	return nil, errFake
+}
+
+func (f *fakeNode) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	var selector [4]byte
+	copy(selector[:], call.Data)
+	if out, ok := f.calls[selector]; ok {
+		return out, nil
+	}
+	return nil, errFake
+}
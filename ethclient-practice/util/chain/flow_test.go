@@ -0,0 +1,222 @@
+package chain_test
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/simnet"
+	"github.com/dapp-learning/ethclient/util/simnet/contracts"
+)
+
+var ether = big.NewInt(1e18)
+
+func newNet(t *testing.T) *simnet.Net {
+	t.Helper()
+	net, err := simnet.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { net.Close() })
+	return net
+}
+
+// mine 出块并返回交易的收据
+func mine(t *testing.T, net *simnet.Net, tx *types.Transaction) *chain.Receipt {
+	t.Helper()
+	net.Commit()
+	receipt, err := chain.GetReceipt(context.Background(), net.Client, tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !receipt.Success() {
+		t.Fatalf("交易 %s 执行失败", tx.Hash().Hex())
+	}
+	return receipt
+}
+
+func TestTransferAndQuery(t *testing.T) {
+	net := newNet(t)
+	ctx := context.Background()
+	alice, bob := net.Accounts[0], net.Accounts[1]
+
+	before, err := chain.GetBalance(ctx, net.Client, alice.Address, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := chain.TransferETH(ctx, net.Client, alice.Key, bob.Address, ether)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt := mine(t, net, tx)
+
+	// 交易
+	info, err := chain.GetTx(ctx, net.Client, tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Pending || info.From != alice.Address || *info.To != bob.Address || info.Value.Cmp(ether) != 0 || !info.IsDynamicFee() {
+		t.Fatalf("交易摘要不符: %+v", info)
+	}
+
+	// 区块：时间戳由 simnet 固定，交易在区块中
+	block, err := chain.GetBlock(ctx, net.Client, new(big.Int).SetUint64(receipt.BlockNumber))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantTime := time.Unix(int64(simnet.GenesisTime+receipt.BlockNumber*simnet.BlockTime), 0).UTC()
+	if block.Hash != receipt.BlockHash || !block.Time.Equal(wantTime) || block.BaseFee == nil {
+		t.Fatalf("区块摘要不符: hash=%s time=%s baseFee=%v", block.Hash.Hex(), block.Time, block.BaseFee)
+	}
+	if len(block.Transactions) != 1 || block.Transactions[0].Hash != tx.Hash() || block.Transactions[0].From != alice.Address {
+		t.Fatalf("区块 %d 的交易列表不符", block.Number)
+	}
+
+	// 收据与余额：alice 付出转账金额加手续费
+	if receipt.GasUsed != 21000 || receipt.ContractAddress != nil || receipt.Fee() == nil {
+		t.Fatalf("收据不符: %+v", receipt)
+	}
+	if receipt.EffectiveGasPrice.Cmp(new(big.Int).Add(block.BaseFee, info.GasTipCap)) > 0 {
+		t.Fatalf("有效价格 %s 超过 baseFee + 小费", receipt.EffectiveGasPrice)
+	}
+	after, err := chain.GetBalance(ctx, net.Client, alice.Address, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := new(big.Int).Sub(before, new(big.Int).Add(ether, receipt.Fee())); after.Cmp(want) != 0 {
+		t.Fatalf("alice 余额 %s，期望 %s", after, want)
+	}
+
+	// WaitReceipt 对已打包的交易立即返回
+	waited, err := chain.WaitReceipt(ctx, net.Client, tx.Hash(), time.Millisecond)
+	if err != nil || waited.TxHash != tx.Hash() {
+		t.Fatalf("WaitReceipt: %v", err)
+	}
+}
+
+func TestDeployAndCall(t *testing.T) {
+	net := newNet(t)
+	ctx := context.Background()
+	storeABI, err := abi.JSON(strings.NewReader(contracts.StoreABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := net.Accounts[2]
+
+	// To 为 nil 是合约创建交易，Gas 上限由 EstimateGas 加余量得到
+	data, err := contracts.StoreDeployData("chain-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	estimated, err := net.Client.EstimateGas(ctx, ethereum.CallMsg{From: owner.Address, Data: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := chain.Send(ctx, net.Client, owner.Key, &chain.TxRequest{Data: data})
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt := mine(t, net, tx)
+	if receipt.ContractAddress == nil {
+		t.Fatal("部署交易的收据没有合约地址")
+	}
+	store := *receipt.ContractAddress
+	info, err := chain.GetTx(ctx, net.Client, tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.IsContractCreation() || info.Gas != chain.WithMargin(estimated, 0) || receipt.GasUsed > info.Gas {
+		t.Fatalf("部署交易 Gas 上限 %d，估算 %d，实际使用 %d", info.Gas, estimated, receipt.GasUsed)
+	}
+
+	// 写入再读取
+	key, value := common.HexToHash("0x2a"), common.HexToHash("0x1234")
+	input, _ := storeABI.Pack("setItem", key, value)
+	tx, err = chain.Send(ctx, net.Client, owner.Key, &chain.TxRequest{To: &store, Data: input})
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt = mine(t, net, tx)
+	if len(receipt.Logs) != 1 || receipt.Logs[0].Topics[0] != storeABI.Events["ItemSet"].ID {
+		t.Fatalf("setItem 没有产生 ItemSet 日志")
+	}
+
+	input, _ = storeABI.Pack("getItem", key)
+	out, err := net.Client.CallContract(ctx, ethereum.CallMsg{To: &store, Data: input}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if common.BytesToHash(out) != value {
+		t.Fatalf("getItem = %x，期望 %s", out, value.Hex())
+	}
+}
+
+func TestTokenFlow(t *testing.T) {
+	net := newNet(t)
+	ctx := context.Background()
+	alice, bob, carol := net.Accounts[0], net.Accounts[1], net.Accounts[2]
+
+	token, err := chain.GetToken(ctx, net.Client, net.Token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Name != contracts.TokenName || token.Symbol != contracts.TokenSymbol || token.Decimals != contracts.TokenDecimals {
+		t.Fatalf("代币信息不符: %+v", token)
+	}
+
+	// transfer
+	amount := new(big.Int).Mul(big.NewInt(100), ether)
+	tx, err := chain.TransferToken(ctx, net.Client, alice.Key, net.Token, bob.Address, amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mine(t, net, tx)
+	checkTokenBalance(t, net, bob.Address, amount)
+
+	// approve + transferFrom：carol 从 bob 账户转走 40
+	allowance := new(big.Int).Mul(big.NewInt(60), ether)
+	tx, err = chain.ApproveToken(ctx, net.Client, bob.Key, net.Token, carol.Address, allowance)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mine(t, net, tx)
+	if got, err := chain.TokenAllowance(ctx, net.Client, net.Token, bob.Address, carol.Address); err != nil || got.Cmp(allowance) != 0 {
+		t.Fatalf("allowance = %v (%v)，期望 %s", got, err, allowance)
+	}
+
+	pulled := new(big.Int).Mul(big.NewInt(40), ether)
+	tx, err = chain.TransferTokenFrom(ctx, net.Client, carol.Key, net.Token, bob.Address, carol.Address, pulled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mine(t, net, tx)
+	checkTokenBalance(t, net, bob.Address, new(big.Int).Sub(amount, pulled))
+	checkTokenBalance(t, net, carol.Address, pulled)
+	if got, _ := chain.TokenAllowance(ctx, net.Client, net.Token, bob.Address, carol.Address); got.Cmp(new(big.Int).Sub(allowance, pulled)) != 0 {
+		t.Fatalf("transferFrom 后 allowance = %s", got)
+	}
+
+	// 超出额度时估算 Gas 失败，交易不会发出
+	if _, err := chain.TransferTokenFrom(ctx, net.Client, carol.Key, net.Token, bob.Address, carol.Address, allowance); err == nil || !strings.Contains(err.Error(), contracts.ErrInsufficientAllowance) {
+		t.Fatalf("超出额度应返回 %q，实际: %v", contracts.ErrInsufficientAllowance, err)
+	}
+}
+
+func checkTokenBalance(t *testing.T, net *simnet.Net, owner common.Address, want *big.Int) {
+	t.Helper()
+	got, err := chain.TokenBalance(context.Background(), net.Client, net.Token, owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Cmp(want) != 0 {
+		t.Fatalf("%s 代币余额 %s，期望 %s", owner.Hex(), got, want)
+	}
+}
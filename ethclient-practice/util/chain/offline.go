@@ -0,0 +1,431 @@
+package chain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/dapp-learning/ethclient/util/output"
+	"github.com/dapp-learning/ethclient/util/sigdb"
+)
+
+// BundleVersion 交易包文件格式的版本
+const BundleVersion = 1
+
+var (
+	// ErrWrongSigner 签名私钥与交易包的 from 不一致
+	ErrWrongSigner = errors.New("签名私钥与交易包的发送方不一致")
+	// ErrWrongChain 交易包的链 ID 与节点不一致
+	ErrWrongChain = errors.New("交易包的链 ID 与节点不一致")
+	// ErrNonceUsed 交易的 nonce 已被另一笔交易使用，这笔交易永远不会被打包
+	ErrNonceUsed = errors.New("nonce 已被其他交易使用")
+)
+
+// UnsignedTx 一笔待签名交易的全部字段，字段名与 eth_signTransaction 一致。
+// 签名端只依赖这些字段，不需要访问网络
+type UnsignedTx struct {
+	Type       hexutil.Uint64   `json:"type"`
+	Nonce      hexutil.Uint64   `json:"nonce"`
+	To         *common.Address  `json:"to"`
+	Value      *hexutil.Big     `json:"value"`
+	Gas        hexutil.Uint64   `json:"gas"`
+	GasPrice   *hexutil.Big     `json:"gasPrice,omitempty"`             // legacy / EIP-2930 交易
+	GasTipCap  *hexutil.Big     `json:"maxPriorityFeePerGas,omitempty"` // EIP-1559 交易
+	GasFeeCap  *hexutil.Big     `json:"maxFeePerGas,omitempty"`         // EIP-1559 交易
+	Data       hexutil.Bytes    `json:"input,omitempty"`
+	AccessList types.AccessList `json:"accessList,omitempty"` // EIP-2930 / EIP-1559 交易
+	Note       string           `json:"note,omitempty"`       // 给签名人核对的说明，不参与签名
+}
+
+// UnsignedBundle 在线端导出的待签名交易包。同一个发送方，nonce 连续
+type UnsignedBundle struct {
+	Version   int            `json:"version"`
+	ChainID   *hexutil.Big   `json:"chainId"`
+	From      common.Address `json:"from"`
+	CreatedAt time.Time      `json:"createdAt"`
+	Txs       []UnsignedTx   `json:"transactions"`
+}
+
+// SignedTx 一笔签名交易。Raw 即 eth_sendRawTransaction 的参数
+type SignedTx struct {
+	Hash  common.Hash    `json:"hash"`
+	Nonce hexutil.Uint64 `json:"nonce"`
+	Raw   hexutil.Bytes  `json:"raw"`
+	Note  string         `json:"note,omitempty"`
+}
+
+// SignedBundle 离线端输出的签名交易包，广播端只使用其中的 Raw
+type SignedBundle struct {
+	Version  int            `json:"version"`
+	ChainID  *hexutil.Big   `json:"chainId"`
+	From     common.Address `json:"from"`
+	SignedAt time.Time      `json:"signedAt"`
+	Txs      []SignedTx     `json:"transactions"`
+}
+
+// Broadcaster 广播签名交易并跟踪状态需要的节点接口
+type Broadcaster interface {
+	ReceiptReader
+	TransactionByHash(ctx context.Context, hash common.Hash) (tx *types.Transaction, isPending bool, err error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// NewUnsignedTx 从未签名的交易生成交易包中的一项
+func NewUnsignedTx(tx *types.Transaction, note string) UnsignedTx {
+	u := UnsignedTx{
+		Type:  hexutil.Uint64(tx.Type()),
+		Nonce: hexutil.Uint64(tx.Nonce()),
+		To:    tx.To(),
+		Value: (*hexutil.Big)(tx.Value()),
+		Gas:   hexutil.Uint64(tx.Gas()),
+		Data:  tx.Data(),
+		Note:  note,
+	}
+	if tx.Type() != types.LegacyTxType {
+		u.AccessList = tx.AccessList()
+	}
+	if tx.Type() == types.LegacyTxType || tx.Type() == types.AccessListTxType {
+		u.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	} else {
+		u.GasTipCap = (*hexutil.Big)(tx.GasTipCap())
+		u.GasFeeCap = (*hexutil.Big)(tx.GasFeeCap())
+	}
+	return u
+}
+
+// Transaction 还原为未签名的交易，缺少必需字段时返回错误
+func (u *UnsignedTx) Transaction(chainID *big.Int) (*types.Transaction, error) {
+	value := new(big.Int)
+	if u.Value != nil {
+		value = u.Value.ToInt()
+	}
+	if u.Gas == 0 {
+		return nil, fmt.Errorf("nonce %d: 缺少 gas", u.Nonce)
+	}
+	switch uint8(u.Type) {
+	case types.LegacyTxType:
+		if u.GasPrice == nil {
+			return nil, fmt.Errorf("nonce %d: legacy 交易缺少 gasPrice", u.Nonce)
+		}
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    uint64(u.Nonce),
+			GasPrice: u.GasPrice.ToInt(),
+			Gas:      uint64(u.Gas),
+			To:       u.To,
+			Value:    value,
+			Data:     u.Data,
+		}), nil
+	case types.AccessListTxType:
+		if u.GasPrice == nil {
+			return nil, fmt.Errorf("nonce %d: EIP-2930 交易缺少 gasPrice", u.Nonce)
+		}
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    chainID,
+			Nonce:      uint64(u.Nonce),
+			GasPrice:   u.GasPrice.ToInt(),
+			Gas:        uint64(u.Gas),
+			To:         u.To,
+			Value:      value,
+			Data:       u.Data,
+			AccessList: u.AccessList,
+		}), nil
+	case types.DynamicFeeTxType:
+		if u.GasTipCap == nil || u.GasFeeCap == nil {
+			return nil, fmt.Errorf("nonce %d: EIP-1559 交易缺少 maxPriorityFeePerGas / maxFeePerGas", u.Nonce)
+		}
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:    chainID,
+			Nonce:      uint64(u.Nonce),
+			GasTipCap:  u.GasTipCap.ToInt(),
+			GasFeeCap:  u.GasFeeCap.ToInt(),
+			Gas:        uint64(u.Gas),
+			To:         u.To,
+			Value:      value,
+			Data:       u.Data,
+			AccessList: u.AccessList,
+		}), nil
+	default:
+		return nil, fmt.Errorf("nonce %d: 不支持的交易类型 %d", u.Nonce, u.Type)
+	}
+}
+
+// PrepareBundle 在线端：为 from 依次补全 reqs 的 nonce、Gas 上限和费用，生成待签名交易包。
+// 不需要私钥。没有指定 nonce 的请求从 pending nonce 开始连续编号
+func PrepareBundle(ctx context.Context, s TransactionSender, from common.Address, reqs []*TxRequest) (*UnsignedBundle, error) {
+	chainID, err := s.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链 ID 失败: %w", err)
+	}
+	nonce, err := s.PendingNonceAt(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("获取 nonce 失败: %w", err)
+	}
+
+	bundle := &UnsignedBundle{
+		Version:   BundleVersion,
+		ChainID:   (*hexutil.Big)(chainID),
+		From:      from,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	for i, req := range reqs {
+		build := *req
+		if build.Nonce == nil {
+			n := nonce
+			build.Nonce = &n
+			nonce++
+		}
+		tx, err := BuildTx(ctx, s, from, chainID, &build)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 笔交易: %w", i+1, err)
+		}
+		bundle.Txs = append(bundle.Txs, NewUnsignedTx(tx, ""))
+	}
+	return bundle, nil
+}
+
+// Sign 离线端：用 key 签名交易包中的全部交易，不访问网络。
+// key 的地址必须与交易包的 from 一致
+func (b *UnsignedBundle) Sign(key *ecdsa.PrivateKey) (*SignedBundle, error) {
+	if err := b.check(); err != nil {
+		return nil, err
+	}
+	if addr := crypto.PubkeyToAddress(key.PublicKey); addr != b.From {
+		return nil, fmt.Errorf("%w: 私钥地址 %s，交易包 %s", ErrWrongSigner, addr.Hex(), b.From.Hex())
+	}
+	chainID := b.ChainID.ToInt()
+	signer := types.LatestSignerForChainID(chainID)
+	signed := &SignedBundle{
+		Version:  BundleVersion,
+		ChainID:  b.ChainID,
+		From:     b.From,
+		SignedAt: time.Now().UTC().Truncate(time.Second),
+	}
+	for i := range b.Txs {
+		tx, err := b.Txs[i].Transaction(chainID)
+		if err != nil {
+			return nil, err
+		}
+		if tx, err = types.SignTx(tx, signer, key); err != nil {
+			return nil, err
+		}
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		signed.Txs = append(signed.Txs, SignedTx{Hash: tx.Hash(), Nonce: b.Txs[i].Nonce, Raw: raw, Note: b.Txs[i].Note})
+	}
+	return signed, nil
+}
+
+func (b *UnsignedBundle) check() error {
+	if b.Version != BundleVersion {
+		return fmt.Errorf("不支持的交易包版本 %d", b.Version)
+	}
+	if b.ChainID == nil || b.ChainID.ToInt().Sign() <= 0 {
+		return errors.New("交易包缺少 chainId")
+	}
+	if len(b.Txs) == 0 {
+		return errors.New("交易包中没有交易")
+	}
+	return nil
+}
+
+// MaxCost 全部交易最多花费的 ETH：金额 + Gas 上限 × 最高 Gas 价格
+func (b *UnsignedBundle) MaxCost() (*big.Int, error) {
+	total := new(big.Int)
+	for i := range b.Txs {
+		tx, err := b.Txs[i].Transaction(b.ChainID.ToInt())
+		if err != nil {
+			return nil, err
+		}
+		total.Add(total, tx.Cost())
+	}
+	return total, nil
+}
+
+// WriteSummary 打印交易包内容供签名人核对：每笔交易的接收方、金额、
+// 按 reg 解码的调用和费用，以及总的最多花费。reg 为 nil 时使用 sigdb.NewDefault()
+func (b *UnsignedBundle) WriteSummary(w io.Writer, reg *sigdb.Registry) error {
+	if err := b.check(); err != nil {
+		return err
+	}
+	if reg == nil {
+		reg = sigdb.NewDefault()
+	}
+	t := output.NewTable()
+	t.Add("链 ID:", b.ChainID.ToInt().String())
+	t.Add("发送方:", b.From.Hex())
+	t.Add("生成时间:", b.CreatedAt.Local().Format(time.DateTime))
+	t.Add("交易数:", strconv.Itoa(len(b.Txs)))
+	t.Write(w)
+
+	for i := range b.Txs {
+		tx, err := b.Txs[i].Transaction(b.ChainID.ToInt())
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "\n#%d  nonce %d", i+1, tx.Nonce())
+		if note := b.Txs[i].Note; note != "" {
+			fmt.Fprintf(w, "  %s", note)
+		}
+		fmt.Fprintln(w)
+		t := output.NewTable()
+		if tx.To() != nil {
+			t.Add("  To:", tx.To().Hex())
+		} else {
+			t.Add("  To:", "<合约创建>")
+		}
+		t.Add("  金额:", FormatEther(tx.Value())+" ETH")
+		if call := describeCall(reg, ethereum.CallMsg{To: tx.To(), Data: tx.Data()}); call != "" {
+			t.Add("  调用:", call)
+		}
+		t.Add("  Gas 上限:", strconv.FormatUint(tx.Gas(), 10))
+		if list := tx.AccessList(); len(list) > 0 {
+			t.Add("  访问列表:", fmt.Sprintf("%d 个地址，%d 个存储槽位", len(list), list.StorageKeys()))
+		}
+		if tx.Type() == types.LegacyTxType || tx.Type() == types.AccessListTxType {
+			t.Add("  Gas 价格:", FormatUnits(tx.GasPrice(), 9)+" Gwei")
+		} else {
+			t.Add("  最高费用:", FormatUnits(tx.GasFeeCap(), 9)+" Gwei（小费 "+FormatUnits(tx.GasTipCap(), 9)+" Gwei）")
+		}
+		t.Add("  最多花费:", FormatEther(tx.Cost())+" ETH")
+		t.Write(w)
+	}
+
+	total, err := b.MaxCost()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "\n合计最多花费: %s ETH\n", FormatEther(total))
+	return nil
+}
+
+// Transactions 解码签名交易，并检查哈希、链 ID 和发送方与交易包一致
+func (b *SignedBundle) Transactions() ([]*types.Transaction, error) {
+	if b.Version != BundleVersion {
+		return nil, fmt.Errorf("不支持的交易包版本 %d", b.Version)
+	}
+	if b.ChainID == nil {
+		return nil, errors.New("交易包缺少 chainId")
+	}
+	signer := types.LatestSignerForChainID(b.ChainID.ToInt())
+	txs := make([]*types.Transaction, len(b.Txs))
+	for i, st := range b.Txs {
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(st.Raw); err != nil {
+			return nil, fmt.Errorf("第 %d 笔交易解码失败: %w", i+1, err)
+		}
+		if tx.Hash() != st.Hash {
+			return nil, fmt.Errorf("第 %d 笔交易的哈希 %s 与记录的 %s 不一致", i+1, tx.Hash().Hex(), st.Hash.Hex())
+		}
+		from, err := types.Sender(signer, tx)
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 笔交易恢复发送方失败（链 ID 不一致？）: %w", i+1, err)
+		}
+		if from != b.From {
+			return nil, fmt.Errorf("第 %d 笔交易: %w: 签名者 %s，交易包 %s", i+1, ErrWrongSigner, from.Hex(), b.From.Hex())
+		}
+		txs[i] = tx
+	}
+	return txs, nil
+}
+
+// CheckChain 检查节点的链 ID 与交易包一致，防止把交易广播到错误的网络
+func (b *SignedBundle) CheckChain(ctx context.Context, s Broadcaster) error {
+	chainID, err := s.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("获取链 ID 失败: %w", err)
+	}
+	if chainID.Cmp(b.ChainID.ToInt()) != 0 {
+		return fmt.Errorf("%w: 节点 %s，交易包 %s", ErrWrongChain, chainID, b.ChainID.ToInt())
+	}
+	return nil
+}
+
+// Broadcast 广播一笔签名交易。交易已经在交易池或已被打包时不重复发送，返回 sent = false，
+// 因此中断后可以重新运行。nonce 已被其他交易使用时返回 ErrNonceUsed
+func Broadcast(ctx context.Context, s Broadcaster, from common.Address, tx *types.Transaction) (sent bool, err error) {
+	if _, _, err := s.TransactionByHash(ctx, tx.Hash()); err == nil {
+		return false, nil
+	} else if !errors.Is(err, ethereum.NotFound) {
+		return false, fmt.Errorf("查询交易 %s 失败: %w", tx.Hash().Hex(), err)
+	}
+
+	confirmed, err := s.NonceAt(ctx, from, nil)
+	if err != nil {
+		return false, fmt.Errorf("获取 nonce 失败: %w", err)
+	}
+	if tx.Nonce() < confirmed {
+		return false, fmt.Errorf("%w: 交易 %s 的 nonce %d，账户已确认 nonce %d", ErrNonceUsed, tx.Hash().Hex(), tx.Nonce(), confirmed)
+	}
+	if err := s.SendTransaction(ctx, tx); err != nil {
+		return false, fmt.Errorf("发送交易 %s 失败: %w", tx.Hash().Hex(), err)
+	}
+	return true, nil
+}
+
+// ReadUnsignedBundle 读取待签名交易包
+func ReadUnsignedBundle(path string) (*UnsignedBundle, error) {
+	b := new(UnsignedBundle)
+	if err := readJSON(path, b); err != nil {
+		return nil, err
+	}
+	return b, b.check()
+}
+
+// ReadSignedBundle 读取签名交易包
+func ReadSignedBundle(path string) (*SignedBundle, error) {
+	b := new(SignedBundle)
+	if err := readJSON(path, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Save 写入待签名交易包
+func (b *UnsignedBundle) Save(path string) error {
+	return writeJSON(path, b)
+}
+
+// Save 写入签名交易包。签名交易泄露不会泄露私钥，但任何人都可以广播它
+func (b *SignedBundle) Save(path string) error {
+	return writeJSON(path, b)
+}
+
+func readJSON(path string, v interface{}) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return fmt.Errorf("解析 %s 失败: %w", path, err)
+	}
+	return nil
+}
+
+func writeJSON(path string, v interface{}) error {
+	raw, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(raw, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,176 @@
+package chain_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+)
+
+func TestOfflineBundle(t *testing.T) {
+	net := newNet(t)
+	ctx := context.Background()
+	alice, bob := net.Accounts[0], net.Accounts[1]
+
+	// 在线端：不需要私钥，nonce 从 pending nonce 开始连续编号
+	start, err := net.Client.PendingNonceAt(ctx, alice.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reqs := []*chain.TxRequest{
+		{To: &bob.Address, Value: ether},
+		{To: &net.Token, Data: chain.TransferData(bob.Address, ether)},
+	}
+	bundle, err := chain.PrepareBundle(ctx, net.Client, alice.Address, reqs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bundle.ChainID.ToInt().Cmp(net.ChainID) != 0 || len(bundle.Txs) != 2 {
+		t.Fatalf("交易包: 链 ID %s，%d 笔交易", bundle.ChainID.ToInt(), len(bundle.Txs))
+	}
+	for i, u := range bundle.Txs {
+		if uint64(u.Nonce) != start+uint64(i) {
+			t.Fatalf("第 %d 笔交易的 nonce %d，期望 %d", i+1, u.Nonce, start+uint64(i))
+		}
+	}
+
+	// 文件在两台机器之间传递
+	path := filepath.Join(t.TempDir(), "unsigned.json")
+	if err := bundle.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	if bundle, err = chain.ReadUnsignedBundle(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// 离线端
+	if _, err := bundle.Sign(bob.Key); !errors.Is(err, chain.ErrWrongSigner) {
+		t.Fatalf("用其他私钥签名返回 %v，期望 %v", err, chain.ErrWrongSigner)
+	}
+	signed, err := bundle.Sign(alice.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txs, err := signed.Transactions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txs) != 2 || txs[0].Hash() != signed.Txs[0].Hash || *txs[1].To() != net.Token {
+		t.Fatalf("解码出 %d 笔交易", len(txs))
+	}
+	if err := signed.CheckChain(ctx, net.Client); err != nil {
+		t.Fatal(err)
+	}
+
+	// 广播端：第二次运行不会重复发送
+	for _, tx := range txs {
+		sent, err := chain.Broadcast(ctx, net.Client, alice.Address, tx)
+		if err != nil || !sent {
+			t.Fatalf("Broadcast = %v, %v", sent, err)
+		}
+	}
+	for _, tx := range txs {
+		if sent, err := chain.Broadcast(ctx, net.Client, alice.Address, tx); err != nil || sent {
+			t.Fatalf("交易在交易池中时 Broadcast = %v, %v", sent, err)
+		}
+	}
+	mine(t, net, txs[1])
+	for _, tx := range txs {
+		if sent, err := chain.Broadcast(ctx, net.Client, alice.Address, tx); err != nil || sent {
+			t.Fatalf("交易打包后 Broadcast = %v, %v", sent, err)
+		}
+	}
+	checkTokenBalance(t, net, bob.Address, ether)
+}
+
+func TestSignedBundleTampered(t *testing.T) {
+	net := newNet(t)
+	ctx := context.Background()
+	alice, bob := net.Accounts[0], net.Accounts[1]
+	otherChain := (*hexutil.Big)(big.NewInt(1))
+	bundle, err := chain.PrepareBundle(ctx, net.Client, alice.Address, []*chain.TxRequest{{To: &bob.Address, Value: ether}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := bundle.Sign(alice.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 另一个账户签名的交易
+	other, err := chain.PrepareBundle(ctx, net.Client, bob.Address, []*chain.TxRequest{{To: &alice.Address, Value: ether}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSigned, err := other.Sign(bob.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		tamper func(b *chain.SignedBundle)
+		err    error
+	}{
+		{"raw", func(b *chain.SignedBundle) {
+			raw := append([]byte(nil), b.Txs[0].Raw...)
+			raw[len(raw)-1] ^= 0x01 // 修改签名的 s
+			b.Txs[0].Raw = raw
+		}, nil},
+		{"hash", func(b *chain.SignedBundle) { b.Txs[0].Hash = common.Hash{1} }, nil},
+		{"swapped tx", func(b *chain.SignedBundle) { b.Txs[0] = otherSigned.Txs[0] }, chain.ErrWrongSigner},
+		{"from", func(b *chain.SignedBundle) { b.From = bob.Address }, chain.ErrWrongSigner},
+		{"chain id", func(b *chain.SignedBundle) { b.ChainID = otherChain }, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := *signed
+			b.Txs = append([]chain.SignedTx(nil), signed.Txs...)
+			tt.tamper(&b)
+			_, err := b.Transactions()
+			if err == nil {
+				t.Fatal("篡改后的交易包应返回错误")
+			}
+			if tt.err != nil && !errors.Is(err, tt.err) {
+				t.Fatalf("返回 %v，期望 %v", err, tt.err)
+			}
+		})
+	}
+
+	wrongChain := *signed
+	wrongChain.ChainID = otherChain
+	if err := wrongChain.CheckChain(ctx, net.Client); !errors.Is(err, chain.ErrWrongChain) {
+		t.Fatalf("CheckChain 返回 %v，期望 %v", err, chain.ErrWrongChain)
+	}
+}
+
+func TestBroadcastNonceUsed(t *testing.T) {
+	net := newNet(t)
+	ctx := context.Background()
+	alice, bob := net.Accounts[0], net.Accounts[1]
+	bundle, err := chain.PrepareBundle(ctx, net.Client, alice.Address, []*chain.TxRequest{{To: &bob.Address, Value: ether}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, err := bundle.Sign(alice.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txs, err := signed.Transactions()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 签名之后、广播之前，同一个 nonce 被另一笔交易使用
+	if _, err := net.Transfer(ctx, alice, bob.Address, big.NewInt(1)); err != nil {
+		t.Fatal(err)
+	}
+	if sent, err := chain.Broadcast(ctx, net.Client, alice.Address, txs[0]); !errors.Is(err, chain.ErrNonceUsed) || sent {
+		t.Fatalf("Broadcast = %v, %v，期望 %v", sent, err, chain.ErrNonceUsed)
+	}
+}
@@ -0,0 +1,243 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// RPCCaller 发送原始 JSON-RPC 请求的接口，*rpc.Client 实现了该接口。
+// ethclient 没有封装 eth_call 的覆盖参数，需要直接调用 RPC
+type RPCCaller interface {
+	CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error
+}
+
+// AccountOverride eth_call 中单个账户的状态覆盖，nil 字段保持链上状态。
+// State 替换整个存储，StateDiff 只修改列出的槽位，两者不能同时设置
+type AccountOverride struct {
+	Nonce     *hexutil.Uint64             `json:"nonce,omitempty"`
+	Code      *hexutil.Bytes              `json:"code,omitempty"`
+	Balance   *hexutil.Big                `json:"balance,omitempty"`
+	State     map[common.Hash]common.Hash `json:"state,omitempty"`
+	StateDiff map[common.Hash]common.Hash `json:"stateDiff,omitempty"`
+}
+
+// StateOverride eth_call 的状态覆盖集合，只在本次调用中生效，不会改变链上状态。
+// 设置方法返回自身，可以链式调用：
+//
+//	state := chain.StateOverride{}.
+//		SetBalance(from, big.NewInt(1e18)).
+//		SetTokenBalance(token, from, amount, 0)
+type StateOverride map[common.Address]*AccountOverride
+
+// BlockOverrides eth_call 执行时使用的区块字段，nil 字段使用所选区块的值
+type BlockOverrides struct {
+	Number      *hexutil.Big    `json:"number,omitempty"`
+	Difficulty  *hexutil.Big    `json:"difficulty,omitempty"`
+	Time        *hexutil.Uint64 `json:"time,omitempty"`
+	GasLimit    *hexutil.Uint64 `json:"gasLimit,omitempty"`
+	Coinbase    *common.Address `json:"coinbase,omitempty"`
+	Random      *common.Hash    `json:"random,omitempty"`
+	BaseFee     *hexutil.Big    `json:"baseFee,omitempty"`
+	BlobBaseFee *hexutil.Big    `json:"blobBaseFee,omitempty"`
+}
+
+// Account 返回 addr 的覆盖项，不存在时创建
+func (s StateOverride) Account(addr common.Address) *AccountOverride {
+	a, ok := s[addr]
+	if !ok {
+		a = new(AccountOverride)
+		s[addr] = a
+	}
+	return a
+}
+
+// SetBalance 覆盖 ETH 余额（wei）
+func (s StateOverride) SetBalance(addr common.Address, wei *big.Int) StateOverride {
+	s.Account(addr).Balance = (*hexutil.Big)(new(big.Int).Set(wei))
+	return s
+}
+
+// SetNonce 覆盖 nonce
+func (s StateOverride) SetNonce(addr common.Address, nonce uint64) StateOverride {
+	n := hexutil.Uint64(nonce)
+	s.Account(addr).Nonce = &n
+	return s
+}
+
+// SetCode 覆盖运行时代码，可以在没有部署的地址上执行新代码。code 为空时移除代码
+func (s StateOverride) SetCode(addr common.Address, code []byte) StateOverride {
+	c := hexutil.Bytes(common.CopyBytes(code))
+	s.Account(addr).Code = &c
+	return s
+}
+
+// SetStorage 修改单个存储槽位，其余槽位保持链上的值
+func (s StateOverride) SetStorage(addr common.Address, slot, value common.Hash) StateOverride {
+	a := s.Account(addr)
+	if a.StateDiff == nil {
+		a.StateDiff = make(map[common.Hash]common.Hash)
+	}
+	a.StateDiff[slot] = value
+	return s
+}
+
+// ReplaceStorage 用 slots 替换整个存储，没有列出的槽位都视为 0
+func (s StateOverride) ReplaceStorage(addr common.Address, slots map[common.Hash]common.Hash) StateOverride {
+	state := make(map[common.Hash]common.Hash, len(slots))
+	for k, v := range slots {
+		state[k] = v
+	}
+	s.Account(addr).State = state
+	return s
+}
+
+// SetTokenBalance 通过修改存储覆盖 ERC20 余额。slot 是余额映射所在的槽位，
+// 与合约的存储布局有关：OpenZeppelin ERC20 为 0，其他实现需要查看源码或存储布局
+func (s StateOverride) SetTokenBalance(token, owner common.Address, amount *big.Int, slot uint64) StateOverride {
+	return s.SetStorage(token, MappingSlot(common.BytesToHash(owner.Bytes()), slot), common.BigToHash(amount))
+}
+
+// Validate 检查覆盖项能否被节点接受
+func (s StateOverride) Validate() error {
+	for addr, a := range s {
+		if a.State != nil && a.StateDiff != nil {
+			return fmt.Errorf("%s: state 和 stateDiff 不能同时覆盖", addr.Hex())
+		}
+	}
+	return nil
+}
+
+// WriteSummary 按地址顺序打印覆盖内容
+func (s StateOverride) WriteSummary(w io.Writer) {
+	addrs := make([]common.Address, 0, len(s))
+	for addr := range s {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].Cmp(addrs[j]) < 0 })
+	for _, addr := range addrs {
+		a := s[addr]
+		fmt.Fprintf(w, "%s\n", addr.Hex())
+		if a.Balance != nil {
+			fmt.Fprintf(w, "  余额     %s ETH\n", FormatEther(a.Balance.ToInt()))
+		}
+		if a.Nonce != nil {
+			fmt.Fprintf(w, "  nonce    %d\n", uint64(*a.Nonce))
+		}
+		if a.Code != nil {
+			fmt.Fprintf(w, "  代码     %d 字节（%s）\n", len(*a.Code), crypto.Keccak256Hash(*a.Code).Hex())
+		}
+		if a.State != nil {
+			fmt.Fprintf(w, "  存储     替换为 %d 个槽位\n", len(a.State))
+		}
+		for _, slot := range sortedSlots(a.StateDiff) {
+			fmt.Fprintf(w, "  槽位     %s = %s\n", slot.Hex(), a.StateDiff[slot].Hex())
+		}
+	}
+}
+
+func sortedSlots(m map[common.Hash]common.Hash) []common.Hash {
+	slots := make([]common.Hash, 0, len(m))
+	for slot := range m {
+		slots = append(slots, slot)
+	}
+	sort.Slice(slots, func(i, j int) bool { return slots[i].Cmp(slots[j]) < 0 })
+	return slots
+}
+
+// MappingSlot Solidity 映射 mapping(key => ...) 在 slot 处时 key 对应的存储位置：
+// keccak256(key . slot)，key 和 slot 都左补零到 32 字节
+func MappingSlot(key common.Hash, slot uint64) common.Hash {
+	return crypto.Keccak256Hash(key.Bytes(), common.BigToHash(new(big.Int).SetUint64(slot)).Bytes())
+}
+
+// CallWithOverrides 执行带状态覆盖和区块覆盖的 eth_call。block 为 nil 时使用 latest，
+// state 和 blockOverrides 都可以为 nil。覆盖只在本次调用中生效
+func CallWithOverrides(ctx context.Context, c RPCCaller, msg ethereum.CallMsg, block *big.Int, state StateOverride, blockOverrides *BlockOverrides) ([]byte, error) {
+	if err := state.Validate(); err != nil {
+		return nil, err
+	}
+	args := []interface{}{callArg(msg), blockArg(block)}
+	if len(state) > 0 || blockOverrides != nil {
+		args = append(args, state)
+	}
+	if blockOverrides != nil {
+		args = append(args, blockOverrides)
+	}
+	var out hexutil.Bytes
+	if err := c.CallContext(ctx, &out, "eth_call", args...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// OverrideCaller 在每次调用时附加覆盖参数的 bind.ContractCaller，
+// 可以直接传给 abigen 生成的 NewXxxCaller，用覆盖后的状态调用只读方法
+type OverrideCaller struct {
+	RPC   RPCCaller
+	State StateOverride
+	Block *BlockOverrides
+}
+
+// CallContract 实现 bind.ContractCaller
+func (o *OverrideCaller) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return CallWithOverrides(ctx, o.RPC, msg, blockNumber, o.State, o.Block)
+}
+
+// CodeAt 实现 bind.ContractCaller。被覆盖的地址返回覆盖后的代码，
+// 这样绑定不会把覆盖出来的合约当作不存在
+func (o *OverrideCaller) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	if a, ok := o.State[contract]; ok && a.Code != nil {
+		return *a.Code, nil
+	}
+	var code hexutil.Bytes
+	if err := o.RPC.CallContext(ctx, &code, "eth_getCode", contract, blockArg(blockNumber)); err != nil {
+		return nil, err
+	}
+	return code, nil
+}
+
+// callArg 与 ethclient 相同的 eth_call 参数编码
+func callArg(msg ethereum.CallMsg) map[string]interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["input"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	if msg.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
+	}
+	if msg.GasFeeCap != nil {
+		arg["maxFeePerGas"] = (*hexutil.Big)(msg.GasFeeCap)
+	}
+	if msg.GasTipCap != nil {
+		arg["maxPriorityFeePerGas"] = (*hexutil.Big)(msg.GasTipCap)
+	}
+	if msg.AccessList != nil {
+		arg["accessList"] = msg.AccessList
+	}
+	return arg
+}
+
+// blockArg 区块号参数，nil 表示 latest
+func blockArg(number *big.Int) string {
+	if number == nil {
+		return "latest"
+	}
+	return hexutil.EncodeBig(number)
+}
@@ -0,0 +1,181 @@
+package chain_test
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+)
+
+// 返回单个 32 字节字的运行时代码：<op> PUSH1 0 MSTORE PUSH1 32 PUSH1 0 RETURN
+var (
+	timestampCode = common.FromHex("0x4260005260206000f3") // TIMESTAMP
+	numberCode    = common.FromHex("0x4360005260206000f3") // NUMBER
+	constantCode  = common.FromHex("0x602a60005260206000f3")
+)
+
+func TestOverrideBalance(t *testing.T) {
+	net := newNet(t)
+	ctx := context.Background()
+	sender := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+	msg := ethereum.CallMsg{From: sender, To: &net.Accounts[1].Address, Value: ether}
+
+	// 没有余额的账户无法转出 ETH
+	if _, err := chain.CallWithOverrides(ctx, net.RPC, msg, nil, nil, nil); err == nil || !strings.Contains(err.Error(), "insufficient funds") {
+		t.Fatalf("余额不足时返回 %v", err)
+	}
+	state := chain.StateOverride{}.SetBalance(sender, new(big.Int).Mul(ether, big.NewInt(2)))
+	if _, err := chain.CallWithOverrides(ctx, net.RPC, msg, nil, state, nil); err != nil {
+		t.Fatalf("覆盖余额后: %v", err)
+	}
+	// 覆盖只在本次调用中生效
+	balance, err := chain.GetBalance(ctx, net.Client, sender, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Sign() != 0 {
+		t.Fatalf("链上余额变为 %s", balance)
+	}
+}
+
+func TestOverrideTokenBalance(t *testing.T) {
+	net := newNet(t)
+	ctx := context.Background()
+	owner, to := common.HexToAddress("0x000000000000000000000000000000000000bEEF"), net.Accounts[1].Address
+	amount := new(big.Int).Mul(ether, big.NewInt(100))
+
+	// simnet 的 ERC20 余额映射在槽位 0
+	caller := &chain.OverrideCaller{RPC: net.RPC, State: chain.StateOverride{}.SetTokenBalance(net.Token, owner, amount, 0)}
+	got, err := chain.TokenBalance(ctx, caller, net.Token, owner)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Cmp(amount) != 0 {
+		t.Fatalf("覆盖后余额 %s，期望 %s", got, amount)
+	}
+
+	// 用覆盖出来的余额模拟转账
+	transfer := ethereum.CallMsg{From: owner, To: &net.Token, Data: chain.TransferData(to, ether)}
+	if _, err := chain.CallWithOverrides(ctx, net.RPC, transfer, nil, nil, nil); err == nil {
+		t.Fatal("没有代币时转账应回滚")
+	}
+	out, err := caller.CallContract(ctx, transfer, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if new(big.Int).SetBytes(out).Int64() != 1 {
+		t.Fatalf("transfer 返回 %x", out)
+	}
+
+	// 错误的槽位不会改变余额
+	wrong := &chain.OverrideCaller{RPC: net.RPC, State: chain.StateOverride{}.SetTokenBalance(net.Token, owner, amount, 1)}
+	if got, err = chain.TokenBalance(ctx, wrong, net.Token, owner); err != nil || got.Sign() != 0 {
+		t.Fatalf("槽位 1 覆盖后余额 %v（%v）", got, err)
+	}
+}
+
+func TestOverrideCode(t *testing.T) {
+	net := newNet(t)
+	ctx := context.Background()
+	empty := common.HexToAddress("0x00000000000000000000000000000000c0FFEE00")
+	caller := &chain.OverrideCaller{RPC: net.RPC, State: chain.StateOverride{}.SetCode(empty, constantCode)}
+
+	out, err := caller.CallContract(ctx, ethereum.CallMsg{To: &empty}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if new(big.Int).SetBytes(out).Int64() != 42 {
+		t.Fatalf("覆盖代码返回 %x", out)
+	}
+	// 绑定通过 CodeAt 判断合约是否存在
+	code, err := caller.CodeAt(ctx, empty, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(code, constantCode) {
+		t.Fatalf("CodeAt = %x", code)
+	}
+	// 没有覆盖的地址查询链上代码
+	if code, err = caller.CodeAt(ctx, net.Store, nil); err != nil || len(code) == 0 {
+		t.Fatalf("Store 的链上代码 %x（%v）", code, err)
+	}
+	if code, err = net.Client.CodeAt(ctx, empty, nil); err != nil || len(code) != 0 {
+		t.Fatalf("链上代码 %x（%v）", code, err)
+	}
+}
+
+func TestBlockOverrides(t *testing.T) {
+	net := newNet(t)
+	ctx := context.Background()
+	clock, height := common.HexToAddress("0x0000000000000000000000000000000000C10c00"), common.HexToAddress("0x0000000000000000000000000000000000B10c00")
+	state := chain.StateOverride{}.SetCode(clock, timestampCode).SetCode(height, numberCode)
+
+	future := hexutil.Uint64(4_102_444_800) // 2100-01-01
+	number := (*hexutil.Big)(big.NewInt(20_000_000))
+	tests := []struct {
+		name  string
+		to    common.Address
+		block *chain.BlockOverrides
+		want  uint64
+	}{
+		{"time", clock, &chain.BlockOverrides{Time: &future}, uint64(future)},
+		{"number", height, &chain.BlockOverrides{Number: number}, number.ToInt().Uint64()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := ethereum.CallMsg{To: &tt.to}
+			before, err := chain.CallWithOverrides(ctx, net.RPC, msg, nil, state, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if new(big.Int).SetBytes(before).Uint64() == tt.want {
+				t.Fatal("没有区块覆盖时不应得到覆盖值")
+			}
+			out, err := chain.CallWithOverrides(ctx, net.RPC, msg, nil, state, tt.block)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := new(big.Int).SetBytes(out).Uint64(); got != tt.want {
+				t.Fatalf("覆盖后得到 %d，期望 %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// failRPC 任何调用都让测试失败，用于确认请求没有发到节点
+type failRPC struct{ t *testing.T }
+
+func (f failRPC) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	f.t.Fatalf("不应调用 %s", method)
+	return nil
+}
+
+func TestOverrideValidate(t *testing.T) {
+	storeAddr := common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	slot := common.HexToHash("0x01")
+	state := chain.StateOverride{}.
+		ReplaceStorage(storeAddr, map[common.Hash]common.Hash{slot: common.HexToHash("0x02")}).
+		SetStorage(storeAddr, slot, common.HexToHash("0x03"))
+	if err := state.Validate(); err == nil || !strings.Contains(err.Error(), "不能同时覆盖") {
+		t.Fatalf("Validate 返回 %v", err)
+	}
+	msg := ethereum.CallMsg{To: &storeAddr}
+	if _, err := chain.CallWithOverrides(context.Background(), failRPC{t}, msg, nil, state, nil); err == nil {
+		t.Fatal("state 与 stateDiff 同时设置时应返回错误")
+	}
+
+	// 不同地址分别使用两种方式可以通过
+	other := chain.StateOverride{}.
+		ReplaceStorage(storeAddr, map[common.Hash]common.Hash{slot: common.HexToHash("0x02")}).
+		SetStorage(common.HexToAddress("0x01"), slot, common.HexToHash("0x03"))
+	if err := other.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
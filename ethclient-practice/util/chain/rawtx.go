@@ -0,0 +1,395 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+
+	"github.com/dapp-learning/ethclient/util/output"
+	"github.com/dapp-learning/ethclient/util/sigdb"
+)
+
+const (
+	// floorTokenGas EIP-7623 calldata 的最低计价：每个 token 10 Gas，非零字节算 4 个 token
+	floorTokenGas = 10
+	// blobHashVersionKZG EIP-4844 版本化哈希的版本字节
+	blobHashVersionKZG = 0x01
+)
+
+// Authorization 解码出的 EIP-7702 授权：Authority 账户把代码委托给 Address。
+// ChainID 为 0 表示在任何链上都有效
+type Authorization struct {
+	types.SetCodeAuthorization
+	Authority common.Address // 从签名恢复的授权账户
+	Err       error          // 签名无效时的错误，此时 Authority 为零地址
+}
+
+// RawTx 解码后的签名交易
+type RawTx struct {
+	Type      uint8
+	Hash      common.Hash
+	Size      int
+	ChainID   *big.Int // legacy 交易未启用 EIP-155 时为 nil
+	Nonce     uint64
+	To        *common.Address // 合约创建交易为 nil
+	Value     *big.Int
+	Gas       uint64
+	GasPrice  *big.Int // legacy / EIP-2930 交易
+	GasTipCap *big.Int // EIP-1559 及之后的交易
+	GasFeeCap *big.Int
+	Data      []byte
+
+	AccessList     types.AccessList
+	BlobFeeCap     *big.Int      // EIP-4844：每单位 blob gas 的最高价格
+	BlobHashes     []common.Hash // EIP-4844：blob 的版本化哈希
+	Sidecar        *types.BlobTxSidecar
+	Authorizations []Authorization // EIP-7702
+
+	V, R, S   *big.Int
+	From      common.Address
+	SenderErr error // 无法恢复发送方时的错误，此时 From 为零地址
+
+	Tx *types.Transaction // 由 go-ethereum 解码的交易
+}
+
+// DecodeRawTxHex 解码十六进制的签名交易，0x 前缀可选
+func DecodeRawTxHex(s string) (*RawTx, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		s = "0x" + s
+	}
+	raw, err := hexutil.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("十六进制格式错误: %w", err)
+	}
+	return DecodeRawTx(raw)
+}
+
+// DecodeRawTx 解码 eth_sendRawTransaction 格式的签名交易：legacy 交易为 RLP 列表，
+// 其他类型为 类型字节 || RLP。按交易自带的链 ID 选择签名器恢复发送方，
+// 恢复失败不返回错误，记录在 SenderErr 中
+func DecodeRawTx(raw []byte) (*RawTx, error) {
+	if len(raw) == 0 {
+		return nil, errors.New("交易为空")
+	}
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("解码交易失败: %w", err)
+	}
+	r := &RawTx{
+		Type:       tx.Type(),
+		Hash:       tx.Hash(),
+		Size:       len(raw),
+		Nonce:      tx.Nonce(),
+		To:         tx.To(),
+		Value:      tx.Value(),
+		Gas:        tx.Gas(),
+		Data:       tx.Data(),
+		AccessList: tx.AccessList(),
+		Tx:         tx,
+	}
+	r.V, r.R, r.S = tx.RawSignatureValues()
+
+	var signer types.Signer = types.HomesteadSigner{}
+	if tx.Protected() {
+		r.ChainID = tx.ChainId()
+		signer = types.LatestSignerForChainID(r.ChainID)
+	}
+	switch tx.Type() {
+	case types.LegacyTxType, types.AccessListTxType:
+		r.GasPrice = tx.GasPrice()
+	default:
+		r.GasTipCap, r.GasFeeCap = tx.GasTipCap(), tx.GasFeeCap()
+	}
+	if tx.Type() == types.BlobTxType {
+		r.BlobFeeCap = tx.BlobGasFeeCap()
+		r.BlobHashes = tx.BlobHashes()
+		r.Sidecar = tx.BlobTxSidecar()
+	}
+	for _, a := range tx.SetCodeAuthorizations() {
+		auth := Authorization{SetCodeAuthorization: a}
+		auth.Authority, auth.Err = a.Authority()
+		r.Authorizations = append(r.Authorizations, auth)
+	}
+	r.From, r.SenderErr = types.Sender(signer, tx)
+	return r, nil
+}
+
+// Protected 签名是否绑定了链 ID（EIP-155 或 typed 交易）
+func (r *RawTx) Protected() bool {
+	return r.ChainID != nil
+}
+
+// BlobGas blob 消耗的 blob gas，每个 blob 固定 131072
+func (r *RawTx) BlobGas() uint64 {
+	return uint64(len(r.BlobHashes)) * params.BlobTxBlobGasPerBlob
+}
+
+// MaxCost 最多花费的 ETH：金额 + Gas 上限 × 最高 Gas 价格 + blob gas × 最高 blob 价格
+func (r *RawTx) MaxCost() *big.Int {
+	price := r.GasFeeCap
+	if price == nil {
+		price = r.GasPrice
+	}
+	cost := new(big.Int).Mul(new(big.Int).SetUint64(r.Gas), price)
+	if r.Value != nil {
+		cost.Add(cost, r.Value)
+	}
+	if r.BlobFeeCap != nil {
+		cost.Add(cost, new(big.Int).Mul(new(big.Int).SetUint64(r.BlobGas()), r.BlobFeeCap))
+	}
+	return cost
+}
+
+// IntrinsicGas 交易执行前就要扣除的 Gas：基础费用、calldata、access list、
+// initcode（EIP-3860）和 EIP-7702 授权。floor 是 EIP-7623 对 calldata 的最低计价，
+// Prague 之后 Gas 上限必须不小于两者中的较大值
+func (r *RawTx) IntrinsicGas() (intrinsic, floor uint64) {
+	intrinsic = params.TxGas
+	if r.To == nil {
+		intrinsic = params.TxGasContractCreation
+		intrinsic += uint64(len(r.Data)+31) / 32 * params.InitCodeWordGas
+	}
+	var tokens uint64
+	for _, b := range r.Data {
+		if b == 0 {
+			intrinsic += params.TxDataZeroGas
+			tokens++
+		} else {
+			intrinsic += params.TxDataNonZeroGasEIP2028
+			tokens += 4
+		}
+	}
+	for _, tuple := range r.AccessList {
+		intrinsic += params.TxAccessListAddressGas + uint64(len(tuple.StorageKeys))*params.TxAccessListStorageKeyGas
+	}
+	intrinsic += uint64(len(r.Authorizations)) * params.CallNewAccountGas // EIP-7702 PER_EMPTY_ACCOUNT_COST
+	return intrinsic, params.TxGas + tokens*floorTokenGas
+}
+
+// TxCheck 一项有效性检查的结果
+type TxCheck struct {
+	Name   string
+	OK     bool
+	Detail string
+}
+
+// Check 离线检查交易能否被节点接受：签名、链 ID、nonce、费用字段、Gas 上限以及
+// blob / 授权等类型特有的字段。chainID 或 nonce 为 nil 时跳过对应检查
+func (r *RawTx) Check(chainID *big.Int, nonce *uint64) []TxCheck {
+	var checks []TxCheck
+	add := func(name string, ok bool, format string, args ...interface{}) {
+		checks = append(checks, TxCheck{Name: name, OK: ok, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	if r.SenderErr != nil {
+		add("签名", false, "无法恢复发送方: %v", r.SenderErr)
+	} else {
+		add("签名", true, "发送方 %s", r.From.Hex())
+	}
+
+	switch {
+	case chainID == nil:
+	case !r.Protected():
+		add("链 ID", false, "未启用 EIP-155，签名没有绑定链 ID，可以在任何链上重放；多数节点默认拒绝")
+	case r.ChainID.Cmp(chainID) != 0:
+		add("链 ID", false, "交易为 %s，目标链为 %s", r.ChainID, chainID)
+	default:
+		add("链 ID", true, "%s", chainID)
+	}
+
+	if nonce != nil {
+		switch {
+		case r.Nonce < *nonce:
+			add("Nonce", false, "%d 低于账户 nonce %d，已被使用", r.Nonce, *nonce)
+		case r.Nonce > *nonce:
+			add("Nonce", false, "%d 高于账户 nonce %d，会在交易池排队，直到前面 %d 笔交易被打包", r.Nonce, *nonce, r.Nonce-*nonce)
+		default:
+			add("Nonce", true, "%d", r.Nonce)
+		}
+	}
+
+	if r.GasFeeCap != nil {
+		if r.GasTipCap.Cmp(r.GasFeeCap) > 0 {
+			add("费用", false, "小费 %s Gwei 高于最高费用 %s Gwei", FormatUnits(r.GasTipCap, 9), FormatUnits(r.GasFeeCap, 9))
+		} else {
+			add("费用", true, "最高费用 %s Gwei，小费 %s Gwei", FormatUnits(r.GasFeeCap, 9), FormatUnits(r.GasTipCap, 9))
+		}
+	}
+
+	intrinsic, floor := r.IntrinsicGas()
+	need := max(intrinsic, floor)
+	if r.Gas < need {
+		add("Gas 上限", false, "%d 低于固有 Gas %d（EIP-7623 下限 %d）", r.Gas, intrinsic, floor)
+	} else {
+		add("Gas 上限", true, "%d，固有 Gas %d（EIP-7623 下限 %d）", r.Gas, intrinsic, floor)
+	}
+	if r.To == nil && len(r.Data) > params.MaxInitCodeSize {
+		add("Initcode", false, "%d 字节，超过 EIP-3860 上限 %d", len(r.Data), params.MaxInitCodeSize)
+	}
+
+	switch r.Type {
+	case types.BlobTxType:
+		checks = append(checks, r.checkBlobs())
+	case types.SetCodeTxType:
+		checks = append(checks, r.checkAuthorizations(chainID)...)
+	}
+	return checks
+}
+
+// checkBlobs blob 交易至少带一个 blob，版本化哈希以 0x01 开头；
+// 带 sidecar 时每个哈希必须等于 0x01 || sha256(commitment)[1:]，且 KZG 证明有效
+func (r *RawTx) checkBlobs() TxCheck {
+	check := TxCheck{Name: "Blob"}
+	if len(r.BlobHashes) == 0 {
+		check.Detail = "没有 blob"
+		return check
+	}
+	for i, h := range r.BlobHashes {
+		if h[0] != blobHashVersionKZG {
+			check.Detail = fmt.Sprintf("第 %d 个版本化哈希的版本为 0x%02x，应为 0x01", i+1, h[0])
+			return check
+		}
+	}
+	if r.Sidecar == nil {
+		check.OK = true
+		check.Detail = fmt.Sprintf("%d 个 blob（不含 sidecar，只能在区块中出现，不能直接广播）", len(r.BlobHashes))
+		return check
+	}
+	if err := VerifyBlobSidecar(r.Sidecar, r.BlobHashes); err != nil {
+		check.Detail = err.Error()
+		return check
+	}
+	check.OK = true
+	check.Detail = fmt.Sprintf("%d 个 blob，承诺与哈希一致，KZG 证明有效", len(r.BlobHashes))
+	return check
+}
+
+// checkAuthorizations SetCode 交易至少带一条授权，每条授权的签名都要能恢复，
+// 链 ID 为 0 或与目标链一致
+func (r *RawTx) checkAuthorizations(chainID *big.Int) []TxCheck {
+	if len(r.Authorizations) == 0 {
+		return []TxCheck{{Name: "授权", Detail: "授权列表为空"}}
+	}
+	var checks []TxCheck
+	for i, a := range r.Authorizations {
+		check := TxCheck{Name: "授权 #" + strconv.Itoa(i+1)}
+		switch {
+		case a.Err != nil:
+			check.Detail = "签名无效: " + a.Err.Error()
+		case chainID != nil && !a.ChainID.IsZero() && a.ChainID.CmpBig(chainID) != 0:
+			check.Detail = fmt.Sprintf("链 ID %s 与目标链 %s 不一致，执行时会被跳过", &a.ChainID, chainID)
+		default:
+			check.OK = true
+			check.Detail = fmt.Sprintf("%s 委托给 %s", a.Authority.Hex(), a.Address.Hex())
+		}
+		checks = append(checks, check)
+	}
+	return checks
+}
+
+// WriteReport 打印交易的全部字段、按 reg 解码的 calldata 和签名。reg 为 nil 时使用 sigdb.NewDefault()
+func (r *RawTx) WriteReport(w io.Writer, reg *sigdb.Registry) {
+	if reg == nil {
+		reg = sigdb.NewDefault()
+	}
+	t := output.NewTable()
+	t.Add("类型:", fmt.Sprintf("%s (0x%02x)", TxTypeName(r.Type), r.Type))
+	t.Add("哈希:", r.Hash.Hex())
+	t.Add("大小:", strconv.Itoa(r.Size)+" 字节")
+	if r.Protected() {
+		t.Add("链 ID:", r.ChainID.String())
+	} else {
+		t.Add("链 ID:", "无（未启用 EIP-155）")
+	}
+	if r.SenderErr != nil {
+		t.Add("From:", "无法恢复: "+r.SenderErr.Error())
+	} else {
+		t.Add("From:", r.From.Hex())
+	}
+	if r.To != nil {
+		t.Add("To:", r.To.Hex())
+	} else if r.SenderErr == nil {
+		t.Add("To:", "<合约创建> 部署地址 "+crypto.CreateAddress(r.From, r.Nonce).Hex())
+	} else {
+		t.Add("To:", "<合约创建>")
+	}
+	t.Add("金额:", FormatEther(r.Value)+" ETH")
+	t.Add("Nonce:", strconv.FormatUint(r.Nonce, 10))
+	t.Add("Gas 上限:", strconv.FormatUint(r.Gas, 10))
+	if r.GasPrice != nil {
+		t.Add("Gas 价格:", FormatUnits(r.GasPrice, 9)+" Gwei")
+	} else {
+		t.Add("最高费用:", FormatUnits(r.GasFeeCap, 9)+" Gwei")
+		t.Add("小费:", FormatUnits(r.GasTipCap, 9)+" Gwei")
+	}
+	if r.BlobFeeCap != nil {
+		t.Add("Blob 最高价格:", FormatUnits(r.BlobFeeCap, 9)+" Gwei")
+		t.Add("Blob gas:", fmt.Sprintf("%d（%d 个 blob）", r.BlobGas(), len(r.BlobHashes)))
+	}
+	t.Add("最多花费:", FormatEther(r.MaxCost())+" ETH")
+	if call := describeCall(reg, ethereum.CallMsg{To: r.To, Data: r.Data}); call != "" {
+		t.Add("调用:", call)
+	}
+	if len(r.Data) > 0 {
+		t.Add("Calldata:", fmt.Sprintf("%d 字节", len(r.Data)))
+	}
+	t.Add("签名:", fmt.Sprintf("v=%s r=%#x s=%#x", r.V, r.R, r.S))
+	t.Write(w)
+
+	if len(r.AccessList) > 0 {
+		fmt.Fprintf(w, "\nAccess list（%d 个地址）:\n", len(r.AccessList))
+		for _, tuple := range r.AccessList {
+			fmt.Fprintf(w, "  %s\n", tuple.Address.Hex())
+			for _, key := range tuple.StorageKeys {
+				fmt.Fprintf(w, "    %s\n", key.Hex())
+			}
+		}
+	}
+	if len(r.BlobHashes) > 0 {
+		fmt.Fprintln(w, "\nBlob 版本化哈希:")
+		for _, h := range r.BlobHashes {
+			fmt.Fprintf(w, "  %s\n", h.Hex())
+		}
+	}
+	if len(r.Authorizations) > 0 {
+		fmt.Fprintf(w, "\n授权列表（%d 条）:\n", len(r.Authorizations))
+		for i, a := range r.Authorizations {
+			authority := a.Authority.Hex()
+			if a.Err != nil {
+				authority = "无法恢复（" + a.Err.Error() + "）"
+			}
+			chain := a.ChainID.String()
+			if a.ChainID.IsZero() {
+				chain = "0（任意链）"
+			}
+			fmt.Fprintf(w, "  #%d %s → %s  链 ID %s  nonce %d\n", i+1, authority, a.Address.Hex(), chain, a.Nonce)
+		}
+	}
+}
+
+// WriteChecks 打印 Check 的结果，返回是否全部通过
+func WriteChecks(w io.Writer, checks []TxCheck) bool {
+	t := output.NewTable()
+	ok := true
+	for _, c := range checks {
+		mark := "✓"
+		if !c.OK {
+			mark = "✗"
+			ok = false
+		}
+		t.Add(mark+" "+c.Name, c.Detail)
+	}
+	t.Write(w)
+	return ok
+}
@@ -0,0 +1,194 @@
+package chain_test
+
+import (
+	"math/big"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+)
+
+func TestDecodeAndCheckRawTx(t *testing.T) {
+	key := newKey(t)
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+	chainID := big.NewInt(1337)
+	const accountNonce = 5
+	to := common.HexToAddress("0x71C7656EC7ab88b098defB751B7401B5f6d8976F")
+	gwei := big.NewInt(params.GWei)
+	ugwei := uint256.NewInt(params.GWei)
+	chainID256 := uint256.MustFromBig(chainID)
+
+	sidecar, err := chain.NewBlobSidecar(chain.EncodeBlobs([]byte("hello blob")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := chain.SignAuthorization(key, chainID, to, accountNonce+1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	calldata := []byte(strings.Repeat("x", 100)) // 100 个非零字节：固有 Gas 22600，EIP-7623 下限 25000
+	initcode := make([]byte, 64)                 // 2 个字：EIP-3860 每字 2 Gas
+
+	tests := []struct {
+		name      string
+		signer    types.Signer
+		tx        types.TxData
+		protected bool
+		intrinsic uint64
+		floor     uint64
+		failed    []string // 未通过的检查
+	}{
+		{
+			name:      "legacy unprotected",
+			signer:    types.HomesteadSigner{},
+			tx:        &types.LegacyTx{Nonce: accountNonce, To: &to, Gas: 24_000, GasPrice: gwei, Data: calldata},
+			intrinsic: 22_600, floor: 25_000,
+			failed: []string{"链 ID", "Gas 上限"}, // 高于固有 Gas 但低于 calldata 下限
+		},
+		{
+			name:      "legacy eip-155",
+			signer:    types.NewEIP155Signer(chainID),
+			tx:        &types.LegacyTx{Nonce: accountNonce - 1, To: &to, Gas: 21_000, GasPrice: gwei},
+			protected: true, intrinsic: 21_000, floor: 21_000,
+			failed: []string{"Nonce"},
+		},
+		{
+			name:   "access list",
+			signer: types.NewEIP2930Signer(chainID),
+			tx: &types.AccessListTx{ChainID: chainID, Nonce: accountNonce, To: &to, Gas: 27_200, GasPrice: gwei,
+				AccessList: types.AccessList{{Address: to, StorageKeys: []common.Hash{{1}, {2}}}}},
+			protected: true, intrinsic: 21_000 + 2_400 + 2*1_900, floor: 21_000,
+		},
+		{
+			name:      "dynamic fee create",
+			signer:    types.NewLondonSigner(chainID),
+			tx:        &types.DynamicFeeTx{ChainID: chainID, Nonce: accountNonce, Gas: 53_259, GasTipCap: gwei, GasFeeCap: gwei, Data: initcode},
+			protected: true, intrinsic: 53_000 + 2*params.InitCodeWordGas + 64*params.TxDataZeroGas, floor: 21_000 + 64*10,
+			failed: []string{"Gas 上限"}, // 少算了 initcode 的字费用
+		},
+		{
+			name:      "dynamic fee wrong chain",
+			signer:    types.NewLondonSigner(big.NewInt(1)),
+			tx:        &types.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: accountNonce + 2, To: &to, Gas: 21_000, GasTipCap: new(big.Int).Mul(gwei, big.NewInt(2)), GasFeeCap: gwei},
+			protected: true, intrinsic: 21_000, floor: 21_000,
+			failed: []string{"链 ID", "Nonce", "费用"},
+		},
+		{
+			name:   "blob without sidecar",
+			signer: types.NewCancunSigner(chainID),
+			tx: &types.BlobTx{ChainID: chainID256, Nonce: accountNonce, To: to, Gas: 21_000, GasTipCap: ugwei, GasFeeCap: ugwei,
+				BlobFeeCap: uint256.NewInt(1), BlobHashes: sidecar.BlobHashes()},
+			protected: true, intrinsic: 21_000, floor: 21_000,
+		},
+		{
+			name:   "blob with sidecar",
+			signer: types.NewCancunSigner(chainID),
+			tx: &types.BlobTx{ChainID: chainID256, Nonce: accountNonce, To: to, Gas: 21_000, GasTipCap: ugwei, GasFeeCap: ugwei,
+				BlobFeeCap: uint256.NewInt(1), BlobHashes: sidecar.BlobHashes(), Sidecar: sidecar},
+			protected: true, intrinsic: 21_000, floor: 21_000,
+		},
+		{
+			name:   "blob with mismatched sidecar",
+			signer: types.NewCancunSigner(chainID),
+			tx: &types.BlobTx{ChainID: chainID256, Nonce: accountNonce, To: to, Gas: 21_000, GasTipCap: ugwei, GasFeeCap: ugwei,
+				BlobFeeCap: uint256.NewInt(1), BlobHashes: []common.Hash{{0x01}}, Sidecar: sidecar},
+			protected: true, intrinsic: 21_000, floor: 21_000,
+			failed: []string{"Blob"},
+		},
+		{
+			name:   "set code",
+			signer: types.NewPragueSigner(chainID),
+			tx: &types.SetCodeTx{ChainID: chainID256, Nonce: accountNonce, To: sender, Gas: 45_999, GasTipCap: ugwei, GasFeeCap: ugwei,
+				AuthList: []types.SetCodeAuthorization{auth}},
+			protected: true, intrinsic: 21_000 + params.CallNewAccountGas, floor: 21_000,
+			failed: []string{"Gas 上限"}, // 每条授权 25000
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tx, err := types.SignNewTx(key, tt.signer, tt.tx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			raw, err := tx.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := chain.DecodeRawTx(raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.SenderErr != nil || r.From != sender {
+				t.Fatalf("发送方 %s（%v），期望 %s", r.From.Hex(), r.SenderErr, sender.Hex())
+			}
+			if r.Type != tx.Type() || r.Hash != tx.Hash() || r.Protected() != tt.protected {
+				t.Fatalf("类型 %d，哈希 %s，protected=%v", r.Type, r.Hash.Hex(), r.Protected())
+			}
+			if intrinsic, floor := r.IntrinsicGas(); intrinsic != tt.intrinsic || floor != tt.floor {
+				t.Fatalf("IntrinsicGas = %d, %d，期望 %d, %d", intrinsic, floor, tt.intrinsic, tt.floor)
+			}
+
+			nonce := uint64(accountNonce)
+			var failed []string
+			for _, c := range r.Check(chainID, &nonce) {
+				if !c.OK {
+					failed = append(failed, c.Name)
+				}
+			}
+			if !slices.Equal(failed, tt.failed) {
+				t.Fatalf("未通过的检查 %v，期望 %v", failed, tt.failed)
+			}
+		})
+	}
+}
+
+func TestRawTxAuthorizations(t *testing.T) {
+	key := newKey(t)
+	chainID := big.NewInt(1337)
+	impl := common.HexToAddress("0x1234")
+	good, err := chain.SignAuthorization(key, nil, impl, 0) // 链 ID 为 0，任何链都有效
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := chain.SignAuthorization(key, big.NewInt(1), impl, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	broken := good
+	broken.S = uint256.Int{} // 无法恢复授权者
+
+	tx, err := types.SignNewTx(key, types.NewPragueSigner(chainID), &types.SetCodeTx{
+		ChainID: uint256.MustFromBig(chainID), To: impl, Gas: 100_000, GasTipCap: uint256.NewInt(1), GasFeeCap: uint256.NewInt(1),
+		AuthList: []types.SetCodeAuthorization{good, other, broken},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := chain.DecodeRawTx(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Authorizations[0].Authority != crypto.PubkeyToAddress(key.PublicKey) || r.Authorizations[2].Err == nil {
+		t.Fatalf("授权 %+v", r.Authorizations)
+	}
+	var results []bool
+	for _, c := range r.Check(chainID, nil) {
+		if strings.HasPrefix(c.Name, "授权") {
+			results = append(results, c.OK)
+		}
+	}
+	if want := []bool{true, false, false}; !slices.Equal(results, want) {
+		t.Fatalf("授权检查 %v，期望 %v", results, want)
+	}
+}
@@ -0,0 +1,194 @@
+package chain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultPollInterval WaitReceipt 的默认轮询间隔
+const DefaultPollInterval = 5 * time.Second
+
+// Receipt 收据摘要
+type Receipt struct {
+	TxHash            common.Hash
+	BlockNumber       uint64
+	BlockHash         common.Hash
+	TransactionIndex  uint
+	Type              uint8
+	Status            uint64
+	GasUsed           uint64
+	CumulativeGasUsed uint64
+	EffectiveGasPrice *big.Int        // 部分节点不返回时为 nil
+	ContractAddress   *common.Address // 仅合约创建交易
+	Logs              []*types.Log
+	BlobGasUsed       uint64   // 仅 blob 交易
+	BlobGasPrice      *big.Int // 仅 blob 交易：打包时每单位 blob gas 的价格
+}
+
+// NewReceipt 从收据中提取摘要
+func NewReceipt(receipt *types.Receipt) *Receipt {
+	r := &Receipt{
+		TxHash:            receipt.TxHash,
+		BlockHash:         receipt.BlockHash,
+		TransactionIndex:  receipt.TransactionIndex,
+		Type:              receipt.Type,
+		Status:            receipt.Status,
+		GasUsed:           receipt.GasUsed,
+		CumulativeGasUsed: receipt.CumulativeGasUsed,
+		EffectiveGasPrice: receipt.EffectiveGasPrice,
+		Logs:              receipt.Logs,
+		BlobGasUsed:       receipt.BlobGasUsed,
+		BlobGasPrice:      receipt.BlobGasPrice,
+	}
+	if receipt.BlockNumber != nil {
+		r.BlockNumber = receipt.BlockNumber.Uint64()
+	}
+	if receipt.ContractAddress != (common.Address{}) {
+		addr := receipt.ContractAddress
+		r.ContractAddress = &addr
+	}
+	return r
+}
+
+// GetReceipt 按交易哈希查询收据
+func GetReceipt(ctx context.Context, r ReceiptReader, hash common.Hash) (*Receipt, error) {
+	receipt, err := r.TransactionReceipt(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("查询收据 %s 失败: %w", hash.Hex(), err)
+	}
+	return NewReceipt(receipt), nil
+}
+
+// Success 交易是否执行成功
+func (r *Receipt) Success() bool {
+	return r.Status == types.ReceiptStatusSuccessful
+}
+
+// Fee 交易费用（wei）= GasUsed × EffectiveGasPrice，节点未返回价格时为 nil
+func (r *Receipt) Fee() *big.Int {
+	if r.EffectiveGasPrice == nil {
+		return nil
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(r.GasUsed), r.EffectiveGasPrice)
+}
+
+// BlobFee blob 费用（wei）= BlobGasUsed × BlobGasPrice，非 blob 交易为 nil。
+// blob 费用与 Fee 分开计算，全部销毁，不支付给验证者
+func (r *Receipt) BlobFee() *big.Int {
+	if r.BlobGasPrice == nil {
+		return nil
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(r.BlobGasUsed), r.BlobGasPrice)
+}
+
+// WaitReceipt 轮询直到交易被打包，返回收据。interval 为 0 时使用 DefaultPollInterval，
+// 超时由 ctx 控制
+func WaitReceipt(ctx context.Context, r ReceiptReader, hash common.Hash, interval time.Duration) (*Receipt, error) {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		receipt, err := r.TransactionReceipt(ctx, hash)
+		if err == nil {
+			return NewReceipt(receipt), nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			return nil, fmt.Errorf("查询收据 %s 失败: %w", hash.Hex(), err)
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("等待交易 %s 确认超时: %w", hash.Hex(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// BlockReceiptsReader 支持 eth_getBlockReceipts 的节点，一次请求取回整个区块的收据
+type BlockReceiptsReader interface {
+	BlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*types.Receipt, error)
+}
+
+// GetBlockReceipts 查询区块内所有交易的收据。number 为 nil 时查询最新区块。
+// 节点支持 BlockReceipts 时一次取回，否则逐笔查询
+func GetBlockReceipts(ctx context.Context, r ChainReader, number *big.Int) ([]*Receipt, error) {
+	if br, ok := r.(BlockReceiptsReader); ok && number != nil {
+		receipts, err := br.BlockReceipts(ctx, rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(number.Int64())))
+		if err != nil {
+			return nil, fmt.Errorf("查询区块 %s 的收据失败: %w", number, err)
+		}
+		out := make([]*Receipt, len(receipts))
+		for i, receipt := range receipts {
+			out[i] = NewReceipt(receipt)
+		}
+		return out, nil
+	}
+
+	block, err := r.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, fmt.Errorf("查询区块失败: %w", err)
+	}
+	out := make([]*Receipt, 0, len(block.Transactions()))
+	for _, tx := range block.Transactions() {
+		receipt, err := GetReceipt(ctx, r, tx.Hash())
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, receipt)
+	}
+	return out, nil
+}
+
+// ReceiptStats 一组收据的统计
+type ReceiptStats struct {
+	Total             int
+	Success           int
+	Failed            int
+	ContractCreations int
+	GasUsed           uint64
+	TopGas            []*Receipt // 按 GasUsed 从高到低
+}
+
+// SummarizeReceipts 统计成功/失败数量、Gas 使用和合约创建，top 为保留的 Gas 使用最多的收据数（负数表示全部保留）
+func SummarizeReceipts(receipts []*Receipt, top int) *ReceiptStats {
+	stats := &ReceiptStats{Total: len(receipts)}
+	for _, r := range receipts {
+		stats.GasUsed += r.GasUsed
+		if r.Success() {
+			stats.Success++
+		} else {
+			stats.Failed++
+		}
+		if r.ContractAddress != nil {
+			stats.ContractCreations++
+		}
+	}
+
+	sorted := append([]*Receipt(nil), receipts...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].GasUsed > sorted[j].GasUsed
+	})
+	if top >= 0 && top < len(sorted) {
+		sorted = sorted[:top]
+	}
+	stats.TopGas = sorted
+	return stats
+}
+
+// AvgGasUsed 平均每笔交易使用的 Gas
+func (s *ReceiptStats) AvgGasUsed() uint64 {
+	if s.Total == 0 {
+		return 0
+	}
+	return s.GasUsed / uint64(s.Total)
+}
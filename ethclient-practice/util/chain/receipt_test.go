@@ -0,0 +1,144 @@
+package chain_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+)
+
+func TestReceiptFees(t *testing.T) {
+	r := chain.NewReceipt(&types.Receipt{
+		Status:            types.ReceiptStatusSuccessful,
+		GasUsed:           21000,
+		EffectiveGasPrice: big.NewInt(3e9),
+		BlobGasUsed:       131072,
+		BlobGasPrice:      big.NewInt(2),
+		BlockNumber:       big.NewInt(9),
+	})
+	if !r.Success() || r.BlockNumber != 9 || r.ContractAddress != nil {
+		t.Fatalf("收据摘要不符: %+v", r)
+	}
+	if want := big.NewInt(63e12); r.Fee().Cmp(want) != 0 {
+		t.Errorf("Fee = %s，期望 %s", r.Fee(), want)
+	}
+	if r.BlobFee().Int64() != 262144 {
+		t.Errorf("BlobFee = %s，期望 262144", r.BlobFee())
+	}
+
+	// 节点没有返回价格
+	r = chain.NewReceipt(&types.Receipt{GasUsed: 21000, ContractAddress: to})
+	if r.Fee() != nil || r.BlobFee() != nil || r.Success() {
+		t.Errorf("缺少价格时 Fee/BlobFee 应为 nil")
+	}
+	if r.ContractAddress == nil || *r.ContractAddress != to {
+		t.Errorf("ContractAddress = %v", r.ContractAddress)
+	}
+}
+
+func TestWaitReceipt(t *testing.T) {
+	node := newFakeNode()
+	hash := common.HexToHash("0xabc")
+	node.receipts[hash] = &types.Receipt{TxHash: hash, Status: types.ReceiptStatusSuccessful}
+	node.receiptAfter = 2
+
+	r, err := chain.WaitReceipt(context.Background(), node, hash, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.TxHash != hash || node.receiptAfter != 0 {
+		t.Fatalf("收据 %s，剩余 NotFound 次数 %d", r.TxHash.Hex(), node.receiptAfter)
+	}
+
+	// 一直没有打包时由 ctx 超时
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := chain.WaitReceipt(ctx, node, common.HexToHash("0xdef"), time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("应超时，得到 %v", err)
+	}
+}
+
+func TestSummarizeReceipts(t *testing.T) {
+	addr := common.HexToAddress("0x01")
+	receipts := []*chain.Receipt{
+		{Status: 1, GasUsed: 21000},
+		{Status: 0, GasUsed: 90000},
+		{Status: 1, GasUsed: 500000, ContractAddress: &addr},
+		{Status: 1, GasUsed: 45000},
+	}
+	stats := chain.SummarizeReceipts(receipts, 2)
+	if stats.Total != 4 || stats.Success != 3 || stats.Failed != 1 || stats.ContractCreations != 1 || stats.GasUsed != 656000 {
+		t.Fatalf("统计不符: %+v", stats)
+	}
+	if stats.AvgGasUsed() != 164000 {
+		t.Errorf("AvgGasUsed = %d", stats.AvgGasUsed())
+	}
+	if len(stats.TopGas) != 2 || stats.TopGas[0].GasUsed != 500000 || stats.TopGas[1].GasUsed != 90000 {
+		t.Errorf("TopGas 不符: %v", stats.TopGas)
+	}
+	if all := chain.SummarizeReceipts(receipts, -1); len(all.TopGas) != 4 {
+		t.Errorf("top 为负数时应保留全部，得到 %d", len(all.TopGas))
+	}
+	if (&chain.ReceiptStats{}).AvgGasUsed() != 0 {
+		t.Error("空统计的平均值应为 0")
+	}
+}
+
+func TestGetBlockReceiptsFallback(t *testing.T) {
+	// fakeNode 没有实现 BlockReceiptsReader，逐笔查询收据
+	node := newFakeNode()
+	key := newKey(t)
+	var txs []*types.Transaction
+	for i := uint64(0); i < 3; i++ {
+		tx := signLegacy(t, key, node.chainID, i)
+		txs = append(txs, tx)
+		node.receipts[tx.Hash()] = &types.Receipt{TxHash: tx.Hash(), Status: 1, GasUsed: 21000 * (i + 1)}
+	}
+	node.blocks[5] = types.NewBlock(&types.Header{Number: big.NewInt(5)}, &types.Body{Transactions: txs}, nil, trie.NewStackTrie(nil))
+
+	receipts, err := chain.GetBlockReceipts(context.Background(), node, big.NewInt(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(receipts) != 3 || receipts[2].GasUsed != 63000 || receipts[0].TxHash != txs[0].Hash() {
+		t.Fatalf("收据不符: %d 条", len(receipts))
+	}
+
+	// 某笔收据缺失时返回错误
+	delete(node.receipts, txs[1].Hash())
+	if _, err := chain.GetBlockReceipts(context.Background(), node, big.NewInt(5)); err == nil {
+		t.Fatal("收据缺失时应返回错误")
+	}
+}
+
+func TestGetTxStatusPending(t *testing.T) {
+	node := newFakeNode()
+	tx := signLegacy(t, newKey(t), node.chainID, 0)
+	node.txs[tx.Hash()] = tx
+
+	status, err := chain.GetTxStatus(context.Background(), node, tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Tx.Pending || status.Receipt != nil || status.GasUsage() != 0 {
+		t.Fatalf("交易池中的交易不应有收据: %+v", status)
+	}
+
+	node.receipts[tx.Hash()] = &types.Receipt{TxHash: tx.Hash(), Status: 1, GasUsed: 10500}
+	if status, err = chain.GetTxStatus(context.Background(), node, tx.Hash()); err != nil {
+		t.Fatal(err)
+	}
+	if status.Tx.Pending || status.GasUsage() != 50 {
+		t.Fatalf("GasUsage = %.1f，期望 50", status.GasUsage())
+	}
+	if status.Tx.TypeName() != "Legacy" || chain.TxTypeName(4) != "SetCode" || chain.TxTypeName(9) != "Unknown(9)" {
+		t.Error("TxTypeName 不符")
+	}
+}
@@ -0,0 +1,165 @@
+package chain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DefaultGasMargin 在 EstimateGas 结果上增加的余量（百分比）
+const DefaultGasMargin = 20
+
+// TxRequest 待发送的交易，零值字段由 SignRequest 从节点补全
+type TxRequest struct {
+	To         *common.Address // 为 nil 时是合约创建交易
+	Value      *big.Int
+	Data       []byte
+	GasLimit   uint64           // 为 0 时使用 EstimateGas 的结果加余量
+	GasMargin  uint64           // 估算余量百分比，为 0 时使用 DefaultGasMargin
+	Nonce      *uint64          // 为 nil 时使用 pending nonce
+	AccessList types.AccessList // EIP-2930 访问列表，可以由 AttachAccessList 生成
+}
+
+// WithMargin 在估算的 Gas 上增加 margin 百分比的余量，margin 为 0 时使用 DefaultGasMargin
+func WithMargin(estimated, margin uint64) uint64 {
+	if margin == 0 {
+		margin = DefaultGasMargin
+	}
+	return estimated * (100 + margin) / 100
+}
+
+// FeeSuggester 查询费用建议需要的节点接口
+type FeeSuggester interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+}
+
+// Fees BuildTx 使用的费用。节点支持 EIP-1559 时 GasTipCap / GasFeeCap 有值，否则只有 GasPrice
+type Fees struct {
+	GasPrice  *big.Int
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+}
+
+// Max 每单位 Gas 最多支付的费用，用于估算交易最多花费多少 ETH
+func (f *Fees) Max() *big.Int {
+	if f.GasPrice != nil {
+		return f.GasPrice
+	}
+	return f.GasFeeCap
+}
+
+// MaxFeePerGas 最高费用 = 2 × baseFee + 小费，可以承受连续几个区块的 baseFee 上涨
+func MaxFeePerGas(baseFee, tip *big.Int) *big.Int {
+	return new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(2)), tip)
+}
+
+// SuggestFees 按节点的建议确定费用：支持 EIP-1559 时小费取 eth_maxPriorityFeePerGas，
+// 最高费用按 MaxFeePerGas 计算；否则使用 eth_gasPrice
+func SuggestFees(ctx context.Context, s FeeSuggester) (*Fees, error) {
+	head, err := s.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取最新区块头失败: %w", err)
+	}
+	if head.BaseFee == nil {
+		gasPrice, err := s.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("获取 Gas 价格失败: %w", err)
+		}
+		return &Fees{GasPrice: gasPrice}, nil
+	}
+	tip, err := s.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取小费建议失败: %w", err)
+	}
+	return &Fees{GasTipCap: tip, GasFeeCap: MaxFeePerGas(head.BaseFee, tip)}, nil
+}
+
+// BuildTx 补全 nonce、Gas 上限和费用，返回未签名的交易。
+// 节点支持 EIP-1559 时构造动态费用交易，否则使用 legacy 交易；
+// 不支持 EIP-1559 但 req 带有访问列表时使用 EIP-2930 交易
+func BuildTx(ctx context.Context, s TransactionSender, from common.Address, chainID *big.Int, req *TxRequest) (*types.Transaction, error) {
+	value := req.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	var nonce uint64
+	if req.Nonce != nil {
+		nonce = *req.Nonce
+	} else {
+		pending, err := s.PendingNonceAt(ctx, from)
+		if err != nil {
+			return nil, fmt.Errorf("获取 nonce 失败: %w", err)
+		}
+		nonce = pending
+	}
+
+	gasLimit := req.GasLimit
+	if gasLimit == 0 {
+		estimated, err := s.EstimateGas(ctx, ethereum.CallMsg{From: from, To: req.To, Value: value, Data: req.Data, AccessList: req.AccessList})
+		if err != nil {
+			return nil, fmt.Errorf("估算 Gas 失败（交易可能会 revert）: %w", err)
+		}
+		gasLimit = WithMargin(estimated, req.GasMargin)
+	}
+
+	fees, err := SuggestFees(ctx, s)
+	if err != nil {
+		return nil, err
+	}
+	if fees.GasPrice != nil {
+		if len(req.AccessList) > 0 {
+			return types.NewTx(&types.AccessListTx{ChainID: chainID, Nonce: nonce, To: req.To, GasPrice: fees.GasPrice, Gas: gasLimit, Value: value, Data: req.Data, AccessList: req.AccessList}), nil
+		}
+		return types.NewTx(&types.LegacyTx{Nonce: nonce, To: req.To, GasPrice: fees.GasPrice, Gas: gasLimit, Value: value, Data: req.Data}), nil
+	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:    chainID,
+		Nonce:      nonce,
+		GasTipCap:  fees.GasTipCap,
+		GasFeeCap:  fees.GasFeeCap,
+		Gas:        gasLimit,
+		To:         req.To,
+		Value:      value,
+		Data:       req.Data,
+		AccessList: req.AccessList,
+	}), nil
+}
+
+// SignRequest 补全并签名交易，但不发送
+func SignRequest(ctx context.Context, s TransactionSender, key *ecdsa.PrivateKey, req *TxRequest) (*types.Transaction, error) {
+	chainID, err := s.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链 ID 失败: %w", err)
+	}
+	tx, err := BuildTx(ctx, s, crypto.PubkeyToAddress(key.PublicKey), chainID, req)
+	if err != nil {
+		return nil, err
+	}
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), key)
+}
+
+// Send 补全、签名并发送交易，不等待确认
+func Send(ctx context.Context, s TransactionSender, key *ecdsa.PrivateKey, req *TxRequest) (*types.Transaction, error) {
+	signed, err := SignRequest(ctx, s, key, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.SendTransaction(ctx, signed); err != nil {
+		return nil, fmt.Errorf("发送交易失败: %w", err)
+	}
+	return signed, nil
+}
+
+// TransferETH 发送 ETH 转账，不等待确认
+func TransferETH(ctx context.Context, s TransactionSender, key *ecdsa.PrivateKey, to common.Address, value *big.Int) (*types.Transaction, error) {
+	return Send(ctx, s, key, &TxRequest{To: &to, Value: value})
+}
@@ -0,0 +1,189 @@
+package chain_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+)
+
+var (
+	from = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	to   = common.HexToAddress("0x2000000000000000000000000000000000000002")
+)
+
+func TestWithMargin(t *testing.T) {
+	for _, tc := range []struct {
+		estimated, margin, want uint64
+	}{
+		{21000, 0, 25200}, // 默认 20%
+		{21000, 10, 23100},
+		{50000, 100, 100000},
+		{99, 1, 99}, // 向下取整
+		{0, 0, 0},
+	} {
+		if got := chain.WithMargin(tc.estimated, tc.margin); got != tc.want {
+			t.Errorf("WithMargin(%d, %d) = %d，期望 %d", tc.estimated, tc.margin, got, tc.want)
+		}
+	}
+}
+
+func TestSuggestFees(t *testing.T) {
+	node := newFakeNode()
+	fees, err := chain.SuggestFees(context.Background(), node)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 2 × 10 gwei + 2 gwei，与 MaxFeePerGas 一致
+	if fees.GasPrice != nil || fees.GasTipCap.Cmp(node.tip) != 0 || fees.Max().Cmp(chain.MaxFeePerGas(node.baseFee, node.tip)) != 0 || fees.Max().Int64() != 22e9 {
+		t.Fatalf("EIP-1559 费用不符: %+v", fees)
+	}
+
+	// 不支持 EIP-1559 的节点使用 eth_gasPrice
+	node.baseFee = nil
+	if fees, err = chain.SuggestFees(context.Background(), node); err != nil {
+		t.Fatal(err)
+	}
+	if fees.GasFeeCap != nil || fees.Max().Cmp(node.gasPrice) != 0 {
+		t.Fatalf("legacy 费用不符: %+v", fees)
+	}
+}
+
+func TestBuildTxDynamicFee(t *testing.T) {
+	node := newFakeNode()
+	tx, err := chain.BuildTx(context.Background(), node, from, node.chainID, &chain.TxRequest{To: &to, Value: big.NewInt(5)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Type() != types.DynamicFeeTxType {
+		t.Fatalf("类型 %d，期望 DynamicFee", tx.Type())
+	}
+	// feeCap = 2 × baseFee + tip
+	if want := big.NewInt(22e9); tx.GasFeeCap().Cmp(want) != 0 {
+		t.Errorf("GasFeeCap = %s，期望 %s", tx.GasFeeCap(), want)
+	}
+	if tx.GasTipCap().Cmp(node.tip) != 0 {
+		t.Errorf("GasTipCap = %s，期望 %s", tx.GasTipCap(), node.tip)
+	}
+	if tx.Nonce() != node.nonce || tx.Gas() != 60000 || tx.ChainId().Cmp(node.chainID) != 0 || *tx.To() != to || tx.Value().Int64() != 5 {
+		t.Errorf("字段不符: nonce=%d gas=%d chainID=%s to=%s value=%s", tx.Nonce(), tx.Gas(), tx.ChainId(), tx.To().Hex(), tx.Value())
+	}
+
+	// 估算时带上发送者、目标和金额
+	if len(node.estimated) != 1 || node.estimated[0].From != from || *node.estimated[0].To != to || node.estimated[0].Value.Int64() != 5 {
+		t.Errorf("EstimateGas 收到的调用不符: %+v", node.estimated)
+	}
+}
+
+func TestBuildTxLegacy(t *testing.T) {
+	node := newFakeNode()
+	node.baseFee = nil
+
+	tx, err := chain.BuildTx(context.Background(), node, from, node.chainID, &chain.TxRequest{To: &to})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Type() != types.LegacyTxType || tx.GasPrice().Cmp(node.gasPrice) != 0 {
+		t.Fatalf("类型 %d，GasPrice %s，期望 legacy 交易使用 SuggestGasPrice %s", tx.Type(), tx.GasPrice(), node.gasPrice)
+	}
+	if tx.Value().Sign() != 0 {
+		t.Errorf("未设置 Value 时应为 0，得到 %s", tx.Value())
+	}
+
+	// 带访问列表时退回 EIP-2930 交易
+	list := types.AccessList{{Address: to, StorageKeys: []common.Hash{{}}}}
+	tx, err = chain.BuildTx(context.Background(), node, from, node.chainID, &chain.TxRequest{To: &to, AccessList: list})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Type() != types.AccessListTxType || len(tx.AccessList()) != 1 || tx.GasPrice().Cmp(node.gasPrice) != 0 {
+		t.Fatalf("类型 %d，期望 AccessList 交易", tx.Type())
+	}
+}
+
+func TestBuildTxOverrides(t *testing.T) {
+	node := newFakeNode()
+	nonce := uint64(3)
+	list := types.AccessList{{Address: to}}
+
+	// 指定 nonce 和 Gas 上限时不查询节点
+	tx, err := chain.BuildTx(context.Background(), node, from, node.chainID, &chain.TxRequest{To: &to, Nonce: &nonce, GasLimit: 30000, AccessList: list})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Nonce() != 3 || tx.Gas() != 30000 || len(node.estimated) != 0 {
+		t.Fatalf("nonce=%d gas=%d estimate 调用 %d 次", tx.Nonce(), tx.Gas(), len(node.estimated))
+	}
+	if tx.Type() != types.DynamicFeeTxType || len(tx.AccessList()) != 1 {
+		t.Fatalf("支持 EIP-1559 时访问列表放在动态费用交易中，得到类型 %d", tx.Type())
+	}
+
+	// 自定义余量
+	tx, err = chain.BuildTx(context.Background(), node, from, node.chainID, &chain.TxRequest{To: &to, GasMargin: 50})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Gas() != 75000 {
+		t.Fatalf("Gas = %d，期望 75000", tx.Gas())
+	}
+	if len(node.estimated) != 1 || len(node.estimated[0].AccessList) != 0 {
+		t.Fatalf("EstimateGas 调用不符: %+v", node.estimated)
+	}
+}
+
+func TestBuildTxEstimateError(t *testing.T) {
+	node := newFakeNode()
+	node.estErr = errFake
+	_, err := chain.BuildTx(context.Background(), node, from, node.chainID, &chain.TxRequest{To: &to})
+	if !errors.Is(err, errFake) {
+		t.Fatalf("应包装 EstimateGas 的错误，得到 %v", err)
+	}
+}
+
+func TestSendSignsWithChainID(t *testing.T) {
+	node := newFakeNode()
+	key, _ := crypto.GenerateKey()
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+
+	tx, err := chain.TransferETH(context.Background(), node, key, to, big.NewInt(1e18))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(node.sent) != 1 || node.sent[0].Hash() != tx.Hash() {
+		t.Fatalf("发送了 %d 笔交易", len(node.sent))
+	}
+	got, err := types.Sender(types.LatestSignerForChainID(node.chainID), tx)
+	if err != nil || got != sender {
+		t.Fatalf("恢复出的发送者 %s (%v)，期望 %s", got.Hex(), err, sender.Hex())
+	}
+	if node.estimated[0].From != sender {
+		t.Fatalf("估算使用的发送者 %s，期望 %s", node.estimated[0].From.Hex(), sender.Hex())
+	}
+}
+
+func TestTokenCallData(t *testing.T) {
+	amount := big.NewInt(1234)
+	for name, data := range map[string][]byte{
+		"transfer":     chain.TransferData(to, amount),
+		"approve":      chain.ApproveData(to, amount),
+		"transferFrom": chain.TransferFromData(from, to, amount),
+	} {
+		method := chain.ERC20ABI.Methods[name]
+		if string(data[:4]) != string(method.ID) {
+			t.Errorf("%s 选择器 %x，期望 %x", name, data[:4], method.ID)
+		}
+		args, err := method.Inputs.Unpack(data[4:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := args[len(args)-1].(*big.Int); got.Cmp(amount) != 0 {
+			t.Errorf("%s 金额 %s，期望 %s", name, got, amount)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package chain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/holiman/uint256"
+)
+
+// ErrNoSetCodeSupport 节点没有启用 Prague（EIP-7702）
+var ErrNoSetCodeSupport = errors.New("节点未启用 EIP-7702（需要 Prague 升级）")
+
+// AccountKind 账户类型
+type AccountKind uint8
+
+const (
+	AccountEOA       AccountKind = iota // 没有代码的普通外部账户
+	AccountDelegated                    // 通过 EIP-7702 委托了代码的外部账户
+	AccountContract                     // 合约账户
+)
+
+func (k AccountKind) String() string {
+	switch k {
+	case AccountEOA:
+		return "EOA"
+	case AccountDelegated:
+		return "委托 EOA（EIP-7702）"
+	case AccountContract:
+		return "合约"
+	default:
+		return fmt.Sprintf("Unknown(%d)", uint8(k))
+	}
+}
+
+// Account 账户类型以及委托信息
+type Account struct {
+	Address          common.Address
+	Kind             AccountKind
+	Nonce            uint64
+	CodeSize         int
+	Delegate         common.Address // 仅 AccountDelegated：实现合约地址
+	DelegateCodeSize int            // 实现合约的代码大小，为 0 时调用账户不会执行任何代码
+}
+
+// InspectAccount 按 block（nil 为 latest）时的代码判断账户类型。
+// 委托不会递归：实现合约本身也是委托账户时，调用只会执行到它的委托指示符为止
+func InspectAccount(ctx context.Context, r CodeReader, addr common.Address, block *big.Int) (*Account, error) {
+	code, err := r.CodeAt(ctx, addr, block)
+	if err != nil {
+		return nil, fmt.Errorf("查询 %s 的代码失败: %w", addr.Hex(), err)
+	}
+	nonce, err := r.NonceAt(ctx, addr, block)
+	if err != nil {
+		return nil, fmt.Errorf("查询 %s 的 nonce 失败: %w", addr.Hex(), err)
+	}
+	account := &Account{Address: addr, Nonce: nonce, CodeSize: len(code)}
+	if len(code) == 0 {
+		return account, nil
+	}
+	// 委托指示符固定为 0xef0100 || 实现合约地址（23 字节）
+	delegate, ok := types.ParseDelegation(code)
+	if !ok {
+		account.Kind = AccountContract
+		return account, nil
+	}
+	account.Kind = AccountDelegated
+	account.Delegate = delegate
+	implCode, err := r.CodeAt(ctx, delegate, block)
+	if err != nil {
+		return nil, fmt.Errorf("查询实现合约 %s 的代码失败: %w", delegate.Hex(), err)
+	}
+	account.DelegateCodeSize = len(implCode)
+	return account, nil
+}
+
+// SignAuthorization 用 key 签名一条 EIP-7702 授权：key 对应的账户把代码委托给 impl，
+// impl 为零地址时表示撤销委托、清空代码。nonce 必须等于授权被执行时账户的 nonce；
+// chainID 为 nil 或 0 时授权在任何链上都有效，一般应绑定到具体的链
+func SignAuthorization(key *ecdsa.PrivateKey, chainID *big.Int, impl common.Address, nonce uint64) (types.SetCodeAuthorization, error) {
+	auth := types.SetCodeAuthorization{Address: impl, Nonce: nonce}
+	if chainID != nil && auth.ChainID.SetFromBig(chainID) {
+		return types.SetCodeAuthorization{}, fmt.Errorf("链 ID %s 超出 uint256 范围", chainID)
+	}
+	signed, err := types.SignSetCode(key, auth)
+	if err != nil {
+		return types.SetCodeAuthorization{}, fmt.Errorf("签名授权失败: %w", err)
+	}
+	return signed, nil
+}
+
+// SetCodeTxRequest 待发送的 EIP-7702 交易，零值字段由 BuildSetCodeTx 从节点补全
+type SetCodeTxRequest struct {
+	To             common.Address // SetCode 交易不能创建合约；委托自己时通常发给账户本身
+	Value          *big.Int
+	Data           []byte                       // 授权生效后执行的调用，例如对实现合约方法的调用
+	Authorizations []types.SetCodeAuthorization // 由 SignAuthorization 签名，至少一条
+	GasLimit       uint64                       // 为 0 时使用带授权列表的 EstimateGas 结果加余量
+	Nonce          *uint64                      // 为 nil 时使用 pending nonce
+}
+
+// NewDelegation 构造由 key 对应的账户自己发送的委托交易：把代码委托给 impl，
+// 并在同一笔交易中以 data 调用自己（data 为空时只设置委托）。impl 为零地址时撤销委托。
+// 交易先把发送方的 nonce 加 1 再处理授权，所以自己授权时授权的 nonce 是交易 nonce + 1
+func NewDelegation(ctx context.Context, s TransactionSender, key *ecdsa.PrivateKey, impl common.Address, data []byte) (*SetCodeTxRequest, error) {
+	chainID, err := s.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链 ID 失败: %w", err)
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	nonce, err := s.PendingNonceAt(ctx, from)
+	if err != nil {
+		return nil, fmt.Errorf("获取 nonce 失败: %w", err)
+	}
+	auth, err := SignAuthorization(key, chainID, impl, nonce+1)
+	if err != nil {
+		return nil, err
+	}
+	return &SetCodeTxRequest{To: from, Data: data, Authorizations: []types.SetCodeAuthorization{auth}, Nonce: &nonce}, nil
+}
+
+// NewRevocation 构造撤销 key 对应账户委托的交易，执行后账户恢复为没有代码的普通 EOA
+func NewRevocation(ctx context.Context, s TransactionSender, key *ecdsa.PrivateKey) (*SetCodeTxRequest, error) {
+	return NewDelegation(ctx, s, key, common.Address{}, nil)
+}
+
+// BuildSetCodeTx 补全 nonce、Gas 上限和费用，返回未签名的 SetCode 交易。
+// Gas 估算时带上授权列表，节点必须已启用 Prague
+func BuildSetCodeTx(ctx context.Context, s TransactionSender, from common.Address, chainID *big.Int, req *SetCodeTxRequest) (*types.Transaction, error) {
+	if len(req.Authorizations) == 0 {
+		return nil, errors.New("SetCode 交易至少需要一条授权")
+	}
+	gasLimit := req.GasLimit
+	if gasLimit == 0 {
+		estimated, err := s.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &req.To, Value: req.Value, Data: req.Data, AuthorizationList: req.Authorizations})
+		if err != nil {
+			return nil, fmt.Errorf("估算 Gas 失败（交易可能会 revert）: %w", err)
+		}
+		gasLimit = WithMargin(estimated, 0)
+	}
+
+	// nonce 和费用与 EIP-1559 交易相同，借用 BuildTx 补全
+	base, err := BuildTx(ctx, s, from, chainID, &TxRequest{To: &req.To, Value: req.Value, Data: req.Data, GasLimit: gasLimit, Nonce: req.Nonce})
+	if err != nil {
+		return nil, err
+	}
+	if base.Type() != types.DynamicFeeTxType {
+		return nil, ErrNoSetCodeSupport
+	}
+	return types.NewTx(&types.SetCodeTx{
+		ChainID:   uint256.MustFromBig(chainID),
+		Nonce:     base.Nonce(),
+		GasTipCap: uint256.MustFromBig(base.GasTipCap()),
+		GasFeeCap: uint256.MustFromBig(base.GasFeeCap()),
+		Gas:       base.Gas(),
+		To:        req.To,
+		Value:     uint256.MustFromBig(base.Value()),
+		Data:      req.Data,
+		AuthList:  req.Authorizations,
+	}), nil
+}
+
+// SignSetCodeTx 补全并签名 SetCode 交易，但不发送
+func SignSetCodeTx(ctx context.Context, s TransactionSender, key *ecdsa.PrivateKey, req *SetCodeTxRequest) (*types.Transaction, error) {
+	chainID, err := s.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链 ID 失败: %w", err)
+	}
+	tx, err := BuildSetCodeTx(ctx, s, crypto.PubkeyToAddress(key.PublicKey), chainID, req)
+	if err != nil {
+		return nil, err
+	}
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), key)
+}
+
+// SendSetCodeTx 补全、签名并发送 SetCode 交易，不等待确认
+func SendSetCodeTx(ctx context.Context, s TransactionSender, key *ecdsa.PrivateKey, req *SetCodeTxRequest) (*types.Transaction, error) {
+	signed, err := SignSetCodeTx(ctx, s, key, req)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.SendTransaction(ctx, signed); err != nil {
+		return nil, fmt.Errorf("发送交易失败: %w", err)
+	}
+	return signed, nil
+}
@@ -0,0 +1,199 @@
+package chain_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/simnet"
+	"github.com/dapp-learning/ethclient/util/simnet/contracts"
+)
+
+func TestSignAuthorization(t *testing.T) {
+	key := newKey(t)
+	authority := crypto.PubkeyToAddress(key.PublicKey)
+	impl := common.HexToAddress("0x1234")
+
+	auth, err := chain.SignAuthorization(key, big.NewInt(11155111), impl, 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if auth.ChainID.Uint64() != 11155111 || auth.Address != impl || auth.Nonce != 7 || auth.V > 1 {
+		t.Fatalf("授权字段不符: %+v", auth)
+	}
+	// 签名哈希 = keccak256(0x05 || rlp([chainId, address, nonce]))
+	msg, _ := rlp.EncodeToBytes([]interface{}{big.NewInt(11155111), impl, uint64(7)})
+	if want := crypto.Keccak256Hash(append([]byte{0x05}, msg...)); auth.SigHash() != want {
+		t.Fatalf("SigHash = %s，期望 %s", auth.SigHash().Hex(), want.Hex())
+	}
+	if got, err := auth.Authority(); err != nil || got != authority {
+		t.Fatalf("恢复的授权者 %s (%v)，期望 %s", got.Hex(), err, authority.Hex())
+	}
+
+	// chainID 为 nil 时授权在任何链上有效
+	any, err := chain.SignAuthorization(key, nil, impl, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !any.ChainID.IsZero() {
+		t.Fatalf("ChainID = %s，期望 0", &any.ChainID)
+	}
+	if got, _ := any.Authority(); got != authority {
+		t.Fatalf("恢复的授权者 %s，期望 %s", got.Hex(), authority.Hex())
+	}
+
+	// 篡改 nonce 后恢复出的是另一个地址
+	any.Nonce++
+	if got, err := any.Authority(); err == nil && got == authority {
+		t.Fatal("修改授权内容后不应恢复出原授权者")
+	}
+}
+
+func TestBuildSetCodeTxRequiresPrague(t *testing.T) {
+	node := newFakeNode()
+	auth, err := chain.SignAuthorization(newKey(t), node.chainID, to, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := &chain.SetCodeTxRequest{To: from, Authorizations: []types.SetCodeAuthorization{auth}}
+
+	tx, err := chain.BuildSetCodeTx(context.Background(), node, from, node.chainID, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Type() != types.SetCodeTxType || tx.GasFeeCap().Int64() != 22e9 || tx.Gas() != 60000 || len(tx.SetCodeAuthorizations()) != 1 {
+		t.Fatalf("交易不符: type=%d feeCap=%s gas=%d", tx.Type(), tx.GasFeeCap(), tx.Gas())
+	}
+	// 估算 Gas 时带上授权列表
+	if len(node.estimated) != 1 || len(node.estimated[0].AuthorizationList) != 1 {
+		t.Fatalf("EstimateGas 没有收到授权列表: %+v", node.estimated)
+	}
+
+	if _, err := chain.BuildSetCodeTx(context.Background(), node, from, node.chainID, &chain.SetCodeTxRequest{To: from}); err == nil {
+		t.Fatal("没有授权时应返回错误")
+	}
+	node.baseFee = nil
+	if _, err := chain.BuildSetCodeTx(context.Background(), node, from, node.chainID, req); !errors.Is(err, chain.ErrNoSetCodeSupport) {
+		t.Fatalf("不支持 EIP-1559 的节点应返回 ErrNoSetCodeSupport，得到 %v", err)
+	}
+}
+
+func TestDelegateAndRevoke(t *testing.T) {
+	net, err := simnet.New(&simnet.Options{SkipContracts: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer net.Close()
+	ctx := context.Background()
+	account := net.Accounts[1]
+
+	inspect := func() *chain.Account {
+		t.Helper()
+		info, err := chain.InspectAccount(ctx, net.Client, account.Address, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return info
+	}
+	if info := inspect(); info.Kind != chain.AccountEOA || info.CodeSize != 0 {
+		t.Fatalf("初始账户应为 EOA: %+v", info)
+	}
+
+	impl, _, err := net.Deploy(ctx, net.Accounts[0], contracts.BatchDeployData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := chain.InspectAccount(ctx, net.Client, impl, nil); info.Kind != chain.AccountContract {
+		t.Fatalf("Batch 应为合约账户，得到 %s", info.Kind)
+	}
+
+	// 委托给 Batch，并在同一笔交易里给两个地址各转 1 wei
+	recipients := []common.Address{common.HexToAddress("0xa1"), common.HexToAddress("0xa2")}
+	data, err := contracts.BatchData([]contracts.Call{{To: recipients[0], Value: big.NewInt(1)}, {To: recipients[1], Value: big.NewInt(1)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := chain.NewDelegation(ctx, net.Client, account.Key, impl, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *req.Nonce != 0 || req.Authorizations[0].Nonce != 1 || req.To != account.Address {
+		t.Fatalf("自己委托时授权 nonce 应为交易 nonce + 1: tx=%d auth=%d", *req.Nonce, req.Authorizations[0].Nonce)
+	}
+	tx, err := chain.SendSetCodeTx(ctx, net.Client, account.Key, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt := mine(t, net, tx)
+	if receipt.Type != types.SetCodeTxType {
+		t.Fatalf("收据类型 %d", receipt.Type)
+	}
+
+	// 账户代码变为 0xef0100 || impl
+	code, err := net.Client.CodeAt(ctx, account.Address, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := append([]byte{0xef, 0x01, 0x00}, impl.Bytes()...); !bytes.Equal(code, want) {
+		t.Fatalf("账户代码 %x，期望 %x", code, want)
+	}
+	info := inspect()
+	if info.Kind != chain.AccountDelegated || info.Delegate != impl || info.CodeSize != 23 || info.DelegateCodeSize == 0 || info.Nonce != 2 {
+		t.Fatalf("委托账户信息不符: %+v", info)
+	}
+	for _, r := range recipients {
+		if balance, _ := net.Client.BalanceAt(ctx, r, nil); balance.Int64() != 1 {
+			t.Fatalf("%s 余额 %s，期望 1", r.Hex(), balance)
+		}
+	}
+
+	// 解码链上的交易，列出授权
+	onchain, _, err := net.Client.TransactionByHash(ctx, tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := onchain.MarshalBinary()
+	decoded, err := chain.DecodeRawTx(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Type != types.SetCodeTxType || decoded.From != account.Address || len(decoded.Authorizations) != 1 {
+		t.Fatalf("解码结果不符: %+v", decoded)
+	}
+	if a := decoded.Authorizations[0]; a.Err != nil || a.Authority != account.Address || a.Address != impl {
+		t.Fatalf("授权解码不符: %+v", a)
+	}
+	if !chain.WriteChecks(new(bytes.Buffer), decoded.Check(net.ChainID, nil)) {
+		t.Fatal("有效的 SetCode 交易应通过全部检查")
+	}
+	for _, check := range decoded.Check(big.NewInt(1), nil) {
+		if check.Name == "授权 #1" && check.OK {
+			t.Fatal("授权绑定的链与目标链不一致时应检查失败")
+		}
+	}
+
+	// 撤销委托，账户恢复为普通 EOA
+	req, err = chain.NewRevocation(ctx, net.Client, account.Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if req.Authorizations[0].Address != (common.Address{}) {
+		t.Fatalf("撤销授权的地址应为零地址，得到 %s", req.Authorizations[0].Address.Hex())
+	}
+	tx, err = chain.SendSetCodeTx(ctx, net.Client, account.Key, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mine(t, net, tx)
+	if info := inspect(); info.Kind != chain.AccountEOA || info.CodeSize != 0 || info.Nonce != 4 {
+		t.Fatalf("撤销后账户应为 EOA: %+v", info)
+	}
+}
@@ -0,0 +1,244 @@
+package chain
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/dapp-learning/ethclient/util/output"
+	"github.com/dapp-learning/ethclient/util/sigdb"
+)
+
+// Simulator dry-run 需要的节点接口：在 pending 状态上执行 eth_call 和 eth_estimateGas。
+// *ethclient.Client 实现了该接口；simnet 的 Client 接口没有 EstimateGasAtBlock，可以用 ethclient.NewClient(net.RPC)
+type Simulator interface {
+	TransactionSender
+	PendingCallContract(ctx context.Context, msg ethereum.CallMsg) ([]byte, error)
+	EstimateGasAtBlock(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) (uint64, error)
+}
+
+// pendingBlock EstimateGasAtBlock 使用的 pending 区块号
+var pendingBlock = big.NewInt(int64(rpc.PendingBlockNumber))
+
+// Simulation 交易的 dry-run 结果。交易已签名但没有发送
+type Simulation struct {
+	From common.Address
+	Msg  ethereum.CallMsg   // eth_call / eth_estimateGas 使用的调用参数
+	Tx   *types.Transaction // 已签名的交易；Gas 估算失败且没有指定 Gas 上限时为 nil
+
+	EstimatedGas uint64 // eth_estimateGas 的结果，失败时为 0
+	EstimateErr  error
+	ReturnData   []byte // eth_call 的返回数据，合约创建时为运行时代码
+	CallErr      error  // eth_call 失败（通常是 revert）时的错误
+}
+
+// Simulate 构造并签名交易，在 pending 状态上执行 eth_call 和 eth_estimateGas，但不发送。
+// 交易会 revert 时不返回错误，原因记录在 CallErr / EstimateErr 中
+func Simulate(ctx context.Context, s Simulator, key *ecdsa.PrivateKey, req *TxRequest) (*Simulation, error) {
+	chainID, err := s.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链 ID 失败: %w", err)
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	value := req.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	sim := &Simulation{From: from, Msg: ethereum.CallMsg{From: from, To: req.To, Value: value, Data: req.Data, AccessList: req.AccessList}}
+	sim.call(ctx, s)
+
+	build := *req
+	if build.GasLimit == 0 {
+		if sim.EstimateErr != nil {
+			return sim, nil // 无法确定 Gas 上限，不签名
+		}
+		build.GasLimit = WithMargin(sim.EstimatedGas, req.GasMargin)
+	}
+	tx, err := BuildTx(ctx, s, from, chainID, &build)
+	if err != nil {
+		return nil, err
+	}
+	if sim.Tx, err = types.SignTx(tx, types.LatestSignerForChainID(chainID), key); err != nil {
+		return nil, err
+	}
+	return sim, nil
+}
+
+// SimulateTx 对已签名的交易执行 eth_call 和 eth_estimateGas，但不发送。
+// 发送方按节点的链 ID 恢复，签名使用了其他链 ID 时返回错误
+func SimulateTx(ctx context.Context, s Simulator, tx *types.Transaction) (*Simulation, error) {
+	chainID, err := s.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链 ID 失败: %w", err)
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+	if err != nil {
+		return nil, fmt.Errorf("恢复发送方失败: %w", err)
+	}
+	sim := &Simulation{
+		From: from,
+		Msg:  ethereum.CallMsg{From: from, To: tx.To(), Value: tx.Value(), Data: tx.Data(), AccessList: tx.AccessList()},
+		Tx:   tx,
+	}
+	sim.call(ctx, s)
+	return sim, nil
+}
+
+func (sim *Simulation) call(ctx context.Context, s Simulator) {
+	sim.ReturnData, sim.CallErr = s.PendingCallContract(ctx, sim.Msg)
+	// 与 eth_call 使用同一个状态：交易池中还没打包的交易（如刚发出的 approve）会影响结果
+	sim.EstimatedGas, sim.EstimateErr = s.EstimateGasAtBlock(ctx, sim.Msg, pendingBlock)
+}
+
+// Reverted 交易在 pending 状态上执行是否失败
+func (sim *Simulation) Reverted() bool {
+	return sim.CallErr != nil || sim.EstimateErr != nil
+}
+
+// RawTx 签名交易的编码，即 eth_sendRawTransaction 的参数
+func (sim *Simulation) RawTx() ([]byte, error) {
+	if sim.Tx == nil {
+		return nil, fmt.Errorf("交易未签名")
+	}
+	return sim.Tx.MarshalBinary()
+}
+
+// ContractAddress 合约创建交易部署后的地址，其他交易返回 nil
+func (sim *Simulation) ContractAddress() *common.Address {
+	if sim.Msg.To != nil || sim.Tx == nil {
+		return nil
+	}
+	addr := crypto.CreateAddress(sim.From, sim.Tx.Nonce())
+	return &addr
+}
+
+// MaxCost 最多花费的 ETH：金额 + Gas 上限 × 最高 Gas 价格
+func (sim *Simulation) MaxCost() *big.Int {
+	if sim.Tx == nil {
+		return nil
+	}
+	return sim.Tx.Cost()
+}
+
+// WriteReport 打印 dry-run 报告：交易内容、按 reg 解码的调用和返回值、
+// revert 原因以及签名交易的十六进制编码。reg 为 nil 时使用 sigdb.NewDefault()
+func (sim *Simulation) WriteReport(w io.Writer, reg *sigdb.Registry) {
+	if reg == nil {
+		reg = sigdb.NewDefault()
+	}
+	t := output.NewTable()
+	t.Add("From:", sim.From.Hex())
+	switch {
+	case sim.Msg.To != nil:
+		t.Add("To:", sim.Msg.To.Hex())
+	case sim.ContractAddress() != nil:
+		t.Add("To:", "<合约创建> 部署地址 "+sim.ContractAddress().Hex())
+	default:
+		t.Add("To:", "<合约创建>")
+	}
+	t.Add("金额:", FormatEther(sim.Msg.Value)+" ETH")
+	if call := describeCall(reg, sim.Msg); call != "" {
+		t.Add("调用:", call)
+	}
+
+	if tx := sim.Tx; tx != nil {
+		t.Add("类型:", TxTypeName(tx.Type()))
+		t.Add("Nonce:", strconv.FormatUint(tx.Nonce(), 10))
+		gas := strconv.FormatUint(tx.Gas(), 10)
+		if sim.EstimateErr == nil {
+			gas = fmt.Sprintf("上限 %d（估算 %d）", tx.Gas(), sim.EstimatedGas)
+		}
+		t.Add("Gas:", gas)
+		if list := tx.AccessList(); len(list) > 0 {
+			t.Add("访问列表:", fmt.Sprintf("%d 个地址，%d 个存储槽位", len(list), list.StorageKeys()))
+		}
+		if tx.Type() == types.LegacyTxType || tx.Type() == types.AccessListTxType {
+			t.Add("Gas 价格:", FormatUnits(tx.GasPrice(), 9)+" Gwei")
+		} else {
+			t.Add("最高费用:", FormatUnits(tx.GasFeeCap(), 9)+" Gwei（小费 "+FormatUnits(tx.GasTipCap(), 9)+" Gwei）")
+		}
+		t.Add("最多花费:", FormatEther(sim.MaxCost())+" ETH")
+	} else if sim.EstimateErr == nil {
+		t.Add("Gas:", "估算 "+strconv.FormatUint(sim.EstimatedGas, 10))
+	}
+
+	t.Add("执行结果:", describeResult(reg, sim))
+	if raw, err := sim.RawTx(); err == nil {
+		t.Add("签名交易:", hexutil.Encode(raw))
+	} else {
+		t.Add("签名交易:", "未签名（交易会失败，且没有指定 Gas 上限）")
+	}
+	t.Write(w)
+}
+
+// describeCall 按签名库解码 calldata，如 transfer(to=0x..., amount=1000)
+func describeCall(reg *sigdb.Registry, msg ethereum.CallMsg) string {
+	if len(msg.Data) == 0 {
+		return ""
+	}
+	if msg.To == nil {
+		return fmt.Sprintf("部署代码 %d 字节", len(msg.Data))
+	}
+	entry, args, err := reg.ResolveFunction(msg.Data)
+	if err != nil {
+		return fmt.Sprintf("%s...（%d 字节，未知方法）", hexutil.Encode(msg.Data[:min(len(msg.Data), 4)]), len(msg.Data))
+	}
+	return entry.Name + "(" + formatArgs(entry.Arguments(), args) + ")"
+}
+
+// describeResult eth_call 的结果：成功时解码返回值，失败时解释 revert 原因
+func describeResult(reg *sigdb.Registry, sim *Simulation) string {
+	if err := sim.CallErr; err != nil {
+		return "revert: " + reg.DescribeError(err)
+	}
+	if err := sim.EstimateErr; err != nil {
+		return "Gas 估算失败: " + reg.DescribeError(err)
+	}
+	if sim.Msg.To == nil {
+		return fmt.Sprintf("成功，运行时代码 %d 字节", len(sim.ReturnData))
+	}
+	return describeReturn(reg, sim.Msg.Data, sim.ReturnData)
+}
+
+// describeReturn 按 calldata 对应方法的 outputs 解码返回值
+func describeReturn(reg *sigdb.Registry, input, ret []byte) string {
+	if len(input) < 4 {
+		return "成功"
+	}
+	entry, _, err := reg.ResolveFunction(input)
+	if err != nil || entry.Method() == nil || len(entry.Method().Outputs) == 0 {
+		if len(ret) == 0 {
+			return "成功"
+		}
+		return "成功，返回 " + hexutil.Encode(ret)
+	}
+	values, err := entry.Method().Outputs.Unpack(ret)
+	if err != nil {
+		return "成功，返回 " + hexutil.Encode(ret)
+	}
+	return "成功，返回 (" + formatArgs(entry.Method().Outputs, values) + ")"
+}
+
+// formatArgs 以 名称=值 的形式显示参数，没有名称时只显示值
+func formatArgs(inputs []abi.Argument, values []interface{}) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = output.Text(v)
+		if i < len(inputs) && inputs[i].Name != "" {
+			parts[i] = inputs[i].Name + "=" + parts[i]
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,95 @@
+package chain_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/sigdb"
+	"github.com/dapp-learning/ethclient/util/simnet/contracts"
+)
+
+// report 生成 dry-run 报告，reg 为 nil 时使用内置签名
+func report(sim *chain.Simulation, reg *sigdb.Registry) string {
+	var b strings.Builder
+	sim.WriteReport(&b, reg)
+	return b.String()
+}
+
+func TestSimulateDecodesReturn(t *testing.T) {
+	net := newNet(t)
+	ctx := context.Background()
+	client := ethclient.NewClient(net.RPC)
+	alice, bob := net.Accounts[0], net.Accounts[1]
+
+	// transfer 的返回值来自内置签名 transfer(address to, uint256 amount) returns (bool)
+	sim, err := chain.Simulate(ctx, client, alice.Key, &chain.TxRequest{To: &net.Token, Data: chain.TransferData(bob.Address, ether)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sim.Reverted() || sim.Tx == nil {
+		t.Fatalf("transfer 的 dry-run 失败: %v / %v", sim.CallErr, sim.EstimateErr)
+	}
+	out := report(sim, nil)
+	for _, want := range []string{"transfer(to=" + bob.Address.Hex(), "成功，返回 (true)"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("报告中没有 %q:\n%s", want, out)
+		}
+	}
+
+	// getItem 的返回值来自 Store 的 ABI
+	storeABI, err := abi.JSON(strings.NewReader(contracts.StoreABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, value := common.HexToHash("0x2a"), common.HexToHash("0x1234")
+	input, _ := storeABI.Pack("setItem", key, value)
+	if _, err := net.Send(ctx, alice, &net.Store, nil, input); err != nil {
+		t.Fatal(err)
+	}
+	reg := sigdb.New()
+	if _, err := reg.LoadABI("Store.abi", strings.NewReader(contracts.StoreABI)); err != nil {
+		t.Fatal(err)
+	}
+	input, _ = storeABI.Pack("getItem", key)
+	if sim, err = chain.Simulate(ctx, client, alice.Key, &chain.TxRequest{To: &net.Store, Data: input}); err != nil {
+		t.Fatal(err)
+	}
+	if out, want := report(sim, reg), "成功，返回 ("+value.Hex()+")"; !strings.Contains(out, want) {
+		t.Errorf("报告中没有 %q:\n%s", want, out)
+	}
+}
+
+func TestSimulateUsesPendingState(t *testing.T) {
+	net := newNet(t)
+	ctx := context.Background()
+	client := ethclient.NewClient(net.RPC)
+	alice, bob, carol := net.Accounts[0], net.Accounts[1], net.Accounts[2]
+
+	// bob 的代币还在交易池中，已打包的状态上 bob 没有余额
+	if _, err := chain.TransferToken(ctx, net.Client, alice.Key, net.Token, bob.Address, ether); err != nil {
+		t.Fatal(err)
+	}
+	req := &chain.TxRequest{To: &net.Token, Data: chain.TransferData(carol.Address, ether)}
+	sim, err := chain.Simulate(ctx, client, bob.Key, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sim.Reverted() {
+		t.Fatalf("pending 状态上 bob 有余额，dry-run 不应失败: %v / %v", sim.CallErr, sim.EstimateErr)
+	}
+	if sim.Tx == nil || sim.Tx.Gas() != chain.WithMargin(sim.EstimatedGas, 0) {
+		t.Fatal("应按 pending 状态的估算结果签名")
+	}
+
+	// 打包前在 latest 状态上估算会失败，说明上面的结果来自 pending
+	if _, err := client.EstimateGasAtBlock(ctx, sim.Msg, nil); err == nil {
+		t.Fatal("latest 状态上 bob 没有余额，估算应失败")
+	}
+	net.Commit()
+}
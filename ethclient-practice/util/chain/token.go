@@ -0,0 +1,160 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultDecimals 代币未实现 decimals() 时使用的小数位数
+const DefaultDecimals = 18
+
+const erc20JSON = `[
+{"type":"function","name":"name","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+{"type":"function","name":"symbol","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+{"type":"function","name":"decimals","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint8"}]},
+{"type":"function","name":"totalSupply","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},
+{"type":"function","name":"balanceOf","stateMutability":"view","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+{"type":"function","name":"allowance","stateMutability":"view","inputs":[{"name":"owner","type":"address"},{"name":"spender","type":"address"}],"outputs":[{"name":"","type":"uint256"}]},
+{"type":"function","name":"transfer","stateMutability":"nonpayable","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+{"type":"function","name":"approve","stateMutability":"nonpayable","inputs":[{"name":"spender","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]},
+{"type":"function","name":"transferFrom","stateMutability":"nonpayable","inputs":[{"name":"from","type":"address"},{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}]}
+]`
+
+// ERC20ABI 标准 ERC20 接口中课程用到的方法
+var ERC20ABI = mustParseABI(erc20JSON)
+
+// Token ERC20 代币信息
+type Token struct {
+	Address  common.Address
+	Name     string
+	Symbol   string
+	Decimals uint8
+}
+
+// GetToken 查询代币的名称、符号和小数位数。
+// name / symbol 是可选接口，查询失败时留空；decimals 失败时使用 DefaultDecimals。
+// 三者都查询失败时认为地址不是 ERC20 合约
+func GetToken(ctx context.Context, c ContractCaller, token common.Address) (*Token, error) {
+	info := &Token{Address: token, Decimals: DefaultDecimals}
+	out, err := call(ctx, c, token, "decimals")
+	if err == nil {
+		v, unpackErr := ERC20ABI.Unpack("decimals", out)
+		if unpackErr != nil {
+			return nil, fmt.Errorf("解析 decimals 返回值失败: %w", unpackErr)
+		}
+		info.Decimals = v[0].(uint8)
+	}
+	info.Name = callString(ctx, c, token, "name")
+	info.Symbol = callString(ctx, c, token, "symbol")
+	if err != nil && info.Name == "" && info.Symbol == "" {
+		return nil, err
+	}
+	return info, nil
+}
+
+// TokenBalance 查询 owner 的代币余额（最小单位）
+func TokenBalance(ctx context.Context, c ContractCaller, token, owner common.Address) (*big.Int, error) {
+	return callUint(ctx, c, token, "balanceOf", owner)
+}
+
+// TokenAllowance 查询 owner 授权给 spender 的额度（最小单位）
+func TokenAllowance(ctx context.Context, c ContractCaller, token, owner, spender common.Address) (*big.Int, error) {
+	return callUint(ctx, c, token, "allowance", owner, spender)
+}
+
+// TokenTotalSupply 查询代币总量（最小单位）
+func TokenTotalSupply(ctx context.Context, c ContractCaller, token common.Address) (*big.Int, error) {
+	return callUint(ctx, c, token, "totalSupply")
+}
+
+// TransferData transfer(to, amount) 的调用数据
+func TransferData(to common.Address, amount *big.Int) []byte {
+	data, _ := ERC20ABI.Pack("transfer", to, amount)
+	return data
+}
+
+// ApproveData approve(spender, amount) 的调用数据
+func ApproveData(spender common.Address, amount *big.Int) []byte {
+	data, _ := ERC20ABI.Pack("approve", spender, amount)
+	return data
+}
+
+// TransferFromData transferFrom(from, to, amount) 的调用数据
+func TransferFromData(from, to common.Address, amount *big.Int) []byte {
+	data, _ := ERC20ABI.Pack("transferFrom", from, to, amount)
+	return data
+}
+
+// TransferToken 发送代币转账交易，不等待确认
+func TransferToken(ctx context.Context, s TransactionSender, key *ecdsa.PrivateKey, token, to common.Address, amount *big.Int) (*types.Transaction, error) {
+	return Send(ctx, s, key, &TxRequest{To: &token, Data: TransferData(to, amount)})
+}
+
+// ApproveToken 发送授权交易，不等待确认
+func ApproveToken(ctx context.Context, s TransactionSender, key *ecdsa.PrivateKey, token, spender common.Address, amount *big.Int) (*types.Transaction, error) {
+	return Send(ctx, s, key, &TxRequest{To: &token, Data: ApproveData(spender, amount)})
+}
+
+// TransferTokenFrom 使用授权额度把 from 的代币转给 to，不等待确认
+func TransferTokenFrom(ctx context.Context, s TransactionSender, key *ecdsa.PrivateKey, token, from, to common.Address, amount *big.Int) (*types.Transaction, error) {
+	return Send(ctx, s, key, &TxRequest{To: &token, Data: TransferFromData(from, to, amount)})
+}
+
+func call(ctx context.Context, c ContractCaller, token common.Address, method string, args ...interface{}) ([]byte, error) {
+	data, err := ERC20ABI.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	out, err := c.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("调用 %s.%s 失败: %w", token.Hex(), method, err)
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("调用 %s.%s 没有返回数据，地址可能不是 ERC20 合约", token.Hex(), method)
+	}
+	return out, nil
+}
+
+func callUint(ctx context.Context, c ContractCaller, token common.Address, method string, args ...interface{}) (*big.Int, error) {
+	out, err := call(ctx, c, token, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	v, err := ERC20ABI.Unpack(method, out)
+	if err != nil {
+		return nil, fmt.Errorf("解析 %s 返回值失败: %w", method, err)
+	}
+	return v[0].(*big.Int), nil
+}
+
+// callString 读取 name / symbol。早期代币（如 MKR）返回 bytes32 而不是 string
+func callString(ctx context.Context, c ContractCaller, token common.Address, method string) string {
+	out, err := call(ctx, c, token, method)
+	if err != nil {
+		return ""
+	}
+	if v, err := ERC20ABI.Unpack(method, out); err == nil {
+		return v[0].(string)
+	}
+	if len(out) == 32 {
+		return string(bytes.TrimRight(out, "\x00"))
+	}
+	return ""
+}
+
+func mustParseABI(raw string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(raw))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
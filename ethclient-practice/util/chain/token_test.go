@@ -0,0 +1,114 @@
+package chain_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+)
+
+func newKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// signLegacy 签名一笔 21000 Gas 的 legacy 转账
+func signLegacy(t *testing.T, key *ecdsa.PrivateKey, chainID *big.Int, nonce uint64) *types.Transaction {
+	t.Helper()
+	tx, err := types.SignTx(types.NewTx(&types.LegacyTx{Nonce: nonce, To: &to, Gas: 21000, GasPrice: big.NewInt(1e9), Value: big.NewInt(1)}), types.LatestSignerForChainID(chainID), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tx
+}
+
+// stub 登记 method 的返回值
+func stub(node *fakeNode, method string, out []byte) {
+	var selector [4]byte
+	copy(selector[:], chain.ERC20ABI.Methods[method].ID)
+	node.calls[selector] = out
+}
+
+func TestGetToken(t *testing.T) {
+	node := newFakeNode()
+	token := common.HexToAddress("0x7070")
+	name, _ := chain.ERC20ABI.Methods["name"].Outputs.Pack("Wrapped Ether")
+	symbol, _ := chain.ERC20ABI.Methods["symbol"].Outputs.Pack("WETH")
+	decimals, _ := chain.ERC20ABI.Methods["decimals"].Outputs.Pack(uint8(6))
+	stub(node, "name", name)
+	stub(node, "symbol", symbol)
+	stub(node, "decimals", decimals)
+
+	info, err := chain.GetToken(context.Background(), node, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Address != token || info.Name != "Wrapped Ether" || info.Symbol != "WETH" || info.Decimals != 6 {
+		t.Fatalf("代币信息不符: %+v", info)
+	}
+}
+
+func TestGetTokenBytes32(t *testing.T) {
+	// 早期代币（如 MKR）的 name / symbol 返回 bytes32，且没有 decimals()
+	node := newFakeNode()
+	stub(node, "name", common.RightPadBytes([]byte("Maker"), 32))
+	stub(node, "symbol", common.RightPadBytes([]byte("MKR"), 32))
+
+	info, err := chain.GetToken(context.Background(), node, common.HexToAddress("0x9f8f"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "Maker" || info.Symbol != "MKR" || info.Decimals != chain.DefaultDecimals {
+		t.Fatalf("代币信息不符: %+v", info)
+	}
+}
+
+func TestGetTokenNotERC20(t *testing.T) {
+	node := newFakeNode()
+	if _, err := chain.GetToken(context.Background(), node, common.HexToAddress("0xdead")); !errors.Is(err, errFake) {
+		t.Fatalf("不是 ERC20 合约时应返回错误，得到 %v", err)
+	}
+
+	// 没有返回数据（普通账户）也算失败
+	stub(node, "balanceOf", nil)
+	if _, err := chain.TokenBalance(context.Background(), node, common.HexToAddress("0xdead"), from); err == nil {
+		t.Fatal("空返回值应报错")
+	}
+}
+
+func TestTokenBalance(t *testing.T) {
+	node := newFakeNode()
+	out, _ := chain.ERC20ABI.Methods["balanceOf"].Outputs.Pack(big.NewInt(42))
+	stub(node, "balanceOf", out)
+	got, err := chain.TokenBalance(context.Background(), node, common.HexToAddress("0x7070"), from)
+	if err != nil || got.Int64() != 42 {
+		t.Fatalf("TokenBalance = %v (%v)", got, err)
+	}
+}
+
+func TestGetBalances(t *testing.T) {
+	node := newFakeNode()
+	node.balances[from] = big.NewInt(3)
+	node.balances[to] = big.NewInt(4)
+	missing := common.HexToAddress("0x3")
+
+	balances := chain.GetBalances(context.Background(), node, []common.Address{from, missing, to}, nil)
+	if len(balances) != 3 || balances[1].Err == nil || balances[1].Wei != nil || balances[0].Wei.Int64() != 3 {
+		t.Fatalf("结果不符: %+v", balances)
+	}
+	// 失败的地址不计入总额
+	if total := chain.TotalBalance(balances); total.Int64() != 7 {
+		t.Fatalf("TotalBalance = %s，期望 7", total)
+	}
+}
@@ -0,0 +1,256 @@
+package chain
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/dapp-learning/ethclient/util/sigdb"
+)
+
+// CallFrame callTracer 返回的一层调用。Calls 是这一层发起的子调用，按执行顺序排列
+type CallFrame struct {
+	Type         string          `json:"type"` // CALL / STATICCALL / DELEGATECALL / CREATE / CREATE2 / SELFDESTRUCT
+	From         common.Address  `json:"from"`
+	To           *common.Address `json:"to,omitempty"`
+	Value        *hexutil.Big    `json:"value,omitempty"`
+	Gas          hexutil.Uint64  `json:"gas"`
+	GasUsed      hexutil.Uint64  `json:"gasUsed"`
+	Input        hexutil.Bytes   `json:"input"`
+	Output       hexutil.Bytes   `json:"output,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	RevertReason string          `json:"revertReason,omitempty"` // 节点能解码 Error(string) 时才有
+	Calls        []*CallFrame    `json:"calls,omitempty"`
+}
+
+// AccountState prestateTracer 返回的账户状态，diff 模式下只包含发生变化的字段
+type AccountState struct {
+	Balance *hexutil.Big                `json:"balance,omitempty"`
+	Nonce   uint64                      `json:"nonce,omitempty"`
+	Code    hexutil.Bytes               `json:"code,omitempty"`
+	Storage map[common.Hash]common.Hash `json:"storage,omitempty"`
+}
+
+// StateDiff prestateTracer diff 模式的结果：Pre 为执行前被修改账户的状态，Post 为修改后的值
+type StateDiff struct {
+	Pre  map[common.Address]*AccountState `json:"pre"`
+	Post map[common.Address]*AccountState `json:"post"`
+}
+
+var (
+	callTracerConfig     = map[string]interface{}{"tracer": "callTracer"}
+	prestateTracerConfig = map[string]interface{}{"tracer": "prestateTracer", "tracerConfig": map[string]interface{}{"diffMode": true}}
+)
+
+// TraceTransaction 用 debug_traceTransaction 和 callTracer 重放已打包的交易，返回调用树。
+// 需要节点开放 debug 命名空间，并保留交易所在区块的状态
+func TraceTransaction(ctx context.Context, c RPCCaller, hash common.Hash) (*CallFrame, error) {
+	frame := new(CallFrame)
+	if err := c.CallContext(ctx, frame, "debug_traceTransaction", hash, callTracerConfig); err != nil {
+		return nil, fmt.Errorf("debug_traceTransaction 失败: %w", err)
+	}
+	return frame, nil
+}
+
+// TraceCall 用 debug_traceCall 和 callTracer 在 block（nil 为 latest）上执行调用，返回调用树。
+// state 和 blockOverrides 与 CallWithOverrides 相同，可以为 nil
+func TraceCall(ctx context.Context, c RPCCaller, msg ethereum.CallMsg, block *big.Int, state StateOverride, blockOverrides *BlockOverrides) (*CallFrame, error) {
+	frame := new(CallFrame)
+	if err := traceCall(ctx, c, frame, callTracerConfig, msg, block, state, blockOverrides); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+// TraceTransactionDiff 用 prestateTracer 的 diff 模式获取交易修改的状态
+func TraceTransactionDiff(ctx context.Context, c RPCCaller, hash common.Hash) (*StateDiff, error) {
+	diff := new(StateDiff)
+	if err := c.CallContext(ctx, diff, "debug_traceTransaction", hash, prestateTracerConfig); err != nil {
+		return nil, fmt.Errorf("debug_traceTransaction 失败: %w", err)
+	}
+	return diff, nil
+}
+
+// TraceCallDiff 用 prestateTracer 的 diff 模式获取调用会修改的状态
+func TraceCallDiff(ctx context.Context, c RPCCaller, msg ethereum.CallMsg, block *big.Int, state StateOverride, blockOverrides *BlockOverrides) (*StateDiff, error) {
+	diff := new(StateDiff)
+	if err := traceCall(ctx, c, diff, prestateTracerConfig, msg, block, state, blockOverrides); err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+func traceCall(ctx context.Context, c RPCCaller, result interface{}, tracer map[string]interface{}, msg ethereum.CallMsg, block *big.Int, state StateOverride, blockOverrides *BlockOverrides) error {
+	if err := state.Validate(); err != nil {
+		return err
+	}
+	config := make(map[string]interface{}, len(tracer)+2)
+	for k, v := range tracer {
+		config[k] = v
+	}
+	if len(state) > 0 {
+		config["stateOverrides"] = state
+	}
+	if blockOverrides != nil {
+		config["blockOverrides"] = blockOverrides
+	}
+	if err := c.CallContext(ctx, result, "debug_traceCall", callArg(msg), blockArg(block), config); err != nil {
+		return fmt.Errorf("debug_traceCall 失败: %w", err)
+	}
+	return nil
+}
+
+// Failed 这一层调用是否失败（revert、out of gas 等）
+func (f *CallFrame) Failed() bool {
+	return f.Error != ""
+}
+
+// RevertPoint 失败的源头：沿着把同样的 revert 数据向上传递的最后一个子调用往下找。
+// 子调用失败但被调用方捕获、之后又因为其他原因失败时，返回调用方自己。调用成功时返回 nil
+func (f *CallFrame) RevertPoint() *CallFrame {
+	if !f.Failed() {
+		return nil
+	}
+	if n := len(f.Calls); n > 0 {
+		last := f.Calls[n-1]
+		if last.Failed() && bytes.Equal(last.Output, f.Output) {
+			return last.RevertPoint()
+		}
+	}
+	return f
+}
+
+// WriteTree 以缩进的调用树打印 callTracer 结果：每一层显示调用类型、目标、
+// 按 reg 解码的方法和参数、转账金额、Gas，以及返回值或失败原因，并标出 revert 发生处。
+// reg 为 nil 时使用 sigdb.NewDefault()
+func (f *CallFrame) WriteTree(w io.Writer, reg *sigdb.Registry) {
+	if reg == nil {
+		reg = sigdb.NewDefault()
+	}
+	f.writeTree(w, reg, f.RevertPoint(), "", "")
+}
+
+func (f *CallFrame) writeTree(w io.Writer, reg *sigdb.Registry, revertPoint *CallFrame, first, rest string) {
+	target := "<合约创建>"
+	if f.To != nil {
+		target = f.To.Hex()
+	}
+	line := f.Type + " " + target
+	if call := describeCall(reg, ethereum.CallMsg{To: f.To, Data: f.Input}); call != "" {
+		line += " " + call
+	}
+	fmt.Fprintln(w, first+line)
+
+	details := []string{fmt.Sprintf("Gas %d / %d", f.GasUsed, f.Gas)}
+	if f.Value != nil && f.Value.ToInt().Sign() > 0 {
+		details = append(details, FormatEther(f.Value.ToInt())+" ETH")
+	}
+	fmt.Fprintln(w, rest+"  "+strings.Join(details, "  "))
+	fmt.Fprintln(w, rest+"  "+f.describeResult(reg, f == revertPoint))
+
+	for i, child := range f.Calls {
+		if i == len(f.Calls)-1 {
+			child.writeTree(w, reg, revertPoint, rest+"└─ ", rest+"   ")
+		} else {
+			child.writeTree(w, reg, revertPoint, rest+"├─ ", rest+"│  ")
+		}
+	}
+}
+
+// describeResult 一层调用的返回值或失败原因
+func (f *CallFrame) describeResult(reg *sigdb.Registry, origin bool) string {
+	if !f.Failed() {
+		if f.To == nil {
+			return fmt.Sprintf("✓ 成功，运行时代码 %d 字节", len(f.Output))
+		}
+		return "✓ " + describeReturn(reg, f.Input, f.Output)
+	}
+	s := "✗ " + f.Error
+	if reason, err := reg.RevertReason(f.Output); err == nil {
+		s += ": " + reason
+	} else if f.RevertReason != "" {
+		s += ": " + f.RevertReason
+	}
+	if origin {
+		s += "  ← revert 发生处"
+	}
+	return s
+}
+
+// WriteDiff 按地址打印 prestateTracer 的状态变化：余额、nonce、代码和存储槽位
+func (d *StateDiff) WriteDiff(w io.Writer) {
+	seen := make(map[common.Address]bool)
+	var addrs []common.Address
+	for _, m := range []map[common.Address]*AccountState{d.Pre, d.Post} {
+		for addr := range m {
+			if !seen[addr] {
+				seen[addr] = true
+				addrs = append(addrs, addr)
+			}
+		}
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].Cmp(addrs[j]) < 0 })
+
+	for _, addr := range addrs {
+		pre, post := d.Pre[addr], d.Post[addr]
+		fmt.Fprintln(w, addr.Hex())
+		if post == nil {
+			fmt.Fprintln(w, "  账户被删除")
+			continue
+		}
+		if pre == nil {
+			pre = new(AccountState)
+		}
+		if post.Balance != nil {
+			delta := new(big.Int).Sub(post.Balance.ToInt(), balanceOf(pre))
+			fmt.Fprintf(w, "  余额   %s → %s ETH（%s）\n", FormatEther(balanceOf(pre)), FormatEther(post.Balance.ToInt()), signedEther(delta))
+		}
+		if post.Nonce != 0 && post.Nonce != pre.Nonce {
+			fmt.Fprintf(w, "  nonce  %d → %d\n", pre.Nonce, post.Nonce)
+		}
+		if len(post.Code) > 0 && !bytes.Equal(post.Code, pre.Code) {
+			fmt.Fprintf(w, "  代码   %d 字节\n", len(post.Code))
+		}
+		slots := make(map[common.Hash]bool)
+		for slot := range pre.Storage {
+			slots[slot] = true
+		}
+		for slot := range post.Storage {
+			slots[slot] = true
+		}
+		keys := make([]common.Hash, 0, len(slots))
+		for slot := range slots {
+			keys = append(keys, slot)
+		}
+		sort.Slice(keys, func(i, j int) bool { return keys[i].Cmp(keys[j]) < 0 })
+		for _, slot := range keys {
+			from, to := pre.Storage[slot], post.Storage[slot] // 不存在的槽位为 0
+			if from != to {
+				fmt.Fprintf(w, "  槽位   %s\n         %s → %s\n", slot.Hex(), from.Hex(), to.Hex())
+			}
+		}
+	}
+}
+
+func balanceOf(a *AccountState) *big.Int {
+	if a.Balance == nil {
+		return new(big.Int)
+	}
+	return a.Balance.ToInt()
+}
+
+// signedEther 带符号的 ETH 数量，如 +1.5 ETH / -0.0021 ETH
+func signedEther(wei *big.Int) string {
+	if wei.Sign() < 0 {
+		return "-" + FormatEther(new(big.Int).Neg(wei)) + " ETH"
+	}
+	return "+" + FormatEther(wei) + " ETH"
+}
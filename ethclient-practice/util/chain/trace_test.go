@@ -0,0 +1,335 @@
+package chain_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/sigdb"
+	"github.com/dapp-learning/ethclient/util/simnet"
+	"github.com/dapp-learning/ethclient/util/simnet/contracts"
+)
+
+// testdata/trace 下的 .json 是 geth callTracer / prestateTracer 对 simnet 交易的原始返回，
+// .tree / .diff 是对应的 WriteTree / WriteDiff 输出。
+// 修改录制场景或输出格式后运行 go test -run Trace -update 重新生成
+var update = flag.Bool("update", false, "用 simnet 重新录制 testdata/trace 下的样本")
+
+const insufficientBalanceSig = "error InsufficientBalance(uint256 available, uint256 required)"
+
+// traceScenario 在新的 simnet 上发送一笔交易，返回交易哈希
+type traceScenario func(t *testing.T, net *simnet.Net) common.Hash
+
+// forwardedTransfer Accounts[0] 经 hops 层转发合约调用 token.transfer(Accounts[1], amount)，
+// 最内层的转发合约（token 看到的 msg.sender）持有 500 SIM
+func forwardedTransfer(amount int64, hops int) traceScenario {
+	return func(t *testing.T, net *simnet.Net) common.Hash {
+		ctx := context.Background()
+		holder := deployForwarder(t, net, net.Token)
+		if _, err := net.Send(ctx, net.Accounts[0], &net.Token, nil, chain.TransferData(holder, big.NewInt(500))); err != nil {
+			t.Fatal(err)
+		}
+		outer := holder
+		for i := 1; i < hops; i++ {
+			outer = deployForwarder(t, net, outer)
+		}
+		return sendFixedGas(t, net, outer, chain.TransferData(net.Accounts[1].Address, big.NewInt(amount)))
+	}
+}
+
+// customError Accounts[0] 经转发合约调用一个总是以 InsufficientBalance(500, 1000) revert 的合约
+func customError(t *testing.T, net *simnet.Net) common.Hash {
+	data := crypto.Keccak256([]byte("InsufficientBalance(uint256,uint256)"))[:4]
+	data = append(data, common.LeftPadBytes(big.NewInt(500).Bytes(), 32)...)
+	data = append(data, common.LeftPadBytes(big.NewInt(1000).Bytes(), 32)...)
+	reverter, _, err := net.Deploy(context.Background(), net.Accounts[0], deployRuntime(revertRuntime(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sendFixedGas(t, net, deployForwarder(t, net, reverter), chain.TransferData(net.Accounts[1].Address, big.NewInt(1000)))
+}
+
+var traceScenarios = map[string]traceScenario{
+	"forwarded-transfer": forwardedTransfer(100, 1),
+	"nested-revert":      forwardedTransfer(1000, 2),
+	"custom-error":       customError,
+}
+
+func deployForwarder(t *testing.T, net *simnet.Net, target common.Address) common.Address {
+	t.Helper()
+	addr, _, err := net.Deploy(context.Background(), net.Accounts[0], contracts.ForwarderDeployData(target))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return addr
+}
+
+// sendFixedGas 指定 Gas 上限发送交易（会 revert 的交易无法估算 Gas）并出块
+func sendFixedGas(t *testing.T, net *simnet.Net, to common.Address, data []byte) common.Hash {
+	t.Helper()
+	tx, err := chain.Send(context.Background(), net.Client, net.Accounts[0].Key, &chain.TxRequest{To: &to, Data: data, GasLimit: 200000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	net.Commit()
+	return tx.Hash()
+}
+
+// revertRuntime 把 data 写入内存后 REVERT
+func revertRuntime(data []byte) []byte {
+	var code []byte
+	padded := common.RightPadBytes(data, (len(data)+31)/32*32)
+	for i := 0; i < len(padded); i += 32 {
+		code = append(code, byte(vm.PUSH32))
+		code = append(code, padded[i:i+32]...)
+		code = append(code, byte(vm.PUSH1), byte(i), byte(vm.MSTORE))
+	}
+	return append(code, byte(vm.PUSH1), byte(len(data)), byte(vm.PUSH1), 0, byte(vm.REVERT))
+}
+
+// deployRuntime 把 runtime 原样作为合约代码返回的 initcode
+func deployRuntime(runtime []byte) []byte {
+	n := len(runtime)
+	code := []byte{byte(vm.PUSH2), byte(n >> 8), byte(n), byte(vm.DUP1), byte(vm.PUSH1), 0x0c, byte(vm.PUSH1), 0, byte(vm.CODECOPY), byte(vm.PUSH1), 0, byte(vm.RETURN)}
+	return append(code, runtime...)
+}
+
+// record 在新的 simnet 上运行场景，把 callTracer 和 prestateTracer 的原始返回写入 testdata
+func record(t *testing.T, name string) {
+	t.Helper()
+	net, err := simnet.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer net.Close()
+	hash := traceScenarios[name](t, net)
+
+	for suffix, tracer := range map[string]map[string]interface{}{
+		".json":      {"tracer": "callTracer"},
+		".diff.json": {"tracer": "prestateTracer", "tracerConfig": map[string]interface{}{"diffMode": true}},
+	} {
+		var raw json.RawMessage
+		if err := net.RPC.CallContext(context.Background(), &raw, "debug_traceTransaction", hash, tracer); err != nil {
+			t.Fatal(err)
+		}
+		var out bytes.Buffer
+		if err := json.Indent(&out, raw, "", "  "); err != nil {
+			t.Fatal(err)
+		}
+		out.WriteByte('\n')
+		writeFixture(t, name+suffix, out.Bytes())
+	}
+}
+
+func fixturePath(name string) string {
+	return filepath.Join("testdata", "trace", name)
+}
+
+func writeFixture(t *testing.T, name string, data []byte) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(fixturePath(name)), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(fixturePath(name), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// fakeRPC 回放录制的 debug_traceTransaction 结果
+type fakeRPC struct {
+	t    *testing.T
+	name string
+}
+
+func (f fakeRPC) CallContext(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	if method != "debug_traceTransaction" {
+		f.t.Fatalf("意外的 RPC 调用 %s", method)
+	}
+	file := f.name + ".json"
+	if config := args[1].(map[string]interface{}); config["tracer"] == "prestateTracer" {
+		file = f.name + ".diff.json"
+	}
+	data, err := os.ReadFile(fixturePath(file))
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, result)
+}
+
+// loadTrace 读取样本（-update 时先重新录制）并经 TraceTransaction / TraceTransactionDiff 解码
+func loadTrace(t *testing.T, name string) (*chain.CallFrame, *chain.StateDiff) {
+	t.Helper()
+	if *update {
+		record(t, name)
+	}
+	rpc := fakeRPC{t: t, name: name}
+	frame, err := chain.TraceTransaction(context.Background(), rpc, common.Hash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	diff, err := chain.TraceTransactionDiff(context.Background(), rpc, common.Hash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return frame, diff
+}
+
+// checkGolden 比较输出与 testdata 中的 golden 文件，-update 时重写
+func checkGolden(t *testing.T, name string, got []byte) {
+	t.Helper()
+	if *update {
+		writeFixture(t, name, got)
+		return
+	}
+	want, err := os.ReadFile(fixturePath(name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s 输出不一致\n--- 得到 ---\n%s\n--- 期望 ---\n%s", name, got, want)
+	}
+}
+
+func TestTraceForwardedTransfer(t *testing.T) {
+	frame, diff := loadTrace(t, "forwarded-transfer")
+
+	if frame.Failed() || frame.RevertPoint() != nil {
+		t.Fatalf("成功的交易不应有失败点: %q", frame.Error)
+	}
+	if frame.Type != "CALL" || len(frame.Calls) != 1 {
+		t.Fatalf("根调用 %s，子调用 %d 个", frame.Type, len(frame.Calls))
+	}
+	inner := frame.Calls[0]
+	if inner.Type != "CALL" || inner.From != *frame.To || !bytes.Equal(inner.Input, frame.Input) || !bytes.Equal(inner.Output, frame.Output) {
+		t.Fatalf("转发的子调用不符: %+v", inner)
+	}
+	if uint64(inner.GasUsed) >= uint64(frame.GasUsed) {
+		t.Errorf("子调用 Gas %d 应小于根调用 %d", inner.GasUsed, frame.GasUsed)
+	}
+
+	var tree bytes.Buffer
+	frame.WriteTree(&tree, nil)
+	if !strings.Contains(tree.String(), "transfer(") || strings.Contains(tree.String(), "revert 发生处") {
+		t.Errorf("调用树缺少解码的 transfer 或错误地标出了 revert:\n%s", tree.String())
+	}
+	checkGolden(t, "forwarded-transfer.tree", tree.Bytes())
+
+	// 状态变化：转发合约和接收方的两个代币余额槽位
+	var changedSlots int
+	for addr, post := range diff.Post {
+		pre := diff.Pre[addr]
+		for slot, v := range post.Storage {
+			if pre == nil || pre.Storage[slot] != v {
+				changedSlots++
+			}
+		}
+	}
+	if changedSlots != 2 {
+		t.Errorf("修改了 %d 个存储槽位，期望 2", changedSlots)
+	}
+	var out bytes.Buffer
+	diff.WriteDiff(&out)
+	checkGolden(t, "forwarded-transfer.diff", out.Bytes())
+}
+
+func TestTraceNestedRevert(t *testing.T) {
+	frame, _ := loadTrace(t, "nested-revert")
+
+	// 三层调用：EOA → 转发 → 转发 → token，revert 数据原样向上传递
+	depth, deepest := 0, frame
+	for len(deepest.Calls) > 0 {
+		deepest = deepest.Calls[len(deepest.Calls)-1]
+		depth++
+	}
+	if depth != 2 {
+		t.Fatalf("调用深度 %d，期望 2", depth)
+	}
+	if !frame.Failed() || frame.Error != "execution reverted" {
+		t.Fatalf("根调用 Error = %q", frame.Error)
+	}
+	if got := frame.RevertPoint(); got != deepest {
+		t.Fatalf("RevertPoint 应为最内层的 token 调用，得到 %s", got.To.Hex())
+	}
+	if !bytes.Equal(frame.Output, deepest.Output) {
+		t.Fatal("revert 数据应原样传递到根调用")
+	}
+	reason, err := sigdb.NewDefault().RevertReason(deepest.Output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `Error("` + contracts.ErrInsufficientBalance + `")`; reason != want {
+		t.Fatalf("RevertReason = %s，期望 %s", reason, want)
+	}
+
+	var tree bytes.Buffer
+	frame.WriteTree(&tree, nil)
+	if n := strings.Count(tree.String(), "revert 发生处"); n != 1 {
+		t.Errorf("revert 发生处标记了 %d 次，期望 1:\n%s", n, tree.String())
+	}
+	checkGolden(t, "nested-revert.tree", tree.Bytes())
+}
+
+func TestTraceCustomError(t *testing.T) {
+	frame, _ := loadTrace(t, "custom-error")
+
+	origin := frame.RevertPoint()
+	if origin == nil || origin == frame || len(origin.Calls) != 0 {
+		t.Fatal("RevertPoint 应为被转发的合约")
+	}
+
+	// 默认签名库不认识自定义错误
+	if reason, err := sigdb.NewDefault().RevertReason(origin.Output); err == nil {
+		t.Fatalf("默认签名库不应解码自定义错误，得到 %s", reason)
+	}
+	reg := sigdb.NewDefault()
+	if err := reg.AddSignature("test", insufficientBalanceSig); err != nil {
+		t.Fatal(err)
+	}
+	reason, err := reg.RevertReason(origin.Output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "InsufficientBalance(available=500, required=1000)"; reason != want {
+		t.Fatalf("RevertReason = %s，期望 %s", reason, want)
+	}
+
+	var tree bytes.Buffer
+	frame.WriteTree(&tree, reg)
+	if !strings.Contains(tree.String(), reason) {
+		t.Errorf("调用树没有显示解码后的错误:\n%s", tree.String())
+	}
+	checkGolden(t, "custom-error.tree", tree.Bytes())
+}
+
+func TestRevertPointCaught(t *testing.T) {
+	// 子调用失败后被捕获，调用方之后以自己的原因 revert：失败点是调用方
+	token := common.HexToAddress("0x01")
+	caller := &chain.CallFrame{
+		Type:   "CALL",
+		Error:  "execution reverted",
+		Output: []byte{0xde, 0xad},
+		Calls: []*chain.CallFrame{
+			{Type: "CALL", To: &token, Error: "execution reverted", Output: []byte{0xbe, 0xef}},
+		},
+	}
+	if got := caller.RevertPoint(); got != caller {
+		t.Fatal("revert 数据不同时失败点应为调用方")
+	}
+
+	// 子调用失败被捕获，调用方成功
+	caller.Error = ""
+	if caller.RevertPoint() != nil {
+		t.Fatal("调用成功时 RevertPoint 应为 nil")
+	}
+}
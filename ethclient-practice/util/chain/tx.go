@@ -0,0 +1,145 @@
+package chain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// Tx 交易摘要
+type Tx struct {
+	Hash      common.Hash
+	Type      uint8
+	From      common.Address
+	To        *common.Address // 合约创建交易为 nil
+	Value     *big.Int
+	Nonce     uint64
+	Gas       uint64   // Gas 上限
+	GasPrice  *big.Int // legacy 交易的价格；EIP-1559 交易为 GasFeeCap
+	GasFeeCap *big.Int
+	GasTipCap *big.Int
+	Data      []byte
+	Pending   bool // 仍在交易池中
+
+	BlobFeeCap *big.Int      // blob 交易每单位 blob gas 的最高价格，其他交易为 nil
+	BlobHashes []common.Hash // blob 交易的版本化哈希
+}
+
+// NewTx 从交易中提取摘要，signer 用于恢复发送者地址
+func NewTx(tx *types.Transaction, signer types.Signer) (*Tx, error) {
+	from, err := types.Sender(signer, tx)
+	if err != nil {
+		return nil, fmt.Errorf("恢复交易 %s 的发送者失败: %w", tx.Hash().Hex(), err)
+	}
+	return &Tx{
+		Hash:      tx.Hash(),
+		Type:      tx.Type(),
+		From:      from,
+		To:        tx.To(),
+		Value:     tx.Value(),
+		Nonce:     tx.Nonce(),
+		Gas:       tx.Gas(),
+		GasPrice:  tx.GasPrice(),
+		GasFeeCap: tx.GasFeeCap(),
+		GasTipCap: tx.GasTipCap(),
+		Data:      tx.Data(),
+
+		BlobFeeCap: tx.BlobGasFeeCap(),
+		BlobHashes: tx.BlobHashes(),
+	}, nil
+}
+
+// GetTx 按哈希查询交易
+func GetTx(ctx context.Context, r ChainReader, hash common.Hash) (*Tx, error) {
+	tx, pending, err := r.TransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("查询交易 %s 失败: %w", hash.Hex(), err)
+	}
+	signer, err := signerFor(ctx, r)
+	if err != nil {
+		return nil, fmt.Errorf("获取链 ID 失败: %w", err)
+	}
+	info, err := NewTx(tx, signer)
+	if err != nil {
+		return nil, err
+	}
+	info.Pending = pending
+	return info, nil
+}
+
+// IsContractCreation 是否为合约创建交易
+func (t *Tx) IsContractCreation() bool {
+	return t.To == nil
+}
+
+// IsDynamicFee 是否按 EIP-1559 方式计费（动态费用、Blob、SetCode 交易）
+func (t *Tx) IsDynamicFee() bool {
+	return t.Type >= types.DynamicFeeTxType
+}
+
+// BlobCount 交易携带的 blob 数量，非 blob 交易为 0
+func (t *Tx) BlobCount() int {
+	return len(t.BlobHashes)
+}
+
+// BlobGas blob 消耗的 blob gas，每个 blob 固定 131072
+func (t *Tx) BlobGas() uint64 {
+	return uint64(len(t.BlobHashes)) * params.BlobTxBlobGasPerBlob
+}
+
+// TypeName 交易类型名
+func (t *Tx) TypeName() string {
+	return TxTypeName(t.Type)
+}
+
+// TxTypeName 交易类型编号对应的名称
+func TxTypeName(txType uint8) string {
+	switch txType {
+	case types.LegacyTxType:
+		return "Legacy"
+	case types.AccessListTxType:
+		return "AccessList"
+	case types.DynamicFeeTxType:
+		return "DynamicFee"
+	case types.BlobTxType:
+		return "Blob"
+	case types.SetCodeTxType:
+		return "SetCode"
+	default:
+		return fmt.Sprintf("Unknown(%d)", txType)
+	}
+}
+
+// TxStatus 交易及其执行结果
+type TxStatus struct {
+	Tx      *Tx
+	Receipt *Receipt // 交易未打包时为 nil
+}
+
+// GetTxStatus 查询交易和收据。交易仍在交易池中时 Receipt 为 nil
+func GetTxStatus(ctx context.Context, r ChainReader, hash common.Hash) (*TxStatus, error) {
+	tx, err := GetTx(ctx, r, hash)
+	if err != nil {
+		return nil, err
+	}
+	status := &TxStatus{Tx: tx}
+	if tx.Pending {
+		return status, nil
+	}
+	if status.Receipt, err = GetReceipt(ctx, r, hash); err != nil {
+		return nil, err
+	}
+	return status, nil
+}
+
+// GasUsage 实际使用的 Gas 占上限的百分比，未打包时为 0
+func (s *TxStatus) GasUsage() float64 {
+	if s.Receipt == nil || s.Tx.Gas == 0 {
+		return 0
+	}
+	return float64(s.Receipt.GasUsed) / float64(s.Tx.Gas) * 100
+}
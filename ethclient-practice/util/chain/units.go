@@ -0,0 +1,65 @@
+package chain
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// EtherDecimals ETH 的小数位数
+const EtherDecimals = 18
+
+// FormatUnits 把最小单位按小数位数格式化为精确的十进制字符串，去掉末尾的 0：
+// FormatUnits(1500000000000000000, 18) = "1.5"
+func FormatUnits(v *big.Int, decimals uint8) string {
+	if v == nil {
+		return ""
+	}
+	neg := v.Sign() < 0
+	digits := new(big.Int).Abs(v).String()
+	if n := int(decimals); n > 0 {
+		if len(digits) <= n {
+			digits = strings.Repeat("0", n-len(digits)+1) + digits
+		}
+		intPart, frac := digits[:len(digits)-n], strings.TrimRight(digits[len(digits)-n:], "0")
+		digits = intPart
+		if frac != "" {
+			digits += "." + frac
+		}
+	}
+	if neg {
+		return "-" + digits
+	}
+	return digits
+}
+
+// FormatEther 把 wei 格式化为 ETH
+func FormatEther(wei *big.Int) string {
+	return FormatUnits(wei, EtherDecimals)
+}
+
+// ParseUnits 把非负的十进制数量解析为最小单位，不经过浮点数，小数位超过 decimals 时报错：
+// ParseUnits("1.5", 18) = 1500000000000000000
+func ParseUnits(s string, decimals uint8) (*big.Int, error) {
+	s = strings.TrimSpace(s)
+	intPart, frac, _ := strings.Cut(s, ".")
+	if len(frac) > int(decimals) {
+		return nil, fmt.Errorf("数量 %q 的小数位超过 %d 位", s, decimals)
+	}
+	if intPart == "" && frac == "" {
+		return nil, fmt.Errorf("无效的数量 %q", s)
+	}
+	digits := intPart + frac + strings.Repeat("0", int(decimals)-len(frac))
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return nil, fmt.Errorf("无效的数量 %q", s)
+		}
+	}
+	v, _ := new(big.Int).SetString(digits, 10)
+	return v, nil
+}
+
+// ParseEther 把 ETH 数量解析为 wei
+func ParseEther(s string) (*big.Int, error) {
+	return ParseUnits(s, EtherDecimals)
+}
@@ -0,0 +1,71 @@
+package chain_test
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+)
+
+func TestFormatUnits(t *testing.T) {
+	for _, tc := range []struct {
+		v        string
+		decimals uint8
+		want     string
+	}{
+		{"1500000000000000000", 18, "1.5"},
+		{"1000000000000000000", 18, "1"},
+		{"1", 18, "0.000000000000000001"},
+		{"0", 18, "0"},
+		{"-2500000", 6, "-2.5"},
+		{"123", 0, "123"},
+	} {
+		v, _ := new(big.Int).SetString(tc.v, 10)
+		if got := chain.FormatUnits(v, tc.decimals); got != tc.want {
+			t.Errorf("FormatUnits(%s, %d) = %q，期望 %q", tc.v, tc.decimals, got, tc.want)
+		}
+	}
+	if got := chain.FormatUnits(nil, 18); got != "" {
+		t.Errorf("FormatUnits(nil) = %q", got)
+	}
+}
+
+func TestParseUnits(t *testing.T) {
+	for _, tc := range []struct {
+		s        string
+		decimals uint8
+		want     string
+	}{
+		{"1.5", 18, "1500000000000000000"},
+		{" 2 ", 6, "2000000"},
+		{".25", 2, "25"},
+		{"0.000000000000000001", 18, "1"},
+	} {
+		got, err := chain.ParseUnits(tc.s, tc.decimals)
+		if err != nil {
+			t.Errorf("ParseUnits(%q, %d): %v", tc.s, tc.decimals, err)
+			continue
+		}
+		if got.String() != tc.want {
+			t.Errorf("ParseUnits(%q, %d) = %s，期望 %s", tc.s, tc.decimals, got, tc.want)
+		}
+	}
+
+	for _, s := range []string{"", ".", "1.2345678", "-1", "1e18", "abc"} {
+		if v, err := chain.ParseUnits(s, 6); err == nil {
+			t.Errorf("ParseUnits(%q, 6) = %s，期望报错", s, v)
+		}
+	}
+}
+
+func TestParseFormatRoundTrip(t *testing.T) {
+	for _, s := range []string{"0.1", "1", "12.345", "1000000.000001"} {
+		wei, err := chain.ParseEther(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := chain.FormatEther(wei); got != s {
+			t.Errorf("FormatEther(ParseEther(%q)) = %q", s, got)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// bindgen 从 Solidity 源码或编译产物生成 Go 绑定，供 go generate 调用。
+//
+//	//go:generate go run github.com/dapp-learning/ethclient/util/cmd/bindgen --artifact ../../2.09-deploy-contract/contract/Store_sol_Store.abi --solc 0.8.30 --pkg store --out store.go
+//
+// 加上 --check（或设置环境变量 BINDGEN_CHECK=1）时不写文件，绑定与合约不一致则以状态码 1 退出，
+// 可用于 CI。BINDGEN_CHECK=1 go generate ./... 会用与生成时完全相同的参数检查。
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dapp-learning/ethclient/util/bindgen"
+)
+
+func main() {
+	solPath := flag.String("sol", "", "Solidity 源文件，使用 PATH 中的 solc / solcjs 编译")
+	artifactPath := flag.String("artifact", "", "编译产物：solc 标准 JSON / combined-json、Hardhat、Foundry，或 solcjs 的 .abi/.bin")
+	abiPath := flag.String("abi", "", "只有 ABI 时使用，生成的绑定没有部署函数")
+	name := flag.String("type", "", "合约名（Go 类型名），默认取文件名")
+	pkg := flag.String("pkg", "", "生成代码的包名")
+	out := flag.String("out", "", "输出文件")
+	solcVersion := flag.String("solc", "", "要求的 solc 版本，如 0.8.30，与字节码元数据中的版本不一致时报错")
+	check := flag.Bool("check", os.Getenv("BINDGEN_CHECK") != "", "只检查 --out 是否为最新，不写文件（默认取环境变量 BINDGEN_CHECK）")
+	flag.Parse()
+
+	if *pkg == "" || *out == "" {
+		fatal(errors.New("需要 --pkg 和 --out"))
+	}
+
+	var (
+		src *bindgen.Source
+		err error
+	)
+	switch {
+	case *solPath != "":
+		src, err = bindgen.Compile(*solPath, *name)
+	case *artifactPath != "":
+		src, err = bindgen.FromArtifact(*artifactPath, *name)
+	case *abiPath != "":
+		src, err = bindgen.FromABI(*abiPath, *name)
+	default:
+		err = errors.New("需要 --sol、--artifact 或 --abi 之一")
+	}
+	if err != nil {
+		fatal(err)
+	}
+	if *solcVersion != "" {
+		if err := bindgen.CheckCompiler(src, *solcVersion); err != nil {
+			fatal(err)
+		}
+	}
+	if len(src.Bytecode) == 0 {
+		fmt.Fprintf(os.Stderr, "bindgen: 警告: %s 没有字节码，不会生成 Deploy%s\n", src.Origin, src.Name)
+	}
+
+	code, err := bindgen.Generate(src, *pkg)
+	if err != nil {
+		fatal(err)
+	}
+
+	if *check {
+		if err := bindgen.Check(*out, code); err != nil {
+			fatal(fmt.Errorf("%w\n请运行 go generate 重新生成", err))
+		}
+		fmt.Printf("bindgen: %s 是最新的（%s）\n", *out, src.Origin)
+		return
+	}
+	if err := os.WriteFile(*out, code, 0o644); err != nil {
+		fatal(err)
+	}
+	fmt.Printf("bindgen: %s → %s\n", src.Origin, *out)
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "bindgen: %v\n", err)
+	os.Exit(1)
+}
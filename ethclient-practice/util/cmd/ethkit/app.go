@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// networks 内置网络名 → Infura 地址前缀（需要 INFURA_API_KEY）
+var networks = map[string]string{
+	"mainnet": "https://mainnet.infura.io/v3/",
+	"sepolia": "https://sepolia.infura.io/v3/",
+	"holesky": "https://holesky.infura.io/v3/",
+}
+
+// options 所有命令共用的全局参数
+type options struct {
+	network  string
+	rpc      string
+	key      string
+	keystore string
+	password string
+	json     bool
+	timeout  time.Duration
+}
+
+// register 把全局参数注册到 fs。以当前值作为默认值，
+// 这样写在命令前的参数不会被命令自己的 FlagSet 覆盖
+func (o *options) register(fs *flag.FlagSet) {
+	fs.StringVar(&o.network, "network", o.network, "网络: mainnet | sepolia | holesky，或节点地址")
+	fs.StringVar(&o.rpc, "rpc", o.rpc, "节点 RPC 地址，优先于 --network")
+	fs.StringVar(&o.key, "private-key", o.key, "签名私钥（十六进制），默认读取 PRIVATE_KEY")
+	fs.StringVar(&o.keystore, "keystore", o.keystore, "签名使用的 keystore 文件，密码读取 --password 或 KEYSTORE_PASSWORD")
+	fs.StringVar(&o.password, "password", o.password, "keystore 密码")
+	fs.BoolVar(&o.json, "json", o.json, "以 JSON 输出")
+	fs.DurationVar(&o.timeout, "timeout", o.timeout, "请求和等待确认的超时时间")
+}
+
+// app 命令运行时的共享状态
+type app struct {
+	opts   options
+	client *ethclient.Client
+	out    io.Writer // 命令的输出，默认为标准输出
+}
+
+func newApp() *app {
+	return &app{opts: options{
+		network:  envOr("ETHKIT_NETWORK", "sepolia"),
+		rpc:      os.Getenv("ETHKIT_RPC_URL"),
+		key:      os.Getenv("PRIVATE_KEY"),
+		password: os.Getenv("KEYSTORE_PASSWORD"),
+		timeout:  5 * time.Minute,
+	}, out: os.Stdout}
+}
+
+// flags 创建命令的 FlagSet，包含全局参数
+func (a *app) flags(name, args string) *flag.FlagSet {
+	fs := flag.NewFlagSet("ethkit "+name, flag.ExitOnError)
+	a.opts.register(fs)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "用法: ethkit %s [参数] %s\n\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// context 带 --timeout 的 context
+func (a *app) context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), a.opts.timeout)
+}
+
+// rpcURL 按 --rpc、--network 解析节点地址。内置网络优先读取 <NETWORK>_RPC_URL，
+// 其次使用 Infura
+func (a *app) rpcURL() (string, error) {
+	if a.opts.rpc != "" {
+		return a.opts.rpc, nil
+	}
+	network := a.opts.network
+	if strings.Contains(network, "://") || strings.HasSuffix(network, ".ipc") {
+		return network, nil
+	}
+	prefix, ok := networks[network]
+	if !ok {
+		return "", usageError("未知网络 %q，可选 mainnet | sepolia | holesky，或用 --rpc 指定节点地址", network)
+	}
+	if url := os.Getenv(strings.ToUpper(network) + "_RPC_URL"); url != "" {
+		return url, nil
+	}
+	apiKey := os.Getenv("INFURA_API_KEY")
+	if apiKey == "" {
+		return "", fmt.Errorf("请设置 %s_RPC_URL 或 INFURA_API_KEY，或用 --rpc 指定节点地址", strings.ToUpper(network))
+	}
+	return prefix + apiKey, nil
+}
+
+// dial 连接节点，同一次运行只连接一次
+func (a *app) dial(ctx context.Context) (*ethclient.Client, error) {
+	if a.client != nil {
+		return a.client, nil
+	}
+	url, err := a.rpcURL()
+	if err != nil {
+		return nil, err
+	}
+	client, err := ethclient.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("连接节点失败: %w", err)
+	}
+	a.client = client
+	return client, nil
+}
+
+// signer 加载签名私钥：--keystore 优先，其次 --private-key / PRIVATE_KEY
+func (a *app) signer() (*ecdsa.PrivateKey, error) {
+	if a.opts.keystore != "" {
+		return loadKeystore(a.opts.keystore, a.opts.password)
+	}
+	if a.opts.key == "" {
+		return nil, errors.New("需要签名私钥: 设置 PRIVATE_KEY，或使用 --private-key / --keystore")
+	}
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(a.opts.key, "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("私钥格式错误: %w", err)
+	}
+	return key, nil
+}
+
+// loadKeystore 解密 keystore 文件
+func loadKeystore(path, password string) (*ecdsa.PrivateKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(raw, password)
+	if err != nil {
+		return nil, fmt.Errorf("解密 %s 失败: %w", path, err)
+	}
+	return key.PrivateKey, nil
+}
+
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
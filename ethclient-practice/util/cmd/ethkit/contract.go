@@ -0,0 +1,261 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/dapp-learning/ethclient/util/abiarg"
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/contract"
+	"github.com/dapp-learning/ethclient/util/deploy"
+	"github.com/dapp-learning/ethclient/util/output"
+)
+
+func runDeploy(app *app, args []string) error {
+	fs := app.flags("deploy", "<artifact> [构造参数...]")
+	contractName := fs.String("contract", "", "产物中包含多个合约时指定合约名")
+	registryPath := fs.String("registry", deploy.DefaultRegistryFile, "部署记录文件，为空时不记录")
+	valueFlag := fs.String("value", "0", "随部署发送的 ETH，如 0.01ether")
+	gasLimit := fs.Uint64("gas-limit", 0, "Gas 上限，0 表示估算后加 20% 余量")
+	fs.Parse(args)
+	if fs.NArg() < 1 {
+		return usageError("需要编译产物路径，如: ethkit deploy out/Store.sol/Store.json 1.0")
+	}
+
+	art, err := deploy.LoadArtifact(fs.Arg(0), *contractName)
+	if err != nil {
+		return err
+	}
+	ctorArgs, err := abiarg.ParseArgs(art.ABI.Constructor.Inputs, fs.Args()[1:])
+	if err != nil {
+		return usageError("构造参数错误: %v", err)
+	}
+	value, err := abiarg.ParseInt(*valueFlag)
+	if err != nil {
+		return usageError("--value: %v", err)
+	}
+	key, err := app.signer()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := app.context()
+	defer cancel()
+	client, err := app.dial(ctx)
+	if err != nil {
+		return err
+	}
+	deployer, err := deploy.NewDeployer(ctx, client, key)
+	if err != nil {
+		return err
+	}
+	if !app.opts.json {
+		fmt.Fprintf(app.out, "部署 %s 到 %s，账户 %s\n", art.Name, deployer.Network(), deployer.From().Hex())
+	}
+	d, err := deployer.Deploy(ctx, art, &deploy.Options{Value: value, GasLimit: *gasLimit}, ctorArgs...)
+	if err != nil {
+		return err
+	}
+	d.Args = fs.Args()[1:]
+	d.Artifact = fs.Arg(0)
+
+	if *registryPath != "" {
+		registry, err := deploy.OpenRegistry(*registryPath)
+		if err != nil {
+			return err
+		}
+		registry.Add(*d)
+		if err := registry.Save(); err != nil {
+			return err
+		}
+	}
+
+	return app.emit(d, func(w io.Writer) {
+		writeFields(w,
+			"合约", d.Name,
+			"地址", d.Address.Hex(),
+			"交易", d.TxHash.Hex(),
+			"区块", strconv.FormatUint(d.Block, 10),
+			"Gas 使用", strconv.FormatUint(d.GasUsed, 10),
+			"代码哈希", d.BytecodeHash.Hex(),
+			"部署记录", *registryPath,
+		)
+	})
+}
+
+// outputView 方法返回值
+type outputView struct {
+	Name  string      `json:"name"`
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// callView call 命令只读调用 / 模拟执行的结果
+type callView struct {
+	Address   string       `json:"address"`
+	Method    string       `json:"method"`
+	Simulated bool         `json:"simulated"` // 非 view 方法未加 --send 时只在本地模拟
+	Overrides bool         `json:"overrides"` // 使用了 --override-* / --block-* 覆盖
+	Outputs   []outputView `json:"outputs"`
+}
+
+func runCall(app *app, args []string) error {
+	fs := app.flags("call", "<address|name> <method> [参数...]")
+	abiPath := fs.String("abi", "", "ABI 文件，按名称调用时默认使用部署记录中的编译产物")
+	registryPath := fs.String("registry", deploy.DefaultRegistryFile, "按名称查找合约时使用的部署记录文件")
+	send := fs.Bool("send", false, "对非 view 方法发送交易，默认只模拟执行")
+	valueFlag := fs.String("value", "0", "随调用发送的 ETH，如 0.01ether")
+	fromFlag := fs.String("from", "", "调用方地址，默认使用签名账户（如果有）")
+	sf := addSendFlags(fs)
+	of := addOverrideFlags(fs)
+	fs.Parse(args)
+	if fs.NArg() < 2 {
+		return usageError("需要合约地址（或部署记录中的名称）和方法名")
+	}
+	value, err := abiarg.ParseInt(*valueFlag)
+	if err != nil {
+		return usageError("--value: %v", err)
+	}
+	if *send && of.set() {
+		return usageError("状态覆盖只用于模拟调用，不能和 --send 一起使用")
+	}
+	state, block, err := of.parse()
+	if err != nil {
+		return err
+	}
+	var from *common.Address
+	if *fromFlag != "" {
+		addr, err := parseAddress(*fromFlag)
+		if err != nil {
+			return err
+		}
+		from = &addr
+	}
+	params := make([]interface{}, fs.NArg()-2)
+	for i, v := range fs.Args()[2:] {
+		params[i] = v // 字符串参数由 contract 按 ABI 类型转换
+	}
+
+	ctx, cancel := app.context()
+	defer cancel()
+	client, err := app.dial(ctx)
+	if err != nil {
+		return err
+	}
+
+	// 地址或部署记录中的名称
+	target, path := fs.Arg(0), *abiPath
+	var address common.Address
+	if common.IsHexAddress(target) {
+		address = common.HexToAddress(target)
+	} else {
+		d, err := deploy.Resolve(ctx, client, *registryPath, target)
+		if err != nil {
+			return err
+		}
+		address = d.Address
+		if path == "" {
+			path = d.Artifact
+		}
+	}
+	if path == "" {
+		return usageError("需要 --abi 指定 ABI 文件")
+	}
+	var backend bind.ContractBackend = client
+	if of.set() {
+		backend = newOverrideBackend(client, state, block)
+	}
+	c, err := contract.Load(address, path, backend)
+	if err != nil {
+		return err
+	}
+	method, err := c.Method(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	if *send && !method.IsConstant() {
+		if value.Sign() > 0 && !method.IsPayable() {
+			return fmt.Errorf("%s 不是 payable 方法，不能附带 ETH", method.Sig)
+		}
+		data, err := c.Pack(method.Sig, params...)
+		if err != nil {
+			return err
+		}
+		return app.send(sf, &chain.TxRequest{To: &address, Value: value, Data: data, GasLimit: *sf.gasLimit})
+	}
+
+	// view 方法直接调用；其他方法以 --from 或签名账户（如果有）为 from 模拟执行
+	opts := &bind.CallOpts{Context: ctx}
+	if from != nil {
+		opts.From = *from
+	} else if !method.IsConstant() {
+		if key, err := app.signer(); err == nil {
+			opts.From = crypto.PubkeyToAddress(key.PublicKey)
+		}
+	}
+	out, err := c.Call(opts, method.Sig, params...)
+	if err != nil {
+		return err
+	}
+	view := callView{
+		Address:   address.Hex(),
+		Method:    method.Sig,
+		Simulated: !method.IsConstant(),
+		Overrides: of.set(),
+		Outputs:   outputViews(method.Outputs, out),
+	}
+	return app.emit(view, func(w io.Writer) {
+		if view.Simulated {
+			fmt.Fprintf(w, "%s 会修改状态，以下为模拟执行结果，加 --send 发送交易\n", method.Sig)
+		}
+		if view.Overrides {
+			fmt.Fprintln(w, "使用覆盖后的状态调用，结果不代表链上状态:")
+			state.WriteSummary(w)
+			if block != nil {
+				writeBlockOverrides(w, block)
+			}
+			fmt.Fprintln(w)
+		}
+		t := output.NewTable("返回值", "类型", "值")
+		for _, o := range view.Outputs {
+			t.Add(o.Name, o.Type, formatValue(o.Value))
+		}
+		t.Write(w)
+	})
+}
+
+// outputViews 把返回值与 ABI 中的名称、类型对应起来
+func outputViews(outputs abi.Arguments, values []interface{}) []outputView {
+	views := make([]outputView, len(values))
+	for i, v := range values {
+		name, typ := fmt.Sprintf("#%d", i), ""
+		if i < len(outputs) {
+			typ = outputs[i].Type.String()
+			if outputs[i].Name != "" {
+				name = outputs[i].Name
+			}
+		}
+		views[i] = outputView{Name: name, Type: typ, Value: output.Normalize(v)}
+	}
+	return views
+}
+
+// formatValue 以 JSON 显示已规范化的值，单个字符串（包括整数）不加引号
+func formatValue(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+)
+
+// decodeView decode 命令的结果。金额和费用为 wei 的十进制字符串
+type decodeView struct {
+	Hash                 string      `json:"hash"`
+	Type                 string      `json:"type"`
+	ChainID              *string     `json:"chainId"` // 未启用 EIP-155 的 legacy 交易为 null
+	From                 *string     `json:"from"`    // 签名无效时为 null
+	To                   *string     `json:"to"`
+	Value                string      `json:"value"`
+	Nonce                uint64      `json:"nonce"`
+	Gas                  uint64      `json:"gas"`
+	GasPrice             string      `json:"gasPrice,omitempty"`
+	MaxFeePerGas         string      `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas string      `json:"maxPriorityFeePerGas,omitempty"`
+	MaxFeePerBlobGas     string      `json:"maxFeePerBlobGas,omitempty"`
+	BlobHashes           []string    `json:"blobVersionedHashes,omitempty"`
+	AccessList           interface{} `json:"accessList,omitempty"`
+	Authorizations       []authView  `json:"authorizationList,omitempty"`
+	Input                string      `json:"input"`
+	Checks               []checkView `json:"checks"`
+}
+
+type checkView struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail"`
+}
+
+type authView struct {
+	ChainID   string  `json:"chainId"`
+	Address   string  `json:"address"`
+	Nonce     uint64  `json:"nonce"`
+	Authority *string `json:"authority"` // 签名无效时为 null
+}
+
+// runDecode 离线解码签名交易并检查有效性，不需要连接节点
+func runDecode(app *app, args []string) error {
+	fs := app.flags("decode", "<raw-tx-hex>")
+	chainIDFlag := fs.String("chain-id", "", "检查交易是否属于这条链")
+	nonceFlag := fs.Int64("nonce", -1, "检查交易 nonce 是否等于账户当前 nonce，-1 表示不检查")
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		return usageError("需要一个十六进制的签名交易")
+	}
+
+	rawTx, err := chain.DecodeRawTxHex(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	var chainID *big.Int
+	if *chainIDFlag != "" {
+		var ok bool
+		if chainID, ok = new(big.Int).SetString(*chainIDFlag, 10); !ok {
+			return usageError("无效的链 ID: %s", *chainIDFlag)
+		}
+	}
+	var nonce *uint64
+	if *nonceFlag >= 0 {
+		n := uint64(*nonceFlag)
+		nonce = &n
+	}
+	checks := rawTx.Check(chainID, nonce)
+
+	err = app.emit(newDecodeView(rawTx, checks), func(w io.Writer) {
+		rawTx.WriteReport(w, nil)
+		fmt.Fprintln(w)
+		chain.WriteChecks(w, checks)
+	})
+	if err != nil {
+		return err
+	}
+	for _, c := range checks {
+		if !c.OK {
+			return errors.New("交易没有通过全部检查")
+		}
+	}
+	return nil
+}
+
+func newDecodeView(r *chain.RawTx, checks []chain.TxCheck) *decodeView {
+	v := &decodeView{
+		Hash:  r.Hash.Hex(),
+		Type:  chain.TxTypeName(r.Type),
+		Value: r.Value.String(),
+		Nonce: r.Nonce,
+		Gas:   r.Gas,
+		Input: hexutil.Encode(r.Data),
+	}
+	for _, c := range checks {
+		v.Checks = append(v.Checks, checkView{Name: c.Name, OK: c.OK, Detail: c.Detail})
+	}
+	if r.ChainID != nil {
+		id := r.ChainID.String()
+		v.ChainID = &id
+	}
+	if r.SenderErr == nil {
+		from := r.From.Hex()
+		v.From = &from
+	}
+	if r.To != nil {
+		to := r.To.Hex()
+		v.To = &to
+	}
+	if r.GasPrice != nil {
+		v.GasPrice = r.GasPrice.String()
+	} else {
+		v.MaxFeePerGas = r.GasFeeCap.String()
+		v.MaxPriorityFeePerGas = r.GasTipCap.String()
+	}
+	if r.BlobFeeCap != nil {
+		v.MaxFeePerBlobGas = r.BlobFeeCap.String()
+	}
+	for _, h := range r.BlobHashes {
+		v.BlobHashes = append(v.BlobHashes, h.Hex())
+	}
+	if len(r.AccessList) > 0 {
+		v.AccessList = r.AccessList
+	}
+	for _, a := range r.Authorizations {
+		av := authView{ChainID: a.ChainID.String(), Address: a.Address.Hex(), Nonce: a.Nonce}
+		if a.Err == nil {
+			authority := a.Authority.Hex()
+			av.Authority = &authority
+		}
+		v.Authorizations = append(v.Authorizations, av)
+	}
+	return v
+}
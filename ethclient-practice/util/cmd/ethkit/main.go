@@ -0,0 +1,118 @@
+// ethkit 把各课程的操作集中到一个命令行工具中：查询区块、交易、收据、余额，
+// 发送 ETH 和 ERC20，部署和调用合约，监听新区块，生成和恢复钱包。
+//
+//	go run github.com/dapp-learning/ethclient/util/cmd/ethkit [全局参数] <命令> [参数]
+//
+//	ethkit block latest
+//	ethkit --network mainnet balance 0xd8dA6BF26964aF9D7eEd9e03E53415D37aA96045
+//	ethkit --json tx 0x...
+//	ethkit send 0x... 0.001
+//	ethkit token transfer 0xToken 0xTo 10.5
+//	ethkit watch blocks
+//
+// 全局参数（--network、--rpc、--private-key、--keystore、--json 等）既可以写在命令前，
+// 也可以写在命令后。
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/dapp-learning/ethclient/util/output"
+)
+
+// command 一个子命令
+type command struct {
+	name    string
+	args    string // 用法中的参数说明
+	summary string
+	run     func(app *app, args []string) error
+}
+
+var commands = []*command{
+	{"block", "[number|latest]", "查询区块，--txs 列出交易", runBlock},
+	{"tx", "<hash>", "查询交易及执行结果", runTx},
+	{"receipt", "<hash>", "查询交易收据和日志", runReceipt},
+	{"trace", "<hash>", "重放交易打印调用树，--prestate 打印状态变化", runTrace},
+	{"decode", "<raw-tx-hex>", "离线解码签名交易，检查链 ID 和 nonce", runDecode},
+	{"balance", "<address>...", "查询 ETH 余额，--token 查询代币余额", runBalance},
+	{"account", "<address>...", "查看账户类型和 EIP-7702 委托", runAccount},
+	{"send", "<to> <amount>", "发送 ETH，数量单位为 ETH", runSend},
+	{"token", "transfer|approve|allowance ...", "ERC20 转账、授权、查询授权额度", runToken},
+	{"deploy", "<artifact> [构造参数...]", "从编译产物部署合约并写入部署记录", runDeploy},
+	{"call", "<address|name> <method> [参数...]", "按 ABI 调用合约，--send 发送交易", runCall},
+	{"watch", "blocks|address <address>", "监听新区块或地址相关的交易", runWatch},
+	{"wallet", "new|restore", "生成新钱包或从私钥 / keystore 恢复", runWallet},
+}
+
+func main() {
+	if err := newApp().run(os.Args[1:]); err != nil {
+		fatal(err)
+	}
+}
+
+// run 解析全局参数，执行 argv 中的命令
+func (a *app) run(argv []string) error {
+	fs := flag.NewFlagSet("ethkit", flag.ExitOnError)
+	a.opts.register(fs)
+	fs.Usage = usage(fs)
+	fs.Parse(argv)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return usageError("需要命令")
+	}
+	name, args := fs.Arg(0), fs.Args()[1:]
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd.run(a, args)
+		}
+	}
+	return usageError("未知命令 %q，运行 ethkit -h 查看帮助", name)
+}
+
+func usage(fs *flag.FlagSet) func() {
+	return func() {
+		w := fs.Output()
+		fmt.Fprintln(w, "用法: ethkit [全局参数] <命令> [参数]")
+		fmt.Fprintln(w, "\n命令:")
+		for _, cmd := range commands {
+			fmt.Fprintf(w, "  %s%s%s\n", output.Pad(cmd.name, 9), output.Pad(cmd.args, 36), cmd.summary)
+		}
+		fmt.Fprintln(w, "\n全局参数:")
+		fs.PrintDefaults()
+		fmt.Fprintln(w, "\n运行 ethkit <命令> -h 查看命令的参数")
+	}
+}
+
+// errUsage 参数错误，进程以退出码 2 结束
+var errUsage = errors.New("参数错误")
+
+// usageError 参数错误
+func usageError(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s", errUsage, fmt.Sprintf(format, args...))
+}
+
+// subcommand 在 args[0] 中查找二级命令（token transfer、watch blocks 等）
+func subcommand(args []string, names ...string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, usageError("需要子命令: %s", strings.Join(names, " | "))
+	}
+	for _, name := range names {
+		if args[0] == name {
+			return name, args[1:], nil
+		}
+	}
+	return "", nil, usageError("未知子命令 %q，可选: %s", args[0], strings.Join(names, " | "))
+}
+
+func fatal(err error) {
+	fmt.Fprintf(os.Stderr, "ethkit: %v\n", err)
+	if errors.Is(err, errUsage) {
+		os.Exit(2)
+	}
+	os.Exit(1)
+}
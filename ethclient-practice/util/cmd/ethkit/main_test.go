@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/simnet"
+)
+
+// testApp 不读取环境变量的 app，输出写入 buf
+func testApp(t *testing.T) (*app, *bytes.Buffer) {
+	t.Helper()
+	for _, name := range []string{"ETHKIT_NETWORK", "ETHKIT_RPC_URL", "PRIVATE_KEY", "KEYSTORE_PASSWORD", "INFURA_API_KEY", "MAINNET_RPC_URL", "SEPOLIA_RPC_URL", "HOLESKY_RPC_URL"} {
+		t.Setenv(name, "")
+	}
+	buf := new(bytes.Buffer)
+	a := newApp()
+	a.out = buf
+	return a, buf
+}
+
+func TestGlobalFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		argv []string
+		want options
+	}{
+		{"defaults", []string{"balance"}, options{network: "sepolia"}},
+		{"before command", []string{"--json", "--network", "mainnet", "balance"}, options{network: "mainnet", json: true}},
+		{"after command", []string{"balance", "--json", "--rpc", "http://localhost:8545"}, options{network: "sepolia", rpc: "http://localhost:8545", json: true}},
+		// 写在命令前的参数不会被命令的 FlagSet 恢复为默认值
+		{"both", []string{"--rpc", "http://a", "--timeout", "10s", "balance", "--network", "holesky"}, options{network: "holesky", rpc: "http://a", timeout: 10 * time.Second}},
+		{"command overrides", []string{"--network", "mainnet", "balance", "--network", "holesky"}, options{network: "holesky"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _ := testApp(t)
+			// balance 没有地址参数时在连接节点之前返回参数错误
+			if err := a.run(tt.argv); !errors.Is(err, errUsage) {
+				t.Fatalf("run 返回 %v", err)
+			}
+			got := a.opts
+			if tt.want.timeout == 0 {
+				tt.want.timeout = 5 * time.Minute
+			}
+			if got != tt.want {
+				t.Fatalf("全局参数 %+v，期望 %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRPCURL(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    options
+		env     map[string]string
+		want    string
+		usage   bool // 期望参数错误
+		wantErr bool
+	}{
+		{"rpc wins", options{network: "mainnet", rpc: "http://localhost:8545"}, nil, "http://localhost:8545", false, false},
+		{"network url", options{network: "ws://localhost:8546"}, nil, "ws://localhost:8546", false, false},
+		{"network ipc", options{network: "/tmp/geth.ipc"}, nil, "/tmp/geth.ipc", false, false},
+		{"network env", options{network: "mainnet"}, map[string]string{"MAINNET_RPC_URL": "https://eth.example", "INFURA_API_KEY": "key"}, "https://eth.example", false, false},
+		{"infura", options{network: "sepolia"}, map[string]string{"INFURA_API_KEY": "key"}, "https://sepolia.infura.io/v3/key", false, false},
+		{"no key", options{network: "holesky"}, nil, "", false, true},
+		{"unknown network", options{network: "goerli"}, map[string]string{"INFURA_API_KEY": "key"}, "", true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _ := testApp(t)
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			a.opts = tt.opts
+			got, err := a.rpcURL()
+			if tt.wantErr {
+				if err == nil || errors.Is(err, errUsage) != tt.usage {
+					t.Fatalf("rpcURL 返回 %q, %v", got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("rpcURL = %q, %v，期望 %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDispatchErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		argv []string
+		want string
+	}{
+		{"no command", nil, "需要命令"},
+		{"unknown command", []string{"blocks"}, `未知命令 "blocks"`},
+		{"missing subcommand", []string{"token"}, "需要子命令: transfer | approve | allowance"},
+		{"unknown subcommand", []string{"watch", "logs"}, `未知子命令 "logs"`},
+		{"bad block number", []string{"block", "abc"}, `无效的区块号 "abc"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, _ := testApp(t)
+			a.opts.rpc = "http://127.0.0.1:0" // 不应连接节点
+			err := a.run(tt.argv)
+			if !errors.Is(err, errUsage) || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("run 返回 %v，期望参数错误 %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONOutput(t *testing.T) {
+	net, err := simnet.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { net.Close() })
+	head, err := net.Client.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("block", func(t *testing.T) {
+		a, buf := testApp(t)
+		a.client = ethclient.NewClient(net.RPC)
+		if err := a.run([]string{"--json", "block", "latest"}); err != nil {
+			t.Fatal(err)
+		}
+		var v blockView
+		if err := json.Unmarshal(buf.Bytes(), &v); err != nil {
+			t.Fatalf("%v\n%s", err, buf)
+		}
+		if v.Number != head.Number.Uint64() || v.Hash != head.Hash().Hex() || v.BaseFee != head.BaseFee.String() {
+			t.Fatalf("区块 %+v，期望 %d %s", v, head.Number, head.Hash().Hex())
+		}
+	})
+
+	t.Run("balance", func(t *testing.T) {
+		a, buf := testApp(t)
+		a.client = ethclient.NewClient(net.RPC)
+		alice, empty := net.Accounts[0].Address, simnet.NewAccount(99).Address
+		want, err := net.Client.BalanceAt(context.Background(), alice, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := a.run([]string{"balance", "--json", alice.Hex(), empty.Hex()}); err != nil {
+			t.Fatal(err)
+		}
+		var views []balanceView
+		if err := json.Unmarshal(buf.Bytes(), &views); err != nil {
+			t.Fatalf("%v\n%s", err, buf)
+		}
+		if len(views) != 2 || views[0].Address != alice.Hex() || views[0].Balance != want.String() || views[0].Decimals != 18 || views[1].Balance != "0" {
+			t.Fatalf("余额 %+v，期望 %s", views, want)
+		}
+
+		// 代币余额：--json 写在地址之前
+		buf.Reset()
+		if err := a.run([]string{"--json", "balance", "--token", net.Token.Hex(), empty.Hex()}); err != nil {
+			t.Fatal(err)
+		}
+		views = nil
+		if err := json.Unmarshal(buf.Bytes(), &views); err != nil {
+			t.Fatalf("%v\n%s", err, buf)
+		}
+		if len(views) != 1 || views[0].Balance != "0" || views[0].Error != "" {
+			t.Fatalf("代币余额 %+v", views)
+		}
+
+		// 不带 --json 时输出表格
+		buf.Reset()
+		a.opts.json = false
+		if err := a.run([]string{"balance", empty.Hex()}); err != nil {
+			t.Fatal(err)
+		}
+		if json.Valid(buf.Bytes()) || !strings.Contains(buf.String(), empty.Hex()) {
+			t.Fatalf("表格输出:\n%s", buf)
+		}
+	})
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/dapp-learning/ethclient/util/output"
+)
+
+// emit 输出一个结果：--json 时输出 v 的 JSON，否则调用 render 打印表格
+func (a *app) emit(v interface{}, render func(w io.Writer)) error {
+	if a.opts.json {
+		enc := json.NewEncoder(a.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	render(a.out)
+	return nil
+}
+
+// emitLine 输出流式结果（watch）：--json 时每行一个 JSON 对象
+func (a *app) emitLine(v interface{}, line string) error {
+	if a.opts.json {
+		return json.NewEncoder(a.out).Encode(v)
+	}
+	_, err := fmt.Fprintln(a.out, line)
+	return err
+}
+
+// writeFields 打印键值对，pairs 为 键, 值, 键, 值...；值为空的字段不打印
+func writeFields(w io.Writer, pairs ...string) {
+	t := output.NewTable()
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if pairs[i+1] != "" {
+			t.Add(pairs[i]+":", pairs[i+1])
+		}
+	}
+	t.Write(w)
+}
+
+// shorten 缩短哈希和地址：0x12345678...abcd
+func shorten(s string) string {
+	if len(s) <= 16 {
+		return s
+	}
+	return s[:10] + "..." + s[len(s)-4:]
+}
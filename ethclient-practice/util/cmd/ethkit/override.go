@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/abiarg"
+	"github.com/dapp-learning/ethclient/util/chain"
+)
+
+// listFlag 可重复指定的参数
+type listFlag []string
+
+func (l *listFlag) String() string     { return strings.Join(*l, ",") }
+func (l *listFlag) Set(s string) error { *l = append(*l, s); return nil }
+
+// overrideFlags eth_call 的状态覆盖和区块覆盖参数
+type overrideFlags struct {
+	balance     listFlag
+	nonce       listFlag
+	code        listFlag
+	storage     listFlag
+	token       listFlag
+	tokenSlot   *uint64
+	blockNumber *uint64
+	blockTime   *uint64
+}
+
+func addOverrideFlags(fs *flag.FlagSet) *overrideFlags {
+	of := &overrideFlags{
+		tokenSlot:   fs.Uint64("token-balance-slot", 0, "--override-token-balance 使用的余额映射槽位，OpenZeppelin ERC20 为 0"),
+		blockNumber: fs.Uint64("block-number", 0, "覆盖调用看到的 block.number，0 表示不覆盖"),
+		blockTime:   fs.Uint64("block-time", 0, "覆盖调用看到的 block.timestamp（Unix 秒），0 表示不覆盖"),
+	}
+	fs.Var(&of.balance, "override-balance", "覆盖 ETH 余额，如 0xabc...=10ether，可重复")
+	fs.Var(&of.nonce, "override-nonce", "覆盖 nonce，如 0xabc...=5，可重复")
+	fs.Var(&of.code, "override-code", "覆盖运行时代码，如 0xabc...=0x6080...，可重复")
+	fs.Var(&of.storage, "override-storage", "覆盖存储槽位，如 0xabc...:0x0=0x1，可重复")
+	fs.Var(&of.token, "override-token-balance", "覆盖 ERC20 余额（最小单位），如 <代币>:<持有人>=100ether，可重复")
+	return of
+}
+
+// set 是否指定了任何覆盖
+func (of *overrideFlags) set() bool {
+	return len(of.balance)+len(of.nonce)+len(of.code)+len(of.storage)+len(of.token) > 0 || *of.blockNumber > 0 || *of.blockTime > 0
+}
+
+// parse 把命令行参数转换为 chain 的覆盖参数，没有区块覆盖时 block 为 nil
+func (of *overrideFlags) parse() (chain.StateOverride, *chain.BlockOverrides, error) {
+	state := chain.StateOverride{}
+	for _, s := range of.balance {
+		addr, v, err := splitOverride("--override-balance", s)
+		if err != nil {
+			return nil, nil, err
+		}
+		wei, err := abiarg.ParseInt(v)
+		if err != nil {
+			return nil, nil, usageError("--override-balance %s: %v", s, err)
+		}
+		state.SetBalance(addr, wei)
+	}
+	for _, s := range of.nonce {
+		addr, v, err := splitOverride("--override-nonce", s)
+		if err != nil {
+			return nil, nil, err
+		}
+		nonce, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return nil, nil, usageError("--override-nonce %s: %v", s, err)
+		}
+		state.SetNonce(addr, nonce)
+	}
+	for _, s := range of.code {
+		addr, v, err := splitOverride("--override-code", s)
+		if err != nil {
+			return nil, nil, err
+		}
+		code, err := hexutil.Decode(v)
+		if err != nil {
+			return nil, nil, usageError("--override-code %s: %v", s, err)
+		}
+		state.SetCode(addr, code)
+	}
+	for _, s := range of.storage {
+		addrHex, rest, ok := strings.Cut(s, ":")
+		slotHex, valueHex, ok2 := strings.Cut(rest, "=")
+		if !ok || !ok2 || !common.IsHexAddress(addrHex) {
+			return nil, nil, usageError("--override-storage 格式为 <地址>:<槽位>=<值>，实际为 %q", s)
+		}
+		slot, err := parseWord(slotHex)
+		if err != nil {
+			return nil, nil, usageError("--override-storage 槽位: %v", err)
+		}
+		value, err := parseWord(valueHex)
+		if err != nil {
+			return nil, nil, usageError("--override-storage 值: %v", err)
+		}
+		state.SetStorage(common.HexToAddress(addrHex), slot, value)
+	}
+	for _, s := range of.token {
+		tokenHex, rest, _ := strings.Cut(s, ":")
+		owner, v, err := splitOverride("--override-token-balance", rest)
+		if err != nil || !common.IsHexAddress(tokenHex) {
+			return nil, nil, usageError("--override-token-balance 格式为 <代币>:<持有人>=<数量>，实际为 %q", s)
+		}
+		amount, err := abiarg.ParseInt(v)
+		if err != nil {
+			return nil, nil, usageError("--override-token-balance %s: %v", s, err)
+		}
+		state.SetTokenBalance(common.HexToAddress(tokenHex), owner, amount, *of.tokenSlot)
+	}
+
+	var block *chain.BlockOverrides
+	if *of.blockNumber > 0 || *of.blockTime > 0 {
+		block = new(chain.BlockOverrides)
+		if *of.blockNumber > 0 {
+			block.Number = (*hexutil.Big)(new(big.Int).SetUint64(*of.blockNumber))
+		}
+		if *of.blockTime > 0 {
+			t := hexutil.Uint64(*of.blockTime)
+			block.Time = &t
+		}
+	}
+	return state, block, nil
+}
+
+// splitOverride 拆分 <地址>=<值>
+func splitOverride(name, s string) (common.Address, string, error) {
+	addr, v, ok := strings.Cut(s, "=")
+	if !ok || !common.IsHexAddress(addr) {
+		return common.Address{}, "", usageError("%s 格式为 <地址>=<值>，实际为 %q", name, s)
+	}
+	return common.HexToAddress(addr), v, nil
+}
+
+// parseWord 解析存储槽位或值：十进制或 0x 开头的十六进制整数
+func parseWord(s string) (common.Hash, error) {
+	v, ok := new(big.Int).SetString(s, 0)
+	if !ok || v.Sign() < 0 || v.BitLen() > 256 {
+		return common.Hash{}, fmt.Errorf("%q 不是有效的 uint256", s)
+	}
+	return common.BigToHash(v), nil
+}
+
+// writeBlockOverrides 打印区块覆盖
+func writeBlockOverrides(w io.Writer, block *chain.BlockOverrides) {
+	if block.Number != nil {
+		fmt.Fprintf(w, "区块号   %s\n", block.Number.ToInt())
+	}
+	if block.Time != nil {
+		fmt.Fprintf(w, "时间戳   %d（%s）\n", uint64(*block.Time), time.Unix(int64(*block.Time), 0).Format(time.DateTime))
+	}
+}
+
+// overrideBackend 调用合约时附加覆盖参数，其他方法直接使用节点
+type overrideBackend struct {
+	*ethclient.Client
+	caller *chain.OverrideCaller
+}
+
+func newOverrideBackend(client *ethclient.Client, state chain.StateOverride, block *chain.BlockOverrides) *overrideBackend {
+	return &overrideBackend{
+		Client: client,
+		caller: &chain.OverrideCaller{RPC: client.Client(), State: state, Block: block},
+	}
+}
+
+func (b *overrideBackend) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return b.caller.CallContract(ctx, msg, blockNumber)
+}
+
+func (b *overrideBackend) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return b.caller.CodeAt(ctx, contract, blockNumber)
+}
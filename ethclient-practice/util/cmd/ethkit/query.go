@@ -0,0 +1,546 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/output"
+)
+
+// 以下 xxxView 是 --json 输出的结构，金额均为 wei（或代币最小单位）的十进制字符串
+
+type txView struct {
+	Hash                 string   `json:"hash"`
+	Type                 string   `json:"type"`
+	From                 string   `json:"from"`
+	To                   *string  `json:"to"` // 合约创建交易为 null
+	Value                string   `json:"value"`
+	Nonce                uint64   `json:"nonce"`
+	Gas                  uint64   `json:"gas"`
+	GasPrice             string   `json:"gasPrice,omitempty"`
+	MaxFeePerGas         string   `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas string   `json:"maxPriorityFeePerGas,omitempty"`
+	MaxFeePerBlobGas     string   `json:"maxFeePerBlobGas,omitempty"`
+	BlobVersionedHashes  []string `json:"blobVersionedHashes,omitempty"`
+	Input                string   `json:"input"`
+	Pending              bool     `json:"pending"`
+}
+
+func newTxView(tx *chain.Tx) *txView {
+	v := &txView{
+		Hash:    tx.Hash.Hex(),
+		Type:    tx.TypeName(),
+		From:    tx.From.Hex(),
+		Value:   tx.Value.String(),
+		Nonce:   tx.Nonce,
+		Gas:     tx.Gas,
+		Input:   hexutil.Encode(tx.Data),
+		Pending: tx.Pending,
+	}
+	if tx.To != nil {
+		to := tx.To.Hex()
+		v.To = &to
+	}
+	if tx.IsDynamicFee() {
+		v.MaxFeePerGas, v.MaxPriorityFeePerGas = tx.GasFeeCap.String(), tx.GasTipCap.String()
+	} else {
+		v.GasPrice = tx.GasPrice.String()
+	}
+	if tx.BlobFeeCap != nil {
+		v.MaxFeePerBlobGas = tx.BlobFeeCap.String()
+	}
+	for _, h := range tx.BlobHashes {
+		v.BlobVersionedHashes = append(v.BlobVersionedHashes, h.Hex())
+	}
+	return v
+}
+
+type logView struct {
+	Index   uint     `json:"index"`
+	Address string   `json:"address"`
+	Topics  []string `json:"topics"`
+	Data    string   `json:"data"`
+}
+
+type receiptView struct {
+	TxHash            string     `json:"txHash"`
+	BlockNumber       uint64     `json:"blockNumber"`
+	BlockHash         string     `json:"blockHash"`
+	TransactionIndex  uint       `json:"transactionIndex"`
+	Status            uint64     `json:"status"`
+	GasUsed           uint64     `json:"gasUsed"`
+	CumulativeGasUsed uint64     `json:"cumulativeGasUsed"`
+	EffectiveGasPrice string     `json:"effectiveGasPrice,omitempty"`
+	Fee               string     `json:"fee,omitempty"`
+	BlobGasUsed       uint64     `json:"blobGasUsed,omitempty"`
+	BlobGasPrice      string     `json:"blobGasPrice,omitempty"`
+	BlobFee           string     `json:"blobFee,omitempty"`
+	ContractAddress   *string    `json:"contractAddress"`
+	Logs              []*logView `json:"logs"`
+}
+
+func newReceiptView(r *chain.Receipt) *receiptView {
+	v := &receiptView{
+		TxHash:            r.TxHash.Hex(),
+		BlockNumber:       r.BlockNumber,
+		BlockHash:         r.BlockHash.Hex(),
+		TransactionIndex:  r.TransactionIndex,
+		Status:            r.Status,
+		GasUsed:           r.GasUsed,
+		CumulativeGasUsed: r.CumulativeGasUsed,
+		Logs:              []*logView{},
+	}
+	if r.EffectiveGasPrice != nil {
+		v.EffectiveGasPrice = r.EffectiveGasPrice.String()
+		v.Fee = r.Fee().String()
+	}
+	if blobFee := r.BlobFee(); blobFee != nil {
+		v.BlobGasUsed, v.BlobGasPrice, v.BlobFee = r.BlobGasUsed, r.BlobGasPrice.String(), blobFee.String()
+	}
+	if r.ContractAddress != nil {
+		addr := r.ContractAddress.Hex()
+		v.ContractAddress = &addr
+	}
+	for _, log := range r.Logs {
+		lv := &logView{Index: log.Index, Address: log.Address.Hex(), Data: hexutil.Encode(log.Data)}
+		for _, topic := range log.Topics {
+			lv.Topics = append(lv.Topics, topic.Hex())
+		}
+		v.Logs = append(v.Logs, lv)
+	}
+	return v
+}
+
+type blockView struct {
+	Number        uint64    `json:"number"`
+	Hash          string    `json:"hash"`
+	ParentHash    string    `json:"parentHash"`
+	Timestamp     int64     `json:"timestamp"`
+	Miner         string    `json:"miner"`
+	GasUsed       uint64    `json:"gasUsed"`
+	GasLimit      uint64    `json:"gasLimit"`
+	BaseFee       string    `json:"baseFeePerGas,omitempty"`
+	BlobGasUsed   *uint64   `json:"blobGasUsed,omitempty"`
+	ExcessBlobGas *uint64   `json:"excessBlobGas,omitempty"`
+	BlobCount     int       `json:"blobCount"`
+	TxCount       int       `json:"txCount"`
+	Transactions  []*txView `json:"transactions,omitempty"`
+}
+
+func newBlockView(b *chain.Block, withTxs bool) *blockView {
+	v := &blockView{
+		Number:     b.Number,
+		Hash:       b.Hash.Hex(),
+		ParentHash: b.ParentHash.Hex(),
+		Timestamp:  b.Time.Unix(),
+		Miner:      b.Miner.Hex(),
+		GasUsed:    b.GasUsed,
+		GasLimit:   b.GasLimit,
+		TxCount:    len(b.Transactions),
+
+		BlobGasUsed:   b.BlobGasUsed,
+		ExcessBlobGas: b.ExcessBlobGas,
+		BlobCount:     b.BlobCount(),
+	}
+	if b.BaseFee != nil {
+		v.BaseFee = b.BaseFee.String()
+	}
+	if withTxs {
+		for _, tx := range b.Transactions {
+			v.Transactions = append(v.Transactions, newTxView(tx))
+		}
+	}
+	return v
+}
+
+func runBlock(app *app, args []string) error {
+	fs := app.flags("block", "[number|latest]")
+	withTxs := fs.Bool("txs", false, "列出区块内的交易")
+	fs.Parse(args)
+
+	number, err := parseBlockNumber(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	ctx, cancel := app.context()
+	defer cancel()
+	client, err := app.dial(ctx)
+	if err != nil {
+		return err
+	}
+	block, err := chain.GetBlock(ctx, client, number)
+	if err != nil {
+		return err
+	}
+
+	return app.emit(newBlockView(block, *withTxs), func(w io.Writer) {
+		baseFee, blob := "", ""
+		if block.BaseFee != nil {
+			baseFee = gwei(block.BaseFee) + " Gwei"
+		}
+		if fees := block.BlobFees(); fees != nil {
+			blob = fmt.Sprintf("%d 个，价格 %s Gwei，费用 %s ETH", block.BlobCount(), gwei(block.BlobGasPrice), chain.FormatEther(fees))
+		}
+		writeFields(w,
+			"区块号", strconv.FormatUint(block.Number, 10),
+			"哈希", block.Hash.Hex(),
+			"父区块", block.ParentHash.Hex(),
+			"时间", block.Time.Format("2006-01-02 15:04:05 UTC"),
+			"矿工", block.Miner.Hex(),
+			"Gas", fmt.Sprintf("%d / %d (%.1f%%)", block.GasUsed, block.GasLimit, block.GasUsage()),
+			"Base Fee", baseFee,
+			"Blob", blob,
+			"交易数", strconv.Itoa(len(block.Transactions)),
+		)
+		if !*withTxs || len(block.Transactions) == 0 {
+			return
+		}
+		fmt.Fprintln(w)
+		t := output.NewTable("#", "哈希", "From", "To", "金额 (ETH)", "Gas", "类型")
+		for i, tx := range block.Transactions {
+			t.Add(strconv.Itoa(i), shorten(tx.Hash.Hex()), shorten(tx.From.Hex()), shorten(orCreation(tx.To)),
+				chain.FormatEther(tx.Value), strconv.FormatUint(tx.Gas, 10), tx.TypeName())
+		}
+		t.Write(w)
+	})
+}
+
+func runTx(app *app, args []string) error {
+	fs := app.flags("tx", "<hash>")
+	fs.Parse(args)
+	hash, err := parseHash(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := app.context()
+	defer cancel()
+	client, err := app.dial(ctx)
+	if err != nil {
+		return err
+	}
+	status, err := chain.GetTxStatus(ctx, client, hash)
+	if err != nil {
+		return err
+	}
+
+	view := struct {
+		*txView
+		Receipt *receiptView `json:"receipt"`
+	}{txView: newTxView(status.Tx)}
+	if status.Receipt != nil {
+		view.Receipt = newReceiptView(status.Receipt)
+	}
+
+	return app.emit(view, func(w io.Writer) {
+		tx := status.Tx
+		fields := []string{
+			"哈希", tx.Hash.Hex(),
+			"类型", tx.TypeName(),
+			"From", tx.From.Hex(),
+			"To", orCreation(tx.To),
+			"金额", chain.FormatEther(tx.Value) + " ETH",
+			"Nonce", strconv.FormatUint(tx.Nonce, 10),
+			"Gas 上限", strconv.FormatUint(tx.Gas, 10),
+		}
+		if tx.IsDynamicFee() {
+			fields = append(fields, "Max Fee", gwei(tx.GasFeeCap)+" Gwei", "Priority Fee", gwei(tx.GasTipCap)+" Gwei")
+		} else {
+			fields = append(fields, "Gas 价格", gwei(tx.GasPrice)+" Gwei")
+		}
+		if tx.BlobCount() > 0 {
+			fields = append(fields, "Blob", fmt.Sprintf("%d 个（blob gas %d）", tx.BlobCount(), tx.BlobGas()),
+				"Max Blob Fee", gwei(tx.BlobFeeCap)+" Gwei")
+		}
+		fields = append(fields, "输入数据", fmt.Sprintf("%d 字节", len(tx.Data)))
+		if r := status.Receipt; r != nil {
+			fields = append(fields,
+				"状态", statusText(r),
+				"区块", strconv.FormatUint(r.BlockNumber, 10),
+				"Gas 使用", fmt.Sprintf("%d (%.1f%%)", r.GasUsed, status.GasUsage()),
+				"手续费", etherOrEmpty(r.Fee()),
+				"Blob 费用", etherOrEmpty(r.BlobFee()),
+			)
+		} else {
+			fields = append(fields, "状态", "pending")
+		}
+		writeFields(w, fields...)
+	})
+}
+
+func runReceipt(app *app, args []string) error {
+	fs := app.flags("receipt", "<hash>")
+	fs.Parse(args)
+	hash, err := parseHash(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := app.context()
+	defer cancel()
+	client, err := app.dial(ctx)
+	if err != nil {
+		return err
+	}
+	receipt, err := chain.GetReceipt(ctx, client, hash)
+	if err != nil {
+		return err
+	}
+
+	return app.emit(newReceiptView(receipt), func(w io.Writer) {
+		writeFields(w,
+			"交易", receipt.TxHash.Hex(),
+			"状态", statusText(receipt),
+			"区块", fmt.Sprintf("%d (%s)", receipt.BlockNumber, receipt.BlockHash.Hex()),
+			"交易索引", strconv.FormatUint(uint64(receipt.TransactionIndex), 10),
+			"Gas 使用", strconv.FormatUint(receipt.GasUsed, 10),
+			"累计 Gas", strconv.FormatUint(receipt.CumulativeGasUsed, 10),
+			"实际 Gas 价格", gweiOrEmpty(receipt.EffectiveGasPrice),
+			"手续费", etherOrEmpty(receipt.Fee()),
+			"Blob 费用", etherOrEmpty(receipt.BlobFee()),
+			"合约地址", toString(receipt.ContractAddress),
+			"日志数", strconv.Itoa(len(receipt.Logs)),
+		)
+		if len(receipt.Logs) == 0 {
+			return
+		}
+		fmt.Fprintln(w)
+		t := output.NewTable("#", "合约", "Topic0", "数据")
+		for _, log := range receipt.Logs {
+			topic0 := ""
+			if len(log.Topics) > 0 {
+				topic0 = shorten(log.Topics[0].Hex())
+			}
+			t.Add(strconv.FormatUint(uint64(log.Index), 10), log.Address.Hex(), topic0, fmt.Sprintf("%d 字节", len(log.Data)))
+		}
+		t.Write(w)
+	})
+}
+
+type balanceView struct {
+	Address  string `json:"address"`
+	Balance  string `json:"balance,omitempty"`
+	Decimals uint8  `json:"decimals"`
+	Error    string `json:"error,omitempty"`
+}
+
+func runBalance(app *app, args []string) error {
+	fs := app.flags("balance", "<address>...")
+	blockFlag := fs.String("block", "latest", "查询的区块号（仅 ETH 余额）")
+	tokenFlag := fs.String("token", "", "查询该 ERC20 代币的余额")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return usageError("需要至少一个地址")
+	}
+	addresses := make([]common.Address, fs.NArg())
+	for i, arg := range fs.Args() {
+		addr, err := parseAddress(arg)
+		if err != nil {
+			return err
+		}
+		addresses[i] = addr
+	}
+	number, err := parseBlockNumber(*blockFlag)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := app.context()
+	defer cancel()
+	client, err := app.dial(ctx)
+	if err != nil {
+		return err
+	}
+
+	unit, decimals := "ETH", uint8(chain.EtherDecimals)
+	var balances []*chain.Balance
+	if *tokenFlag != "" {
+		tokenAddr, err := parseAddress(*tokenFlag)
+		if err != nil {
+			return err
+		}
+		token, err := chain.GetToken(ctx, client, tokenAddr)
+		if err != nil {
+			return err
+		}
+		unit, decimals = token.Symbol, token.Decimals
+		for _, addr := range addresses {
+			balance, err := chain.TokenBalance(ctx, client, tokenAddr, addr)
+			balances = append(balances, &chain.Balance{Address: addr, Wei: balance, Err: err})
+		}
+	} else {
+		balances = chain.GetBalances(ctx, client, addresses, number)
+	}
+
+	views := make([]*balanceView, len(balances))
+	for i, b := range balances {
+		views[i] = &balanceView{Address: b.Address.Hex(), Decimals: decimals}
+		if b.Err != nil {
+			views[i].Error = b.Err.Error()
+		} else {
+			views[i].Balance = b.Wei.String()
+		}
+	}
+
+	return app.emit(views, func(w io.Writer) {
+		t := output.NewTable("地址", "余额 ("+unit+")")
+		for _, b := range balances {
+			if b.Err != nil {
+				t.Add(b.Address.Hex(), "错误: "+b.Err.Error())
+				continue
+			}
+			t.Add(b.Address.Hex(), chain.FormatUnits(b.Wei, decimals))
+		}
+		if len(balances) > 1 {
+			t.Add("总计", chain.FormatUnits(chain.TotalBalance(balances), decimals))
+		}
+		t.Write(w)
+	})
+}
+
+// accountView 账户类型，Delegate 仅在 EIP-7702 委托账户时有值
+type accountView struct {
+	Address          string `json:"address"`
+	Kind             string `json:"kind"`
+	Nonce            uint64 `json:"nonce"`
+	CodeSize         int    `json:"codeSize"`
+	Delegate         string `json:"delegate,omitempty"`
+	DelegateCodeSize int    `json:"delegateCodeSize,omitempty"`
+	Error            string `json:"error,omitempty"`
+}
+
+func runAccount(app *app, args []string) error {
+	fs := app.flags("account", "<address>...")
+	blockFlag := fs.String("block", "latest", "查询的区块号")
+	fs.Parse(args)
+	if fs.NArg() == 0 {
+		return usageError("需要至少一个地址")
+	}
+	addresses := make([]common.Address, fs.NArg())
+	for i, arg := range fs.Args() {
+		addr, err := parseAddress(arg)
+		if err != nil {
+			return err
+		}
+		addresses[i] = addr
+	}
+	number, err := parseBlockNumber(*blockFlag)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := app.context()
+	defer cancel()
+	client, err := app.dial(ctx)
+	if err != nil {
+		return err
+	}
+
+	views := make([]*accountView, len(addresses))
+	for i, addr := range addresses {
+		views[i] = &accountView{Address: addr.Hex()}
+		account, err := chain.InspectAccount(ctx, client, addr, number)
+		if err != nil {
+			views[i].Error = err.Error()
+			continue
+		}
+		views[i].Kind = account.Kind.String()
+		views[i].Nonce = account.Nonce
+		views[i].CodeSize = account.CodeSize
+		if account.Kind == chain.AccountDelegated {
+			views[i].Delegate = account.Delegate.Hex()
+			views[i].DelegateCodeSize = account.DelegateCodeSize
+		}
+	}
+
+	return app.emit(views, func(w io.Writer) {
+		t := output.NewTable("地址", "类型", "Nonce", "委托给")
+		for _, v := range views {
+			if v.Error != "" {
+				t.Add(v.Address, "错误: "+v.Error, "", "")
+				continue
+			}
+			delegate := v.Delegate
+			if delegate != "" && v.DelegateCodeSize == 0 {
+				delegate += "（没有代码）"
+			}
+			t.Add(v.Address, v.Kind, strconv.FormatUint(v.Nonce, 10), delegate)
+		}
+		t.Write(w)
+	})
+}
+
+// parseBlockNumber 解析区块号：空或 latest 为 nil（最新区块），支持十进制和 0x 十六进制
+func parseBlockNumber(s string) (*big.Int, error) {
+	if s == "" || s == "latest" {
+		return nil, nil
+	}
+	if n, ok := new(big.Int).SetString(s, 0); ok && n.Sign() >= 0 {
+		return n, nil
+	}
+	return nil, usageError("无效的区块号 %q", s)
+}
+
+func parseHash(s string) (common.Hash, error) {
+	if s == "" {
+		return common.Hash{}, usageError("需要交易哈希")
+	}
+	b, err := hexutil.Decode(s)
+	if err != nil || len(b) != common.HashLength {
+		return common.Hash{}, usageError("无效的哈希 %q", s)
+	}
+	return common.BytesToHash(b), nil
+}
+
+func parseAddress(s string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, usageError("无效的地址 %q", s)
+	}
+	return common.HexToAddress(s), nil
+}
+
+func toString(addr *common.Address) string {
+	if addr == nil {
+		return ""
+	}
+	return addr.Hex()
+}
+
+func statusText(r *chain.Receipt) string {
+	if r.Success() {
+		return "成功"
+	}
+	return "失败"
+}
+
+func gwei(v *big.Int) string {
+	return chain.FormatUnits(v, 9)
+}
+
+func gweiOrEmpty(v *big.Int) string {
+	if v == nil {
+		return ""
+	}
+	return gwei(v) + " Gwei"
+}
+
+func etherOrEmpty(v *big.Int) string {
+	if v == nil {
+		return ""
+	}
+	return chain.FormatEther(v) + " ETH"
+}
+
+// orCreation 交易的 To，合约创建交易显示为 <合约创建>
+func orCreation(to *common.Address) string {
+	if to == nil {
+		return "<合约创建>"
+	}
+	return to.Hex()
+}
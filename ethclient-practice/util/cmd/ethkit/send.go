@@ -0,0 +1,348 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"flag"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+)
+
+// sentView 已发送交易的结果，Receipt 在 --no-wait 时为 null
+type sentView struct {
+	Hash       string           `json:"hash"`
+	From       string           `json:"from"`
+	To         *string          `json:"to"`
+	Nonce      uint64           `json:"nonce"`
+	Gas        uint64           `json:"gas"`
+	Value      string           `json:"value"`
+	Receipt    *receiptView     `json:"receipt"`
+	Explorer   string           `json:"explorer,omitempty"`
+	AccessList types.AccessList `json:"accessList,omitempty"`
+}
+
+// simView --dry-run 的结果。RawTx 为签名交易的十六进制编码，交易会失败且没有指定 Gas 上限时为空
+type simView struct {
+	From         string           `json:"from"`
+	To           *string          `json:"to"`
+	Contract     *string          `json:"contractAddress,omitempty"`
+	Value        string           `json:"value"`
+	EstimatedGas uint64           `json:"estimatedGas"`
+	Reverted     bool             `json:"reverted"`
+	Error        string           `json:"error,omitempty"`
+	ReturnData   string           `json:"returnData"`
+	RawTx        string           `json:"rawTx,omitempty"`
+	AccessList   types.AccessList `json:"accessList,omitempty"`
+}
+
+// sendFlags send / token 命令共用的发送参数
+type sendFlags struct {
+	gasLimit   *uint64
+	accessList *bool
+	noWait     *bool
+	dryRun     *bool
+	interval   *time.Duration
+}
+
+func addSendFlags(fs *flag.FlagSet) *sendFlags {
+	return &sendFlags{
+		gasLimit:   fs.Uint64("gas-limit", 0, "Gas 上限，0 表示估算后加 20% 余量"),
+		accessList: fs.Bool("access-list", false, "用 eth_createAccessList 生成 EIP-2930 访问列表，能节省 Gas 时附加到交易"),
+		noWait:     fs.Bool("no-wait", false, "发送后不等待确认"),
+		dryRun:     fs.Bool("dry-run", false, "只签名并在 pending 状态上模拟执行，打印签名交易，不发送"),
+		interval:   fs.Duration("poll", chain.DefaultPollInterval, "查询收据的间隔"),
+	}
+}
+
+func runSend(app *app, args []string) error {
+	fs := app.flags("send", "<to> <amount>")
+	data := fs.String("data", "", "附带的调用数据（0x 十六进制）")
+	sf := addSendFlags(fs)
+	fs.Parse(args)
+	if fs.NArg() != 2 {
+		return usageError("需要接收地址和数量（单位 ETH），如: ethkit send 0x... 0.01")
+	}
+	to, err := parseAddress(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	value, err := chain.ParseEther(fs.Arg(1))
+	if err != nil {
+		return usageError("%v", err)
+	}
+	input, err := hexutil.Decode(orHexEmpty(*data))
+	if err != nil {
+		return usageError("--data 格式错误: %v", err)
+	}
+
+	return app.send(sf, &chain.TxRequest{To: &to, Value: value, Data: input, GasLimit: *sf.gasLimit})
+}
+
+func runToken(app *app, args []string) error {
+	sub, args, err := subcommand(args, "transfer", "approve", "allowance")
+	if err != nil {
+		return err
+	}
+	switch sub {
+	case "transfer":
+		return runTokenWrite(app, "transfer", "<token> <to> <amount>", args)
+	case "approve":
+		return runTokenWrite(app, "approve", "<token> <spender> <amount|max>", args)
+	default:
+		return runAllowance(app, args)
+	}
+}
+
+// runTokenWrite token transfer / approve：数量按代币的 decimals 换算
+func runTokenWrite(app *app, method, usage string, args []string) error {
+	fs := app.flags("token "+method, usage)
+	sf := addSendFlags(fs)
+	fs.Parse(args)
+	if fs.NArg() != 3 {
+		return usageError("用法: ethkit token %s %s", method, usage)
+	}
+	tokenAddr, err := parseAddress(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	target, err := parseAddress(fs.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := app.context()
+	defer cancel()
+	client, err := app.dial(ctx)
+	if err != nil {
+		return err
+	}
+	token, err := chain.GetToken(ctx, client, tokenAddr)
+	if err != nil {
+		return err
+	}
+	var amount *big.Int
+	if method == "approve" && fs.Arg(2) == "max" {
+		amount = math.MaxBig256 // 无限授权
+	} else if amount, err = chain.ParseUnits(fs.Arg(2), token.Decimals); err != nil {
+		return usageError("%v", err)
+	}
+
+	data := chain.TransferData(target, amount)
+	if method == "approve" {
+		data = chain.ApproveData(target, amount)
+	}
+	if !app.opts.json {
+		fmt.Fprintf(app.out, "%s %s %s → %s\n", method, fs.Arg(2), token.Symbol, target.Hex())
+	}
+	return app.send(sf, &chain.TxRequest{To: &tokenAddr, Data: data, GasLimit: *sf.gasLimit})
+}
+
+func runAllowance(app *app, args []string) error {
+	fs := app.flags("token allowance", "<token> <owner> <spender>")
+	fs.Parse(args)
+	if fs.NArg() != 3 {
+		return usageError("用法: ethkit token allowance <token> <owner> <spender>")
+	}
+	var addrs [3]common.Address
+	for i := range addrs {
+		addr, err := parseAddress(fs.Arg(i))
+		if err != nil {
+			return err
+		}
+		addrs[i] = addr
+	}
+
+	ctx, cancel := app.context()
+	defer cancel()
+	client, err := app.dial(ctx)
+	if err != nil {
+		return err
+	}
+	token, err := chain.GetToken(ctx, client, addrs[0])
+	if err != nil {
+		return err
+	}
+	allowance, err := chain.TokenAllowance(ctx, client, addrs[0], addrs[1], addrs[2])
+	if err != nil {
+		return err
+	}
+
+	view := struct {
+		Token     string `json:"token"`
+		Owner     string `json:"owner"`
+		Spender   string `json:"spender"`
+		Allowance string `json:"allowance"`
+		Decimals  uint8  `json:"decimals"`
+	}{addrs[0].Hex(), addrs[1].Hex(), addrs[2].Hex(), allowance.String(), token.Decimals}
+	return app.emit(view, func(w io.Writer) {
+		amount := chain.FormatUnits(allowance, token.Decimals) + " " + token.Symbol
+		if allowance.Cmp(math.MaxBig256) == 0 {
+			amount = "无限"
+		}
+		writeFields(w,
+			"代币", fmt.Sprintf("%s (%s)", addrs[0].Hex(), token.Symbol),
+			"Owner", addrs[1].Hex(),
+			"Spender", addrs[2].Hex(),
+			"授权额度", amount,
+		)
+	})
+}
+
+// send 签名、发送交易并（默认）等待确认
+func (a *app) send(sf *sendFlags, req *chain.TxRequest) error {
+	key, err := a.signer()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := a.context()
+	defer cancel()
+	client, err := a.dial(ctx)
+	if err != nil {
+		return err
+	}
+
+	if *sf.accessList {
+		cmp, err := chain.AttachAccessList(ctx, client.Client(), crypto.PubkeyToAddress(key.PublicKey), req)
+		if err != nil {
+			return err
+		}
+		if !a.opts.json {
+			cmp.WriteReport(a.out)
+			if req.AccessList != nil {
+				fmt.Fprint(a.out, "已附加访问列表\n\n")
+			} else {
+				fmt.Fprint(a.out, "访问列表不能节省 Gas，不附加\n\n")
+			}
+		}
+	}
+
+	if *sf.dryRun {
+		sim, err := chain.Simulate(ctx, client, key, req)
+		if err != nil {
+			return err
+		}
+		return a.emit(newSimView(sim), func(w io.Writer) { sim.WriteReport(w, nil) })
+	}
+
+	tx, err := chain.Send(ctx, client, key, req)
+	if err != nil {
+		return err
+	}
+	view := newSentView(tx, key, a.explorer(ctx, client, tx.Hash()))
+	if !a.opts.json {
+		fmt.Fprintf(a.out, "交易已发送: %s\n", tx.Hash().Hex())
+	}
+	var receipt *chain.Receipt
+	if !*sf.noWait {
+		if receipt, err = chain.WaitReceipt(ctx, client, tx.Hash(), *sf.interval); err != nil {
+			return err
+		}
+		view.Receipt = newReceiptView(receipt)
+	}
+
+	return a.emit(view, func(w io.Writer) {
+		fields := []string{
+			"From", view.From,
+			"To", orCreation(tx.To()),
+			"Nonce", strconv.FormatUint(tx.Nonce(), 10),
+			"Gas 上限", strconv.FormatUint(tx.Gas(), 10),
+			"金额", chain.FormatEther(tx.Value()) + " ETH",
+			"浏览器", view.Explorer,
+		}
+		if receipt != nil {
+			fields = append(fields,
+				"状态", statusText(receipt),
+				"区块", strconv.FormatUint(receipt.BlockNumber, 10),
+				"Gas 使用", strconv.FormatUint(receipt.GasUsed, 10),
+			)
+		}
+		writeFields(w, fields...)
+	})
+}
+
+func newSentView(tx *types.Transaction, key *ecdsa.PrivateKey, explorer string) *sentView {
+	v := &sentView{
+		Hash:     tx.Hash().Hex(),
+		From:     crypto.PubkeyToAddress(key.PublicKey).Hex(),
+		Nonce:    tx.Nonce(),
+		Gas:      tx.Gas(),
+		Value:    tx.Value().String(),
+		Explorer: explorer,
+	}
+	if tx.Type() != types.LegacyTxType {
+		v.AccessList = tx.AccessList()
+	}
+	if tx.To() != nil {
+		to := tx.To().Hex()
+		v.To = &to
+	}
+	return v
+}
+
+// explorers 链 ID → 区块浏览器交易页前缀
+var explorers = map[uint64]string{
+	1:        "https://etherscan.io/tx/",
+	11155111: "https://sepolia.etherscan.io/tx/",
+	17000:    "https://holesky.etherscan.io/tx/",
+}
+
+// explorer 交易在区块浏览器中的链接，未知网络返回空字符串
+func (a *app) explorer(ctx context.Context, client *ethclient.Client, hash common.Hash) string {
+	chainID, err := client.ChainID(ctx)
+	if err != nil || !chainID.IsUint64() {
+		return ""
+	}
+	if prefix, ok := explorers[chainID.Uint64()]; ok {
+		return prefix + hash.Hex()
+	}
+	return ""
+}
+
+func orHexEmpty(s string) string {
+	if s == "" {
+		return "0x"
+	}
+	return s
+}
+
+func newSimView(sim *chain.Simulation) *simView {
+	view := &simView{
+		From:         sim.From.Hex(),
+		Value:        sim.Msg.Value.String(),
+		EstimatedGas: sim.EstimatedGas,
+		Reverted:     sim.Reverted(),
+		ReturnData:   hexutil.Encode(sim.ReturnData),
+	}
+	if sim.Msg.To != nil {
+		to := sim.Msg.To.Hex()
+		view.To = &to
+	}
+	if addr := sim.ContractAddress(); addr != nil {
+		contract := addr.Hex()
+		view.Contract = &contract
+	}
+	if err := sim.CallErr; err != nil {
+		view.Error = err.Error()
+	} else if err := sim.EstimateErr; err != nil {
+		view.Error = err.Error()
+	}
+	if raw, err := sim.RawTx(); err == nil {
+		view.RawTx = hexutil.Encode(raw)
+	}
+	if sim.Tx != nil && sim.Tx.Type() != types.LegacyTxType {
+		view.AccessList = sim.Tx.AccessList()
+	}
+	return view
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/sigdb"
+)
+
+// traceView trace 命令的结果：callTracer 的调用树，--prestate 时附带状态变化
+type traceView struct {
+	Hash      string           `json:"hash"`
+	Calls     *chain.CallFrame `json:"calls"`
+	StateDiff *chain.StateDiff `json:"stateDiff,omitempty"`
+}
+
+// runTrace 用 debug_traceTransaction 重放交易，需要节点开放 debug 命名空间
+func runTrace(app *app, args []string) error {
+	fs := app.flags("trace", "<hash>")
+	prestate := fs.Bool("prestate", false, "同时用 prestateTracer 打印执行前后的状态变化")
+	sigsPath := fs.String("sigs", "", "额外加载的 ABI / 编译产物 / 文本签名文件，用于解码调用")
+	fs.Parse(args)
+	hash, err := parseHash(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	registry := sigdb.NewDefault()
+	if *sigsPath != "" {
+		if _, err := registry.LoadFile(*sigsPath); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := app.context()
+	defer cancel()
+	client, err := app.dial(ctx)
+	if err != nil {
+		return err
+	}
+	frame, err := chain.TraceTransaction(ctx, client.Client(), hash)
+	if err != nil {
+		return err
+	}
+	view := traceView{Hash: hash.Hex(), Calls: frame}
+	if *prestate {
+		if view.StateDiff, err = chain.TraceTransactionDiff(ctx, client.Client(), hash); err != nil {
+			return err
+		}
+	}
+
+	return app.emit(view, func(w io.Writer) {
+		frame.WriteTree(w, registry)
+		if view.StateDiff != nil {
+			fmt.Fprintln(w, "\n状态变化:")
+			view.StateDiff.WriteDiff(w)
+		}
+	})
+}
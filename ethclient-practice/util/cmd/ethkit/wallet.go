@@ -0,0 +1,106 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// walletView 钱包信息。私钥只在未保存到 keystore 时输出
+type walletView struct {
+	Address    string `json:"address"`
+	PublicKey  string `json:"publicKey"`
+	PrivateKey string `json:"privateKey,omitempty"`
+	Keystore   string `json:"keystore,omitempty"`
+}
+
+func runWallet(app *app, args []string) error {
+	sub, args, err := subcommand(args, "new", "restore")
+	if err != nil {
+		return err
+	}
+	if sub == "new" {
+		return runWalletNew(app, args)
+	}
+	return runWalletRestore(app, args)
+}
+
+func runWalletNew(app *app, args []string) error {
+	fs := app.flags("wallet new", "")
+	dir := fs.String("keystore-dir", "", "把私钥加密保存到该目录（密码读取 --password 或 KEYSTORE_PASSWORD），不再输出私钥")
+	fs.Parse(args)
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return err
+	}
+	view := newWalletView(key)
+	if *dir == "" {
+		view.PrivateKey = hexutil.Encode(crypto.FromECDSA(key))[2:]
+	} else {
+		if app.opts.password == "" {
+			return errors.New("保存 keystore 需要密码: 使用 --password 或设置 KEYSTORE_PASSWORD")
+		}
+		ks := keystore.NewKeyStore(*dir, keystore.StandardScryptN, keystore.StandardScryptP)
+		account, err := ks.ImportECDSA(key, app.opts.password)
+		if err != nil {
+			return err
+		}
+		view.Keystore = account.URL.Path
+	}
+
+	return app.emit(view, func(w io.Writer) {
+		writeWallet(w, view)
+		if view.PrivateKey != "" {
+			fmt.Fprintln(w, "\n⚠️  私钥即资产控制权，请妥善保存，永远不要分享")
+		}
+	})
+}
+
+func runWalletRestore(app *app, args []string) error {
+	fs := app.flags("wallet restore", "[private-key]")
+	fs.Parse(args)
+
+	var key *ecdsa.PrivateKey
+	var err error
+	switch {
+	case fs.NArg() == 1:
+		if key, err = crypto.HexToECDSA(strings.TrimPrefix(fs.Arg(0), "0x")); err != nil {
+			return usageError("私钥格式错误: %v", err)
+		}
+	case fs.NArg() == 0 && (app.opts.keystore != "" || app.opts.key != ""):
+		if key, err = app.signer(); err != nil {
+			return err
+		}
+	default:
+		return usageError("需要私钥参数，或使用 --keystore / PRIVATE_KEY")
+	}
+
+	view := newWalletView(key)
+	view.Keystore = app.opts.keystore
+	return app.emit(view, func(w io.Writer) {
+		writeWallet(w, view)
+	})
+}
+
+func newWalletView(key *ecdsa.PrivateKey) *walletView {
+	return &walletView{
+		Address:   crypto.PubkeyToAddress(key.PublicKey).Hex(),
+		PublicKey: hexutil.Encode(crypto.FromECDSAPub(&key.PublicKey))[4:], // 去掉 0x04 前缀
+	}
+}
+
+func writeWallet(w io.Writer, view *walletView) {
+	writeFields(w,
+		"地址", view.Address,
+		"公钥", view.PublicKey,
+		"私钥", view.PrivateKey,
+		"Keystore", view.Keystore,
+	)
+}
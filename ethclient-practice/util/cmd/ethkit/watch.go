@@ -0,0 +1,201 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+)
+
+// errStop 回调返回它表示正常结束监听（达到 --count）
+var errStop = errors.New("stop")
+
+func runWatch(app *app, args []string) error {
+	sub, args, err := subcommand(args, "blocks", "address")
+	if err != nil {
+		return err
+	}
+	usage := ""
+	if sub == "address" {
+		usage = "<address>"
+	}
+	fs := app.flags("watch "+sub, usage)
+	interval := fs.Duration("interval", 12*time.Second, "节点不支持订阅（HTTP）时的轮询间隔")
+	count := fs.Int("count", 0, "处理多少个区块后退出，0 表示一直运行")
+	fs.Parse(args)
+
+	var watched common.Address
+	if sub == "address" {
+		if fs.NArg() != 1 {
+			return usageError("需要监听的地址，如: ethkit watch address 0x...")
+		}
+		if watched, err = parseAddress(fs.Arg(0)); err != nil {
+			return err
+		}
+	}
+
+	// 监听不受 --timeout 限制，Ctrl+C 退出
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	client, err := app.dial(ctx)
+	if err != nil {
+		return err
+	}
+
+	seen := 0
+	onBlock := func(number uint64) error {
+		var err error
+		if sub == "blocks" {
+			err = app.emitBlock(ctx, client, number)
+		} else {
+			err = app.emitAddressTxs(ctx, client, watched, number)
+		}
+		if err != nil {
+			return err
+		}
+		if seen++; *count > 0 && seen >= *count {
+			return errStop
+		}
+		return nil
+	}
+	err = followBlocks(ctx, client, *interval, onBlock)
+	if errors.Is(err, errStop) || errors.Is(err, context.Canceled) {
+		return nil
+	}
+	return err
+}
+
+// followBlocks 依次对每个新区块调用 fn。优先使用 eth_subscribe，
+// 节点不支持订阅时改为轮询最新区块号；两次之间跳过的区块会补上
+func followBlocks(ctx context.Context, client *ethclient.Client, interval time.Duration, fn func(number uint64) error) error {
+	var last uint64
+	handle := func(number uint64) error {
+		from := number
+		if last != 0 && number > last+1 {
+			from = last + 1
+		}
+		for n := from; n <= number; n++ {
+			if err := fn(n); err != nil {
+				return err
+			}
+		}
+		last = number
+		return nil
+	}
+
+	headers := make(chan *types.Header)
+	sub, err := client.SubscribeNewHead(ctx, headers)
+	if err == nil {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case err := <-sub.Err():
+				return fmt.Errorf("订阅中断: %w", err)
+			case header := <-headers:
+				if err := handle(header.Number.Uint64()); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	if !errors.Is(err, rpc.ErrNotificationsUnsupported) {
+		return fmt.Errorf("订阅新区块失败: %w", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		header, err := client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return err
+		}
+		if number := header.Number.Uint64(); number > last {
+			if err := handle(number); err != nil {
+				return err
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *app) emitBlock(ctx context.Context, client *ethclient.Client, number uint64) error {
+	block, err := chain.GetBlock(ctx, client, new(big.Int).SetUint64(number))
+	if err != nil {
+		return err
+	}
+	view := newBlockView(block, false)
+	line := fmt.Sprintf("区块 %d  %s  %s  交易 %d  Gas %.1f%%",
+		block.Number, shorten(block.Hash.Hex()), block.Time.Local().Format("15:04:05"),
+		len(block.Transactions), block.GasUsage())
+	if block.BaseFee != nil {
+		line += "  基础费用 " + gwei(block.BaseFee) + " Gwei"
+	}
+	return a.emitLine(view, line)
+}
+
+// addressTxView watch address 输出的一条记录
+type addressTxView struct {
+	Block     uint64  `json:"block"`
+	Direction string  `json:"direction"` // in | out | self
+	Tx        *txView `json:"tx"`
+	Balance   string  `json:"balance"` // 该区块之后的余额（wei）
+}
+
+func (a *app) emitAddressTxs(ctx context.Context, client *ethclient.Client, watched common.Address, number uint64) error {
+	blockNumber := new(big.Int).SetUint64(number)
+	block, err := chain.GetBlock(ctx, client, blockNumber)
+	if err != nil {
+		return err
+	}
+	var balance *big.Int
+	for _, tx := range block.Transactions {
+		direction := txDirection(tx, watched)
+		if direction == "" {
+			continue
+		}
+		if balance == nil {
+			if balance, err = client.BalanceAt(ctx, watched, blockNumber); err != nil {
+				return err
+			}
+		}
+		view := &addressTxView{Block: number, Direction: direction, Tx: newTxView(tx), Balance: balance.String()}
+		line := fmt.Sprintf("区块 %d  %-4s  %s  %s → %s  %s ETH  余额 %s ETH",
+			number, direction, shorten(tx.Hash.Hex()), shorten(tx.From.Hex()), shorten(orCreation(tx.To)),
+			chain.FormatEther(tx.Value), chain.FormatEther(balance))
+		if err := a.emitLine(view, line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// txDirection 交易相对于 watched 的方向，无关交易返回空字符串
+func txDirection(tx *chain.Tx, watched common.Address) string {
+	in := tx.To != nil && *tx.To == watched
+	out := tx.From == watched
+	switch {
+	case in && out:
+		return "self"
+	case in:
+		return "in"
+	case out:
+		return "out"
+	}
+	return ""
+}
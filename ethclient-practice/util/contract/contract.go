@@ -0,0 +1,223 @@
+// Package contract 不使用 abigen，按 ABI 文件动态调用任意合约。
+//
+// 方法名可以写简单名称（setItem），存在重载时写完整签名（setItem(bytes32,bytes32)）。
+// 参数既可以是 Go 值，也可以是命令行字符串；字符串会按 ABI 类型由 abiarg 转换，
+// 因此元组、数组、定长 bytes 都可以直接从命令行传入。
+package contract
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/dapp-learning/ethclient/util/abiarg"
+	"github.com/dapp-learning/ethclient/util/deploy"
+	"github.com/dapp-learning/ethclient/util/sigdb"
+)
+
+var (
+	// ErrUnknownMethod ABI 中没有该方法
+	ErrUnknownMethod = errors.New("ABI 中没有该方法")
+	// ErrUnknownEvent ABI 中没有该事件
+	ErrUnknownEvent = errors.New("ABI 中没有该事件")
+	// ErrAmbiguous 名称对应多个重载，需要使用完整签名
+	ErrAmbiguous = errors.New("存在多个重载，请使用完整签名")
+)
+
+// Contract 由 ABI 驱动的合约客户端
+type Contract struct {
+	Address common.Address
+	ABI     abi.ABI
+
+	backend bind.ContractBackend
+	bound   *bind.BoundContract
+}
+
+// New 使用已解析的 ABI 创建合约客户端
+func New(address common.Address, parsed abi.ABI, backend bind.ContractBackend) *Contract {
+	return &Contract{
+		Address: address,
+		ABI:     parsed,
+		backend: backend,
+		bound:   bind.NewBoundContract(address, parsed, backend, backend, backend),
+	}
+}
+
+// Load 从 ABI 文件创建合约客户端，文件格式见 LoadABI
+func Load(address common.Address, path string, backend bind.ContractBackend) (*Contract, error) {
+	parsed, err := LoadABI(path)
+	if err != nil {
+		return nil, err
+	}
+	return New(address, parsed, backend), nil
+}
+
+// LoadABI 读取 ABI 文件。支持纯 ABI 数组（solcjs 的 .abi）、带 "abi" 字段的
+// Hardhat / Foundry 产物，以及只有一个合约的 solc 标准 JSON / combined-json 输出
+func LoadABI(path string) (abi.ABI, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return abi.ABI{}, err
+	}
+	if extracted, err := sigdb.ExtractABI(raw); err == nil {
+		parsed, err := abi.JSON(strings.NewReader(string(extracted)))
+		if err != nil {
+			return abi.ABI{}, fmt.Errorf("%s: 解析 ABI 失败: %w", path, err)
+		}
+		return parsed, nil
+	}
+	art, err := deploy.LoadArtifact(path, "")
+	if err != nil {
+		return abi.ABI{}, err
+	}
+	return art.ABI, nil
+}
+
+// Method 按名称或完整签名查找方法
+func (c *Contract) Method(name string) (*abi.Method, error) {
+	var found []abi.Method
+	for _, m := range c.ABI.Methods {
+		if m.RawName == name || m.Sig == strings.ReplaceAll(name, " ", "") {
+			found = append(found, m)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("%w: %s（可用: %s）", ErrUnknownMethod, name, strings.Join(c.Methods(), ", "))
+	case 1:
+		return &found[0], nil
+	}
+	sigs := make([]string, len(found))
+	for i, m := range found {
+		sigs[i] = m.Sig
+	}
+	sort.Strings(sigs)
+	return nil, fmt.Errorf("%w: %s（%s）", ErrAmbiguous, name, strings.Join(sigs, ", "))
+}
+
+// Methods 返回所有方法的签名，按字母排序
+func (c *Contract) Methods() []string {
+	sigs := make([]string, 0, len(c.ABI.Methods))
+	for _, m := range c.ABI.Methods {
+		sigs = append(sigs, m.Sig)
+	}
+	sort.Strings(sigs)
+	return sigs
+}
+
+// Args 检查参数数量并把字符串参数转换为方法要求的 Go 类型。
+// 每个参数单独试编码，出错时指出是哪个参数、期望什么类型
+func (c *Contract) Args(method *abi.Method, args []interface{}) ([]interface{}, error) {
+	return convertArgs(method.Sig, method.Inputs, args)
+}
+
+// Pack 编码调用数据：4 字节选择器 + ABI 编码参数
+func (c *Contract) Pack(method string, args ...interface{}) ([]byte, error) {
+	m, err := c.Method(method)
+	if err != nil {
+		return nil, err
+	}
+	converted, err := c.Args(m, args)
+	if err != nil {
+		return nil, err
+	}
+	packed, err := m.Inputs.Pack(converted...)
+	if err != nil {
+		return nil, fmt.Errorf("编码 %s 失败: %w", m.Sig, err)
+	}
+	return append(append([]byte{}, m.ID...), packed...), nil
+}
+
+// Call 通过 eth_call 调用方法并解码返回值。opts 可以为 nil
+func (c *Contract) Call(opts *bind.CallOpts, method string, args ...interface{}) ([]interface{}, error) {
+	m, err := c.Method(method)
+	if err != nil {
+		return nil, err
+	}
+	converted, err := c.Args(m, args)
+	if err != nil {
+		return nil, err
+	}
+	var out []interface{}
+	if err := c.bound.Call(opts, &out, m.Name, converted...); err != nil {
+		return nil, fmt.Errorf("调用 %s 失败: %w", m.Sig, err)
+	}
+	return out, nil
+}
+
+// Transact 发送调用方法的交易，不等待确认
+func (c *Contract) Transact(opts *bind.TransactOpts, method string, args ...interface{}) (*types.Transaction, error) {
+	m, err := c.Method(method)
+	if err != nil {
+		return nil, err
+	}
+	if m.IsConstant() {
+		return nil, fmt.Errorf("%s 是 %s 方法，不会修改状态，请使用 Call", m.Sig, m.StateMutability)
+	}
+	if opts.Value != nil && opts.Value.Sign() > 0 && !m.IsPayable() {
+		return nil, fmt.Errorf("%s 不是 payable 方法，不能附带 ETH", m.Sig)
+	}
+	converted, err := c.Args(m, args)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := c.bound.Transact(opts, m.Name, converted...)
+	if err != nil {
+		return nil, fmt.Errorf("发送 %s 失败: %w", m.Sig, err)
+	}
+	return tx, nil
+}
+
+// EstimateGas 估算调用方法需要的 gas
+func (c *Contract) EstimateGas(ctx context.Context, from common.Address, value *big.Int, method string, args ...interface{}) (uint64, error) {
+	data, err := c.Pack(method, args...)
+	if err != nil {
+		return 0, err
+	}
+	return c.backend.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &c.Address, Value: value, Data: data})
+}
+
+// convertArgs 参数转换的公共实现，方法参数与事件过滤条件共用
+func convertArgs(sig string, inputs abi.Arguments, args []interface{}) ([]interface{}, error) {
+	if len(args) != len(inputs) {
+		return nil, fmt.Errorf("%s 需要 %d 个参数，实际传入 %d 个", sig, len(inputs), len(args))
+	}
+	out := make([]interface{}, len(args))
+	for i, input := range inputs {
+		v, err := convertArg(input.Type, args[i])
+		if err == nil {
+			_, err = abi.Arguments{{Type: input.Type}}.Pack(v)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s 的参数 %s (%s): %w", sig, argName(input, i), input.Type.String(), err)
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// convertArg 字符串按 ABI 类型解析，其他值原样返回
+func convertArg(typ abi.Type, v interface{}) (interface{}, error) {
+	s, ok := v.(string)
+	if !ok || typ.T == abi.StringTy {
+		return v, nil
+	}
+	return abiarg.Parse(typ, s)
+}
+
+func argName(input abi.Argument, i int) string {
+	if input.Name != "" {
+		return input.Name
+	}
+	return fmt.Sprintf("#%d", i)
+}
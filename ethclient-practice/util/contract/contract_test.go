@@ -0,0 +1,126 @@
+package contract_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/dapp-learning/ethclient/util/contract"
+	"github.com/dapp-learning/ethclient/util/simnet"
+	"github.com/dapp-learning/ethclient/util/simnet/contracts"
+)
+
+// storeABIFile 2.09 提交的 solcjs 产物，只有 ABI 数组
+const storeABIFile = "../../2.09-deploy-contract/contract/Store_sol_Store.abi"
+
+// writeFile 在临时目录中写入编译产物
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoad(t *testing.T) {
+	net, err := simnet.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { net.Close() })
+	bin, err := os.ReadFile(strings.TrimSuffix(storeABIFile, ".abi") + ".bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	abiJSON := strings.TrimSpace(contracts.StoreABI)
+	standard := func(sources string) string {
+		return `{"contracts":{"Store.sol":{` + sources + `}}}`
+	}
+	solcContract := `{"abi":` + abiJSON + `,"evm":{"bytecode":{"object":"` + string(bin) + `"},"deployedBytecode":{"object":""}}}`
+
+	tests := []struct {
+		name string
+		path string
+		err  bool
+	}{
+		{"abi only", storeABIFile, false},
+		{"hardhat", writeFile(t, dir, "Store.json", `{"_format":"hh-sol-artifact-1","contractName":"Store","abi":`+abiJSON+`,"bytecode":"0x`+string(bin)+`"}`), false},
+		// solc 标准 JSON 没有顶层 abi 字段，由 deploy.LoadArtifact 读取
+		{"solc standard json", writeFile(t, dir, "solc-output.json", standard(`"Store":`+solcContract)), false},
+		{"solc with two contracts", writeFile(t, dir, "solc-two.json", standard(`"Store":`+solcContract+`,"Other":`+solcContract)), true},
+		{"bytecode only", writeFile(t, dir, "Store.bin.json", `{"bytecode":"0x`+string(bin)+`"}`), true},
+		{"missing", filepath.Join(dir, "missing.abi"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := contract.Load(net.Store, tt.path, net.Client)
+			if tt.err {
+				if err == nil {
+					t.Fatal("期望返回错误")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			// 无论来自哪种文件，得到的合约客户端相同
+			if got := strings.Join(c.Methods(), " "); got != "getItem(bytes32) items(bytes32) setItem(bytes32,bytes32) version()" {
+				t.Fatalf("方法列表: %s", got)
+			}
+			out, err := c.Call(nil, "version")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if out[0] != simnet.DefaultStoreVersion {
+				t.Fatalf("version() = %v", out[0])
+			}
+			// 字符串参数按 ABI 类型转换
+			if _, err := c.Call(nil, "getItem", "0x2a"); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := c.Method("transfer"); !errors.Is(err, contract.ErrUnknownMethod) {
+				t.Fatalf("未知方法返回 %v", err)
+			}
+		})
+	}
+}
+
+func TestTransactAndCall(t *testing.T) {
+	net, err := simnet.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { net.Close() })
+	c, err := contract.Load(net.Store, storeABIFile, net.Client)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 不带 0x 的 bytes32 按文本处理
+	if _, err := c.Transact(net.Transactor(net.Accounts[0]), "setItem", "color", "0x1234"); err != nil {
+		t.Fatal(err)
+	}
+	net.Commit()
+	out, err := c.Call(nil, "getItem(bytes32)", "color")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// bytesN 的十六进制在右侧补零
+	if got, want := common.Hash(out[0].([32]byte)), common.BytesToHash(common.RightPadBytes([]byte{0x12, 0x34}, 32)); got != want {
+		t.Fatalf("getItem = %s，期望 %s", got.Hex(), want.Hex())
+	}
+
+	if _, err := c.Transact(net.Transactor(net.Accounts[0]), "getItem", "color"); err == nil {
+		t.Fatal("view 方法不应发送交易")
+	}
+	if _, err := c.Call(nil, "setItem", "color"); err == nil || !strings.Contains(err.Error(), "需要 2 个参数") {
+		t.Fatalf("参数数量不符时返回 %v", err)
+	}
+}
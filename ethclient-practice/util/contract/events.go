@@ -0,0 +1,161 @@
+package contract
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/dapp-learning/ethclient/util/logdecode"
+)
+
+// EventFilter 事件查询条件
+type EventFilter struct {
+	FromBlock uint64
+	ToBlock   *uint64 // nil 表示最新区块
+
+	// Args 按 indexed 参数名过滤，值可以是单个值或 []interface{} / []string（任一匹配）。
+	// 字符串按参数类型转换，未指定的参数不过滤
+	Args map[string]interface{}
+}
+
+// Event 按名称或完整签名查找事件
+func (c *Contract) Event(name string) (*abi.Event, error) {
+	var found []abi.Event
+	for _, e := range c.ABI.Events {
+		if e.RawName == name || e.Sig == strings.ReplaceAll(name, " ", "") {
+			found = append(found, e)
+		}
+	}
+	switch len(found) {
+	case 0:
+		names := make([]string, 0, len(c.ABI.Events))
+		for _, e := range c.ABI.Events {
+			names = append(names, e.Sig)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("%w: %s（可用: %s）", ErrUnknownEvent, name, strings.Join(names, ", "))
+	case 1:
+		return &found[0], nil
+	}
+	sigs := make([]string, len(found))
+	for i, e := range found {
+		sigs[i] = e.Sig
+	}
+	sort.Strings(sigs)
+	return nil, fmt.Errorf("%w: %s（%s）", ErrAmbiguous, name, strings.Join(sigs, ", "))
+}
+
+// Events 查询合约的历史事件并按 ABI 解码
+func (c *Contract) Events(ctx context.Context, name string, filter *EventFilter) ([]*logdecode.Record, error) {
+	event, err := c.Event(name)
+	if err != nil {
+		return nil, err
+	}
+	if event.Anonymous {
+		return nil, fmt.Errorf("%s 是匿名事件，没有 topic0，无法按事件过滤", event.Sig)
+	}
+	if filter == nil {
+		filter = new(EventFilter)
+	}
+
+	query, err := topicQuery(event, filter.Args)
+	if err != nil {
+		return nil, err
+	}
+	topics, err := abi.MakeTopics(append([][]interface{}{{event.ID}}, query...)...)
+	if err != nil {
+		return nil, fmt.Errorf("构造 %s 的 topic 失败: %w", event.Sig, err)
+	}
+
+	q := ethereum.FilterQuery{
+		Addresses: []common.Address{c.Address},
+		Topics:    topics,
+		FromBlock: new(big.Int).SetUint64(filter.FromBlock),
+	}
+	if filter.ToBlock != nil {
+		q.ToBlock = new(big.Int).SetUint64(*filter.ToBlock)
+	}
+	logs, err := c.backend.FilterLogs(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("查询 %s 事件失败: %w", event.Sig, err)
+	}
+
+	records := make([]*logdecode.Record, 0, len(logs))
+	for i := range logs {
+		record, err := logdecode.DecodeEvent(event, &logs[i])
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// topicQuery 把按参数名给出的过滤条件转换为 indexed 参数顺序的查询
+func topicQuery(event *abi.Event, args map[string]interface{}) ([][]interface{}, error) {
+	var query [][]interface{}
+	used := 0
+	for i, input := range event.Inputs {
+		if !input.Indexed {
+			continue
+		}
+		name := argName(input, i)
+		value, ok := args[name]
+		if !ok {
+			query = append(query, nil)
+			continue
+		}
+		used++
+
+		var values []interface{}
+		switch v := value.(type) {
+		case []interface{}:
+			values = v
+		case []string:
+			for _, s := range v {
+				values = append(values, s)
+			}
+		default:
+			values = []interface{}{v}
+		}
+		rule := make([]interface{}, len(values))
+		for j, v := range values {
+			converted, err := convertArg(input.Type, v)
+			if err != nil {
+				return nil, fmt.Errorf("%s 的过滤参数 %s (%s): %w", event.Sig, name, input.Type.String(), err)
+			}
+			rule[j] = converted
+		}
+		query = append(query, rule)
+	}
+
+	if used != len(args) {
+		var indexed []string
+		for i, input := range event.Inputs {
+			if input.Indexed {
+				indexed = append(indexed, argName(input, i))
+			}
+		}
+		for name := range args {
+			if !contains(indexed, name) {
+				return nil, fmt.Errorf("%s 没有名为 %s 的 indexed 参数（可过滤: %s）", event.Sig, name, strings.Join(indexed, ", "))
+			}
+		}
+	}
+	return query, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,328 @@
+package deploy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// 支持的编译产物格式
+const (
+	FormatHardhat  = "hardhat"  // artifacts/contracts/X.sol/X.json
+	FormatFoundry  = "foundry"  // out/X.sol/X.json，bytecode 为 {"object": "0x..."}
+	FormatSolcJSON = "solc"     // solc --standard-json 输出
+	FormatCombined = "combined" // solc --combined-json abi,bin,bin-runtime 输出
+	FormatSolcjs   = "solcjs"   // solcjs --abi / --bin 生成的 .abi + .bin 文件
+)
+
+// ErrUnlinked 字节码中还有未链接的库占位符
+var ErrUnlinked = errors.New("字节码包含未链接的库占位符")
+
+// placeholderRe 匹配 __$<34 hex>$__ 或旧版 __LibName___ 形式的库占位符
+var placeholderRe = regexp.MustCompile(`__\$[0-9a-fA-F]{34}\$__|__[^_\s][^\s]{0,36}__`)
+
+// Artifact 编译产物中部署需要的部分
+type Artifact struct {
+	Name             string          // 合约名
+	Format           string          // 产物格式，见 Format* 常量
+	Path             string          // 产物文件路径
+	ABI              abi.ABI         // 合约 ABI
+	RawABI           json.RawMessage // 原始 ABI JSON，生成绑定代码时使用
+	Bytecode         []byte          // 创建字节码（initcode，不含构造参数）
+	DeployedBytecode []byte          // 运行时字节码，部分格式可能为空
+
+	// Immutables 运行时字节码中 immutable 变量的位置。编译产物中这些位置是 0，
+	// 部署时由构造函数填入实际值。只有 Foundry 与 solc 标准 JSON 输出包含该信息
+	Immutables []CodeRange
+}
+
+// CodeRange 字节码中的一段区间
+type CodeRange struct {
+	Start  int `json:"start"`
+	Length int `json:"length"`
+}
+
+// DeployData 拼接 initcode 与 ABI 编码后的构造参数
+func (a *Artifact) DeployData(args ...interface{}) ([]byte, error) {
+	if len(a.Bytecode) == 0 {
+		return nil, fmt.Errorf("%s 没有字节码（接口或抽象合约？）", a.Name)
+	}
+	packed, err := a.ABI.Pack("", args...)
+	if err != nil {
+		return nil, fmt.Errorf("编码构造参数失败: %w", err)
+	}
+	data := make([]byte, 0, len(a.Bytecode)+len(packed))
+	return append(append(data, a.Bytecode...), packed...), nil
+}
+
+// LoadArtifact 读取编译产物文件并自动识别格式。
+// name 用于从包含多个合约的 solc 输出中选择合约，单合约产物可以传空字符串
+func LoadArtifact(path, name string) (*Artifact, error) {
+	if strings.EqualFold(filepath.Ext(path), ".abi") || strings.EqualFold(filepath.Ext(path), ".bin") {
+		return loadSolcjs(path)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, fmt.Errorf("%s 不是 JSON 编译产物: %w", path, err)
+	}
+
+	var art *Artifact
+	switch {
+	case doc["contracts"] != nil:
+		art, err = loadSolcOutput(doc["contracts"], name)
+	case doc["abi"] != nil && doc["bytecode"] != nil:
+		art, err = loadSingle(doc)
+	default:
+		err = errors.New("无法识别的产物格式（需要 abi 与 bytecode 字段，或 solc 的 contracts 字段）")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	art.Path = path
+	if art.Name == "" {
+		art.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if name != "" && art.Name != name {
+		return nil, fmt.Errorf("%s 中的合约是 %s，不是 %s", path, art.Name, name)
+	}
+	return art, nil
+}
+
+// loadSingle 解析 Hardhat / Foundry 的单合约产物
+func loadSingle(doc map[string]json.RawMessage) (*Artifact, error) {
+	art := &Artifact{Format: FormatHardhat}
+	if doc["contractName"] != nil {
+		if err := json.Unmarshal(doc["contractName"], &art.Name); err != nil {
+			return nil, err
+		}
+	}
+	var err error
+	if art.ABI, err = abi.JSON(bytes.NewReader(doc["abi"])); err != nil {
+		return nil, fmt.Errorf("解析 ABI 失败: %w", err)
+	}
+	art.RawABI = doc["abi"]
+
+	// Hardhat 是 "0x..."，Foundry 是 {"object": "0x...", "linkReferences": ...}
+	var isObject bool
+	if art.Bytecode, isObject, err = decodeBytecode(doc["bytecode"]); err != nil {
+		return nil, fmt.Errorf("bytecode: %w", err)
+	}
+	if isObject {
+		art.Format = FormatFoundry
+	}
+	if doc["deployedBytecode"] != nil {
+		if art.DeployedBytecode, _, err = decodeBytecode(doc["deployedBytecode"]); err != nil {
+			return nil, fmt.Errorf("deployedBytecode: %w", err)
+		}
+		art.Immutables = immutableRefs(doc["deployedBytecode"])
+	}
+	return art, nil
+}
+
+// loadSolcOutput 解析 solc 的 --standard-json 或 --combined-json 输出
+func loadSolcOutput(raw json.RawMessage, name string) (*Artifact, error) {
+	var contracts map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &contracts); err != nil {
+		return nil, err
+	}
+
+	// combined-json: {"contracts": {"Store.sol:Store": {"abi": ..., "bin": "...", "bin-runtime": "..."}}}
+	// standard-json: {"contracts": {"Store.sol": {"Store": {"abi": ..., "evm": {...}}}}}
+	candidates := make(map[string]json.RawMessage)
+	combined := false
+	for key, value := range contracts {
+		if idx := strings.LastIndex(key, ":"); idx >= 0 {
+			combined = true
+			candidates[key[idx+1:]] = value
+			continue
+		}
+		var inner map[string]json.RawMessage
+		if err := json.Unmarshal(value, &inner); err != nil {
+			return nil, err
+		}
+		for contract, body := range inner {
+			candidates[contract] = body
+		}
+	}
+
+	body, contract, err := pick(candidates, name)
+	if err != nil {
+		return nil, err
+	}
+
+	if combined {
+		var c struct {
+			ABI        json.RawMessage `json:"abi"`
+			Bin        string          `json:"bin"`
+			BinRuntime string          `json:"bin-runtime"`
+		}
+		if err := json.Unmarshal(body, &c); err != nil {
+			return nil, err
+		}
+		return newArtifact(contract, FormatCombined, c.ABI, c.Bin, c.BinRuntime)
+	}
+
+	var c struct {
+		ABI json.RawMessage `json:"abi"`
+		EVM struct {
+			Bytecode struct {
+				Object string `json:"object"`
+			} `json:"bytecode"`
+			DeployedBytecode struct {
+				Object              string                 `json:"object"`
+				ImmutableReferences map[string][]CodeRange `json:"immutableReferences"`
+			} `json:"deployedBytecode"`
+		} `json:"evm"`
+	}
+	if err := json.Unmarshal(body, &c); err != nil {
+		return nil, err
+	}
+	art, err := newArtifact(contract, FormatSolcJSON, c.ABI, c.EVM.Bytecode.Object, c.EVM.DeployedBytecode.Object)
+	if err != nil {
+		return nil, err
+	}
+	art.Immutables = flattenRefs(c.EVM.DeployedBytecode.ImmutableReferences)
+	return art, nil
+}
+
+// loadSolcjs 读取 solcjs 生成的 X_sol_X.abi 与同名 .bin 文件
+func loadSolcjs(path string) (*Artifact, error) {
+	base := strings.TrimSuffix(path, filepath.Ext(path))
+	abiJSON, err := os.ReadFile(base + ".abi")
+	if err != nil {
+		return nil, err
+	}
+	bin, err := os.ReadFile(base + ".bin")
+	if err != nil {
+		return nil, err
+	}
+
+	// solcjs 的文件名形如 Store_sol_Store，最后一段是合约名
+	name := filepath.Base(base)
+	if idx := strings.LastIndex(name, "_sol_"); idx >= 0 {
+		name = name[idx+len("_sol_"):]
+	}
+	art, err := newArtifact(name, FormatSolcjs, abiJSON, strings.TrimSpace(string(bin)), "")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	art.Path = path
+	return art, nil
+}
+
+func newArtifact(name, format string, abiJSON json.RawMessage, bin, binRuntime string) (*Artifact, error) {
+	art := &Artifact{Name: name, Format: format}
+
+	// combined-json 旧版本中 abi 是字符串
+	var s string
+	if json.Unmarshal(abiJSON, &s) == nil {
+		abiJSON = json.RawMessage(s)
+	}
+	var err error
+	if art.ABI, err = abi.JSON(bytes.NewReader(abiJSON)); err != nil {
+		return nil, fmt.Errorf("解析 ABI 失败: %w", err)
+	}
+	art.RawABI = abiJSON
+	if art.Bytecode, err = decodeHex(bin); err != nil {
+		return nil, fmt.Errorf("bytecode: %w", err)
+	}
+	if art.DeployedBytecode, err = decodeHex(binRuntime); err != nil {
+		return nil, fmt.Errorf("deployedBytecode: %w", err)
+	}
+	return art, nil
+}
+
+// pick 从多个合约中按名称选择；只有一个合约时可以不指定名称
+func pick(candidates map[string]json.RawMessage, name string) (json.RawMessage, string, error) {
+	if name != "" {
+		body, ok := candidates[name]
+		if !ok {
+			return nil, "", fmt.Errorf("产物中没有合约 %s（可选: %s）", name, names(candidates))
+		}
+		return body, name, nil
+	}
+	if len(candidates) != 1 {
+		return nil, "", fmt.Errorf("产物中有 %d 个合约，请指定名称（可选: %s）", len(candidates), names(candidates))
+	}
+	for contract, body := range candidates {
+		return body, contract, nil
+	}
+	return nil, "", errors.New("产物中没有合约")
+}
+
+func names(candidates map[string]json.RawMessage) string {
+	list := make([]string, 0, len(candidates))
+	for name := range candidates {
+		list = append(list, name)
+	}
+	sort.Strings(list)
+	return strings.Join(list, ", ")
+}
+
+// decodeBytecode 解析字符串或 {"object": "..."} 形式的字节码，第二个返回值表示是否为对象形式
+func decodeBytecode(raw json.RawMessage) ([]byte, bool, error) {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		b, err := decodeHex(s)
+		return b, false, err
+	}
+	var obj struct {
+		Object string `json:"object"`
+	}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return nil, false, err
+	}
+	b, err := decodeHex(obj.Object)
+	return b, true, err
+}
+
+// immutableRefs 读取 {"object": ..., "immutableReferences": {...}} 中的 immutable 位置
+func immutableRefs(raw json.RawMessage) []CodeRange {
+	var obj struct {
+		ImmutableReferences map[string][]CodeRange `json:"immutableReferences"`
+	}
+	if json.Unmarshal(raw, &obj) != nil {
+		return nil
+	}
+	return flattenRefs(obj.ImmutableReferences)
+}
+
+// flattenRefs 合并各个 immutable 变量（以 AST id 为键）的引用位置，按起点排序
+func flattenRefs(refs map[string][]CodeRange) []CodeRange {
+	var list []CodeRange
+	for _, ranges := range refs {
+		list = append(list, ranges...)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Start < list[j].Start })
+	return list
+}
+
+// decodeHex 解析可能不带 0x 前缀的十六进制字节码
+func decodeHex(s string) ([]byte, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "0x" {
+		return nil, nil
+	}
+	if placeholderRe.MatchString(s) {
+		return nil, fmt.Errorf("%w: %s", ErrUnlinked, placeholderRe.FindString(s))
+	}
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		s = "0x" + s
+	}
+	return hexutil.Decode(s)
+}
@@ -0,0 +1,31 @@
+package deploy_test
+
+import (
+	"testing"
+
+	"github.com/dapp-learning/ethclient/util/deploy"
+)
+
+// storeArtifact 2.09 提交的 Store 编译产物（solcjs 0.8.30）
+const storeArtifact = "../../2.09-deploy-contract/contract/Store_sol_Store.abi"
+
+func TestLoadSolcjsArtifact(t *testing.T) {
+	art, err := deploy.LoadArtifact(storeArtifact, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if art.Name != "Store" || art.Format != deploy.FormatSolcjs {
+		t.Fatalf("Name = %q，Format = %q", art.Name, art.Format)
+	}
+	for _, method := range []string{"version", "items", "getItem", "setItem"} {
+		if _, ok := art.ABI.Methods[method]; !ok {
+			t.Errorf("ABI 中缺少 %s", method)
+		}
+	}
+	if len(art.Bytecode) == 0 {
+		t.Fatal("没有读取到字节码")
+	}
+	if _, err := art.DeployData("1.0"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,240 @@
+package deploy
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DeterministicDeployer 确定性部署代理（Arachnid deterministic-deployment-proxy）的地址。
+// 它在绝大多数 EVM 链上都部署在同一个地址，calldata 为 salt(32 字节) ++ initcode
+var DeterministicDeployer = common.HexToAddress("0x4e59b44847b379578588920cA78FbF26c0B4956C")
+
+// DeterministicDeployerCode 确定性部署代理的运行时代码，可用于在本地链 / 模拟后端中预置
+var DeterministicDeployerCode = common.FromHex("0x7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffe03601600081602082378035828234f58015156039578182fd5b8082525050506014600cf3")
+
+// ErrAlreadyDeployed 预测的 CREATE2 地址上已经有合约（相同 salt 与 initcode 只能部署一次）
+var ErrAlreadyDeployed = errors.New("CREATE2 地址上已有合约")
+
+// Factory 通过 CREATE2 部署合约的工厂合约
+type Factory struct {
+	Address common.Address
+	// Encode 构造调用工厂的 calldata，为 nil 时使用确定性部署代理的格式 salt ++ initcode
+	Encode func(salt common.Hash, initCode []byte) ([]byte, error)
+}
+
+// CanonicalFactory 确定性部署代理
+var CanonicalFactory = Factory{Address: DeterministicDeployer}
+
+// ABIFactory 用户自己的工厂合约，method 的参数须为 (bytes32 salt, bytes initCode)，
+// 例如 function deploy(bytes32 salt, bytes memory code) returns (address)
+func ABIFactory(address common.Address, factoryABI abi.ABI, method string) Factory {
+	return Factory{
+		Address: address,
+		Encode: func(salt common.Hash, initCode []byte) ([]byte, error) {
+			return factoryABI.Pack(method, [32]byte(salt), initCode)
+		},
+	}
+}
+
+func (f Factory) calldata(salt common.Hash, initCode []byte) ([]byte, error) {
+	if f.Encode != nil {
+		return f.Encode(salt, initCode)
+	}
+	data := make([]byte, 0, common.HashLength+len(initCode))
+	return append(append(data, salt.Bytes()...), initCode...), nil
+}
+
+// PredictCreate2 计算 CREATE2 地址：
+// keccak256(0xff ++ deployer ++ salt ++ keccak256(initCode))[12:]
+func PredictCreate2(deployer common.Address, salt common.Hash, initCode []byte) common.Address {
+	return crypto.CreateAddress2(deployer, salt, crypto.Keccak256(initCode))
+}
+
+// ParseSalt 解析命令行传入的 salt：0x 十六进制按数值左侧补零到 32 字节，其他文本取 keccak256
+func ParseSalt(s string) (common.Hash, error) {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		b, err := hexutil.Decode(s)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("无效的 salt %q: %w", s, err)
+		}
+		if len(b) > common.HashLength {
+			return common.Hash{}, fmt.Errorf("salt %q 超过 32 字节", s)
+		}
+		return common.BytesToHash(b), nil
+	}
+	return crypto.Keccak256Hash([]byte(s)), nil
+}
+
+// SendCreate2 通过工厂合约发送 CREATE2 部署交易，不等待确认。
+// initCode 须已包含构造参数（见 Artifact.DeployData）。返回交易与预测地址
+func (d *Deployer) SendCreate2(ctx context.Context, factory Factory, salt common.Hash, initCode []byte, opts *Options) (*types.Transaction, common.Address, error) {
+	predicted := PredictCreate2(factory.Address, salt, initCode)
+
+	// 工厂不存在时交易会“成功”但什么也不做，提前检查
+	code, err := d.backend.CodeAt(ctx, factory.Address, nil)
+	if err != nil {
+		return nil, predicted, fmt.Errorf("读取工厂合约代码失败: %w", err)
+	}
+	if len(code) == 0 {
+		return nil, predicted, fmt.Errorf("工厂 %s 在当前网络上不存在", factory.Address.Hex())
+	}
+	if code, err = d.backend.CodeAt(ctx, predicted, nil); err != nil {
+		return nil, predicted, fmt.Errorf("读取合约代码失败: %w", err)
+	}
+	if len(code) > 0 {
+		return nil, predicted, fmt.Errorf("%w: %s", ErrAlreadyDeployed, predicted.Hex())
+	}
+
+	data, err := factory.calldata(salt, initCode)
+	if err != nil {
+		return nil, predicted, fmt.Errorf("编码工厂调用失败: %w", err)
+	}
+	tx, err := d.send(ctx, &factory.Address, data, opts)
+	if err != nil {
+		return nil, predicted, err
+	}
+	return tx, predicted, nil
+}
+
+// DeployCreate2 通过工厂合约部署并等待确认，确认预测地址上确实有了代码
+func (d *Deployer) DeployCreate2(ctx context.Context, factory Factory, name string, salt common.Hash, initCode []byte, opts *Options) (*Deployment, error) {
+	tx, predicted, err := d.SendCreate2(ctx, factory, salt, initCode, opts)
+	if err != nil {
+		return nil, err
+	}
+	return d.WaitCreate2(ctx, name, tx, predicted)
+}
+
+// WaitCreate2 等待 CREATE2 部署交易确认。收据中没有合约地址，使用预测地址读取代码
+func (d *Deployer) WaitCreate2(ctx context.Context, name string, tx *types.Transaction, predicted common.Address) (*Deployment, error) {
+	return d.wait(ctx, name, tx, &predicted)
+}
+
+// SaltMiner 搜索使 CREATE2 地址带有指定十六进制前缀的 salt（靓号地址）。
+// 每多一位十六进制前缀，期望尝试次数乘以 16
+type SaltMiner struct {
+	Deployer     common.Address // 执行 CREATE2 的地址（工厂合约）
+	InitCodeHash common.Hash    // keccak256(initCode)
+	Prefix       string         // 地址前缀，不区分大小写，可带 0x
+	Workers      int            // 并发 goroutine 数，<= 0 时为 1
+
+	attempts atomic.Uint64
+}
+
+// MinedSalt 搜索结果
+type MinedSalt struct {
+	Salt    common.Hash
+	Address common.Address
+}
+
+// Attempts 已尝试的 salt 数量，可在搜索过程中读取以显示进度
+func (m *SaltMiner) Attempts() uint64 {
+	return m.attempts.Load()
+}
+
+// Expected 前缀对应的期望尝试次数
+func (m *SaltMiner) Expected() float64 {
+	n := 1.0
+	for range normalizePrefix(m.Prefix) {
+		n *= 16
+	}
+	return n
+}
+
+// Mine 启动 Workers 个 goroutine 搜索，找到第一个结果或 ctx 取消时返回。
+// 每个 goroutine 从随机起点开始，把 salt 的最后 8 字节当计数器递增
+func (m *SaltMiner) Mine(ctx context.Context) (*MinedSalt, error) {
+	prefix := normalizePrefix(m.Prefix)
+	for _, c := range prefix {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return nil, fmt.Errorf("前缀 %q 不是十六进制", m.Prefix)
+		}
+	}
+	if len(prefix) > 2*common.AddressLength {
+		return nil, fmt.Errorf("前缀 %q 超过地址长度", m.Prefix)
+	}
+	workers := m.Workers
+	if workers <= 0 {
+		workers = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		once   sync.Once
+		result *MinedSalt
+		wg     sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		var salt common.Hash
+		if _, err := rand.Read(salt[:]); err != nil {
+			return nil, err
+		}
+		wg.Add(1)
+		go func(salt common.Hash) {
+			defer wg.Done()
+			counter := binary.BigEndian.Uint64(salt[24:])
+			for i := 0; ; i++ {
+				// 每 4096 次检查一次是否已被取消，减少 select 开销
+				if i%4096 == 0 {
+					select {
+					case <-ctx.Done():
+						return
+					default:
+					}
+				}
+				binary.BigEndian.PutUint64(salt[24:], counter)
+				counter++
+				m.attempts.Add(1)
+
+				addr := crypto.CreateAddress2(m.Deployer, salt, m.InitCodeHash.Bytes())
+				if hasHexPrefix(addr, prefix) {
+					once.Do(func() {
+						result = &MinedSalt{Salt: salt, Address: addr}
+						cancel()
+					})
+					return
+				}
+			}
+		}(salt)
+	}
+	wg.Wait()
+
+	if result == nil {
+		return nil, ctx.Err()
+	}
+	return result, nil
+}
+
+func normalizePrefix(prefix string) string {
+	return strings.TrimPrefix(strings.ToLower(prefix), "0x")
+}
+
+// hasHexPrefix 逐个半字节比较地址与前缀，避免每次都格式化成字符串
+func hasHexPrefix(addr common.Address, prefix string) bool {
+	for i := 0; i < len(prefix); i++ {
+		b := addr[i/2]
+		if i%2 == 0 {
+			b >>= 4
+		} else {
+			b &= 0x0f
+		}
+		if "0123456789abcdef"[b] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,205 @@
+package deploy_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/dapp-learning/ethclient/util/deploy"
+	"github.com/dapp-learning/ethclient/util/proxy"
+	"github.com/dapp-learning/ethclient/util/simnet"
+)
+
+// answerInitCode 一个最小合约的 initcode：部署后任何调用都返回 uint256(42)
+// 69 602a60005260206000f3  PUSH10 运行时代码
+// 6000 52                  MSTORE 到内存 [22:32]
+// 600a 6016 f3             RETURN 内存 [22:32]
+var answerInitCode = common.FromHex("0x69602a60005260206000f3600052600a6016f3")
+
+// cloneCreationPrefix OpenZeppelin Clones 使用的 EIP-1167 创建代码前缀，
+// 把紧随其后的 45 字节运行时代码原样返回
+var cloneCreationPrefix = common.FromHex("0x3d602d80600a3d3981f3")
+
+// newCreate2Net 启动模拟链并在创世状态中预置确定性部署代理（真实网络上它由预签名交易部署）
+func newCreate2Net(t *testing.T) (*simnet.Net, *deploy.Deployer) {
+	t.Helper()
+	net, err := simnet.New(&simnet.Options{
+		SkipContracts: true,
+		Alloc: types.GenesisAlloc{
+			deploy.DeterministicDeployer: {Code: deploy.DeterministicDeployerCode, Balance: new(big.Int)},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { net.Close() })
+
+	deployer, err := deploy.NewDeployer(context.Background(), net.Client, net.Accounts[0].Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return net, deployer
+}
+
+// create2 通过工厂发送部署交易、出块，并确认实际地址与预测一致
+func create2(t *testing.T, net *simnet.Net, deployer *deploy.Deployer, factory deploy.Factory, salt common.Hash, initCode []byte) (*deploy.Deployment, error) {
+	t.Helper()
+	ctx := context.Background()
+	tx, predicted, err := deployer.SendCreate2(ctx, factory, salt, initCode, nil)
+	if err != nil {
+		return nil, err
+	}
+	if want := deploy.PredictCreate2(factory.Address, salt, initCode); predicted != want {
+		t.Fatalf("SendCreate2 预测 %s，PredictCreate2 为 %s", predicted.Hex(), want.Hex())
+	}
+	net.Commit()
+	d, err := deployer.WaitCreate2(ctx, "Answer", tx, predicted)
+	if err != nil {
+		return nil, err
+	}
+	if d.Address != predicted {
+		t.Fatalf("部署到 %s，预测为 %s", d.Address.Hex(), predicted.Hex())
+	}
+	return d, nil
+}
+
+// callUint 调用合约并把返回值解释为 uint256
+func callUint(t *testing.T, net *simnet.Net, addr common.Address) *big.Int {
+	t.Helper()
+	out, err := net.Client.CallContract(context.Background(), ethereum.CallMsg{To: &addr}, nil)
+	if err != nil {
+		t.Fatalf("调用 %s 失败: %v", addr.Hex(), err)
+	}
+	return new(big.Int).SetBytes(out)
+}
+
+func TestPredictCreate2(t *testing.T) {
+	// EIP-1014 的示例 1：deployer 为零地址，salt 为 0，initcode 为 0x00
+	got := deploy.PredictCreate2(common.Address{}, common.Hash{}, []byte{0x00})
+	if want := common.HexToAddress("0x4D1A2e2bB4F88F0250f26Ffff098B0b30B26BF38"); got != want {
+		t.Fatalf("PredictCreate2 = %s，期望 %s", got.Hex(), want.Hex())
+	}
+}
+
+func TestCreate2CanonicalFactory(t *testing.T) {
+	net, deployer := newCreate2Net(t)
+
+	salt, err := deploy.ParseSalt("dapp-learning")
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := create2(t, net, deployer, deploy.CanonicalFactory, salt, answerInitCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := callUint(t, net, d.Address); got.Int64() != 42 {
+		t.Fatalf("合约返回 %s，期望 42", got)
+	}
+
+	// 相同 salt 与 initcode 只能部署一次
+	if _, _, err := deployer.SendCreate2(context.Background(), deploy.CanonicalFactory, salt, answerInitCode, nil); !errors.Is(err, deploy.ErrAlreadyDeployed) {
+		t.Fatalf("重复部署应返回 ErrAlreadyDeployed，实际: %v", err)
+	}
+}
+
+func TestCreate2DeployedFactory(t *testing.T) {
+	net, deployer := newCreate2Net(t)
+	ctx := context.Background()
+
+	// 用 CREATE 部署一个自己的工厂（复用确定性部署代理的运行时代码）
+	factoryAddr, _, err := net.Deploy(ctx, net.Accounts[1], wrapRuntime(deploy.DeterministicDeployerCode))
+	if err != nil {
+		t.Fatal(err)
+	}
+	factory := deploy.Factory{Address: factoryAddr}
+
+	salt := common.HexToHash("0x01")
+	d, err := create2(t, net, deployer, factory, salt, answerInitCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 同样的 salt 与 initcode，工厂不同，地址也不同
+	if canonical := deploy.PredictCreate2(deploy.DeterministicDeployer, salt, answerInitCode); d.Address == canonical {
+		t.Fatalf("不同工厂得到了相同地址 %s", d.Address.Hex())
+	}
+
+	// 不存在的工厂在发送前就被拒绝
+	missing := deploy.Factory{Address: common.HexToAddress("0x000000000000000000000000000000000000dEaD")}
+	if _, _, err := deployer.SendCreate2(ctx, missing, salt, answerInitCode, nil); err == nil {
+		t.Fatal("工厂不存在时应返回错误")
+	}
+}
+
+func TestCreate2MinedSalt(t *testing.T) {
+	net, deployer := newCreate2Net(t)
+
+	miner := &deploy.SaltMiner{
+		Deployer:     deploy.DeterministicDeployer,
+		InitCodeHash: crypto.Keccak256Hash(answerInitCode),
+		Prefix:       "00",
+		Workers:      4,
+	}
+	mined, err := miner.Mine(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	d, err := create2(t, net, deployer, deploy.CanonicalFactory, mined.Salt, answerInitCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(strings.ToLower(d.Address.Hex()), "0x00") {
+		t.Fatalf("地址 %s 不满足前缀 00", d.Address.Hex())
+	}
+}
+
+func TestCreate2MinimalProxyClone(t *testing.T) {
+	net, deployer := newCreate2Net(t)
+
+	impl, err := create2(t, net, deployer, deploy.CanonicalFactory, common.Hash{}, answerInitCode)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// clone 的 initcode = 创建代码前缀 ++ EIP-1167 运行时代码
+	runtime := proxy.MinimalProxyCode(impl.Address)
+	want := common.FromHex("0x363d3d373d3d3d363d73" + strings.TrimPrefix(strings.ToLower(impl.Address.Hex()), "0x") + "5af43d82803e903d91602b57fd5bf3")
+	if !bytes.Equal(runtime, want) {
+		t.Fatalf("MinimalProxyCode = %x，期望 %x", runtime, want)
+	}
+	initCode := append(append([]byte{}, cloneCreationPrefix...), runtime...)
+
+	// 同一个实现、不同的 salt 得到不同的 clone，地址都可以提前算出
+	for _, salt := range []common.Hash{common.HexToHash("0x01"), common.HexToHash("0x02")} {
+		clone, err := create2(t, net, deployer, deploy.CanonicalFactory, salt, initCode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		code, err := net.Client.CodeAt(context.Background(), clone.Address, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if target, ok := proxy.MinimalProxyTarget(code); !ok || target != impl.Address {
+			t.Fatalf("clone %s 的代码不是指向 %s 的最小代理: %x", clone.Address.Hex(), impl.Address.Hex(), code)
+		}
+		// 调用经 DELEGATECALL 转发到实现合约
+		if got := callUint(t, net, clone.Address); got.Int64() != 42 {
+			t.Fatalf("clone 返回 %s，期望 42", got)
+		}
+	}
+}
+
+// wrapRuntime 生成把 runtime 原样作为合约代码返回的 initcode：
+// PUSH2 len, DUP1, PUSH1 12, PUSH1 0, CODECOPY, PUSH1 0, RETURN, <runtime>
+func wrapRuntime(runtime []byte) []byte {
+	n := len(runtime)
+	code := []byte{0x61, byte(n >> 8), byte(n), 0x80, 0x60, 0x0c, 0x60, 0x00, 0x39, 0x60, 0x00, 0xf3}
+	return append(code, runtime...)
+}
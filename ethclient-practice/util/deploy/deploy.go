@@ -0,0 +1,153 @@
+// Package deploy 从 Hardhat / Foundry / solc 编译产物部署合约，
+// 并把部署结果记录到 deployments.json，供后续按名称加载合约。
+package deploy
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+)
+
+// Backend 部署需要的节点接口，*ethclient.Client 与模拟后端的客户端都满足
+type Backend interface {
+	bind.ContractBackend
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	ChainID(ctx context.Context) (*big.Int, error)
+}
+
+// Options 部署选项
+type Options struct {
+	Value     *big.Int // 随部署发送的 ETH（构造函数需为 payable）
+	GasLimit  uint64   // 为 0 时使用 EstimateGas 的结果加余量
+	GasMargin uint64   // 估算余量百分比，为 0 时使用 chain.DefaultGasMargin
+}
+
+// Deployer 使用一个私钥部署合约
+type Deployer struct {
+	backend Backend
+	key     *ecdsa.PrivateKey
+	from    common.Address
+	chainID *big.Int
+}
+
+// NewDeployer 创建部署器，会查询一次链 ID
+func NewDeployer(ctx context.Context, backend Backend, key *ecdsa.PrivateKey) (*Deployer, error) {
+	chainID, err := backend.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链 ID 失败: %w", err)
+	}
+	return &Deployer{
+		backend: backend,
+		key:     key,
+		from:    crypto.PubkeyToAddress(key.PublicKey),
+		chainID: chainID,
+	}, nil
+}
+
+// From 部署账户地址
+func (d *Deployer) From() common.Address {
+	return d.from
+}
+
+// ChainID 链 ID
+func (d *Deployer) ChainID() *big.Int {
+	return new(big.Int).Set(d.chainID)
+}
+
+// Network 链 ID 对应的网络名，用作部署记录的 network 字段
+func (d *Deployer) Network() string {
+	return NetworkName(d.chainID)
+}
+
+// Send 构造、签名并发送部署交易，不等待确认。
+// 返回交易和按 (from, nonce) 计算出的合约地址
+func (d *Deployer) Send(ctx context.Context, art *Artifact, opts *Options, args ...interface{}) (*types.Transaction, common.Address, error) {
+	data, err := art.DeployData(args...)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	tx, err := d.send(ctx, nil, data, opts)
+	if err != nil {
+		return nil, common.Address{}, err
+	}
+	return tx, crypto.CreateAddress(d.from, tx.Nonce()), nil
+}
+
+// Deploy 发送部署交易并等待确认，返回可直接写入 Registry 的部署记录
+func (d *Deployer) Deploy(ctx context.Context, art *Artifact, opts *Options, args ...interface{}) (*Deployment, error) {
+	tx, _, err := d.Send(ctx, art, opts, args...)
+	if err != nil {
+		return nil, err
+	}
+	return d.Wait(ctx, art.Name, tx)
+}
+
+// Wait 等待部署交易确认，检查执行状态并读取链上运行时代码
+func (d *Deployer) Wait(ctx context.Context, name string, tx *types.Transaction) (*Deployment, error) {
+	return d.wait(ctx, name, tx, nil)
+}
+
+// send 通过 chain.BuildTx 补全 nonce、Gas 和费用，签名并发送交易。to 为 nil 时是合约创建交易
+func (d *Deployer) send(ctx context.Context, to *common.Address, data []byte, opts *Options) (*types.Transaction, error) {
+	if opts == nil {
+		opts = &Options{}
+	}
+	req := &chain.TxRequest{To: to, Value: opts.Value, Data: data, GasLimit: opts.GasLimit, GasMargin: opts.GasMargin}
+	tx, err := chain.BuildTx(ctx, d.backend, d.from, d.chainID, req)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := types.SignTx(tx, types.LatestSignerForChainID(d.chainID), d.key)
+	if err != nil {
+		return nil, err
+	}
+	if err := d.backend.SendTransaction(ctx, signed); err != nil {
+		return nil, fmt.Errorf("发送部署交易失败: %w", err)
+	}
+	return signed, nil
+}
+
+// wait 等待交易确认。address 为 nil 时使用收据中的 ContractAddress（CREATE），
+// 通过工厂合约部署（CREATE2）时收据里没有合约地址，需要传入预测的地址
+func (d *Deployer) wait(ctx context.Context, name string, tx *types.Transaction, address *common.Address) (*Deployment, error) {
+	receipt, err := bind.WaitMined(ctx, d.backend, tx)
+	if err != nil {
+		return nil, fmt.Errorf("等待交易 %s 确认失败: %w", tx.Hash().Hex(), err)
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, fmt.Errorf("部署交易 %s 执行失败（区块 %d，Gas 使用 %d / %d）",
+			tx.Hash().Hex(), receipt.BlockNumber.Uint64(), receipt.GasUsed, tx.Gas())
+	}
+
+	contract := receipt.ContractAddress
+	if address != nil {
+		contract = *address
+	}
+	code, err := d.backend.CodeAt(ctx, contract, receipt.BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("读取合约代码失败: %w", err)
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("地址 %s 上没有合约代码", contract.Hex())
+	}
+
+	return &Deployment{
+		Network:      d.Network(),
+		ChainID:      d.chainID.Uint64(),
+		Name:         name,
+		Address:      contract,
+		TxHash:       receipt.TxHash,
+		Block:        receipt.BlockNumber.Uint64(),
+		BytecodeHash: crypto.Keccak256Hash(code),
+		Deployer:     d.from,
+		GasUsed:      receipt.GasUsed,
+	}, nil
+}
@@ -0,0 +1,178 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// DefaultRegistryFile 默认的部署记录文件名
+const DefaultRegistryFile = "deployments.json"
+
+var (
+	// ErrNotDeployed 部署记录中没有找到合约
+	ErrNotDeployed = errors.New("没有部署记录")
+	// ErrCodeChanged 链上代码与部署记录中的 bytecodeHash 不一致
+	ErrCodeChanged = errors.New("链上代码与部署记录不一致")
+)
+
+// networkNames 常见链 ID 对应的网络名
+var networkNames = map[uint64]string{
+	1:        "mainnet",
+	11155111: "sepolia",
+	17000:    "holesky",
+	560048:   "hoodi",
+	1337:     "simulated", // geth --dev 与模拟后端
+	31337:    "localhost", // anvil / hardhat node
+}
+
+// NetworkName 返回链 ID 对应的网络名，未知的链返回 chain-<id>
+func NetworkName(chainID *big.Int) string {
+	if chainID.IsUint64() {
+		if name, ok := networkNames[chainID.Uint64()]; ok {
+			return name
+		}
+	}
+	return fmt.Sprintf("chain-%s", chainID)
+}
+
+// Deployment 一条部署记录。BytecodeHash 是链上运行时代码的 keccak256，
+// 可用来判断地址上的合约是否被替换（例如本地链重启后重新部署）
+type Deployment struct {
+	Network      string         `json:"network"`
+	ChainID      uint64         `json:"chainId"`
+	Name         string         `json:"name"`
+	Address      common.Address `json:"address"`
+	TxHash       common.Hash    `json:"txHash"`
+	Block        uint64         `json:"block"`
+	BytecodeHash common.Hash    `json:"bytecodeHash"`
+	Deployer     common.Address `json:"deployer"`
+	GasUsed      uint64         `json:"gasUsed,omitempty"`
+	Args         []string       `json:"args,omitempty"`     // 命令行传入的构造参数原文
+	Artifact     string         `json:"artifact,omitempty"` // 编译产物路径
+}
+
+// Registry deployments.json 的内存表示，同一网络下同名合约只保留最新一条
+type Registry struct {
+	path        string
+	deployments []Deployment
+}
+
+type registryFile struct {
+	Deployments []Deployment `json:"deployments"`
+}
+
+// OpenRegistry 读取部署记录文件，文件不存在时返回空记录
+func OpenRegistry(path string) (*Registry, error) {
+	r := &Registry{path: path}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var file registryFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %w", path, err)
+	}
+	r.deployments = file.Deployments
+	return r, nil
+}
+
+// Path 记录文件路径
+func (r *Registry) Path() string {
+	return r.path
+}
+
+// Add 添加部署记录，替换同一网络下的同名记录
+func (r *Registry) Add(d Deployment) {
+	for i := range r.deployments {
+		if r.deployments[i].Network == d.Network && r.deployments[i].Name == d.Name {
+			r.deployments[i] = d
+			return
+		}
+	}
+	r.deployments = append(r.deployments, d)
+}
+
+// Lookup 按网络和合约名查找，找不到时返回 ErrNotDeployed
+func (r *Registry) Lookup(network, name string) (*Deployment, error) {
+	for i := range r.deployments {
+		if r.deployments[i].Network == network && r.deployments[i].Name == name {
+			d := r.deployments[i]
+			return &d, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s/%s（%s）", ErrNotDeployed, network, name, r.path)
+}
+
+// Network 返回某个网络下的全部记录，按合约名排序
+func (r *Registry) Network(network string) []Deployment {
+	var list []Deployment
+	for _, d := range r.deployments {
+		if d.Network == network {
+			list = append(list, d)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Save 写回记录文件（先写临时文件再重命名，避免中途失败留下半个文件）
+func (r *Registry) Save() error {
+	sorted := append([]Deployment(nil), r.deployments...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Network != sorted[j].Network {
+			return sorted[i].Network < sorted[j].Network
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	raw, err := json.MarshalIndent(registryFile{Deployments: sorted}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, append(raw, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.path)
+}
+
+// CodeReader Resolve 需要的节点接口
+type CodeReader interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+}
+
+// Resolve 打开部署记录文件，按当前连接的网络和合约名查找地址，
+// 并确认链上代码仍与记录中的 bytecodeHash 一致
+func Resolve(ctx context.Context, backend CodeReader, path, name string) (*Deployment, error) {
+	reg, err := OpenRegistry(path)
+	if err != nil {
+		return nil, err
+	}
+	chainID, err := backend.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链 ID 失败: %w", err)
+	}
+	d, err := reg.Lookup(NetworkName(chainID), name)
+	if err != nil {
+		return nil, err
+	}
+	code, err := backend.CodeAt(ctx, d.Address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 代码失败: %w", d.Address.Hex(), err)
+	}
+	if hash := crypto.Keccak256Hash(code); hash != d.BytecodeHash {
+		return d, fmt.Errorf("%w: %s %s（链上 %s，记录 %s）", ErrCodeChanged, d.Name, d.Address.Hex(), hash.Hex(), d.BytecodeHash.Hex())
+	}
+	return d, nil
+}
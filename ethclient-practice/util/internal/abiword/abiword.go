@@ -0,0 +1,26 @@
+// Package abiword 按 abi.encode 的规则手工拼接 32 字节字。
+// userop 和 safe 计算哈希时只用到静态类型，不需要为此解析 ABI
+package abiword
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Concat 把每个值左侧补零到 32 字节后拼接，等同于只含静态类型的 abi.encode
+func Concat(values ...[]byte) []byte {
+	out := make([]byte, 0, 32*len(values))
+	for _, v := range values {
+		out = append(out, common.LeftPadBytes(v, 32)...)
+	}
+	return out
+}
+
+// Big 返回 v，v 为 nil 时返回 0，用于可选的 *big.Int 字段
+func Big(v *big.Int) *big.Int {
+	if v == nil {
+		return new(big.Int)
+	}
+	return v
+}
@@ -0,0 +1,44 @@
+package abiword
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestConcatMatchesABIEncode(t *testing.T) {
+	typ := func(s string) abi.Type {
+		ty, err := abi.NewType(s, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ty
+	}
+	args := abi.Arguments{{Type: typ("address")}, {Type: typ("uint256")}, {Type: typ("bytes32")}, {Type: typ("uint8")}}
+	addr := common.HexToAddress("0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789")
+	n := big.NewInt(1337)
+	hash := common.HexToHash("0x88a9b2626e43da02f978ae6cc89feffb68afcd5860cb9239337352db4b694fe1")
+	want, err := args.Pack(addr, n, hash, uint8(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Concat(addr.Bytes(), n.Bytes(), hash.Bytes(), []byte{1}); !bytes.Equal(got, want) {
+		t.Fatalf("Concat = %x\n期望 %x", got, want)
+	}
+	if got := Concat(); len(got) != 0 {
+		t.Fatalf("没有参数时应返回空，得到 %x", got)
+	}
+}
+
+func TestBig(t *testing.T) {
+	if Big(nil).Sign() != 0 {
+		t.Fatal("nil 应视为 0")
+	}
+	v := big.NewInt(7)
+	if Big(v) != v {
+		t.Fatal("非 nil 时应原样返回")
+	}
+}
@@ -0,0 +1,15 @@
+package logdecode
+
+import (
+	"encoding/json"
+
+	"github.com/dapp-learning/ethclient/util/output"
+)
+
+// MarshalJSON 输出参数时统一转换值的格式，规则与 util/output 相同
+func (a Arg) MarshalJSON() ([]byte, error) {
+	type plain Arg
+	p := plain(a)
+	p.Value = output.Normalize(a.Value)
+	return json.Marshal(p)
+}
@@ -0,0 +1,196 @@
+// Package logdecode 根据 ABI 把收据中的 types.Log 解码为带参数名的事件记录。
+//
+// 事件定义来自 sigdb 签名数据库，因此可以同时加载任意多个合约的 ABI。
+// 对于 indexed 的动态类型（string、bytes、数组、元组），日志中只保存了
+// keccak256 哈希，解码结果会保留该哈希并标记 Hashed。
+package logdecode
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/dapp-learning/ethclient/util/sigdb"
+)
+
+// Arg 解码后的事件参数
+type Arg struct {
+	Name    string      `json:"name"`
+	Type    string      `json:"type"`
+	Indexed bool        `json:"indexed"`
+	Hashed  bool        `json:"hashed,omitempty"` // indexed 动态类型，Value 是原值的 keccak256
+	Value   interface{} `json:"value"`
+}
+
+// Position 日志在链上的位置
+type Position struct {
+	BlockNumber uint64      `json:"blockNumber"`
+	BlockHash   common.Hash `json:"blockHash"`
+	TxHash      common.Hash `json:"txHash"`
+	TxIndex     uint        `json:"txIndex"`
+	LogIndex    uint        `json:"logIndex"`
+	Removed     bool        `json:"removed,omitempty"`
+}
+
+// Record 解码后的事件记录
+type Record struct {
+	Address   common.Address `json:"address"`
+	Event     string         `json:"event"`
+	Signature string         `json:"signature"`
+	Anonymous bool           `json:"anonymous,omitempty"`
+	Args      []Arg          `json:"args"`
+	Position  Position       `json:"position"`
+}
+
+// Get 按名称取参数值
+func (r *Record) Get(name string) (interface{}, bool) {
+	for _, arg := range r.Args {
+		if arg.Name == name {
+			return arg.Value, true
+		}
+	}
+	return nil, false
+}
+
+// Map 以 参数名 → 值 的形式返回所有参数
+func (r *Record) Map() map[string]interface{} {
+	m := make(map[string]interface{}, len(r.Args))
+	for _, arg := range r.Args {
+		m[arg.Name] = arg.Value
+	}
+	return m
+}
+
+// Decoder 事件日志解码器
+type Decoder struct {
+	sigs *sigdb.Registry
+}
+
+// New 使用给定的签名数据库创建解码器
+func New(sigs *sigdb.Registry) *Decoder {
+	return &Decoder{sigs: sigs}
+}
+
+// NewWithABIs 在默认签名数据库的基础上加载 ABI / 编译产物文件，创建解码器
+func NewWithABIs(paths ...string) (*Decoder, error) {
+	sigs := sigdb.NewDefault()
+	for _, path := range paths {
+		if _, err := sigs.LoadFile(path); err != nil {
+			return nil, err
+		}
+	}
+	return New(sigs), nil
+}
+
+// Registry 返回解码器使用的签名数据库
+func (d *Decoder) Registry() *sigdb.Registry {
+	return d.sigs
+}
+
+// Decode 解码单条日志。找不到匹配事件时返回 sigdb.ErrNotFound / sigdb.ErrNoMatch
+func (d *Decoder) Decode(log *types.Log) (*Record, error) {
+	entry, err := d.sigs.ResolveEvent(log)
+	if err != nil {
+		return nil, err
+	}
+	record, err := DecodeEvent(entry.Event(), log)
+	if err != nil {
+		return nil, err
+	}
+	record.Signature = entry.Signature
+	return record, nil
+}
+
+// DecodeEvent 按已知的事件定义解码日志，调用方需保证日志属于该事件
+func DecodeEvent(event *abi.Event, log *types.Log) (*Record, error) {
+	record := &Record{
+		Address:   log.Address,
+		Event:     event.RawName,
+		Signature: event.Sig,
+		Anonymous: event.Anonymous,
+		Args:      make([]Arg, 0, len(event.Inputs)),
+		Position: Position{
+			BlockNumber: log.BlockNumber,
+			BlockHash:   log.BlockHash,
+			TxHash:      log.TxHash,
+			TxIndex:     log.TxIndex,
+			LogIndex:    log.Index,
+			Removed:     log.Removed,
+		},
+	}
+
+	// 非 indexed 参数按顺序编码在 Data 中
+	values, err := event.Inputs.NonIndexed().Unpack(log.Data)
+	if err != nil {
+		return nil, fmt.Errorf("解码 %s 的 data 失败: %w", event.Sig, err)
+	}
+
+	// 非匿名事件的 Topics[0] 是事件签名，indexed 参数从下一个 topic 开始
+	topics := log.Topics
+	if !event.Anonymous {
+		if len(topics) == 0 {
+			return nil, fmt.Errorf("日志没有 topic，不是 %s 事件", event.Sig)
+		}
+		topics = topics[1:]
+	}
+
+	topicIdx, dataIdx := 0, 0
+	for i, input := range event.Inputs {
+		arg := Arg{Name: argName(input, i), Type: input.Type.String(), Indexed: input.Indexed}
+		if input.Indexed {
+			if topicIdx >= len(topics) {
+				return nil, fmt.Errorf("%s 的 indexed 参数数量与日志 topic 不一致", event.Sig)
+			}
+			value, hashed, err := decodeTopic(input.Type, topics[topicIdx])
+			if err != nil {
+				return nil, fmt.Errorf("解码 %s 的参数 %s 失败: %w", event.Sig, arg.Name, err)
+			}
+			arg.Value, arg.Hashed = value, hashed
+			topicIdx++
+		} else {
+			arg.Value = values[dataIdx]
+			dataIdx++
+		}
+		record.Args = append(record.Args, arg)
+	}
+	return record, nil
+}
+
+// DecodeAll 解码一组日志（通常是一个收据的 Logs）。
+// 无法解码的日志不会中断处理，而是在 failed 中返回其下标和错误
+func (d *Decoder) DecodeAll(logs []*types.Log) (records []*Record, failed map[int]error) {
+	failed = make(map[int]error)
+	for i, log := range logs {
+		record, err := d.Decode(log)
+		if err != nil {
+			failed[i] = err
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, failed
+}
+
+// decodeTopic 解码一个 indexed 参数。
+// 静态类型的 topic 就是其 32 字节 ABI 编码；动态类型只能返回哈希
+func decodeTopic(typ abi.Type, topic common.Hash) (interface{}, bool, error) {
+	switch typ.T {
+	case abi.StringTy, abi.BytesTy, abi.SliceTy, abi.ArrayTy, abi.TupleTy:
+		return topic, true, nil
+	}
+	values, err := abi.Arguments{{Type: typ}}.Unpack(topic.Bytes())
+	if err != nil {
+		return nil, false, err
+	}
+	return values[0], false, nil
+}
+
+// argName 参数没有名称时使用 arg0、arg1……
+func argName(input abi.Argument, i int) string {
+	if input.Name != "" {
+		return input.Name
+	}
+	return fmt.Sprintf("arg%d", i)
+}
@@ -0,0 +1,179 @@
+package logdecode_test
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/dapp-learning/ethclient/util/logdecode"
+	"github.com/dapp-learning/ethclient/util/sigdb"
+)
+
+var (
+	token = common.HexToAddress("0x5FbDB2315678afecb367f032d93F642f64180aa3")
+	alice = common.HexToAddress("0x71C7656EC7ab88b098defB751B7401B5f6d8976F")
+	bob   = common.HexToAddress("0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359")
+)
+
+func newDecoder(t *testing.T, sigs ...string) *logdecode.Decoder {
+	t.Helper()
+	r := sigdb.New()
+	for _, sig := range sigs {
+		if err := r.AddSignature("test", sig); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return logdecode.New(r)
+}
+
+// pack 按类型列表 ABI 编码，用于构造日志的 data
+func pack(t *testing.T, typeNames []string, values ...interface{}) []byte {
+	t.Helper()
+	var args abi.Arguments
+	for _, typ := range typeNames {
+		abiType, err := abi.NewType(typ, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		args = append(args, abi.Argument{Type: abiType})
+	}
+	data, err := args.Pack(values...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func topic(v int64) common.Hash { return common.BigToHash(big.NewInt(v)) }
+
+func TestIndexedDynamicType(t *testing.T) {
+	d := newDecoder(t, "event Registered(string indexed name, uint256[] indexed ids, address indexed owner, bytes data)")
+	ids := pack(t, []string{"uint256", "uint256"}, big.NewInt(1), big.NewInt(2)) // 数组的 topic 是元素编码拼接后的哈希
+	log := &types.Log{
+		Address: token,
+		Topics: []common.Hash{
+			crypto.Keccak256Hash([]byte("Registered(string,uint256[],address,bytes)")),
+			crypto.Keccak256Hash([]byte("alice.eth")),
+			crypto.Keccak256Hash(ids),
+			common.BytesToHash(alice.Bytes()),
+		},
+		Data: pack(t, []string{"bytes"}, []byte{0xca, 0xfe}),
+	}
+	record, err := d.Decode(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		value  interface{}
+		hashed bool
+	}{
+		{"name", crypto.Keccak256Hash([]byte("alice.eth")), true},
+		{"ids", crypto.Keccak256Hash(ids), true},
+		{"owner", alice, false},
+		{"data", []byte{0xca, 0xfe}, false},
+	}
+	if len(record.Args) != len(tests) {
+		t.Fatalf("解码出 %d 个参数", len(record.Args))
+	}
+	for i, tt := range tests {
+		arg := record.Args[i]
+		if arg.Name != tt.name || arg.Hashed != tt.hashed || !equal(arg.Value, tt.value) {
+			t.Errorf("参数 %d = %+v，期望 %s = %v（hashed=%v）", i, arg, tt.name, tt.value, tt.hashed)
+		}
+	}
+
+	// JSON 中保留 hashed 标记，值为哈希
+	raw, err := json.Marshal(record.Args[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `"hashed":true,"value":"` + crypto.Keccak256Hash([]byte("alice.eth")).Hex() + `"`; !strings.Contains(string(raw), want) {
+		t.Errorf("JSON %s 中没有 %s", raw, want)
+	}
+}
+
+func equal(a, b interface{}) bool {
+	ja, _ := json.Marshal(a)
+	jb, _ := json.Marshal(b)
+	return string(ja) == string(jb)
+}
+
+func TestAnonymousEvent(t *testing.T) {
+	d := newDecoder(t,
+		"event Transfer(address indexed from, address indexed to, uint256 value)",
+		"event Moved(address indexed from, uint256 indexed id, uint256 amount) anonymous",
+	)
+	// 匿名事件没有签名 topic，第一个 topic 就是第一个 indexed 参数
+	log := &types.Log{
+		Address: token,
+		Topics:  []common.Hash{common.BytesToHash(alice.Bytes()), topic(7)},
+		Data:    pack(t, []string{"uint256"}, big.NewInt(1000)),
+	}
+	record, err := d.Decode(log)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !record.Anonymous || record.Event != "Moved" {
+		t.Fatalf("解码为 %s（anonymous=%v）", record.Signature, record.Anonymous)
+	}
+	m := record.Map()
+	if m["from"] != alice || m["id"].(*big.Int).Int64() != 7 || m["amount"].(*big.Int).Int64() != 1000 {
+		t.Fatalf("参数不符: %v", m)
+	}
+
+	// topic 数量不同的匿名日志不会被误认为 Moved
+	log.Topics = log.Topics[:1]
+	if _, err := d.Decode(log); !errors.Is(err, sigdb.ErrNotFound) {
+		t.Fatalf("返回 %v，期望 %v", err, sigdb.ErrNotFound)
+	}
+}
+
+func TestTopicCount(t *testing.T) {
+	const (
+		erc20  = "event Transfer(address indexed from, address indexed to, uint256 value)"
+		erc721 = "event Transfer(address indexed from, address indexed to, uint256 indexed tokenId)"
+	)
+	transferTopic := crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	from, to := common.BytesToHash(alice.Bytes()), common.BytesToHash(bob.Bytes())
+
+	// ERC20 与 ERC721 的 Transfer 签名相同，按 topic 数量区分
+	d := newDecoder(t, erc20, erc721)
+	erc20Log := &types.Log{Topics: []common.Hash{transferTopic, from, to}, Data: pack(t, []string{"uint256"}, big.NewInt(5))}
+	erc721Log := &types.Log{Topics: []common.Hash{transferTopic, from, to, topic(42)}}
+	records, failed := d.DecodeAll([]*types.Log{erc20Log, erc721Log})
+	if len(failed) != 0 || len(records) != 2 {
+		t.Fatalf("解码 %d 条，失败 %v", len(records), failed)
+	}
+	if _, ok := records[0].Get("value"); !ok {
+		t.Error("3 个 topic 的日志应解码为 ERC20 Transfer")
+	}
+	if id, ok := records[1].Get("tokenId"); !ok || id.(*big.Int).Int64() != 42 {
+		t.Error("4 个 topic 的日志应解码为 ERC721 Transfer")
+	}
+
+	// 只有 ERC20 定义时，4 个 topic 的日志没有匹配的事件
+	d = newDecoder(t, erc20)
+	records, failed = d.DecodeAll([]*types.Log{erc721Log, erc20Log})
+	if len(records) != 1 || !errors.Is(failed[0], sigdb.ErrNoMatch) {
+		t.Fatalf("解码 %d 条，失败 %v，期望第 0 条返回 %v", len(records), failed, sigdb.ErrNoMatch)
+	}
+
+	// 直接按事件定义解码时检查 topic 数量
+	event := d.Registry().Events(transferTopic)[0].Event()
+	short := &types.Log{Topics: []common.Hash{transferTopic, from}, Data: erc20Log.Data}
+	if _, err := logdecode.DecodeEvent(event, short); err == nil || !strings.Contains(err.Error(), "topic 不一致") {
+		t.Fatalf("topic 不足时返回 %v", err)
+	}
+	if _, err := logdecode.DecodeEvent(event, &types.Log{Data: erc20Log.Data}); err == nil {
+		t.Fatal("没有 topic 的日志应返回错误")
+	}
+}
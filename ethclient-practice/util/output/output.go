@@ -0,0 +1,65 @@
+// Package output 查询程序共用的输出层：同一组记录可以输出为对齐的文本表格、
+// JSON、JSON Lines 或 CSV，供人阅读，也可以直接交给 jq、表格软件和看板。
+//
+// 记录是带 json 标签的结构体，字段名取自 json 标签，在所有格式中保持一致：
+//
+//   - *big.Int（wei、Gas 价格、ABI 中超过 64 位的整数）输出为精确的十进制字符串，不会因浮点丢失精度；
+//     uint8 ~ uint64 等 Go 整数输出为 JSON 数字
+//   - 地址、哈希输出为十六进制字符串，[]byte 输出为 0x 开头的十六进制
+//   - time.Time 输出为 RFC 3339 格式的 UTC 时间
+//   - CSV 和表格中，嵌套的结构体和数组以紧凑 JSON 写入一个单元格
+package output
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Format 输出格式
+type Format string
+
+const (
+	FormatTable Format = "table" // 对齐的文本表格
+	FormatJSON  Format = "json"  // 一个 JSON 文档
+	FormatJSONL Format = "jsonl" // 每行一个 JSON 对象
+	FormatCSV   Format = "csv"   // 首行为字段名
+)
+
+// Formats 支持的全部格式
+var Formats = []Format{FormatTable, FormatJSON, FormatJSONL, FormatCSV}
+
+// FlagUsage --format 参数的说明
+const FlagUsage = "输出格式: table | json | jsonl | csv"
+
+// ParseFormat 解析格式名，不区分大小写
+func ParseFormat(s string) (Format, error) {
+	for _, f := range Formats {
+		if strings.EqualFold(s, string(f)) {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("未知输出格式 %q，可选: table | json | jsonl | csv", s)
+}
+
+// String 实现 flag.Value
+func (f *Format) String() string {
+	return string(*f)
+}
+
+// Set 实现 flag.Value，可以直接注册为命令行参数：
+//
+//	format := output.FormatTable
+//	flag.Var(&format, "format", output.FlagUsage)
+func (f *Format) Set(s string) error {
+	parsed, err := ParseFormat(s)
+	if err != nil {
+		return err
+	}
+	*f = parsed
+	return nil
+}
+
+// Machine 是否为机器可读格式（json / jsonl / csv）
+func (f Format) Machine() bool {
+	return f != FormatTable
+}
@@ -0,0 +1,87 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Table 按显示宽度对齐的文本表格，中文按两个字符宽计算。表头为空时不输出表头和分隔线
+type Table struct {
+	header []string
+	rows   [][]string
+}
+
+// NewTable 创建表格
+func NewTable(header ...string) *Table {
+	return &Table{header: header}
+}
+
+// Add 添加一行
+func (t *Table) Add(cols ...string) {
+	t.rows = append(t.rows, cols)
+}
+
+// Write 输出表格，列之间用两个空格分隔
+func (t *Table) Write(w io.Writer) {
+	var widths []int
+	for _, row := range append([][]string{t.header}, t.rows...) {
+		for i, col := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			if DisplayWidth(col) > widths[i] {
+				widths[i] = DisplayWidth(col)
+			}
+		}
+	}
+	line := func(cols []string) {
+		var b strings.Builder
+		for i, col := range cols {
+			if i < len(cols)-1 {
+				b.WriteString(Pad(col, widths[i]+2))
+			} else {
+				b.WriteString(col)
+			}
+		}
+		fmt.Fprintln(w, b.String())
+	}
+	if len(t.header) > 0 {
+		line(t.header)
+		sep := make([]string, len(t.header))
+		for i := range sep {
+			sep[i] = strings.Repeat("─", widths[i])
+		}
+		line(sep)
+	}
+	for _, row := range t.rows {
+		line(row)
+	}
+}
+
+// DisplayWidth 字符串在终端中的显示宽度
+func DisplayWidth(s string) int {
+	width := 0
+	for len(s) > 0 {
+		r, size := utf8.DecodeRuneInString(s)
+		s = s[size:]
+		switch {
+		case r >= 0x1100 && r <= 0x115F, r >= 0x2E80 && r <= 0xA4CF, r >= 0xAC00 && r <= 0xD7A3,
+			r >= 0xF900 && r <= 0xFAFF, r >= 0xFE30 && r <= 0xFE4F, r >= 0xFF00 && r <= 0xFF60,
+			r >= 0xFFE0 && r <= 0xFFE6:
+			width += 2
+		default:
+			width++
+		}
+	}
+	return width
+}
+
+// Pad 在右侧补空格，使 s 的显示宽度达到 width
+func Pad(s string, width int) string {
+	if n := width - DisplayWidth(s); n > 0 {
+		return s + strings.Repeat(" ", n)
+	}
+	return s
+}
@@ -0,0 +1,186 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// field 结构体的一个输出字段
+type field struct {
+	name      string
+	header    string // 表格表头，取 table 标签，默认与 name 相同
+	omitEmpty bool
+	value     reflect.Value
+}
+
+// fields 按声明顺序列出结构体的导出字段，跳过 json:"-"
+func fields(v reflect.Value) []field {
+	var out []field
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue // 未导出字段
+		}
+		tag := strings.Split(sf.Tag.Get("json"), ",")
+		if tag[0] == "-" {
+			continue
+		}
+		f := field{name: sf.Name, value: v.Field(i)}
+		if tag[0] != "" {
+			f.name = tag[0]
+		}
+		for _, opt := range tag[1:] {
+			if opt == "omitempty" {
+				f.omitEmpty = true
+			}
+		}
+		f.header = f.name
+		if h := sf.Tag.Get("table"); h != "" {
+			f.header = h
+		}
+		out = append(out, f)
+	}
+	return out
+}
+
+// normalize 把值转换为 JSON 友好的形式，规则见包文档
+func normalize(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+		return nil
+	}
+	switch x := v.Interface().(type) {
+	case object:
+		return x // 已经转换过
+	case *big.Int:
+		return x.String()
+	case big.Int:
+		return x.String()
+	case common.Address:
+		return x.Hex()
+	case common.Hash:
+		return x.Hex()
+	case time.Time:
+		return x.UTC().Format(time.RFC3339)
+	case []byte:
+		return hexutil.Encode(x)
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		return normalize(v.Elem())
+	case reflect.Struct:
+		fs := fields(v)
+		obj := make(object, 0, len(fs))
+		for _, f := range fs {
+			if f.omitEmpty && f.value.IsZero() {
+				continue
+			}
+			obj = append(obj, entry{f.name, normalize(f.value)})
+		}
+		return obj
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return hexutil.Encode(b)
+		}
+		list := make([]interface{}, v.Len())
+		for i := range list {
+			list[i] = normalize(v.Index(i))
+		}
+		return list
+	case reflect.Map:
+		m := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			m[cell(normalize(iter.Key()))] = normalize(iter.Value())
+		}
+		return m
+	}
+	return v.Interface()
+}
+
+// Normalize 把任意值按包文档中的规则转换为 JSON 友好的形式：结构体变为按字段顺序排列的对象，
+// 数组和切片递归转换。ABI 解码得到的参数、返回值也使用它，所有工具输出的值格式一致
+func Normalize(v interface{}) interface{} {
+	return normalize(reflect.ValueOf(v))
+}
+
+// Text 把任意值按包文档中的规则写成一行文本，例如解码后的合约参数和返回值
+func Text(v interface{}) string {
+	return cell(normalize(reflect.ValueOf(v)))
+}
+
+// cell 把规范化后的值写成 CSV / 表格单元格
+func cell(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return x
+	case bool:
+		return strconv.FormatBool(x)
+	case float32:
+		return strconv.FormatFloat(float64(x), 'f', -1, 32)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+	return string(b)
+}
+
+// object 保持字段顺序的 JSON 对象
+type object []entry
+
+type entry struct {
+	key   string
+	value interface{}
+}
+
+// MarshalJSON 按字段声明顺序输出
+func (o object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, e := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(e.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(e.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
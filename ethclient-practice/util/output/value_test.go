@@ -0,0 +1,48 @@
+package output_test
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/dapp-learning/ethclient/util/output"
+)
+
+func TestNormalize(t *testing.T) {
+	type tuple struct {
+		Recipient common.Address
+		Amount    *big.Int
+		Fee       uint16
+		Memo      []byte `json:"memo"`
+	}
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"uint8", uint8(18), `18`},
+		{"uint64", uint64(1) << 63, `9223372036854775808`},
+		{"int64", int64(-5), `-5`},
+		{"big.Int", new(big.Int).Lsh(big.NewInt(1), 200), `"1606938044258990275541962092341162602522202993782792835301376"`},
+		{"nil big.Int", (*big.Int)(nil), `null`},
+		{"bytes32", [32]byte{31: 1}, `"0x0000000000000000000000000000000000000000000000000000000000000001"`},
+		{"bytes", []byte{0xca, 0xfe}, `"0xcafe"`},
+		{"hash", common.HexToHash("0x2a"), `"0x000000000000000000000000000000000000000000000000000000000000002a"`},
+		{"tuple", tuple{common.HexToAddress("0xfb6916095ca1df60bb79ce92ce3ea74c37c5d359"), big.NewInt(7), 30, nil},
+			`{"Recipient":"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359","Amount":"7","Fee":30,"memo":"0x"}`},
+		{"array", []*big.Int{big.NewInt(1), big.NewInt(2)}, `["1","2"]`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(output.Normalize(tt.v))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("得到 %s，期望 %s", got, tt.want)
+			}
+		})
+	}
+}
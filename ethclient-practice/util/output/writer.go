@@ -0,0 +1,134 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// Writer 按格式输出一组同类记录。列由第一条记录决定：
+//
+//   - table：表头取 table 标签（默认为 json 字段名），Flush 时按列宽对齐输出
+//   - json：Flush 时输出一个数组
+//   - jsonl：每条记录立即输出一行
+//   - csv：首行为 json 字段名，omitempty 的字段同样保留列
+type Writer struct {
+	w       io.Writer
+	format  Format
+	columns []string
+	table   *Table
+	csv     *csv.Writer
+	rows    []interface{}
+}
+
+// NewWriter 创建记录输出器，结束时需要调用 Flush
+func NewWriter(w io.Writer, format Format) *Writer {
+	return &Writer{w: w, format: format}
+}
+
+// Write 输出一条记录，record 必须是结构体或结构体指针
+func (w *Writer) Write(record interface{}) error {
+	v := reflect.Indirect(reflect.ValueOf(record))
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("output: 记录必须是结构体，实际为 %T", record)
+	}
+	fs := fields(v)
+	if w.columns == nil {
+		if err := w.start(fs); err != nil {
+			return err
+		}
+	} else if err := w.check(fs); err != nil {
+		return err
+	}
+
+	switch w.format {
+	case FormatJSON:
+		w.rows = append(w.rows, normalize(v))
+	case FormatJSONL:
+		return json.NewEncoder(w.w).Encode(normalize(v))
+	case FormatCSV, FormatTable:
+		cols := make([]string, len(fs))
+		for i, f := range fs {
+			cols[i] = cell(normalize(f.value))
+		}
+		if w.format == FormatCSV {
+			return w.csv.Write(cols)
+		}
+		w.table.Add(cols...)
+	}
+	return nil
+}
+
+// Flush 写出缓存的内容。json 格式没有记录时输出 []
+func (w *Writer) Flush() error {
+	switch w.format {
+	case FormatJSON:
+		rows := w.rows
+		if rows == nil {
+			rows = []interface{}{}
+		}
+		return WriteJSON(w.w, rows)
+	case FormatCSV:
+		if w.csv == nil {
+			return nil
+		}
+		w.csv.Flush()
+		return w.csv.Error()
+	case FormatTable:
+		if w.table != nil {
+			w.table.Write(w.w)
+		}
+	}
+	return nil
+}
+
+// start 根据第一条记录确定列并输出 CSV 表头
+func (w *Writer) start(fs []field) error {
+	w.columns = make([]string, len(fs))
+	headers := make([]string, len(fs))
+	for i, f := range fs {
+		w.columns[i] = f.name
+		headers[i] = f.header
+	}
+	switch w.format {
+	case FormatCSV:
+		w.csv = csv.NewWriter(w.w)
+		return w.csv.Write(w.columns)
+	case FormatTable:
+		w.table = NewTable(headers...)
+	case FormatJSON, FormatJSONL:
+	default:
+		return fmt.Errorf("output: 未知格式 %q", w.format)
+	}
+	return nil
+}
+
+// errColumns 同一个 Writer 中混用了不同结构的记录
+var errColumns = errors.New("output: 记录的字段与第一条记录不一致")
+
+func (w *Writer) check(fs []field) error {
+	if len(fs) != len(w.columns) {
+		return errColumns
+	}
+	for i, f := range fs {
+		if f.name != w.columns[i] {
+			return errColumns
+		}
+	}
+	return nil
+}
+
+// WriteJSON 以缩进 JSON 输出一个文档，值按包文档中的规则转换
+func WriteJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(normalize(reflect.ValueOf(v)))
+}
+
+// WriteJSONLine 以单行 JSON 输出一个值（JSON Lines 中的一行）
+func WriteJSONLine(w io.Writer, v interface{}) error {
+	return json.NewEncoder(w).Encode(normalize(reflect.ValueOf(v)))
+}
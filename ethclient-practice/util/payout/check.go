@@ -0,0 +1,108 @@
+package payout
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+)
+
+// ErrInsufficientBalance 余额不足以支付剩余的付款
+var ErrInsufficientBalance = errors.New("余额不足")
+
+// Requirement 一种资产剩余需要付出的总额和账户当前余额
+type Requirement struct {
+	Asset   *Asset
+	Rows    int      // 剩余的付款行数
+	Amount  *big.Int // 剩余付款总额（最小单位）
+	Fees    *big.Int // 仅 ETH：全部剩余交易预估的最高 Gas 费用
+	Balance *big.Int
+}
+
+// Total 需要的总额：付款总额加上 Gas 费用
+func (r *Requirement) Total() *big.Int {
+	if r.Fees == nil {
+		return r.Amount
+	}
+	return new(big.Int).Add(r.Amount, r.Fees)
+}
+
+// Enough 余额是否足够
+func (r *Requirement) Enough() bool {
+	return r.Balance.Cmp(r.Total()) >= 0
+}
+
+// Check 统计还没有付款的行（Status.Retry）需要的各资产总额，与账户余额比较。
+// Gas 费用按每种资产的第一笔付款估算 Gas，再乘以行数和当前的最高费用（2 × baseFee + 小费），
+// 通常高于实际费用。余额不足时同时返回统计结果和 ErrInsufficientBalance
+func Check(ctx context.Context, b Backend, plan *Plan, j *Journal, gasMargin uint64) ([]*Requirement, error) {
+	reqs := make(map[*Asset]*Requirement)
+	first := make(map[*Asset]*Payment)
+	for _, asset := range plan.Assets {
+		reqs[asset] = &Requirement{Asset: asset, Amount: new(big.Int)}
+	}
+	for _, p := range plan.Payments {
+		if !j.Get(p.Line).Status.Retry() {
+			continue
+		}
+		req := reqs[p.Asset]
+		req.Rows++
+		req.Amount.Add(req.Amount, p.Value)
+		if first[p.Asset] == nil {
+			first[p.Asset] = p
+		}
+	}
+
+	var err error
+	for _, asset := range plan.Assets {
+		req := reqs[asset]
+		if asset.Token == nil {
+			req.Balance, err = b.BalanceAt(ctx, plan.From, nil)
+		} else {
+			req.Balance, err = chain.TokenBalance(ctx, b, *asset.Token, plan.From)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("查询 %s 余额失败: %w", asset.Symbol, err)
+		}
+	}
+
+	// 与 chain.BuildTx 签名时使用同一组费用，余额检查不会低估
+	fees, err := chain.SuggestFees(ctx, b)
+	if err != nil {
+		return nil, err
+	}
+	feeCap := fees.Max()
+	eth := reqs[plan.Assets[0]]
+	eth.Fees = new(big.Int)
+	for _, asset := range plan.Assets {
+		req, p := reqs[asset], first[asset]
+		// 余额不足时估算也会失败，这种资产不计入费用，直接报告余额不足
+		if p == nil || !req.Enough() {
+			continue
+		}
+		gas, err := b.EstimateGas(ctx, ethereum.CallMsg{From: plan.From, To: p.target(), Value: p.ethValue(), Data: p.Data()})
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 行: 估算 Gas 失败: %w", p.Line, err)
+		}
+		fee := new(big.Int).SetUint64(chain.WithMargin(gas, gasMargin) * uint64(req.Rows))
+		eth.Fees.Add(eth.Fees, fee.Mul(fee, feeCap))
+	}
+
+	out := make([]*Requirement, len(plan.Assets))
+	var short []string
+	for i, asset := range plan.Assets {
+		out[i] = reqs[asset]
+		if !out[i].Enough() {
+			short = append(short, fmt.Sprintf("%s 需要 %s，余额 %s", asset.Symbol, asset.Format(out[i].Total()), asset.Format(out[i].Balance)))
+		}
+	}
+	if len(short) > 0 {
+		return out, fmt.Errorf("%w: %s", ErrInsufficientBalance, strings.Join(short, "；"))
+	}
+	return out, nil
+}
@@ -0,0 +1,161 @@
+package payout
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Status 一行付款的状态
+type Status string
+
+const (
+	StatusPending   Status = "pending"   // 还没有签名，日志中没有这一行
+	StatusError     Status = "error"     // 签名前出错（例如估算 Gas 失败），没有发出交易，重新运行时重试
+	StatusSigned    Status = "signed"    // 已签名，可能还没有广播成功
+	StatusSent      Status = "sent"      // 已广播，等待确认
+	StatusConfirmed Status = "confirmed" // 已打包且执行成功
+	StatusFailed    Status = "failed"    // 已打包但执行失败，没有付款，需要人工处理
+	StatusDropped   Status = "dropped"   // nonce 被其他交易占用且操作员确认没有付款，这笔交易不会再上链，重新运行时重新发送
+)
+
+// InFlight 交易已签名但还没有确认，重新运行时先处理这些行
+func (s Status) InFlight() bool {
+	return s == StatusSigned || s == StatusSent
+}
+
+// Retry 没有付款也没有交易在途，重新运行时重新发送
+func (s Status) Retry() bool {
+	return s == StatusPending || s == StatusError || s == StatusDropped
+}
+
+// Entry 日志中的一条记录。日志只追加，同一行以最后一条记录为准
+type Entry struct {
+	Line    int           `json:"line"`
+	Key     string        `json:"key"`
+	Status  Status        `json:"status"`
+	Nonce   uint64        `json:"nonce,omitempty"`
+	TxHash  *common.Hash  `json:"txHash,omitempty"`
+	RawTx   hexutil.Bytes `json:"rawTx,omitempty"` // 签名后的交易，重新运行时原样广播
+	Block   uint64        `json:"block,omitempty"`
+	GasUsed uint64        `json:"gasUsed,omitempty"`
+	Fee     *big.Int      `json:"fee,omitempty"` // wei
+	Error   string        `json:"error,omitempty"`
+	Time    time.Time     `json:"time"`
+}
+
+// Journal 付款状态日志，每行一个 JSON 对象（JSON Lines）。
+// 每条记录写入后立即 fsync，进程崩溃最多丢失正在写入的那一条
+type Journal struct {
+	path    string
+	mu      sync.Mutex
+	f       *os.File
+	entries map[int]Entry
+}
+
+// OpenJournal 打开日志并读取已有的记录，文件不存在时创建。
+// 最后一行不完整（写入时崩溃）时忽略该行
+func OpenJournal(path string) (*Journal, error) {
+	j := &Journal{path: path, entries: make(map[int]Entry)}
+	raw, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	valid := 0
+	sc := bufio.NewScanner(bytes.NewReader(raw))
+	sc.Buffer(nil, 1<<20)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			valid += len(line) + 1
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(line, &e); err != nil {
+			if valid+len(line) == len(raw) {
+				break // 末尾不完整的一行
+			}
+			return nil, fmt.Errorf("%s 第 %d 行: %w", path, n, err)
+		}
+		j.entries[e.Line] = e
+		valid += len(line) + 1
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("读取 %s 失败: %w", path, err)
+	}
+	if valid > len(raw) {
+		valid = len(raw) // 最后一行完整但缺少换行符
+	}
+
+	if j.f, err = os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644); err != nil {
+		return nil, err
+	}
+	// 截掉不完整的一行，之后的记录从完整的行尾开始追加
+	if valid < len(raw) {
+		if err := j.f.Truncate(int64(valid)); err != nil {
+			j.f.Close()
+			return nil, err
+		}
+	}
+	if _, err := j.f.Seek(int64(valid), 0); err != nil {
+		j.f.Close()
+		return nil, err
+	}
+	if valid > 0 && raw[valid-1] != '\n' {
+		if _, err := j.f.Write([]byte{'\n'}); err != nil {
+			j.f.Close()
+			return nil, err
+		}
+	}
+	return j, nil
+}
+
+// Path 日志文件路径
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Get 返回一行的最新记录，没有记录时 Status 为 StatusPending
+func (j *Journal) Get(line int) Entry {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if e, ok := j.entries[line]; ok {
+		return e
+	}
+	return Entry{Line: line, Status: StatusPending}
+}
+
+// Record 追加一条记录并写入磁盘
+func (j *Journal) Record(e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	raw, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if _, err := j.f.Write(append(raw, '\n')); err != nil {
+		return fmt.Errorf("写入日志 %s 失败: %w", j.path, err)
+	}
+	if err := j.f.Sync(); err != nil {
+		return fmt.Errorf("写入日志 %s 失败: %w", j.path, err)
+	}
+	j.entries[e.Line] = e
+	return nil
+}
+
+// Close 关闭日志文件
+func (j *Journal) Close() error {
+	return j.f.Close()
+}
@@ -0,0 +1,122 @@
+package payout
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/output"
+)
+
+// ReportRow 对账报告中的一行，也是 --format csv / jsonl 的一行
+type ReportRow struct {
+	Line    int            `json:"line"`
+	To      common.Address `json:"to"`
+	Asset   string         `json:"asset" table:"ASSET"`   // ETH 或代币地址
+	Symbol  string         `json:"symbol" table:"SYMBOL"` // ETH 或代币符号
+	Amount  string         `json:"amount"`                // CSV 中的数量
+	Value   *big.Int       `json:"value"`                 // 最小单位
+	Status  Status         `json:"status"`
+	Nonce   *uint64        `json:"nonce"`
+	TxHash  *common.Hash   `json:"txHash"`
+	Block   uint64         `json:"block"`
+	GasUsed uint64         `json:"gasUsed"`
+	Fee     *big.Int       `json:"fee"` // wei
+	Error   string         `json:"error"`
+}
+
+// Report 按 CSV 的顺序列出每一行的最终状态
+func Report(plan *Plan, j *Journal) []ReportRow {
+	rows := make([]ReportRow, len(plan.Payments))
+	for i, p := range plan.Payments {
+		e := j.Get(p.Line)
+		rows[i] = ReportRow{
+			Line:    p.Line,
+			To:      p.To,
+			Asset:   p.Asset.ID(),
+			Symbol:  p.Asset.Symbol,
+			Amount:  p.Amount,
+			Value:   p.Value,
+			Status:  e.Status,
+			TxHash:  e.TxHash,
+			Block:   e.Block,
+			GasUsed: e.GasUsed,
+			Fee:     e.Fee,
+			Error:   e.Error,
+		}
+		if e.TxHash != nil {
+			nonce := e.Nonce
+			rows[i].Nonce = &nonce
+		}
+	}
+	return rows
+}
+
+// WriteReport 按格式输出对账报告
+func WriteReport(w io.Writer, format output.Format, rows []ReportRow) error {
+	if format == output.FormatJSON {
+		return output.WriteJSON(w, rows)
+	}
+	ow := output.NewWriter(w, format)
+	for _, r := range rows {
+		if err := ow.Write(r); err != nil {
+			return err
+		}
+	}
+	return ow.Flush()
+}
+
+// Summary 报告的汇总：各状态的行数、各资产已付总额和 Gas 费用
+type Summary struct {
+	Counts map[Status]int
+	Paid   map[string]*big.Int // 按资产标识（ETH 或代币地址）汇总的已确认付款
+	Fees   *big.Int            // 所有已打包交易的 Gas 费用，包括执行失败的
+}
+
+// Summarize 汇总对账报告
+func Summarize(rows []ReportRow) *Summary {
+	s := &Summary{Counts: make(map[Status]int), Paid: make(map[string]*big.Int), Fees: new(big.Int)}
+	for _, r := range rows {
+		s.Counts[r.Status]++
+		if r.Fee != nil {
+			s.Fees.Add(s.Fees, r.Fee)
+		}
+		if r.Status != StatusConfirmed {
+			continue
+		}
+		if s.Paid[r.Asset] == nil {
+			s.Paid[r.Asset] = new(big.Int)
+		}
+		s.Paid[r.Asset].Add(s.Paid[r.Asset], r.Value)
+	}
+	return s
+}
+
+// Done 是否所有行都已确认付款
+func (s *Summary) Done() bool {
+	total := 0
+	for _, n := range s.Counts {
+		total += n
+	}
+	return s.Counts[StatusConfirmed] == total
+}
+
+// Write 以文本输出汇总，资产按 plan.Assets 的顺序
+func (s *Summary) Write(w io.Writer, plan *Plan) {
+	fmt.Fprintf(w, "行数: %d 已确认", s.Counts[StatusConfirmed])
+	for _, status := range []Status{StatusFailed, StatusSent, StatusSigned, StatusDropped, StatusError, StatusPending} {
+		if n := s.Counts[status]; n > 0 {
+			fmt.Fprintf(w, "，%d %s", n, status)
+		}
+	}
+	fmt.Fprintln(w)
+	for _, asset := range plan.Assets {
+		if paid := s.Paid[asset.ID()]; paid != nil {
+			fmt.Fprintf(w, "已付 %s\n", asset.Format(paid))
+		}
+	}
+	fmt.Fprintf(w, "Gas 费用: %s ETH\n", chain.FormatEther(s.Fees))
+}
@@ -0,0 +1,309 @@
+package payout
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+)
+
+// DefaultConcurrency 默认最多同时等待确认的交易数
+const DefaultConcurrency = 4
+
+var (
+	// ErrPlanChanged 日志中某一行的付款内容与 CSV 不一致，通常是 CSV 在两次运行之间被修改过
+	ErrPlanChanged = errors.New("付款清单与日志不一致")
+	// ErrNeedConfirmation 日志中交易的 nonce 已被使用，但查不到这笔交易的收据。
+	// 可能是别的交易占用了 nonce（没有付款），也可能是节点没有这笔交易的索引（已经付款），
+	// 需要操作员在区块浏览器上核实后，把确认没有付款的行加入 Options.ConfirmDropped
+	ErrNeedConfirmation = errors.New("需要人工确认")
+)
+
+// Options Run 的配置，零值字段使用默认值
+type Options struct {
+	Concurrency  int                       // 最多同时等待确认的交易数，默认 DefaultConcurrency
+	PollInterval time.Duration             // 查询收据的间隔，默认 chain.DefaultPollInterval
+	GasMargin    uint64                    // 估算 Gas 的余量百分比，默认 chain.DefaultGasMargin
+	OnUpdate     func(p *Payment, e Entry) // 每写入一条日志后调用，用于显示进度；不会并发调用
+
+	// Verifier 另一个节点（最好是另一家服务商的），日志中交易的 nonce 已被使用时再用它查一次收据。
+	// 只保留最近交易索引的节点查不到较早的交易，仅凭一个节点判断容易把已付款的行当作没有付款
+	Verifier chain.ReceiptReader
+	// ConfirmDropped 操作员核实过没有付款的行号。nonce 已被使用且查不到收据时，
+	// 只有列在这里的行才标记为 dropped 重新发送，其余的行让 Run 返回 ErrNeedConfirmation
+	ConfirmDropped []int
+}
+
+// Run 执行付款，分三步：
+//
+//  1. 日志中已签名但未确认的行：原样重新广播（已在交易池或已打包时不重复发送），等待确认。
+//     nonce 已被使用时先在 b 和 Verifier 上查收据，查到就按收据记录结果；
+//     查不到时停在这一行返回 ErrNeedConfirmation，操作员确认后（ConfirmDropped）才标记为 dropped 重新发送
+//  2. 对剩余的行调用 Check，余额不足时不发送任何交易
+//  3. 从 pending nonce 开始按 CSV 顺序逐笔签名、写入日志、广播，最多 Concurrency 笔同时等待确认。
+//     某一笔广播失败时停止发送新交易，否则后面的 nonce 不连续，交易只会卡在交易池
+//
+// 签名前出错的行（如估算 Gas 失败）标记为 error 并跳过，不占用 nonce。
+// ctx 取消后已广播的交易在日志中保持 sent，重新运行时继续等待确认
+func Run(ctx context.Context, b Backend, key *ecdsa.PrivateKey, plan *Plan, j *Journal, opts *Options) error {
+	r := &runner{b: b, key: key, plan: plan, j: j}
+	if opts != nil {
+		r.opts = *opts
+	}
+	if r.opts.Concurrency <= 0 {
+		r.opts.Concurrency = DefaultConcurrency
+	}
+	if from := crypto.PubkeyToAddress(key.PublicKey); from != plan.From {
+		return fmt.Errorf("私钥对应的地址 %s 不是付款账户 %s", from.Hex(), plan.From.Hex())
+	}
+	for _, p := range plan.Payments {
+		if e := j.Get(p.Line); e.Status != StatusPending && e.Key != p.Key() {
+			return fmt.Errorf("%w: 第 %d 行现在是 %s，日志中是 %s", ErrPlanChanged, p.Line, p.Key(), e.Key)
+		}
+	}
+
+	if err := r.resume(ctx); err != nil {
+		return err
+	}
+	if _, err := Check(ctx, b, plan, j, r.opts.GasMargin); err != nil {
+		return err
+	}
+	return r.send(ctx)
+}
+
+type runner struct {
+	b    Backend
+	key  *ecdsa.PrivateKey
+	plan *Plan
+	j    *Journal
+	opts Options
+
+	wg       sync.WaitGroup
+	updateMu sync.Mutex
+	errMu    sync.Mutex
+	err      error
+}
+
+// resume 重新广播日志中已签名但未确认的交易，并等待它们全部确认
+func (r *runner) resume(ctx context.Context) error {
+	var pending []*Payment
+	for _, p := range r.plan.Payments {
+		if r.j.Get(p.Line).Status.InFlight() {
+			pending = append(pending, p)
+		}
+	}
+	sort.Slice(pending, func(a, b int) bool { return r.j.Get(pending[a].Line).Nonce < r.j.Get(pending[b].Line).Nonce })
+
+	for _, p := range pending {
+		e := r.j.Get(p.Line)
+		tx := new(types.Transaction)
+		if err := tx.UnmarshalBinary(e.RawTx); err != nil {
+			return fmt.Errorf("第 %d 行: 日志中的交易无法解码: %w", p.Line, err)
+		}
+		if _, err := chain.Broadcast(ctx, r.b, r.plan.From, tx); err != nil {
+			if err := r.nonceUsed(ctx, p, e, err); err != nil {
+				r.wg.Wait()
+				return err
+			}
+			continue
+		}
+		if e.Status == StatusSigned {
+			e.Status, e.Error = StatusSent, ""
+			if err := r.record(p, e); err != nil {
+				r.wg.Wait()
+				return err
+			}
+		}
+		r.wg.Add(1)
+		go r.wait(ctx, p, e, nil)
+	}
+	r.wg.Wait()
+	return r.result(ctx)
+}
+
+// nonceUsed 处理重新广播失败的行。只有 nonce 已被使用时才可能继续：
+// 任一节点查到收据就记录结果；都查不到时，操作员确认过的行标记为 dropped，否则返回 ErrNeedConfirmation
+func (r *runner) nonceUsed(ctx context.Context, p *Payment, e Entry, broadcastErr error) error {
+	if !errors.Is(broadcastErr, chain.ErrNonceUsed) {
+		return fmt.Errorf("第 %d 行: %w", p.Line, broadcastErr)
+	}
+	readers := []chain.ReceiptReader{r.b}
+	if r.opts.Verifier != nil {
+		readers = append(readers, r.opts.Verifier)
+	}
+	for _, reader := range readers {
+		receipt, err := chain.GetReceipt(ctx, reader, *e.TxHash)
+		if err == nil {
+			return r.settle(p, e, receipt)
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			return fmt.Errorf("第 %d 行: %w", p.Line, err)
+		}
+	}
+	if !slices.Contains(r.opts.ConfirmDropped, p.Line) {
+		return fmt.Errorf("%w: 第 %d 行的交易 %s 查不到收据，但 nonce %d 已被使用。"+
+			"在区块浏览器上确认这一行没有付款后，把行号加入 ConfirmDropped 重新运行",
+			ErrNeedConfirmation, p.Line, e.TxHash.Hex(), e.Nonce)
+	}
+	e.Status, e.Error = StatusDropped, broadcastErr.Error()
+	return r.record(p, e)
+}
+
+// send 按顺序发送剩余的付款。sem 限制同时等待确认的交易数
+func (r *runner) send(ctx context.Context) error {
+	nonce, err := r.b.PendingNonceAt(ctx, r.plan.From)
+	if err != nil {
+		return fmt.Errorf("获取 nonce 失败: %w", err)
+	}
+	sem := make(chan struct{}, r.opts.Concurrency)
+	release := func() { <-sem }
+
+	for _, p := range r.plan.Payments {
+		if !r.j.Get(p.Line).Status.Retry() {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil || r.failed() {
+			break
+		}
+
+		tx, err := r.sign(ctx, p, nonce)
+		if err != nil {
+			release()
+			if ctx.Err() != nil {
+				break
+			}
+			if err := r.record(p, Entry{Status: StatusError, Error: err.Error()}); err != nil {
+				r.fail(err)
+				break
+			}
+			continue
+		}
+
+		// 先写日志再广播：广播后崩溃，重新运行时日志里有这笔交易，不会用新的 nonce 再付一次
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			release()
+			r.fail(err)
+			break
+		}
+		hash := tx.Hash()
+		e := Entry{Status: StatusSigned, Nonce: nonce, TxHash: &hash, RawTx: raw}
+		if err := r.record(p, e); err != nil {
+			release()
+			r.fail(err)
+			break
+		}
+		if err := r.b.SendTransaction(ctx, tx); err != nil {
+			// 交易可能已经到达节点，保持 signed 状态，重新运行时由 chain.Broadcast 判断
+			release()
+			e.Error = err.Error()
+			if recordErr := r.record(p, e); recordErr != nil {
+				err = recordErr
+			}
+			r.fail(fmt.Errorf("第 %d 行: 发送交易 %s 失败: %w", p.Line, hash.Hex(), err))
+			break
+		}
+		e.Status = StatusSent
+		if err := r.record(p, e); err != nil {
+			release()
+			r.fail(err)
+			break
+		}
+		nonce++
+
+		r.wg.Add(1)
+		go r.wait(ctx, p, e, release)
+	}
+	r.wg.Wait()
+	return r.result(ctx)
+}
+
+// sign 构造并签名一笔付款，nonce 由调用方分配
+func (r *runner) sign(ctx context.Context, p *Payment, nonce uint64) (*types.Transaction, error) {
+	req := &chain.TxRequest{To: p.target(), Value: p.ethValue(), Data: p.Data(), GasMargin: r.opts.GasMargin, Nonce: &nonce}
+	tx, err := chain.BuildTx(ctx, r.b, r.plan.From, r.plan.ChainID, req)
+	if err != nil {
+		return nil, err
+	}
+	return types.SignTx(tx, types.LatestSignerForChainID(r.plan.ChainID), r.key)
+}
+
+// wait 等待交易确认并记录结果。ctx 取消时不写日志，这一行保持 sent
+func (r *runner) wait(ctx context.Context, p *Payment, e Entry, done func()) {
+	defer r.wg.Done()
+	if done != nil {
+		defer done()
+	}
+	receipt, err := chain.WaitReceipt(ctx, r.b, *e.TxHash, r.opts.PollInterval)
+	if err != nil {
+		if ctx.Err() == nil {
+			r.fail(fmt.Errorf("第 %d 行: %w", p.Line, err))
+		}
+		return
+	}
+	if err := r.settle(p, e, receipt); err != nil {
+		r.fail(err)
+	}
+}
+
+// settle 按收据记录这一行的最终状态
+func (r *runner) settle(p *Payment, e Entry, receipt *chain.Receipt) error {
+	e.Status, e.Error = StatusConfirmed, ""
+	if !receipt.Success() {
+		e.Status, e.Error = StatusFailed, "交易执行失败"
+	}
+	e.Block, e.GasUsed, e.Fee = receipt.BlockNumber, receipt.GasUsed, receipt.Fee()
+	return r.record(p, e)
+}
+
+// record 写入日志并通知 OnUpdate
+func (r *runner) record(p *Payment, e Entry) error {
+	e.Line, e.Key, e.Time = p.Line, p.Key(), time.Time{}
+	if err := r.j.Record(e); err != nil {
+		return err
+	}
+	if r.opts.OnUpdate != nil {
+		r.updateMu.Lock()
+		r.opts.OnUpdate(p, r.j.Get(p.Line))
+		r.updateMu.Unlock()
+	}
+	return nil
+}
+
+func (r *runner) fail(err error) {
+	r.errMu.Lock()
+	defer r.errMu.Unlock()
+	if r.err == nil {
+		r.err = err
+	}
+}
+
+func (r *runner) failed() bool {
+	r.errMu.Lock()
+	defer r.errMu.Unlock()
+	return r.err != nil
+}
+
+// result 第一个错误；没有错误但 ctx 已取消时返回 ctx 的错误
+func (r *runner) result(ctx context.Context) error {
+	r.errMu.Lock()
+	defer r.errMu.Unlock()
+	if r.err != nil {
+		return r.err
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,312 @@
+package payout_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/dapp-learning/ethclient/util/payout"
+	"github.com/dapp-learning/ethclient/util/simnet"
+)
+
+var (
+	alice = common.HexToAddress("0x71C7656EC7ab88b098defB751B7401B5f6d8976F")
+	bob   = common.HexToAddress("0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359")
+	carol = common.HexToAddress("0x3E5e9111Ae8eB78Fe1CC3bb8915d5D461F3Ef9A9")
+
+	errCrash = errors.New("进程崩溃")
+)
+
+const payouts = `address,amount
+0x71C7656EC7ab88b098defB751B7401B5f6d8976F,1.5
+0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359,2
+`
+
+// node 模拟链客户端，发送交易后立即出块，并能模拟发送时崩溃和节点缺少旧交易的索引
+type node struct {
+	simnet.Client
+	net *simnet.Net
+
+	crash   bool                 // SendTransaction 返回 errCrash
+	forward bool                 // 崩溃前交易已经到达节点
+	pruned  map[common.Hash]bool // 查不到这些交易和收据
+}
+
+func (n *node) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if !n.crash || n.forward {
+		if err := n.Client.SendTransaction(ctx, tx); err != nil {
+			return err
+		}
+		n.net.Commit()
+	}
+	if n.crash {
+		return errCrash
+	}
+	return nil
+}
+
+func (n *node) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	if n.pruned[hash] {
+		return nil, false, ethereum.NotFound
+	}
+	return n.Client.TransactionByHash(ctx, hash)
+}
+
+func (n *node) TransactionReceipt(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
+	if n.pruned[hash] {
+		return nil, ethereum.NotFound
+	}
+	return n.Client.TransactionReceipt(ctx, hash)
+}
+
+type fixture struct {
+	t       *testing.T
+	net     *simnet.Net
+	node    *node
+	journal string
+	before  map[common.Address]*big.Int
+}
+
+func newFixture(t *testing.T) *fixture {
+	t.Helper()
+	net, err := simnet.New(&simnet.Options{SkipContracts: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { net.Close() })
+	f := &fixture{
+		t:       t,
+		net:     net,
+		node:    &node{Client: net.Client, net: net},
+		journal: filepath.Join(t.TempDir(), "payouts.journal"),
+		before:  make(map[common.Address]*big.Int),
+	}
+	// 只有创世区块的链不会建立交易索引，按哈希查询会返回 "transaction indexing is in progress"。
+	// 真实网络总有区块，这里先出一个空块，等索引就绪
+	net.Commit()
+	for {
+		_, _, err := net.Client.TransactionByHash(context.Background(), common.Hash{})
+		if errors.Is(err, ethereum.NotFound) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	for _, addr := range []common.Address{alice, bob, carol} {
+		f.before[addr] = f.balance(addr)
+	}
+	return f
+}
+
+// run 相当于重新启动一次出款程序：重新读取 CSV 和日志，执行 Run
+func (f *fixture) run(csv string, opts *payout.Options) (*payout.Plan, *payout.Journal, error) {
+	f.t.Helper()
+	ctx := context.Background()
+	rows, err := payout.ReadCSV(strings.NewReader(csv))
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	plan, err := payout.NewPlan(ctx, f.node, f.net.Accounts[0].Address, rows)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	j, err := payout.OpenJournal(f.journal)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	f.t.Cleanup(func() { j.Close() })
+	if opts == nil {
+		opts = new(payout.Options)
+	}
+	opts.PollInterval = 10 * time.Millisecond
+	err = payout.Run(ctx, f.node, f.net.Accounts[0].Key, plan, j, opts)
+	return plan, j, err
+}
+
+func (f *fixture) balance(addr common.Address) *big.Int {
+	f.t.Helper()
+	b, err := f.net.Client.BalanceAt(context.Background(), addr, nil)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return b
+}
+
+// received 检查 addr 在测试期间一共收到 want wei
+func (f *fixture) received(addr common.Address, want *big.Int) {
+	f.t.Helper()
+	if got := new(big.Int).Sub(f.balance(addr), f.before[addr]); got.Cmp(want) != 0 {
+		f.t.Errorf("%s 收到 %s wei，期望 %s", addr.Hex(), got, want)
+	}
+}
+
+func (f *fixture) nonce() uint64 {
+	f.t.Helper()
+	n, err := f.net.Client.NonceAt(context.Background(), f.net.Accounts[0].Address, nil)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	return n
+}
+
+func ether(s string) *big.Int {
+	v, ok := new(big.Rat).SetString(s)
+	if !ok {
+		panic(s)
+	}
+	v.Mul(v, new(big.Rat).SetInt64(1e18))
+	return new(big.Int).Quo(v.Num(), v.Denom())
+}
+
+func expectStatus(t *testing.T, j *payout.Journal, line int, want payout.Status) payout.Entry {
+	t.Helper()
+	e := j.Get(line)
+	if e.Status != want {
+		t.Fatalf("第 %d 行的状态为 %s（%s），期望 %s", line, e.Status, e.Error, want)
+	}
+	return e
+}
+
+// crash 第一笔交易签名并写入日志后崩溃，forward 表示交易是否已经到达节点
+func (f *fixture) crash(forward bool) payout.Entry {
+	f.t.Helper()
+	f.node.crash, f.node.forward = true, forward
+	_, j, err := f.run(payouts, nil)
+	if !errors.Is(err, errCrash) {
+		f.t.Fatalf("Run 返回 %v，期望 %v", err, errCrash)
+	}
+	e := expectStatus(f.t, j, 2, payout.StatusSigned)
+	expectStatus(f.t, j, 3, payout.StatusPending)
+	j.Close()
+	f.node.crash, f.node.forward = false, false
+	return e
+}
+
+func TestResumeAfterCrashBeforeBroadcast(t *testing.T) {
+	f := newFixture(t)
+	signed := f.crash(false)
+	if f.nonce() != 0 {
+		t.Fatal("崩溃前的交易不应上链")
+	}
+
+	_, j, err := f.run(payouts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := expectStatus(t, j, 2, payout.StatusConfirmed); *e.TxHash != *signed.TxHash || e.Nonce != signed.Nonce {
+		t.Fatalf("第 2 行应重新广播日志中的交易 %s，实际为 %s", signed.TxHash.Hex(), e.TxHash.Hex())
+	}
+	expectStatus(t, j, 3, payout.StatusConfirmed)
+	f.received(alice, ether("1.5"))
+	f.received(bob, ether("2"))
+	if n := f.nonce(); n != 2 {
+		t.Fatalf("一共发送了 %d 笔交易，期望 2", n)
+	}
+}
+
+func TestResumeAfterBroadcast(t *testing.T) {
+	f := newFixture(t)
+	signed := f.crash(true)
+	f.received(alice, ether("1.5"))
+
+	_, j, err := f.run(payouts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := expectStatus(t, j, 2, payout.StatusConfirmed); *e.TxHash != *signed.TxHash || e.Block == 0 {
+		t.Fatalf("第 2 行应记录已上链的交易 %s，实际为 %s（区块 %d）", signed.TxHash.Hex(), e.TxHash.Hex(), e.Block)
+	}
+	expectStatus(t, j, 3, payout.StatusConfirmed)
+	f.received(alice, ether("1.5"))
+	f.received(bob, ether("2"))
+	if n := f.nonce(); n != 2 {
+		t.Fatalf("一共发送了 %d 笔交易，期望 2", n)
+	}
+}
+
+func TestResumeFindsReceiptOnVerifier(t *testing.T) {
+	f := newFixture(t)
+	signed := f.crash(true)
+	// 节点只保留最近的交易索引，查不到已经上链的交易
+	f.node.pruned = map[common.Hash]bool{*signed.TxHash: true}
+
+	_, j, err := f.run(payouts, nil)
+	if !errors.Is(err, payout.ErrNeedConfirmation) {
+		t.Fatalf("没有 Verifier 时 Run 返回 %v，期望 %v", err, payout.ErrNeedConfirmation)
+	}
+	expectStatus(t, j, 2, payout.StatusSigned)
+	expectStatus(t, j, 3, payout.StatusPending)
+	j.Close()
+
+	_, j, err = f.run(payouts, &payout.Options{Verifier: f.net.Client})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := expectStatus(t, j, 2, payout.StatusConfirmed); *e.TxHash != *signed.TxHash {
+		t.Fatalf("第 2 行应记录 Verifier 查到的交易 %s，实际为 %s", signed.TxHash.Hex(), e.TxHash.Hex())
+	}
+	expectStatus(t, j, 3, payout.StatusConfirmed)
+	f.received(alice, ether("1.5"))
+	f.received(bob, ether("2"))
+}
+
+func TestResumeNonceUsedNeedsConfirmation(t *testing.T) {
+	f := newFixture(t)
+	signed := f.crash(false)
+	// 日志中交易的 nonce 被另一笔交易占用，这一行实际没有付款
+	if _, err := f.net.Transfer(context.Background(), f.net.Accounts[0], carol, ether("0.1")); err != nil {
+		t.Fatal(err)
+	}
+
+	_, j, err := f.run(payouts, &payout.Options{Verifier: f.net.Client, ConfirmDropped: []int{3}})
+	if !errors.Is(err, payout.ErrNeedConfirmation) {
+		t.Fatalf("Run 返回 %v，期望 %v", err, payout.ErrNeedConfirmation)
+	}
+	expectStatus(t, j, 2, payout.StatusSigned)
+	expectStatus(t, j, 3, payout.StatusPending)
+	f.received(alice, new(big.Int))
+	j.Close()
+
+	_, j, err = f.run(payouts, &payout.Options{Verifier: f.net.Client, ConfirmDropped: []int{2}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := expectStatus(t, j, 2, payout.StatusConfirmed); *e.TxHash == *signed.TxHash {
+		t.Fatal("确认 dropped 后应使用新的 nonce 重新发送")
+	}
+	expectStatus(t, j, 3, payout.StatusConfirmed)
+	f.received(alice, ether("1.5"))
+	f.received(bob, ether("2"))
+	f.received(carol, ether("0.1"))
+}
+
+func TestResumePlanChanged(t *testing.T) {
+	f := newFixture(t)
+	f.crash(false)
+
+	changed := strings.Replace(payouts, ",1.5", ",15", 1)
+	_, j, err := f.run(changed, nil)
+	if !errors.Is(err, payout.ErrPlanChanged) {
+		t.Fatalf("Run 返回 %v，期望 %v", err, payout.ErrPlanChanged)
+	}
+	expectStatus(t, j, 2, payout.StatusSigned)
+	expectStatus(t, j, 3, payout.StatusPending)
+	if f.nonce() != 0 {
+		t.Fatal("清单与日志不一致时不应发送任何交易")
+	}
+	j.Close()
+
+	// 改回原来的清单后可以继续
+	if _, _, err := f.run(payouts, nil); err != nil {
+		t.Fatal(err)
+	}
+	f.received(alice, ether("1.5"))
+	f.received(bob, ether("2"))
+}
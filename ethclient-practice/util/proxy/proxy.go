@@ -0,0 +1,235 @@
+// Package proxy 识别可升级代理合约并解析出实际的实现合约。
+//
+// 支持的代理类型：
+//   - EIP-1967 存储槽代理：Transparent、UUPS、Beacon
+//   - EIP-1167 最小代理（clone），实现地址直接写在字节码里
+//
+// 读取代理合约时应当使用代理地址 + 实现合约的 ABI。
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Kind 代理类型
+type Kind string
+
+const (
+	KindNone        Kind = "none"        // 不是代理
+	KindMinimal     Kind = "eip1167"     // EIP-1167 最小代理
+	KindTransparent Kind = "transparent" // EIP-1967 Transparent 代理（admin 槽非空）
+	KindUUPS        Kind = "uups"        // EIP-1967 UUPS 代理（升级逻辑在实现合约中）
+	KindBeacon      Kind = "beacon"      // EIP-1967 Beacon 代理（实现地址由 beacon 合约提供）
+	KindEIP1967     Kind = "eip1967"     // 只有实现槽，无法进一步区分
+)
+
+// maxDepth 解析代理链的最大深度（例如最小代理指向一个 Beacon 代理）
+const maxDepth = 5
+
+// EIP-1967 存储槽：keccak256(name) - 1，减一使其没有已知的哈希原像
+var (
+	ImplementationSlot = eip1967Slot("eip1967.proxy.implementation")
+	AdminSlot          = eip1967Slot("eip1967.proxy.admin")
+	BeaconSlot         = eip1967Slot("eip1967.proxy.beacon")
+)
+
+// EIP-1167 最小代理的运行时代码：前缀 ++ 20 字节实现地址 ++ 后缀
+var (
+	minimalPrefix = common.FromHex("0x363d3d373d3d3d363d73")
+	minimalSuffix = common.FromHex("0x5af43d82803e903d91602b57fd5bf3")
+)
+
+// Backend 识别代理需要的节点接口
+type Backend interface {
+	ethereum.ChainStateReader
+	ethereum.ContractCaller
+}
+
+// Info 一个地址的代理信息
+type Info struct {
+	Address        common.Address `json:"address"`
+	Kind           Kind           `json:"kind"`
+	Implementation common.Address `json:"implementation,omitempty"`
+	Admin          common.Address `json:"admin,omitempty"`  // Transparent 代理的管理员（通常是 ProxyAdmin 合约）
+	Beacon         common.Address `json:"beacon,omitempty"` // Beacon 代理的 beacon 合约
+}
+
+// IsProxy 是否为代理
+func (i *Info) IsProxy() bool {
+	return i.Kind != KindNone
+}
+
+func (i *Info) String() string {
+	if !i.IsProxy() {
+		return fmt.Sprintf("%s（不是代理）", i.Address.Hex())
+	}
+	s := fmt.Sprintf("%s [%s] → %s", i.Address.Hex(), i.Kind, i.Implementation.Hex())
+	if i.Admin != (common.Address{}) {
+		s += fmt.Sprintf("，admin %s", i.Admin.Hex())
+	}
+	if i.Beacon != (common.Address{}) {
+		s += fmt.Sprintf("，beacon %s", i.Beacon.Hex())
+	}
+	return s
+}
+
+// Inspect 检查地址是否为代理。blockNumber 为 nil 时读取最新状态
+func Inspect(ctx context.Context, backend Backend, addr common.Address, blockNumber *big.Int) (*Info, error) {
+	info := &Info{Address: addr, Kind: KindNone}
+
+	code, err := backend.CodeAt(ctx, addr, blockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 代码失败: %w", addr.Hex(), err)
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("地址 %s 上没有合约代码", addr.Hex())
+	}
+	if impl, ok := MinimalProxyTarget(code); ok {
+		info.Kind, info.Implementation = KindMinimal, impl
+		return info, nil
+	}
+
+	readSlot := func(slot common.Hash) (common.Address, error) {
+		value, err := backend.StorageAt(ctx, addr, slot, blockNumber)
+		if err != nil {
+			return common.Address{}, fmt.Errorf("读取存储槽 %s 失败: %w", slot.Hex(), err)
+		}
+		return common.BytesToAddress(value), nil
+	}
+
+	if info.Beacon, err = readSlot(BeaconSlot); err != nil {
+		return nil, err
+	}
+	if info.Beacon != (common.Address{}) {
+		info.Kind = KindBeacon
+		if info.Implementation, err = callAddress(ctx, backend, info.Beacon, "implementation", blockNumber); err != nil {
+			return nil, fmt.Errorf("从 beacon %s 读取实现地址失败: %w", info.Beacon.Hex(), err)
+		}
+		return info, nil
+	}
+
+	if info.Implementation, err = readSlot(ImplementationSlot); err != nil {
+		return nil, err
+	}
+	if info.Implementation == (common.Address{}) {
+		return info, nil
+	}
+	if info.Admin, err = readSlot(AdminSlot); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case info.Admin != (common.Address{}):
+		info.Kind = KindTransparent
+	case isUUPS(ctx, backend, info.Implementation, blockNumber):
+		info.Kind = KindUUPS
+	default:
+		info.Kind = KindEIP1967
+	}
+	return info, nil
+}
+
+// Resolve 沿代理链一直解析到最终的实现合约，返回实现地址和经过的每一层代理。
+// 不是代理时返回地址本身和空列表
+func Resolve(ctx context.Context, backend Backend, addr common.Address) (common.Address, []*Info, error) {
+	var chain []*Info
+	current := addr
+	for depth := 0; depth < maxDepth; depth++ {
+		info, err := Inspect(ctx, backend, current, nil)
+		if err != nil {
+			return common.Address{}, chain, err
+		}
+		if !info.IsProxy() {
+			return current, chain, nil
+		}
+		chain = append(chain, info)
+		current = info.Implementation
+	}
+	return common.Address{}, chain, fmt.Errorf("代理链超过 %d 层，可能存在循环", maxDepth)
+}
+
+// MinimalProxyTarget 判断运行时代码是否为 EIP-1167 最小代理，是则返回实现地址
+func MinimalProxyTarget(code []byte) (common.Address, bool) {
+	if len(code) != len(minimalPrefix)+common.AddressLength+len(minimalSuffix) {
+		return common.Address{}, false
+	}
+	if !bytes.HasPrefix(code, minimalPrefix) || !bytes.HasSuffix(code, minimalSuffix) {
+		return common.Address{}, false
+	}
+	return common.BytesToAddress(code[len(minimalPrefix) : len(minimalPrefix)+common.AddressLength]), true
+}
+
+// MinimalProxyCode 生成指向 impl 的 EIP-1167 运行时代码
+func MinimalProxyCode(impl common.Address) []byte {
+	code := make([]byte, 0, len(minimalPrefix)+common.AddressLength+len(minimalSuffix))
+	code = append(code, minimalPrefix...)
+	code = append(code, impl.Bytes()...)
+	return append(code, minimalSuffix...)
+}
+
+// isUUPS UUPS 实现合约（ERC-1822）的 proxiableUUID() 返回实现槽
+func isUUPS(ctx context.Context, backend Backend, impl common.Address, blockNumber *big.Int) bool {
+	out, err := call(ctx, backend, impl, "proxiableUUID", blockNumber)
+	if err != nil || len(out) != 1 {
+		return false
+	}
+	uuid, ok := out[0].([32]byte)
+	return ok && common.Hash(uuid) == ImplementationSlot
+}
+
+func callAddress(ctx context.Context, backend Backend, contract common.Address, method string, blockNumber *big.Int) (common.Address, error) {
+	out, err := call(ctx, backend, contract, method, blockNumber)
+	if err != nil {
+		return common.Address{}, err
+	}
+	addr, ok := out[0].(common.Address)
+	if !ok {
+		return common.Address{}, fmt.Errorf("%s() 返回值不是地址", method)
+	}
+	return addr, nil
+}
+
+func call(ctx context.Context, backend Backend, contract common.Address, method string, blockNumber *big.Int) ([]interface{}, error) {
+	data, err := proxyABI.Pack(method)
+	if err != nil {
+		return nil, err
+	}
+	out, err := backend.CallContract(ctx, ethereum.CallMsg{To: &contract, Data: data}, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	return proxyABI.Unpack(method, out)
+}
+
+func eip1967Slot(name string) common.Hash {
+	slot := new(big.Int).SetBytes(crypto.Keccak256([]byte(name)))
+	return common.BigToHash(slot.Sub(slot, big.NewInt(1)))
+}
+
+// proxyABI 代理、beacon、ProxyAdmin 中用到的方法
+var proxyABI = mustParseABI(`[
+	{"type":"function","name":"implementation","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"address"}]},
+	{"type":"function","name":"proxiableUUID","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"bytes32"}]},
+	{"type":"function","name":"UPGRADE_INTERFACE_VERSION","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"string"}]},
+	{"type":"function","name":"upgradeTo","stateMutability":"nonpayable","inputs":[{"name":"newImplementation","type":"address"}],"outputs":[]},
+	{"type":"function","name":"upgradeToAndCall","stateMutability":"payable","inputs":[{"name":"newImplementation","type":"address"},{"name":"data","type":"bytes"}],"outputs":[]},
+	{"type":"function","name":"upgrade","stateMutability":"nonpayable","inputs":[{"name":"proxy","type":"address"},{"name":"implementation","type":"address"}],"outputs":[]},
+	{"type":"function","name":"upgradeAndCall","stateMutability":"payable","inputs":[{"name":"proxy","type":"address"},{"name":"implementation","type":"address"},{"name":"data","type":"bytes"}],"outputs":[]}
+]`)
+
+func mustParseABI(s string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
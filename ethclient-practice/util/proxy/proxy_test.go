@@ -0,0 +1,233 @@
+package proxy_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/dapp-learning/ethclient/util/proxy"
+	"github.com/dapp-learning/ethclient/util/simnet"
+)
+
+// 预置在创世状态中的合约
+var (
+	implA       = common.HexToAddress("0x00000000000000000000000000000000000A0001")
+	implB       = common.HexToAddress("0x00000000000000000000000000000000000A0002")
+	uupsImpl    = common.HexToAddress("0x00000000000000000000000000000000000B0001")
+	uupsImpl2   = common.HexToAddress("0x00000000000000000000000000000000000B0002")
+	beacon      = common.HexToAddress("0x00000000000000000000000000000000000C0001")
+	transparent = common.HexToAddress("0x00000000000000000000000000000000000D0001")
+	uups        = common.HexToAddress("0x00000000000000000000000000000000000D0002")
+	bare1967    = common.HexToAddress("0x00000000000000000000000000000000000D0003")
+	beaconProxy = common.HexToAddress("0x00000000000000000000000000000000000D0004")
+	clone       = common.HexToAddress("0x00000000000000000000000000000000000D0005")
+	cloneChain  = common.HexToAddress("0x00000000000000000000000000000000000D0006")
+	admin       = common.HexToAddress("0x00000000000000000000000000000000000E0001")
+
+	// 实现地址带前导零：EIP-1167 字节码仍然用 PUSH20 写入完整的 20 字节
+	vanityImpl = common.HexToAddress("0x0000000000000000000000000000000000001167")
+)
+
+// returnWord 对任何调用都返回 word 的运行时代码
+func returnWord(word common.Hash) []byte {
+	code := append([]byte{0x7f}, word.Bytes()...) // PUSH32 word
+	return append(code, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3)
+}
+
+// upgradeable 最简单的可升级合约：调用 selector(address) 时把参数写入 slot，
+// 其他调用返回 slot 中的值。没有权限检查，只用于测试
+func upgradeable(selector string, slot common.Hash) []byte {
+	sel := crypto.Keccak256([]byte(selector))[:4]
+	code := []byte{
+		0x60, 0x00, 0x35, 0x60, 0xe0, 0x1c, // calldataload(0) >> 224
+		0x63, sel[0], sel[1], sel[2], sel[3], 0x14, // == selector
+		0x60, 0x39, 0x57, // jumpi upgrade
+		0x7f, // PUSH32 slot
+	}
+	code = append(code, slot.Bytes()...)
+	code = append(code, 0x54, 0x60, 0x00, 0x52, 0x60, 0x20, 0x60, 0x00, 0xf3) // return sload(slot)
+	code = append(code, 0x5b, 0x60, 0x04, 0x35, 0x7f)                         // upgrade: calldataload(4)
+	code = append(code, slot.Bytes()...)
+	return append(code, 0x55, 0x00) // sstore(slot, ...) stop
+}
+
+func newNet(t *testing.T) *simnet.Net {
+	t.Helper()
+	word := func(a common.Address) common.Hash { return common.BytesToHash(a.Bytes()) }
+	proxyCode := upgradeable("upgradeToAndCall(address,bytes)", proxy.ImplementationSlot)
+	alloc := types.GenesisAlloc{
+		implA:      {Code: returnWord(common.HexToHash("0x2a"))},
+		implB:      {Code: returnWord(common.HexToHash("0x2b"))},
+		vanityImpl: {Code: returnWord(common.HexToHash("0x2c"))},
+		uupsImpl:   {Code: returnWord(proxy.ImplementationSlot)},
+		uupsImpl2:  {Code: returnWord(proxy.ImplementationSlot)},
+		beacon:     {Code: upgradeable("upgradeTo(address)", common.Hash{}), Storage: map[common.Hash]common.Hash{{}: word(implA)}},
+		transparent: {Code: proxyCode, Storage: map[common.Hash]common.Hash{
+			proxy.ImplementationSlot: word(implA),
+			proxy.AdminSlot:          word(admin),
+		}},
+		uups:        {Code: proxyCode, Storage: map[common.Hash]common.Hash{proxy.ImplementationSlot: word(uupsImpl)}},
+		bare1967:    {Code: proxyCode, Storage: map[common.Hash]common.Hash{proxy.ImplementationSlot: word(implA)}},
+		beaconProxy: {Code: returnWord(common.Hash{}), Storage: map[common.Hash]common.Hash{proxy.BeaconSlot: word(beacon)}},
+		clone:       {Code: proxy.MinimalProxyCode(vanityImpl)},
+		cloneChain:  {Code: proxy.MinimalProxyCode(beaconProxy)},
+	}
+	net, err := simnet.New(&simnet.Options{SkipContracts: true, Alloc: alloc})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { net.Close() })
+	return net
+}
+
+func TestInspect(t *testing.T) {
+	net := newNet(t)
+	tests := []struct {
+		name string
+		addr common.Address
+		want proxy.Info
+	}{
+		{"transparent", transparent, proxy.Info{Kind: proxy.KindTransparent, Implementation: implA, Admin: admin}},
+		{"uups", uups, proxy.Info{Kind: proxy.KindUUPS, Implementation: uupsImpl}},
+		{"eip1967", bare1967, proxy.Info{Kind: proxy.KindEIP1967, Implementation: implA}}, // 实现没有 proxiableUUID
+		{"beacon", beaconProxy, proxy.Info{Kind: proxy.KindBeacon, Implementation: implA, Beacon: beacon}},
+		{"eip1167", clone, proxy.Info{Kind: proxy.KindMinimal, Implementation: vanityImpl}},
+		{"not a proxy", implA, proxy.Info{Kind: proxy.KindNone}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := proxy.Inspect(context.Background(), net.Client, tt.addr, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.want.Address = tt.addr
+			if *info != tt.want {
+				t.Fatalf("Inspect = %s，期望 %s", info, &tt.want)
+			}
+		})
+	}
+
+	if _, err := proxy.Inspect(context.Background(), net.Client, admin, nil); err == nil {
+		t.Fatal("没有代码的地址应返回错误")
+	}
+}
+
+func TestResolve(t *testing.T) {
+	net := newNet(t)
+	// 最小代理 → Beacon 代理 → 实现
+	impl, layers, err := proxy.Resolve(context.Background(), net.Client, cloneChain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if impl != implA || len(layers) != 2 || layers[0].Kind != proxy.KindMinimal || layers[1].Kind != proxy.KindBeacon {
+		t.Fatalf("Resolve = %s，%v", impl.Hex(), layers)
+	}
+	if impl, layers, err = proxy.Resolve(context.Background(), net.Client, implB); err != nil || impl != implB || len(layers) != 0 {
+		t.Fatalf("非代理 Resolve = %s，%v，%v", impl.Hex(), layers, err)
+	}
+}
+
+func TestPlanUpgrade(t *testing.T) {
+	net := newNet(t)
+	from := net.Accounts[0].Address
+	tests := []struct {
+		name     string
+		proxy    common.Address
+		newImpl  common.Address
+		initData []byte
+		err      string // 为空时期望成功
+		target   common.Address
+		method   string
+	}{
+		{"uups", uups, uupsImpl2, nil, "", uups, "upgradeToAndCall"},
+		{"beacon", beaconProxy, implB, nil, "", beacon, "upgradeTo"},
+		{"eoa admin", transparent, implB, nil, "无权升级", common.Address{}, ""},
+		{"new impl not a contract", uups, from, nil, "没有合约代码", common.Address{}, ""},
+		{"same impl", beaconProxy, implA, nil, "已经是当前实现", common.Address{}, ""},
+		{"uups to non-uups", uups, implB, nil, "不是 UUPS 实现", common.Address{}, ""},
+		{"beacon with init", beaconProxy, implB, []byte{1}, "不支持初始化调用", common.Address{}, ""},
+		{"minimal", clone, implB, nil, "不可升级", common.Address{}, ""},
+		{"not a proxy", implA, implB, nil, "不是代理合约", common.Address{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			plan, err := proxy.PlanUpgrade(context.Background(), net.Client, from, tt.proxy, tt.newImpl, tt.initData)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("PlanUpgrade 返回 %v，期望包含 %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if plan.Target != tt.target || plan.Method != tt.method || plan.NewImplementation != tt.newImpl {
+				t.Fatalf("升级方案 %+v", plan)
+			}
+		})
+	}
+}
+
+// backend 发送交易后立即出块，bind.WaitMined 才能等到收据
+type backend struct {
+	simnet.Client
+	net *simnet.Net
+}
+
+func (b *backend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	if err := b.Client.SendTransaction(ctx, tx); err != nil {
+		return err
+	}
+	b.net.Commit()
+	return nil
+}
+
+func TestUpgrade(t *testing.T) {
+	net := newNet(t)
+	ctx := context.Background()
+	b := &backend{Client: net.Client, net: net}
+	opts := net.Transactor(net.Accounts[0])
+
+	tests := []struct {
+		name    string
+		proxy   common.Address
+		newImpl common.Address
+		holder  common.Address // 保存实现地址的合约
+		slot    common.Hash
+	}{
+		{"uups", uups, uupsImpl2, uups, proxy.ImplementationSlot},
+		{"beacon", beaconProxy, implB, beacon, common.Hash{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, tx, err := proxy.Upgrade(ctx, b, opts, tt.proxy, tt.newImpl, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Implementation != tt.newImpl {
+				t.Fatalf("升级后 %s", info)
+			}
+			receipt, err := net.Client.TransactionReceipt(ctx, tx.Hash())
+			if err != nil {
+				t.Fatal(err)
+			}
+			value, err := net.Client.StorageAt(ctx, tt.holder, tt.slot, receipt.BlockNumber)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if common.BytesToAddress(value) != tt.newImpl {
+				t.Fatalf("存储槽中的实现地址 %x", value)
+			}
+			// 升级之前的区块仍是旧实现
+			if _, err := proxy.Confirm(ctx, net.Client, tt.proxy, tt.newImpl, new(big.Int).Sub(receipt.BlockNumber, common.Big1)); !errors.Is(err, proxy.ErrNotUpgraded) {
+				t.Fatalf("Confirm 返回 %v，期望 %v", err, proxy.ErrNotUpgraded)
+			}
+		})
+	}
+}
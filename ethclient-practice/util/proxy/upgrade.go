@@ -0,0 +1,161 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrNotUpgraded 升级交易成功但实现地址没有变成预期值
+var ErrNotUpgraded = errors.New("升级后的实现地址与预期不一致")
+
+// UpgradeBackend 发送升级交易并确认需要的节点接口
+type UpgradeBackend interface {
+	Backend
+	bind.ContractBackend
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// UpgradePlan 升级方案：向 Target 发送 Data。
+// 不同代理类型的升级入口不同：
+//
+//	UUPS         代理自身的 upgradeToAndCall(newImpl, data)，由实现合约校验权限
+//	Transparent  ProxyAdmin 合约的 upgradeAndCall / upgrade；admin 是 EOA 时直接调用代理
+//	Beacon       beacon 合约的 upgradeTo(newImpl)，所有指向该 beacon 的代理一起升级
+type UpgradePlan struct {
+	Proxy             common.Address `json:"proxy"`
+	Kind              Kind           `json:"kind"`
+	OldImplementation common.Address `json:"oldImplementation"`
+	NewImplementation common.Address `json:"newImplementation"`
+	Target            common.Address `json:"target"`
+	Method            string         `json:"method"`
+	Data              []byte         `json:"data"`
+}
+
+// PlanUpgrade 根据代理类型确定升级方式。from 为发送升级交易的账户，
+// initData 为升级后在新实现上执行的初始化调用，可以为空
+func PlanUpgrade(ctx context.Context, backend Backend, from, proxy, newImpl common.Address, initData []byte) (*UpgradePlan, error) {
+	info, err := Inspect(ctx, backend, proxy, nil)
+	if err != nil {
+		return nil, err
+	}
+	code, err := backend.CodeAt(ctx, newImpl, nil)
+	if err != nil {
+		return nil, fmt.Errorf("读取新实现代码失败: %w", err)
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("新实现 %s 上没有合约代码", newImpl.Hex())
+	}
+	if newImpl == info.Implementation {
+		return nil, fmt.Errorf("%s 已经是当前实现", newImpl.Hex())
+	}
+
+	plan := &UpgradePlan{Proxy: proxy, Kind: info.Kind, OldImplementation: info.Implementation, NewImplementation: newImpl}
+	switch info.Kind {
+	case KindUUPS:
+		// 新实现不支持 UUPS 时，升级后代理将永远无法再升级
+		if !isUUPS(ctx, backend, newImpl, nil) {
+			return nil, fmt.Errorf("新实现 %s 没有 proxiableUUID()，不是 UUPS 实现", newImpl.Hex())
+		}
+		plan.Target, plan.Method = proxy, "upgradeToAndCall"
+		plan.Data, err = proxyABI.Pack(plan.Method, newImpl, initData)
+
+	case KindTransparent:
+		adminCode, err := backend.CodeAt(ctx, info.Admin, nil)
+		if err != nil {
+			return nil, fmt.Errorf("读取 admin 代码失败: %w", err)
+		}
+		if len(adminCode) == 0 {
+			// admin 是 EOA（OpenZeppelin 4.x 之前的用法），只有 admin 本人能调用升级
+			if info.Admin != from {
+				return nil, fmt.Errorf("代理的 admin 是 %s，当前账户 %s 无权升级", info.Admin.Hex(), from.Hex())
+			}
+			plan.Target, plan.Method = proxy, "upgradeToAndCall"
+			plan.Data, err = proxyABI.Pack(plan.Method, newImpl, initData)
+			if err != nil {
+				return nil, err
+			}
+			break
+		}
+		// ProxyAdmin 合约：5.x 只有 upgradeAndCall，4.x 不带初始化时使用 upgrade
+		plan.Target = info.Admin
+		if _, err := call(ctx, backend, info.Admin, "UPGRADE_INTERFACE_VERSION", nil); err == nil || len(initData) > 0 {
+			plan.Method = "upgradeAndCall"
+			plan.Data, err = proxyABI.Pack(plan.Method, proxy, newImpl, initData)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			plan.Method = "upgrade"
+			if plan.Data, err = proxyABI.Pack(plan.Method, proxy, newImpl); err != nil {
+				return nil, err
+			}
+		}
+
+	case KindBeacon:
+		if len(initData) > 0 {
+			return nil, errors.New("Beacon 升级不支持初始化调用，请升级后单独调用")
+		}
+		plan.Target, plan.Method = info.Beacon, "upgradeTo"
+		plan.Data, err = proxyABI.Pack(plan.Method, newImpl)
+
+	case KindMinimal:
+		return nil, errors.New("EIP-1167 最小代理的实现地址写在字节码里，不可升级")
+	case KindNone:
+		return nil, fmt.Errorf("%s 不是代理合约", proxy.Hex())
+	default:
+		return nil, fmt.Errorf("无法确定 %s 代理的升级方式", info.Kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// Send 发送升级交易，不等待确认
+func (p *UpgradePlan) Send(opts *bind.TransactOpts, backend bind.ContractBackend) (*types.Transaction, error) {
+	contract := bind.NewBoundContract(p.Target, proxyABI, backend, backend, backend)
+	tx, err := contract.RawTransact(opts, p.Data)
+	if err != nil {
+		return nil, fmt.Errorf("发送 %s 失败: %w", p.Method, err)
+	}
+	return tx, nil
+}
+
+// Confirm 读取指定区块的代理信息，确认实现地址已经变为 newImpl
+func Confirm(ctx context.Context, backend Backend, proxy, newImpl common.Address, blockNumber *big.Int) (*Info, error) {
+	info, err := Inspect(ctx, backend, proxy, blockNumber)
+	if err != nil {
+		return nil, err
+	}
+	if info.Implementation != newImpl {
+		return info, fmt.Errorf("%w: 期望 %s，实际 %s", ErrNotUpgraded, newImpl.Hex(), info.Implementation.Hex())
+	}
+	return info, nil
+}
+
+// Upgrade 升级代理并等待确认，返回升级后的代理信息
+func Upgrade(ctx context.Context, backend UpgradeBackend, opts *bind.TransactOpts, proxy, newImpl common.Address, initData []byte) (*Info, *types.Transaction, error) {
+	plan, err := PlanUpgrade(ctx, backend, opts.From, proxy, newImpl, initData)
+	if err != nil {
+		return nil, nil, err
+	}
+	tx, err := plan.Send(opts, backend)
+	if err != nil {
+		return nil, nil, err
+	}
+	receipt, err := bind.WaitMined(ctx, backend, tx)
+	if err != nil {
+		return nil, tx, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return nil, tx, fmt.Errorf("升级交易 %s 执行失败", tx.Hash().Hex())
+	}
+	info, err := Confirm(ctx, backend, proxy, newImpl, receipt.BlockNumber)
+	return info, tx, err
+}
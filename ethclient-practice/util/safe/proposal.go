@@ -0,0 +1,218 @@
+package safe
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/internal/abiword"
+)
+
+var (
+	// ErrHashMismatch 提案中的 safeTxHash 与交易内容不一致，文件可能被改过
+	ErrHashMismatch = errors.New("safeTxHash 与交易内容不一致")
+	// ErrNotEnoughSignatures owner 签名数量少于阈值
+	ErrNotEnoughSignatures = errors.New("签名数量不足")
+)
+
+// Signature 一个 owner 对 safeTxHash 的签名：r | s | v 共 65 字节，v 为 27 / 28
+type Signature struct {
+	Owner common.Address `json:"owner"`
+	Data  hexutil.Bytes  `json:"data"`
+}
+
+// Proposal 待执行的 Safe 交易和已收集的签名。各 owner 依次加载同一个提案文件、签名后保存，
+// 全部签完再交给执行者，私钥不需要出现在同一台机器上
+type Proposal struct {
+	Safe       common.Address `json:"safe"`
+	ChainID    *hexutil.Big   `json:"chainId"`
+	Tx         SafeTx         `json:"tx"`
+	SafeTxHash common.Hash    `json:"safeTxHash"`
+	Signatures []Signature    `json:"signatures"`
+}
+
+// NewProposal 创建提案并计算 safeTxHash
+func NewProposal(safe common.Address, chainID *big.Int, tx SafeTx) *Proposal {
+	return &Proposal{
+		Safe:       safe,
+		ChainID:    (*hexutil.Big)(chainID),
+		Tx:         tx,
+		SafeTxHash: tx.Hash(safe, chainID),
+	}
+}
+
+// Sign 用 key 签名 safeTxHash 并加入提案。签名直接针对 EIP-712 摘要，不加 personal_sign 前缀
+func (p *Proposal) Sign(key *ecdsa.PrivateKey) error {
+	if err := p.checkHash(); err != nil {
+		return err
+	}
+	sig, err := crypto.Sign(p.SafeTxHash.Bytes(), key)
+	if err != nil {
+		return fmt.Errorf("签名失败: %w", err)
+	}
+	sig[64] += 27
+	return p.Add(Signature{Owner: crypto.PubkeyToAddress(key.PublicKey), Data: sig})
+}
+
+// SignKeystore 解密 keystore 文件后签名，私钥只在本次调用中使用
+func (p *Proposal) SignKeystore(path, password string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	key, err := keystore.DecryptKey(raw, password)
+	if err != nil {
+		return fmt.Errorf("解密 %s 失败: %w", path, err)
+	}
+	return p.Sign(key.PrivateKey)
+}
+
+// Add 加入其他人提供的签名。签名必须能恢复出 sig.Owner，同一个 owner 重复签名时忽略
+func (p *Proposal) Add(sig Signature) error {
+	if err := p.checkHash(); err != nil {
+		return err
+	}
+	signer, err := recoverSigner(p.SafeTxHash, sig.Data)
+	if err != nil {
+		return err
+	}
+	if signer != sig.Owner {
+		return fmt.Errorf("签名来自 %s，不是 %s", signer.Hex(), sig.Owner.Hex())
+	}
+	for _, s := range p.Signatures {
+		if s.Owner == sig.Owner {
+			return nil
+		}
+	}
+	p.Signatures = append(p.Signatures, sig)
+	return nil
+}
+
+// Check 按链上的 owner、阈值和 nonce 检查提案能否执行：
+// nonce 必须等于 Safe 当前的 nonce，所有签名都来自 owner，且数量不少于阈值
+func (p *Proposal) Check(info *Info) error {
+	if err := p.checkHash(); err != nil {
+		return err
+	}
+	if p.Safe != info.Address {
+		return fmt.Errorf("提案属于 Safe %s，不是 %s", p.Safe.Hex(), info.Address.Hex())
+	}
+	if p.Tx.Nonce != info.Nonce {
+		return fmt.Errorf("提案的 nonce 为 %d，Safe 当前的 nonce 为 %d", p.Tx.Nonce, info.Nonce)
+	}
+	for _, sig := range p.Signatures {
+		signer, err := recoverSigner(p.SafeTxHash, sig.Data)
+		if err != nil {
+			return err
+		}
+		if signer != sig.Owner {
+			return fmt.Errorf("签名来自 %s，不是 %s", signer.Hex(), sig.Owner.Hex())
+		}
+		if !info.IsOwner(signer) {
+			return fmt.Errorf("%s 不是 Safe 的 owner", signer.Hex())
+		}
+	}
+	if uint64(len(p.Signatures)) < info.Threshold {
+		return fmt.Errorf("%w: 需要 %d 个，已有 %d 个", ErrNotEnoughSignatures, info.Threshold, len(p.Signatures))
+	}
+	return nil
+}
+
+// EncodedSignatures 按 owner 地址升序拼接签名，这是 execTransaction 要求的格式：
+// 合约逐个恢复签名者，并要求地址严格递增，以此排除重复签名
+func (p *Proposal) EncodedSignatures() []byte {
+	sigs := append([]Signature{}, p.Signatures...)
+	sort.Slice(sigs, func(i, j int) bool { return bytes.Compare(sigs[i].Owner.Bytes(), sigs[j].Owner.Bytes()) < 0 })
+	var out []byte
+	for _, sig := range sigs {
+		out = append(out, sig.Data...)
+	}
+	return out
+}
+
+// ExecData 编码 execTransaction 的调用数据
+func (p *Proposal) ExecData() ([]byte, error) {
+	tx := p.Tx
+	return safeABI.Pack("execTransaction", tx.To, abiword.Big(tx.Value), []byte(tx.Data), uint8(tx.Operation),
+		new(big.Int).SetUint64(tx.SafeTxGas), new(big.Int).SetUint64(tx.BaseGas), abiword.Big(tx.GasPrice),
+		tx.GasToken, tx.RefundReceiver, p.EncodedSignatures())
+}
+
+// Exec 检查提案后由 key 发送 execTransaction。执行者只支付 Gas，不需要是 owner
+func Exec(ctx context.Context, s chain.TransactionSender, key *ecdsa.PrivateKey, p *Proposal, info *Info) (*types.Transaction, error) {
+	if err := p.Check(info); err != nil {
+		return nil, err
+	}
+	data, err := p.ExecData()
+	if err != nil {
+		return nil, err
+	}
+	return chain.Send(ctx, s, key, &chain.TxRequest{To: &p.Safe, Data: data})
+}
+
+// checkHash 重新计算 safeTxHash，防止签名的哈希与要执行的交易不一致
+func (p *Proposal) checkHash() error {
+	if p.Tx.Hash(p.Safe, p.ChainID.ToInt()) != p.SafeTxHash {
+		return ErrHashMismatch
+	}
+	return nil
+}
+
+// recoverSigner 从 v 为 27 / 28 的签名恢复签名者
+func recoverSigner(hash common.Hash, sig []byte) (common.Address, error) {
+	if len(sig) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("签名长度应为 %d 字节，实际 %d 字节", crypto.SignatureLength, len(sig))
+	}
+	if sig[64] != 27 && sig[64] != 28 {
+		return common.Address{}, fmt.Errorf("不支持 v = %d 的签名", sig[64])
+	}
+	rsv := append([]byte{}, sig...)
+	rsv[64] -= 27
+	pub, err := crypto.SigToPub(hash.Bytes(), rsv)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("恢复签名者失败: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// ReadProposal 读取提案文件
+func ReadProposal(path string) (*Proposal, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var p Proposal
+	if err := json.Unmarshal(raw, &p); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %w", path, err)
+	}
+	if p.ChainID == nil {
+		return nil, fmt.Errorf("%s 缺少 chainId", path)
+	}
+	return &p, nil
+}
+
+// Save 写入提案文件。文件只包含交易和签名，泄露不会危及私钥
+func (p *Proposal) Save(path string) error {
+	raw, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(raw, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
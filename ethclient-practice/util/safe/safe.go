@@ -0,0 +1,238 @@
+// Package safe 构造 Safe（原 Gnosis Safe）多签钱包的交易，计算 EIP-712 safeTxHash，
+// 收集 owner 的签名并调用 execTransaction 执行。
+//
+// Safe 是一个合约钱包：资金属于合约，合约记录一组 owner 和阈值 threshold，
+// 任何交易都要有至少 threshold 个 owner 对 safeTxHash 签名后才能执行。
+// 签名在链下收集，最后由任意账户（不一定是 owner）提交 execTransaction 并支付 Gas。
+//
+//	info, err := safe.GetInfo(ctx, client, treasury)
+//	p := safe.NewProposal(treasury, chainID, safe.SafeTx{To: to, Value: amount, Nonce: info.Nonce})
+//	err = p.SignKeystore("owner1.json", password1)
+//	err = p.SignKeystore("owner2.json", password2)
+//	tx, err := safe.Exec(ctx, client, executorKey, p, info)
+//
+// 哈希规则适用于 Safe v1.3.0 及以后的版本（EIP-712 域包含 chainId）。
+package safe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/internal/abiword"
+)
+
+// Operation SafeTx 的执行方式
+type Operation uint8
+
+const (
+	Call         Operation = 0
+	DelegateCall Operation = 1 // 在 Safe 的上下文中执行目标合约的代码，通常用于 MultiSend 批量交易
+)
+
+// EIP-712 类型哈希
+var (
+	domainTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(uint256 chainId,address verifyingContract)"))
+	safeTxTypeHash = crypto.Keccak256Hash([]byte("SafeTx(address to,uint256 value,bytes data,uint8 operation,uint256 safeTxGas,uint256 baseGas,uint256 gasPrice,address gasToken,address refundReceiver,uint256 nonce)"))
+)
+
+// SafeTx Safe 要执行的交易，字段与合约中的 SafeTx 结构体一一对应。
+// 不需要 Gas 退款时 SafeTxGas、BaseGas、GasPrice、GasToken、RefundReceiver 都保持零值，
+// 此时执行失败会让整笔 execTransaction 回滚
+type SafeTx struct {
+	To             common.Address `json:"to"`
+	Value          *big.Int       `json:"value"`
+	Data           hexutil.Bytes  `json:"data"`
+	Operation      Operation      `json:"operation"`
+	SafeTxGas      uint64         `json:"safeTxGas"`
+	BaseGas        uint64         `json:"baseGas"`
+	GasPrice       *big.Int       `json:"gasPrice"`
+	GasToken       common.Address `json:"gasToken"`
+	RefundReceiver common.Address `json:"refundReceiver"`
+	Nonce          uint64         `json:"nonce"` // 必须等于 Safe 当前的 nonce，每执行一笔加 1
+}
+
+// MarshalJSON 与 Safe Transaction Service 相同，value 和 gasPrice 写成十进制字符串，
+// 避免 JavaScript 等按 double 解析 JSON 数字的工具丢失精度
+func (tx SafeTx) MarshalJSON() ([]byte, error) {
+	type plain SafeTx
+	return json.Marshal(struct {
+		plain
+		Value    string `json:"value"`
+		GasPrice string `json:"gasPrice"`
+	}{plain(tx), abiword.Big(tx.Value).String(), abiword.Big(tx.GasPrice).String()})
+}
+
+// UnmarshalJSON 读取 value 和 gasPrice 时接受十进制或 0x 开头的十六进制字符串，也兼容旧文件中的 JSON 数字
+func (tx *SafeTx) UnmarshalJSON(data []byte) error {
+	type plain SafeTx
+	dec := struct {
+		*plain
+		Value    json.RawMessage `json:"value"`
+		GasPrice json.RawMessage `json:"gasPrice"`
+	}{plain: (*plain)(tx)}
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+	var err error
+	if tx.Value, err = parseBig("value", dec.Value); err != nil {
+		return err
+	}
+	tx.GasPrice, err = parseBig("gasPrice", dec.GasPrice)
+	return err
+}
+
+// parseBig 解析 JSON 中的大整数，字段缺失或为 null 时返回 nil
+func parseBig(field string, raw json.RawMessage) (*big.Int, error) {
+	s := strings.Trim(string(raw), `"`)
+	if s == "" || s == "null" {
+		return nil, nil
+	}
+	v, ok := math.ParseBig256(s)
+	if !ok {
+		return nil, fmt.Errorf("%s 不是有效的整数: %s", field, raw)
+	}
+	return v, nil
+}
+
+// DomainSeparator Safe 的 EIP-712 域分隔符，与合约的 domainSeparator() 相同
+func DomainSeparator(safe common.Address, chainID *big.Int) common.Hash {
+	return crypto.Keccak256Hash(abiword.Concat(domainTypeHash.Bytes(), abiword.Big(chainID).Bytes(), safe.Bytes()))
+}
+
+// StructHash SafeTx 结构体的 EIP-712 哈希，bytes 字段 data 先取 keccak256
+func (tx *SafeTx) StructHash() common.Hash {
+	return crypto.Keccak256Hash(abiword.Concat(
+		safeTxTypeHash.Bytes(),
+		tx.To.Bytes(),
+		abiword.Big(tx.Value).Bytes(),
+		crypto.Keccak256(tx.Data),
+		[]byte{byte(tx.Operation)},
+		new(big.Int).SetUint64(tx.SafeTxGas).Bytes(),
+		new(big.Int).SetUint64(tx.BaseGas).Bytes(),
+		abiword.Big(tx.GasPrice).Bytes(),
+		tx.GasToken.Bytes(),
+		tx.RefundReceiver.Bytes(),
+		new(big.Int).SetUint64(tx.Nonce).Bytes(),
+	))
+}
+
+// Hash 计算 safeTxHash = keccak256(0x19 0x01 | domainSeparator | structHash)，
+// 与合约的 getTransactionHash 相同，owner 签名的就是这个哈希
+func (tx *SafeTx) Hash(safe common.Address, chainID *big.Int) common.Hash {
+	domain := DomainSeparator(safe, chainID)
+	return crypto.Keccak256Hash([]byte{0x19, 0x01}, domain.Bytes(), tx.StructHash().Bytes())
+}
+
+// Safe 合约中用到的函数和事件，签名与 v1.3.0 相同
+const safeABIJSON = `[` +
+	`{"type":"function","name":"getOwners","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"address[]"}]},` +
+	`{"type":"function","name":"getThreshold","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},` +
+	`{"type":"function","name":"nonce","stateMutability":"view","inputs":[],"outputs":[{"name":"","type":"uint256"}]},` +
+	`{"type":"function","name":"execTransaction","stateMutability":"payable","outputs":[{"name":"success","type":"bool"}],"inputs":[{"name":"to","type":"address"},{"name":"value","type":"uint256"},{"name":"data","type":"bytes"},{"name":"operation","type":"uint8"},{"name":"safeTxGas","type":"uint256"},{"name":"baseGas","type":"uint256"},{"name":"gasPrice","type":"uint256"},{"name":"gasToken","type":"address"},{"name":"refundReceiver","type":"address"},{"name":"signatures","type":"bytes"}]},` +
+	`{"type":"event","name":"ExecutionSuccess","anonymous":false,"inputs":[{"indexed":false,"name":"txHash","type":"bytes32"},{"indexed":false,"name":"payment","type":"uint256"}]},` +
+	`{"type":"event","name":"ExecutionFailure","anonymous":false,"inputs":[{"indexed":false,"name":"txHash","type":"bytes32"},{"indexed":false,"name":"payment","type":"uint256"}]}]`
+
+var safeABI = func() abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(safeABIJSON))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// Info Safe 的 owner、阈值和下一笔交易的 nonce
+type Info struct {
+	Address   common.Address
+	Owners    []common.Address
+	Threshold uint64
+	Nonce     uint64
+}
+
+// GetInfo 从合约读取 owner 列表、阈值和 nonce
+func GetInfo(ctx context.Context, c chain.ContractCaller, safe common.Address) (*Info, error) {
+	info := &Info{Address: safe}
+	out, err := call(ctx, c, safe, "getOwners")
+	if err != nil {
+		return nil, err
+	}
+	info.Owners = out[0].([]common.Address)
+	if out, err = call(ctx, c, safe, "getThreshold"); err != nil {
+		return nil, err
+	}
+	info.Threshold = out[0].(*big.Int).Uint64()
+	if out, err = call(ctx, c, safe, "nonce"); err != nil {
+		return nil, err
+	}
+	info.Nonce = out[0].(*big.Int).Uint64()
+	return info, nil
+}
+
+// IsOwner address 是否是 owner
+func (i *Info) IsOwner(address common.Address) bool {
+	for _, owner := range i.Owners {
+		if owner == address {
+			return true
+		}
+	}
+	return false
+}
+
+func call(ctx context.Context, c chain.ContractCaller, safe common.Address, method string) ([]interface{}, error) {
+	data, err := safeABI.Pack(method)
+	if err != nil {
+		return nil, err
+	}
+	out, err := c.CallContract(ctx, ethereum.CallMsg{To: &safe, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("调用 %s.%s 失败: %w", safe.Hex(), method, err)
+	}
+	values, err := safeABI.Unpack(method, out)
+	if err != nil {
+		return nil, fmt.Errorf("解析 %s 的返回值失败（%s 可能不是 Safe）: %w", method, safe.Hex(), err)
+	}
+	return values, nil
+}
+
+// Execution execTransaction 的结果，来自 ExecutionSuccess / ExecutionFailure 事件
+type Execution struct {
+	SafeTxHash common.Hash
+	Success    bool
+	Payment    *big.Int // 付给 refundReceiver 的 Gas 退款
+}
+
+// ParseExecution 从交易收据中找出 Safe 的执行结果。
+// SafeTxGas 或 GasPrice 不为 0 时，内部调用失败不会回滚，只有事件能说明是否执行成功
+func ParseExecution(receipt *types.Receipt, safe common.Address) (*Execution, error) {
+	for _, l := range receipt.Logs {
+		if l.Address != safe || len(l.Topics) == 0 {
+			continue
+		}
+		for _, name := range []string{"ExecutionSuccess", "ExecutionFailure"} {
+			if l.Topics[0] != safeABI.Events[name].ID {
+				continue
+			}
+			values, err := safeABI.Unpack(name, l.Data)
+			if err != nil {
+				return nil, fmt.Errorf("解析 %s 失败: %w", name, err)
+			}
+			return &Execution{
+				SafeTxHash: common.Hash(values[0].([32]byte)),
+				Success:    name == "ExecutionSuccess",
+				Payment:    values[1].(*big.Int),
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("交易 %s 中没有 Safe %s 的执行事件", receipt.TxHash.Hex(), safe.Hex())
+}
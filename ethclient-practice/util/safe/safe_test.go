@@ -0,0 +1,351 @@
+package safe_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/internal/abiword"
+	"github.com/dapp-learning/ethclient/util/safe"
+	"github.com/dapp-learning/ethclient/util/simnet"
+	"github.com/dapp-learning/ethclient/util/simnet/contracts"
+)
+
+var (
+	safeABI = func() abi.ABI {
+		parsed, err := abi.JSON(strings.NewReader(contracts.SafeABI))
+		if err != nil {
+			panic(err)
+		}
+		return parsed
+	}()
+	bob   = common.HexToAddress("0xb0b")
+	ether = big.NewInt(1e18)
+)
+
+// newSafe 在模拟链上部署 3 个 owner、阈值为 2 的 Safe 并转入 5 ETH。
+// owners 按地址升序排列，方便构造乱序的签名
+func newSafe(t *testing.T) (*simnet.Net, *safe.Info, []*simnet.Account) {
+	t.Helper()
+	net, err := simnet.New(&simnet.Options{Accounts: 5, SkipContracts: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { net.Close() })
+	ctx := context.Background()
+
+	owners := append([]*simnet.Account{}, net.Accounts[1:4]...)
+	for i := range owners {
+		for j := i + 1; j < len(owners); j++ {
+			if bytes.Compare(owners[j].Address.Bytes(), owners[i].Address.Bytes()) < 0 {
+				owners[i], owners[j] = owners[j], owners[i]
+			}
+		}
+	}
+	var addrs []common.Address
+	for _, owner := range owners {
+		addrs = append(addrs, owner.Address)
+	}
+	treasury, err := net.DeploySafe(ctx, net.Accounts[0], addrs, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := net.Transfer(ctx, net.Accounts[0], treasury, new(big.Int).Mul(big.NewInt(5), ether)); err != nil {
+		t.Fatal(err)
+	}
+	info, err := safe.GetInfo(ctx, net.Client, treasury)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return net, info, owners
+}
+
+func call(t *testing.T, net *simnet.Net, to common.Address, method string, args ...interface{}) []interface{} {
+	t.Helper()
+	data, err := safeABI.Pack(method, args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := net.Client.CallContract(context.Background(), ethereum.CallMsg{To: &to, Data: data}, nil)
+	if err != nil {
+		t.Fatalf("%s: %v", method, err)
+	}
+	values, err := safeABI.Unpack(method, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return values
+}
+
+func TestDeploySafe(t *testing.T) {
+	net, info, owners := newSafe(t)
+
+	if v := call(t, net, info.Address, "VERSION")[0].(string); v != contracts.SafeVersion {
+		t.Fatalf("VERSION = %q，期望 %q", v, contracts.SafeVersion)
+	}
+	if info.Threshold != 2 || info.Nonce != 0 || len(info.Owners) != 3 {
+		t.Fatalf("Safe 状态不符: %+v", info)
+	}
+	for _, owner := range owners {
+		if !info.IsOwner(owner.Address) {
+			t.Fatalf("%s 应为 owner", owner.Address.Hex())
+		}
+	}
+	if info.IsOwner(net.Accounts[0].Address) {
+		t.Fatal("部署者不是 owner")
+	}
+
+	// 代理的 slot 0 保存单例地址
+	slot, err := net.Client.StorageAt(context.Background(), info.Address, common.Hash{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if common.BytesToAddress(slot) != net.SafeSingleton {
+		t.Fatalf("代理指向 %x，期望单例 %s", slot, net.SafeSingleton.Hex())
+	}
+
+	// 第二个 Safe 共用单例和工厂
+	singleton := net.SafeSingleton
+	second, err := net.DeploySafe(context.Background(), net.Accounts[0], []common.Address{owners[0].Address}, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second == info.Address || net.SafeSingleton != singleton {
+		t.Fatalf("第二个 Safe %s，单例 %s", second.Hex(), net.SafeSingleton.Hex())
+	}
+	if _, err := net.DeploySafe(context.Background(), net.Accounts[0], []common.Address{owners[0].Address}, 2); err == nil {
+		t.Fatal("threshold 超过 owner 数量时应返回错误")
+	}
+}
+
+func TestSafeTxHashMatchesContract(t *testing.T) {
+	net, info, _ := newSafe(t)
+
+	if got, want := safe.DomainSeparator(info.Address, net.ChainID), common.Hash(call(t, net, info.Address, "domainSeparator")[0].([32]byte)); got != want {
+		t.Fatalf("DomainSeparator = %s，合约返回 %s", got.Hex(), want.Hex())
+	}
+	for _, tx := range []safe.SafeTx{
+		{To: bob, Value: ether},
+		{To: bob, Data: chain.TransferData(bob, ether), Operation: safe.DelegateCall, Nonce: 7},
+		{To: bob, SafeTxGas: 50_000, BaseGas: 21_000, GasPrice: big.NewInt(1e9), GasToken: bob, RefundReceiver: bob, Nonce: 1},
+	} {
+		want := common.Hash(call(t, net, info.Address, "getTransactionHash", tx.To, abiword.Big(tx.Value), []byte(tx.Data), uint8(tx.Operation),
+			new(big.Int).SetUint64(tx.SafeTxGas), new(big.Int).SetUint64(tx.BaseGas), abiword.Big(tx.GasPrice),
+			tx.GasToken, tx.RefundReceiver, new(big.Int).SetUint64(tx.Nonce))[0].([32]byte))
+		if got := tx.Hash(info.Address, net.ChainID); got != want {
+			t.Errorf("safeTxHash = %s，合约返回 %s", got.Hex(), want.Hex())
+		}
+	}
+}
+
+func TestCollectAndExecute(t *testing.T) {
+	net, info, owners := newSafe(t)
+	ctx := context.Background()
+	executor := net.Accounts[0]
+
+	p := safe.NewProposal(info.Address, net.ChainID, safe.SafeTx{To: bob, Value: ether, Nonce: info.Nonce})
+
+	// 地址较大的 owner 先签，收集顺序与执行要求的顺序相反
+	if err := p.Sign(owners[2].Key); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Check(info); !errors.Is(err, safe.ErrNotEnoughSignatures) {
+		t.Fatalf("一个签名时应返回 ErrNotEnoughSignatures，得到 %v", err)
+	}
+	if _, err := safe.Exec(ctx, net.Client, executor.Key, p, info); !errors.Is(err, safe.ErrNotEnoughSignatures) {
+		t.Fatalf("签名不足时 Exec 不应发送交易，得到 %v", err)
+	}
+	if err := p.Sign(owners[2].Key); err != nil || len(p.Signatures) != 1 {
+		t.Fatalf("重复签名应被忽略: %v，共 %d 个", err, len(p.Signatures))
+	}
+	if err := p.Add(safe.Signature{Owner: owners[1].Address, Data: p.Signatures[0].Data}); err == nil {
+		t.Fatal("签名与声明的 owner 不一致时应返回错误")
+	}
+	if err := p.Sign(owners[0].Key); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Check(info); err != nil {
+		t.Fatal(err)
+	}
+
+	// EncodedSignatures 按 owner 地址升序排列
+	sigs := p.EncodedSignatures()
+	if len(sigs) != 130 || !bytes.Equal(sigs[:65], p.Signatures[1].Data) || !bytes.Equal(sigs[65:], p.Signatures[0].Data) {
+		t.Fatal("签名没有按 owner 地址升序排列")
+	}
+
+	// 按收集顺序拼接时合约回滚 GS026
+	unsorted := append(append([]byte{}, p.Signatures[0].Data...), p.Signatures[1].Data...)
+	data, err := safeABI.Pack("execTransaction", bob, ether, []byte{}, uint8(0),
+		new(big.Int), new(big.Int), new(big.Int), common.Address{}, common.Address{}, unsorted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = net.Client.CallContract(ctx, ethereum.CallMsg{From: executor.Address, To: &info.Address, Data: data}, nil)
+	if err == nil || !strings.Contains(err.Error(), contracts.ErrSafeInvalidOwner) {
+		t.Fatalf("未排序的签名应回滚 %s，得到 %v", contracts.ErrSafeInvalidOwner, err)
+	}
+
+	// 不是 owner 的账户提交
+	tx, err := safe.Exec(ctx, net.Client, executor.Key, p, info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	net.Commit()
+	receipt, err := net.Client.TransactionReceipt(ctx, tx.Hash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := safe.ParseExecution(receipt, info.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Success || result.SafeTxHash != p.SafeTxHash {
+		t.Fatalf("执行结果不符: %+v", result)
+	}
+	if balance, _ := net.Client.BalanceAt(ctx, bob, nil); balance.Cmp(ether) != 0 {
+		t.Fatalf("bob 余额 %s，期望 1 ETH", balance)
+	}
+
+	// nonce 加 1 后同一个提案不能再执行
+	after, err := safe.GetInfo(ctx, net.Client, info.Address)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if after.Nonce != 1 {
+		t.Fatalf("执行后 nonce = %d，期望 1", after.Nonce)
+	}
+	if err := p.Check(after); err == nil {
+		t.Fatal("nonce 已使用的提案不应通过检查")
+	}
+
+	// 不是 owner 的签名
+	p = safe.NewProposal(info.Address, net.ChainID, safe.SafeTx{To: bob, Value: ether, Nonce: after.Nonce})
+	for _, key := range []*simnet.Account{owners[0], net.Accounts[4]} {
+		if err := p.Sign(key.Key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := p.Check(after); err == nil || !strings.Contains(err.Error(), "不是 Safe 的 owner") {
+		t.Fatalf("非 owner 的签名应检查失败，得到 %v", err)
+	}
+}
+
+func TestProposalFile(t *testing.T) {
+	net, info, owners := newSafe(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "proposal.json")
+
+	value, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	p := safe.NewProposal(info.Address, net.ChainID, safe.SafeTx{To: bob, Value: value, Nonce: info.Nonce})
+	if err := p.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	// 每个 owner 从自己的 keystore 签名后保存
+	ks := keystore.NewKeyStore(filepath.Join(dir, "keys"), keystore.LightScryptN, keystore.LightScryptP)
+	for _, owner := range owners[:2] {
+		account, err := ks.ImportECDSA(owner.Key, "pw")
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, err := safe.ReadProposal(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := p.SignKeystore(account.URL.Path, "wrong"); err == nil {
+			t.Fatal("密码错误时应返回错误")
+		}
+		if err := p.SignKeystore(account.URL.Path, "pw"); err != nil {
+			t.Fatal(err)
+		}
+		if err := p.Save(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p, err := safe.ReadProposal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Signatures) != 2 || p.Tx.Value.Cmp(value) != 0 {
+		t.Fatalf("读回的提案不符: %d 个签名，value %s", len(p.Signatures), p.Tx.Value)
+	}
+	if err := p.Check(info); err != nil {
+		t.Fatal(err)
+	}
+
+	// 修改过的提案与 safeTxHash 不一致，不能再签名
+	p.Tx.Value = ether
+	if err := p.Sign(owners[2].Key); !errors.Is(err, safe.ErrHashMismatch) {
+		t.Fatalf("篡改后应返回 ErrHashMismatch，得到 %v", err)
+	}
+}
+
+func TestSafeTxJSON(t *testing.T) {
+	value, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	tx := safe.SafeTx{To: bob, Value: value, Data: []byte{0x01}, Nonce: 3}
+	raw, err := json.Marshal(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 超过 2^53 的金额写成字符串，JavaScript 读取时不会丢失精度
+	for _, want := range []string{`"value":"123456789012345678901234567890"`, `"gasPrice":"0"`, `"nonce":3`, `"data":"0x01"`} {
+		if !strings.Contains(string(raw), want) {
+			t.Errorf("%s 中没有 %s", raw, want)
+		}
+	}
+	var back safe.SafeTx
+	if err := json.Unmarshal(raw, &back); err != nil {
+		t.Fatal(err)
+	}
+	if back.Value.Cmp(value) != 0 || back.GasPrice.Sign() != 0 || back.Hash(bob, big.NewInt(1)) != tx.Hash(bob, big.NewInt(1)) {
+		t.Fatalf("往返后不一致: %+v", back)
+	}
+
+	// 也接受十六进制字符串和旧文件中的 JSON 数字
+	for _, in := range []string{`{"value":"0xde0b6b3a7640000"}`, `{"value":1000000000000000000}`} {
+		var tx safe.SafeTx
+		if err := json.Unmarshal([]byte(in), &tx); err != nil {
+			t.Fatalf("%s: %v", in, err)
+		}
+		if tx.Value.Cmp(ether) != 0 {
+			t.Fatalf("%s: value = %s", in, tx.Value)
+		}
+	}
+	if err := json.Unmarshal([]byte(`{"value":"1.5"}`), new(safe.SafeTx)); err == nil {
+		t.Fatal("非整数的 value 应返回错误")
+	}
+}
+
+// 签名者恢复使用的是 EIP-712 摘要本身，不带 personal_sign 前缀
+func TestSignatureFormat(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := safe.NewProposal(bob, big.NewInt(1), safe.SafeTx{To: bob})
+	if err := p.Sign(key); err != nil {
+		t.Fatal(err)
+	}
+	sig := append([]byte{}, p.Signatures[0].Data...)
+	if sig[64] != 27 && sig[64] != 28 {
+		t.Fatalf("v = %d，期望 27 或 28", sig[64])
+	}
+	sig[64] -= 27
+	pub, err := crypto.SigToPub(p.SafeTxHash.Bytes(), sig)
+	if err != nil || crypto.PubkeyToAddress(*pub) != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatal("签名应能直接从 safeTxHash 恢复出签名者")
+	}
+}
@@ -0,0 +1,78 @@
+package sigdb
+
+// builtinSignatures 默认加载的常用签名
+const builtinSignatures = `
+# 内置错误
+error Error(string message)
+error Panic(uint256 code)
+
+# ERC20
+function name() view returns (string)
+function symbol() view returns (string)
+function decimals() view returns (uint8)
+function totalSupply() view returns (uint256)
+function balanceOf(address account) view returns (uint256)
+function allowance(address owner, address spender) view returns (uint256)
+function transfer(address to, uint256 amount) returns (bool)
+function approve(address spender, uint256 amount) returns (bool)
+function transferFrom(address from, address to, uint256 amount) returns (bool)
+function mint(address to, uint256 amount)
+function burn(uint256 amount)
+event Transfer(address indexed from, address indexed to, uint256 value)
+event Approval(address indexed owner, address indexed spender, uint256 value)
+
+# ERC721（Transfer / Approval 与 ERC20 的 topic0 相同，靠 indexed 数量区分）
+function ownerOf(uint256 tokenId) view returns (address)
+function safeTransferFrom(address from, address to, uint256 tokenId)
+function safeTransferFrom(address from, address to, uint256 tokenId, bytes data)
+function setApprovalForAll(address operator, bool approved)
+event Transfer(address indexed from, address indexed to, uint256 indexed tokenId)
+event Approval(address indexed owner, address indexed approved, uint256 indexed tokenId)
+event ApprovalForAll(address indexed owner, address indexed operator, bool approved)
+
+# WETH
+function deposit() payable
+function withdraw(uint256 wad)
+event Deposit(address indexed dst, uint256 wad)
+event Withdrawal(address indexed src, uint256 wad)
+
+# Uniswap V2 Router
+function swapExactETHForTokens(uint256 amountOutMin, address[] path, address to, uint256 deadline) payable
+function swapExactTokensForETH(uint256 amountIn, uint256 amountOutMin, address[] path, address to, uint256 deadline)
+function swapExactTokensForTokens(uint256 amountIn, uint256 amountOutMin, address[] path, address to, uint256 deadline)
+
+# 通用
+function multicall(bytes[] data) returns (bytes[])
+
+# Safe MultiSend，也是 simnet 中 EIP-7702 批量调用合约 Batch 的接口
+function multiSend(bytes transactions) payable
+
+# ERC-4337 EntryPoint v0.7 和 SimpleAccount
+function handleOps((address sender, uint256 nonce, bytes initCode, bytes callData, bytes32 accountGasLimits, uint256 preVerificationGas, bytes32 gasFees, bytes paymasterAndData, bytes signature)[] ops, address beneficiary)
+function getNonce(address sender, uint192 key) view returns (uint256)
+function depositTo(address account) payable
+function execute(address dest, uint256 value, bytes func)
+function createAccount(address owner, uint256 salt) returns (address)
+event UserOperationEvent(bytes32 indexed userOpHash, address indexed sender, address indexed paymaster, uint256 nonce, bool success, uint256 actualGasCost, uint256 actualGasUsed)
+event UserOperationRevertReason(bytes32 indexed userOpHash, address indexed sender, uint256 nonce, bytes revertReason)
+event Deposited(address indexed account, uint256 totalDeposit)
+
+# Safe 多签钱包 v1.3.0
+function execTransaction(address to, uint256 value, bytes data, uint8 operation, uint256 safeTxGas, uint256 baseGas, uint256 gasPrice, address gasToken, address refundReceiver, bytes signatures) payable returns (bool success)
+function getTransactionHash(address to, uint256 value, bytes data, uint8 operation, uint256 safeTxGas, uint256 baseGas, uint256 gasPrice, address gasToken, address refundReceiver, uint256 _nonce) view returns (bytes32)
+function getOwners() view returns (address[])
+function getThreshold() view returns (uint256)
+event ExecutionSuccess(bytes32 txHash, uint256 payment)
+event ExecutionFailure(bytes32 txHash, uint256 payment)
+event SafeReceived(address indexed sender, uint256 value)
+
+# 本课程的 Store 合约（2.09 / 2.11）
+function version() view returns (string)
+function items(bytes32) view returns (bytes32)
+function getItem(bytes32 key) view returns (bytes32)
+function setItem(bytes32 key, bytes32 value)
+event ItemSet(bytes32 indexed key, bytes32 value)
+
+# 2.03 练习中的拍卖合约
+event BidPlaced(uint256 indexed auctionId, address indexed bidder, uint256 amount, bool isETH)
+`
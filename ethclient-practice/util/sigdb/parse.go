@@ -0,0 +1,248 @@
+package sigdb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+)
+
+// parseSignature 解析文本形式的签名，支持以下写法：
+//
+//	transfer(address,uint256)
+//	function transfer(address to, uint256 amount) external returns (bool)
+//	function getReserves() view returns (uint112 reserve0, uint112 reserve1, uint32 blockTimestampLast)
+//	event Transfer(address indexed from, address indexed to, uint256 value)
+//	event Anon(uint256 indexed a) anonymous
+//	error InsufficientBalance(uint256 available, uint256 required)
+//	swap((address,uint256)[],bytes)
+//
+// 没有关键字前缀时按 function 处理。函数的 returns (...) 记录在 Outputs 中，用于解码返回值
+func parseSignature(line string) (*Entry, error) {
+	line = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(line), ";"))
+
+	kind := KindFunction
+	for _, k := range []Kind{KindFunction, KindEvent, KindError} {
+		if strings.HasPrefix(line, string(k)+" ") {
+			kind = k
+			line = strings.TrimSpace(line[len(k):])
+			break
+		}
+	}
+
+	open := strings.Index(line, "(")
+	if open <= 0 {
+		return nil, fmt.Errorf("签名格式错误: %q", line)
+	}
+	closing := matchParen(line, open)
+	if closing < 0 {
+		return nil, fmt.Errorf("括号不匹配: %q", line)
+	}
+
+	name := strings.TrimSpace(line[:open])
+	if !isIdentifier(name) {
+		return nil, fmt.Errorf("无效的名称: %q", name)
+	}
+
+	entry := &Entry{Kind: kind, Name: name}
+	for _, param := range splitTopLevel(line[open+1 : closing]) {
+		arg, err := parseParam(param, kind == KindEvent)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		entry.Inputs = append(entry.Inputs, arg)
+	}
+
+	// 括号之后只允许出现修饰符、returns (...) 和 anonymous
+	rest := strings.TrimSpace(line[closing+1:])
+	if kind == KindEvent && strings.HasSuffix(rest, "anonymous") {
+		entry.Anonymous = true
+	}
+	if kind == KindFunction {
+		outputs, err := parseReturns(rest)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		entry.Outputs = outputs
+	}
+	return entry, nil
+}
+
+// parseReturns 解析修饰符中的 returns (...)，没有时返回 nil
+func parseReturns(rest string) ([]abi.ArgumentMarshaling, error) {
+	i := strings.Index(rest, "returns")
+	if i < 0 {
+		return nil, nil
+	}
+	list := strings.TrimSpace(rest[i+len("returns"):])
+	if !strings.HasPrefix(list, "(") {
+		return nil, fmt.Errorf("returns 后应为括号: %q", rest)
+	}
+	closing := matchParen(list, 0)
+	if closing < 0 {
+		return nil, fmt.Errorf("returns 括号不匹配: %q", rest)
+	}
+	var outputs []abi.ArgumentMarshaling
+	for _, param := range splitTopLevel(list[1:closing]) {
+		arg, err := parseParam(param, false)
+		if err != nil {
+			return nil, fmt.Errorf("返回值: %w", err)
+		}
+		outputs = append(outputs, arg)
+	}
+	return outputs, nil
+}
+
+// parseParam 解析单个参数：类型 [indexed] [名称]
+func parseParam(param string, allowIndexed bool) (abi.ArgumentMarshaling, error) {
+	param = strings.TrimSpace(param)
+	if param == "" {
+		return abi.ArgumentMarshaling{}, fmt.Errorf("参数为空")
+	}
+
+	// 类型可能是元组 (a,b)[]，需要整体取出
+	var typ, rest string
+	if strings.HasPrefix(param, "(") || strings.HasPrefix(param, "tuple(") {
+		open := strings.Index(param, "(")
+		closing := matchParen(param, open)
+		if closing < 0 {
+			return abi.ArgumentMarshaling{}, fmt.Errorf("元组括号不匹配: %q", param)
+		}
+		end := closing + 1
+		for end < len(param) && param[end] != ' ' {
+			end++
+		}
+		typ, rest = param[:end], param[end:]
+	} else {
+		fields := strings.SplitN(param, " ", 2)
+		typ = fields[0]
+		if len(fields) > 1 {
+			rest = fields[1]
+		}
+	}
+
+	arg, err := parseType(typ)
+	if err != nil {
+		return abi.ArgumentMarshaling{}, err
+	}
+
+	for _, word := range strings.Fields(rest) {
+		switch word {
+		case "indexed":
+			if !allowIndexed {
+				return abi.ArgumentMarshaling{}, fmt.Errorf("只有事件参数可以使用 indexed")
+			}
+			arg.Indexed = true
+		case "memory", "calldata", "storage", "payable":
+			// 数据位置修饰符不影响 ABI
+		default:
+			arg.Name = word
+		}
+	}
+	return arg, nil
+}
+
+// parseType 将类型文本转换为 ArgumentMarshaling，元组会展开为 components
+func parseType(typ string) (abi.ArgumentMarshaling, error) {
+	typ = strings.TrimPrefix(strings.TrimSpace(typ), "tuple")
+	if !strings.HasPrefix(typ, "(") {
+		typ = canonicalType(typ)
+		// 用 abi.NewType 校验类型是否合法
+		if _, err := abi.NewType(typ, "", nil); err != nil {
+			return abi.ArgumentMarshaling{}, err
+		}
+		return abi.ArgumentMarshaling{Type: typ}, nil
+	}
+
+	closing := matchParen(typ, 0)
+	if closing < 0 {
+		return abi.ArgumentMarshaling{}, fmt.Errorf("元组括号不匹配: %q", typ)
+	}
+	arg := abi.ArgumentMarshaling{Type: "tuple" + typ[closing+1:]}
+	for i, elem := range splitTopLevel(typ[1:closing]) {
+		component, err := parseParam(elem, false)
+		if err != nil {
+			return abi.ArgumentMarshaling{}, err
+		}
+		// 元组字段必须有名称，否则 go-ethereum 无法生成结构体
+		if component.Name == "" {
+			component.Name = fmt.Sprintf("field%d", i)
+		}
+		arg.Components = append(arg.Components, component)
+	}
+	return arg, nil
+}
+
+// canonicalType 将 uint / int 等别名转换为规范类型
+func canonicalType(typ string) string {
+	suffix := ""
+	if i := strings.Index(typ, "["); i >= 0 {
+		typ, suffix = typ[:i], typ[i:]
+	}
+	switch typ {
+	case "uint":
+		typ = "uint256"
+	case "int":
+		typ = "int256"
+	case "byte":
+		typ = "bytes1"
+	}
+	return typ + suffix
+}
+
+// splitTopLevel 按不在括号内的逗号拆分
+func splitTopLevel(list string) []string {
+	if strings.TrimSpace(list) == "" {
+		return nil
+	}
+	var parts []string
+	depth, start := 0, 0
+	for i, ch := range list {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, list[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, list[start:])
+}
+
+// matchParen 返回与 open 位置的左括号匹配的右括号位置
+func matchParen(s string, open int) int {
+	depth := 0
+	for i := open; i < len(s); i++ {
+		switch s[i] {
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// isIdentifier 检查是否为合法的 Solidity 标识符
+func isIdentifier(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, ch := range name {
+		switch {
+		case ch == '_' || ch == '$':
+		case ch >= 'a' && ch <= 'z', ch >= 'A' && ch <= 'Z':
+		case ch >= '0' && ch <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
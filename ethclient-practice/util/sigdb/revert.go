@@ -0,0 +1,100 @@
+package sigdb
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// panicReasons Solidity Panic(uint256) 错误码含义
+var panicReasons = map[uint64]string{
+	0x00: "编译器插入的通用 panic",
+	0x01: "assert 失败",
+	0x11: "算术溢出或下溢",
+	0x12: "除以零或对零取模",
+	0x21: "转换为枚举时数值越界",
+	0x22: "存储字节数组编码错误",
+	0x31: "对空数组调用 pop()",
+	0x32: "数组下标越界",
+	0x41: "内存分配过大",
+	0x51: "调用未初始化的内部函数",
+}
+
+// RevertReason 将 revert 数据解码为可读文本，例如：
+//
+//	Error("ERC20: transfer amount exceeds balance")
+//	Panic(0x11): 算术溢出或下溢
+//	InsufficientBalance(available=1, required=2)
+func (r *Registry) RevertReason(data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", errors.New("没有 revert 数据（require 未带错误信息或 out of gas）")
+	}
+
+	e, args, err := r.ResolveError(data)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case e.Signature == "Error(string)" && len(args) == 1:
+		return fmt.Sprintf("Error(%q)", args[0]), nil
+	case e.Signature == "Panic(uint256)" && len(args) == 1:
+		code, _ := args[0].(*big.Int)
+		if code == nil {
+			break
+		}
+		reason := panicReasons[code.Uint64()]
+		if reason == "" {
+			reason = "未知错误码"
+		}
+		return fmt.Sprintf("Panic(0x%x): %s", code, reason), nil
+	}
+
+	inputs := e.Arguments()
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		if name := inputs[i].Name; name != "" {
+			parts[i] = fmt.Sprintf("%s=%v", name, arg)
+		} else {
+			parts[i] = fmt.Sprintf("%v", arg)
+		}
+	}
+	return fmt.Sprintf("%s(%s)", e.Name, strings.Join(parts, ", ")), nil
+}
+
+// RevertData 从 eth_call / eth_estimateGas 返回的错误中取出 revert 数据
+func RevertData(err error) ([]byte, bool) {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return nil, false
+	}
+	hexData, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return nil, false
+	}
+	data, decodeErr := hexutil.Decode(hexData)
+	if decodeErr != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// DescribeError 尝试把调用错误解释为 revert 原因，无法解释时返回原始错误信息
+func (r *Registry) DescribeError(err error) string {
+	if err == nil {
+		return ""
+	}
+	data, ok := RevertData(err)
+	if !ok {
+		return err.Error()
+	}
+	reason, decodeErr := r.RevertReason(data)
+	if decodeErr != nil {
+		return fmt.Sprintf("%v（revert 数据: %s）", err, hexutil.Encode(data))
+	}
+	return reason
+}
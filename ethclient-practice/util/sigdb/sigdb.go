@@ -0,0 +1,607 @@
+// Package sigdb 提供本地的函数选择器 / 事件 topic0 / 自定义错误签名数据库。
+//
+// 签名可以从 ABI JSON（包括 Hardhat / Foundry 编译产物）或文本签名列表加载，
+// 同一个选择器对应多个签名（冲突）时，在解码阶段根据数据能否被正确解析来挑选。
+package sigdb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Kind 签名类型
+type Kind string
+
+const (
+	KindFunction Kind = "function"
+	KindEvent    Kind = "event"
+	KindError    Kind = "error"
+)
+
+var (
+	// ErrNotFound 数据库中没有该选择器 / topic
+	ErrNotFound = errors.New("签名未找到")
+	// ErrNoMatch 找到了签名，但没有一个能解码给定数据
+	ErrNoMatch = errors.New("没有匹配的签名")
+)
+
+// Entry 数据库中的一条签名记录
+type Entry struct {
+	Kind      Kind                     `json:"kind"`
+	Name      string                   `json:"name"`
+	Signature string                   `json:"signature"` // 规范签名，如 transfer(address,uint256)
+	Selector  string                   `json:"selector"`  // 函数 / 错误为 4 字节选择器，事件为 topic0
+	Source    string                   `json:"source,omitempty"`
+	Inputs    []abi.ArgumentMarshaling `json:"inputs"`
+	Outputs   []abi.ArgumentMarshaling `json:"outputs,omitempty"` // 仅函数，用于解码返回值
+	Anonymous bool                     `json:"anonymous,omitempty"`
+
+	method *abi.Method
+	event  *abi.Event
+	abiErr *abi.Error
+}
+
+// Method 返回函数的 ABI 定义（仅 KindFunction）
+func (e *Entry) Method() *abi.Method { return e.method }
+
+// Event 返回事件的 ABI 定义（仅 KindEvent）
+func (e *Entry) Event() *abi.Event { return e.event }
+
+// Error 返回自定义错误的 ABI 定义（仅 KindError）
+func (e *Entry) Error() *abi.Error { return e.abiErr }
+
+// Arguments 返回参数列表
+func (e *Entry) Arguments() abi.Arguments {
+	switch e.Kind {
+	case KindEvent:
+		return e.event.Inputs
+	case KindError:
+		return e.abiErr.Inputs
+	default:
+		return e.method.Inputs
+	}
+}
+
+// Named 参数是否带名称。来自 ABI 的签名带名称，解析冲突时优先使用
+func (e *Entry) Named() bool {
+	for _, in := range e.Inputs {
+		if in.Name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// better e 是否比同一签名的 old 信息更完整
+func (e *Entry) better(old *Entry) bool {
+	if e.Named() != old.Named() {
+		return e.Named()
+	}
+	return len(e.Outputs) > 0 && len(old.Outputs) == 0
+}
+
+// IndexedCount 事件 indexed 参数的数量
+func (e *Entry) IndexedCount() int {
+	n := 0
+	for _, in := range e.Inputs {
+		if in.Indexed {
+			n++
+		}
+	}
+	return n
+}
+
+// compile 根据 Inputs 构造 go-ethereum 的 ABI 对象，并填充 Signature / Selector
+func (e *Entry) compile() error {
+	inputs := e.Inputs
+	if inputs == nil {
+		inputs = []abi.ArgumentMarshaling{}
+	}
+	fragment := map[string]interface{}{
+		"type":   e.Kind,
+		"name":   e.Name,
+		"inputs": inputs,
+	}
+	switch e.Kind {
+	case KindFunction:
+		outputs := e.Outputs
+		if outputs == nil {
+			outputs = []abi.ArgumentMarshaling{}
+		}
+		fragment["outputs"] = outputs
+		fragment["stateMutability"] = "nonpayable"
+	case KindEvent:
+		fragment["anonymous"] = e.Anonymous
+	}
+
+	raw, err := json.Marshal([]interface{}{fragment})
+	if err != nil {
+		return err
+	}
+	parsed, err := abi.JSON(bytes.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", e.Kind, e.Name, err)
+	}
+
+	switch e.Kind {
+	case KindFunction:
+		m := parsed.Methods[e.Name]
+		e.method = &m
+		e.Signature = m.Sig
+		e.Selector = hexutil.Encode(m.ID)
+	case KindEvent:
+		ev := parsed.Events[e.Name]
+		e.event = &ev
+		e.Signature = ev.Sig
+		e.Selector = ev.ID.Hex()
+	case KindError:
+		er := parsed.Errors[e.Name]
+		e.abiErr = &er
+		e.Signature = er.Sig
+		e.Selector = hexutil.Encode(er.ID[:4])
+	default:
+		return fmt.Errorf("未知的签名类型 %q", e.Kind)
+	}
+	return nil
+}
+
+// key 去重使用的键。事件的 indexed 位置不同会得到不同的解码方式，需要区分
+func (e *Entry) key() string {
+	k := string(e.Kind) + ":" + e.Signature
+	if e.Kind == KindEvent {
+		for _, in := range e.Inputs {
+			if in.Indexed {
+				k += "i"
+			} else {
+				k += "d"
+			}
+		}
+		if e.Anonymous {
+			k += ":anon"
+		}
+	}
+	return k
+}
+
+// Registry 签名数据库，可并发读取
+type Registry struct {
+	mu        sync.RWMutex
+	entries   []*Entry
+	byKey     map[string]*Entry
+	functions map[[4]byte][]*Entry
+	errors    map[[4]byte][]*Entry
+	events    map[common.Hash][]*Entry
+	anonymous []*Entry
+}
+
+// New 创建空的签名数据库
+func New() *Registry {
+	return &Registry{
+		byKey:     make(map[string]*Entry),
+		functions: make(map[[4]byte][]*Entry),
+		errors:    make(map[[4]byte][]*Entry),
+		events:    make(map[common.Hash][]*Entry),
+	}
+}
+
+// NewDefault 创建包含常用签名（ERC20 / ERC721 / WETH / Store 等）的数据库
+func NewDefault() *Registry {
+	r := New()
+	if _, err := r.LoadSignatures("builtin", strings.NewReader(builtinSignatures)); err != nil {
+		panic("sigdb: 内置签名无效: " + err.Error())
+	}
+	return r
+}
+
+// Open 创建默认数据库，并合并 path 中持久化的签名（文件不存在时忽略）
+func Open(path string) (*Registry, error) {
+	r := NewDefault()
+	if _, err := r.LoadDB(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Len 返回签名数量
+func (r *Registry) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.entries)
+}
+
+// Entries 返回所有签名的副本，按类型和签名排序
+func (r *Registry) Entries() []*Entry {
+	r.mu.RLock()
+	list := append([]*Entry(nil), r.entries...)
+	r.mu.RUnlock()
+
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].Kind != list[j].Kind {
+			return list[i].Kind < list[j].Kind
+		}
+		return list[i].Signature < list[j].Signature
+	})
+	return list
+}
+
+// Add 加入一条签名。已存在的相同签名会被跳过，
+// 但带参数名的版本会替换不带参数名的版本，参数名相同时带返回值的版本替换不带返回值的版本。返回是否有变化
+func (r *Registry) Add(e *Entry) (bool, error) {
+	if err := e.compile(); err != nil {
+		return false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := e.key()
+	if old, ok := r.byKey[key]; ok {
+		if !e.better(old) {
+			return false, nil
+		}
+		// 换成新的 Entry 而不是就地修改：Entries / Functions 等返回的指针在锁外读取
+		r.replace(old, e)
+		return true, nil
+	}
+
+	r.byKey[key] = e
+	r.entries = append(r.entries, e)
+	switch e.Kind {
+	case KindFunction:
+		sel := selector4(e.method.ID)
+		r.functions[sel] = append(r.functions[sel], e)
+	case KindError:
+		sel := selector4(e.abiErr.ID[:4])
+		r.errors[sel] = append(r.errors[sel], e)
+	case KindEvent:
+		if e.Anonymous {
+			r.anonymous = append(r.anonymous, e)
+		} else {
+			r.events[e.event.ID] = append(r.events[e.event.ID], e)
+		}
+	}
+	return true, nil
+}
+
+// replace 把所有索引中的 old 换成 e，两者的 key 相同，选择器也相同
+func (r *Registry) replace(old, e *Entry) {
+	swap := func(list []*Entry) {
+		for i := range list {
+			if list[i] == old {
+				list[i] = e
+			}
+		}
+	}
+	r.byKey[e.key()] = e
+	swap(r.entries)
+	switch e.Kind {
+	case KindFunction:
+		swap(r.functions[selector4(e.method.ID)])
+	case KindError:
+		swap(r.errors[selector4(e.abiErr.ID[:4])])
+	case KindEvent:
+		if e.Anonymous {
+			swap(r.anonymous)
+		} else {
+			swap(r.events[e.event.ID])
+		}
+	}
+}
+
+// AddSignature 加入一条文本签名，格式见 parseSignature
+func (r *Registry) AddSignature(source, line string) error {
+	e, err := parseSignature(line)
+	if err != nil {
+		return err
+	}
+	e.Source = source
+	_, err = r.Add(e)
+	return err
+}
+
+// LoadSignatures 从文本读取签名，每行一个，忽略空行和 # 或 // 开头的注释。
+// 返回新增的签名数量
+func (r *Registry) LoadSignatures(source string, rd io.Reader) (int, error) {
+	count := 0
+	scanner := bufio.NewScanner(rd)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "//") {
+			continue
+		}
+		e, err := parseSignature(line)
+		if err != nil {
+			return count, fmt.Errorf("%s:%d: %w", source, lineNo, err)
+		}
+		e.Source = source
+		added, err := r.Add(e)
+		if err != nil {
+			return count, fmt.Errorf("%s:%d: %w", source, lineNo, err)
+		}
+		if added {
+			count++
+		}
+	}
+	return count, scanner.Err()
+}
+
+// abiItem ABI JSON 中的一项
+type abiItem struct {
+	Type      string                   `json:"type"`
+	Name      string                   `json:"name"`
+	Inputs    []abi.ArgumentMarshaling `json:"inputs"`
+	Outputs   []abi.ArgumentMarshaling `json:"outputs"`
+	Anonymous bool                     `json:"anonymous"`
+}
+
+// LoadABI 从 ABI JSON 加载函数、事件和错误。
+// 支持纯 ABI 数组，以及带 "abi" 字段的 Hardhat / Foundry 编译产物
+func (r *Registry) LoadABI(source string, rd io.Reader) (int, error) {
+	raw, err := io.ReadAll(rd)
+	if err != nil {
+		return 0, err
+	}
+	raw, err = ExtractABI(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", source, err)
+	}
+
+	var items []abiItem
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return 0, fmt.Errorf("%s: 解析 ABI 失败: %w", source, err)
+	}
+
+	count := 0
+	for _, item := range items {
+		kind := Kind(item.Type)
+		if kind != KindFunction && kind != KindEvent && kind != KindError {
+			continue // constructor / fallback / receive 没有选择器
+		}
+		added, err := r.Add(&Entry{
+			Kind:      kind,
+			Name:      item.Name,
+			Source:    source,
+			Inputs:    item.Inputs,
+			Outputs:   item.Outputs,
+			Anonymous: item.Anonymous,
+		})
+		if err != nil {
+			return count, fmt.Errorf("%s: %w", source, err)
+		}
+		if added {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// ExtractABI 从纯 ABI 数组或 Hardhat / Foundry 编译产物中取出 ABI 数组
+func ExtractABI(raw []byte) ([]byte, error) {
+	raw = bytes.TrimSpace(raw)
+	if len(raw) > 0 && raw[0] == '[' {
+		return raw, nil
+	}
+	var artifact struct {
+		ABI json.RawMessage `json:"abi"`
+	}
+	if err := json.Unmarshal(raw, &artifact); err != nil {
+		return nil, fmt.Errorf("既不是 ABI 数组也不是编译产物: %w", err)
+	}
+	if len(artifact.ABI) == 0 {
+		return nil, errors.New("编译产物中没有 abi 字段")
+	}
+	// solc --combined-json 等工具会把 ABI 存成字符串
+	var asString string
+	if json.Unmarshal(artifact.ABI, &asString) == nil {
+		return []byte(asString), nil
+	}
+	return artifact.ABI, nil
+}
+
+// dbFile 持久化文件格式
+type dbFile struct {
+	Version int      `json:"version"`
+	Entries []*Entry `json:"entries"`
+}
+
+const dbVersion = 1
+
+// LoadDB 合并由 Save 写出的数据库文件，返回新增的签名数量
+func (r *Registry) LoadDB(path string) (int, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	var db dbFile
+	if err := json.Unmarshal(raw, &db); err != nil {
+		return 0, fmt.Errorf("%s: %w", path, err)
+	}
+	if db.Version != dbVersion {
+		return 0, fmt.Errorf("%s: 不支持的数据库版本 %d", path, db.Version)
+	}
+
+	count := 0
+	for _, e := range db.Entries {
+		added, err := r.Add(e)
+		if err != nil {
+			return count, fmt.Errorf("%s: %w", path, err)
+		}
+		if added {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Save 将数据库写入 path（先写临时文件再重命名，避免中途失败损坏原文件）
+func (r *Registry) Save(path string) error {
+	raw, err := json.MarshalIndent(dbFile{Version: dbVersion, Entries: r.Entries()}, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// LoadFile 根据文件内容自动选择加载方式：
+// 数据库文件（含 entries）、ABI / 编译产物（.json），其余按文本签名列表处理
+func (r *Registry) LoadFile(path string) (int, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	source := filepath.Base(path)
+
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		var probe struct {
+			Entries json.RawMessage `json:"entries"`
+		}
+		if bytes.HasPrefix(bytes.TrimSpace(raw), []byte("{")) && json.Unmarshal(raw, &probe) == nil && probe.Entries != nil {
+			return r.LoadDB(path)
+		}
+		return r.LoadABI(source, bytes.NewReader(raw))
+	}
+	return r.LoadSignatures(source, bytes.NewReader(raw))
+}
+
+// Functions 返回选择器对应的所有函数签名，带参数名的排在前面
+func (r *Registry) Functions(selector []byte) []*Entry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return preferNamed(r.functions[selector4(selector)])
+}
+
+// Errors 返回选择器对应的所有自定义错误签名
+func (r *Registry) Errors(selector []byte) []*Entry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return preferNamed(r.errors[selector4(selector)])
+}
+
+// Events 返回 topic0 对应的所有事件签名
+func (r *Registry) Events(topic common.Hash) []*Entry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return preferNamed(r.events[topic])
+}
+
+// AnonymousEvents 返回所有匿名事件
+func (r *Registry) AnonymousEvents() []*Entry {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return preferNamed(r.anonymous)
+}
+
+// ResolveFunction 根据 calldata 找到匹配的函数并解码参数。
+// 选择器冲突时，优先选择解码后重新编码与原数据完全一致的签名
+func (r *Registry) ResolveFunction(calldata []byte) (*Entry, []interface{}, error) {
+	if len(calldata) < 4 {
+		return nil, nil, fmt.Errorf("calldata 长度不足 4 字节")
+	}
+	return resolveArgs(r.Functions(calldata[:4]), calldata)
+}
+
+// ResolveError 根据 revert 数据找到匹配的错误并解码参数
+func (r *Registry) ResolveError(data []byte) (*Entry, []interface{}, error) {
+	if len(data) < 4 {
+		return nil, nil, fmt.Errorf("revert 数据长度不足 4 字节")
+	}
+	return resolveArgs(r.Errors(data[:4]), data)
+}
+
+// ResolveEvent 找到能解码该日志的事件签名：
+// topic 数量必须等于 indexed 参数数量 + 1（匿名事件不加 1），且 data 可以被解码
+func (r *Registry) ResolveEvent(log *types.Log) (*Entry, error) {
+	if len(log.Topics) > 0 {
+		candidates := r.Events(log.Topics[0])
+		for _, e := range candidates {
+			if e.IndexedCount() == len(log.Topics)-1 && canUnpack(e.event.Inputs.NonIndexed(), log.Data) {
+				return e, nil
+			}
+		}
+		if len(candidates) > 0 {
+			return nil, fmt.Errorf("%w: topic0 %s 有 %d 个候选事件", ErrNoMatch, log.Topics[0].Hex(), len(candidates))
+		}
+	}
+
+	// 匿名事件没有 topic0，只能按 topic 数量和 data 猜测
+	for _, e := range r.AnonymousEvents() {
+		if e.IndexedCount() == len(log.Topics) && canUnpack(e.event.Inputs.NonIndexed(), log.Data) {
+			return e, nil
+		}
+	}
+	return nil, ErrNotFound
+}
+
+// resolveArgs 在候选签名中挑选能解码 data[4:] 的一个
+func resolveArgs(candidates []*Entry, data []byte) (*Entry, []interface{}, error) {
+	if len(candidates) == 0 {
+		return nil, nil, fmt.Errorf("%w: %s", ErrNotFound, hexutil.Encode(data[:4]))
+	}
+
+	var (
+		loose     *Entry
+		looseArgs []interface{}
+	)
+	payload := data[4:]
+	for _, e := range candidates {
+		args, err := e.Arguments().Unpack(payload)
+		if err != nil {
+			continue
+		}
+		// 严格匹配：重新编码后与原数据一致，说明没有多余或错位的字节
+		if packed, err := e.Arguments().Pack(args...); err == nil && bytes.Equal(packed, payload) {
+			return e, args, nil
+		}
+		if loose == nil {
+			loose, looseArgs = e, args
+		}
+	}
+	if loose != nil {
+		return loose, looseArgs, nil
+	}
+	return nil, nil, fmt.Errorf("%w: %s 有 %d 个候选签名，均无法解码", ErrNoMatch, hexutil.Encode(data[:4]), len(candidates))
+}
+
+// canUnpack 检查 data 是否能按给定参数解码
+func canUnpack(args abi.Arguments, data []byte) bool {
+	if len(args) == 0 {
+		return len(data) == 0
+	}
+	_, err := args.Unpack(data)
+	return err == nil
+}
+
+// preferNamed 返回副本，带参数名的签名排在前面，其余保持加入顺序
+func preferNamed(list []*Entry) []*Entry {
+	out := append([]*Entry(nil), list...)
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Named() && !out[j].Named()
+	})
+	return out
+}
+
+func selector4(b []byte) [4]byte {
+	var sel [4]byte
+	copy(sel[:], b)
+	return sel
+}
@@ -0,0 +1,232 @@
+package sigdb_test
+
+import (
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/dapp-learning/ethclient/util/sigdb"
+)
+
+// burn(uint256) 与 collate_propagate_storage(bytes16) 的选择器都是 0x42966c68
+const (
+	burnSig    = "burn(uint256)"
+	collideSig = "collate_propagate_storage(bytes16)"
+)
+
+func word(v int64) []byte { return common.LeftPadBytes(big.NewInt(v).Bytes(), 32) }
+
+func calldata(selector string, words ...[]byte) []byte {
+	data := hexutil.MustDecode(selector)
+	for _, w := range words {
+		data = append(data, w...)
+	}
+	return data
+}
+
+func newRegistry(t *testing.T, sigs ...string) *sigdb.Registry {
+	t.Helper()
+	r := sigdb.New()
+	for _, sig := range sigs {
+		if err := r.AddSignature("test", sig); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return r
+}
+
+func TestSelectorCollision(t *testing.T) {
+	// 冲突的签名先加入，仍然应按数据挑出 burn
+	r := newRegistry(t, collideSig, burnSig)
+	if n := len(r.Functions(hexutil.MustDecode("0x42966c68"))); n != 2 {
+		t.Fatalf("0x42966c68 有 %d 个候选签名，期望 2", n)
+	}
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		// 低位有值：按 bytes16 解码后重新编码会丢掉低 16 字节，只有 burn 严格匹配
+		{"strict", calldata("0x42966c68", word(1)), burnSig},
+		// 多出一个字：两个签名都不能严格匹配，退回第一个能解码的候选
+		{"loose", calldata("0x42966c68", word(1), word(2)), collideSig},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, args, err := r.ResolveFunction(tt.data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if e.Signature != tt.want {
+				t.Fatalf("解析为 %s，期望 %s", e.Signature, tt.want)
+			}
+			if len(args) != 1 {
+				t.Fatalf("解码出 %d 个参数，期望 1", len(args))
+			}
+		})
+	}
+
+	if _, _, err := r.ResolveFunction(calldata("0x42966c68")); !errors.Is(err, sigdb.ErrNoMatch) {
+		t.Fatalf("数据不足时返回 %v，期望 %v", err, sigdb.ErrNoMatch)
+	}
+	if _, _, err := r.ResolveFunction(calldata("0xdeadbeef", word(1))); !errors.Is(err, sigdb.ErrNotFound) {
+		t.Fatalf("未知选择器返回 %v，期望 %v", err, sigdb.ErrNotFound)
+	}
+}
+
+func TestNamedReplacesUnnamed(t *testing.T) {
+	r := newRegistry(t, "transfer(address,uint256)")
+	unnamed := r.Entries()[0]
+
+	abiJSON := `[{"type":"function","name":"transfer","inputs":[{"name":"to","type":"address"},{"name":"amount","type":"uint256"}],"outputs":[{"name":"","type":"bool"}],"stateMutability":"nonpayable"}]`
+	n, err := r.LoadABI("erc20.json", strings.NewReader(abiJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 || r.Len() != 1 {
+		t.Fatalf("新增 %d 条，共 %d 条，期望带参数名的版本替换原来的一条", n, r.Len())
+	}
+	named := r.Entries()[0]
+	if !named.Named() || named.Source != "erc20.json" {
+		t.Fatalf("替换后的签名 %+v 应带参数名，来源为 erc20.json", named)
+	}
+	if got := r.Functions(hexutil.MustDecode("0xa9059cbb")); len(got) != 1 || got[0] != named {
+		t.Fatal("按选择器查询应返回新的签名")
+	}
+	// 之前取出的指针保持原样，不会在读取时被改写
+	if unnamed == named || unnamed.Named() || unnamed.Source != "test" {
+		t.Fatalf("旧的签名被就地修改: %+v", unnamed)
+	}
+
+	// 不带参数名的版本不会替换带参数名的版本
+	if err := r.AddSignature("later", "transfer(address,uint256)"); err != nil {
+		t.Fatal(err)
+	}
+	if e := r.Entries()[0]; e != named {
+		t.Fatalf("不带参数名的签名替换了 %s", e.Source)
+	}
+}
+
+func TestExtractABI(t *testing.T) {
+	const abiArray = `[{"type":"function","name":"getItem","inputs":[{"name":"key","type":"bytes32"}],"outputs":[{"name":"","type":"bytes32"}],"stateMutability":"view"}]`
+	tests := []struct {
+		name    string
+		raw     string
+		wantErr bool
+	}{
+		{"abi", "\n  " + abiArray, false},
+		{"hardhat", `{"_format":"hh-sol-artifact-1","contractName":"Store","abi":` + abiArray + `,"bytecode":"0x6080"}`, false},
+		{"foundry", `{"abi":` + abiArray + `,"bytecode":{"object":"0x6080"},"deployedBytecode":{"object":"0x6080"}}`, false},
+		{"combined-json", `{"abi":` + strconv.Quote(abiArray) + `,"bin":"6080"}`, false},
+		{"no abi", `{"bytecode":"0x6080"}`, true},
+		{"invalid", `0x6080`, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := sigdb.ExtractABI([]byte(tt.raw))
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("期望返回错误")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := sigdb.New()
+			if n, err := r.LoadABI(tt.name, strings.NewReader(string(raw))); err != nil || n != 1 {
+				t.Fatalf("加载了 %d 条签名 (%v)，期望 1", n, err)
+			}
+			if sig := r.Entries()[0].Signature; sig != "getItem(bytes32)" {
+				t.Fatalf("签名为 %s", sig)
+			}
+		})
+	}
+}
+
+func TestSaveLoadDB(t *testing.T) {
+	r := newRegistry(t,
+		"function transfer(address to, uint256 amount) returns (bool)",
+		"event Transfer(address indexed from, address indexed to, uint256 value)",
+		"event Transfer(address indexed from, address indexed to, uint256 indexed tokenId)",
+		"event Anon(uint256 indexed a, bytes data) anonymous",
+		"error InsufficientBalance(uint256 available, uint256 required)",
+		"swap((address,uint256)[],bytes)",
+	)
+	path := filepath.Join(t.TempDir(), "sigs.json")
+	if err := r.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := sigdb.New()
+	if n, err := loaded.LoadFile(path); err != nil || n != r.Len() {
+		t.Fatalf("读回 %d 条签名 (%v)，期望 %d", n, err, r.Len())
+	}
+	want, got := r.Entries(), loaded.Entries()
+	for i := range want {
+		if got[i].Kind != want[i].Kind || got[i].Signature != want[i].Signature || got[i].Selector != want[i].Selector ||
+			got[i].Anonymous != want[i].Anonymous || got[i].Named() != want[i].Named() || got[i].IndexedCount() != want[i].IndexedCount() {
+			t.Errorf("第 %d 条: 读回 %+v，期望 %+v", i, got[i], want[i])
+		}
+	}
+	// 读回的签名可以直接解码
+	if e, _, err := loaded.ResolveFunction(calldata("0xa9059cbb", word(0xbeef), word(7))); err != nil || e.Name != "transfer" {
+		t.Fatalf("读回的数据库无法解码 transfer: %v", err)
+	}
+
+	// 再次合并同一个文件不会新增
+	if n, err := loaded.LoadDB(path); err != nil || n != 0 {
+		t.Fatalf("重复合并新增了 %d 条 (%v)", n, err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Replace(string(raw), `"version": 1`, `"version": 2`, 1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := sigdb.New().LoadDB(path); err == nil {
+		t.Fatal("不支持的版本应返回错误")
+	}
+}
+
+func TestOutputs(t *testing.T) {
+	r := newRegistry(t,
+		"function getReserves() view returns (uint112 reserve0, uint112 reserve1, uint32 blockTimestampLast)",
+		"balanceOf(address)",
+	)
+	e, _, err := r.ResolveFunction(hexutil.MustDecode("0x0902f1ac"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outputs := e.Method().Outputs; len(outputs) != 3 || outputs[2].Name != "blockTimestampLast" || outputs[2].Type.String() != "uint32" {
+		t.Fatalf("getReserves 的返回值为 %v", outputs)
+	}
+
+	// 参数名相同时，ABI 中带返回值的版本替换文本签名
+	abiJSON := `[{"type":"function","name":"balanceOf","inputs":[{"name":"","type":"address"}],"outputs":[{"name":"","type":"uint256"}],"stateMutability":"view"}]`
+	if n, err := r.LoadABI("erc20.json", strings.NewReader(abiJSON)); err != nil || n != 1 {
+		t.Fatalf("新增 %d 条 (%v)，期望替换 balanceOf", n, err)
+	}
+	e, _, err = r.ResolveFunction(calldata("0x70a08231", word(1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := e.Method().Outputs.Unpack(word(42))
+	if err != nil || len(values) != 1 || values[0].(*big.Int).Int64() != 42 {
+		t.Fatalf("解码返回值 %v (%v)", values, err)
+	}
+
+	if err := r.AddSignature("test", "function f() returns bool"); err == nil {
+		t.Fatal("returns 后没有括号应返回错误")
+	}
+}
@@ -0,0 +1,164 @@
+package contracts
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// asm 一个只够写示例合约的 EVM 汇编器：按顺序追加指令，跳转目标用标签表示，
+// assemble 时把标签替换为实际偏移
+type asm struct {
+	code   []byte
+	labels map[string]int
+	refs   map[int]string // PUSH2 立即数的位置 → 标签
+}
+
+func newAsm() *asm {
+	return &asm{labels: make(map[string]int), refs: make(map[int]string)}
+}
+
+// op 追加不带立即数的指令
+func (a *asm) op(ops ...vm.OpCode) *asm {
+	for _, op := range ops {
+		a.code = append(a.code, byte(op))
+	}
+	return a
+}
+
+// push 用最短的 PUSHn 压入一个整数或字节串
+func (a *asm) push(v interface{}) *asm {
+	var b []byte
+	switch x := v.(type) {
+	case int:
+		b = new(big.Int).SetInt64(int64(x)).Bytes()
+	case uint64:
+		b = new(big.Int).SetUint64(x).Bytes()
+	case *big.Int:
+		b = x.Bytes()
+	case []byte:
+		b = x
+	default:
+		panic(fmt.Sprintf("asm: 不支持的立即数类型 %T", v))
+	}
+	if len(b) == 0 {
+		return a.op(vm.PUSH0)
+	}
+	if len(b) > 32 {
+		panic("asm: 立即数超过 32 字节")
+	}
+	a.code = append(a.code, byte(vm.PUSH1)+byte(len(b)-1))
+	a.code = append(a.code, b...)
+	return a
+}
+
+// pushLabel 压入标签的偏移（固定使用 PUSH2）
+func (a *asm) pushLabel(name string) *asm {
+	a.code = append(a.code, byte(vm.PUSH2))
+	a.refs[len(a.code)] = name
+	a.code = append(a.code, 0, 0)
+	return a
+}
+
+// label 定义跳转目标（JUMPDEST）
+func (a *asm) label(name string) *asm {
+	a.mark(name)
+	return a.op(vm.JUMPDEST)
+}
+
+// mark 记录当前位置但不插入 JUMPDEST，用于定位附加的数据
+func (a *asm) mark(name string) *asm {
+	if _, ok := a.labels[name]; ok {
+		panic("asm: 重复的标签 " + name)
+	}
+	a.labels[name] = len(a.code)
+	return a
+}
+
+func (a *asm) jump(name string) *asm  { return a.pushLabel(name).op(vm.JUMP) }
+func (a *asm) jumpi(name string) *asm { return a.pushLabel(name).op(vm.JUMPI) }
+
+// data 追加原始字节（不会被执行）
+func (a *asm) data(b []byte) *asm {
+	a.code = append(a.code, b...)
+	return a
+}
+
+// assemble 回填标签并返回字节码
+func (a *asm) assemble() []byte {
+	code := append([]byte(nil), a.code...)
+	for pos, name := range a.refs {
+		offset, ok := a.labels[name]
+		if !ok {
+			panic("asm: 未定义的标签 " + name)
+		}
+		code[pos], code[pos+1] = byte(offset>>8), byte(offset)
+	}
+	return code
+}
+
+// 以下是合约中反复用到的指令序列
+
+// mappingSlot 栈顶的 key → keccak256(key . slot)，即 Solidity mapping 在 slot 处的存储位置
+func (a *asm) mappingSlot(slot int) *asm {
+	a.push(0).op(vm.MSTORE)
+	a.push(slot).push(0x20).op(vm.MSTORE)
+	return a.push(0x40).push(0).op(vm.KECCAK256)
+}
+
+// returnWord 返回栈顶的 32 字节
+func (a *asm) returnWord() *asm {
+	a.push(0).op(vm.MSTORE)
+	return a.push(0x20).push(0).op(vm.RETURN)
+}
+
+// returnString 按 ABI 编码返回不超过 32 字节的常量字符串
+func (a *asm) returnString(s string) *asm {
+	a.push(0x20).push(0).op(vm.MSTORE)
+	a.push(len(s)).push(0x20).op(vm.MSTORE)
+	a.push(padRight([]byte(s))).push(0x40).op(vm.MSTORE)
+	return a.push(0x60).push(0).op(vm.RETURN)
+}
+
+// revertWith 以 Error(string) 回滚，msg 不超过 32 字节
+func (a *asm) revertWith(msg string) *asm {
+	a.push(padRight([]byte{0x08, 0xc3, 0x79, 0xa0})).push(0).op(vm.MSTORE)
+	a.push(0x20).push(0x04).op(vm.MSTORE)
+	a.push(len(msg)).push(0x24).op(vm.MSTORE)
+	a.push(padRight([]byte(msg))).push(0x44).op(vm.MSTORE)
+	return a.push(0x64).push(0).op(vm.REVERT)
+}
+
+// loadAddress 读取 calldata 中 offset 处的地址参数，并清除高 12 字节
+func (a *asm) loadAddress(offset int) *asm {
+	return a.push(offset).op(vm.CALLDATALOAD).mask(160)
+}
+
+// mask 只保留栈顶的低 bits 位
+func (a *asm) mask(bits uint) *asm {
+	return a.push(new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), bits), big.NewInt(1))).op(vm.AND)
+}
+
+// mstoreAt 把栈顶写入内存 addr。合约较复杂时用固定的内存位置保存局部变量，避免深层的栈操作
+func (a *asm) mstoreAt(addr int) *asm { return a.push(addr).op(vm.MSTORE) }
+
+// mloadAt 读取内存 addr 处的 32 字节
+func (a *asm) mloadAt(addr int) *asm { return a.push(addr).op(vm.MLOAD) }
+
+// deployRuntime 构造函数的结尾：把附加在 initcode 中的运行时代码复制到内存并返回
+func (a *asm) deployRuntime(runtime []byte) *asm {
+	a.push(len(runtime)).op(vm.DUP1).pushLabel("runtime").push(0).op(vm.CODECOPY)
+	a.push(0).op(vm.RETURN)
+	a.mark("runtime").data(runtime)
+	return a.mark("end")
+}
+
+func padRight(b []byte) []byte {
+	if len(b) > 32 {
+		panic("asm: 超过 32 字节")
+	}
+	out := make([]byte, 32)
+	copy(out, b)
+	return out
+}
@@ -0,0 +1,299 @@
+// Package contracts 提供模拟链上使用的示例合约：Store、一个最小的 ERC20、
+// 把调用原样转发给另一个合约的 Forwarder，供 EIP-7702 委托使用的批量调用合约 Batch，
+// ERC-4337 的最小实现 EntryPoint、SimpleAccount 和账户工厂（见 entrypoint.go），
+// 以及多签钱包 Safe（见 safe.go）。
+//
+// Store 使用 2.09 提交的 solc 0.8.30 编译产物（见 store.go），与课程在测试网上部署的字节码完全相同；
+// Safe 嵌入了正式的 v1.3.0 单例合约和 v1.4.1 代理工厂的字节码，来源见 safe.go。
+// 其余合约的字节码由 asm.go 中的小型汇编器直接生成，不依赖 solc，因此离线环境也能部署。
+// ABI 与存储布局和 Solidity 版本一致：
+//
+//	Token  余额映射在 slot 0，授权映射在 slot 1，totalSupply 在 slot 2
+//
+// Token 只实现了课程中用到的功能：name / symbol / decimals 为常量，总量在部署时全部发给部署者。
+package contracts
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// TokenABI 标准 ERC20 接口，构造参数为初始总量
+const TokenABI = `[{"inputs":[{"internalType":"uint256","name":"initialSupply","type":"uint256"}],"stateMutability":"nonpayable","type":"constructor"},{"anonymous":false,"inputs":[{"indexed":true,"internalType":"address","name":"owner","type":"address"},{"indexed":true,"internalType":"address","name":"spender","type":"address"},{"indexed":false,"internalType":"uint256","name":"value","type":"uint256"}],"name":"Approval","type":"event"},{"anonymous":false,"inputs":[{"indexed":true,"internalType":"address","name":"from","type":"address"},{"indexed":true,"internalType":"address","name":"to","type":"address"},{"indexed":false,"internalType":"uint256","name":"value","type":"uint256"}],"name":"Transfer","type":"event"},{"inputs":[{"internalType":"address","name":"owner","type":"address"},{"internalType":"address","name":"spender","type":"address"}],"name":"allowance","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address","name":"spender","type":"address"},{"internalType":"uint256","name":"value","type":"uint256"}],"name":"approve","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"address","name":"account","type":"address"}],"name":"balanceOf","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"decimals","outputs":[{"internalType":"uint8","name":"","type":"uint8"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"name","outputs":[{"internalType":"string","name":"","type":"string"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"symbol","outputs":[{"internalType":"string","name":"","type":"string"}],"stateMutability":"view","type":"function"},{"inputs":[],"name":"totalSupply","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},{"inputs":[{"internalType":"address","name":"to","type":"address"},{"internalType":"uint256","name":"value","type":"uint256"}],"name":"transfer","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"nonpayable","type":"function"},{"inputs":[{"internalType":"address","name":"from","type":"address"},{"internalType":"address","name":"to","type":"address"},{"internalType":"uint256","name":"value","type":"uint256"}],"name":"transferFrom","outputs":[{"internalType":"bool","name":"","type":"bool"}],"stateMutability":"nonpayable","type":"function"}]`
+
+// Token 的常量属性
+const (
+	TokenName     = "Simulated Token"
+	TokenSymbol   = "SIM"
+	TokenDecimals = 18
+)
+
+// Token 回滚原因
+const (
+	ErrInsufficientBalance   = "ERC20: insufficient balance"
+	ErrInsufficientAllowance = "ERC20: insufficient allowance"
+	ErrInvalidReceiver       = "ERC20: invalid receiver"
+)
+
+// BatchABI 批量调用合约的接口，编码与 Safe 的 MultiSendCallOnly 相同
+const BatchABI = `[{"inputs":[{"internalType":"bytes","name":"transactions","type":"bytes"}],"name":"multiSend","outputs":[],"stateMutability":"nonpayable","type":"function"}]`
+
+// Batch 回滚原因。子调用失败时原样返回子调用的 revert 数据
+const (
+	ErrNotSelf     = "Batch: caller is not self"
+	ErrDelegateOps = "Batch: only CALL supported"
+)
+
+var (
+	storeABI = mustParseABI(StoreABI)
+	tokenABI = mustParseABI(TokenABI)
+	batchABI = mustParseABI(BatchABI)
+
+	storeInitCode = common.FromHex(strings.TrimSpace(storeBin))
+	tokenInitCode = buildToken()
+	batchInitCode = buildBatch()
+)
+
+// StoreDeployData Store 的部署数据（initcode + 构造参数）
+func StoreDeployData(version string) ([]byte, error) {
+	args, err := storeABI.Pack("", version)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, storeInitCode...), args...), nil
+}
+
+// TokenDeployData Token 的部署数据，initialSupply 以最小单位计
+func TokenDeployData(initialSupply *big.Int) ([]byte, error) {
+	args, err := tokenABI.Pack("", initialSupply)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, tokenInitCode...), args...), nil
+}
+
+// StoreInitCode Store 不含构造参数的 initcode
+func StoreInitCode() []byte { return append([]byte{}, storeInitCode...) }
+
+// TokenInitCode Token 不含构造参数的 initcode
+func TokenInitCode() []byte { return append([]byte{}, tokenInitCode...) }
+
+// BatchDeployData Batch 的部署数据，没有构造参数
+func BatchDeployData() []byte { return append([]byte{}, batchInitCode...) }
+
+// Call Batch 中的一次调用
+type Call struct {
+	To    common.Address
+	Value *big.Int // 为 nil 时不转 ETH
+	Data  []byte
+}
+
+// BatchData 编码 multiSend(bytes) 的调用数据。每次调用按 MultiSend 的紧凑格式拼接：
+// operation（1 字节，0 = CALL）| to（20 字节）| value（32 字节）| data 长度（32 字节）| data
+func BatchData(calls []Call) ([]byte, error) {
+	var packed []byte
+	for _, c := range calls {
+		value := c.Value
+		if value == nil {
+			value = new(big.Int)
+		}
+		packed = append(packed, 0)
+		packed = append(packed, c.To.Bytes()...)
+		packed = append(packed, common.LeftPadBytes(value.Bytes(), 32)...)
+		packed = append(packed, common.LeftPadBytes(big.NewInt(int64(len(c.Data))).Bytes(), 32)...)
+		packed = append(packed, c.Data...)
+	}
+	return batchABI.Pack("multiSend", packed)
+}
+
+// ForwarderDeployData 转发合约的部署数据。部署后对它的任何调用都会带着原 calldata
+// 和 ETH 转发给 target，并原样返回 target 的返回值或 revert 数据；
+// 对 target 来说 msg.sender 是转发合约，可以用来演示多合约调用
+func ForwarderDeployData(target common.Address) []byte {
+	rt := newAsm()
+	rt.op(vm.CALLDATASIZE).push(0).push(0).op(vm.CALLDATACOPY)
+	rt.push(0).push(0).op(vm.CALLDATASIZE).push(0).op(vm.CALLVALUE).push(target.Bytes()).op(vm.GAS, vm.CALL)
+	rt.op(vm.RETURNDATASIZE).push(0).push(0).op(vm.RETURNDATACOPY)
+	rt.jumpi("ok")
+	rt.op(vm.RETURNDATASIZE).push(0).op(vm.REVERT)
+	rt.label("ok").op(vm.RETURNDATASIZE).push(0).op(vm.RETURN)
+
+	ctor := newAsm()
+	ctor.deployRuntime(rt.assemble())
+	return ctor.assemble()
+}
+
+// buildToken 一个最小的 ERC20，行为与 OpenZeppelin ERC20 一致（授权为最大值时不扣减）
+func buildToken() []byte {
+	rt := newAsm()
+	dispatch(rt, tokenABI, "name", "symbol", "decimals", "totalSupply", "balanceOf", "allowance", "transfer", "approve", "transferFrom")
+
+	rt.label("name").returnString(TokenName)
+	rt.label("symbol").returnString(TokenSymbol)
+	rt.label("decimals").push(TokenDecimals).returnWord()
+	rt.label("totalSupply").push(2).op(vm.SLOAD).returnWord()
+
+	rt.label("balanceOf").op(vm.POP)
+	rt.loadAddress(4).mappingSlot(0).op(vm.SLOAD).returnWord()
+
+	rt.label("allowance").op(vm.POP)
+	rt.loadAddress(4).loadAddress(0x24) // [owner, spender]
+	allowanceSlot(rt)
+	rt.op(vm.SLOAD).returnWord()
+
+	// transfer(to, value)
+	rt.label("transfer").op(vm.POP)
+	rt.push(0x24).op(vm.CALLDATALOAD).loadAddress(4).op(vm.CALLER) // [value, to, from]
+	transferBody(rt)
+	rt.push(1).returnWord()
+
+	// approve(spender, value)
+	rt.label("approve").op(vm.POP)
+	rt.push(0x24).op(vm.CALLDATALOAD).loadAddress(4).op(vm.CALLER) // [value, spender, owner]
+	rt.op(vm.DUP2, vm.DUP2, vm.SWAP1)                              // [..., owner, spender]
+	allowanceSlot(rt)                                              // [value, spender, owner, slot]
+	rt.op(vm.DUP4, vm.SWAP1, vm.SSTORE)                            // allowance[owner][spender] = value
+	rt.op(vm.DUP3).push(0).op(vm.MSTORE)
+	rt.push(tokenABI.Events["Approval"].ID.Bytes()).push(0x20).push(0).op(vm.LOG3)
+	rt.op(vm.POP).push(1).returnWord()
+
+	// transferFrom(from, to, value)
+	rt.label("transferFrom").op(vm.POP)
+	rt.push(0x44).op(vm.CALLDATALOAD).loadAddress(0x24).loadAddress(4) // [value, to, from]
+	rt.op(vm.DUP1, vm.CALLER)
+	allowanceSlot(rt)        // [value, to, from, slot]
+	rt.op(vm.DUP1, vm.SLOAD) // [..., slot, allowed]
+	rt.op(vm.DUP1).push(0).op(vm.NOT, vm.EQ).jumpi("unlimited")
+	rt.op(vm.DUP5, vm.DUP2, vm.LT).jumpi("insufficientAllowance")
+	rt.op(vm.DUP5, vm.SWAP1, vm.SUB) // [..., slot, allowed-value]
+	rt.op(vm.SWAP1, vm.SSTORE)       // [value, to, from]
+	rt.jump("doTransferFrom")
+	rt.label("unlimited").op(vm.POP, vm.POP)
+	rt.label("doTransferFrom")
+	transferBody(rt)
+	rt.push(1).returnWord()
+
+	rt.label("revert").push(0).push(0).op(vm.REVERT)
+	rt.label("insufficientBalance").revertWith(ErrInsufficientBalance)
+	rt.label("insufficientAllowance").revertWith(ErrInsufficientAllowance)
+	rt.label("invalidReceiver").revertWith(ErrInvalidReceiver)
+
+	ctor := newAsm()
+	ctor.op(vm.CALLVALUE).jumpi("revert")
+	ctor.push(0x20).pushLabel("end").push(0).op(vm.CODECOPY)
+	ctor.push(0).op(vm.MLOAD)                                // [supply]
+	ctor.op(vm.DUP1).push(2).op(vm.SSTORE)                   // totalSupply = supply
+	ctor.op(vm.DUP1, vm.CALLER).mappingSlot(0).op(vm.SSTORE) // balances[msg.sender] = supply
+	ctor.push(0).op(vm.MSTORE)
+	ctor.op(vm.CALLER).push(0).push(tokenABI.Events["Transfer"].ID.Bytes()).push(0x20).push(0).op(vm.LOG3)
+	ctor.jump("deploy")
+	ctor.label("revert").push(0).push(0).op(vm.REVERT)
+	ctor.label("deploy").deployRuntime(rt.assemble())
+	return ctor.assemble()
+}
+
+// buildBatch 批量调用合约，供 EOA 通过 EIP-7702 委托使用：
+//
+//	function multiSend(bytes transactions) external {
+//	    require(msg.sender == address(this));
+//	    // 依次 CALL 每一笔，任何一笔失败时整体回滚并返回它的 revert 数据
+//	}
+//	receive() external payable {}
+//
+// 代码在被委托账户的上下文中执行，address(this) 就是 EOA 本身，
+// 所以只有 EOA 自己发给自己的交易能触发批量调用，其他人只能向它转 ETH
+func buildBatch() []byte {
+	rt := newAsm()
+	// 空 calldata：和普通 EOA 一样接收 ETH
+	rt.op(vm.CALLDATASIZE).jumpi("dispatch").op(vm.STOP)
+	rt.label("dispatch")
+	dispatch(rt, batchABI, "multiSend")
+
+	rt.label("multiSend").op(vm.POP)
+	rt.op(vm.ADDRESS, vm.CALLER, vm.EQ, vm.ISZERO).jumpi("notSelf")
+	// bytes 参数：偏移在 0x04，偏移处是长度，之后是数据
+	rt.push(4).op(vm.CALLDATALOAD).push(4).op(vm.ADD) // [p]
+	rt.op(vm.DUP1, vm.CALLDATALOAD)                   // [p, len]
+	rt.op(vm.DUP2).push(0x20).op(vm.ADD)              // [p, len, i]
+	rt.op(vm.SWAP2, vm.POP)                           // [i, len]
+	rt.op(vm.DUP2, vm.ADD, vm.SWAP1)                  // [end, i]
+
+	rt.label("loop")
+	rt.op(vm.DUP2, vm.DUP2, vm.LT, vm.ISZERO).jumpi("done")
+	rt.op(vm.DUP1, vm.CALLDATALOAD).push(0xf8).op(vm.SHR).jumpi("delegateOps")
+	rt.op(vm.DUP1).push(1).op(vm.ADD, vm.CALLDATALOAD).push(0x60).op(vm.SHR) // [end, i, to]
+	rt.op(vm.DUP2).push(0x15).op(vm.ADD, vm.CALLDATALOAD)                    // [end, i, to, value]
+	rt.op(vm.DUP3).push(0x35).op(vm.ADD, vm.CALLDATALOAD)                    // [end, i, to, value, n]
+	// 把 data 复制到内存 0
+	rt.op(vm.DUP1, vm.DUP5).push(0x55).op(vm.ADD).push(0).op(vm.CALLDATACOPY)
+	// call(gas, to, value, 0, n, 0, 0)
+	rt.push(0).push(0).op(vm.DUP3).push(0).op(vm.DUP6, vm.DUP8, vm.GAS, vm.CALL)
+	rt.op(vm.ISZERO).jumpi("failed")
+	rt.push(0x55).op(vm.ADD, vm.SWAP2, vm.POP, vm.POP, vm.ADD) // [end, i + 0x55 + n]
+	rt.jump("loop")
+	rt.label("done").op(vm.STOP)
+
+	rt.label("failed")
+	rt.op(vm.RETURNDATASIZE).push(0).push(0).op(vm.RETURNDATACOPY)
+	rt.op(vm.RETURNDATASIZE).push(0).op(vm.REVERT)
+	rt.label("revert").push(0).push(0).op(vm.REVERT)
+	rt.label("notSelf").revertWith(ErrNotSelf)
+	rt.label("delegateOps").revertWith(ErrDelegateOps)
+
+	ctor := newAsm()
+	ctor.deployRuntime(rt.assemble())
+	return ctor.assemble()
+}
+
+// dispatch 函数分发：按 4 字节选择器跳转到同名标签，跳转时栈上保留选择器。
+// 函数都不是 payable，带 ETH 或 calldata 不足 4 字节时直接回滚
+func dispatch(a *asm, parsed abi.ABI, methods ...string) {
+	a.op(vm.CALLVALUE).jumpi("revert")
+	selectors(a, parsed, methods...)
+}
+
+// selectors 与 dispatch 相同，但不检查 CALLVALUE，有 payable 函数的合约由各函数自己检查
+func selectors(a *asm, parsed abi.ABI, methods ...string) {
+	a.push(4).op(vm.CALLDATASIZE, vm.LT).jumpi("revert")
+	a.push(0).op(vm.CALLDATALOAD).push(0xe0).op(vm.SHR)
+	for _, name := range methods {
+		a.op(vm.DUP1).push(parsed.Methods[name].ID).op(vm.EQ).jumpi(name)
+	}
+	a.jump("revert")
+}
+
+// allowanceSlot [owner, spender] → keccak256(spender . keccak256(owner . 1))
+func allowanceSlot(a *asm) {
+	a.op(vm.SWAP1).mappingSlot(1) // [spender, inner]
+	a.push(0x20).op(vm.MSTORE)
+	a.push(0).op(vm.MSTORE)
+	a.push(0x40).push(0).op(vm.KECCAK256)
+}
+
+// transferBody [value, to, from] → []，余额不足或接收方为零地址时回滚
+func transferBody(a *asm) {
+	a.op(vm.DUP2, vm.ISZERO).jumpi("invalidReceiver")
+	a.op(vm.DUP1).mappingSlot(0) // [value, to, from, fromSlot]
+	a.op(vm.DUP1, vm.SLOAD)      // [..., fromSlot, fromBalance]
+	a.op(vm.DUP5, vm.DUP2, vm.LT).jumpi("insufficientBalance")
+	a.op(vm.DUP5, vm.SWAP1, vm.SUB) // [..., fromSlot, fromBalance-value]
+	a.op(vm.SWAP1, vm.SSTORE)       // [value, to, from]
+	a.op(vm.DUP2).mappingSlot(0)    // [value, to, from, toSlot]
+	a.op(vm.DUP1, vm.SLOAD, vm.DUP5, vm.ADD)
+	a.op(vm.SWAP1, vm.SSTORE) // [value, to, from]
+	a.op(vm.DUP3).push(0).op(vm.MSTORE)
+	a.push(tokenABI.Events["Transfer"].ID.Bytes()).push(0x20).push(0).op(vm.LOG3)
+	a.op(vm.POP)
+}
+
+func mustParseABI(s string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
@@ -0,0 +1,506 @@
+package contracts
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// packedUserOpComponents EntryPoint v0.7 的 PackedUserOperation 结构的字段
+const packedUserOpComponents = `[{"internalType":"address","name":"sender","type":"address"},{"internalType":"uint256","name":"nonce","type":"uint256"},{"internalType":"bytes","name":"initCode","type":"bytes"},{"internalType":"bytes","name":"callData","type":"bytes"},{"internalType":"bytes32","name":"accountGasLimits","type":"bytes32"},{"internalType":"uint256","name":"preVerificationGas","type":"uint256"},{"internalType":"bytes32","name":"gasFees","type":"bytes32"},{"internalType":"bytes","name":"paymasterAndData","type":"bytes"},{"internalType":"bytes","name":"signature","type":"bytes"}]`
+
+// packedUserOpABI PackedUserOperation 类型的 userOp 参数
+const packedUserOpABI = `{"components":` + packedUserOpComponents + `,"internalType":"struct PackedUserOperation","name":"userOp","type":"tuple"}`
+
+// EntryPointABI ERC-4337 EntryPoint v0.7 中模拟链实现的部分，函数和事件签名与正式部署的合约相同
+const EntryPointABI = `[` +
+	`{"anonymous":false,"inputs":[{"indexed":true,"internalType":"address","name":"account","type":"address"},{"indexed":false,"internalType":"uint256","name":"totalDeposit","type":"uint256"}],"name":"Deposited","type":"event"},` +
+	`{"anonymous":false,"inputs":[{"indexed":true,"internalType":"bytes32","name":"userOpHash","type":"bytes32"},{"indexed":true,"internalType":"address","name":"sender","type":"address"},{"indexed":true,"internalType":"address","name":"paymaster","type":"address"},{"indexed":false,"internalType":"uint256","name":"nonce","type":"uint256"},{"indexed":false,"internalType":"bool","name":"success","type":"bool"},{"indexed":false,"internalType":"uint256","name":"actualGasCost","type":"uint256"},{"indexed":false,"internalType":"uint256","name":"actualGasUsed","type":"uint256"}],"name":"UserOperationEvent","type":"event"},` +
+	`{"anonymous":false,"inputs":[{"indexed":true,"internalType":"bytes32","name":"userOpHash","type":"bytes32"},{"indexed":true,"internalType":"address","name":"sender","type":"address"},{"indexed":false,"internalType":"uint256","name":"nonce","type":"uint256"},{"indexed":false,"internalType":"bytes","name":"revertReason","type":"bytes"}],"name":"UserOperationRevertReason","type":"event"},` +
+	`{"inputs":[{"internalType":"address","name":"account","type":"address"}],"name":"balanceOf","outputs":[{"internalType":"uint256","name":"","type":"uint256"}],"stateMutability":"view","type":"function"},` +
+	`{"inputs":[{"internalType":"address","name":"account","type":"address"}],"name":"depositTo","outputs":[],"stateMutability":"payable","type":"function"},` +
+	`{"inputs":[{"internalType":"address","name":"sender","type":"address"},{"internalType":"uint192","name":"key","type":"uint192"}],"name":"getNonce","outputs":[{"internalType":"uint256","name":"nonce","type":"uint256"}],"stateMutability":"view","type":"function"},` +
+	`{"inputs":[` + packedUserOpABI + `],"name":"getUserOpHash","outputs":[{"internalType":"bytes32","name":"","type":"bytes32"}],"stateMutability":"view","type":"function"},` +
+	`{"inputs":[{"components":` + packedUserOpComponents + `,"internalType":"struct PackedUserOperation[]","name":"ops","type":"tuple[]"},{"internalType":"address payable","name":"beneficiary","type":"address"}],"name":"handleOps","outputs":[],"stateMutability":"nonpayable","type":"function"},` +
+	`{"stateMutability":"payable","type":"receive"}]`
+
+// SimpleAccountABI 最小的 ERC-4337 账户：owner 的签名通过验证，EntryPoint 或 owner 可以让它执行调用
+const SimpleAccountABI = `[` +
+	`{"inputs":[{"internalType":"address","name":"anEntryPoint","type":"address"},{"internalType":"address","name":"anOwner","type":"address"}],"stateMutability":"nonpayable","type":"constructor"},` +
+	`{"inputs":[],"name":"entryPoint","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},` +
+	`{"inputs":[{"internalType":"address","name":"dest","type":"address"},{"internalType":"uint256","name":"value","type":"uint256"},{"internalType":"bytes","name":"func","type":"bytes"}],"name":"execute","outputs":[],"stateMutability":"nonpayable","type":"function"},` +
+	`{"inputs":[],"name":"owner","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"},` +
+	`{"inputs":[` + packedUserOpABI + `,{"internalType":"bytes32","name":"userOpHash","type":"bytes32"},{"internalType":"uint256","name":"missingAccountFunds","type":"uint256"}],"name":"validateUserOp","outputs":[{"internalType":"uint256","name":"validationData","type":"uint256"}],"stateMutability":"nonpayable","type":"function"},` +
+	`{"stateMutability":"payable","type":"receive"}]`
+
+// AccountFactoryABI 用 CREATE2 部署 SimpleAccount 的工厂，地址由 owner 和 salt 决定
+const AccountFactoryABI = `[` +
+	`{"inputs":[{"internalType":"address","name":"anEntryPoint","type":"address"}],"stateMutability":"nonpayable","type":"constructor"},` +
+	`{"inputs":[{"internalType":"address","name":"owner","type":"address"},{"internalType":"uint256","name":"salt","type":"uint256"}],"name":"createAccount","outputs":[{"internalType":"address","name":"ret","type":"address"}],"stateMutability":"nonpayable","type":"function"},` +
+	`{"inputs":[{"internalType":"address","name":"owner","type":"address"},{"internalType":"uint256","name":"salt","type":"uint256"}],"name":"getAddress","outputs":[{"internalType":"address","name":"","type":"address"}],"stateMutability":"view","type":"function"}]`
+
+// EntryPoint 回滚原因，编号与正式合约的 FailedOp 相同。
+// 正式合约以 FailedOp(opIndex, reason) 回滚，这里简化为 Error(reason)
+const (
+	ErrInitCodeFailed   = "AA13 initCode failed or OOG"
+	ErrInitCodeSender   = "AA14 initCode must return sender"
+	ErrAlreadyDeployed  = "AA10 sender already constructed"
+	ErrNotDeployed      = "AA20 account not deployed"
+	ErrPrefund          = "AA21 didn't pay prefund"
+	ErrValidateReverted = "AA23 reverted"
+	ErrSignature        = "AA24 signature error"
+	ErrInvalidNonce     = "AA25 invalid account nonce"
+	ErrNoPaymaster      = "AA30 paymaster not supported"
+	ErrBeneficiary      = "AA91 failed send to beneficiary"
+)
+
+// SimpleAccount 回滚原因
+const (
+	ErrNotEntryPoint = "account: not from EntryPoint"
+	ErrNotAuthorized = "account: not Owner or EntryPoint"
+)
+
+var (
+	entryPointABI     = mustParseABI(EntryPointABI)
+	simpleAccountABI  = mustParseABI(SimpleAccountABI)
+	accountFactoryABI = mustParseABI(AccountFactoryABI)
+
+	entryPointInitCode     = buildEntryPoint()
+	simpleAccountInitCode  = buildSimpleAccount()
+	accountFactoryInitCode = buildAccountFactory()
+)
+
+// EntryPointDeployData EntryPoint 的部署数据，没有构造参数
+func EntryPointDeployData() []byte { return append([]byte{}, entryPointInitCode...) }
+
+// AccountFactoryDeployData 账户工厂的部署数据，工厂创建的账户都信任 entryPoint
+func AccountFactoryDeployData(entryPoint common.Address) []byte {
+	return append(append([]byte{}, accountFactoryInitCode...), common.LeftPadBytes(entryPoint.Bytes(), 32)...)
+}
+
+// SimpleAccountDeployData 直接部署 SimpleAccount 的部署数据
+func SimpleAccountDeployData(entryPoint, owner common.Address) []byte {
+	data := append([]byte{}, simpleAccountInitCode...)
+	data = append(data, common.LeftPadBytes(entryPoint.Bytes(), 32)...)
+	return append(data, common.LeftPadBytes(owner.Bytes(), 32)...)
+}
+
+// AccountAddress 工厂为 owner 和 salt 创建的账户地址，与链上 getAddress 的结果相同。
+// 账户部署之前就可以用这个地址接收 ETH
+func AccountAddress(factory, entryPoint, owner common.Address, salt *big.Int) common.Address {
+	initHash := crypto.Keccak256(SimpleAccountDeployData(entryPoint, owner))
+	return crypto.CreateAddress2(factory, common.BigToHash(salt), initHash)
+}
+
+// CreateAccountData 编码工厂 createAccount(owner, salt) 的调用数据，用作 UserOperation 的 factoryData
+func CreateAccountData(owner common.Address, salt *big.Int) ([]byte, error) {
+	return accountFactoryABI.Pack("createAccount", owner, salt)
+}
+
+// ExecuteData 编码 SimpleAccount execute(dest, value, func) 的调用数据，用作 UserOperation 的 callData
+func ExecuteData(dest common.Address, value *big.Int, data []byte) ([]byte, error) {
+	if value == nil {
+		value = new(big.Int)
+	}
+	return simpleAccountABI.Pack("execute", dest, value, data)
+}
+
+// EntryPoint 运行时保存局部变量的内存位置。0x00 ~ 0xff 留给哈希和事件等临时数据
+const (
+	epIndex     = 0x100 // 当前 UserOperation 的序号
+	epCount     = 0x120 // UserOperation 数量
+	epHeads     = 0x140 // ops 数组偏移表在 calldata 中的位置
+	epOp        = 0x160 // 当前 UserOperation 元组在 calldata 中的位置
+	epHash      = 0x180 // userOpHash
+	epSender    = 0x1a0
+	epPrefund   = 0x1c0 // 预扣的最高费用
+	epGasStart  = 0x1e0
+	epCollected = 0x200 // 累计付给 beneficiary 的费用
+	epSuccess   = 0x220 // callData 是否执行成功
+	epBuf       = 0x400 // 编码调用数据、事件数据
+	epBytes     = 0x500 // 复制 bytes 字段用于计算哈希
+)
+
+// buildEntryPoint 只够演示 UserOperation 完整流程的 EntryPoint v0.7：
+//
+//	receive() / depositTo(account)   给账户存入 ETH，用来支付 UserOperation 的费用
+//	balanceOf(account)               账户的存款
+//	getNonce(sender, key)            key << 64 | 该 key 下的序号
+//	getUserOpHash(userOp)            keccak256(abi.encode(keccak256(pack(userOp)), address(this), chainid))
+//	handleOps(ops, beneficiary)      逐个执行 UserOperation，最后把收取的费用转给 beneficiary
+//
+// handleOps 对每个 UserOperation：检查并递增 nonce；initCode 不为空时调用工厂部署账户；
+// 预扣 (verificationGasLimit + callGasLimit + preVerificationGas) × maxFeePerGas，
+// 存款不足的部分由账户在 validateUserOp 中转入；签名验证通过后以 callGasLimit 调用账户执行 callData，
+// 按实际用量收费，多扣的部分退回存款，并触发 UserOperationEvent。
+// 与正式合约相比省略了 paymaster、聚合签名、验证数据中的有效期以及各阶段的 Gas 上限检查，
+// 验证和执行也没有分成两个循环
+func buildEntryPoint() []byte {
+	rt := newAsm()
+	// 空 calldata：存入 ETH
+	rt.op(vm.CALLDATASIZE).jumpi("dispatch")
+	rt.op(vm.CALLER)
+	deposit(rt)
+	rt.op(vm.STOP)
+	rt.label("dispatch")
+	selectors(rt, entryPointABI, "handleOps", "getUserOpHash", "getNonce", "balanceOf", "depositTo")
+
+	rt.label("depositTo").op(vm.POP)
+	rt.loadAddress(4)
+	deposit(rt)
+	rt.op(vm.STOP)
+
+	rt.label("balanceOf").op(vm.POP)
+	rt.loadAddress(4).mappingSlot(0).op(vm.SLOAD).returnWord()
+
+	rt.label("getNonce").op(vm.POP)
+	rt.op(vm.CALLVALUE).jumpi("revert")
+	rt.push(0x24).op(vm.CALLDATALOAD)                       // [key]
+	rt.op(vm.DUP1).loadAddress(4).op(vm.SWAP1)              // [key, sender, key]
+	nonceSlot(rt)                                           // [key, slot]
+	rt.op(vm.SLOAD)                                         // [key, seq]
+	rt.op(vm.SWAP1).push(64).op(vm.SHL, vm.OR).returnWord() // key << 64 | seq
+
+	rt.label("getUserOpHash").op(vm.POP)
+	rt.op(vm.CALLVALUE).jumpi("revert")
+	rt.push(4).op(vm.CALLDATALOAD).push(4).op(vm.ADD).mstoreAt(epOp)
+	userOpHash(rt)
+	rt.returnWord()
+
+	rt.label("handleOps").op(vm.POP)
+	rt.op(vm.CALLVALUE).jumpi("revert")
+	rt.push(4).op(vm.CALLDATALOAD).push(4).op(vm.ADD) // [arr]
+	rt.op(vm.DUP1, vm.CALLDATALOAD).mstoreAt(epCount)
+	rt.push(0x20).op(vm.ADD).mstoreAt(epHeads)
+	rt.push(0).mstoreAt(epIndex)
+	rt.push(0).mstoreAt(epCollected)
+
+	rt.label("loop")
+	rt.mloadAt(epCount).mloadAt(epIndex).op(vm.LT, vm.ISZERO).jumpi("done")
+	rt.mloadAt(epHeads).op(vm.DUP1).mloadAt(epIndex).push(5).op(vm.SHL, vm.ADD, vm.CALLDATALOAD, vm.ADD).mstoreAt(epOp)
+	rt.op(vm.GAS).mstoreAt(epGasStart)
+	userOpHash(rt)
+	rt.mstoreAt(epHash)
+	opWord(rt, 0)
+	rt.mask(160).mstoreAt(epSender)
+
+	// nonce：高 192 位是 key，低 64 位必须等于该 key 的下一个序号
+	rt.mloadAt(epSender)
+	opWord(rt, 1)
+	rt.push(64).op(vm.SHR)
+	nonceSlot(rt)            // [slot]
+	rt.op(vm.DUP1, vm.SLOAD) // [slot, next]
+	opWord(rt, 1)
+	rt.mask(64)                                                 // [slot, next, seq]
+	rt.op(vm.DUP2, vm.DUP2, vm.EQ, vm.ISZERO).jumpi("badNonce") // [slot, next, seq]
+	rt.push(1).op(vm.ADD, vm.SWAP1, vm.POP, vm.SWAP1, vm.SSTORE)
+
+	opBytes(rt, 7) // paymasterAndData
+	rt.jumpi("noPaymaster").op(vm.POP)
+
+	// initCode = factory (20 字节) | factoryData
+	opBytes(rt, 2) // [p, n]
+	rt.op(vm.DUP1, vm.ISZERO).jumpi("noInitCode")
+	rt.mloadAt(epSender).op(vm.EXTCODESIZE).jumpi("alreadyDeployed")
+	rt.push(20).op(vm.DUP2, vm.LT).jumpi("initCodeFailed")
+	rt.push(20).op(vm.DUP2, vm.SUB) // [p, n, n-20]
+	rt.op(vm.DUP1, vm.DUP4).push(20).op(vm.ADD).push(epBuf).op(vm.CALLDATACOPY)
+	rt.push(0).mstoreAt(0)
+	// call(gas, factory, 0, epBuf, n-20, 0, 0x20)
+	rt.push(0x20).push(0).op(vm.DUP3).push(epBuf).push(0)
+	rt.op(vm.DUP8, vm.CALLDATALOAD).push(96).op(vm.SHR, vm.GAS, vm.CALL) // [p, n, n-20, ok]
+	rt.op(vm.ISZERO).jumpi("initCodeFailed")
+	rt.op(vm.POP)
+	rt.push(0).op(vm.MLOAD).mloadAt(epSender).op(vm.EQ, vm.ISZERO).jumpi("initCodeSender")
+	rt.mloadAt(epSender).op(vm.EXTCODESIZE, vm.ISZERO).jumpi("initCodeSender")
+	rt.jump("validate")
+	rt.label("noInitCode")
+	rt.mloadAt(epSender).op(vm.EXTCODESIZE, vm.ISZERO).jumpi("notDeployed")
+	rt.label("validate").op(vm.POP, vm.POP)
+
+	// prefund = (verificationGasLimit + callGasLimit + preVerificationGas) * maxFeePerGas
+	opWord(rt, 4)
+	rt.op(vm.DUP1).push(128).op(vm.SHR, vm.SWAP1).mask(128).op(vm.ADD)
+	opWord(rt, 5)
+	rt.op(vm.ADD)
+	opWord(rt, 6)
+	rt.mask(128).op(vm.MUL).mstoreAt(epPrefund)
+
+	// missingAccountFunds = max(prefund - deposit, 0)
+	rt.mloadAt(epSender).mappingSlot(0).op(vm.SLOAD).mloadAt(epPrefund) // [deposit, prefund]
+	rt.op(vm.DUP2, vm.DUP2, vm.GT).jumpi("needFunds")
+	rt.op(vm.POP, vm.POP).push(0).jump("haveMissing")
+	rt.label("needFunds").op(vm.SUB)
+	rt.label("haveMissing") // [missing]
+
+	// validateUserOp(userOp, userOpHash, missingAccountFunds)：元组原样复制到参数中
+	rt.push(padRight(simpleAccountABI.Methods["validateUserOp"].ID)).mstoreAt(epBuf)
+	rt.push(0x60).mstoreAt(epBuf + 0x04)
+	rt.mloadAt(epHash).mstoreAt(epBuf + 0x24)
+	rt.mstoreAt(epBuf + 0x44)
+	// 元组长度：signature 是最后一个字段，结尾为 signature 的数据末尾
+	rt.mloadAt(epOp).op(vm.DUP1).push(0x100).op(vm.ADD, vm.CALLDATALOAD, vm.ADD) // [sigPos]
+	rt.op(vm.DUP1, vm.CALLDATALOAD).push(31).op(vm.ADD).push(31).op(vm.NOT, vm.AND)
+	rt.op(vm.ADD).push(0x20).op(vm.ADD).mloadAt(epOp).op(vm.SWAP1, vm.SUB) // [len]
+	rt.op(vm.DUP1).mloadAt(epOp).push(epBuf + 0x64).op(vm.CALLDATACOPY)
+	rt.push(0x64).op(vm.ADD) // [size]
+	rt.push(0).mstoreAt(0)
+	rt.push(0x20).push(0).op(vm.DUP3).push(epBuf).push(0).mloadAt(epSender)
+	opWord(rt, 4)
+	rt.push(128).op(vm.SHR, vm.CALL) // gas = verificationGasLimit
+	rt.op(vm.ISZERO).jumpi("validateReverted")
+	rt.op(vm.POP)
+	rt.push(0x20).op(vm.RETURNDATASIZE, vm.LT).jumpi("validateReverted")
+	rt.push(0).op(vm.MLOAD).jumpi("signatureError")
+
+	// 从存款中预扣 prefund
+	rt.mloadAt(epSender).mappingSlot(0).op(vm.DUP1, vm.SLOAD).mloadAt(epPrefund) // [slot, deposit, prefund]
+	rt.op(vm.DUP1, vm.DUP3, vm.LT).jumpi("prefundError")
+	rt.op(vm.SWAP1, vm.SUB, vm.SWAP1, vm.SSTORE)
+
+	// 以 callGasLimit 执行 callData
+	opBytes(rt, 3) // [p, n]
+	rt.op(vm.DUP1, vm.DUP3).push(epBuf).op(vm.CALLDATACOPY, vm.SWAP1, vm.POP)
+	rt.push(0).push(0).op(vm.DUP3).push(epBuf).push(0).mloadAt(epSender)
+	opWord(rt, 4)
+	rt.mask(128).op(vm.CALL, vm.SWAP1, vm.POP).mstoreAt(epSuccess)
+	rt.mloadAt(epSuccess).jumpi("executed")
+	// UserOperationRevertReason(userOpHash, sender, nonce, revertReason)
+	opWord(rt, 1)
+	rt.mstoreAt(epBuf)
+	rt.push(0x40).mstoreAt(epBuf + 0x20)
+	rt.op(vm.RETURNDATASIZE).mstoreAt(epBuf + 0x40)
+	rt.op(vm.RETURNDATASIZE).push(0).push(epBuf + 0x60).op(vm.RETURNDATACOPY)
+	rt.mloadAt(epSender).mloadAt(epHash).push(entryPointABI.Events["UserOperationRevertReason"].ID.Bytes())
+	rt.op(vm.RETURNDATASIZE).push(31).op(vm.ADD).push(31).op(vm.NOT, vm.AND).push(0x60).op(vm.ADD)
+	rt.push(epBuf).op(vm.LOG3)
+	rt.label("executed")
+
+	// gasPrice = min(maxFeePerGas, maxPriorityFeePerGas + basefee)
+	opWord(rt, 6)
+	rt.op(vm.DUP1).mask(128).op(vm.SWAP1).push(128).op(vm.SHR, vm.BASEFEE, vm.ADD) // [maxFee, tip+basefee]
+	rt.op(vm.DUP2, vm.DUP2, vm.LT).jumpi("useTip")
+	rt.op(vm.POP).jump("havePrice")
+	rt.label("useTip").op(vm.SWAP1, vm.POP)
+	rt.label("havePrice") // [price]
+	rt.op(vm.GAS).mloadAt(epGasStart).op(vm.SUB)
+	opWord(rt, 5)
+	rt.op(vm.ADD)                   // [price, used]
+	rt.op(vm.DUP2, vm.DUP2, vm.MUL) // [price, used, cost]
+	rt.mloadAt(epPrefund).op(vm.DUP2, vm.GT, vm.ISZERO).jumpi("costOK")
+	rt.op(vm.POP).mloadAt(epPrefund)
+	rt.label("costOK")
+	// 多扣的部分退回存款，实际费用计入 beneficiary
+	rt.mloadAt(epSender).mappingSlot(0).op(vm.DUP1, vm.SLOAD) // [price, used, cost, slot, deposit]
+	rt.op(vm.DUP3).mloadAt(epPrefund).op(vm.SUB, vm.ADD, vm.SWAP1, vm.SSTORE)
+	rt.op(vm.DUP1).mloadAt(epCollected).op(vm.ADD).mstoreAt(epCollected)
+	// UserOperationEvent(userOpHash, sender, paymaster, nonce, success, actualGasCost, actualGasUsed)
+	opWord(rt, 1)
+	rt.mstoreAt(epBuf)
+	rt.mloadAt(epSuccess).mstoreAt(epBuf + 0x20)
+	rt.mstoreAt(epBuf + 0x40).mstoreAt(epBuf + 0x60).op(vm.POP)
+	rt.push(0).mloadAt(epSender).mloadAt(epHash).push(entryPointABI.Events["UserOperationEvent"].ID.Bytes())
+	rt.push(0x80).push(epBuf).op(vm.LOG4)
+
+	rt.mloadAt(epIndex).push(1).op(vm.ADD).mstoreAt(epIndex)
+	rt.jump("loop")
+
+	rt.label("done")
+	rt.push(0).push(0).push(0).push(0).mloadAt(epCollected).loadAddress(0x24).op(vm.GAS, vm.CALL)
+	rt.op(vm.ISZERO).jumpi("beneficiaryError")
+	rt.op(vm.STOP)
+
+	rt.label("revert").push(0).push(0).op(vm.REVERT)
+	rt.label("badNonce").revertWith(ErrInvalidNonce)
+	rt.label("noPaymaster").revertWith(ErrNoPaymaster)
+	rt.label("alreadyDeployed").revertWith(ErrAlreadyDeployed)
+	rt.label("initCodeFailed").revertWith(ErrInitCodeFailed)
+	rt.label("initCodeSender").revertWith(ErrInitCodeSender)
+	rt.label("notDeployed").revertWith(ErrNotDeployed)
+	rt.label("validateReverted").revertWith(ErrValidateReverted)
+	rt.label("signatureError").revertWith(ErrSignature)
+	rt.label("prefundError").revertWith(ErrPrefund)
+	rt.label("beneficiaryError").revertWith(ErrBeneficiary)
+
+	ctor := newAsm()
+	ctor.deployRuntime(rt.assemble())
+	return ctor.assemble()
+}
+
+// deposit [account] → []：deposits[account] += msg.value，触发 Deposited
+func deposit(a *asm) {
+	a.op(vm.DUP1).mappingSlot(0)                   // [account, slot]
+	a.op(vm.DUP1, vm.SLOAD, vm.CALLVALUE, vm.ADD)  // [account, slot, total]
+	a.op(vm.DUP1, vm.SWAP2, vm.SSTORE).mstoreAt(0) // [account]
+	a.push(entryPointABI.Events["Deposited"].ID.Bytes()).push(0x20).push(0).op(vm.LOG2)
+}
+
+// nonceSlot [sender, key] → [slot]：序号保存在 mapping(address => mapping(uint192 => uint256)) 中，
+// 位于 slot 1，与 slot 0 的存款分开
+func nonceSlot(a *asm) {
+	a.op(vm.SWAP1).mappingSlot(1) // [key, keccak256(sender . 1)]
+	a.push(0x20).op(vm.MSTORE).push(0).op(vm.MSTORE)
+	a.push(0x40).push(0).op(vm.KECCAK256)
+}
+
+// opWord 当前 UserOperation 元组的第 k 个静态字段
+func opWord(a *asm, k int) {
+	a.mloadAt(epOp)
+	if k > 0 {
+		a.push(k * 32).op(vm.ADD)
+	}
+	a.op(vm.CALLDATALOAD)
+}
+
+// opBytes 当前 UserOperation 元组的第 k 个 bytes 字段 → [数据位置, 长度]
+func opBytes(a *asm, k int) {
+	a.mloadAt(epOp).op(vm.DUP1).push(k*32).op(vm.ADD, vm.CALLDATALOAD, vm.ADD) // [pos]
+	a.op(vm.DUP1).push(0x20).op(vm.ADD, vm.SWAP1, vm.CALLDATALOAD)             // [p, n]
+}
+
+// hashOpBytes 第 k 个 bytes 字段的 keccak256
+func hashOpBytes(a *asm, k int) {
+	opBytes(a, k)
+	a.op(vm.DUP1, vm.DUP3).push(epBytes).op(vm.CALLDATACOPY)
+	a.push(epBytes).op(vm.KECCAK256, vm.SWAP1, vm.POP)
+}
+
+// userOpHash 计算当前 UserOperation 的 userOpHash，结果留在栈顶
+func userOpHash(a *asm) {
+	opWord(a, 0)
+	a.mask(160).mstoreAt(epBuf)
+	opWord(a, 1)
+	a.mstoreAt(epBuf + 0x20)
+	hashOpBytes(a, 2)
+	a.mstoreAt(epBuf + 0x40)
+	hashOpBytes(a, 3)
+	a.mstoreAt(epBuf + 0x60)
+	opWord(a, 4)
+	a.mstoreAt(epBuf + 0x80)
+	opWord(a, 5)
+	a.mstoreAt(epBuf + 0xa0)
+	opWord(a, 6)
+	a.mstoreAt(epBuf + 0xc0)
+	hashOpBytes(a, 7)
+	a.mstoreAt(epBuf + 0xe0)
+	a.push(0x100).push(epBuf).op(vm.KECCAK256).mstoreAt(epBuf)
+	a.op(vm.ADDRESS).mstoreAt(epBuf + 0x20)
+	a.op(vm.CHAINID).mstoreAt(epBuf + 0x40)
+	a.push(0x60).push(epBuf).op(vm.KECCAK256)
+}
+
+// buildSimpleAccount 对应 eth-infinitism 的 SimpleAccount（去掉了代理和批量执行）：
+//
+//	constructor(IEntryPoint anEntryPoint, address anOwner)
+//	function validateUserOp(PackedUserOperation calldata userOp, bytes32 userOpHash, uint256 missingAccountFunds)
+//	    external returns (uint256 validationData) // 只接受 EntryPoint 调用；签名是 owner 对 userOpHash 的 personal_sign，
+//	                                              // 不匹配时返回 1；然后把 missingAccountFunds 转给 EntryPoint
+//	function execute(address dest, uint256 value, bytes calldata func) external // 只接受 EntryPoint 或 owner 调用
+//	function owner() / entryPoint() external view returns (address)
+//	receive() external payable {}
+func buildSimpleAccount() []byte {
+	rt := newAsm()
+	rt.op(vm.CALLDATASIZE).jumpi("dispatch").op(vm.STOP)
+	rt.label("dispatch")
+	dispatch(rt, simpleAccountABI, "validateUserOp", "execute", "owner", "entryPoint")
+
+	rt.label("owner").op(vm.POP)
+	rt.push(0).op(vm.SLOAD).returnWord()
+	rt.label("entryPoint").op(vm.POP)
+	rt.push(1).op(vm.SLOAD).returnWord()
+
+	rt.label("validateUserOp").op(vm.POP)
+	rt.push(1).op(vm.SLOAD, vm.CALLER, vm.EQ, vm.ISZERO).jumpi("notEntryPoint")
+	// digest = keccak256("\x19Ethereum Signed Message:\n32" . userOpHash)
+	rt.push(padRight([]byte("\x19Ethereum Signed Message:\n32"))).mstoreAt(0)
+	rt.push(0x24).op(vm.CALLDATALOAD).mstoreAt(0x1c)
+	rt.push(0x3c).push(0).op(vm.KECCAK256).mstoreAt(0x80)
+	// signature 是元组的第 9 个字段，r | s | v 共 65 字节
+	rt.push(4).op(vm.CALLDATALOAD).push(4).op(vm.ADD)              // [op]
+	rt.op(vm.DUP1).push(0x100).op(vm.ADD, vm.CALLDATALOAD, vm.ADD) // [sig]
+	rt.push(65).op(vm.DUP2, vm.CALLDATALOAD, vm.EQ, vm.ISZERO).jumpi("invalidSig")
+	rt.op(vm.DUP1).push(0x20).op(vm.ADD, vm.CALLDATALOAD).mstoreAt(0xc0) // r
+	rt.op(vm.DUP1).push(0x40).op(vm.ADD, vm.CALLDATALOAD).mstoreAt(0xe0) // s
+	rt.push(0x60).op(vm.ADD, vm.CALLDATALOAD).push(248).op(vm.SHR).mstoreAt(0xa0)
+	// ecrecover(digest, v, r, s)，恢复失败时没有返回数据，所以先清空 0
+	rt.push(0).mstoreAt(0)
+	rt.push(0x20).push(0).push(0x80).push(0x80).push(1).op(vm.GAS, vm.STATICCALL, vm.POP)
+	rt.push(0).op(vm.MLOAD).push(0).op(vm.SLOAD, vm.EQ, vm.ISZERO) // [validationData]
+	rt.jump("payPrefund")
+	rt.label("invalidSig").op(vm.POP).push(1)
+	rt.label("payPrefund")
+	rt.push(0x44).op(vm.CALLDATALOAD, vm.DUP1, vm.ISZERO).jumpi("paid")
+	rt.push(0).push(0).push(0).push(0).op(vm.DUP5, vm.CALLER, vm.GAS, vm.CALL, vm.POP) // 与 SimpleAccount 相同，忽略转账结果
+	rt.label("paid").op(vm.POP).returnWord()
+
+	rt.label("execute").op(vm.POP)
+	rt.push(1).op(vm.SLOAD, vm.CALLER, vm.EQ).push(0).op(vm.SLOAD, vm.CALLER, vm.EQ, vm.OR, vm.ISZERO).jumpi("notAuthorized")
+	rt.push(0x44).op(vm.CALLDATALOAD).push(4).op(vm.ADD) // [pos]
+	rt.op(vm.DUP1, vm.CALLDATALOAD)                      // [pos, n]
+	rt.op(vm.DUP1, vm.SWAP2).push(0x20).op(vm.ADD).push(0).op(vm.CALLDATACOPY)
+	rt.push(0).push(0).op(vm.DUP3).push(0).push(0x24).op(vm.CALLDATALOAD).loadAddress(4).op(vm.GAS, vm.CALL)
+	rt.jumpi("ok")
+	rt.op(vm.RETURNDATASIZE).push(0).push(0).op(vm.RETURNDATACOPY)
+	rt.op(vm.RETURNDATASIZE).push(0).op(vm.REVERT)
+	rt.label("ok").op(vm.STOP)
+
+	rt.label("revert").push(0).push(0).op(vm.REVERT)
+	rt.label("notEntryPoint").revertWith(ErrNotEntryPoint)
+	rt.label("notAuthorized").revertWith(ErrNotAuthorized)
+
+	ctor := newAsm()
+	ctor.push(0x40).pushLabel("end").push(0).op(vm.CODECOPY)
+	ctor.push(0).op(vm.MLOAD).push(1).op(vm.SSTORE)    // entryPoint
+	ctor.push(0x20).op(vm.MLOAD).push(0).op(vm.SSTORE) // owner
+	ctor.deployRuntime(rt.assemble())
+	return ctor.assemble()
+}
+
+// buildAccountFactory 对应 SimpleAccountFactory：
+//
+//	constructor(IEntryPoint anEntryPoint)
+//	function createAccount(address owner, uint256 salt) external returns (address) // 已部署时直接返回地址
+//	function getAddress(address owner, uint256 salt) external view returns (address)
+//
+// 账户的 initcode 附加在运行时代码末尾，CREATE2 的 salt 直接使用参数 salt
+func buildAccountFactory() []byte {
+	rt := newAsm()
+	dispatch(rt, accountFactoryABI, "createAccount", "getAddress")
+
+	rt.label("getAddress").op(vm.POP)
+	accountAddress(rt)
+	rt.returnWord()
+
+	rt.label("createAccount").op(vm.POP)
+	accountAddress(rt) // [addr]
+	rt.op(vm.DUP1, vm.EXTCODESIZE).jumpi("deployed")
+	rt.push(0x24).op(vm.CALLDATALOAD).push(len(simpleAccountInitCode) + 0x40).push(0x100).push(0).op(vm.CREATE2)
+	rt.op(vm.DUP1, vm.ISZERO).jumpi("revert")
+	rt.op(vm.SWAP1, vm.POP)
+	rt.label("deployed").returnWord()
+
+	rt.label("revert").push(0).push(0).op(vm.REVERT)
+	rt.mark("account").data(simpleAccountInitCode)
+
+	ctor := newAsm()
+	ctor.push(0x20).pushLabel("end").push(0).op(vm.CODECOPY)
+	ctor.push(0).op(vm.MLOAD).push(0).op(vm.SSTORE)
+	ctor.deployRuntime(rt.assemble())
+	return ctor.assemble()
+}
+
+// accountAddress 在内存 0x100 处拼出账户的部署数据（initcode | entryPoint | owner），
+// 按 CREATE2 规则计算地址留在栈顶
+func accountAddress(a *asm) {
+	n := len(simpleAccountInitCode)
+	a.push(n).pushLabel("account").push(0x100).op(vm.CODECOPY)
+	a.push(0).op(vm.SLOAD).mstoreAt(0x100 + n)
+	a.loadAddress(4).mstoreAt(0x120 + n)
+	a.push(n + 0x40).push(0x100).op(vm.KECCAK256).mstoreAt(0x40)
+	a.push(0x24).op(vm.CALLDATALOAD).mstoreAt(0x20)
+	a.op(vm.ADDRESS).mstoreAt(0)
+	a.push(0xff).push(11).op(vm.MSTORE8)
+	a.push(85).push(11).op(vm.KECCAK256).mask(160)
+}
@@ -0,0 +1,86 @@
+package contracts
+
+import (
+	_ "embed"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// GnosisSafe_v1.3.0.abi / .bin 是正式的 Safe v1.3.0 单例合约（solc 0.7.6，与各链上
+// 0xd9Db270c1B5E3Bd161E8c8503c55cEABeE709552 的代码相同），
+// SafeProxyFactory_v1.4.1.abi / .bin 是 Safe v1.4.1 源码的代理工厂。
+// 两者都取自 Optimism 仓库 v1.5.0-rc.3 的 op-bindings/bindings（safe_v130.go、safeproxyfactory.go）：
+// Safe_v130 就是 OP Stack 预装在 0x69f4D1788e39c87893C980c06EdF4b7f686e2938 的那份字节码，
+// SafeProxyFactory 由 Optimism 用 solc 0.8.15 编译。
+// v1.4.1 工厂创建的 SafeProxy 与 v1.3.0 的代理行为相同：slot 0 保存单例地址，其余调用全部 DELEGATECALL
+
+// SafeABI Safe v1.3.0 单例合约的完整 ABI，通过代理调用
+//
+//go:embed GnosisSafe_v1.3.0.abi
+var SafeABI string
+
+// SafeProxyFactoryABI SafeProxyFactory v1.4.1 的 ABI
+//
+//go:embed SafeProxyFactory_v1.4.1.abi
+var SafeProxyFactoryABI string
+
+//go:embed GnosisSafe_v1.3.0.bin
+var safeSingletonBin string
+
+//go:embed SafeProxyFactory_v1.4.1.bin
+var safeProxyFactoryBin string
+
+// SafeVersion 单例合约 VERSION() 的返回值
+const SafeVersion = "1.3.0"
+
+// Safe 回滚原因（GS0xx 执行、GS2xx 初始化），完整列表见 Safe 仓库的 docs/error_codes.md
+const (
+	ErrSafeExecFailed      = "GS013" // safeTxGas 和 gasPrice 都为 0 时内部调用失败
+	ErrSafeSigTooShort     = "GS020" // 签名数据短于 threshold 个签名
+	ErrSafeInvalidOwner    = "GS026" // 签名者不是 owner，或签名没有按地址升序排列
+	ErrSafeAlreadySetup    = "GS200" // 已经初始化过，单例合约自身也视为已初始化
+	ErrSafeThresholdHigh   = "GS201" // threshold 超过 owner 数量
+	ErrSafeThresholdZero   = "GS202"
+	ErrSafeInvalidOwnerArg = "GS203" // owner 为零地址、哨兵地址或 Safe 自己
+	ErrSafeDuplicateOwner  = "GS204"
+)
+
+// EIP-712 类型哈希，v1.3.0 起域只包含 chainId 和合约地址
+var (
+	SafeDomainTypeHash = crypto.Keccak256Hash([]byte("EIP712Domain(uint256 chainId,address verifyingContract)"))
+	SafeTxTypeHash     = crypto.Keccak256Hash([]byte("SafeTx(address to,uint256 value,bytes data,uint8 operation,uint256 safeTxGas,uint256 baseGas,uint256 gasPrice,address gasToken,address refundReceiver,uint256 nonce)"))
+)
+
+var (
+	safeABI             = mustParseABI(SafeABI)
+	safeProxyFactoryABI = mustParseABI(SafeProxyFactoryABI)
+)
+
+// SafeSingletonDeployData Safe v1.3.0 单例合约的部署数据，没有构造参数。
+// 单例本身不保存资金，每个 Safe 是一个指向它的代理
+func SafeSingletonDeployData() []byte { return common.FromHex(safeSingletonBin) }
+
+// SafeProxyFactoryDeployData 代理工厂的部署数据，没有构造参数
+func SafeProxyFactoryDeployData() []byte { return common.FromHex(safeProxyFactoryBin) }
+
+// SafeSetupData 编码 setup 的调用数据：owners 中的每个地址都能签名，至少 threshold 个签名才能执行交易。
+// 不设置模块、fallback handler 和部署时的费用支付
+func SafeSetupData(owners []common.Address, threshold uint64) ([]byte, error) {
+	if threshold == 0 || threshold > uint64(len(owners)) {
+		return nil, fmt.Errorf("threshold 应在 1 ~ %d 之间，实际为 %d", len(owners), threshold)
+	}
+	return safeABI.Pack("setup", owners, new(big.Int).SetUint64(threshold),
+		common.Address{}, []byte{}, common.Address{}, common.Address{}, new(big.Int), common.Address{})
+}
+
+// CreateSafeProxyData 编码工厂 createProxyWithNonce(singleton, initializer, saltNonce) 的调用数据。
+// 工厂用 CREATE2 部署代理并立即以 initializer 调用它，同一个 singleton、initializer 和 saltNonce 只能部署一次
+func CreateSafeProxyData(singleton common.Address, initializer []byte, saltNonce *big.Int) ([]byte, error) {
+	return safeProxyFactoryABI.Pack("createProxyWithNonce", singleton, initializer, saltNonce)
+}
+
+// SafeProxyCreationTopic 工厂的 ProxyCreation(proxy, singleton) 事件，代理地址在 topics[1]
+var SafeProxyCreationTopic = safeProxyFactoryABI.Events["ProxyCreation"].ID
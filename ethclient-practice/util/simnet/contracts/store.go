@@ -0,0 +1,18 @@
+package contracts
+
+import _ "embed"
+
+// Store_sol_Store.abi / .bin 是 2.09-deploy-contract/contract 下同名编译产物的副本
+// （solcjs 0.8.30），修改 Store.sol 并重新编译后运行 go generate 同步，
+// TestStoreArtifactInSync 检查两者一致
+//go:generate cp ../../../2.09-deploy-contract/contract/Store_sol_Store.abi ../../../2.09-deploy-contract/contract/Store_sol_Store.bin .
+
+// StoreABI 与 2.09-deploy-contract/contract/Store.sol 相同
+//
+//go:embed Store_sol_Store.abi
+var StoreABI string
+
+// storeBin Store 的创建字节码（十六进制，不含 0x 前缀和构造参数）
+//
+//go:embed Store_sol_Store.bin
+var storeBin string
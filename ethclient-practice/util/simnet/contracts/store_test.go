@@ -0,0 +1,22 @@
+package contracts
+
+import (
+	"os"
+	"testing"
+)
+
+// 嵌入的 Store 编译产物必须与 2.09 提交的产物一致，修改合约后运行 go generate 同步
+func TestStoreArtifactInSync(t *testing.T) {
+	for name, embedded := range map[string]string{
+		"Store_sol_Store.abi": StoreABI,
+		"Store_sol_Store.bin": storeBin,
+	} {
+		want, err := os.ReadFile("../../../2.09-deploy-contract/contract/" + name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if embedded != string(want) {
+			t.Errorf("%s 与 2.09-deploy-contract/contract 中的产物不一致，请运行 go generate", name)
+		}
+	}
+}
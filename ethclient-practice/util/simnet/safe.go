@@ -0,0 +1,49 @@
+package simnet
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/dapp-learning/ethclient/util/simnet/contracts"
+)
+
+// DeploySafe 用正式的 Safe v1.3.0 单例和代理工厂部署一个 Safe，返回代理地址。
+// 第一次调用时先部署单例和工厂，之后的 Safe 共用它们（地址保存在 SafeSingleton 和 SafeFactory）
+func (n *Net) DeploySafe(ctx context.Context, from *Account, owners []common.Address, threshold uint64) (common.Address, error) {
+	setup, err := contracts.SafeSetupData(owners, threshold)
+	if err != nil {
+		return common.Address{}, err
+	}
+	n.safeMu.Lock()
+	defer n.safeMu.Unlock()
+	if n.SafeSingleton == (common.Address{}) {
+		if n.SafeSingleton, _, err = n.Deploy(ctx, from, contracts.SafeSingletonDeployData()); err != nil {
+			return common.Address{}, fmt.Errorf("部署 Safe 单例失败: %w", err)
+		}
+	}
+	if n.SafeFactory == (common.Address{}) {
+		if n.SafeFactory, _, err = n.Deploy(ctx, from, contracts.SafeProxyFactoryDeployData()); err != nil {
+			return common.Address{}, fmt.Errorf("部署 SafeProxyFactory 失败: %w", err)
+		}
+	}
+
+	// 每次用不同的 saltNonce，相同的 owner 和阈值也能部署多个 Safe
+	n.safeCount++
+	data, err := contracts.CreateSafeProxyData(n.SafeSingleton, setup, big.NewInt(n.safeCount))
+	if err != nil {
+		return common.Address{}, err
+	}
+	receipt, err := n.Send(ctx, from, &n.SafeFactory, nil, data)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("创建 Safe 代理失败: %w", err)
+	}
+	for _, l := range receipt.Logs {
+		if l.Address == n.SafeFactory && len(l.Topics) > 1 && l.Topics[0] == contracts.SafeProxyCreationTopic {
+			return common.BytesToAddress(l.Topics[1].Bytes()), nil
+		}
+	}
+	return common.Address{}, fmt.Errorf("交易 %s 中没有 ProxyCreation 事件", receipt.TxHash.Hex())
+}
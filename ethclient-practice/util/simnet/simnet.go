@@ -0,0 +1,379 @@
+// Package simnet 按 go-ethereum 的 ethclient/simulated 的方式搭建离线测试链。
+//
+// New 会预置若干个有余额的账户，并部署 Store 与一个 ERC20（见 contracts 包），
+// 课程中的查询、转账、部署、调用流程不需要 Sepolia RPC 和私钥就能运行。
+// 模拟链只在 Commit 时出块，区块内容完全由发送的交易决定：创世时间固定为 GenesisTime，
+// 每个区块比父区块晚 BlockTime 秒，prevRandao 由区块号派生，
+// 因此同样的操作序列每次运行得到的区块哈希、时间戳和合约地址都相同。
+// net.RPC 是连接同一节点的原始 RPC 客户端，可以调用 eth_call 的状态覆盖、
+// debug_traceTransaction 等 ethclient 没有封装的方法。
+//
+//	net, err := simnet.New(nil)
+//	defer net.Close()
+//	balance, err := net.Client.BalanceAt(ctx, net.Accounts[0].Address, nil)
+package simnet
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/beacon/engine"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/eth"
+	"github.com/ethereum/go-ethereum/eth/downloader"
+	"github.com/ethereum/go-ethereum/eth/ethconfig"
+	"github.com/ethereum/go-ethereum/eth/filters"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/miner"
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	// 注册 callTracer、prestateTracer 等内置 tracer
+	_ "github.com/ethereum/go-ethereum/eth/tracers/native"
+
+	"github.com/dapp-learning/ethclient/util/simnet/contracts"
+)
+
+// ChainID 模拟链的链 ID（开发链配置 params.AllDevChainProtocolChanges 固定为 1337）
+const ChainID = 1337
+
+// 区块时间：创世区块固定为 2025-01-01 00:00:00 UTC，之后每个区块间隔 BlockTime 秒
+const (
+	GenesisTime uint64 = 1735689600
+	BlockTime   uint64 = 12
+)
+
+// ErrPendingTxs AdjustTime 要求交易池为空，否则这些交易会被打包进调整时间的区块
+var ErrPendingTxs = errors.New("交易池中还有待打包的交易")
+
+// 默认配置
+var (
+	DefaultAccounts     = 4
+	DefaultBalance      = new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))      // 每个账户 1000 ETH
+	DefaultTokenSupply  = new(big.Int).Mul(big.NewInt(1_000_000), big.NewInt(1e18)) // 1,000,000 SIM
+	DefaultStoreVersion = "1.0"
+)
+
+// ErrTxFailed 交易已打包但执行失败
+var ErrTxFailed = errors.New("交易执行失败")
+
+// Client 模拟链客户端，方法与 *ethclient.Client 相同，*ethclient.Client 也实现了该接口
+type Client = simulated.Client
+
+// Account 预置资金的账户
+type Account struct {
+	Key     *ecdsa.PrivateKey
+	Address common.Address
+}
+
+// Options 模拟链配置，零值字段使用默认值
+type Options struct {
+	Accounts      int                // 预置账户数量
+	Balance       *big.Int           // 每个账户的初始余额（wei）
+	StoreVersion  string             // Store 构造参数
+	TokenSupply   *big.Int           // ERC20 初始总量，全部属于 Accounts[0]
+	SkipContracts bool               // 不部署 Store 和 ERC20
+	Alloc         types.GenesisAlloc // 额外的创世状态，例如预置合约代码
+}
+
+// Net 一条模拟链
+type Net struct {
+	Client   Client
+	RPC      *rpc.Client // 原始 RPC 客户端，用于 ethclient 没有封装的方法，如 eth_call 的状态覆盖、debug_trace*
+	ChainID  *big.Int
+	Accounts []*Account
+
+	Store common.Address // Store 合约地址
+	Token common.Address // ERC20 合约地址
+
+	SafeSingleton common.Address // Safe v1.3.0 单例，第一次 DeploySafe 时部署
+	SafeFactory   common.Address // SafeProxyFactory，第一次 DeploySafe 时部署
+
+	stack   *node.Node
+	backend *eth.Ethereum
+	sealMu  sync.Mutex
+
+	safeMu    sync.Mutex
+	safeCount int64
+}
+
+// New 启动模拟链，预置账户并部署示例合约。opts 为 nil 时使用默认配置
+func New(opts *Options) (*Net, error) {
+	if opts == nil {
+		opts = new(Options)
+	}
+	if opts.Accounts <= 0 {
+		opts.Accounts = DefaultAccounts
+	}
+	if opts.Balance == nil {
+		opts.Balance = DefaultBalance
+	}
+	if opts.StoreVersion == "" {
+		opts.StoreVersion = DefaultStoreVersion
+	}
+	if opts.TokenSupply == nil {
+		opts.TokenSupply = DefaultTokenSupply
+	}
+
+	alloc := make(types.GenesisAlloc, opts.Accounts+len(opts.Alloc))
+	for addr, account := range opts.Alloc {
+		alloc[addr] = account
+	}
+	net := &Net{ChainID: big.NewInt(ChainID)}
+	for i := 0; i < opts.Accounts; i++ {
+		account := NewAccount(i)
+		net.Accounts = append(net.Accounts, account)
+		alloc[account.Address] = types.Account{Balance: new(big.Int).Set(opts.Balance)}
+	}
+
+	if err := net.start(alloc); err != nil {
+		return nil, err
+	}
+	if opts.SkipContracts {
+		return net, nil
+	}
+
+	ctx := context.Background()
+	deployer := net.Accounts[0]
+	data, err := contracts.StoreDeployData(opts.StoreVersion)
+	if err != nil {
+		net.Close()
+		return nil, err
+	}
+	if net.Store, _, err = net.Deploy(ctx, deployer, data); err != nil {
+		net.Close()
+		return nil, fmt.Errorf("部署 Store 失败: %w", err)
+	}
+	if data, err = contracts.TokenDeployData(opts.TokenSupply); err != nil {
+		net.Close()
+		return nil, err
+	}
+	if net.Token, _, err = net.Deploy(ctx, deployer, data); err != nil {
+		net.Close()
+		return nil, fmt.Errorf("部署 ERC20 失败: %w", err)
+	}
+	return net, nil
+}
+
+// start 启动节点。与 simulated.NewBackend 相同，另外注册了 debug_trace* 接口，
+// 并把创世区块的难度设为 0：合并后的区块才启用 PUSH0 等 Shanghai 操作码。
+// 不使用 catalyst.SimulatedBeacon（它用当前时间和随机数出块），由 seal 直接驱动出块
+func (n *Net) start(alloc types.GenesisAlloc) error {
+	nodeConf := node.DefaultConfig
+	nodeConf.DataDir = ""
+	nodeConf.P2P = p2p.Config{NoDiscovery: true}
+
+	ethConf := ethconfig.Defaults
+	ethConf.Genesis = &core.Genesis{
+		Config:     params.AllDevChainProtocolChanges,
+		GasLimit:   ethconfig.Defaults.Miner.GasCeil,
+		Difficulty: new(big.Int),
+		Timestamp:  GenesisTime,
+		Alloc:      alloc,
+	}
+	ethConf.SyncMode = downloader.FullSync
+	ethConf.TxPool.NoLocals = true
+
+	stack, err := node.New(&nodeConf)
+	if err != nil {
+		return err
+	}
+	backend, err := eth.New(stack, &ethConf)
+	if err != nil {
+		stack.Close()
+		return err
+	}
+	filterSystem := filters.NewFilterSystem(backend.APIBackend, filters.Config{})
+	stack.RegisterAPIs(append(tracers.APIs(backend.APIBackend), rpc.API{
+		Namespace: "eth",
+		Service:   filters.NewFilterAPI(filterSystem),
+	}))
+	if err := stack.Start(); err != nil {
+		stack.Close()
+		return err
+	}
+	n.stack, n.backend = stack, backend
+	n.RPC = stack.Attach()
+	n.Client = ethclient.NewClient(n.RPC)
+	return nil
+}
+
+// NewAccount 第 i 个预置账户。私钥由固定种子派生，每次运行地址都相同
+func NewAccount(i int) *Account {
+	key, err := crypto.ToECDSA(crypto.Keccak256([]byte(fmt.Sprintf("simnet account %d", i))))
+	if err != nil {
+		panic(err) // 哈希落在曲线阶之外的概率可以忽略
+	}
+	return &Account{Key: key, Address: crypto.PubkeyToAddress(key.PublicKey)}
+}
+
+// Close 关闭模拟链
+func (n *Net) Close() error {
+	n.RPC.Close()
+	return n.stack.Close()
+}
+
+// Commit 打包当前交易池中的交易并出块，新区块的时间戳为父区块 + BlockTime，返回新区块哈希。
+// 出块失败说明节点状态已经损坏，直接 panic
+func (n *Net) Commit() common.Hash {
+	hash, err := n.seal(BlockTime)
+	if err != nil {
+		panic(fmt.Sprintf("simnet: 出块失败: %v", err))
+	}
+	return hash
+}
+
+// AdjustTime 与 simulated.Backend 相同：出一个时间戳为父区块 + d 的空块，
+// 用于测试时间锁、过期时间等逻辑。d 按秒取整且至少 1 秒，只能在交易池为空时调用
+func (n *Net) AdjustTime(d time.Duration) error {
+	if d < time.Second {
+		return fmt.Errorf("时间调整至少 1 秒，实际 %s", d)
+	}
+	if pending, queued := n.backend.TxPool().Stats(); pending+queued > 0 {
+		return fmt.Errorf("%w: %d 笔", ErrPendingTxs, pending+queued)
+	}
+	_, err := n.seal(uint64(d / time.Second))
+	return err
+}
+
+// seal 在当前链头上构建并导入一个区块，时间戳为父区块 + delta 秒。
+// 流程与 SimulatedBeacon 相同（构建 payload → 导入 → 设为链头并最终确认），
+// 只是时间戳和 prevRandao 都是确定的
+func (n *Net) seal(delta uint64) (common.Hash, error) {
+	n.sealMu.Lock()
+	defer n.sealMu.Unlock()
+
+	// 交易池在后台处理新交易和链头变化，先等它完成，避免刚发送的交易没有被打包
+	if err := n.backend.TxPool().Sync(); err != nil {
+		return common.Hash{}, err
+	}
+
+	chain := n.backend.BlockChain()
+	parent := chain.CurrentBlock()
+	number := new(big.Int).Add(parent.Number, common.Big1)
+	payload, err := n.backend.Miner().BuildPayload(&miner.BuildPayloadArgs{
+		Parent:       parent.Hash(),
+		Timestamp:    parent.Time + delta,
+		FeeRecipient: common.Address{},
+		Random:       crypto.Keccak256Hash([]byte("simnet randao"), number.Bytes()),
+		Withdrawals:  types.Withdrawals{},
+		BeaconRoot:   &common.Hash{},
+		Version:      engine.PayloadV3,
+	}, false)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	envelope := payload.ResolveFull()
+	if envelope == nil {
+		return common.Hash{}, errors.New("payload 构建被中断")
+	}
+
+	// 导入时需要交易中的 blob 哈希来校验区块
+	var blobHashes []common.Hash
+	for _, raw := range envelope.ExecutionPayload.Transactions {
+		var tx types.Transaction
+		if err := tx.UnmarshalBinary(raw); err != nil {
+			return common.Hash{}, err
+		}
+		blobHashes = append(blobHashes, tx.BlobHashes()...)
+	}
+	block, err := engine.ExecutableDataToBlock(*envelope.ExecutionPayload, blobHashes, &common.Hash{}, envelope.Requests)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if _, err := chain.InsertBlockWithoutSetHead(block, false); err != nil {
+		return common.Hash{}, err
+	}
+	if _, err := chain.SetCanonical(block); err != nil {
+		return common.Hash{}, err
+	}
+	chain.SetSafe(block.Header())
+	chain.SetFinalized(block.Header())
+	return block.Hash(), nil
+}
+
+// Transactor 账户的交易签名器，可直接用于 abigen 生成的绑定。
+// 通过绑定发送交易后需要调用 Commit 出块
+func (n *Net) Transactor(account *Account) *bind.TransactOpts {
+	opts, err := bind.NewKeyedTransactorWithChainID(account.Key, n.ChainID)
+	if err != nil {
+		panic(err) // 只有 chainID 为 nil 时才会出错
+	}
+	return opts
+}
+
+// Send 签名并发送交易，立即出块并返回收据。to 为 nil 时部署合约。
+// 交易执行失败时同时返回收据和 ErrTxFailed
+func (n *Net) Send(ctx context.Context, from *Account, to *common.Address, value *big.Int, data []byte) (*types.Receipt, error) {
+	if value == nil {
+		value = new(big.Int)
+	}
+	nonce, err := n.Client.PendingNonceAt(ctx, from.Address)
+	if err != nil {
+		return nil, err
+	}
+	head, err := n.Client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	gas, err := n.Client.EstimateGas(ctx, ethereum.CallMsg{From: from.Address, To: to, Value: value, Data: data})
+	if err != nil {
+		return nil, fmt.Errorf("估算 gas 失败: %w", err)
+	}
+	tip := big.NewInt(1e9)
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   n.ChainID,
+		Nonce:     nonce,
+		GasTipCap: tip,
+		GasFeeCap: new(big.Int).Add(new(big.Int).Mul(head.BaseFee, big.NewInt(2)), tip),
+		Gas:       gas,
+		To:        to,
+		Value:     value,
+		Data:      data,
+	})
+	signed, err := types.SignTx(tx, types.LatestSignerForChainID(n.ChainID), from.Key)
+	if err != nil {
+		return nil, err
+	}
+	if err := n.Client.SendTransaction(ctx, signed); err != nil {
+		return nil, err
+	}
+	n.Commit()
+
+	receipt, err := n.Client.TransactionReceipt(ctx, signed.Hash())
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		return receipt, fmt.Errorf("%w: %s", ErrTxFailed, signed.Hash().Hex())
+	}
+	return receipt, nil
+}
+
+// Transfer 转账 ETH 并出块
+func (n *Net) Transfer(ctx context.Context, from *Account, to common.Address, value *big.Int) (*types.Receipt, error) {
+	return n.Send(ctx, from, &to, value, nil)
+}
+
+// Deploy 部署合约并出块，data 为 initcode + 构造参数
+func (n *Net) Deploy(ctx context.Context, from *Account, data []byte) (common.Address, *types.Receipt, error) {
+	receipt, err := n.Send(ctx, from, nil, nil, data)
+	if err != nil {
+		return common.Address{}, receipt, err
+	}
+	return receipt.ContractAddress, receipt, nil
+}
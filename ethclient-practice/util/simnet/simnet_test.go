@@ -0,0 +1,340 @@
+package simnet_test
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	"github.com/dapp-learning/ethclient/util/simnet"
+	"github.com/dapp-learning/ethclient/util/simnet/contracts"
+)
+
+var ether = big.NewInt(1e18)
+
+func newNet(t *testing.T, opts *simnet.Options) *simnet.Net {
+	t.Helper()
+	net, err := simnet.New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { net.Close() })
+	return net
+}
+
+func head(t *testing.T, net *simnet.Net) *types.Header {
+	t.Helper()
+	h, err := net.Client.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestDeterministicBlocks(t *testing.T) {
+	ctx := context.Background()
+
+	// 两条独立的模拟链执行同样的操作，区块哈希、时间戳和合约地址都相同
+	var hashes [2][]common.Hash
+	var stores [2]common.Address
+	for i := range hashes {
+		net := newNet(t, nil)
+		if _, err := net.Transfer(ctx, net.Accounts[0], net.Accounts[1].Address, ether); err != nil {
+			t.Fatal(err)
+		}
+		if err := net.AdjustTime(time.Hour); err != nil {
+			t.Fatal(err)
+		}
+		h := head(t, net)
+		for n := uint64(0); n <= h.Number.Uint64(); n++ {
+			header, err := net.Client.HeaderByNumber(ctx, new(big.Int).SetUint64(n))
+			if err != nil {
+				t.Fatal(err)
+			}
+			hashes[i] = append(hashes[i], header.Hash())
+		}
+		stores[i] = net.Store
+	}
+	if len(hashes[0]) != len(hashes[1]) {
+		t.Fatalf("区块数不同: %d / %d", len(hashes[0]), len(hashes[1]))
+	}
+	for n := range hashes[0] {
+		if hashes[0][n] != hashes[1][n] {
+			t.Fatalf("区块 %d 哈希不同: %s / %s", n, hashes[0][n].Hex(), hashes[1][n].Hex())
+		}
+	}
+	if stores[0] != stores[1] {
+		t.Fatalf("Store 地址不同: %s / %s", stores[0].Hex(), stores[1].Hex())
+	}
+}
+
+func TestBlockTimestamps(t *testing.T) {
+	net := newNet(t, &simnet.Options{SkipContracts: true})
+	ctx := context.Background()
+
+	genesis, err := net.Client.HeaderByNumber(ctx, common.Big0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if genesis.Time != simnet.GenesisTime {
+		t.Fatalf("创世时间 %d，期望 %d", genesis.Time, simnet.GenesisTime)
+	}
+
+	net.Commit()
+	net.Commit()
+	if h := head(t, net); h.Number.Uint64() != 2 || h.Time != simnet.GenesisTime+2*simnet.BlockTime {
+		t.Fatalf("区块 %d 时间 %d，期望区块 2 时间 %d", h.Number, h.Time, simnet.GenesisTime+2*simnet.BlockTime)
+	}
+
+	// AdjustTime 出一个晚 d 的空块
+	if err := net.AdjustTime(90 * time.Second); err != nil {
+		t.Fatal(err)
+	}
+	h := head(t, net)
+	if want := simnet.GenesisTime + 2*simnet.BlockTime + 90; h.Time != want {
+		t.Fatalf("调整后时间 %d，期望 %d", h.Time, want)
+	}
+	if h.GasUsed != 0 {
+		t.Fatalf("调整时间的区块应为空块，GasUsed = %d", h.GasUsed)
+	}
+
+	if err := net.AdjustTime(time.Millisecond); err == nil {
+		t.Fatal("小于 1 秒的调整应返回错误")
+	}
+}
+
+func TestAdjustTimePendingTxs(t *testing.T) {
+	net := newNet(t, &simnet.Options{SkipContracts: true})
+	ctx := context.Background()
+
+	tx, err := types.SignTx(types.NewTx(&types.DynamicFeeTx{
+		ChainID:   net.ChainID,
+		GasTipCap: big.NewInt(1e9),
+		GasFeeCap: big.NewInt(1e10),
+		Gas:       21000,
+		To:        &net.Accounts[1].Address,
+		Value:     big.NewInt(1),
+	}), types.LatestSignerForChainID(net.ChainID), net.Accounts[0].Key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := net.Client.SendTransaction(ctx, tx); err != nil {
+		t.Fatal(err)
+	}
+	if err := net.AdjustTime(time.Minute); !errors.Is(err, simnet.ErrPendingTxs) {
+		t.Fatalf("交易池不为空时应返回 ErrPendingTxs，实际: %v", err)
+	}
+
+	// Commit 之后交易被打包，可以再调整时间
+	net.Commit()
+	if _, err := net.Client.TransactionReceipt(ctx, tx.Hash()); err != nil {
+		t.Fatal(err)
+	}
+	if err := net.AdjustTime(time.Minute); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueryAndTransfer(t *testing.T) {
+	net := newNet(t, &simnet.Options{SkipContracts: true})
+	ctx := context.Background()
+	alice, bob := net.Accounts[0], net.Accounts[1]
+
+	for _, account := range net.Accounts {
+		balance, err := net.Client.BalanceAt(ctx, account.Address, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if balance.Cmp(simnet.DefaultBalance) != 0 {
+			t.Fatalf("%s 初始余额 %s，期望 %s", account.Address.Hex(), balance, simnet.DefaultBalance)
+		}
+	}
+
+	receipt, err := net.Transfer(ctx, alice, bob.Address, ether)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 按区块查询：交易在收据记录的区块中
+	block, err := net.Client.BlockByNumber(ctx, receipt.BlockNumber)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Hash() != receipt.BlockHash || len(block.Transactions()) != 1 || block.Transactions()[0].Hash() != receipt.TxHash {
+		t.Fatalf("区块 %d 中没有找到交易 %s", block.NumberU64(), receipt.TxHash.Hex())
+	}
+
+	// 按哈希查询交易并恢复发送者
+	tx, pending, err := net.Client.TransactionByHash(ctx, receipt.TxHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(net.ChainID), tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pending || from != alice.Address || *tx.To() != bob.Address || tx.Value().Cmp(ether) != 0 {
+		t.Fatalf("交易字段不符: pending=%v from=%s to=%s value=%s", pending, from.Hex(), tx.To().Hex(), tx.Value())
+	}
+
+	// 余额变化：bob 收到 1 ETH，alice 付出 1 ETH + 手续费
+	fee := new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+	aliceBalance, _ := net.Client.BalanceAt(ctx, alice.Address, nil)
+	bobBalance, _ := net.Client.BalanceAt(ctx, bob.Address, nil)
+	if want := new(big.Int).Sub(simnet.DefaultBalance, new(big.Int).Add(ether, fee)); aliceBalance.Cmp(want) != 0 {
+		t.Fatalf("alice 余额 %s，期望 %s", aliceBalance, want)
+	}
+	if want := new(big.Int).Add(simnet.DefaultBalance, ether); bobBalance.Cmp(want) != 0 {
+		t.Fatalf("bob 余额 %s，期望 %s", bobBalance, want)
+	}
+	if receipt.GasUsed != 21000 {
+		t.Fatalf("转账 GasUsed = %d，期望 21000", receipt.GasUsed)
+	}
+}
+
+func TestDeployStore(t *testing.T) {
+	net := newNet(t, &simnet.Options{StoreVersion: "2.0"})
+	ctx := context.Background()
+	storeABI, err := abi.JSON(strings.NewReader(contracts.StoreABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 预置的 Store 是 solc 编译的真实字节码，运行时代码末尾带有 solc 元数据
+	code, err := net.Client.CodeAt(ctx, net.Store, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(common.Bytes2Hex(code), "64736f6c634300081e0033") {
+		t.Fatalf("Store 运行时代码不是 solc 0.8.30 的输出: ...%x", code[len(code)-16:])
+	}
+	if got := callString(t, net, storeABI, net.Store, "version"); got != "2.0" {
+		t.Fatalf("version() = %q，期望 2.0", got)
+	}
+
+	// 再部署一个：solc 编译的 Store 支持任意长度的 version
+	long := strings.Repeat("v", 100)
+	data, err := contracts.StoreDeployData(long)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, receipt, err := net.Deploy(ctx, net.Accounts[1], data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if receipt.ContractAddress != addr || addr == net.Store {
+		t.Fatalf("部署地址 %s，收据 %s", addr.Hex(), receipt.ContractAddress.Hex())
+	}
+	if got := callString(t, net, storeABI, addr, "version"); got != long {
+		t.Fatalf("version() = %q，期望 %q", got, long)
+	}
+}
+
+func TestCallStore(t *testing.T) {
+	net := newNet(t, nil)
+	ctx := context.Background()
+	storeABI, err := abi.JSON(strings.NewReader(contracts.StoreABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, value := common.HexToHash("0x01"), common.HexToHash("0xbeef")
+	data, err := storeABI.Pack("setItem", key, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt, err := net.Send(ctx, net.Accounts[0], &net.Store, nil, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ItemSet(bytes32 indexed key, bytes32 value)
+	if len(receipt.Logs) != 1 {
+		t.Fatalf("收据中有 %d 条日志，期望 1", len(receipt.Logs))
+	}
+	log := receipt.Logs[0]
+	if log.Address != net.Store || log.Topics[0] != storeABI.Events["ItemSet"].ID || log.Topics[1] != key || common.BytesToHash(log.Data) != value {
+		t.Fatalf("ItemSet 日志不符: %+v", log)
+	}
+
+	// 过滤日志得到同一条事件
+	logs, err := net.Client.FilterLogs(ctx, ethereum.FilterQuery{Addresses: []common.Address{net.Store}, Topics: [][]common.Hash{{storeABI.Events["ItemSet"].ID}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 1 || logs[0].TxHash != receipt.TxHash {
+		t.Fatalf("FilterLogs 返回 %d 条日志", len(logs))
+	}
+
+	// items(key) 和 getItem(key) 都读到写入的值
+	for _, method := range []string{"items", "getItem"} {
+		input, _ := storeABI.Pack(method, key)
+		out, err := net.Client.CallContract(ctx, ethereum.CallMsg{To: &net.Store, Data: input}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if common.BytesToHash(out) != value {
+			t.Fatalf("%s() = %x，期望 %s", method, out, value.Hex())
+		}
+	}
+}
+
+func TestTokenTransfer(t *testing.T) {
+	net := newNet(t, nil)
+	ctx := context.Background()
+	tokenABI, err := abi.JSON(strings.NewReader(contracts.TokenABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, bob := net.Accounts[0], net.Accounts[1]
+
+	amount := new(big.Int).Mul(big.NewInt(250), ether)
+	data, _ := tokenABI.Pack("transfer", bob.Address, amount)
+	if _, err := net.Send(ctx, alice, &net.Token, nil, data); err != nil {
+		t.Fatal(err)
+	}
+	if got := tokenBalance(t, net, tokenABI, bob.Address); got.Cmp(amount) != 0 {
+		t.Fatalf("bob 代币余额 %s，期望 %s", got, amount)
+	}
+	if got, want := tokenBalance(t, net, tokenABI, alice.Address), new(big.Int).Sub(simnet.DefaultTokenSupply, amount); got.Cmp(want) != 0 {
+		t.Fatalf("alice 代币余额 %s，期望 %s", got, want)
+	}
+
+	// 余额不足时模拟调用返回 revert 原因
+	data, _ = tokenABI.Pack("transfer", alice.Address, new(big.Int).Add(amount, common.Big1))
+	msg := ethereum.CallMsg{From: bob.Address, To: &net.Token, Data: data}
+	if _, err := net.Client.CallContract(ctx, msg, nil); err == nil || !strings.Contains(err.Error(), contracts.ErrInsufficientBalance) {
+		t.Fatalf("余额不足应 revert %q，实际: %v", contracts.ErrInsufficientBalance, err)
+	}
+}
+
+func callString(t *testing.T, net *simnet.Net, contractABI abi.ABI, addr common.Address, method string) string {
+	t.Helper()
+	input, _ := contractABI.Pack(method)
+	out, err := net.Client.CallContract(context.Background(), ethereum.CallMsg{To: &addr, Data: input}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values, err := contractABI.Unpack(method, out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return values[0].(string)
+}
+
+func tokenBalance(t *testing.T, net *simnet.Net, tokenABI abi.ABI, owner common.Address) *big.Int {
+	t.Helper()
+	input, _ := tokenABI.Pack("balanceOf", owner)
+	out, err := net.Client.CallContract(context.Background(), ethereum.CallMsg{To: &net.Token, Data: input}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return new(big.Int).SetBytes(out)
+}
@@ -0,0 +1,328 @@
+package userop
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/internal/abiword"
+)
+
+// Client bundler 的 JSON-RPC 客户端（ERC-7769）。bundler 只实现 eth_sendUserOperation 等少数方法，
+// 查询链上状态仍然需要普通节点
+type Client struct {
+	c   chain.RPCCaller
+	rpc *rpc.Client // 由 Dial 创建时非 nil，Close 时关闭
+}
+
+// NewClient 用已有的 RPC 连接创建客户端
+func NewClient(c chain.RPCCaller) *Client {
+	return &Client{c: c}
+}
+
+// Dial 连接 bundler，例如 https://api.pimlico.io/v2/sepolia/rpc?apikey=...
+func Dial(url string) (*Client, error) {
+	c, err := rpc.Dial(url)
+	if err != nil {
+		return nil, fmt.Errorf("连接 bundler 失败: %w", err)
+	}
+	return &Client{c: c, rpc: c}, nil
+}
+
+// Close 关闭由 Dial 创建的连接
+func (b *Client) Close() {
+	if b.rpc != nil {
+		b.rpc.Close()
+	}
+}
+
+// ChainID bundler 所在链的链 ID
+func (b *Client) ChainID(ctx context.Context) (*big.Int, error) {
+	var id hexutil.Big
+	if err := b.c.CallContext(ctx, &id, "eth_chainId"); err != nil {
+		return nil, fmt.Errorf("获取链 ID 失败: %w", err)
+	}
+	return id.ToInt(), nil
+}
+
+// SupportedEntryPoints bundler 支持的 EntryPoint 地址
+func (b *Client) SupportedEntryPoints(ctx context.Context) ([]common.Address, error) {
+	var eps []common.Address
+	if err := b.c.CallContext(ctx, &eps, "eth_supportedEntryPoints"); err != nil {
+		return nil, fmt.Errorf("查询支持的 EntryPoint 失败: %w", err)
+	}
+	return eps, nil
+}
+
+// GasEstimate eth_estimateUserOperationGas 的结果
+type GasEstimate struct {
+	PreVerificationGas            uint64
+	VerificationGasLimit          uint64
+	CallGasLimit                  uint64
+	PaymasterVerificationGasLimit uint64 // 仅 v0.7 且使用 paymaster 时
+	PaymasterPostOpGasLimit       uint64
+}
+
+// Apply 把估算结果写入 op
+func (e *GasEstimate) Apply(op *UserOperation) {
+	op.PreVerificationGas = e.PreVerificationGas
+	op.VerificationGasLimit = e.VerificationGasLimit
+	op.CallGasLimit = e.CallGasLimit
+	if op.Paymaster != nil {
+		op.PaymasterVerificationGasLimit = e.PaymasterVerificationGasLimit
+		op.PaymasterPostOpGasLimit = e.PaymasterPostOpGasLimit
+	}
+}
+
+// EstimateGas 调用 eth_estimateUserOperationGas。op 的 Gas 字段可以为 0，
+// 签名应使用 DummySignature 之类能被账户解析的占位值，否则验证阶段会提前失败
+func (b *Client) EstimateGas(ctx context.Context, op *UserOperation, ep EntryPoint) (*GasEstimate, error) {
+	var res rpcGasEstimate
+	if err := b.c.CallContext(ctx, &res, "eth_estimateUserOperationGas", toRPC(op, ep.Version), ep.Address); err != nil {
+		return nil, fmt.Errorf("估算 UserOperation Gas 失败: %w", err)
+	}
+	return &GasEstimate{
+		PreVerificationGas:            uint64(res.PreVerificationGas),
+		VerificationGasLimit:          uint64(res.VerificationGasLimit),
+		CallGasLimit:                  uint64(res.CallGasLimit),
+		PaymasterVerificationGasLimit: uint64(res.PaymasterVerificationGasLimit),
+		PaymasterPostOpGasLimit:       uint64(res.PaymasterPostOpGasLimit),
+	}, nil
+}
+
+// Send 调用 eth_sendUserOperation，返回 bundler 计算的 userOpHash。
+// bundler 会先模拟验证，签名错误、nonce 不对或存款不足时直接返回错误（错误码 -32500 等）
+func (b *Client) Send(ctx context.Context, op *UserOperation, ep EntryPoint) (common.Hash, error) {
+	var hash common.Hash
+	if err := b.c.CallContext(ctx, &hash, "eth_sendUserOperation", toRPC(op, ep.Version), ep.Address); err != nil {
+		return common.Hash{}, fmt.Errorf("发送 UserOperation 失败: %w", err)
+	}
+	return hash, nil
+}
+
+// Receipt UserOperation 的执行结果，来自 EntryPoint 的 UserOperationEvent
+type Receipt struct {
+	UserOpHash    common.Hash
+	EntryPoint    common.Address
+	Sender        common.Address
+	Nonce         *big.Int
+	Paymaster     common.Address // 自己付费时为零地址
+	ActualGasCost *big.Int       // 实际支付的费用（wei）
+	ActualGasUsed uint64
+	Success       bool   // callData 是否执行成功；验证失败的 UserOperation 不会上链
+	Reason        string // 执行失败时的 revert 数据
+	TxHash        common.Hash
+	BlockNumber   *big.Int
+}
+
+// GetReceipt 调用 eth_getUserOperationReceipt。UserOperation 还没有上链时返回 ethereum.NotFound
+func (b *Client) GetReceipt(ctx context.Context, hash common.Hash) (*Receipt, error) {
+	var res *rpcReceipt
+	if err := b.c.CallContext(ctx, &res, "eth_getUserOperationReceipt", hash); err != nil {
+		return nil, fmt.Errorf("查询 UserOperation 收据失败: %w", err)
+	}
+	if res == nil {
+		return nil, ethereum.NotFound
+	}
+	return res.receipt(), nil
+}
+
+// WaitReceipt 每隔 interval 查询一次收据，直到 UserOperation 上链或 ctx 结束
+func (b *Client) WaitReceipt(ctx context.Context, hash common.Hash, interval time.Duration) (*Receipt, error) {
+	if interval <= 0 {
+		interval = chain.DefaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		receipt, err := b.GetReceipt(ctx, hash)
+		if err == nil {
+			return receipt, nil
+		}
+		if !errors.Is(err, ethereum.NotFound) {
+			return nil, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("等待 UserOperation %s 上链超时: %w", hash.Hex(), ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// rpcUserOp JSON-RPC 中的 UserOperation。v0.6 使用 initCode / paymasterAndData，
+// v0.7 拆成 factory / factoryData 和 paymaster 的四个字段，没有时省略
+type rpcUserOp struct {
+	Sender                        common.Address  `json:"sender"`
+	Nonce                         *hexutil.Big    `json:"nonce"`
+	InitCode                      *hexutil.Bytes  `json:"initCode,omitempty"`
+	Factory                       *common.Address `json:"factory,omitempty"`
+	FactoryData                   *hexutil.Bytes  `json:"factoryData,omitempty"`
+	CallData                      hexutil.Bytes   `json:"callData"`
+	CallGasLimit                  quantity        `json:"callGasLimit"`
+	VerificationGasLimit          quantity        `json:"verificationGasLimit"`
+	PreVerificationGas            quantity        `json:"preVerificationGas"`
+	MaxFeePerGas                  *hexutil.Big    `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas          *hexutil.Big    `json:"maxPriorityFeePerGas"`
+	PaymasterAndData              *hexutil.Bytes  `json:"paymasterAndData,omitempty"`
+	Paymaster                     *common.Address `json:"paymaster,omitempty"`
+	PaymasterVerificationGasLimit *quantity       `json:"paymasterVerificationGasLimit,omitempty"`
+	PaymasterPostOpGasLimit       *quantity       `json:"paymasterPostOpGasLimit,omitempty"`
+	PaymasterData                 *hexutil.Bytes  `json:"paymasterData,omitempty"`
+	Signature                     hexutil.Bytes   `json:"signature"`
+}
+
+func toRPC(op *UserOperation, v Version) *rpcUserOp {
+	r := &rpcUserOp{
+		Sender:               op.Sender,
+		Nonce:                (*hexutil.Big)(abiword.Big(op.Nonce)),
+		CallData:             op.CallData,
+		CallGasLimit:         quantity(op.CallGasLimit),
+		VerificationGasLimit: quantity(op.VerificationGasLimit),
+		PreVerificationGas:   quantity(op.PreVerificationGas),
+		MaxFeePerGas:         (*hexutil.Big)(abiword.Big(op.MaxFeePerGas)),
+		MaxPriorityFeePerGas: (*hexutil.Big)(abiword.Big(op.MaxPriorityFeePerGas)),
+		Signature:            op.Signature,
+	}
+	if r.CallData == nil {
+		r.CallData = hexutil.Bytes{}
+	}
+	if v < V07 {
+		initCode, pmData := hexutil.Bytes(op.InitCode()), hexutil.Bytes(op.PaymasterAndData(V06))
+		r.InitCode, r.PaymasterAndData = &initCode, &pmData
+		return r
+	}
+	if op.Factory != nil {
+		data := hexutil.Bytes(op.FactoryData)
+		r.Factory, r.FactoryData = op.Factory, &data
+	}
+	if op.Paymaster != nil {
+		verification, postOp, data := quantity(op.PaymasterVerificationGasLimit), quantity(op.PaymasterPostOpGasLimit), hexutil.Bytes(op.PaymasterData)
+		r.Paymaster, r.PaymasterVerificationGasLimit, r.PaymasterPostOpGasLimit, r.PaymasterData = op.Paymaster, &verification, &postOp, &data
+	}
+	return r
+}
+
+// userOp 转换回 UserOperation。v0.6 的 initCode 和 paymasterAndData 按地址拆开
+func (r *rpcUserOp) userOp() (*UserOperation, error) {
+	op := &UserOperation{
+		Sender:               r.Sender,
+		Nonce:                (*big.Int)(r.Nonce),
+		CallData:             r.CallData,
+		CallGasLimit:         uint64(r.CallGasLimit),
+		VerificationGasLimit: uint64(r.VerificationGasLimit),
+		PreVerificationGas:   uint64(r.PreVerificationGas),
+		MaxFeePerGas:         (*big.Int)(r.MaxFeePerGas),
+		MaxPriorityFeePerGas: (*big.Int)(r.MaxPriorityFeePerGas),
+		Factory:              r.Factory,
+		Paymaster:            r.Paymaster,
+		Signature:            r.Signature,
+	}
+	if op.Nonce == nil {
+		return nil, errors.New("缺少 nonce")
+	}
+	if r.FactoryData != nil {
+		op.FactoryData = *r.FactoryData
+	}
+	if r.PaymasterData != nil {
+		op.PaymasterData = *r.PaymasterData
+	}
+	if r.PaymasterVerificationGasLimit != nil {
+		op.PaymasterVerificationGasLimit = uint64(*r.PaymasterVerificationGasLimit)
+	}
+	if r.PaymasterPostOpGasLimit != nil {
+		op.PaymasterPostOpGasLimit = uint64(*r.PaymasterPostOpGasLimit)
+	}
+	if r.InitCode != nil && len(*r.InitCode) > 0 {
+		if len(*r.InitCode) < common.AddressLength {
+			return nil, errors.New("initCode 不足 20 字节")
+		}
+		factory := common.BytesToAddress((*r.InitCode)[:common.AddressLength])
+		op.Factory, op.FactoryData = &factory, (*r.InitCode)[common.AddressLength:]
+	}
+	if r.PaymasterAndData != nil && len(*r.PaymasterAndData) > 0 {
+		if len(*r.PaymasterAndData) < common.AddressLength {
+			return nil, errors.New("paymasterAndData 不足 20 字节")
+		}
+		paymaster := common.BytesToAddress((*r.PaymasterAndData)[:common.AddressLength])
+		op.Paymaster, op.PaymasterData = &paymaster, (*r.PaymasterAndData)[common.AddressLength:]
+	}
+	return op, nil
+}
+
+type rpcGasEstimate struct {
+	PreVerificationGas            quantity `json:"preVerificationGas"`
+	VerificationGasLimit          quantity `json:"verificationGasLimit"`
+	CallGasLimit                  quantity `json:"callGasLimit"`
+	PaymasterVerificationGasLimit quantity `json:"paymasterVerificationGasLimit,omitempty"`
+	PaymasterPostOpGasLimit       quantity `json:"paymasterPostOpGasLimit,omitempty"`
+}
+
+type rpcReceipt struct {
+	UserOpHash    common.Hash    `json:"userOpHash"`
+	EntryPoint    common.Address `json:"entryPoint"`
+	Sender        common.Address `json:"sender"`
+	Nonce         *hexutil.Big   `json:"nonce"`
+	Paymaster     common.Address `json:"paymaster"`
+	ActualGasCost *hexutil.Big   `json:"actualGasCost"`
+	ActualGasUsed quantity       `json:"actualGasUsed"`
+	Success       bool           `json:"success"`
+	Reason        string         `json:"reason"`
+	// Receipt 打包交易的收据，这里只解析用到的字段
+	Receipt struct {
+		TransactionHash common.Hash  `json:"transactionHash"`
+		BlockNumber     *hexutil.Big `json:"blockNumber"`
+	} `json:"receipt"`
+}
+
+func (r *rpcReceipt) receipt() *Receipt {
+	return &Receipt{
+		UserOpHash:    r.UserOpHash,
+		EntryPoint:    r.EntryPoint,
+		Sender:        r.Sender,
+		Nonce:         (*big.Int)(r.Nonce),
+		Paymaster:     r.Paymaster,
+		ActualGasCost: (*big.Int)(r.ActualGasCost),
+		ActualGasUsed: uint64(r.ActualGasUsed),
+		Success:       r.Success,
+		Reason:        r.Reason,
+		TxHash:        r.Receipt.TransactionHash,
+		BlockNumber:   (*big.Int)(r.Receipt.BlockNumber),
+	}
+}
+
+// quantity 编码为十六进制的整数。部分 v0.6 bundler 的估算结果是十进制数字，解码时两种都接受
+type quantity uint64
+
+func (q quantity) MarshalJSON() ([]byte, error) {
+	return json.Marshal(hexutil.Uint64(q))
+}
+
+func (q *quantity) UnmarshalJSON(input []byte) error {
+	s := strings.Trim(string(input), `"`)
+	var (
+		v   uint64
+		err error
+	)
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		v, err = hexutil.DecodeUint64(s)
+	} else {
+		v, err = strconv.ParseUint(s, 10, 64)
+	}
+	if err != nil {
+		return fmt.Errorf("无效的数值 %s: %w", input, err)
+	}
+	*q = quantity(v)
+	return nil
+}
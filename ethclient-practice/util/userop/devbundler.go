@@ -0,0 +1,321 @@
+package userop
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/simnet/contracts"
+)
+
+// 账户尚未部署时无法在链上单独执行 validateUserOp 和 callData，只能使用固定的估算值
+const (
+	DevValidationGas = 60_000  // 账户验证签名的 Gas
+	DevCallGas       = 100_000 // 执行 callData 的 Gas
+	DevBundleGas     = 10_000  // handleOps 中不计入单个 UserOperation 的开销（循环、给 beneficiary 转账）
+)
+
+// bundler JSON-RPC 错误码（ERC-7769）
+const (
+	codeInvalidParams = -32602 // 字段无效或不支持的 EntryPoint
+	codeRejected      = -32500 // 验证失败，例如签名错误、nonce 不对、存款不足
+	codeReverted      = -32521 // 估算时 callData 执行失败
+)
+
+// handleOps 和 UserOperation 相关事件使用 EntryPoint 的 ABI，validateUserOp 属于账户的 IAccount 接口，
+// 两者的签名都与正式的 EntryPoint v0.7 相同
+var (
+	entryPointABI = mustParseABI(contracts.EntryPointABI)
+	accountABI    = mustParseABI(contracts.SimpleAccountABI)
+)
+
+func mustParseABI(s string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// DevBackend 本地 bundler 需要的节点接口，*ethclient.Client 和 simnet.Client 都实现了它
+type DevBackend interface {
+	chain.TransactionSender
+	chain.ReceiptReader
+	chain.ContractCaller
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+}
+
+// DevBundler 开发和测试用的 bundler：每收到一个 UserOperation 就模拟执行 handleOps，
+// 成功后立即用 Key 发送只包含它的 handleOps 交易，收取的费用归 Key 对应的地址。
+// 只支持 EntryPoint v0.7，没有内存池、信誉系统和 ERC-7562 的验证规则检查，不要在公开网络上运行
+type DevBundler struct {
+	Backend    DevBackend
+	Key        *ecdsa.PrivateKey
+	EntryPoint common.Address
+	// AfterSend 在 handleOps 交易发送后调用。模拟链只在 Commit 时出块，可以在这里 Commit
+	AfterSend func()
+
+	mu  sync.Mutex
+	txs map[common.Hash]common.Hash // userOpHash → handleOps 交易哈希
+}
+
+// NewDevBundler 创建本地 bundler
+func NewDevBundler(backend DevBackend, key *ecdsa.PrivateKey, entryPoint common.Address) *DevBundler {
+	return &DevBundler{Backend: backend, Key: key, EntryPoint: entryPoint, txs: make(map[common.Hash]common.Hash)}
+}
+
+// Server 注册 eth_sendUserOperation 等方法的 JSON-RPC 服务。*rpc.Server 实现了 http.Handler，
+// 可以直接交给 http.Serve；也可以用 rpc.DialInProc 在进程内连接
+func (b *DevBundler) Server() (*rpc.Server, error) {
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("eth", &devAPI{b}); err != nil {
+		return nil, fmt.Errorf("注册 bundler 接口失败: %w", err)
+	}
+	return srv, nil
+}
+
+// devAPI eth 命名空间下的 bundler 方法，方法名按 geth rpc 的规则转换为 eth_xxx
+type devAPI struct {
+	b *DevBundler
+}
+
+// rpcError 带错误码的 JSON-RPC 错误
+type rpcError struct {
+	code int
+	msg  string
+}
+
+func (e *rpcError) Error() string  { return e.msg }
+func (e *rpcError) ErrorCode() int { return e.code }
+
+func (api *devAPI) ChainId(ctx context.Context) (*hexutil.Big, error) {
+	id, err := api.b.Backend.ChainID(ctx)
+	return (*hexutil.Big)(id), err
+}
+
+func (api *devAPI) SupportedEntryPoints() []common.Address {
+	return []common.Address{api.b.EntryPoint}
+}
+
+func (api *devAPI) EstimateUserOperationGas(ctx context.Context, arg rpcUserOp, ep common.Address) (*rpcGasEstimate, error) {
+	op, err := api.b.userOp(&arg, ep)
+	if err != nil {
+		return nil, err
+	}
+	if len(op.Signature) == 0 {
+		op.Signature = DummySignature
+	}
+	est, err := api.b.estimate(ctx, op)
+	if err != nil {
+		return nil, err
+	}
+	return &rpcGasEstimate{
+		PreVerificationGas:   quantity(est.PreVerificationGas),
+		VerificationGasLimit: quantity(est.VerificationGasLimit),
+		CallGasLimit:         quantity(est.CallGasLimit),
+	}, nil
+}
+
+func (api *devAPI) SendUserOperation(ctx context.Context, arg rpcUserOp, ep common.Address) (common.Hash, error) {
+	op, err := api.b.userOp(&arg, ep)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return api.b.send(ctx, op)
+}
+
+func (api *devAPI) GetUserOperationReceipt(ctx context.Context, hash common.Hash) (*rpcReceipt, error) {
+	return api.b.receipt(ctx, hash)
+}
+
+// userOp 检查 EntryPoint 并转换参数
+func (b *DevBundler) userOp(arg *rpcUserOp, ep common.Address) (*UserOperation, error) {
+	if ep != b.EntryPoint {
+		return nil, &rpcError{codeInvalidParams, fmt.Sprintf("不支持的 EntryPoint %s", ep.Hex())}
+	}
+	op, err := arg.userOp()
+	if err != nil {
+		return nil, &rpcError{codeInvalidParams, err.Error()}
+	}
+	if op.Paymaster != nil {
+		return nil, &rpcError{codeInvalidParams, "本地 bundler 不支持 paymaster"}
+	}
+	return op, nil
+}
+
+// estimate 以 EntryPoint 为调用者分别估算部署、验证和执行的 Gas，
+// preVerificationGas 为只含这一个 UserOperation 的 handleOps 交易的固有 Gas 加上 DevBundleGas
+func (b *DevBundler) estimate(ctx context.Context, op *UserOperation) (*GasEstimate, error) {
+	code, err := b.Backend.CodeAt(ctx, op.Sender, nil)
+	if err != nil {
+		return nil, err
+	}
+	deployed := len(code) > 0
+	switch {
+	case deployed && op.Factory != nil:
+		return nil, &rpcError{codeRejected, "AA10 sender already constructed"}
+	case !deployed && op.Factory == nil:
+		return nil, &rpcError{codeRejected, "AA20 account not deployed"}
+	}
+
+	est := &GasEstimate{VerificationGasLimit: DevValidationGas, CallGasLimit: DevCallGas}
+	if op.Factory != nil {
+		gas, err := b.Backend.EstimateGas(ctx, ethereum.CallMsg{From: b.EntryPoint, To: op.Factory, Data: op.FactoryData})
+		if err != nil {
+			return nil, &rpcError{codeRejected, "AA13 initCode failed: " + err.Error()}
+		}
+		est.VerificationGasLimit += gas
+	}
+	if deployed {
+		chainID, err := b.Backend.ChainID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		data, err := accountABI.Pack("validateUserOp", op.Pack(), op.Hash(EntryPoint{b.EntryPoint, V07}, chainID), new(big.Int))
+		if err != nil {
+			return nil, err
+		}
+		if est.VerificationGasLimit, err = b.Backend.EstimateGas(ctx, ethereum.CallMsg{From: b.EntryPoint, To: &op.Sender, Data: data}); err != nil {
+			return nil, &rpcError{codeRejected, "AA23 reverted: " + err.Error()}
+		}
+		if est.CallGasLimit, err = b.Backend.EstimateGas(ctx, ethereum.CallMsg{From: b.EntryPoint, To: &op.Sender, Data: op.CallData}); err != nil {
+			return nil, &rpcError{codeReverted, "callData 执行失败: " + err.Error()}
+		}
+	}
+	est.VerificationGasLimit = chain.WithMargin(est.VerificationGasLimit, 0)
+	est.CallGasLimit = chain.WithMargin(est.CallGasLimit, 0)
+
+	// 用估算后的 Gas 字段编码 handleOps，calldata 的长度与最终发送的交易一致
+	sized := *op
+	est.Apply(&sized)
+	data, err := b.handleOpsData(&sized)
+	if err != nil {
+		return nil, err
+	}
+	est.PreVerificationGas = params.TxGas + calldataGas(data) + DevBundleGas
+	return est, nil
+}
+
+// send 模拟执行 handleOps，通过后发送交易
+func (b *DevBundler) send(ctx context.Context, op *UserOperation) (common.Hash, error) {
+	chainID, err := b.Backend.ChainID(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	hash := op.Hash(EntryPoint{b.EntryPoint, V07}, chainID)
+	data, err := b.handleOpsData(op)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	from := crypto.PubkeyToAddress(b.Key.PublicKey)
+	if _, err := b.Backend.CallContract(ctx, ethereum.CallMsg{From: from, To: &b.EntryPoint, Data: data}, nil); err != nil {
+		return common.Hash{}, &rpcError{codeRejected, err.Error()}
+	}
+	tx, err := chain.Send(ctx, b.Backend, b.Key, &chain.TxRequest{To: &b.EntryPoint, Data: data})
+	if err != nil {
+		return common.Hash{}, err
+	}
+
+	b.mu.Lock()
+	if b.txs == nil {
+		b.txs = make(map[common.Hash]common.Hash)
+	}
+	b.txs[hash] = tx.Hash()
+	b.mu.Unlock()
+	if b.AfterSend != nil {
+		b.AfterSend()
+	}
+	return hash, nil
+}
+
+// receipt 从 handleOps 交易的日志中找出 UserOperation 的执行结果，交易还没有打包时返回 nil
+func (b *DevBundler) receipt(ctx context.Context, hash common.Hash) (*rpcReceipt, error) {
+	b.mu.Lock()
+	txHash, ok := b.txs[hash]
+	b.mu.Unlock()
+	if !ok {
+		return nil, nil
+	}
+	receipt, err := b.Backend.TransactionReceipt(ctx, txHash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		res    *rpcReceipt
+		reason []byte
+	)
+	for _, l := range receipt.Logs {
+		if l.Address != b.EntryPoint || len(l.Topics) < 3 || l.Topics[1] != hash {
+			continue
+		}
+		switch l.Topics[0] {
+		case entryPointABI.Events["UserOperationEvent"].ID:
+			values, err := entryPointABI.Unpack("UserOperationEvent", l.Data)
+			if err != nil {
+				return nil, fmt.Errorf("解析 UserOperationEvent 失败: %w", err)
+			}
+			res = &rpcReceipt{
+				UserOpHash:    hash,
+				EntryPoint:    b.EntryPoint,
+				Sender:        common.BytesToAddress(l.Topics[2].Bytes()),
+				Nonce:         (*hexutil.Big)(values[0].(*big.Int)),
+				Success:       values[1].(bool),
+				ActualGasCost: (*hexutil.Big)(values[2].(*big.Int)),
+				ActualGasUsed: quantity(values[3].(*big.Int).Uint64()),
+			}
+			if len(l.Topics) > 3 {
+				res.Paymaster = common.BytesToAddress(l.Topics[3].Bytes())
+			}
+		case entryPointABI.Events["UserOperationRevertReason"].ID:
+			values, err := entryPointABI.Unpack("UserOperationRevertReason", l.Data)
+			if err != nil {
+				return nil, fmt.Errorf("解析 UserOperationRevertReason 失败: %w", err)
+			}
+			reason = values[1].([]byte)
+		}
+	}
+	if res == nil {
+		return nil, fmt.Errorf("交易 %s 中没有 UserOperation %s 的事件", txHash.Hex(), hash.Hex())
+	}
+	if reason != nil {
+		res.Reason = hexutil.Encode(reason)
+	}
+	res.Receipt.TransactionHash = receipt.TxHash
+	res.Receipt.BlockNumber = (*hexutil.Big)(receipt.BlockNumber)
+	return res, nil
+}
+
+// handleOpsData 编码 handleOps([op], beneficiary)，beneficiary 为 bundler 自己
+func (b *DevBundler) handleOpsData(op *UserOperation) ([]byte, error) {
+	return entryPointABI.Pack("handleOps", []PackedUserOperation{op.Pack()}, crypto.PubkeyToAddress(b.Key.PublicKey))
+}
+
+// calldataGas 交易数据的固有 Gas：零字节 4，非零字节 16
+func calldataGas(data []byte) uint64 {
+	var gas uint64
+	for _, c := range data {
+		if c == 0 {
+			gas += params.TxDataZeroGas
+		} else {
+			gas += params.TxDataNonZeroGasEIP2028
+		}
+	}
+	return gas
+}
@@ -0,0 +1,169 @@
+package userop_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/dapp-learning/ethclient/util/simnet"
+	"github.com/dapp-learning/ethclient/util/simnet/contracts"
+	"github.com/dapp-learning/ethclient/util/userop"
+)
+
+// devBundler 在模拟链上部署 EntryPoint 和账户工厂，通过 HTTP 提供本地 bundler
+func devBundler(t *testing.T) (*simnet.Net, *userop.Client, userop.EntryPoint, common.Address) {
+	t.Helper()
+	ctx := context.Background()
+	net, err := simnet.New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { net.Close() })
+	epAddress, _, err := net.Deploy(ctx, net.Accounts[0], contracts.EntryPointDeployData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	factory, _, err := net.Deploy(ctx, net.Accounts[0], contracts.AccountFactoryDeployData(epAddress))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dev := userop.NewDevBundler(net.Client, net.Accounts[2].Key, epAddress)
+	dev.AfterSend = func() { net.Commit() }
+	srv, err := dev.Server()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts := httptest.NewServer(srv)
+	t.Cleanup(func() {
+		ts.Close()
+		srv.Stop()
+	})
+	bundler, err := userop.Dial(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(bundler.Close)
+	return net, bundler, userop.EntryPoint{Address: epAddress, Version: userop.V07}, factory
+}
+
+// prepare 查询 nonce 和费用，通过 bundler 估算 Gas，最后用 key 签名
+func prepare(t *testing.T, net *simnet.Net, bundler *userop.Client, ep userop.EntryPoint, op *userop.UserOperation, key *ecdsa.PrivateKey) {
+	t.Helper()
+	ctx := context.Background()
+	if op.Nonce == nil {
+		nonce, err := userop.GetNonce(ctx, net.Client, ep, op.Sender, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		op.Nonce = nonce
+	}
+	if err := op.SuggestFees(ctx, net.Client); err != nil {
+		t.Fatal(err)
+	}
+	op.Signature = userop.DummySignature
+	gas, err := bundler.EstimateGas(ctx, op, ep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gas.Apply(op)
+	if err := op.Sign(key, ep, net.ChainID); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDevBundler(t *testing.T) {
+	net, bundler, ep, factory := devBundler(t)
+	ctx := context.Background()
+	owner := net.Accounts[1]
+	bob := simnet.NewAccount(99).Address
+
+	eps, err := bundler.SupportedEntryPoints(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(eps) != 1 || eps[0] != ep.Address {
+		t.Fatalf("SupportedEntryPoints = %v", eps)
+	}
+
+	// 账户部署之前先转入 ETH，第一个 UserOperation 部署账户并向 bob 转账
+	salt := big.NewInt(0)
+	account := contracts.AccountAddress(factory, ep.Address, owner.Address, salt)
+	if _, err := net.Transfer(ctx, net.Accounts[0], account, big.NewInt(1e18)); err != nil {
+		t.Fatal(err)
+	}
+	factoryData, err := contracts.CreateAccountData(owner.Address, salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	callData, err := contracts.ExecuteData(bob, big.NewInt(1e17), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := &userop.UserOperation{Sender: account, Factory: &factory, FactoryData: factoryData, CallData: callData}
+	prepare(t, net, bundler, ep, op, owner.Key)
+
+	hash, err := bundler.Send(ctx, op, ep)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hash != op.Hash(ep, net.ChainID) {
+		t.Fatalf("bundler 返回的 userOpHash %s 与本地计算的不同", hash.Hex())
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	receipt, err := bundler.WaitReceipt(waitCtx, hash, 10*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !receipt.Success || receipt.Sender != account || receipt.Nonce.Sign() != 0 || receipt.ActualGasCost.Sign() <= 0 {
+		t.Fatalf("收据 %+v", receipt)
+	}
+	balance, err := net.Client.BalanceAt(ctx, bob, receipt.BlockNumber)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Cmp(big.NewInt(1e17)) != 0 {
+		t.Fatalf("bob 的余额 %s", balance)
+	}
+
+	// 账户已部署：签名错误或 nonce 不对时 bundler 模拟失败，返回 -32500 且不发送交易
+	tests := []struct {
+		name   string
+		nonce  *big.Int
+		key    *ecdsa.PrivateKey
+		reason string
+	}{
+		{"bad signature", nil, net.Accounts[3].Key, contracts.ErrSignature},
+		{"wrong nonce", big.NewInt(5), owner.Key, contracts.ErrInvalidNonce},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := &userop.UserOperation{Sender: account, Nonce: tt.nonce, CallData: callData}
+			prepare(t, net, bundler, ep, op, tt.key)
+			head, err := net.Client.BlockNumber(ctx)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = bundler.Send(ctx, op, ep)
+			var rpcErr rpc.Error
+			if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != -32500 {
+				t.Fatalf("Send 返回 %v，期望错误码 -32500", err)
+			}
+			if !strings.Contains(err.Error(), tt.reason) {
+				t.Errorf("错误 %q 中没有 %q", err, tt.reason)
+			}
+			if after, err := net.Client.BlockNumber(ctx); err != nil || after != head {
+				t.Fatalf("被拒绝的 UserOperation 不应出块（%d → %d）", head, after)
+			}
+		})
+	}
+}
@@ -0,0 +1,260 @@
+// Package userop 构造和签名 ERC-4337 UserOperation，并通过 bundler 的 JSON-RPC 接口发送。
+//
+// 智能合约账户不能自己发起交易，而是把要执行的调用写进 UserOperation 交给 bundler；
+// bundler 把多个 UserOperation 打包成一笔调用 EntryPoint.handleOps 的普通交易，
+// EntryPoint 先调用账户的 validateUserOp 验证签名并收取费用，再执行 callData。
+//
+//	op := &userop.UserOperation{Sender: account, Nonce: nonce, CallData: callData}
+//	op.Signature = userop.DummySignature
+//	gas, err := bundler.EstimateGas(ctx, op, userop.EntryPointV07)
+//	gas.Apply(op)
+//	err = op.Sign(key, userop.EntryPointV07, chainID)
+//	hash, err := bundler.Send(ctx, op, userop.EntryPointV07)
+//	receipt, err := bundler.WaitReceipt(ctx, hash, time.Second)
+//
+// 同时支持 EntryPoint v0.6 和 v0.7：两者的 UserOperation 字段含义相同，
+// 但链上的打包格式、userOpHash 的计算方式和 JSON-RPC 中的字段名不同。
+package userop
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/dapp-learning/ethclient/util/chain"
+	"github.com/dapp-learning/ethclient/util/internal/abiword"
+)
+
+// Version EntryPoint 版本
+type Version uint8
+
+const (
+	V06 Version = 6
+	V07 Version = 7
+)
+
+func (v Version) String() string { return fmt.Sprintf("v0.%d", v) }
+
+// EntryPoint 一个 EntryPoint 合约。UserOperation 的哈希和编码取决于它的版本
+type EntryPoint struct {
+	Address common.Address
+	Version Version
+}
+
+// 主网和各测试网上通过 CREATE2 部署在相同地址的 EntryPoint
+var (
+	EntryPointV06 = EntryPoint{common.HexToAddress("0x5FF137D4b0FDCD49DcA30c7CF57E578a026d2789"), V06}
+	EntryPointV07 = EntryPoint{common.HexToAddress("0x0000000071727De22E5E9d8BAf0edAc6f37da032"), V07}
+)
+
+// DummySignature 估算 Gas 时使用的占位签名：长度与真实签名相同，能被 ecrecover 解析，
+// 账户的验证逻辑会走完整的路径（只是验证失败），估算的 verificationGasLimit 与真实签名接近
+var DummySignature = common.FromHex("0xfffffffffffffffffffffffffffffff0000000000000000000000000000000007aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa1c")
+
+// UserOperation 按 v0.7 的 JSON-RPC 格式拆开的 UserOperation。
+// v0.6 的 initCode 为 Factory | FactoryData，paymasterAndData 为 Paymaster | PaymasterData
+type UserOperation struct {
+	Sender               common.Address
+	Nonce                *big.Int
+	Factory              *common.Address // 账户尚未部署时由工厂创建，否则为 nil
+	FactoryData          []byte
+	CallData             []byte
+	CallGasLimit         uint64
+	VerificationGasLimit uint64
+	PreVerificationGas   uint64 // bundler 的固定开销和 calldata 费用
+	MaxFeePerGas         *big.Int
+	MaxPriorityFeePerGas *big.Int
+
+	Paymaster                     *common.Address // 代付费用的 paymaster，自己付费时为 nil
+	PaymasterVerificationGasLimit uint64          // 仅 v0.7
+	PaymasterPostOpGasLimit       uint64          // 仅 v0.7
+	PaymasterData                 []byte
+
+	Signature []byte
+}
+
+// InitCode 部署账户的 initCode：工厂地址 | 调用工厂的数据
+func (op *UserOperation) InitCode() []byte {
+	if op.Factory == nil {
+		return nil
+	}
+	return append(op.Factory.Bytes(), op.FactoryData...)
+}
+
+// PaymasterAndData 按版本拼接的 paymasterAndData。v0.7 在地址之后多了两个 16 字节的 Gas 上限
+func (op *UserOperation) PaymasterAndData(v Version) []byte {
+	if op.Paymaster == nil {
+		return nil
+	}
+	data := op.Paymaster.Bytes()
+	if v >= V07 {
+		data = append(data, uint128(op.PaymasterVerificationGasLimit)...)
+		data = append(data, uint128(op.PaymasterPostOpGasLimit)...)
+	}
+	return append(data, op.PaymasterData...)
+}
+
+// RequiredPrefund 账户（或 paymaster）需要预先存入 EntryPoint 的最高费用，多余部分执行后退回存款
+func (op *UserOperation) RequiredPrefund() *big.Int {
+	gas := new(big.Int).SetUint64(op.CallGasLimit)
+	gas.Add(gas, new(big.Int).SetUint64(op.VerificationGasLimit))
+	gas.Add(gas, new(big.Int).SetUint64(op.PreVerificationGas))
+	gas.Add(gas, new(big.Int).SetUint64(op.PaymasterVerificationGasLimit))
+	gas.Add(gas, new(big.Int).SetUint64(op.PaymasterPostOpGasLimit))
+	return gas.Mul(gas, abiword.Big(op.MaxFeePerGas))
+}
+
+// PackedUserOperation v0.7 EntryPoint 链上使用的格式，字段与 Solidity 结构体一一对应，
+// 可以直接传给 abi 编码 handleOps 和 getUserOpHash 的参数
+type PackedUserOperation struct {
+	Sender             common.Address
+	Nonce              *big.Int
+	InitCode           []byte
+	CallData           []byte
+	AccountGasLimits   [32]byte // verificationGasLimit << 128 | callGasLimit
+	PreVerificationGas *big.Int
+	GasFees            [32]byte // maxPriorityFeePerGas << 128 | maxFeePerGas
+	PaymasterAndData   []byte
+	Signature          []byte
+}
+
+// Pack 转换为 v0.7 的链上格式
+func (op *UserOperation) Pack() PackedUserOperation {
+	var gasLimits, gasFees [32]byte
+	copy(gasLimits[:16], uint128(op.VerificationGasLimit))
+	copy(gasLimits[16:], uint128(op.CallGasLimit))
+	copy(gasFees[:16], common.LeftPadBytes(abiword.Big(op.MaxPriorityFeePerGas).Bytes(), 16))
+	copy(gasFees[16:], common.LeftPadBytes(abiword.Big(op.MaxFeePerGas).Bytes(), 16))
+	return PackedUserOperation{
+		Sender:             op.Sender,
+		Nonce:              abiword.Big(op.Nonce),
+		InitCode:           op.InitCode(),
+		CallData:           op.CallData,
+		AccountGasLimits:   gasLimits,
+		PreVerificationGas: new(big.Int).SetUint64(op.PreVerificationGas),
+		GasFees:            gasFees,
+		PaymasterAndData:   op.PaymasterAndData(V07),
+		Signature:          op.Signature,
+	}
+}
+
+// Hash 计算 userOpHash，与 EntryPoint.getUserOpHash 的结果相同：
+//
+//	keccak256(abi.encode(keccak256(pack(op)), entryPoint, chainId))
+//
+// pack 对 bytes 字段取哈希，不包含签名；v0.6 和 v0.7 的区别只在 pack 中 Gas 和费用字段的排列
+func (op *UserOperation) Hash(ep EntryPoint, chainID *big.Int) common.Hash {
+	var packed []byte
+	switch ep.Version {
+	case V06:
+		packed = abiword.Concat(
+			op.Sender.Bytes(),
+			abiword.Big(op.Nonce).Bytes(),
+			crypto.Keccak256(op.InitCode()),
+			crypto.Keccak256(op.CallData),
+			new(big.Int).SetUint64(op.CallGasLimit).Bytes(),
+			new(big.Int).SetUint64(op.VerificationGasLimit).Bytes(),
+			new(big.Int).SetUint64(op.PreVerificationGas).Bytes(),
+			abiword.Big(op.MaxFeePerGas).Bytes(),
+			abiword.Big(op.MaxPriorityFeePerGas).Bytes(),
+			crypto.Keccak256(op.PaymasterAndData(V06)),
+		)
+	default:
+		p := op.Pack()
+		packed = abiword.Concat(
+			p.Sender.Bytes(),
+			p.Nonce.Bytes(),
+			crypto.Keccak256(p.InitCode),
+			crypto.Keccak256(p.CallData),
+			p.AccountGasLimits[:],
+			p.PreVerificationGas.Bytes(),
+			p.GasFees[:],
+			crypto.Keccak256(p.PaymasterAndData),
+		)
+	}
+	return crypto.Keccak256Hash(abiword.Concat(crypto.Keccak256(packed), ep.Address.Bytes(), abiword.Big(chainID).Bytes()))
+}
+
+// Sign 用 key 签名 userOpHash 并写入 Signature。与 SimpleAccount 的验证方式一致，
+// 签名的是 personal_sign 格式的消息（"\x19Ethereum Signed Message:\n32" + userOpHash），v 为 27 / 28。
+// 其他账户实现的签名格式可能不同，例如加上验证模块的前缀
+func (op *UserOperation) Sign(key *ecdsa.PrivateKey, ep EntryPoint, chainID *big.Int) error {
+	hash := op.Hash(ep, chainID)
+	sig, err := crypto.Sign(accounts.TextHash(hash.Bytes()), key)
+	if err != nil {
+		return fmt.Errorf("签名 UserOperation 失败: %w", err)
+	}
+	sig[64] += 27
+	op.Signature = sig
+	return nil
+}
+
+// Signer 从签名恢复签名者地址，用于在发送前检查签名是否来自账户的 owner
+func (op *UserOperation) Signer(ep EntryPoint, chainID *big.Int) (common.Address, error) {
+	if len(op.Signature) != crypto.SignatureLength {
+		return common.Address{}, fmt.Errorf("签名长度应为 %d 字节，实际 %d 字节", crypto.SignatureLength, len(op.Signature))
+	}
+	sig := append([]byte{}, op.Signature...)
+	if sig[64] >= 27 {
+		sig[64] -= 27
+	}
+	pub, err := crypto.SigToPub(accounts.TextHash(op.Hash(ep, chainID).Bytes()), sig)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("恢复签名者失败: %w", err)
+	}
+	return crypto.PubkeyToAddress(*pub), nil
+}
+
+// FeeReader 估算费用需要的节点接口
+type FeeReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+}
+
+// SuggestFees 按节点的建议设置 MaxFeePerGas 和 MaxPriorityFeePerGas，最高费用使用 chain.MaxFeePerGas，
+// 与 chain.BuildTx 相同。部分 bundler 要求的最低价格更高，需要改用它们自己的接口查询
+func (op *UserOperation) SuggestFees(ctx context.Context, r FeeReader) error {
+	head, err := r.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("获取最新区块头失败: %w", err)
+	}
+	if head.BaseFee == nil {
+		return errors.New("节点不支持 EIP-1559，无法设置 UserOperation 的费用")
+	}
+	tip, err := r.SuggestGasTipCap(ctx)
+	if err != nil {
+		return fmt.Errorf("获取小费建议失败: %w", err)
+	}
+	op.MaxPriorityFeePerGas = tip
+	op.MaxFeePerGas = chain.MaxFeePerGas(head.BaseFee, tip)
+	return nil
+}
+
+// getNonceSelector getNonce(address,uint192)，v0.6 和 v0.7 相同
+var getNonceSelector = crypto.Keccak256([]byte("getNonce(address,uint192)"))[:4]
+
+// GetNonce 查询账户在 EntryPoint 中的 nonce。nonce 的高 192 位是 key，低 64 位是该 key 下的序号，
+// 不同 key 的 UserOperation 互不阻塞，可以并行发送；一般使用 key 0
+func GetNonce(ctx context.Context, c ethereum.ContractCaller, ep EntryPoint, sender common.Address, key *big.Int) (*big.Int, error) {
+	data := append(append([]byte{}, getNonceSelector...), abiword.Concat(sender.Bytes(), abiword.Big(key).Bytes())...)
+	out, err := c.CallContract(ctx, ethereum.CallMsg{To: &ep.Address, Data: data}, nil)
+	if err != nil {
+		return nil, fmt.Errorf("查询 %s 的 nonce 失败: %w", sender.Hex(), err)
+	}
+	if len(out) != 32 {
+		return nil, fmt.Errorf("getNonce 返回了 %d 字节，%s 上可能没有 EntryPoint", len(out), ep.Address.Hex())
+	}
+	return new(big.Int).SetBytes(out), nil
+}
+
+func uint128(v uint64) []byte {
+	return common.LeftPadBytes(new(big.Int).SetUint64(v).Bytes(), 16)
+}
@@ -0,0 +1,257 @@
+package userop_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+
+	"github.com/dapp-learning/ethclient/util/simnet"
+	"github.com/dapp-learning/ethclient/util/simnet/contracts"
+	"github.com/dapp-learning/ethclient/util/userop"
+)
+
+// entryPointV07 testdata/entrypoint-v0.7.json：取自 go-ethereum 的 erc7562 tracer 测试数据
+// （eth/tracers/internal/tracetest/testdata/erc7562_tracer/erc7562Tracer.test_simple.json），
+// code 是部署在 0x0000000071727De22E5E9d8BAf0edAc6f37da032 的正式 EntryPoint v0.7 运行时字节码，
+// handleOps 是链 1337 上的一笔 handleOps 调用，validateUserOp 是 EntryPoint 随后调用账户时传入的参数，
+// 其中的 userOpHash 由正式合约计算
+type entryPointV07 struct {
+	ChainID        int64         `json:"chainId"`
+	EntryPoint     string        `json:"entryPoint"`
+	HandleOps      hexutil.Bytes `json:"handleOps"`
+	ValidateUserOp hexutil.Bytes `json:"validateUserOp"`
+	Code           hexutil.Bytes `json:"code"`
+}
+
+var entryPointABI = mustABI(contracts.EntryPointABI)
+
+func mustABI(s string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+func loadEntryPointV07(t *testing.T) *entryPointV07 {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "entrypoint-v0.7.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var fx entryPointV07
+	if err := json.Unmarshal(data, &fx); err != nil {
+		t.Fatal(err)
+	}
+	if common.HexToAddress(fx.EntryPoint) != userop.EntryPointV07.Address {
+		t.Fatalf("样本的 EntryPoint %s 不是 v0.7 的地址", fx.EntryPoint)
+	}
+	return &fx
+}
+
+// unpack 把链上的 PackedUserOperation 拆回 UserOperation，与 Pack 互逆
+func unpack(p userop.PackedUserOperation) *userop.UserOperation {
+	op := &userop.UserOperation{
+		Sender:               p.Sender,
+		Nonce:                p.Nonce,
+		CallData:             p.CallData,
+		VerificationGasLimit: new(big.Int).SetBytes(p.AccountGasLimits[:16]).Uint64(),
+		CallGasLimit:         new(big.Int).SetBytes(p.AccountGasLimits[16:]).Uint64(),
+		PreVerificationGas:   p.PreVerificationGas.Uint64(),
+		MaxPriorityFeePerGas: new(big.Int).SetBytes(p.GasFees[:16]),
+		MaxFeePerGas:         new(big.Int).SetBytes(p.GasFees[16:]),
+		Signature:            p.Signature,
+	}
+	if len(p.InitCode) >= 20 {
+		factory := common.BytesToAddress(p.InitCode[:20])
+		op.Factory, op.FactoryData = &factory, p.InitCode[20:]
+	}
+	if pm := p.PaymasterAndData; len(pm) >= 52 {
+		paymaster := common.BytesToAddress(pm[:20])
+		op.Paymaster = &paymaster
+		op.PaymasterVerificationGasLimit = new(big.Int).SetBytes(pm[20:36]).Uint64()
+		op.PaymasterPostOpGasLimit = new(big.Int).SetBytes(pm[36:52]).Uint64()
+		op.PaymasterData = pm[52:]
+	}
+	return op
+}
+
+func TestHashMatchesRecordedEntryPointV07(t *testing.T) {
+	fx := loadEntryPointV07(t)
+
+	values, err := entryPointABI.Methods["handleOps"].Inputs.Unpack(fx.HandleOps[4:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops := *abi.ConvertType(values[0], new([]userop.PackedUserOperation)).(*[]userop.PackedUserOperation)
+	if len(ops) != 1 {
+		t.Fatalf("样本中有 %d 个 UserOperation，期望 1", len(ops))
+	}
+
+	op := unpack(ops[0])
+	repacked, err := entryPointABI.Pack("handleOps", []userop.PackedUserOperation{op.Pack()}, values[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(repacked, fx.HandleOps) {
+		t.Fatal("Pack 的结果与链上 handleOps 的参数不一致")
+	}
+
+	// validateUserOp(userOp, userOpHash, missingAccountFunds)：userOpHash 是第二个参数
+	want := common.BytesToHash(fx.ValidateUserOp[4+32 : 4+64])
+	if got := op.Hash(userop.EntryPointV07, big.NewInt(fx.ChainID)); got != want {
+		t.Fatalf("userOpHash = %s，正式 EntryPoint 计算的是 %s", got.Hex(), want.Hex())
+	}
+	if got := op.Hash(userop.EntryPointV07, big.NewInt(1)); got == want {
+		t.Fatal("不同链上的 userOpHash 应不同")
+	}
+}
+
+func TestHashMatchesEntryPointV07Bytecode(t *testing.T) {
+	fx := loadEntryPointV07(t)
+	net, err := simnet.New(&simnet.Options{
+		SkipContracts: true,
+		Alloc:         types.GenesisAlloc{userop.EntryPointV07.Address: {Code: fx.Code, Balance: new(big.Int)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer net.Close()
+	ctx := context.Background()
+
+	// 模拟链自带的简化版 EntryPoint 应与正式合约算出相同的 userOpHash
+	dev, _, err := net.Deploy(ctx, net.Accounts[0], contracts.EntryPointDeployData())
+	if err != nil {
+		t.Fatal(err)
+	}
+	entryPoints := []userop.EntryPoint{userop.EntryPointV07, {Address: dev, Version: userop.V07}}
+
+	for name, op := range sampleOps() {
+		for _, ep := range entryPoints {
+			data, err := entryPointABI.Pack("getUserOpHash", op.Pack())
+			if err != nil {
+				t.Fatal(err)
+			}
+			out, err := net.Client.CallContract(ctx, ethereum.CallMsg{To: &ep.Address, Data: data}, nil)
+			if err != nil {
+				t.Fatalf("%s: getUserOpHash: %v", name, err)
+			}
+			if got, want := op.Hash(ep, net.ChainID), common.BytesToHash(out); got != want {
+				t.Errorf("%s @ %s: userOpHash = %s，合约返回 %s", name, ep.Address.Hex(), got.Hex(), want.Hex())
+			}
+		}
+	}
+}
+
+// TestHashV06 v0.6 没有可用的合约字节码，按 v0.6 UserOperationLib 的 Solidity 源码
+// 用 abi 包独立编码，与手工拼接的结果对照：
+//
+//	pack = abi.encode(sender, nonce, keccak256(initCode), keccak256(callData),
+//	                  callGasLimit, verificationGasLimit, preVerificationGas,
+//	                  maxFeePerGas, maxPriorityFeePerGas, keccak256(paymasterAndData))
+//	userOpHash = keccak256(abi.encode(keccak256(pack), entryPoint, chainid))
+func TestHashV06(t *testing.T) {
+	typ := func(s string) abi.Argument {
+		ty, err := abi.NewType(s, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return abi.Argument{Type: ty}
+	}
+	u256, addr, b32 := typ("uint256"), typ("address"), typ("bytes32")
+	packArgs := abi.Arguments{addr, u256, b32, b32, u256, u256, u256, u256, u256, b32}
+	hashArgs := abi.Arguments{b32, addr, u256}
+	chainID := big.NewInt(11155111)
+	u := func(v uint64) *big.Int { return new(big.Int).SetUint64(v) }
+	keccak := func(b []byte) common.Hash { return crypto.Keccak256Hash(b) }
+
+	for name, op := range sampleOps() {
+		packed, err := packArgs.Pack(op.Sender, op.Nonce, keccak(op.InitCode()), keccak(op.CallData),
+			u(op.CallGasLimit), u(op.VerificationGasLimit), u(op.PreVerificationGas),
+			op.MaxFeePerGas, op.MaxPriorityFeePerGas, keccak(op.PaymasterAndData(userop.V06)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded, err := hashArgs.Pack(keccak(packed), userop.EntryPointV06.Address, chainID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, want := op.Hash(userop.EntryPointV06, chainID), keccak(encoded); got != want {
+			t.Errorf("%s: userOpHash = %s，期望 %s", name, got.Hex(), want.Hex())
+		}
+		if op.Hash(userop.EntryPointV06, chainID) == op.Hash(userop.EntryPointV07, chainID) {
+			t.Errorf("%s: v0.6 和 v0.7 的 userOpHash 应不同", name)
+		}
+	}
+}
+
+func TestSignAndRecover(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+	chainID := big.NewInt(11155111)
+
+	for _, ep := range []userop.EntryPoint{userop.EntryPointV06, userop.EntryPointV07} {
+		op := sampleOps()["paymaster"]
+		if err := op.Sign(key, ep, chainID); err != nil {
+			t.Fatal(err)
+		}
+		if v := op.Signature[64]; v != 27 && v != 28 {
+			t.Fatalf("%s: v = %d，期望 27 或 28", ep.Version, v)
+		}
+		if signer, err := op.Signer(ep, chainID); err != nil || signer != owner {
+			t.Fatalf("%s: 恢复的签名者 %s (%v)，期望 %s", ep.Version, signer.Hex(), err, owner.Hex())
+		}
+		// 签名绑定 EntryPoint 和链，换一个就恢复出别的地址
+		if signer, _ := op.Signer(ep, big.NewInt(1)); signer == owner {
+			t.Fatalf("%s: 换链后不应恢复出 owner", ep.Version)
+		}
+		op.CallGasLimit++
+		if signer, _ := op.Signer(ep, chainID); signer == owner {
+			t.Fatalf("%s: 修改字段后不应恢复出 owner", ep.Version)
+		}
+	}
+}
+
+// sampleOps 覆盖 initCode、paymasterAndData 为空和不为空的情况，Gas 和费用取不同的值，
+// 字段排列错位时哈希必然不同
+func sampleOps() map[string]*userop.UserOperation {
+	factory := common.HexToAddress("0x9406Cc6185a346906296840746125a0E44976454")
+	paymaster := common.HexToAddress("0x00000000000000fB866DaAA79352cC568a005D96")
+	base := func() *userop.UserOperation {
+		return &userop.UserOperation{
+			Sender:               common.HexToAddress("0x8C9D927336aDC963536122f8E0D269319e79eD7A"),
+			Nonce:                new(big.Int).Lsh(big.NewInt(3), 64), // key 3，序号 0
+			CallData:             common.FromHex("0xb61d27f6"),
+			CallGasLimit:         300_000,
+			VerificationGasLimit: 1_000_000,
+			PreVerificationGas:   50_000,
+			MaxFeePerGas:         big.NewInt(4_000_000_000),
+			MaxPriorityFeePerGas: big.NewInt(3_000_000_000),
+			Signature:            userop.DummySignature,
+		}
+	}
+	withFactory := base()
+	withFactory.Factory = &factory
+	withFactory.FactoryData = common.FromHex("0x5fbfb9cf000000000000000000000000f39fd6e51aad88f6f4ce6ab8827279cfffb92266")
+	withPaymaster := base()
+	withPaymaster.Paymaster = &paymaster
+	withPaymaster.PaymasterVerificationGasLimit = 70_000
+	withPaymaster.PaymasterPostOpGasLimit = 20_000
+	withPaymaster.PaymasterData = []byte{0xfa, 0xce}
+	return map[string]*userop.UserOperation{"plain": base(), "factory": withFactory, "paymaster": withPaymaster}
+}
@@ -0,0 +1,200 @@
+// Package verify 把链上运行时代码与本地编译产物比较，判断合约是否由该源码编译部署。
+//
+// 比较前会做两件事：
+//   - 去掉末尾的 CBOR 元数据（包含源码 IPFS 哈希、编译器版本），只有元数据不同视为部分匹配
+//   - 屏蔽 immutable 变量和库合约自身地址，这些位置在部署时才会填入实际值
+package verify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/dapp-learning/ethclient/util/deploy"
+)
+
+// Status 比较结果
+type Status string
+
+const (
+	StatusMatch    Status = "match"    // 完全一致（immutable 除外）
+	StatusPartial  Status = "partial"  // 只有元数据不同：逻辑相同，但源码注释、路径或编译设置不同
+	StatusMismatch Status = "mismatch" // 字节码不同
+)
+
+// 运行时字节码来源
+const (
+	SourceDeployed = "deployedBytecode"
+	SourceTail     = "bytecode 末尾（产物中没有运行时字节码，按长度截取，仅适用于没有 immutable 的合约）"
+)
+
+// Result 比较结果的详细信息
+type Result struct {
+	Status          Status             `json:"status"`
+	Source          string             `json:"source"`
+	OnchainSize     int                `json:"onchainSize"`
+	LocalSize       int                `json:"localSize"`
+	OnchainMetadata hexutil.Bytes      `json:"onchainMetadata,omitempty"`
+	LocalMetadata   hexutil.Bytes      `json:"localMetadata,omitempty"`
+	Masked          []deploy.CodeRange `json:"masked,omitempty"`    // 比较时忽略的区间
+	FirstDiff       int                `json:"firstDiff,omitempty"` // 第一个不同字节的偏移，不匹配时有效
+}
+
+// CodeReader 读取链上代码的接口
+type CodeReader interface {
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+}
+
+// Verify 读取 addr 的运行时代码并与编译产物比较
+func Verify(ctx context.Context, backend CodeReader, addr common.Address, art *deploy.Artifact) (*Result, error) {
+	code, err := backend.CodeAt(ctx, addr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 代码失败: %w", addr.Hex(), err)
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("地址 %s 上没有合约代码", addr.Hex())
+	}
+
+	local, source := art.DeployedBytecode, SourceDeployed
+	if len(local) == 0 {
+		// solcjs --bin 只输出创建字节码，其中运行时代码位于末尾（紧跟在构造逻辑之后）
+		if len(art.Bytecode) < len(code) {
+			return nil, fmt.Errorf("%s 没有运行时字节码，且创建字节码比链上代码短", art.Name)
+		}
+		local, source = art.Bytecode[len(art.Bytecode)-len(code):], SourceTail
+	}
+
+	result := Compare(code, local, art.Immutables)
+	result.Source = source
+	return result, nil
+}
+
+// Compare 比较链上代码与本地运行时字节码。
+// immutables 为编译产物给出的 immutable 位置，为空时按 PUSH32 全零立即数推断
+func Compare(onchain, local []byte, immutables []deploy.CodeRange) *Result {
+	onBody, onMeta := SplitMetadata(onchain)
+	localBody, localMeta := SplitMetadata(local)
+	result := &Result{
+		OnchainSize:     len(onchain),
+		LocalSize:       len(local),
+		OnchainMetadata: onMeta,
+		LocalMetadata:   localMeta,
+	}
+
+	if len(onBody) != len(localBody) {
+		result.Status = StatusMismatch
+		result.FirstDiff = firstDiff(onBody, localBody)
+		return result
+	}
+
+	masked := append([]deploy.CodeRange(nil), immutables...)
+	if len(masked) == 0 {
+		masked = guessImmutables(onBody, localBody)
+	}
+	masked = append(masked, libraryAddress(localBody)...)
+	result.Masked = masked
+
+	onMasked, localMasked := mask(onBody, masked), mask(localBody, masked)
+	switch {
+	case !bytes.Equal(onMasked, localMasked):
+		result.Status = StatusMismatch
+		result.FirstDiff = firstDiff(onMasked, localMasked)
+	case bytes.Equal(onMeta, localMeta):
+		result.Status = StatusMatch
+	default:
+		result.Status = StatusPartial
+	}
+	return result
+}
+
+// SplitMetadata 拆分运行时代码与 solc 追加的 CBOR 元数据。
+// 最后两个字节是元数据长度（大端），元数据本身是一个 CBOR map（首字节 0xa0-0xbf）
+func SplitMetadata(code []byte) (body, metadata []byte) {
+	if len(code) < 2 {
+		return code, nil
+	}
+	n := int(code[len(code)-2])<<8 | int(code[len(code)-1])
+	start := len(code) - 2 - n
+	if n == 0 || start < 0 || code[start]&0xe0 != 0xa0 {
+		return code, nil
+	}
+	return code[:start], code[start:]
+}
+
+// SolcVersion 从元数据中读取编译器版本（CBOR 键 "solc" 对应 3 字节的版本号）
+func SolcVersion(metadata []byte) string {
+	key := []byte{0x64, 's', 'o', 'l', 'c', 0x43}
+	idx := bytes.Index(metadata, key)
+	if idx < 0 || idx+len(key)+3 > len(metadata) {
+		return ""
+	}
+	v := metadata[idx+len(key):]
+	return fmt.Sprintf("%d.%d.%d", v[0], v[1], v[2])
+}
+
+// guessImmutables 产物没有 immutableReferences 时（Hardhat、solcjs），
+// 把本地为全零、链上不同的 PUSH32 立即数视为 immutable
+func guessImmutables(onchain, local []byte) []deploy.CodeRange {
+	var ranges []deploy.CodeRange
+	for pc := 0; pc < len(local); pc++ {
+		op := local[pc]
+		if op < 0x60 || op > 0x7f { // PUSH1 … PUSH32
+			continue
+		}
+		size := int(op-0x60) + 1
+		start, end := pc+1, pc+1+size
+		pc += size
+		if op != 0x7f || end > len(local) {
+			continue
+		}
+		if isZero(local[start:end]) && !bytes.Equal(onchain[start:end], local[start:end]) {
+			ranges = append(ranges, deploy.CodeRange{Start: start, Length: size})
+		}
+	}
+	return ranges
+}
+
+// libraryAddress 库合约的运行时代码以 PUSH20 <自身地址> ADDRESS EQ 开头（防止被直接调用），
+// 编译产物中该地址为零，部署时填入
+func libraryAddress(local []byte) []deploy.CodeRange {
+	if len(local) >= 23 && local[0] == 0x73 && isZero(local[1:21]) && local[21] == 0x30 && local[22] == 0x14 {
+		return []deploy.CodeRange{{Start: 1, Length: common.AddressLength}}
+	}
+	return nil
+}
+
+func mask(code []byte, ranges []deploy.CodeRange) []byte {
+	out := append([]byte(nil), code...)
+	for _, r := range ranges {
+		for i := r.Start; i < r.Start+r.Length && i < len(out); i++ {
+			out[i] = 0
+		}
+	}
+	return out
+}
+
+func firstDiff(a, b []byte) int {
+	n := len(a)
+	if len(b) < n {
+		n = len(b)
+	}
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
+
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,179 @@
+package verify_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+
+	"github.com/dapp-learning/ethclient/util/deploy"
+	"github.com/dapp-learning/ethclient/util/simnet"
+	"github.com/dapp-learning/ethclient/util/verify"
+)
+
+// storeArtifact 2.09 提交的 Store 编译产物（solcjs 0.8.30，只有创建字节码）
+const storeArtifact = "../../2.09-deploy-contract/contract/Store_sol_Store.abi"
+
+// deployStore 在 simnet 上部署 2.09 的 Store，返回编译产物、合约地址和链上运行时代码
+func deployStore(t *testing.T) (*simnet.Net, *deploy.Artifact, common.Address, []byte) {
+	t.Helper()
+	art, err := deploy.LoadArtifact(storeArtifact, "Store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	net, err := simnet.New(&simnet.Options{SkipContracts: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { net.Close() })
+
+	ctx := context.Background()
+	data, err := art.DeployData("1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr, _, err := net.Deploy(ctx, net.Accounts[0], data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, err := net.Client.CodeAt(ctx, addr, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return net, art, addr, code
+}
+
+func TestSplitMetadata(t *testing.T) {
+	art, err := deploy.LoadArtifact(storeArtifact, "Store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, meta := verify.SplitMetadata(art.Bytecode)
+	if len(meta) == 0 || meta[0]&0xe0 != 0xa0 {
+		t.Fatalf("元数据 %x 不是 CBOR map", meta)
+	}
+	if !bytes.Equal(append(append([]byte(nil), body...), meta...), art.Bytecode) {
+		t.Fatal("body + metadata 应等于原字节码")
+	}
+	if v := verify.SolcVersion(meta); v != "0.8.30" {
+		t.Fatalf("SolcVersion = %q，期望 0.8.30", v)
+	}
+
+	tests := []struct {
+		name string
+		code []byte
+	}{
+		{"empty", nil},
+		{"short", []byte{0x00}},
+		{"no metadata", []byte{0x60, 0x00, 0x60, 0x00, 0xf3}},
+		{"zero length", []byte{0x60, 0x00, 0x00, 0x00}},
+		{"length too long", []byte{0xa1, 0x00, 0x10}},
+		{"not a map", []byte{0x60, 0x00, 0x60, 0x00, 0x00, 0x02}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, meta := verify.SplitMetadata(tt.code)
+			if meta != nil || !bytes.Equal(body, tt.code) {
+				t.Fatalf("SplitMetadata(%x) = %x, %x，期望原样返回", tt.code, body, meta)
+			}
+			if v := verify.SolcVersion(meta); v != "" {
+				t.Fatalf("SolcVersion = %q，期望为空", v)
+			}
+		})
+	}
+}
+
+func TestVerifyStore(t *testing.T) {
+	net, art, addr, code := deployStore(t)
+	result, err := verify.Verify(context.Background(), net.Client, addr, art)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != verify.StatusMatch || result.Source != verify.SourceTail || result.OnchainSize != len(code) {
+		t.Fatalf("Verify = %+v，期望按 bytecode 末尾完全匹配", result)
+	}
+	if v := verify.SolcVersion(result.OnchainMetadata); v != "0.8.30" {
+		t.Fatalf("链上元数据中的版本为 %q", v)
+	}
+
+	// 没有代码的地址
+	if _, err := verify.Verify(context.Background(), net.Client, net.Accounts[1].Address, art); err == nil {
+		t.Fatal("外部账户应返回错误")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	_, _, _, code := deployStore(t)
+
+	// flip 返回 code 的副本，off 处的字节取反
+	flip := func(code []byte, off int) []byte {
+		out := append([]byte(nil), code...)
+		out[off] ^= 0xff
+		return out
+	}
+	filled := bytes.Repeat([]byte{0x11}, 32)
+	zero := make([]byte, 32)
+	push32 := func(imm []byte) []byte {
+		return append(append([]byte{0x7f}, imm...), 0x50, 0x00) // PUSH32 imm POP STOP
+	}
+	library := func(addr byte) []byte {
+		code := append([]byte{0x73}, make([]byte, 20)...) // PUSH20 <自身地址> ADDRESS EQ
+		code[20] = addr
+		return append(code, 0x30, 0x14, 0x00)
+	}
+
+	tests := []struct {
+		name       string
+		onchain    []byte
+		local      []byte
+		immutables []deploy.CodeRange
+		want       verify.Status
+		masked     []deploy.CodeRange
+		firstDiff  int
+	}{
+		{name: "store match", onchain: code, local: code, want: verify.StatusMatch},
+		{name: "store metadata", onchain: code, local: flip(code, len(code)-10), want: verify.StatusPartial},
+		{name: "store body", onchain: code, local: flip(code, 40), want: verify.StatusMismatch, firstDiff: 40},
+		{name: "store length", onchain: code, local: append([]byte{0x00}, code...), want: verify.StatusMismatch},
+
+		// 本地为全零的 PUSH32 立即数视为 immutable
+		{name: "push32 immutable", onchain: push32(filled), local: push32(zero), want: verify.StatusMatch, masked: []deploy.CodeRange{{Start: 1, Length: 32}}},
+		// 本地不是全零时按普通代码比较
+		{name: "push32 constant", onchain: push32(filled), local: push32(bytes.Repeat([]byte{0x22}, 32)), want: verify.StatusMismatch, firstDiff: 1},
+		// 只推断 PUSH32，较短的 PUSH 不视为 immutable
+		{name: "push1", onchain: []byte{0x60, 0x01, 0x00}, local: []byte{0x60, 0x00, 0x00}, want: verify.StatusMismatch, firstDiff: 1},
+		// 编译产物给出了 immutable 位置时不再推断
+		{name: "explicit immutables", onchain: push32(filled), local: push32(zero), immutables: []deploy.CodeRange{{Start: 1, Length: 16}}, want: verify.StatusMismatch, masked: []deploy.CodeRange{{Start: 1, Length: 16}}, firstDiff: 17},
+
+		// 库合约开头的自身地址
+		{name: "library address", onchain: library(0x42), local: library(0), want: verify.StatusMatch, masked: []deploy.CodeRange{{Start: 1, Length: 20}}},
+		{name: "library other code", onchain: flip(library(0x42), 22), local: library(0), want: verify.StatusMismatch, masked: []deploy.CodeRange{{Start: 1, Length: 20}}, firstDiff: 22},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := verify.Compare(tt.onchain, tt.local, tt.immutables)
+			if result.Status != tt.want {
+				t.Fatalf("Status = %s，期望 %s（%+v）", result.Status, tt.want, result)
+			}
+			if tt.masked != nil && !equalRanges(result.Masked, tt.masked) {
+				t.Errorf("Masked = %v，期望 %v", result.Masked, tt.masked)
+			}
+			if tt.want == verify.StatusMismatch && tt.firstDiff != 0 && result.FirstDiff != tt.firstDiff {
+				t.Errorf("FirstDiff = %d，期望 %d", result.FirstDiff, tt.firstDiff)
+			}
+		})
+	}
+}
+
+func equalRanges(a, b []deploy.CodeRange) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}